@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"worldgenerator/core"
+	"worldgenerator/simulation"
+)
+
+// apiRequestTimeout bounds how long a request waits for the main loop
+const apiRequestTimeout = 5 * time.Second
+
+// apiServer answers analysis queries over HTTP
+// Handlers never touch the planet themselves: they queue a reply channel and
+// the main loop fills it between frames, when the rendered planet is stable
+type apiServer struct {
+	boundaryRequests  chan chan []simulation.BoundarySegment
+	statusRequests    chan chan apiStatus
+	referenceRequests chan referencePlateRequest
+}
+
+// apiStatus summarizes the running simulation
+type apiStatus struct {
+	Seed       int64   `json:"seed"`       // Regenerates the initial planet with -seed
+	Time       float64 `json:"time"`       // Simulated years
+	SeaLevel   float64 `json:"seaLevel"`   // Meters
+	Continents int     `json:"continents"` // Separate landmasses, see core.IdentifyContinents
+}
+
+// apiReferencePlate is the plate held still as the physics reference frame
+type apiReferencePlate struct {
+	PlateID int `json:"plateId"` // 0 = absolute frame
+}
+
+// referencePlateRequest reads the reference plate, or changes it when set
+type referencePlateRequest struct {
+	set     bool
+	plateID int
+	reply   chan referencePlateReply
+}
+
+type referencePlateReply struct {
+	plate apiReferencePlate
+	err   error
+}
+
+// startAPIServer serves the analysis endpoints on addr in the background
+func startAPIServer(addr string) *apiServer {
+	s := &apiServer{
+		boundaryRequests:  make(chan chan []simulation.BoundarySegment),
+		statusRequests:    make(chan chan apiStatus),
+		referenceRequests: make(chan referencePlateRequest),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/boundaries", s.handleBoundaries)
+	mux.HandleFunc("/status", s.handleStatus)
+	mux.HandleFunc("/reference-plate", s.handleReferencePlate)
+
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			fmt.Printf("⚠️  HTTP API stopped: %v\n", err)
+		}
+	}()
+
+	fmt.Printf("✅ HTTP API listening on %s (GET /status, /boundaries; GET or POST /reference-plate)\n", addr)
+	return s
+}
+
+// handleBoundaries returns plate boundary convergence and divergence rates as JSON
+func (s *apiServer) handleBoundaries(w http.ResponseWriter, r *http.Request) {
+	reply := make(chan []simulation.BoundarySegment, 1)
+	timeout := time.After(apiRequestTimeout)
+
+	select {
+	case s.boundaryRequests <- reply:
+	case <-timeout:
+		http.Error(w, "simulation busy", http.StatusServiceUnavailable)
+		return
+	}
+
+	select {
+	case segments := <-reply:
+		w.Header().Set("Content-Type", "application/json")
+		if segments == nil {
+			segments = []simulation.BoundarySegment{}
+		}
+		json.NewEncoder(w).Encode(segments)
+	case <-timeout:
+		http.Error(w, "simulation busy", http.StatusServiceUnavailable)
+	}
+}
+
+// handleStatus returns the seed, simulated time, sea level and continent
+// count as JSON
+func (s *apiServer) handleStatus(w http.ResponseWriter, r *http.Request) {
+	reply := make(chan apiStatus, 1)
+	timeout := time.After(apiRequestTimeout)
+
+	select {
+	case s.statusRequests <- reply:
+	case <-timeout:
+		http.Error(w, "simulation busy", http.StatusServiceUnavailable)
+		return
+	}
+
+	select {
+	case status := <-reply:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(status)
+	case <-timeout:
+		http.Error(w, "simulation busy", http.StatusServiceUnavailable)
+	}
+}
+
+// handleReferencePlate returns the plate held still as JSON, and on POST
+// holds the plate given by the plate parameter still instead (0 = release)
+func (s *apiServer) handleReferencePlate(w http.ResponseWriter, r *http.Request) {
+	req := referencePlateRequest{reply: make(chan referencePlateReply, 1)}
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		plateID, err := strconv.Atoi(r.FormValue("plate"))
+		if err != nil || plateID < 0 {
+			http.Error(w, "plate must be a plate ID, or 0 for the absolute frame", http.StatusBadRequest)
+			return
+		}
+		req.set, req.plateID = true, plateID
+	default:
+		http.Error(w, "use GET or POST", http.StatusMethodNotAllowed)
+		return
+	}
+	timeout := time.After(apiRequestTimeout)
+
+	select {
+	case s.referenceRequests <- req:
+	case <-timeout:
+		http.Error(w, "simulation busy", http.StatusServiceUnavailable)
+		return
+	}
+
+	select {
+	case reply := <-req.reply:
+		if reply.err != nil {
+			http.Error(w, reply.err.Error(), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(reply.plate)
+	case <-timeout:
+		http.Error(w, "simulation busy", http.StatusServiceUnavailable)
+	}
+}
+
+// serve answers pending requests from the main loop without blocking
+// referencePlate is the renderer's plate held still, which POSTs change, and
+// continents the core.IdentifyContinents count the stats overlay shows
+func (s *apiServer) serve(planet *core.VoxelPlanet, referencePlate *int, continents int) {
+	if s == nil {
+		return
+	}
+
+	for {
+		select {
+		case reply := <-s.boundaryRequests:
+			var segments []simulation.BoundarySegment
+			if plates := simulation.PublishedPlates(planet); plates != nil {
+				segments = plates.Boundaries
+			}
+			reply <- segments
+		case reply := <-s.statusRequests:
+			reply <- apiStatus{
+				Seed:       planet.Seed(),
+				Time:       planet.Time,
+				SeaLevel:   planet.SeaLevel,
+				Continents: continents,
+			}
+		case req := <-s.referenceRequests:
+			if req.set && req.plateID != 0 && !hasPlate(planet, req.plateID) {
+				req.reply <- referencePlateReply{err: fmt.Errorf("no plate %d", req.plateID)}
+				continue
+			}
+			if req.set {
+				*referencePlate = req.plateID
+			}
+			req.reply <- referencePlateReply{plate: apiReferencePlate{PlateID: *referencePlate}}
+		default:
+			return
+		}
+	}
+}
+
+// hasPlate reports whether physics published a plate with the ID in the
+// planet's state
+func hasPlate(planet *core.VoxelPlanet, plateID int) bool {
+	plates := simulation.PublishedPlates(planet)
+	return plates != nil && plates.HasPlate(plateID)
+}
@@ -31,8 +31,8 @@ func main() {
 			Alt: 0,
 		}
 
-		cart := core.GeographicToCartesian(geo, radius)
-		geoBack := core.CartesianToGeographic(cart, radius)
+		cart := core.GeographicToCartesian(geo, radius, 0)
+		geoBack := core.CartesianToGeographic(cart, radius, 0)
 
 		fmt.Printf("%s (%.0f°, %.0f°):\n", pos.name, pos.lat, pos.lon)
 		fmt.Printf("  Cartesian: X=%.0f, Y=%.0f, Z=%.0f\n", cart.X, cart.Y, cart.Z)
@@ -0,0 +1,32 @@
+package core
+
+import (
+	"io"
+	"os"
+)
+
+// WriteFileAtomic creates path with the contents write produces. They go to a
+// temporary file that is renamed into place once complete, so an interrupted
+// run or a failed write never leaves a truncated file behind, and an existing
+// file stays untouched until its replacement is whole
+func WriteFileAtomic(path string, write func(w io.Writer) error) error {
+	tmpPath := path + ".tmp"
+	file, err := os.Create(tmpPath)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath) // No-op once renamed
+
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, path)
+}
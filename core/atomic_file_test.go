@@ -0,0 +1,38 @@
+package core
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestWriteFileAtomic checks a complete write replaces the file, and a failed
+// one leaves the old file as it was with no temporary file behind
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.txt")
+	write := func(text string, fail error) error {
+		return WriteFileAtomic(path, func(w io.Writer) error {
+			if _, err := io.WriteString(w, text); err != nil {
+				return err
+			}
+			return fail
+		})
+	}
+
+	if err := write("first", nil); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if err := write("second, cut short", errors.New("interrupted")); err == nil {
+		t.Fatal("failed write reported success")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "first" {
+		t.Errorf("file holds %q (%v) after a failed write, want %q", data, err, "first")
+	}
+	if _, err := os.Stat(path + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temporary file left behind: %v", err)
+	}
+}
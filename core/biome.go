@@ -0,0 +1,35 @@
+package core
+
+import "fmt"
+
+// Biome is the climate zone of a surface voxel, loosely after Köppen
+type Biome uint8
+
+const (
+	BiomeNone               Biome = iota // Not a surface voxel, or not yet classified
+	BiomeOcean                           // Open water
+	BiomeIce                             // Sea ice, ice sheets and perpetual frost
+	BiomeTundra                          // Too cold for trees
+	BiomeBorealForest                    // Taiga
+	BiomeTemperateForest                 // Mild and wet
+	BiomeGrassland                       // Steppe and prairie, too dry for forest
+	BiomeDesert                          // Too dry for grass
+	BiomeSavanna                         // Hot with a long dry season
+	BiomeTropicalRainforest              // Hot and wet all year
+	BiomeAlpine                          // Above the tree line on high mountains
+	BiomeCount                           // Number of biomes
+)
+
+// biomeNames names each biome, in biome order
+var biomeNames = [BiomeCount]string{
+	"none", "ocean", "ice", "tundra", "boreal forest", "temperate forest",
+	"grassland", "desert", "savanna", "tropical rainforest", "alpine",
+}
+
+// String returns the biome's name
+func (b Biome) String() string {
+	if b < BiomeCount {
+		return biomeNames[b]
+	}
+	return fmt.Sprintf("biome_%d", b)
+}
@@ -0,0 +1,128 @@
+package core
+
+// RenderMode is a coloring of the planet view, numbered as the ray march
+// shader's renderMode uniform
+type RenderMode int32
+
+const (
+	RenderMaterial RenderMode = iota
+	RenderTemperature
+	RenderVelocity
+	RenderAge
+	RenderPlates
+	RenderStress
+	RenderSubPosition
+	RenderElevation
+	RenderHeatFlux
+)
+
+// ColorStop is a color a colormap passes through at a value
+type ColorStop struct {
+	Value float64
+	Color Vector3
+}
+
+// Colormap maps a scalar field to colors, blending linearly between stops.
+// Two stops at the same value make a hard edge, like the coastline
+type Colormap struct {
+	Title      string  // Legend title, including the unit labels are in
+	LabelScale float64 // Values are divided by this for legend labels
+	Stops      []ColorStop
+}
+
+// TemperatureColormap runs from surface-cold blue to red-hot mantle
+var TemperatureColormap = Colormap{
+	Title:      "Temperature (kelvin)",
+	LabelScale: 1,
+	Stops: []ColorStop{
+		{273, Vector3{X: 0, Y: 0, Z: 1}},
+		{3273, Vector3{X: 1, Y: 0, Z: 0}},
+	},
+}
+
+// ElevationColormap shades the seafloor in blues and land like a relief
+// map: beaches, lowland green, brown and grey mountains, snow caps
+var ElevationColormap = Colormap{
+	Title:      "Elevation (meters)",
+	LabelScale: 1,
+	Stops: []ColorStop{
+		{-4000, Vector3{X: 0.05, Y: 0.1, Z: 0.3}}, // Deep ocean
+		{-2000, Vector3{X: 0.1, Y: 0.3, Z: 0.6}},
+		{-200, Vector3{X: 0.2, Y: 0.5, Z: 0.8}}, // Shelf break
+		{0, Vector3{X: 0.3, Y: 0.6, Z: 0.85}},
+		{0, Vector3{X: 0.76, Y: 0.7, Z: 0.5}}, // Beaches
+		{50, Vector3{X: 0.7, Y: 0.65, Z: 0.45}},
+		{500, Vector3{X: 0.3, Y: 0.5, Z: 0.2}}, // Lowland plains
+		{1500, Vector3{X: 0.295, Y: 0.385, Z: 0.18}},
+		{1500, Vector3{X: 0.45, Y: 0.35, Z: 0.25}}, // Mountain rock
+		{3000, Vector3{X: 0.5, Y: 0.48, Z: 0.45}},
+		{4500, Vector3{X: 0.4, Y: 0.38, Z: 0.36}},
+		{6000, Vector3{X: 0.95, Y: 0.96, Z: 0.98}}, // Snow caps
+	},
+}
+
+// AgeColormap colors crust from newly formed red through yellow and green to
+// the blue of the oldest seafloor, as on seafloor age maps
+var AgeColormap = Colormap{
+	Title:      "Crust age (million years)",
+	LabelScale: 1e6,
+	Stops: []ColorStop{
+		{0, Vector3{X: 0.85, Y: 0.1, Z: 0.05}},
+		{50e6, Vector3{X: 1.0, Y: 0.9, Z: 0.3}},
+		{100e6, Vector3{X: 0.3, Y: 0.7, Z: 0.3}},
+		{200e6, Vector3{X: 0.1, Y: 0.2, Z: 0.6}},
+	},
+}
+
+// Colormaps are the rows of the shader's colormap texture, in this order
+// Keep in sync with the COLORMAP_ constants in the ray march shader
+var Colormaps = []*Colormap{&TemperatureColormap, &ElevationColormap, &AgeColormap}
+
+// ColormapFor returns the colormap a render mode shades with, or nil if it
+// has none
+func ColormapFor(mode RenderMode) *Colormap {
+	switch mode {
+	case RenderTemperature:
+		return &TemperatureColormap
+	case RenderAge:
+		return &AgeColormap
+	case RenderElevation:
+		return &ElevationColormap
+	}
+	return nil
+}
+
+// Range returns the values of the first and last stops; values outside
+// take the end colors
+func (c *Colormap) Range() (min, max float64) {
+	return c.Stops[0].Value, c.Stops[len(c.Stops)-1].Value
+}
+
+// Color returns the colormap's color for a value. Both the legends and the
+// shader's colormap texture are drawn with it, so they can't disagree
+func (c *Colormap) Color(value float64) Vector3 {
+	if value <= c.Stops[0].Value {
+		return c.Stops[0].Color
+	}
+	for i := 1; i < len(c.Stops); i++ {
+		next := c.Stops[i]
+		if value < next.Value {
+			prev := c.Stops[i-1]
+			t := (value - prev.Value) / (next.Value - prev.Value)
+			return prev.Color.Add(next.Color.Sub(prev.Color).Scale(t))
+		}
+	}
+	return c.Stops[len(c.Stops)-1].Color
+}
+
+// Table samples the colormap at n evenly spaced values across its range, as
+// RGB triples for a lookup texture
+func (c *Colormap) Table(n int) []float32 {
+	min, max := c.Range()
+	table := make([]float32, 0, n*3)
+	for i := 0; i < n; i++ {
+		color := c.Color(min + (max-min)*float64(i)/float64(n-1))
+		table = append(table, float32(color.X), float32(color.Y), float32(color.Z))
+	}
+	return table
+}
@@ -0,0 +1,133 @@
+package core
+
+import (
+	"math"
+	"sort"
+)
+
+// Continent describes one connected landmass on the surface shell
+type Continent struct {
+	ID         int // 1 = largest by area
+	VoxelCount int
+	Area       float64 // Surface area in m²
+
+	// Centroid in degrees (mean of voxel unit vectors, safe across the antimeridian)
+	CentroidLat float64
+	CentroidLon float64
+
+	// Bounding box of voxel centers in degrees
+	// MinLon > MaxLon means the continent crosses the antimeridian
+	MinLat, MaxLat float64
+	MinLon, MaxLon float64
+
+	Voxels []VoxelCoord
+}
+
+// isContinentLand reports whether a surface voxel counts as exposed land
+func isContinentLand(voxel *VoxelMaterial, seaLevel float64) bool {
+	if voxel.Type != MatGranite && voxel.Type != MatBasalt {
+		return false
+	}
+	return float64(voxel.Elevation) > seaLevel
+}
+
+// IdentifyContinents finds connected land on the surface shell
+// Continents are returned largest first, by surface area
+func IdentifyContinents(planet *VoxelPlanet) []Continent {
+	if len(planet.Shells) < 2 {
+		return nil
+	}
+	shellIdx := len(planet.Shells) - 2
+	shell := &planet.Shells[shellIdx]
+
+	visited := make([][]bool, len(shell.Voxels))
+	for latIdx := range shell.Voxels {
+		visited[latIdx] = make([]bool, len(shell.Voxels[latIdx]))
+	}
+
+	isLand := func(voxel *VoxelMaterial) bool {
+		return isContinentLand(voxel, planet.SeaLevel)
+	}
+
+	var continents []Continent
+	for latIdx := range shell.Voxels {
+		for lonIdx := range shell.Voxels[latIdx] {
+			if visited[latIdx][lonIdx] || !isContinentLand(&shell.Voxels[latIdx][lonIdx], planet.SeaLevel) {
+				continue
+			}
+
+			// Flood fill this landmass
+			members := SphericalFloodFill(shell, VoxelCoord{Shell: shellIdx, Lat: latIdx, Lon: lonIdx}, isLand)
+			for _, coord := range members {
+				visited[coord.Lat][coord.Lon] = true
+			}
+
+			continents = append(continents, summarizeContinent(planet, shell, members))
+		}
+	}
+
+	sort.Slice(continents, func(i, j int) bool {
+		return continents[i].Area > continents[j].Area
+	})
+	for i := range continents {
+		continents[i].ID = i + 1
+	}
+
+	return continents
+}
+
+// summarizeContinent computes size, centroid and bounds for a set of voxels
+func summarizeContinent(planet *VoxelPlanet, shell *SphericalShell, members []VoxelCoord) Continent {
+	c := Continent{
+		VoxelCount: len(members),
+		MinLat:     90,
+		MaxLat:     -90,
+		Voxels:     members,
+	}
+
+	var sx, sy, sz float64
+	lons := make([]float64, 0, len(members))
+	for _, coord := range members {
+		lat := GetLatitudeForBand(coord.Lat, shell.LatBands)
+		lon := GetLongitudeForIndex(coord.Lon, shell.LonCounts[coord.Lat])
+
+		c.Area += planet.VoxelArea(shell, coord.Lat)
+		c.MinLat = math.Min(c.MinLat, lat)
+		c.MaxLat = math.Max(c.MaxLat, lat)
+		lons = append(lons, lon)
+
+		latRad := lat * math.Pi / 180.0
+		lonRad := lon * math.Pi / 180.0
+		sx += math.Cos(latRad) * math.Cos(lonRad)
+		sy += math.Sin(latRad)
+		sz += math.Cos(latRad) * math.Sin(lonRad)
+	}
+
+	c.CentroidLat = math.Atan2(sy, math.Hypot(sx, sz)) * 180.0 / math.Pi
+	c.CentroidLon = math.Atan2(sz, sx) * 180.0 / math.Pi
+
+	c.MinLon, c.MaxLon = longitudeBounds(lons)
+	return c
+}
+
+// longitudeBounds returns the smallest longitude range covering all values
+// The range starts after the largest gap, so it may wrap past 180°
+func longitudeBounds(lons []float64) (float64, float64) {
+	if len(lons) == 0 {
+		return 0, 0
+	}
+	sort.Float64s(lons)
+
+	// Gap across the antimeridian
+	bestGap := lons[0] + 360.0 - lons[len(lons)-1]
+	minLon, maxLon := lons[0], lons[len(lons)-1]
+
+	for i := 1; i < len(lons); i++ {
+		if gap := lons[i] - lons[i-1]; gap > bestGap {
+			bestGap = gap
+			minLon, maxLon = lons[i], lons[i-1]
+		}
+	}
+
+	return minLon, maxLon
+}
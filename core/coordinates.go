@@ -51,20 +51,35 @@ func RadiansToDegrees(radians float64) float64 {
 	return radians * 180.0 / math.Pi
 }
 
+// oblateLatitudeIterations bounds the refinement of geodetic latitude in
+// CartesianToGeographic; each one gains several digits, so Earth converges
+// in two and even a body flattened by half in a handful
+const oblateLatitudeIterations = 8
+
 // GeographicToCartesian converts geographic coordinates to Cartesian
-func GeographicToCartesian(g Geographic, radius float64) Cartesian {
-	r := radius + g.Alt
-	cosLat := math.Cos(g.Lat)
+// With nonzero flattening (a-b)/a the surface is an oblate spheroid of
+// equatorial radius radius: Lat is geodetic, the angle of the surface normal,
+// and Alt is the height along that normal. Zero flattening is a sphere
+func GeographicToCartesian(g Geographic, radius, flattening float64) Cartesian {
+	sinLat, cosLat := math.Sin(g.Lat), math.Cos(g.Lat)
+
+	// Prime vertical radius of curvature, the radius itself on a sphere
+	e2 := flattening * (2 - flattening)
+	n := radius / math.Sqrt(1-e2*sinLat*sinLat)
+	equatorial := (n + g.Alt) * cosLat
 
 	return Cartesian{
-		X: r * cosLat * math.Cos(g.Lon),
-		Y: r * math.Sin(g.Lat),
-		Z: r * cosLat * math.Sin(g.Lon),
+		X: equatorial * math.Cos(g.Lon),
+		Y: (n*(1-e2) + g.Alt) * sinLat,
+		Z: equatorial * math.Sin(g.Lon),
 	}
 }
 
-// CartesianToGeographic converts Cartesian coordinates to geographic
-func CartesianToGeographic(c Cartesian, radius float64) Geographic {
+// CartesianToGeographic converts Cartesian coordinates to geographic, the
+// inverse of GeographicToCartesian for the same radius and flattening
+// Geodetic latitude on a spheroid has no closed form, so it is refined with
+// Bowring's iteration from the parametric latitude
+func CartesianToGeographic(c Cartesian, radius, flattening float64) Geographic {
 	r := math.Sqrt(c.X*c.X + c.Y*c.Y + c.Z*c.Z)
 
 	// Handle special case of origin
@@ -72,10 +87,38 @@ func CartesianToGeographic(c Cartesian, radius float64) Geographic {
 		return Geographic{Lat: 0, Lon: 0, Alt: -radius}
 	}
 
+	lon := math.Atan2(c.Z, c.X)
+	if flattening == 0 {
+		return Geographic{
+			Lat: math.Asin(c.Y / r),
+			Lon: lon,
+			Alt: r - radius,
+		}
+	}
+
+	e2 := flattening * (2 - flattening)
+	polar := radius * (1 - flattening)
+	ep2 := e2 / (1 - e2)
+	p := math.Hypot(c.X, c.Z) // Distance from the axis
+
+	beta := math.Atan2((1-flattening)*c.Y, p)
+	lat := beta
+	for i := 0; i < oblateLatitudeIterations; i++ {
+		sinBeta, cosBeta := math.Sin(beta), math.Cos(beta)
+		next := math.Atan2(c.Y+ep2*polar*sinBeta*sinBeta*sinBeta, p-e2*radius*cosBeta*cosBeta*cosBeta)
+		converged := math.Abs(next-lat) < 1e-15
+		lat = next
+		if converged {
+			break
+		}
+		beta = math.Atan2((1-flattening)*math.Sin(lat), math.Cos(lat))
+	}
+
+	sinLat := math.Sin(lat)
 	return Geographic{
-		Lat: math.Asin(c.Y / r),
-		Lon: math.Atan2(c.Z, c.X),
-		Alt: r - radius,
+		Lat: lat,
+		Lon: lon,
+		Alt: p*math.Cos(lat) + c.Y*sinLat - radius*math.Sqrt(1-e2*sinLat*sinLat),
 	}
 }
 
@@ -0,0 +1,261 @@
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// MeshFace is one triangle of an exported mesh
+type MeshFace struct {
+	V        [3]int // Vertex indices, counter-clockwise seen from outside
+	Material MaterialType
+	Cut      bool // Lies on the cut plane rather than the planet surface
+}
+
+// CrossSectionMesh is a planet cut by an axis-aligned plane: the exposed cut
+// face plus the hemisphere of surface on the kept side
+type CrossSectionMesh struct {
+	Vertices []Vector3 // Meters from the planet center, Y toward the north pole
+	Faces    []MeshFace
+	Axis     int     // 0=X, 1=Y, 2=Z
+	Position float64 // Cut plane position along Axis in meters
+}
+
+// ExportCrossSectionMesh builds a mesh of the planet cut at position meters
+// along axis (0=X, 1=Y, 2=Z), keeping the side where the coordinate is
+// greater - the same half the renderer's cross-section view shows
+// The cut face is split into cells along shell boundaries so each cell takes
+// the material of a single shell
+func ExportCrossSectionMesh(planet *VoxelPlanet, axis int, position float64) (*CrossSectionMesh, error) {
+	if axis < 0 || axis > 2 {
+		return nil, fmt.Errorf("invalid cross-section axis %d (want 0=X, 1=Y, 2=Z)", axis)
+	}
+	surface := len(planet.Shells) - 2
+	if surface < 0 {
+		return nil, fmt.Errorf("planet has no surface shell")
+	}
+	surfaceShell := &planet.Shells[surface]
+	radius := surfaceShell.OuterRadius
+	if math.Abs(position) >= radius {
+		return nil, fmt.Errorf("cross-section at %.0f m misses the planet (radius %.0f m)", position, radius)
+	}
+
+	mesh := &CrossSectionMesh{Axis: axis, Position: position}
+
+	// Angular resolution follows the surface shell
+	segments := 2 * surfaceShell.LatBands
+	if segments < 16 {
+		segments = 16
+	}
+
+	// Rings where the cut plane crosses shell boundaries
+	var rings []float64
+	for i := 0; i <= surface; i++ {
+		if r := planet.Shells[i].InnerRadius; r > math.Abs(position) {
+			rings = append(rings, math.Sqrt(r*r-position*position))
+		}
+	}
+	rings = append(rings, math.Sqrt(radius*radius-position*position))
+
+	center := mesh.addVertex(position, 0, 0)
+	ringStart := make([]int, len(rings))
+	for k, rho := range rings {
+		ringStart[k] = mesh.addRing(position, rho, segments)
+	}
+
+	// Cut face - normals point along -axis, out of the kept half
+	innerRho := 0.0
+	for k, rho := range rings {
+		for s := 0; s < segments; s++ {
+			next := (s + 1) % segments
+			midRho := (innerRho + rho) / 2
+			midPhi := (float64(s) + 0.5) / float64(segments) * 2 * math.Pi
+			mat := materialAtPoint(planet, mesh.point(position, midRho*math.Cos(midPhi), midRho*math.Sin(midPhi)))
+
+			a, b := ringStart[k]+s, ringStart[k]+next
+			if k == 0 {
+				mesh.addFace(center, b, a, mat, true)
+				continue
+			}
+			pa, pb := ringStart[k-1]+s, ringStart[k-1]+next
+			mesh.addFace(pa, b, a, mat, true)
+			mesh.addFace(pa, pb, b, mat, true)
+		}
+		innerRho = rho
+	}
+
+	// Hemisphere cap around the +axis pole, closing onto the cut face rim
+	thetaMax := math.Acos(position / radius)
+	capRings := int(math.Ceil(thetaMax / (math.Pi / float64(surfaceShell.LatBands))))
+	if capRings < 1 {
+		capRings = 1
+	}
+	sampleRadius := (surfaceShell.InnerRadius + surfaceShell.OuterRadius) / 2
+
+	pole := mesh.addVertex(radius, 0, 0)
+	capStart := make([]int, capRings)
+	for j := 1; j < capRings; j++ {
+		theta := thetaMax * float64(j) / float64(capRings)
+		capStart[j-1] = mesh.addRing(radius*math.Cos(theta), radius*math.Sin(theta), segments)
+	}
+	capStart[capRings-1] = ringStart[len(rings)-1]
+
+	for j := 0; j < capRings; j++ {
+		midTheta := thetaMax * (float64(j) + 0.5) / float64(capRings)
+		for s := 0; s < segments; s++ {
+			next := (s + 1) % segments
+			midPhi := (float64(s) + 0.5) / float64(segments) * 2 * math.Pi
+			mat := materialAtPoint(planet, mesh.point(
+				sampleRadius*math.Cos(midTheta),
+				sampleRadius*math.Sin(midTheta)*math.Cos(midPhi),
+				sampleRadius*math.Sin(midTheta)*math.Sin(midPhi),
+			))
+
+			a, b := capStart[j]+s, capStart[j]+next
+			if j == 0 {
+				mesh.addFace(pole, a, b, mat, false)
+				continue
+			}
+			pa, pb := capStart[j-1]+s, capStart[j-1]+next
+			mesh.addFace(pa, a, b, mat, false)
+			mesh.addFace(pa, b, pb, mat, false)
+		}
+	}
+
+	return mesh, nil
+}
+
+// point maps a position along the cut axis and two in-plane coordinates to
+// Cartesian space, keeping (axis, u, v) right-handed
+func (m *CrossSectionMesh) point(along, u, v float64) Vector3 {
+	switch m.Axis {
+	case 0:
+		return Vector3{X: along, Y: u, Z: v}
+	case 1:
+		return Vector3{X: v, Y: along, Z: u}
+	default:
+		return Vector3{X: u, Y: v, Z: along}
+	}
+}
+
+// addVertex appends a vertex and returns its index
+func (m *CrossSectionMesh) addVertex(along, u, v float64) int {
+	m.Vertices = append(m.Vertices, m.point(along, u, v))
+	return len(m.Vertices) - 1
+}
+
+// addRing appends a circle of vertices around the axis and returns the first index
+func (m *CrossSectionMesh) addRing(along, rho float64, segments int) int {
+	start := len(m.Vertices)
+	for s := 0; s < segments; s++ {
+		phi := float64(s) / float64(segments) * 2 * math.Pi
+		m.addVertex(along, rho*math.Cos(phi), rho*math.Sin(phi))
+	}
+	return start
+}
+
+// addFace appends a triangle unless it is air
+func (m *CrossSectionMesh) addFace(a, b, c int, mat MaterialType, cut bool) {
+	if mat == MatAir {
+		return
+	}
+	m.Faces = append(m.Faces, MeshFace{V: [3]int{a, b, c}, Material: mat, Cut: cut})
+}
+
+// materialAtPoint returns the material of the voxel containing a Cartesian point
+// The inner core is not voxelized, so points inside it take the deepest shell's material
+func materialAtPoint(planet *VoxelPlanet, p Vector3) MaterialType {
+	geo := CartesianToGeographic(Cartesian{X: p.X, Y: p.Y, Z: p.Z}, planet.Radius, planet.Flattening)
+	alt := math.Max(geo.Alt, planet.Shells[0].InnerRadius-planet.Radius)
+
+	voxel, _ := planet.VoxelAtGeographic(RadiansToDegrees(geo.Lat), RadiansToDegrees(geo.Lon), alt)
+	if voxel == nil {
+		return MatAir
+	}
+	return voxel.Type
+}
+
+// WriteOBJ writes the mesh as a Wavefront OBJ file with a companion .mtl
+// Faces are grouped by material so each region is a separate colored surface.
+// Both files are written with WriteFileAtomic
+func (m *CrossSectionMesh) WriteOBJ(path string) error {
+	mtlPath := strings.TrimSuffix(path, filepath.Ext(path)) + ".mtl"
+	if err := m.writeMTL(mtlPath); err != nil {
+		return err
+	}
+
+	err := WriteFileAtomic(path, func(file io.Writer) error {
+		w := bufio.NewWriter(file)
+		fmt.Fprintf(w, "# Planet cross-section, axis %d at %.0f m\n", m.Axis, m.Position)
+		fmt.Fprintf(w, "mtllib %s\n", filepath.Base(mtlPath))
+
+		for _, v := range m.Vertices {
+			fmt.Fprintf(w, "v %.3f %.3f %.3f\n", v.X, v.Y, v.Z)
+		}
+
+		// Cut face regions first, then the surface, each split by material
+		for _, cut := range []bool{true, false} {
+			for _, mat := range m.materials() {
+				var group []MeshFace
+				for _, face := range m.Faces {
+					if face.Cut == cut && face.Material == mat {
+						group = append(group, face)
+					}
+				}
+				if len(group) == 0 {
+					continue
+				}
+
+				prefix := "surface"
+				if cut {
+					prefix = "cut"
+				}
+				fmt.Fprintf(w, "g %s_%s\nusemtl %s\n", prefix, MaterialName(mat), MaterialName(mat))
+				for _, face := range group {
+					// OBJ indices are 1-based
+					fmt.Fprintf(w, "f %d %d %d\n", face.V[0]+1, face.V[1]+1, face.V[2]+1)
+				}
+			}
+		}
+		return w.Flush()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write OBJ file: %v", err)
+	}
+	return nil
+}
+
+// writeMTL writes a diffuse color for every material used by the mesh
+func (m *CrossSectionMesh) writeMTL(path string) error {
+	err := WriteFileAtomic(path, func(file io.Writer) error {
+		w := bufio.NewWriter(file)
+		for _, mat := range m.materials() {
+			color := MaterialColor(mat)
+			fmt.Fprintf(w, "newmtl %s\nKd %.3f %.3f %.3f\n\n", MaterialName(mat), color.X, color.Y, color.Z)
+		}
+		return w.Flush()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write MTL file: %v", err)
+	}
+	return nil
+}
+
+// materials returns the distinct materials used by the mesh in type order
+func (m *CrossSectionMesh) materials() []MaterialType {
+	seen := make(map[MaterialType]bool)
+	var mats []MaterialType
+	for _, face := range m.Faces {
+		if !seen[face.Material] {
+			seen[face.Material] = true
+			mats = append(mats, face.Material)
+		}
+	}
+	sort.Slice(mats, func(i, j int) bool { return mats[i] < mats[j] })
+	return mats
+}
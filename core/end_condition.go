@@ -0,0 +1,91 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EndCondition stops a run once a planet statistic compares true against a
+// threshold, as in "until:continents==1" for a reassembled supercontinent
+type EndCondition struct {
+	Stat      string  // Name in endConditionStats
+	Op        string  // One of endConditionOps
+	Threshold float64 // Value the statistic is compared against
+}
+
+// endConditionStats are the statistics an end condition can test
+var endConditionStats = map[string]func(planet *VoxelPlanet) float64{
+	"year":       func(planet *VoxelPlanet) float64 { return planet.Time },
+	"seaLevel":   func(planet *VoxelPlanet) float64 { return planet.SeaLevel },
+	"continents": func(planet *VoxelPlanet) float64 { return float64(len(IdentifyContinents(planet))) },
+}
+
+// endConditionOps are the comparisons, two-character operators first so
+// ">=" is not read as ">"
+var endConditionOps = []string{">=", "<=", "==", "!=", ">", "<"}
+
+// ParseEndCondition parses an expression like "until:seaLevel>50" or
+// "year>=1e9". The "until:" prefix is optional
+func ParseEndCondition(s string) (*EndCondition, error) {
+	expr := strings.TrimPrefix(strings.TrimSpace(s), "until:")
+
+	for _, op := range endConditionOps {
+		idx := strings.Index(expr, op)
+		if idx < 0 {
+			continue
+		}
+		stat := strings.TrimSpace(expr[:idx])
+		if _, ok := endConditionStats[stat]; !ok {
+			return nil, fmt.Errorf("end condition %q: unknown statistic %q (want %s)", s, stat, endConditionStatNames())
+		}
+		threshold, err := strconv.ParseFloat(strings.TrimSpace(expr[idx+len(op):]), 64)
+		if err != nil {
+			return nil, fmt.Errorf("end condition %q: bad threshold: %v", s, err)
+		}
+		return &EndCondition{Stat: stat, Op: op, Threshold: threshold}, nil
+	}
+
+	return nil, fmt.Errorf("end condition %q: no comparison (want one of %s)", s, strings.Join(endConditionOps, " "))
+}
+
+// endConditionStatNames lists the statistics for error messages
+func endConditionStatNames() string {
+	names := make([]string, 0, len(endConditionStats))
+	for name := range endConditionStats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// Value returns the statistic the condition tests, measured on the planet
+func (c *EndCondition) Value(planet *VoxelPlanet) float64 {
+	return endConditionStats[c.Stat](planet)
+}
+
+// Met reports whether the planet satisfies the condition
+func (c *EndCondition) Met(planet *VoxelPlanet) bool {
+	value := c.Value(planet)
+	switch c.Op {
+	case ">=":
+		return value >= c.Threshold
+	case "<=":
+		return value <= c.Threshold
+	case "==":
+		return value == c.Threshold
+	case "!=":
+		return value != c.Threshold
+	case ">":
+		return value > c.Threshold
+	case "<":
+		return value < c.Threshold
+	}
+	return false
+}
+
+// String formats the condition the way it is written on the command line
+func (c *EndCondition) String() string {
+	return "until:" + c.Stat + c.Op + strconv.FormatFloat(c.Threshold, 'g', -1, 64)
+}
@@ -0,0 +1,39 @@
+package core
+
+import "math"
+
+// EarthFlattening is Earth's polar flattening (a-b)/a, its poles about 21 km
+// closer to the center than its equator
+const EarthFlattening = 1 / 298.257
+
+// ShellRadiusAt returns how far from the center a shell boundary of
+// equatorial radius r lies at geodetic latitude latDeg. Every shell of a
+// flattened planet is the same spheroid scaled, so the shells keep their
+// thickness ratios while closing in toward the poles; on a sphere it is r
+func (p *VoxelPlanet) ShellRadiusAt(r, latDeg float64) float64 {
+	if p.Flattening == 0 {
+		return r
+	}
+	c := GeographicToCartesian(Geographic{Lat: DegreesToRadians(latDeg)}, r, p.Flattening)
+	return math.Hypot(c.X, c.Y)
+}
+
+// ShellAltitude returns the altitude above the surface, as VoxelAtGeographic
+// takes it, of the shell boundary of equatorial radius r at latitude latDeg
+func (p *VoxelPlanet) ShellAltitude(r, latDeg float64) float64 {
+	return p.ShellRadiusAt(r, latDeg) - p.ShellRadiusAt(p.Radius, latDeg)
+}
+
+// spheroidBandExtent integrates a spheroid's surface from the equator to
+// geodetic latitude lat (radians), in units of 2πa² for equatorial radius a,
+// so the difference between two latitudes times 2πa² is the area of the band
+// between them. On a sphere it is sin(lat)
+func spheroidBandExtent(lat, flattening float64) float64 {
+	s := math.Sin(lat)
+	if flattening == 0 {
+		return s
+	}
+	e2 := flattening * (2 - flattening)
+	e := math.Sqrt(e2)
+	return (1 - e2) * (s/(2*(1-e2*s*s)) + math.Atanh(e*s)/(2*e))
+}
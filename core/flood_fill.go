@@ -0,0 +1,77 @@
+package core
+
+// SphericalFloodFill returns the connected region of accepted voxels containing start
+// Connectivity follows ShellNeighbors, so regions wrap in longitude, join bands
+// of different resolution and meet across the poles
+// Returns nil if the start voxel is not accepted
+func SphericalFloodFill(shell *SphericalShell, start VoxelCoord, accept func(*VoxelMaterial) bool) []VoxelCoord {
+	if start.Lat < 0 || start.Lat >= shell.LatBands {
+		return nil
+	}
+	lonCount := shell.LonCounts[start.Lat]
+	start.Lon = ((start.Lon % lonCount) + lonCount) % lonCount
+	if !accept(&shell.Voxels[start.Lat][start.Lon]) {
+		return nil
+	}
+
+	// Sparse visited set keeps small regions cheap on large shells
+	visited := map[[2]int]bool{{start.Lat, start.Lon}: true}
+
+	var region []VoxelCoord
+	queue := []VoxelCoord{start}
+
+	for len(queue) > 0 {
+		coord := queue[0]
+		queue = queue[1:]
+		region = append(region, coord)
+
+		for _, n := range ShellNeighbors(shell, coord) {
+			key := [2]int{n.Lat, n.Lon}
+			if visited[key] || !accept(&shell.Voxels[n.Lat][n.Lon]) {
+				continue
+			}
+			visited[key] = true
+			queue = append(queue, n)
+		}
+	}
+
+	return region
+}
+
+// ShellNeighbors returns the voxels sharing an edge with coord in the same shell
+// Longitude wraps around, bands with different longitude counts are matched by
+// overlapping extent, and all voxels of a polar band meet at the pole
+func ShellNeighbors(shell *SphericalShell, coord VoxelCoord) []VoxelCoord {
+	var neighbors []VoxelCoord
+	lonCount := shell.LonCounts[coord.Lat]
+
+	// East and west with wrapping
+	neighbors = append(neighbors,
+		VoxelCoord{Shell: coord.Shell, Lat: coord.Lat, Lon: (coord.Lon + 1) % lonCount},
+		VoxelCoord{Shell: coord.Shell, Lat: coord.Lat, Lon: (coord.Lon - 1 + lonCount) % lonCount},
+	)
+
+	// North and south - every voxel whose longitude range overlaps this one
+	for _, lat := range []int{coord.Lat - 1, coord.Lat + 1} {
+		if lat < 0 || lat >= shell.LatBands {
+			continue
+		}
+		otherCount := shell.LonCounts[lat]
+		start := coord.Lon * otherCount / lonCount
+		end := ((coord.Lon+1)*otherCount - 1) / lonCount
+		for lon := start; lon <= end; lon++ {
+			neighbors = append(neighbors, VoxelCoord{Shell: coord.Shell, Lat: lat, Lon: lon % otherCount})
+		}
+	}
+
+	// Polar caps - the whole band touches the pole
+	if coord.Lat == 0 || coord.Lat == shell.LatBands-1 {
+		for lon := 0; lon < lonCount; lon++ {
+			if lon != coord.Lon {
+				neighbors = append(neighbors, VoxelCoord{Shell: coord.Shell, Lat: coord.Lat, Lon: lon})
+			}
+		}
+	}
+
+	return neighbors
+}
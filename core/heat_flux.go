@@ -0,0 +1,33 @@
+package core
+
+// HeatFlux returns the conductive heat flux in W/m² rising into shell
+// shellIdx from the shell below at lat/lon in degrees, from the temperature
+// difference between the two voxels over the distance between shell centers
+// The lower voxel's conductivity is used, since the heat is conducted out of it
+// Positive values mean heat flows upward; the innermost shell returns 0
+func (p *VoxelPlanet) HeatFlux(shellIdx int, lat, lon float64) float64 {
+	if shellIdx <= 0 || shellIdx >= len(p.Shells) {
+		return 0
+	}
+	upper := &p.Shells[shellIdx]
+	lower := &p.Shells[shellIdx-1]
+
+	upperLat, upperLon := upper.indexAt(lat, lon)
+	lowerLat, lowerLon := lower.indexAt(lat, lon)
+	top := &upper.Voxels[upperLat][upperLon]
+	bottom := &lower.Voxels[lowerLat][lowerLon]
+
+	dr := (upper.InnerRadius+upper.OuterRadius)/2 - (lower.InnerRadius+lower.OuterRadius)/2
+	if dr <= 0 {
+		return 0
+	}
+
+	k := float64(MaterialProperties[bottom.Type].ThermalConductivity)
+	return k * float64(bottom.Temperature-top.Temperature) / dr
+}
+
+// SurfaceHeatFlux returns the heat flux in W/m² into the surface shell at
+// lat/lon in degrees, the model's equivalent of measured surface heat flow
+func (p *VoxelPlanet) SurfaceHeatFlux(lat, lon float64) float64 {
+	return p.HeatFlux(len(p.Shells)-2, lat, lon)
+}
@@ -0,0 +1,98 @@
+package core
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+)
+
+// HypsometryBin is one point of a cumulative hypsometric curve
+type HypsometryBin struct {
+	Elevation float64 // Upper edge of the bin in meters
+	Fraction  float64 // Fraction of surface area at or below Elevation
+}
+
+// HypsometricCurve returns the fraction of surface area at or below each of
+// bins evenly spaced elevations spanning the surface shell
+// Voxels are weighted by their true area, which shrinks toward the poles
+func HypsometricCurve(planet *VoxelPlanet, bins int) []HypsometryBin {
+	if bins <= 0 || len(planet.Shells) < 2 {
+		return nil
+	}
+	shell := &planet.Shells[len(planet.Shells)-2]
+
+	// Gather elevations and per-voxel area weights
+	var elevations, weights []float64
+	minElev, maxElev := math.Inf(1), math.Inf(-1)
+	totalWeight := 0.0
+	for latIdx := range shell.Voxels {
+		weight := planet.VoxelArea(shell, latIdx)
+
+		for lonIdx := range shell.Voxels[latIdx] {
+			voxel := &shell.Voxels[latIdx][lonIdx]
+			if voxel.Type == MatAir {
+				continue
+			}
+			elev := float64(voxel.Elevation)
+			elevations = append(elevations, elev)
+			weights = append(weights, weight)
+			totalWeight += weight
+			minElev = math.Min(minElev, elev)
+			maxElev = math.Max(maxElev, elev)
+		}
+	}
+	if totalWeight == 0 {
+		return nil
+	}
+
+	// Accumulate area per bin, then take the running total
+	binWidth := (maxElev - minElev) / float64(bins)
+	area := make([]float64, bins)
+	for i, elev := range elevations {
+		bin := bins - 1
+		if binWidth > 0 {
+			bin = int((elev - minElev) / binWidth)
+			if bin >= bins {
+				bin = bins - 1 // Max elevation sits on the last edge
+			}
+		}
+		area[bin] += weights[i]
+	}
+
+	curve := make([]HypsometryBin, bins)
+	cumulative := 0.0
+	for i := range curve {
+		cumulative += area[i]
+		curve[i] = HypsometryBin{
+			Elevation: minElev + float64(i+1)*binWidth,
+			Fraction:  cumulative / totalWeight,
+		}
+	}
+	curve[bins-1].Elevation = maxElev
+
+	return curve
+}
+
+// WriteHypsometryCSV writes a hypsometric curve as elevation_m,fraction rows
+// The file is written with WriteFileAtomic, so an interrupted run never
+// leaves a truncated CSV behind
+func WriteHypsometryCSV(path string, curve []HypsometryBin) error {
+	err := WriteFileAtomic(path, func(file io.Writer) error {
+		w := csv.NewWriter(file)
+		w.Write([]string{"elevation_m", "fraction"})
+		for _, bin := range curve {
+			w.Write([]string{
+				strconv.FormatFloat(bin.Elevation, 'f', 1, 64),
+				strconv.FormatFloat(bin.Fraction, 'f', 6, 64),
+			})
+		}
+		w.Flush()
+		return w.Error()
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write hypsometry file: %v", err)
+	}
+	return nil
+}
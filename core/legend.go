@@ -0,0 +1,188 @@
+package core
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Legend layout in pixels
+const (
+	legendPadding    = 10
+	legendGlyphScale = 2 // Each font pixel is drawn this many pixels wide
+	legendSwatch     = 20
+	legendRowHeight  = 26
+	legendStripWidth = 256
+	legendStripTall  = 24
+)
+
+// legendFont is a 3x5 pixel font for legend text; lowercase letters are
+// drawn as capitals and missing characters as spaces
+var legendFont = map[rune][5]string{
+	'A': {"###", "#.#", "###", "#.#", "#.#"},
+	'B': {"##.", "#.#", "##.", "#.#", "##."},
+	'C': {"###", "#..", "#..", "#..", "###"},
+	'D': {"##.", "#.#", "#.#", "#.#", "##."},
+	'E': {"###", "#..", "##.", "#..", "###"},
+	'F': {"###", "#..", "##.", "#..", "#.."},
+	'G': {"###", "#..", "#.#", "#.#", "###"},
+	'H': {"#.#", "#.#", "###", "#.#", "#.#"},
+	'I': {"###", ".#.", ".#.", ".#.", "###"},
+	'J': {"..#", "..#", "..#", "#.#", "###"},
+	'K': {"#.#", "#.#", "##.", "#.#", "#.#"},
+	'L': {"#..", "#..", "#..", "#..", "###"},
+	'M': {"#.#", "###", "###", "#.#", "#.#"},
+	'N': {"##.", "#.#", "#.#", "#.#", "#.#"},
+	'O': {"###", "#.#", "#.#", "#.#", "###"},
+	'P': {"###", "#.#", "###", "#..", "#.."},
+	'Q': {"###", "#.#", "#.#", "###", "..#"},
+	'R': {"###", "#.#", "##.", "#.#", "#.#"},
+	'S': {"###", "#..", "###", "..#", "###"},
+	'T': {"###", ".#.", ".#.", ".#.", ".#."},
+	'U': {"#.#", "#.#", "#.#", "#.#", "###"},
+	'V': {"#.#", "#.#", "#.#", "#.#", ".#."},
+	'W': {"#.#", "#.#", "###", "###", "#.#"},
+	'X': {"#.#", "#.#", ".#.", "#.#", "#.#"},
+	'Y': {"#.#", "#.#", ".#.", ".#.", ".#."},
+	'Z': {"###", "..#", ".#.", "#..", "###"},
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"###", "..#", "###", "#..", "###"},
+	'3': {"###", "..#", "###", "..#", "###"},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "###", "..#", "###"},
+	'6': {"###", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", "..#", "..#", "..#"},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "###"},
+	'-': {"...", "...", "###", "...", "..."},
+	'_': {"...", "...", "...", "...", "###"},
+	'.': {"...", "...", "...", "...", ".#."},
+	'(': {".#.", "#..", "#..", "#..", ".#."},
+	')': {".#.", "..#", "..#", "..#", ".#."},
+}
+
+// legendTextWidth returns how wide drawLegendText draws text
+func legendTextWidth(text string) int {
+	return len([]rune(text)) * 4 * legendGlyphScale
+}
+
+// legendTextHeight is the height of a line of legend text
+const legendTextHeight = 5 * legendGlyphScale
+
+// drawLegendText draws text with its top left corner at x, y
+func drawLegendText(img *image.RGBA, x, y int, text string, c color.Color) {
+	for _, r := range strings.ToUpper(text) {
+		glyph := legendFont[r]
+		for row, bits := range glyph {
+			for col, bit := range bits {
+				if bit != '#' {
+					continue
+				}
+				px := x + col*legendGlyphScale
+				py := y + row*legendGlyphScale
+				draw.Draw(img, image.Rect(px, py, px+legendGlyphScale, py+legendGlyphScale),
+					image.NewUniform(c), image.Point{}, draw.Src)
+			}
+		}
+		x += 4 * legendGlyphScale
+	}
+}
+
+// legendColor converts a display color to 8 bits per channel
+func legendColor(c Vector3) color.RGBA {
+	channel := func(v float64) uint8 {
+		return uint8(math.Max(0, math.Min(1, v))*255 + 0.5)
+	}
+	return color.RGBA{R: channel(c.X), G: channel(c.Y), B: channel(c.Z), A: 255}
+}
+
+// newLegendImage returns a white legend canvas
+func newLegendImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	return img
+}
+
+// materialLegend draws a swatch in materialColor and name for every material
+func materialLegend(materialColor func(MaterialType) Vector3) *image.RGBA {
+	const title = "Materials"
+	width := legendTextWidth(title)
+	for mat := 0; mat < MaterialCount(); mat++ {
+		width = max(width, legendSwatch+legendPadding+legendTextWidth(MaterialName(MaterialType(mat))))
+	}
+	top := legendPadding + legendTextHeight + legendPadding
+	img := newLegendImage(width+2*legendPadding, top+MaterialCount()*legendRowHeight+legendPadding)
+
+	drawLegendText(img, legendPadding, legendPadding, title, color.Black)
+	for mat := 0; mat < MaterialCount(); mat++ {
+		y := top + mat*legendRowHeight
+		swatch := image.Rect(legendPadding, y, legendPadding+legendSwatch, y+legendSwatch)
+		draw.Draw(img, swatch, image.Black, image.Point{}, draw.Src)
+		draw.Draw(img, swatch.Inset(1), image.NewUniform(legendColor(materialColor(MaterialType(mat)))), image.Point{}, draw.Src)
+		drawLegendText(img, legendPadding+legendSwatch+legendPadding, y+(legendSwatch-legendTextHeight)/2,
+			MaterialName(MaterialType(mat)), color.Black)
+	}
+	return img
+}
+
+// colormapLegend draws a colormap as a strip labelled with the values at its
+// ends and middle
+func colormapLegend(c *Colormap) *image.RGBA {
+	width := max(legendStripWidth, legendTextWidth(c.Title))
+	stripTop := legendPadding + legendTextHeight + legendPadding
+	labelTop := stripTop + legendStripTall + legendPadding/2
+	img := newLegendImage(width+2*legendPadding, labelTop+legendTextHeight+legendPadding)
+
+	drawLegendText(img, legendPadding, legendPadding, c.Title, color.Black)
+
+	min, max := c.Range()
+	for x := 0; x < legendStripWidth; x++ {
+		value := min + (max-min)*(float64(x)+0.5)/legendStripWidth
+		column := image.Rect(legendPadding+x, stripTop, legendPadding+x+1, stripTop+legendStripTall)
+		draw.Draw(img, column, image.NewUniform(legendColor(c.Color(value))), image.Point{}, draw.Src)
+	}
+
+	label := func(value float64) string {
+		return strconv.FormatFloat(value/c.LabelScale, 'f', -1, 64)
+	}
+	middle := label((min + max) / 2)
+	drawLegendText(img, legendPadding, labelTop, label(min), color.Black)
+	drawLegendText(img, legendPadding+(legendStripWidth-legendTextWidth(middle))/2, labelTop, middle, color.Black)
+	drawLegendText(img, legendPadding+legendStripWidth-legendTextWidth(label(max)), labelTop, label(max), color.Black)
+	return img
+}
+
+// ExportLegend writes a PNG legend for a render mode: a swatch and name per
+// material for the material view, or a labelled color strip for the views
+// shaded by a colormap. Colors come from the same tables the renderer
+// uploads, so figures match screenshots; materialColor is the material
+// view's palette, nil for the natural MaterialColor
+func ExportLegend(mode RenderMode, path string, materialColor func(MaterialType) Vector3) error {
+	if materialColor == nil {
+		materialColor = MaterialColor
+	}
+
+	var img *image.RGBA
+	if mode == RenderMaterial {
+		img = materialLegend(materialColor)
+	} else if c := ColormapFor(mode); c != nil {
+		img = colormapLegend(c)
+	} else {
+		return fmt.Errorf("render mode %d has no legend", mode)
+	}
+
+	err := WriteFileAtomic(path, func(w io.Writer) error {
+		return png.Encode(w, img)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write legend file: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestColormapStops checks colors at, between and beyond the stops, and the
+// hard coastline edge made by two stops at sea level
+func TestColormapStops(t *testing.T) {
+	c := &TemperatureColormap
+	if got := c.Color(0); got != c.Stops[0].Color {
+		t.Errorf("below range: %v, want %v", got, c.Stops[0].Color)
+	}
+	if got := c.Color(1e6); got != c.Stops[1].Color {
+		t.Errorf("above range: %v, want %v", got, c.Stops[1].Color)
+	}
+	if got := c.Color(1773); got.Sub(Vector3{X: 0.5, Z: 0.5}).Length() > 1e-9 {
+		t.Errorf("midpoint: %v, want halfway from blue to red", got)
+	}
+
+	sea := ElevationColormap.Color(-0.001)
+	land := ElevationColormap.Color(0)
+	if sea.Sub(Vector3{X: 0.3, Y: 0.6, Z: 0.85}).Length() > 1e-3 {
+		t.Errorf("just below sea level: %v, want shelf blue", sea)
+	}
+	if land != (Vector3{X: 0.76, Y: 0.7, Z: 0.5}) {
+		t.Errorf("sea level: %v, want beach", land)
+	}
+
+	table := ElevationColormap.Table(5)
+	if len(table) != 15 {
+		t.Fatalf("table has %d floats, want 15", len(table))
+	}
+	last := ElevationColormap.Stops[len(ElevationColormap.Stops)-1].Color
+	if table[12] != float32(last.X) || table[14] != float32(last.Z) {
+		t.Errorf("table ends at %v, want the last stop %v", table[12:], last)
+	}
+}
+
+// TestExportLegend writes each legend and checks a colormap strip is drawn
+// with Colormap.Color
+func TestExportLegend(t *testing.T) {
+	dir := t.TempDir()
+	for _, mode := range []RenderMode{RenderMaterial, RenderTemperature, RenderAge, RenderElevation} {
+		path := filepath.Join(dir, "legend.png")
+		if err := ExportLegend(mode, path, nil); err != nil {
+			t.Fatalf("mode %d: %v", mode, err)
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		img, err := png.Decode(file)
+		file.Close()
+		if err != nil {
+			t.Fatalf("mode %d: %v", mode, err)
+		}
+
+		if mode != RenderAge {
+			continue
+		}
+		// Leftmost strip column is the youngest crust
+		stripTop := legendPadding + legendTextHeight + legendPadding
+		min, max := AgeColormap.Range()
+		want := legendColor(AgeColormap.Color(min + (max-min)*0.5/legendStripWidth))
+		r, g, b, _ := img.At(legendPadding, stripTop+legendStripTall/2).RGBA()
+		if uint8(r>>8) != want.R || uint8(g>>8) != want.G || uint8(b>>8) != want.B {
+			t.Errorf("age strip starts %d,%d,%d, want %v", r>>8, g>>8, b>>8, want)
+		}
+	}
+
+	if err := ExportLegend(RenderPlates, filepath.Join(dir, "plates.png"), nil); err == nil {
+		t.Error("plate view has no colormap but a legend was written")
+	}
+}
+
+// TestExportLegendPalette checks the material legend draws its swatches in
+// the palette it is given
+func TestExportLegendPalette(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legend.png")
+	magenta := Vector3{X: 1, Z: 1}
+	if err := ExportLegend(RenderMaterial, path, func(MaterialType) Vector3 { return magenta }); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Middle of the first swatch
+	top := legendPadding + legendTextHeight + legendPadding
+	r, g, b, _ := img.At(legendPadding+legendSwatch/2, top+legendSwatch/2).RGBA()
+	if r>>8 != 255 || g>>8 != 0 || b>>8 != 255 {
+		t.Errorf("swatch is %d,%d,%d, want the palette's magenta", r>>8, g>>8, b>>8)
+	}
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxMaterials bounds the material table so its colors fit the renderer's
+// uniforms; it must match MAX_MATERIALS in the ray march shader
+const MaxMaterials = 64
+
+// firstCustomMaterial is the type handed out by the first RegisterMaterial call
+const firstCustomMaterial = MatEclogite + 1
+
+// materialCount is one past the highest material type in use
+var materialCount = int(firstCustomMaterial)
+
+// builtinMaterialNames names the compiled-in materials
+var builtinMaterialNames = map[MaterialType]string{
+	MatAir:        "air",
+	MatWater:      "water",
+	MatBasalt:     "basalt",
+	MatGranite:    "granite",
+	MatPeridotite: "peridotite",
+	MatMagma:      "magma",
+	MatSediment:   "sediment",
+	MatIce:        "ice",
+	MatSand:       "sand",
+	MatEclogite:   "eclogite",
+}
+
+// builtinMaterialColors are natural diffuse colors for the compiled-in materials
+var builtinMaterialColors = map[MaterialType]Vector3{
+	MatAir:        {X: 0.7, Y: 0.8, Z: 1.0},
+	MatWater:      {X: 0.1, Y: 0.3, Z: 0.6},
+	MatBasalt:     {X: 0.5, Y: 0.5, Z: 0.5},
+	MatGranite:    {X: 0.6, Y: 0.5, Z: 0.4},
+	MatPeridotite: {X: 0.5, Y: 0.4, Z: 0.3},
+	MatMagma:      {X: 1.0, Y: 0.3, Z: 0.0},
+	MatSediment:   {X: 0.9, Y: 0.8, Z: 0.6},
+	MatIce:        {X: 0.95, Y: 0.95, Z: 1.0},
+	MatSand:       {X: 0.8, Y: 0.7, Z: 0.5},
+	MatEclogite:   {X: 0.4, Y: 0.5, Z: 0.35},
+}
+
+// RegisterMaterial adds a material to MaterialProperties and returns its type
+// Register materials before the simulation starts: the table is read without
+// locking by the physics thread. Panics once MaxMaterials are in use
+func RegisterMaterial(props MaterialProps) MaterialType {
+	if materialCount >= MaxMaterials {
+		panic(fmt.Sprintf("cannot register material %q: all %d material types are in use", props.Name, MaxMaterials))
+	}
+
+	mat := MaterialType(materialCount)
+	materialCount++
+	if props.Name == "" {
+		props.Name = fmt.Sprintf("material_%d", mat)
+	}
+	MaterialProperties[mat] = props
+	return mat
+}
+
+// UnregisterMaterials drops every material registered after the table held
+// count types, so a test can put back the table it found. Built-in materials
+// are never dropped
+func UnregisterMaterials(count int) {
+	if count < int(firstCustomMaterial) {
+		count = int(firstCustomMaterial)
+	}
+	for ; materialCount > count; materialCount-- {
+		delete(MaterialProperties, MaterialType(materialCount-1))
+	}
+}
+
+// MaterialCount returns one past the highest registered material type, the
+// size of any table indexed by MaterialType
+func MaterialCount() int {
+	return materialCount
+}
+
+// IsCustomMaterial reports whether mat was added with RegisterMaterial
+func IsCustomMaterial(mat MaterialType) bool {
+	return mat >= firstCustomMaterial && int(mat) < materialCount
+}
+
+// MaterialName returns a lowercase, file-safe name for a material
+func MaterialName(mat MaterialType) string {
+	if name, ok := builtinMaterialNames[mat]; ok {
+		return name
+	}
+	if props, ok := MaterialProperties[mat]; ok && IsCustomMaterial(mat) {
+		return strings.ToLower(strings.ReplaceAll(props.Name, " ", "_"))
+	}
+	return fmt.Sprintf("material_%d", mat)
+}
+
+// MaterialColor returns the display color of a material
+func MaterialColor(mat MaterialType) Vector3 {
+	if color, ok := builtinMaterialColors[mat]; ok {
+		return color
+	}
+	if IsCustomMaterial(mat) {
+		return MaterialProperties[mat].Color
+	}
+	return Vector3{X: 1, Y: 0, Z: 1} // Magenta marks unknown materials
+}
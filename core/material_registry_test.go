@@ -0,0 +1,30 @@
+package core
+
+import "testing"
+
+// TestUnregisterMaterials registers two materials and checks unregistering
+// puts the table back as it was, reusing the freed types
+func TestUnregisterMaterials(t *testing.T) {
+	count := MaterialCount()
+	t.Cleanup(func() { UnregisterMaterials(count) })
+
+	first := RegisterMaterial(MaterialProps{Name: "First", DefaultDensity: 1000})
+	RegisterMaterial(MaterialProps{Name: "Second", DefaultDensity: 2000})
+	if MaterialCount() != count+2 {
+		t.Fatalf("%d types after registering two, want %d", MaterialCount(), count+2)
+	}
+
+	UnregisterMaterials(count)
+	if MaterialCount() != count {
+		t.Errorf("%d types after unregistering, want %d", MaterialCount(), count)
+	}
+	if IsCustomMaterial(first) {
+		t.Errorf("type %d still custom after unregistering", first)
+	}
+	if _, ok := MaterialProperties[first]; ok {
+		t.Errorf("type %d still has properties after unregistering", first)
+	}
+	if again := RegisterMaterial(MaterialProps{Name: "Third"}); again != first {
+		t.Errorf("re-registered as type %d, want freed type %d", again, first)
+	}
+}
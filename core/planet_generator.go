@@ -4,8 +4,13 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"sort"
 )
 
+// GreenhouseOff is the GreenhouseStrength that switches the greenhouse off,
+// since 0 leaves physics to use Earth's
+const GreenhouseOff = -1.0
+
 // PlanetGenerationParams controls random planet generation
 type PlanetGenerationParams struct {
 	Seed               int64
@@ -14,15 +19,94 @@ type PlanetGenerationParams struct {
 	MinContinentSize   float64 // Minimum size as fraction of surface
 	MaxContinentSize   float64 // Maximum size as fraction of surface
 	ContinentRoughness float64 // How irregular continent shapes are (0=smooth, 1=very rough)
+
+	// Core-mantle boundary condition
+	CoreBoundary    CoreBoundaryMode
+	CoreTemperature float64 // Kelvin at the core-mantle boundary
+	CoreHeatFlux    float64 // W/m² (Earth is ~0.1)
+
+	// Subduction geometry
+	SlabDip float64 // Degrees (Earth's slabs range ~20-70)
+
+	// Surface stability
+	MaxElevationRate float64 // m/year (fast orogens are ~0.01)
+
+	// Plate topology
+	MaxPlates int // Earth has ~15 major and minor plates
+
+	// Baseline mantle flow
+	ConvectionForcing float64 // Minimum plate speed in cm/year (Earth's plates move ~2-10)
+	MaxPlateVelocity  float64 // Maximum plate speed in cm/year (0 = DefaultMaxPlateVelocity)
+
+	// Atmosphere
+	GreenhouseStrength float64 // Emissivity (Earth is ~0.78, 0 = Earth's, GreenhouseOff = none)
+
+	// Radioactive heating
+	InitialRadiogenicHeat float64 // K per year in the deep interior at year 0 (0 = DefaultRadiogenicHeat)
+	HeatHalfLifeYears     float64 // Years for heat production to halve (0 = constant heating)
+
+	// Spin
+	AxialTilt           float64 // Degrees (0 = DefaultAxialTilt, NoAxialTilt = upright)
+	RotationRate        float64 // Radians per second (0 = DefaultRotationRate)
+	RotationPeriodHours float64 // Length of the sidereal day, overriding RotationRate (0 = use RotationRate)
+
+	// Shape
+	Flattening float64 // Polar flattening (a-b)/a, the radius given at the equator (Earth is ~0.00335, 0 = sphere)
+
+	// Grid resolution
+	SurfaceBands    int // Latitude bands in the surface shell (0 = DefaultSurfaceBands)
+	SurfaceLatBands int   // Latitude bands of the surface and atmosphere alone, above the interior's SurfaceBands (0 = SurfaceBands)
+	LatBands        []int // Latitude bands of every shell from the core out, e.g. a coarse mantle under a fine crust (nil = the SurfaceBands schedule)
 }
 
-// CreateRandomizedPlanet creates a planet with randomly placed continents
-func CreateRandomizedPlanet(radius float64, shellCount int, params PlanetGenerationParams) *VoxelPlanet {
+// CreateRandomizedPlanet creates a planet with randomly placed continents,
+// failing on parameters Validate rejects
+func CreateRandomizedPlanet(radius float64, shellCount int, params PlanetGenerationParams) (*VoxelPlanet, error) {
+	if err := params.Validate(shellCount); err != nil {
+		return nil, err
+	}
+
 	// Initialize random generator with seed
 	rng := rand.New(rand.NewSource(params.Seed))
 
 	// Create base planet structure
-	planet := CreateVoxelPlanet(radius, shellCount)
+	schedule := params.LatBands
+	if schedule == nil {
+		surfaceBands := params.SurfaceBands
+		if surfaceBands <= 0 {
+			surfaceBands = DefaultSurfaceBands
+		}
+		surfaceLatBands := params.SurfaceLatBands
+		if surfaceLatBands <= 0 {
+			surfaceLatBands = surfaceBands
+		}
+		schedule = DefaultShellLatBands(shellCount, surfaceBands, surfaceLatBands)
+	}
+	planet := CreateVoxelPlanetWithShellBands(radius, schedule)
+	planet.SetSeed(params.Seed)
+	planet.CoreBoundary = params.CoreBoundary
+	planet.CoreTemperature = params.CoreTemperature
+	planet.CoreHeatFlux = params.CoreHeatFlux
+	planet.SlabDip = params.SlabDip
+	planet.MaxElevationRate = params.MaxElevationRate
+	planet.MaxPlates = params.MaxPlates
+	planet.ConvectionForcing = params.ConvectionForcing
+	planet.MaxPlateVelocity = params.MaxPlateVelocity
+	planet.GreenhouseStrength = params.GreenhouseStrength
+	planet.InitialRadiogenicHeat = params.InitialRadiogenicHeat
+	planet.HeatHalfLifeYears = params.HeatHalfLifeYears
+	planet.Flattening = params.Flattening
+	if params.AxialTilt == NoAxialTilt {
+		planet.AxialTilt = 0
+	} else if params.AxialTilt > 0 {
+		planet.AxialTilt = params.AxialTilt
+	}
+	if params.RotationRate > 0 {
+		planet.RotationRate = params.RotationRate
+	}
+	if params.RotationPeriodHours > 0 {
+		planet.RotationRate = RotationRateForPeriod(params.RotationPeriodHours)
+	}
 
 	// Generate random continents on the surface
 	generateRandomContinents(planet, rng, params)
@@ -30,7 +114,7 @@ func CreateRandomizedPlanet(radius float64, shellCount int, params PlanetGenerat
 	// Add initial plate velocities with random patterns
 	addRandomPlateVelocities(planet, rng)
 
-	return planet
+	return planet, nil
 }
 
 // generateRandomContinents creates randomly positioned continental masses
@@ -45,7 +129,7 @@ func generateRandomContinents(planet *VoxelPlanet, rng *rand.Rand, params Planet
 	
 	fmt.Printf("Generating %d random continents on surface shell %d\n", params.ContinentCount, surfaceShell)
 
-	// Calculate total surface area
+	// Count the surface voxels
 	totalVoxels := 0
 	for _, latBand := range shell.Voxels {
 		totalVoxels += len(latBand)
@@ -72,9 +156,9 @@ func generateRandomContinents(planet *VoxelPlanet, rng *rand.Rand, params Planet
 		sizeFraction := params.MinContinentSize + rng.Float64()*sizeRange
 
 		// Convert size fraction to angular radius
-		// Approximate: total surface area = 4πr², continent area = πR²
-		// So R = sqrt(sizeFraction * 4) * 180/π degrees
-		angularRadius := math.Sqrt(sizeFraction*4) * 180.0 / math.Pi
+		// A cap of angular radius R covers (1 - cos R) / 2 of the sphere
+		// So R = acos(1 - 2 * sizeFraction) * 180/π degrees
+		angularRadius := math.Acos(1-2*sizeFraction) * 180.0 / math.Pi
 
 		// Random shape factor
 		shape := rng.Float64() * params.ContinentRoughness
@@ -106,23 +190,26 @@ func generateRandomContinents(planet *VoxelPlanet, rng *rand.Rand, params Planet
 		}
 	}
 
-	// Fill in the voxels based on continent seeds
-	landCount := 0
-	waterCount := 0
+	// Score each voxel by how far inside the nearest continent it lies, in
+	// degrees, how far inside the next nearest, and how far it is from any
+	// continent's center
+	type cellScore struct {
+		lat, lon int
+		inside   float64
+		runnerUp float64
+		center   float64
+	}
+	scores := make([]cellScore, 0, totalVoxels)
 	for latIdx, latBand := range shell.Voxels {
 		lat := GetLatitudeForBand(latIdx, shell.LatBands)
 
 		for lonIdx := range latBand {
-			voxel := &shell.Voxels[latIdx][lonIdx]
 			lon := float64(lonIdx)/float64(len(latBand))*360.0 - 180.0
-
-			// Check if this voxel is within any continent
-			isLand := false
-			minDistance := math.MaxFloat64
+			score := cellScore{lat: latIdx, lon: lonIdx, inside: -math.MaxFloat64, runnerUp: -math.MaxFloat64, center: math.MaxFloat64}
 
 			for _, seed := range seeds {
 				// Calculate angular distance to continent center
-				distance := angularDistance(lat, lon, seed.lat, seed.lon)
+				distance := AngularDistance(lat, lon, seed.lat, seed.lon)
 
 				// Add noise for irregular shapes
 				noiseFactor := 1.0
@@ -130,7 +217,7 @@ func generateRandomContinents(planet *VoxelPlanet, rng *rand.Rand, params Planet
 					// Use multi-scale noise for more natural continent shapes
 					// Large scale features
 					noise1 := math.Sin(lat*0.05+lon*0.04) * math.Cos(lat*0.06-lon*0.03)
-					// Medium scale features  
+					// Medium scale features
 					noise2 := math.Sin(lat*0.15-lon*0.12) * math.Cos(lat*0.18+lon*0.14)
 					// Small scale features for coastline detail
 					noise3 := math.Sin(lat*0.3+lon*0.25) * math.Cos(lat*0.35-lon*0.28)
@@ -139,61 +226,97 @@ func generateRandomContinents(planet *VoxelPlanet, rng *rand.Rand, params Planet
 				}
 
 				effectiveRadius := seed.radius * noiseFactor
-
-				if distance < effectiveRadius {
-					isLand = true
-					if distance < minDistance {
-						minDistance = distance
-					}
+				if inside := effectiveRadius - distance; inside > score.inside {
+					score.runnerUp = score.inside
+					score.inside = inside
+				} else if inside > score.runnerUp {
+					score.runnerUp = inside
 				}
+				score.center = math.Min(score.center, distance)
+			}
+			if len(seeds) == 1 {
+				// A lone continent's ocean spreads from around its antipode
+				score.runnerUp = score.center - 180
 			}
+			scores = append(scores, score)
+		}
+	}
 
-			// Set voxel properties
-			if isLand {
-				voxel.Type = MatGranite
-				voxel.Density = MaterialProperties[MatGranite].DefaultDensity
-				voxel.IsBrittle = true
-				landCount++
-
-				// Age based on distance from continent center (older at center)
-				ageFactor := 1.0 - minDistance/30.0
-				if ageFactor < 0 {
-					ageFactor = 0
-				}
-				voxel.Age = float32(50000000 + 150000000*ageFactor) // 50-200 My
-
-				// Elevation based on distance from edge
-				// Find distance to nearest continent edge
-				edgeDistance := math.MaxFloat64
-				for _, seed := range seeds {
-					dist := angularDistance(lat, lon, seed.lat, seed.lon)
-					edgeDist := seed.radius - dist
-					if edgeDist < edgeDistance && edgeDist > 0 {
-						edgeDistance = edgeDist
-					}
-				}
-				
-				// Create elevation gradient from coast to interior
-				if edgeDistance < 5.0 { // Within 5 degrees of coast
-					// Coastal lowlands with smooth transition
-					coastFactor := edgeDistance / 5.0
-					voxel.Elevation = float32(coastFactor * 500.0) // 0-500m coastal plain
-				} else {
-					// Interior with varied elevation
-					voxel.Elevation = 500 + float32(rng.Float64()*1000) // 500-1500m interior
-				}
-			} else {
-				voxel.Type = MatWater
-				voxel.Density = MaterialProperties[MatWater].DefaultDensity
-				waterCount++
-				// Ocean depth gradient based on distance from land
-				oceanDepth := float32(-1000 - rng.Float64()*3000) // -1 to -4km
-				voxel.Elevation = oceanDepth
+	// Move the coastline in or out until land covers 1 - OceanFraction of the
+	// surface by area: polar voxels are many but small, so counting them
+	// would leave land-heavy planets short of land and ocean-heavy ones short
+	// of ocean. Continents keep their shapes, only growing or shrinking
+	sort.SliceStable(scores, func(i, j int) bool { return scores[i].inside > scores[j].inside })
+	totalArea := 0.0
+	for _, score := range scores {
+		totalArea += planet.VoxelArea(shell, score.lat)
+	}
+	landTarget := (1 - params.OceanFraction) * totalArea
+	landCells := 0
+	for area := 0.0; landCells < len(scores); landCells++ {
+		cellArea := planet.VoxelArea(shell, scores[landCells].lat)
+		if area+cellArea/2 > landTarget {
+			break // Closer to the target without this voxel
+		}
+		area += cellArea
+	}
+	coastline := 0.0
+	if landCells > 0 {
+		coastline = scores[landCells-1].inside
+	}
+
+	// Fill in the voxels, land first
+	landCount := 0
+	waterCount := 0
+	landArea := 0.0
+	waterArea := 0.0
+	for i, score := range scores {
+		voxel := &shell.Voxels[score.lat][score.lon]
+		lat := GetLatitudeForBand(score.lat, shell.LatBands)
+		cellArea := planet.VoxelArea(shell, score.lat)
+
+		// Set voxel properties
+		if i < landCells {
+			voxel.Type = MatGranite
+			voxel.Density = MaterialProperties[MatGranite].DefaultDensity
+			voxel.IsBrittle = true
+			landCount++
+			landArea += cellArea
+
+			// Age based on distance from continent center (older at center)
+			ageFactor := 1.0 - score.center/30.0
+			if ageFactor < 0 {
+				ageFactor = 0
 			}
+			voxel.Age = float32(50000000 + 150000000*ageFactor) // 50-200 My
+
+			// Elevation based on distance inland from the coastline
+			edgeDistance := score.inside - coastline
 
-			// Set temperature
-			voxel.Temperature = 288.15 - float32(math.Abs(lat)*0.5)
+			// Create elevation gradient from coast to interior
+			if edgeDistance < 5.0 { // Within 5 degrees of coast
+				// Coastal lowlands with smooth transition
+				coastFactor := edgeDistance / 5.0
+				voxel.Elevation = float32(coastFactor * 500.0) // 0-500m coastal plain
+			} else {
+				// Interior with varied elevation
+				voxel.Elevation = 500 + float32(rng.Float64()*1000) // 500-1500m interior
+			}
+		} else {
+			voxel.Type = MatWater
+			voxel.Density = MaterialProperties[MatWater].DefaultDensity
+			waterCount++
+			waterArea += cellArea
+			// Ridges run midway between continents and the seafloor ages
+			// spreading away from them, oldest along the coasts. Its age
+			// sets its depth: young ridges shallow, old basins deep
+			toRidge := (score.inside - score.runnerUp) / 2 * math.Pi / 180 * planet.Radius
+			voxel.Age = float32(math.Min(toRidge/SeafloorSpreadingRate, MaxSeafloorAge))
+			voxel.Elevation = float32(-SeafloorDepth(float64(voxel.Age)))
 		}
+
+		// Set temperature
+		voxel.Temperature = 288.15 - float32(math.Abs(lat)*0.5)
 	}
 
 	// Also update the crust layer below
@@ -203,22 +326,23 @@ func generateRandomContinents(planet *VoxelPlanet, rng *rand.Rand, params Planet
 			for lonIdx := range latBand {
 				voxel := &crustShell.Voxels[latIdx][lonIdx]
 
-				// Check what's above
-				if latIdx < len(shell.Voxels) && lonIdx < len(shell.Voxels[latIdx]) {
-					surfaceVoxel := &shell.Voxels[latIdx][lonIdx]
-					if surfaceVoxel.Type == MatGranite {
-						// Continental crust
-						voxel.Type = MatGranite
-						voxel.Density = MaterialProperties[MatGranite].DefaultDensity
-						voxel.IsBrittle = true
-						voxel.Age = surfaceVoxel.Age
-					} else {
-						// Oceanic crust
-						voxel.Type = MatBasalt
-						voxel.Density = MaterialProperties[MatBasalt].DefaultDensity
-						voxel.IsBrittle = true
-						voxel.Age = float32(rng.Float64() * 100000000) // 0-100 My
-					}
+				// Check what's above - matched by position, since the surface
+				// may have finer bands than the crust
+				lat, lon := planet.VoxelLatLon(VoxelCoord{Shell: surfaceShell - 1, Lat: latIdx, Lon: lonIdx})
+				surfLat, surfLon := shell.indexAt(lat, lon)
+				surfaceVoxel := &shell.Voxels[surfLat][surfLon]
+				if surfaceVoxel.Type == MatGranite {
+					// Continental crust
+					voxel.Type = MatGranite
+					voxel.Density = MaterialProperties[MatGranite].DefaultDensity
+					voxel.IsBrittle = true
+					voxel.Age = surfaceVoxel.Age
+				} else {
+					// Oceanic crust
+					voxel.Type = MatBasalt
+					voxel.Density = MaterialProperties[MatBasalt].DefaultDensity
+					voxel.IsBrittle = true
+					voxel.Age = surfaceVoxel.Age
 				}
 
 				voxel.Temperature = 1000 - float32(700*(crustShell.OuterRadius-planet.Radius*0.85)/(planet.Radius*0.14))
@@ -227,7 +351,7 @@ func generateRandomContinents(planet *VoxelPlanet, rng *rand.Rand, params Planet
 	}
 	
 	fmt.Printf("Generated continents: %d land voxels, %d water voxels (%.1f%% land)\n", 
-		landCount, waterCount, float64(landCount)*100/float64(landCount+waterCount))
+		landCount, waterCount, landArea*100/(landArea+waterArea))
 		
 	// Apply smoothing pass to reduce grid artifacts at coastlines
 	smoothCoastlines(shell)
@@ -329,10 +453,10 @@ func addRandomPlateVelocities(planet *VoxelPlanet, rng *rand.Rand) {
 		lat := (rng.Float64() - 0.5) * 180.0
 		lon := (rng.Float64() - 0.5) * 360.0
 
-		// Random velocity (in m/s)
-		// Typical plate velocities are 2-10 cm/year = 6e-10 to 3e-9 m/s
-		// Start with realistic speeds - fractional system will handle smooth movement
-		speed := float32(rng.Float64()*2e-9 + 1e-9) // 3-10 cm/year realistic
+		// Random velocity seed (in m/year)
+		// Only the direction matters: plate motion replaces it with the
+		// plate's speed, and the boundary mechanics are tuned to seeds this small
+		speed := float32(rng.Float64()*2e-9 + 1e-9)
 		angle := rng.Float64() * 2 * math.Pi
 
 		cells[i] = velocityCell{
@@ -370,7 +494,7 @@ func addRandomPlateVelocities(planet *VoxelPlanet, rng *rand.Rand) {
 				minDistance := math.MaxFloat64
 
 				for i := range cells {
-					distance := angularDistance(lat, lon, cells[i].lat, cells[i].lon)
+					distance := AngularDistance(lat, lon, cells[i].lat, cells[i].lon)
 					if distance < cells[i].influence && distance < minDistance {
 						minDistance = distance
 						nearestCell = &cells[i]
@@ -388,8 +512,9 @@ func addRandomPlateVelocities(planet *VoxelPlanet, rng *rand.Rand) {
 	}
 }
 
-// angularDistance calculates the angular distance between two points on a sphere
-func angularDistance(lat1, lon1, lat2, lon2 float64) float64 {
+// AngularDistance returns the angle in degrees between two points on a
+// sphere, given as latitude and longitude in degrees
+func AngularDistance(lat1, lon1, lat2, lon2 float64) float64 {
 	// Convert to radians
 	lat1Rad := lat1 * math.Pi / 180.0
 	lon1Rad := lon1 * math.Pi / 180.0
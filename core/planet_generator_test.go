@@ -0,0 +1,97 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+// TestGeneratedOceanFraction checks the ocean a generated planet ends up
+// with, measured by area, is the fraction it was asked for
+func TestGeneratedOceanFraction(t *testing.T) {
+	for _, ocean := range []float64{0.3, 0.5, 0.7, 0.9} {
+		params := validParams()
+		params.Seed = 42
+		params.OceanFraction = ocean
+		planet, err := CreateRandomizedPlanet(6371000.0, 8, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := planet.Summary().OceanFraction; math.Abs(got-ocean) > 0.02 {
+			t.Errorf("asked for %.0f%% ocean, generated %.1f%% by area", ocean*100, got*100)
+		}
+	}
+}
+
+// TestSeafloorAgeFromRidges checks generated seafloor ages with its distance
+// from the ridges between continents rather than at random: youngest out in
+// the open ocean and older along the coasts. Its depth follows its age
+// outside shallow bays
+func TestSeafloorAgeFromRidges(t *testing.T) {
+	params := validParams()
+	params.Seed = 42
+	planet, err := CreateRandomizedPlanet(6371000.0, 8, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shell := &planet.Shells[len(planet.Shells)-2]
+
+	var coastal, open []float64
+	youngest := math.MaxFloat64
+	for latIdx, band := range shell.Voxels {
+		for lonIdx, voxel := range band {
+			if voxel.Type != MatWater {
+				continue
+			}
+			age := float64(voxel.Age)
+			youngest = math.Min(youngest, age)
+			// smoothCoastlines shallows bays to -200 m and above
+			if want := float32(-SeafloorDepth(age)); voxel.Elevation < -200 && voxel.Elevation != want {
+				t.Fatalf("seafloor %.0f My old at %.0f m, want %.0f m", age/1e6, voxel.Elevation, want)
+			}
+
+			east := band[(lonIdx+1)%len(band)]
+			if east.Type == MatGranite || band[(lonIdx+len(band)-1)%len(band)].Type == MatGranite {
+				coastal = append(coastal, age)
+			} else if latIdx > 0 && latIdx < len(shell.Voxels)-1 {
+				open = append(open, age)
+			}
+		}
+	}
+
+	mean := func(ages []float64) float64 {
+		sum := 0.0
+		for _, age := range ages {
+			sum += age
+		}
+		return sum / float64(max(len(ages), 1))
+	}
+	if youngest > 5e6 {
+		t.Errorf("youngest seafloor is %.1f My old, want a ridge near 0", youngest/1e6)
+	}
+	if mean(coastal) <= mean(open) {
+		t.Errorf("coastal seafloor averages %.1f My, open ocean %.1f My, want coasts older", mean(coastal)/1e6, mean(open)/1e6)
+	}
+}
+
+// TestGeneratedAxialTilt checks an unset tilt gives Earth's, like CreateVoxelPlanet,
+// and NoAxialTilt an upright planet
+func TestGeneratedAxialTilt(t *testing.T) {
+	for _, c := range []struct {
+		tilt, want float64
+	}{
+		{0, DefaultAxialTilt},
+		{NoAxialTilt, 0},
+		{60, 60},
+	} {
+		params := validParams()
+		params.AxialTilt = c.tilt
+		planet, err := CreateRandomizedPlanet(6371000.0, 4, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if planet.AxialTilt != c.want {
+			t.Errorf("AxialTilt %g generated a planet tilted %g°, want %g°", c.tilt, planet.AxialTilt, c.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MinShellCount is the fewest shells a planet can have: an interior, the
+// surface and the atmosphere
+const MinShellCount = 3
+
+// ValidatePlanetSize rejects a radius or shell count the voxel grid can't be
+// built from
+func ValidatePlanetSize(radius float64, shellCount int) error {
+	var errs []error
+	if !(radius > 0) {
+		errs = append(errs, fmt.Errorf("planet radius must be positive, got %g m", radius))
+	}
+	if shellCount < MinShellCount {
+		errs = append(errs, fmt.Errorf("need at least %d shells (interior, surface and atmosphere), got %d", MinShellCount, shellCount))
+	}
+	return errors.Join(errs...)
+}
+
+// Validate rejects generation parameters outside their usable ranges for a
+// planet of shellCount shells, listing every problem at once. Zero keeps the
+// default wherever the field says so
+func (p PlanetGenerationParams) Validate(shellCount int) error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(p.ContinentCount >= 1, "need at least 1 continent, got %d", p.ContinentCount)
+	check(p.OceanFraction >= 0 && p.OceanFraction <= 1, "ocean fraction must be between 0 and 1, got %g", p.OceanFraction)
+	check(p.MinContinentSize > 0 && p.MinContinentSize <= 1, "minimum continent size must be a fraction of the surface above 0 and up to 1, got %g", p.MinContinentSize)
+	check(p.MaxContinentSize >= p.MinContinentSize && p.MaxContinentSize <= 1, "maximum continent size must be between the minimum (%g) and 1, got %g", p.MinContinentSize, p.MaxContinentSize)
+	check(p.ContinentRoughness >= 0 && p.ContinentRoughness <= 1, "continent roughness must be between 0 and 1, got %g", p.ContinentRoughness)
+	check(p.CoreTemperature >= 0, "core temperature can't be negative, got %g K", p.CoreTemperature)
+	check(p.CoreHeatFlux >= 0, "core heat flux can't be negative, got %g W/m²", p.CoreHeatFlux)
+	check(p.SlabDip >= 0 && p.SlabDip <= 90, "slab dip must be between 0 and 90 degrees, got %g", p.SlabDip)
+	check(p.MaxElevationRate >= 0, "maximum uplift rate can't be negative, got %g m/year", p.MaxElevationRate)
+	check(p.MaxPlates >= 0, "maximum plate count can't be negative (0 = default), got %d", p.MaxPlates)
+	check(p.ConvectionForcing >= 0, "convection strength can't be negative, got %g cm/year", p.ConvectionForcing)
+	check(p.MaxPlateVelocity >= 0, "maximum plate speed can't be negative (0 = default), got %g cm/year", p.MaxPlateVelocity)
+	check(p.GreenhouseStrength == GreenhouseOff || (p.GreenhouseStrength >= 0 && p.GreenhouseStrength <= 1), "greenhouse strength is an emissivity between 0 and 1 (0 = default, -1 = none), got %g", p.GreenhouseStrength)
+	check(p.InitialRadiogenicHeat >= 0, "radiogenic heating can't be negative, got %g K/year", p.InitialRadiogenicHeat)
+	check(p.HeatHalfLifeYears >= 0, "heat half-life can't be negative (0 = no decay), got %g years", p.HeatHalfLifeYears)
+	check(p.AxialTilt == NoAxialTilt || (p.AxialTilt >= 0 && p.AxialTilt <= 180), "axial tilt must be between 0 and 180 degrees (0 = default, -1 = upright), got %g", p.AxialTilt)
+	check(p.Flattening >= 0 && p.Flattening < 1, "flattening must be at least 0 and below 1, got %g", p.Flattening)
+	check(p.RotationRate >= 0, "rotation rate can't be negative, got %g rad/s", p.RotationRate)
+	check(p.RotationPeriodHours >= 0, "rotation period can't be negative (0 = use the rotation rate), got %g hours", p.RotationPeriodHours)
+	check(p.SurfaceBands == 0 || p.SurfaceBands >= 2, "surface needs at least 2 latitude bands (0 = default), got %d", p.SurfaceBands)
+	check(p.SurfaceLatBands == 0 || p.SurfaceLatBands >= 2, "surface latitude bands must be at least 2 (0 = same as the interior), got %d", p.SurfaceLatBands)
+	check(p.LatBands == nil || len(p.LatBands) == shellCount, "latitude band schedule lists %d shells, need one per shell (%d)", len(p.LatBands), shellCount)
+	for i, bands := range p.LatBands {
+		check(bands >= 2, "shell %d needs at least 2 latitude bands, got %d", i, bands)
+	}
+	return errors.Join(errs...)
+}
+
+// ParseShellLatBands reads a comma-separated latitude band schedule, one
+// count per shell from the core out, e.g. "20,20,40,90,90". An empty string
+// is the default schedule
+func ParseShellLatBands(text string) ([]int, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	fields := strings.Split(text, ",")
+	schedule := make([]int, len(fields))
+	for i, field := range fields {
+		bands, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil {
+			return nil, fmt.Errorf("shell %d of latitude band schedule %q isn't a whole number: %q", i, text, field)
+		}
+		schedule[i] = bands
+	}
+	return schedule, nil
+}
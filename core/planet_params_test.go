@@ -0,0 +1,117 @@
+package core
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// validParams are the command line defaults
+func validParams() PlanetGenerationParams {
+	return PlanetGenerationParams{
+		ContinentCount:     7,
+		OceanFraction:      0.7,
+		MinContinentSize:   0.01,
+		MaxContinentSize:   0.15,
+		ContinentRoughness: 0.7,
+		SlabDip:            45,
+		MaxPlates:          20,
+		GreenhouseStrength: 0.78,
+		AxialTilt:          DefaultAxialTilt,
+	}
+}
+
+// TestValidateParams checks each out-of-range setting is reported by name
+// instead of reaching planet generation
+func TestValidateParams(t *testing.T) {
+	if err := validParams().Validate(20); err != nil {
+		t.Fatalf("defaults rejected: %v", err)
+	}
+	if err := ValidatePlanetSize(6371000, 20); err != nil {
+		t.Fatalf("default size rejected: %v", err)
+	}
+
+	cases := []struct {
+		name   string
+		modify func(*PlanetGenerationParams)
+		want   string
+	}{
+		{"ocean above 1", func(p *PlanetGenerationParams) { p.OceanFraction = 1.5 }, "ocean fraction"},
+		{"negative ocean", func(p *PlanetGenerationParams) { p.OceanFraction = -0.1 }, "ocean fraction"},
+		{"no continents", func(p *PlanetGenerationParams) { p.ContinentCount = 0 }, "continent"},
+		{"continent sizes reversed", func(p *PlanetGenerationParams) { p.MaxContinentSize = 0.005 }, "maximum continent size"},
+		{"negative core flux", func(p *PlanetGenerationParams) { p.CoreHeatFlux = -1 }, "core heat flux"},
+		{"slab past vertical", func(p *PlanetGenerationParams) { p.SlabDip = 120 }, "slab dip"},
+		{"greenhouse above 1", func(p *PlanetGenerationParams) { p.GreenhouseStrength = 2 }, "greenhouse"},
+		{"negative greenhouse", func(p *PlanetGenerationParams) { p.GreenhouseStrength = -0.5 }, "greenhouse"},
+		{"negative tilt", func(p *PlanetGenerationParams) { p.AxialTilt = -0.5 }, "axial tilt"},
+		{"negative half-life", func(p *PlanetGenerationParams) { p.HeatHalfLifeYears = -1 }, "half-life"},
+		{"negative plate cap", func(p *PlanetGenerationParams) { p.MaxPlates = -1 }, "plate count"},
+		{"negative speed limit", func(p *PlanetGenerationParams) { p.MaxPlateVelocity = -5 }, "plate speed"},
+		{"flattened to a disk", func(p *PlanetGenerationParams) { p.Flattening = 1 }, "flattening"},
+		{"negative day", func(p *PlanetGenerationParams) { p.RotationPeriodHours = -24 }, "rotation period"},
+		{"one surface band", func(p *PlanetGenerationParams) { p.SurfaceLatBands = 1 }, "latitude bands"},
+		{"one band in a shell", func(p *PlanetGenerationParams) { p.LatBands = []int{20, 1, 40} }, "shell 1"},
+	}
+	for _, c := range cases {
+		params := validParams()
+		c.modify(&params)
+		err := params.Validate(3)
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: got %v, want an error about %s", c.name, err, c.want)
+		}
+	}
+
+	for _, size := range []struct {
+		radius float64
+		shells int
+		want   string
+	}{
+		{-6371000, 20, "radius"},
+		{0, 20, "radius"},
+		{6371000, 1, "shells"},
+		{6371000, 2, "shells"},
+	} {
+		if err := ValidatePlanetSize(size.radius, size.shells); err == nil || !strings.Contains(err.Error(), size.want) {
+			t.Errorf("radius %g with %d shells: got %v, want an error about %s", size.radius, size.shells, err, size.want)
+		}
+	}
+
+	// Every problem is listed, not just the first
+	params := validParams()
+	params.OceanFraction = 2
+	params.ContinentCount = 0
+	if err := params.Validate(20); err == nil || strings.Count(err.Error(), "\n") != 1 {
+		t.Errorf("two problems reported as %q", err)
+	}
+}
+
+// TestParseShellLatBands checks a schedule is read one shell per entry and
+// has to cover every shell
+func TestParseShellLatBands(t *testing.T) {
+	schedule, err := ParseShellLatBands("20, 20,40,90,90")
+	if err != nil {
+		t.Fatalf("ParseShellLatBands: %v", err)
+	}
+	if want := []int{20, 20, 40, 90, 90}; !slices.Equal(schedule, want) {
+		t.Errorf("schedule %v, want %v", schedule, want)
+	}
+	params := validParams()
+	params.LatBands = schedule
+	if err := params.Validate(5); err != nil {
+		t.Errorf("5-shell schedule for 5 shells: %v", err)
+	}
+	if err := params.Validate(6); err == nil || !strings.Contains(err.Error(), "one per shell") {
+		t.Errorf("5-shell schedule for 6 shells reported as %v", err)
+	}
+	if _, err := CreateRandomizedPlanet(6371000.0, 6, params); err == nil {
+		t.Error("planet generated from a 5-shell schedule for 6 shells")
+	}
+
+	if schedule, err := ParseShellLatBands(""); schedule != nil || err != nil {
+		t.Errorf("empty schedule parsed as %v, %v", schedule, err)
+	}
+	if _, err := ParseShellLatBands("20,fine,90"); err == nil || !strings.Contains(err.Error(), "shell 1") {
+		t.Errorf("bad entry reported as %v", err)
+	}
+}
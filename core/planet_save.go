@@ -0,0 +1,462 @@
+package core
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+)
+
+// planetSaveMagic opens every planet save file
+var planetSaveMagic = [8]byte{'V', 'O', 'X', 'P', 'L', 'A', 'N', 'T'}
+
+// planetSaveVersion is bumped whenever the layout below changes, so older
+// files are rejected instead of misread
+const planetSaveVersion = 9
+
+// voxelRecordSize is the bytes of one voxel in a save, see codeVoxel
+const voxelRecordSize = 125
+
+// Limits on a save's grid, so a corrupt file fails instead of allocating
+// without bound
+const (
+	maxSavedShells      = 1 << 12
+	maxSavedLatBands    = 1 << 15
+	maxSavedPlates      = 1 << 16
+	maxSavedTrackPoints = 1 << 16
+)
+
+// planetSaveHeader holds the planet-wide state and the settings it was
+// generated with, written once after the magic and version
+type planetSaveHeader struct {
+	Radius       float64
+	Flattening   float64
+	Mass         float64
+	Time         float64
+	RotationRate float64
+	AxialTilt    float64
+	Seed         int64
+
+	CoreBoundary       uint8
+	CoreTemperature    float64
+	CoreHeatFlux       float64
+	SlabDip            float64
+	MaxElevationRate   float64
+	MaxPlates          int64
+	ReferencePlate     int64
+	ConvectionForcing  float64
+	MaxPlateVelocity   float64
+	GreenhouseStrength float64
+
+	InitialRadiogenicHeat float64
+	HeatHalfLifeYears     float64
+
+	TotalWaterVolume float64
+	TotalRockVolume  float64
+	SeaLevel         float64
+	SeaLevelForced   bool
+	SeaLevelTarget   float64
+	SeaLevelRate     float64
+
+	PlateHueCount   uint32
+	PlateTrackCount uint32
+	ShellCount      uint32
+}
+
+// plateHueRecord is one entry of VoxelPlanet.PlateHues, written in ID order
+// after the header
+type plateHueRecord struct {
+	PlateID int32
+	Hue     float32
+}
+
+// plateTrackHeader precedes each of VoxelPlanet.PlateTracks, written in ID
+// order after the plate colors and followed by the track's points
+type plateTrackHeader struct {
+	PlateID    int32
+	PointCount uint32
+}
+
+// shellSaveHeader precedes each shell's longitude counts and voxels
+type shellSaveHeader struct {
+	InnerRadius float64
+	OuterRadius float64
+	LatBands    uint32
+}
+
+// SavePlanet writes the planet's voxels, time, sea level and generation
+// settings to a versioned binary file. Shells are written one latitude band
+// at a time, so saving doesn't need a second copy of the planet in memory.
+// The file is written with WriteFileAtomic, so a save cut short never
+// replaces an earlier one with a truncated file
+func SavePlanet(planet *VoxelPlanet, path string) error {
+	err := WriteFileAtomic(path, func(f io.Writer) error {
+		w := bufio.NewWriter(f)
+		if err := writePlanet(w, planet); err != nil {
+			return err
+		}
+		return w.Flush()
+	})
+	if err != nil {
+		return fmt.Errorf("save planet to %s: %w", path, err)
+	}
+	return nil
+}
+
+// writePlanet streams the save format to w
+func writePlanet(w io.Writer, planet *VoxelPlanet) error {
+	if _, err := w.Write(planetSaveMagic[:]); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint32(planetSaveVersion)); err != nil {
+		return err
+	}
+
+	header := planetSaveHeader{
+		Radius:                planet.Radius,
+		Flattening:            planet.Flattening,
+		Mass:                  planet.Mass,
+		Time:                  planet.Time,
+		RotationRate:          planet.RotationRate,
+		AxialTilt:             planet.AxialTilt,
+		Seed:                  planet.Seed(),
+		CoreBoundary:          uint8(planet.CoreBoundary),
+		CoreTemperature:       planet.CoreTemperature,
+		CoreHeatFlux:          planet.CoreHeatFlux,
+		SlabDip:               planet.SlabDip,
+		MaxElevationRate:      planet.MaxElevationRate,
+		MaxPlates:             int64(planet.MaxPlates),
+		ReferencePlate:        int64(planet.ReferencePlate),
+		ConvectionForcing:     planet.ConvectionForcing,
+		MaxPlateVelocity:      planet.MaxPlateVelocity,
+		GreenhouseStrength:    planet.GreenhouseStrength,
+		InitialRadiogenicHeat: planet.InitialRadiogenicHeat,
+		HeatHalfLifeYears:     planet.HeatHalfLifeYears,
+		TotalWaterVolume:      planet.WaterBudget,
+		TotalRockVolume:       planet.TotalRockVolume,
+		SeaLevel:              planet.SeaLevel,
+		SeaLevelForced:        planet.SeaLevelForced,
+		SeaLevelTarget:        planet.SeaLevelTarget,
+		SeaLevelRate:          planet.SeaLevelRate,
+		PlateHueCount:         uint32(len(planet.PlateHues)),
+		PlateTrackCount:       uint32(len(planet.PlateTracks)),
+		ShellCount:            uint32(len(planet.Shells)),
+	}
+	if err := binary.Write(w, binary.LittleEndian, &header); err != nil {
+		return err
+	}
+
+	hues := make([]plateHueRecord, 0, len(planet.PlateHues))
+	for id, hue := range planet.PlateHues {
+		hues = append(hues, plateHueRecord{PlateID: id, Hue: hue})
+	}
+	sort.Slice(hues, func(i, j int) bool { return hues[i].PlateID < hues[j].PlateID })
+	if err := binary.Write(w, binary.LittleEndian, hues); err != nil {
+		return err
+	}
+
+	trackIDs := make([]int32, 0, len(planet.PlateTracks))
+	for id := range planet.PlateTracks {
+		trackIDs = append(trackIDs, id)
+	}
+	sort.Slice(trackIDs, func(i, j int) bool { return trackIDs[i] < trackIDs[j] })
+	for _, id := range trackIDs {
+		points := planet.PlateTracks[id]
+		if err := binary.Write(w, binary.LittleEndian, plateTrackHeader{PlateID: id, PointCount: uint32(len(points))}); err != nil {
+			return err
+		}
+		if err := binary.Write(w, binary.LittleEndian, points); err != nil {
+			return err
+		}
+	}
+
+	var record []byte
+	for _, shell := range planet.Shells {
+		shellHeader := shellSaveHeader{
+			InnerRadius: shell.InnerRadius,
+			OuterRadius: shell.OuterRadius,
+			LatBands:    uint32(len(shell.Voxels)),
+		}
+		if err := binary.Write(w, binary.LittleEndian, &shellHeader); err != nil {
+			return err
+		}
+		for _, band := range shell.Voxels {
+			if err := binary.Write(w, binary.LittleEndian, uint32(len(band))); err != nil {
+				return err
+			}
+		}
+
+		for _, band := range shell.Voxels {
+			record = encodeVoxels(record[:0], band)
+			if _, err := w.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// LoadPlanet reads a planet written by SavePlanet. Files from another format
+// version are rejected
+func LoadPlanet(path string) (*VoxelPlanet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("load planet: %w", err)
+	}
+	defer f.Close()
+
+	planet, err := readPlanet(bufio.NewReader(f))
+	if err != nil {
+		return nil, fmt.Errorf("load planet from %s: %w", path, err)
+	}
+	return planet, nil
+}
+
+// readPlanet parses the save format from r
+func readPlanet(r io.Reader) (*VoxelPlanet, error) {
+	var magic [8]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	if magic != planetSaveMagic {
+		return nil, fmt.Errorf("not a planet save")
+	}
+	var version uint32
+	if err := binary.Read(r, binary.LittleEndian, &version); err != nil {
+		return nil, fmt.Errorf("reading version: %w", err)
+	}
+	if version != planetSaveVersion {
+		return nil, fmt.Errorf("save format version %d, this build reads version %d", version, planetSaveVersion)
+	}
+
+	var header planetSaveHeader
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	if header.ShellCount > maxSavedShells {
+		return nil, fmt.Errorf("%d shells is more than a save can hold", header.ShellCount)
+	}
+	if header.PlateHueCount > maxSavedPlates {
+		return nil, fmt.Errorf("%d plate colors is more than a save can hold", header.PlateHueCount)
+	}
+	if header.PlateTrackCount > maxSavedPlates {
+		return nil, fmt.Errorf("%d plate tracks is more than a save can hold", header.PlateTrackCount)
+	}
+
+	planet := &VoxelPlanet{
+		Shells:                make([]SphericalShell, header.ShellCount),
+		Radius:                header.Radius,
+		Flattening:            header.Flattening,
+		Mass:                  header.Mass,
+		Time:                  header.Time,
+		RotationRate:          header.RotationRate,
+		AxialTilt:             header.AxialTilt,
+		ActiveCells:           make(map[VoxelCoord]bool),
+		MeshDirty:             true,
+		CoreBoundary:          CoreBoundaryMode(header.CoreBoundary),
+		CoreTemperature:       header.CoreTemperature,
+		CoreHeatFlux:          header.CoreHeatFlux,
+		SlabDip:               header.SlabDip,
+		MaxElevationRate:      header.MaxElevationRate,
+		MaxPlates:             int(header.MaxPlates),
+		ReferencePlate:        int(header.ReferencePlate),
+		ConvectionForcing:     header.ConvectionForcing,
+		MaxPlateVelocity:      header.MaxPlateVelocity,
+		GreenhouseStrength:    header.GreenhouseStrength,
+		InitialRadiogenicHeat: header.InitialRadiogenicHeat,
+		HeatHalfLifeYears:     header.HeatHalfLifeYears,
+		WaterBudget:           header.TotalWaterVolume,
+		TotalRockVolume:       header.TotalRockVolume,
+		SeaLevel:              header.SeaLevel,
+		SeaLevelForced:        header.SeaLevelForced,
+		SeaLevelTarget:        header.SeaLevelTarget,
+		SeaLevelRate:          header.SeaLevelRate,
+	}
+	planet.SetSeed(header.Seed)
+
+	hues := make([]plateHueRecord, header.PlateHueCount)
+	if err := binary.Read(r, binary.LittleEndian, hues); err != nil {
+		return nil, fmt.Errorf("reading plate colors: %w", err)
+	}
+	planet.PlateHues = make(map[int32]float32, len(hues))
+	for _, h := range hues {
+		planet.PlateHues[h.PlateID] = h.Hue
+	}
+
+	planet.PlateTracks = make(map[int32][]PlateTrackPoint, header.PlateTrackCount)
+	for i := uint32(0); i < header.PlateTrackCount; i++ {
+		var trackHeader plateTrackHeader
+		if err := binary.Read(r, binary.LittleEndian, &trackHeader); err != nil {
+			return nil, fmt.Errorf("reading plate tracks: %w", err)
+		}
+		if trackHeader.PointCount > maxSavedTrackPoints {
+			return nil, fmt.Errorf("plate %d track of %d points is more than a save can hold", trackHeader.PlateID, trackHeader.PointCount)
+		}
+		points := make([]PlateTrackPoint, trackHeader.PointCount)
+		if err := binary.Read(r, binary.LittleEndian, points); err != nil {
+			return nil, fmt.Errorf("reading plate tracks: %w", err)
+		}
+		planet.PlateTracks[trackHeader.PlateID] = points
+	}
+
+	var record []byte
+	for shellIdx := range planet.Shells {
+		var shellHeader shellSaveHeader
+		if err := binary.Read(r, binary.LittleEndian, &shellHeader); err != nil {
+			return nil, fmt.Errorf("reading shell %d: %w", shellIdx, err)
+		}
+		if shellHeader.LatBands > maxSavedLatBands {
+			return nil, fmt.Errorf("shell %d has %d latitude bands, more than a save can hold", shellIdx, shellHeader.LatBands)
+		}
+
+		latBands := int(shellHeader.LatBands)
+		shell := &planet.Shells[shellIdx]
+		shell.InnerRadius = shellHeader.InnerRadius
+		shell.OuterRadius = shellHeader.OuterRadius
+		shell.LatBands = latBands
+		shell.LonCounts = make([]int, latBands)
+		shell.Voxels = make([][]VoxelMaterial, latBands)
+
+		for lat := range shell.LonCounts {
+			var count uint32
+			if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+				return nil, fmt.Errorf("reading shell %d: %w", shellIdx, err)
+			}
+			if count > 4*maxSavedLatBands {
+				return nil, fmt.Errorf("shell %d band %d has %d longitudes, more than a save can hold", shellIdx, lat, count)
+			}
+			shell.LonCounts[lat] = int(count)
+		}
+
+		for lat, count := range shell.LonCounts {
+			record = resizeBuffer(record, count*voxelRecordSize)
+			if _, err := io.ReadFull(r, record); err != nil {
+				return nil, fmt.Errorf("reading shell %d band %d: %w", shellIdx, lat, err)
+			}
+			shell.Voxels[lat] = decodeVoxels(record, count)
+		}
+	}
+
+	return planet, nil
+}
+
+// resizeBuffer returns b resized to n bytes, reusing its storage when it fits
+func resizeBuffer(b []byte, n int) []byte {
+	if cap(b) < n {
+		return make([]byte, n)
+	}
+	return b[:n]
+}
+
+// encodeVoxels appends the records of voxels to b
+func encodeVoxels(b []byte, voxels []VoxelMaterial) []byte {
+	start := len(b)
+	b = append(b, make([]byte, len(voxels)*voxelRecordSize)...)
+	c := voxelCodec{buf: b[start:], write: true}
+	for i := range voxels {
+		codeVoxel(&c, &voxels[i])
+	}
+	return b
+}
+
+// decodeVoxels parses count voxel records from b
+func decodeVoxels(b []byte, count int) []VoxelMaterial {
+	voxels := make([]VoxelMaterial, count)
+	c := voxelCodec{buf: b}
+	for i := range voxels {
+		codeVoxel(&c, &voxels[i])
+	}
+	return voxels
+}
+
+// voxelCodec reads or writes little-endian fields at a moving offset
+type voxelCodec struct {
+	buf   []byte
+	off   int
+	write bool
+}
+
+func (c *voxelCodec) u8(p *uint8) {
+	if c.write {
+		c.buf[c.off] = *p
+	} else {
+		*p = c.buf[c.off]
+	}
+	c.off++
+}
+
+func (c *voxelCodec) flag(p *bool) {
+	if c.write {
+		c.buf[c.off] = 0
+		if *p {
+			c.buf[c.off] = 1
+		}
+	} else {
+		*p = c.buf[c.off] != 0
+	}
+	c.off++
+}
+
+func (c *voxelCodec) u32(p *uint32) {
+	if c.write {
+		binary.LittleEndian.PutUint32(c.buf[c.off:], *p)
+	} else {
+		*p = binary.LittleEndian.Uint32(c.buf[c.off:])
+	}
+	c.off += 4
+}
+
+func (c *voxelCodec) f32(p *float32) {
+	bits := math.Float32bits(*p)
+	c.u32(&bits)
+	*p = math.Float32frombits(bits)
+}
+
+func (c *voxelCodec) i32(p *int32) {
+	bits := uint32(*p)
+	c.u32(&bits)
+	*p = int32(bits)
+}
+
+// codeVoxel runs every field of a voxel through c in save order, so reading
+// and writing share one layout of voxelRecordSize bytes
+func codeVoxel(c *voxelCodec, v *VoxelMaterial) {
+	c.u8((*uint8)(&v.Type))
+	c.f32(&v.Density)
+	c.f32(&v.Temperature)
+	c.f32(&v.Pressure)
+	c.f32(&v.VelR)
+	c.f32(&v.VelNorth)
+	c.f32(&v.VelEast)
+	c.f32(&v.Age)
+	c.f32(&v.Stress)
+	c.f32(&v.Composition)
+	c.f32(&v.YieldStrength)
+	c.flag(&v.IsBrittle)
+	c.flag(&v.IsFractured)
+	c.i32(&v.PlateID)
+	c.f32(&v.SubPosLat)
+	c.f32(&v.SubPosLon)
+	c.f32(&v.SubPosR)
+	c.f32(&v.Elevation)
+	c.f32(&v.WaterVolume)
+	for i := range v.WaterVelocity {
+		c.f32(&v.WaterVelocity[i])
+	}
+	c.f32(&v.WaterVapor)
+	c.f32(&v.CloudDensity)
+	c.f32(&v.Precipitation)
+	c.f32(&v.IceThickness)
+	c.f32(&v.Sediment)
+	c.u8((*uint8)(&v.Biome))
+	c.f32(&v.MeltFraction)
+	c.f32(&v.FracLon)
+	c.f32(&v.FracLat)
+	c.f32(&v.LastMoveTime)
+	c.flag(&v.IsTransient)
+	c.i32(&v.SourcePlateID)
+	c.f32(&v.StretchFactor)
+}
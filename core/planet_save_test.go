@@ -0,0 +1,153 @@
+package core
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fillVoxelFields sets every field of v to a value derived from seed and the
+// field's position, so a field the save format drops comes back different
+func fillVoxelFields(v *VoxelMaterial, seed int) {
+	value := reflect.ValueOf(v).Elem()
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Field(i)
+		n := seed*64 + i + 1
+		switch field.Kind() {
+		case reflect.Uint8:
+			field.SetUint(uint64(n % 251))
+		case reflect.Bool:
+			field.SetBool((seed+i)%2 == 0)
+		case reflect.Int32:
+			field.SetInt(int64(n))
+		case reflect.Float32:
+			field.SetFloat(float64(n) * 1.25)
+		case reflect.Array:
+			for j := 0; j < field.Len(); j++ {
+				field.Index(j).SetFloat(float64(n*10+j) * 0.5)
+			}
+		default:
+			panic("voxel field " + value.Type().Field(i).Name + " has no test value")
+		}
+	}
+}
+
+// TestSavePlanetRoundTrip checks every voxel field, the grid and the planet
+// settings survive a save and load
+func TestSavePlanetRoundTrip(t *testing.T) {
+	planet := CreateVoxelPlanetWithSurfaceBands(6371000.0, 5, 12, 24)
+	planet.Time = 1.5e8
+	planet.SeaLevel = -42.5
+	planet.SetSeed(7)
+	planet.CoreBoundary = CoreBoundaryFixedFlux
+	planet.CoreHeatFlux = 0.09
+	planet.MaxPlates = 12
+	planet.MaxPlateVelocity = 8
+	planet.Flattening = 0.00335
+	planet.SeaLevelForced = true
+	planet.SeaLevelTarget = 30
+	planet.InitialRadiogenicHeat = 2e-6
+	planet.HeatHalfLifeYears = 3e9
+	planet.PlateHues = map[int32]float32{1: 137.5, 4: 200, 9: 12.5}
+	planet.PlateTracks = map[int32][]PlateTrackPoint{
+		1: {{Time: 1e8, Lat: 10, Lon: 170}, {Time: 1.5e8, Lat: 12.5, Lon: -178}},
+		9: {{Time: 1.5e8, Lat: -40, Lon: 3}},
+	}
+	for shellIdx := range planet.Shells {
+		for latIdx := range planet.Shells[shellIdx].Voxels {
+			for lonIdx := range planet.Shells[shellIdx].Voxels[latIdx] {
+				fillVoxelFields(&planet.Shells[shellIdx].Voxels[latIdx][lonIdx], shellIdx*10000+latIdx*100+lonIdx)
+			}
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "planet.vox")
+	if err := SavePlanet(planet, path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadPlanet(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Time != planet.Time || loaded.SeaLevel != planet.SeaLevel || loaded.Seed() != 7 || loaded.Radius != planet.Radius {
+		t.Errorf("loaded time %g, sea level %g, seed %d, radius %g", loaded.Time, loaded.SeaLevel, loaded.Seed(), loaded.Radius)
+	}
+	if loaded.CoreBoundary != CoreBoundaryFixedFlux || loaded.CoreHeatFlux != 0.09 || loaded.MaxPlates != 12 || loaded.MaxPlateVelocity != 8 || loaded.Flattening != 0.00335 ||
+		!loaded.SeaLevelForced || loaded.SeaLevelTarget != 30 ||
+		loaded.InitialRadiogenicHeat != 2e-6 || loaded.HeatHalfLifeYears != 3e9 {
+		t.Errorf("generation settings changed: %+v", loaded)
+	}
+	if !reflect.DeepEqual(loaded.PlateHues, planet.PlateHues) {
+		t.Errorf("plate colors %v, want %v", loaded.PlateHues, planet.PlateHues)
+	}
+	if !reflect.DeepEqual(loaded.PlateTracks, planet.PlateTracks) {
+		t.Errorf("plate tracks %v, want %v", loaded.PlateTracks, planet.PlateTracks)
+	}
+	if len(loaded.Shells) != len(planet.Shells) {
+		t.Fatalf("loaded %d shells, want %d", len(loaded.Shells), len(planet.Shells))
+	}
+	for shellIdx, shell := range planet.Shells {
+		got := loaded.Shells[shellIdx]
+		if got.InnerRadius != shell.InnerRadius || got.OuterRadius != shell.OuterRadius || got.LatBands != shell.LatBands {
+			t.Errorf("shell %d is %g-%g m with %d bands, want %g-%g m with %d", shellIdx,
+				got.InnerRadius, got.OuterRadius, got.LatBands, shell.InnerRadius, shell.OuterRadius, shell.LatBands)
+		}
+		if !reflect.DeepEqual(got.LonCounts, shell.LonCounts) {
+			t.Errorf("shell %d longitude counts %v, want %v", shellIdx, got.LonCounts, shell.LonCounts)
+		}
+		if !reflect.DeepEqual(got.Voxels, shell.Voxels) {
+			t.Fatalf("shell %d voxels changed in the round trip", shellIdx)
+		}
+	}
+}
+
+// TestVoxelRecordSize checks codeVoxel fills exactly one record
+func TestVoxelRecordSize(t *testing.T) {
+	var v VoxelMaterial
+	c := voxelCodec{buf: make([]byte, voxelRecordSize), write: true}
+	codeVoxel(&c, &v)
+	if c.off != voxelRecordSize {
+		t.Errorf("voxel record is %d bytes, voxelRecordSize says %d", c.off, voxelRecordSize)
+	}
+}
+
+// TestLoadPlanetRejectsOtherFiles checks foreign files and other format
+// versions fail with a clear error
+func TestLoadPlanetRejectsOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	foreign := filepath.Join(dir, "foreign.vox")
+	if err := os.WriteFile(foreign, []byte("P6\n1 1\n255\n\x00\x00\x00"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPlanet(foreign); err == nil || !strings.Contains(err.Error(), "not a planet save") {
+		t.Errorf("foreign file loaded with error %v", err)
+	}
+
+	future := filepath.Join(dir, "future.vox")
+	data := append(planetSaveMagic[:], planetSaveVersion+1, 0, 0, 0)
+	if err := os.WriteFile(future, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPlanet(future); err == nil || !strings.Contains(err.Error(), "version") {
+		t.Errorf("newer format loaded with error %v", err)
+	}
+
+	// A save cut short fails instead of returning a partial planet
+	truncated := filepath.Join(dir, "truncated.vox")
+	if err := SavePlanet(CreateVoxelPlanetWithResolution(6371000.0, 4, 12), truncated); err != nil {
+		t.Fatal(err)
+	}
+	full, _ := os.ReadFile(truncated)
+	if err := os.WriteFile(truncated, full[:len(full)-10], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPlanet(truncated); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("truncated save loaded with error %v", err)
+	}
+}
@@ -0,0 +1,14 @@
+package core
+
+// DefaultMaxPlates caps the plate count on a planet that doesn't set its own,
+// a little over Earth's ~15 major and minor plates
+const DefaultMaxPlates = 20
+
+// PlateLimit returns the most plates rifting may leave on the planet,
+// DefaultMaxPlates for a planet that doesn't set a cap
+func (p *VoxelPlanet) PlateLimit() int {
+	if p.MaxPlates <= 0 {
+		return DefaultMaxPlates
+	}
+	return p.MaxPlates
+}
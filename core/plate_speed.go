@@ -0,0 +1,20 @@
+package core
+
+// DefaultMaxPlateVelocity is the fastest a plate may move in cm/year on a
+// planet that doesn't set its own, about twice Earth's fastest plates
+const DefaultMaxPlateVelocity = 20.0
+
+// CmPerYear converts a voxel velocity in m/year to cm/year, the unit plate
+// speeds are quoted in: Earth's plates move 2-10 cm/year
+func CmPerYear(velMetersPerYear float64) float64 {
+	return velMetersPerYear * 100
+}
+
+// PlateSpeedLimit returns the fastest a plate may move at the surface in
+// cm/year, DefaultMaxPlateVelocity for a planet that doesn't set one
+func (p *VoxelPlanet) PlateSpeedLimit() float64 {
+	if p.MaxPlateVelocity <= 0 {
+		return DefaultMaxPlateVelocity
+	}
+	return p.MaxPlateVelocity
+}
@@ -0,0 +1,22 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+// TestCmPerYear checks a plate moving 5 cm/year reads as 5, and that a
+// planet without a speed limit takes the default
+func TestCmPerYear(t *testing.T) {
+	velocity := 0.05 // 5 cm in a year, in m/year
+	if got := CmPerYear(velocity); math.Abs(got-5) > 1e-9 {
+		t.Errorf("%.3g m/year is %g cm/year, want 5", velocity, got)
+	}
+
+	if got := (&VoxelPlanet{}).PlateSpeedLimit(); got != DefaultMaxPlateVelocity {
+		t.Errorf("unset planet caps plates at %g cm/year, want the default %g", got, DefaultMaxPlateVelocity)
+	}
+	if got := (&VoxelPlanet{MaxPlateVelocity: 8}).PlateSpeedLimit(); got != 8 {
+		t.Errorf("planet capped at 8 cm/year caps plates at %g", got)
+	}
+}
@@ -0,0 +1,22 @@
+package core
+
+import "math"
+
+// DefaultRadiogenicHeat is the deep interior's radioactive heating in K per
+// year for a planet that doesn't set its own
+const DefaultRadiogenicHeat = 1e-6
+
+// RadiogenicHeat returns the deep interior's radioactive heating rate in K
+// per year at the planet's current time. It starts at InitialRadiogenicHeat
+// and halves every HeatHalfLifeYears as the isotopes decay. Every physics
+// backend takes its heating from here, so CPU and GPU steps stay alike
+func (p *VoxelPlanet) RadiogenicHeat() float64 {
+	heat := p.InitialRadiogenicHeat
+	if heat <= 0 {
+		heat = DefaultRadiogenicHeat
+	}
+	if p.HeatHalfLifeYears <= 0 || p.Time <= 0 {
+		return heat
+	}
+	return heat * math.Exp2(-p.Time/p.HeatHalfLifeYears)
+}
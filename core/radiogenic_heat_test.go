@@ -0,0 +1,30 @@
+package core
+
+import (
+	"math"
+	"testing"
+)
+
+// TestRadiogenicHeatDecay checks heating halves every half-life and stays
+// constant without one
+func TestRadiogenicHeatDecay(t *testing.T) {
+	planet := &VoxelPlanet{InitialRadiogenicHeat: 4e-6, HeatHalfLifeYears: 2.5e9}
+	for _, c := range []struct {
+		years, want float64
+	}{
+		{0, 4e-6},
+		{2.5e9, 2e-6},
+		{5e9, 1e-6},
+		{1.25e9, 4e-6 / math.Sqrt2},
+	} {
+		planet.Time = c.years
+		if got := planet.RadiogenicHeat(); math.Abs(got-c.want) > 1e-12*c.want {
+			t.Errorf("year %g: heating %g K/year, want %g", c.years, got, c.want)
+		}
+	}
+
+	constant := &VoxelPlanet{Time: 4.5e9}
+	if got := constant.RadiogenicHeat(); got != DefaultRadiogenicHeat {
+		t.Errorf("unset planet heats at %g K/year after 4.5 Gy, want the constant default %g", got, DefaultRadiogenicHeat)
+	}
+}
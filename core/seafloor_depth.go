@@ -0,0 +1,33 @@
+package core
+
+import "math"
+
+const (
+	// RidgeDepth is the depth of the seafloor at a spreading ridge (m)
+	RidgeDepth = 2500.0
+
+	// SubsidenceRate scales the half-space cooling law,
+	// depth = RidgeDepth + SubsidenceRate·√(age in My) (Parsons & Sclater)
+	SubsidenceRate = 350.0
+
+	// MaxSeafloorDepth is where the plate model stops old lithosphere from
+	// sinking further, reached around 130 My (m)
+	MaxSeafloorDepth = 6400.0
+
+	// SeafloorSpreadingRate is how fast seafloor moves away from the ridge
+	// it formed at, a typical half spreading rate (m/year)
+	SeafloorSpreadingRate = 0.025
+
+	// MaxSeafloorAge is about the age of Earth's oldest seafloor; older
+	// ocean floor has subducted (years)
+	MaxSeafloorAge = 180e6
+)
+
+// SeafloorDepth returns how deep below the datum cooling oceanic lithosphere
+// of the given age in years sits. Young seafloor deepens with √age as the
+// plate cools and thickens; old seafloor flattens once the plate reaches its
+// full thickness
+func SeafloorDepth(age float64) float64 {
+	myr := math.Max(age, 0) / 1e6
+	return math.Min(RidgeDepth+SubsidenceRate*math.Sqrt(myr), MaxSeafloorDepth)
+}
@@ -0,0 +1,32 @@
+package core
+
+import "math"
+
+const (
+	// DefaultAxialTilt is Earth's obliquity in degrees
+	DefaultAxialTilt = 23.44
+
+	// NoAxialTilt is the generation AxialTilt for an upright planet with no
+	// seasons, since 0 leaves it at DefaultAxialTilt
+	NoAxialTilt = -1.0
+
+	// DefaultRotationRate is Earth's sidereal spin in radians per second
+	DefaultRotationRate = 7.2921e-5
+)
+
+// RotationRateForPeriod returns the spin in radians per second of a planet
+// whose sidereal day lasts hours. A tidally locked planet turns once per
+// orbit, so its day is its year
+func RotationRateForPeriod(hours float64) float64 {
+	return 2 * math.Pi / (hours * 3600)
+}
+
+// AngularVelocity returns the planet's spin Ω in radians per second,
+// Earth's for a planet that doesn't set one. The Coriolis parameter at a
+// latitude is 2Ω sin(lat)
+func (p *VoxelPlanet) AngularVelocity() float64 {
+	if p.RotationRate <= 0 {
+		return DefaultRotationRate
+	}
+	return p.RotationRate
+}
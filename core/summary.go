@@ -0,0 +1,94 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+)
+
+// PlanetSummary is a handful of planet-wide measures, for logging the
+// outcome of a run in machine-readable form
+type PlanetSummary struct {
+	Time                   float64 `json:"time"`                   // Simulated years
+	LandFraction           float64 `json:"landFraction"`           // Of the surface area
+	OceanFraction          float64 `json:"oceanFraction"`          // Of the surface area
+	PlateCount             int     `json:"plateCount"`             // Plates moving the surface
+	MeanSurfaceTemperature float64 `json:"meanSurfaceTemperature"` // Kelvin, weighted by area
+	HighestPeak            float64 `json:"highestPeak"`            // Meters
+	DeepestTrench          float64 `json:"deepestTrench"`          // Meters
+	SeaLevel               float64 `json:"seaLevel"`               // Meters
+	WaterVolume            float64 `json:"waterVolume"`            // m³ of ocean, see TotalWaterVolume
+}
+
+// Summary measures the planet as it stands. Fractions and the mean
+// temperature weight each surface voxel by its area, as a plain count would
+// let the many small voxels near the poles stand for far more surface than
+// they cover. Plates are counted by the distinct plate IDs on the surface,
+// which physics keeps current, so a headless run counts them too
+func (p *VoxelPlanet) Summary() PlanetSummary {
+	summary := PlanetSummary{
+		Time:        p.Time,
+		SeaLevel:    p.SeaLevel,
+		WaterVolume: p.TotalWaterVolume(),
+	}
+	if len(p.Shells) < 2 {
+		return summary
+	}
+	shell := &p.Shells[len(p.Shells)-2]
+
+	totalArea, landArea, oceanArea, temperature := 0.0, 0.0, 0.0, 0.0
+	highest, deepest := math.Inf(-1), math.Inf(1)
+	plateIDs := make(map[int32]bool)
+	for latIdx := range shell.Voxels {
+		area := p.VoxelArea(shell, latIdx)
+		for lonIdx := range shell.Voxels[latIdx] {
+			voxel := &shell.Voxels[latIdx][lonIdx]
+			if voxel.Type == MatAir {
+				continue
+			}
+			totalArea += area
+			if voxel.Type == MatWater {
+				oceanArea += area
+			} else {
+				landArea += area
+			}
+			temperature += float64(voxel.Temperature) * area
+			highest = math.Max(highest, float64(voxel.Elevation))
+			deepest = math.Min(deepest, float64(voxel.Elevation))
+			if voxel.PlateID > 0 {
+				plateIDs[voxel.PlateID] = true
+			}
+		}
+	}
+	if totalArea == 0 {
+		return summary
+	}
+
+	summary.LandFraction = landArea / totalArea
+	summary.OceanFraction = oceanArea / totalArea
+	summary.MeanSurfaceTemperature = temperature / totalArea
+	summary.HighestPeak = highest
+	summary.DeepestTrench = deepest
+	summary.PlateCount = len(plateIDs)
+	return summary
+}
+
+// WriteSummaryJSON writes the planet's Summary to path as indented JSON
+// It goes through WriteFileAtomic, so an interrupted run never leaves a
+// truncated file behind
+func (p *VoxelPlanet) WriteSummaryJSON(path string) error {
+	data, err := json.MarshalIndent(p.Summary(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode summary: %v", err)
+	}
+
+	err = WriteFileAtomic(path, func(w io.Writer) error {
+		_, err := w.Write(append(data, '\n'))
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write summary file: %v", err)
+	}
+	return nil
+}
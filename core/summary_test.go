@@ -0,0 +1,70 @@
+package core
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestPlanetSummary lays land north of about 30°N, near a quarter of the
+// surface but far more than a quarter of its voxels, and checks the summary weighs it
+// by area and survives the trip through JSON
+func TestPlanetSummary(t *testing.T) {
+	planet := CreateVoxelPlanetWithResolution(6371000.0, 8, 36)
+	planet.Time = 2.5e8
+	shell := &planet.Shells[len(planet.Shells)-2]
+	landCells, cells := 0, 0
+	firstLand := GetBandForLatitude(30, shell.LatBands) + 1
+	for latIdx := range shell.Voxels {
+		for lonIdx := range shell.Voxels[latIdx] {
+			voxel := &shell.Voxels[latIdx][lonIdx]
+			cells++
+			if latIdx >= firstLand {
+				landCells++
+				*voxel = VoxelMaterial{Type: MatGranite, Elevation: 800, Temperature: 250, PlateID: 1}
+			} else {
+				*voxel = VoxelMaterial{Type: MatWater, Elevation: -4000, Temperature: 290, PlateID: 2}
+			}
+		}
+	}
+	shell.Voxels[30][0].Elevation = 6500
+	shell.Voxels[5][3].Elevation = -10500
+
+	// The land is a cap from halfway between its first band and the one below
+	edge := (GetLatitudeForBand(firstLand, shell.LatBands) - 90.0/float64(shell.LatBands-1)) * math.Pi / 180.0
+	wantLand := (1 - math.Sin(edge)) / 2
+	wantTemperature := 250*wantLand + 290*(1-wantLand)
+
+	summary := planet.Summary()
+	if math.Abs(summary.LandFraction-wantLand) > 1e-9 || math.Abs(summary.OceanFraction-(1-wantLand)) > 1e-9 {
+		t.Errorf("land %.4f and ocean %.4f of the surface, want %.4f and %.4f by area (%.4f of the voxels are land)",
+			summary.LandFraction, summary.OceanFraction, wantLand, 1-wantLand, float64(landCells)/float64(cells))
+	}
+	if math.Abs(summary.MeanSurfaceTemperature-wantTemperature) > 1e-6 {
+		t.Errorf("mean surface temperature %.3f K, want %.3f K weighted by area", summary.MeanSurfaceTemperature, wantTemperature)
+	}
+	if summary.HighestPeak != 6500 || summary.DeepestTrench != -10500 {
+		t.Errorf("peak %g m and trench %g m, want 6500 and -10500", summary.HighestPeak, summary.DeepestTrench)
+	}
+	if summary.PlateCount != 2 || summary.Time != 2.5e8 || summary.WaterVolume != planet.TotalWaterVolume() {
+		t.Errorf("summary %+v, want 2 plates at 2.5e8 years", summary)
+	}
+
+	path := filepath.Join(t.TempDir(), "summary.json")
+	if err := planet.WriteSummaryJSON(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded PlanetSummary
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatalf("summary file isn't JSON: %v", err)
+	}
+	if loaded != summary {
+		t.Errorf("summary file holds %+v, want %+v", loaded, summary)
+	}
+}
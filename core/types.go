@@ -38,6 +38,22 @@ func (v Vector3) Normalize() Vector3 {
 	return Vector3{v.X / length, v.Y / length, v.Z / length}
 }
 
+func (v Vector3) Sub(other Vector3) Vector3 {
+	return Vector3{v.X - other.X, v.Y - other.Y, v.Z - other.Z}
+}
+
+func (v Vector3) Dot(other Vector3) float64 {
+	return v.X*other.X + v.Y*other.Y + v.Z*other.Z
+}
+
+func (v Vector3) Cross(other Vector3) Vector3 {
+	return Vector3{
+		v.Y*other.Z - v.Z*other.Y,
+		v.Z*other.X - v.X*other.Z,
+		v.X*other.Y - v.Y*other.X,
+	}
+}
+
 // MeshData is sent to the frontend for rendering
 type MeshData struct {
 	Type         string        `json:"type"`
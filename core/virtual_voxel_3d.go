@@ -418,5 +418,5 @@ func (vvs *VirtualVoxelSystem) MapToGrid() {
 	}
 
 	// Mark as dirty for rendering
-	vvs.Planet.MeshDirty = true
+	vvs.Planet.MarkShellDirty(surfaceShell)
 }
@@ -3,17 +3,67 @@ package core
 import (
 	"fmt"
 	"math"
+	"sync/atomic"
 )
 
+// DefaultSurfaceBands is the latitude resolution of the surface and atmosphere shells
+const DefaultSurfaceBands = 360 // 0.5 degree resolution
+
 // CreateVoxelPlanet initializes a new voxel-based planet
 func CreateVoxelPlanet(radius float64, shellCount int) *VoxelPlanet {
+	return CreateVoxelPlanetWithResolution(radius, shellCount, DefaultSurfaceBands)
+}
+
+// CreateVoxelPlanetWithResolution initializes a planet whose surface and
+// atmosphere shells have surfaceBands latitude bands; deeper shells never
+// exceed that. Coarse grids keep tests and tools fast
+func CreateVoxelPlanetWithResolution(radius float64, shellCount, surfaceBands int) *VoxelPlanet {
+	return CreateVoxelPlanetWithSurfaceBands(radius, shellCount, surfaceBands, surfaceBands)
+}
+
+// CreateVoxelPlanetWithSurfaceBands initializes a planet whose surface and
+// atmosphere shells have surfaceLatBands latitude bands while deeper shells
+// are capped at bands, so coastlines can be sharpened without paying for a
+// finer mantle. Shells of different resolution are matched by position
+func CreateVoxelPlanetWithSurfaceBands(radius float64, shellCount, bands, surfaceLatBands int) *VoxelPlanet {
+	return CreateVoxelPlanetWithShellBands(radius, DefaultShellLatBands(shellCount, bands, surfaceLatBands))
+}
+
+// DefaultShellLatBands is the latitude band schedule of shellCount shells,
+// from the core out: resolution grows with depth toward the surface, capped
+// at bands, and the surface and atmosphere have surfaceLatBands
+func DefaultShellLatBands(shellCount, bands, surfaceLatBands int) []int {
+	schedule := make([]int, shellCount)
+	for i := range schedule {
+		// More latitude bands for outer shells
+		// Use exponential growth for better surface resolution
+		latBands := 20 + i*i*2 // Much higher resolution at surface
+		if latBands > bands {
+			latBands = bands
+		}
+		if i >= shellCount-2 {
+			// Maximum resolution for surface and atmosphere
+			latBands = surfaceLatBands
+		}
+		schedule[i] = latBands
+	}
+	return schedule
+}
+
+// CreateVoxelPlanetWithShellBands initializes a planet with one shell per
+// entry of latBands, from the core out, each with that many latitude bands.
+// A coarse interior under a fine surface saves most of the voxels without
+// losing surface detail; neighboring shells are matched by position
+func CreateVoxelPlanetWithShellBands(radius float64, latBands []int) *VoxelPlanet {
+	shellCount := len(latBands)
 	planet := &VoxelPlanet{
-		Radius:      radius,
-		Mass:        5.972e24, // Earth mass in kg
-		Time:        0,
-		RotationVel: 7.2921e-5, // Earth rotation rate rad/s
-		ActiveCells: make(map[VoxelCoord]bool),
-		MeshDirty:   true,
+		Radius:       radius,
+		Mass:         5.972e24, // Earth mass in kg
+		Time:         0,
+		RotationRate: DefaultRotationRate,
+		AxialTilt:    DefaultAxialTilt,
+		ActiveCells:  make(map[VoxelCoord]bool),
+		MeshDirty:    true,
 	}
 
 	// Create shells from core to surface
@@ -35,18 +85,7 @@ func CreateVoxelPlanet(radius float64, shellCount int) *VoxelPlanet {
 			outer = radius * 1.01 // Thin atmosphere layer
 		}
 
-		// More latitude bands for outer shells
-		// Use exponential growth for better surface resolution
-		latBands := 20 + i*i*2 // Much higher resolution at surface
-		if i >= shellCount-2 {
-			// Maximum resolution for surface and atmosphere
-			latBands = 360 // 0.5 degree resolution
-		}
-		if latBands > 360 {
-			latBands = 360
-		}
-
-		planet.Shells[i] = createSphericalShell(inner, outer, latBands, i, shellCount)
+		planet.Shells[i] = createSphericalShell(inner, outer, latBands[i], i, shellCount)
 	}
 
 	// Initialize material composition
@@ -54,7 +93,7 @@ func CreateVoxelPlanet(radius float64, shellCount int) *VoxelPlanet {
 
 	// Initialize water conservation tracking
 	planet.SeaLevel = 0 // Start at 0m elevation
-	planet.TotalWaterVolume = planet.CalculateTotalWaterVolume()
+	planet.WaterBudget = planet.TotalWaterVolume()
 
 	// Debug: Check velocities in surface shell
 	if len(planet.Shells) >= 2 {
@@ -75,12 +114,12 @@ func CreateVoxelPlanet(radius float64, shellCount int) *VoxelPlanet {
 				}
 			}
 		}
-		fmt.Printf("Initial surface velocities: %d voxels with velocity, max=%.2e m/s (%.1f cm/yr)\n",
-			velCount, maxVel, maxVel*1e9*365.25*24*3600/1e7)
+		fmt.Printf("Initial surface velocities: %d voxels with velocity, max=%.2e m/yr (%.1f cm/yr)\n",
+			velCount, maxVel, CmPerYear(float64(maxVel)))
 	}
 
 	fmt.Printf("Created voxel planet: radius=%.0fm, shells=%d\n", radius, shellCount)
-	fmt.Printf("Initial water volume: %.2e m³\n", planet.TotalWaterVolume)
+	fmt.Printf("Initial water volume: %.2e m³\n", planet.WaterBudget)
 	for i, shell := range planet.Shells {
 		totalVoxels := 0
 		for _, count := range shell.LonCounts {
@@ -152,7 +191,7 @@ func initializePlanetComposition(planet *VoxelPlanet) {
 					voxel.Temperature = 4000 - float32(3000*t) // 4000K to 1000K
 				} else if shellIdx < len(planet.Shells)-2 && avgRadius < earthRadius*0.99 {
 					// Crust - create realistic continental distribution (but not the surface shell)
-					lat := getLatitudeForBand(latIdx, shell.LatBands)
+					lat := GetLatitudeForBand(latIdx, shell.LatBands)
 					lon := float64(lonIdx)/float64(shell.LonCounts[latIdx])*360.0 - 180.0
 
 					// Create several continental masses using multi-scale noise
@@ -218,14 +257,16 @@ func initializePlanetComposition(planet *VoxelPlanet) {
 					voxel.IsBrittle = true
 
 					// Add initial plate velocities (simple eastward drift)
-					// This gives plates something to work with initially
-					voxel.VelEast = 3e-9 * float32(1+0.5*math.Sin(lat*0.1)) // ~3-4.5 cm/year at equator
+					// This gives plates a direction to work with initially. At a few
+					// nm/year they are only seeds: plate motion sets real speeds,
+					// and the boundary mechanics are tuned to seeds this small
+					voxel.VelEast = 3e-9 * float32(1+0.5*math.Sin(lat*0.1)) // m/year
 					// Add some variation in latitude velocity too
 					voxel.VelNorth = 1e-9 * float32(math.Sin(lon*0.05)) // Small N-S drift
 				} else if shellIdx == len(planet.Shells)-2 {
 					// Surface shell - Initialize as all ocean
 					// This will be overwritten by generateRandomContinents if using CreateRandomizedPlanet
-					lat := getLatitudeForBand(latIdx, shell.LatBands)
+					lat := GetLatitudeForBand(latIdx, shell.LatBands)
 					
 					// Default to ocean
 					voxel.Type = MatWater
@@ -237,7 +278,7 @@ func initializePlanetComposition(planet *VoxelPlanet) {
 					voxel.VelEast = 0
 				} else {
 					// Atmosphere
-					lat := getLatitudeForBand(latIdx, shell.LatBands)
+					lat := GetLatitudeForBand(latIdx, shell.LatBands)
 					voxel.Type = MatAir
 					voxel.Density = MaterialProperties[MatAir].DefaultDensity *
 						float32(math.Exp(-(avgRadius-earthRadius)/8000)) // Exponential atmosphere
@@ -254,10 +295,20 @@ func initializePlanetComposition(planet *VoxelPlanet) {
 	}
 }
 
-// getLatitudeForBand converts a latitude band index to degrees
-func getLatitudeForBand(latIdx int, latBands int) float64 {
-	// Map from 0..latBands-1 to -90..+90 degrees
-	return (float64(latIdx)+0.5)/float64(latBands)*180.0 - 90.0
+// VoxelArea returns the area in m² of one voxel in latitude band lat, measured
+// on the shell's outer surface, flattened like the planet. Bands narrow toward
+// the poles faster than their voxel counts shrink, so polar voxels cover far
+// less area than equatorial ones; area and coverage statistics should weight
+// by this
+func (p *VoxelPlanet) VoxelArea(shell *SphericalShell, lat int) float64 {
+	// Each band reaches halfway to its neighbors, so the polar bands are
+	// caps half as tall as the rest
+	center := GetLatitudeForBand(lat, shell.LatBands)
+	halfSpacing := 90.0 / float64(shell.LatBands-1)
+	south := math.Max(center-halfSpacing, -90) * math.Pi / 180.0
+	north := math.Min(center+halfSpacing, 90) * math.Pi / 180.0
+	bandArea := 2 * math.Pi * shell.OuterRadius * shell.OuterRadius * (spheroidBandExtent(north, p.Flattening) - spheroidBandExtent(south, p.Flattening))
+	return bandArea / float64(shell.LonCounts[lat])
 }
 
 // GetVoxel retrieves a voxel at the specified coordinates
@@ -278,6 +329,101 @@ func (p *VoxelPlanet) GetVoxel(coord VoxelCoord) *VoxelMaterial {
 	return &shell.Voxels[coord.Lat][coord.Lon]
 }
 
+// VoxelAtGeographic finds the voxel containing a geographic position
+// lat/lon are in degrees, alt is meters above the planet's surface (negative = depth)
+// Returns nil and a coordinate with Shell = -1 if the altitude is outside the grid
+func (p *VoxelPlanet) VoxelAtGeographic(lat, lon, alt float64) (*VoxelMaterial, VoxelCoord) {
+	shellIdx := p.shellAtAltitude(lat, alt)
+	if shellIdx < 0 {
+		return nil, VoxelCoord{Shell: -1}
+	}
+	shell := &p.Shells[shellIdx]
+
+	latIdx, lonIdx := shell.indexAt(lat, lon)
+	coord := VoxelCoord{Shell: shellIdx, Lat: latIdx, Lon: lonIdx}
+	return &shell.Voxels[latIdx][lonIdx], coord
+}
+
+// GetVoxelAtLatLon finds the voxel at a geographic position for tools that
+// think in coordinates rather than grid indices. lat/lon are in degrees, alt
+// is meters above the planet's surface (negative = depth). It picks the same
+// voxel as VoxelAtGeographic, so the poles fall in the first and last bands.
+// Longitudes of any size wrap, so 180° and -180° are the same voxel. It
+// returns false when the position is outside the grid or the latitude
+// beyond a pole
+func (p *VoxelPlanet) GetVoxelAtLatLon(latDeg, lonDeg, altMeters float64) (*VoxelMaterial, VoxelCoord, bool) {
+	if !(latDeg >= -90 && latDeg <= 90) || math.IsNaN(lonDeg) || math.IsInf(lonDeg, 0) {
+		return nil, VoxelCoord{Shell: -1}, false
+	}
+	shellIdx := p.shellAtAltitude(latDeg, altMeters)
+	if shellIdx < 0 {
+		return nil, VoxelCoord{Shell: -1}, false
+	}
+	shell := &p.Shells[shellIdx]
+
+	latIdx, lonIdx := shell.indexAt(latDeg, math.Mod(lonDeg, 360))
+	coord := VoxelCoord{Shell: shellIdx, Lat: latIdx, Lon: lonIdx}
+	return &shell.Voxels[latIdx][lonIdx], coord, true
+}
+
+// shellAtAltitude returns the index of the shell alt meters above the
+// surface at lat in degrees, or -1 if the altitude is outside the grid
+func (p *VoxelPlanet) shellAtAltitude(lat, alt float64) int {
+	return p.ShellForRadius(p.ShellRadiusAt(p.Radius, lat)+alt, lat)
+}
+
+// Column returns the voxel at lat/lon in degrees from every shell, ordered
+// from the innermost shell to the outermost. Shells have different
+// resolutions, so each one is indexed separately at the same location
+func (p *VoxelPlanet) Column(lat, lon float64) []*VoxelMaterial {
+	column := make([]*VoxelMaterial, len(p.Shells))
+	for i := range p.Shells {
+		shell := &p.Shells[i]
+		latIdx, lonIdx := shell.indexAt(lat, lon)
+		column[i] = &shell.Voxels[latIdx][lonIdx]
+	}
+	return column
+}
+
+// indexAt returns the band and longitude index of the voxel containing
+// lat/lon in degrees
+func (s *SphericalShell) indexAt(lat, lon float64) (latIdx, lonIdx int) {
+	// The band whose GetLatitudeForBand latitude is nearest, as the
+	// generator and physics place bands
+	latIdx = GetBandForLatitude(lat, s.LatBands)
+
+	// Longitude resolution varies per band
+	lonIdx = GetIndexForLongitude(lon, s.LonCounts[latIdx])
+	return latIdx, lonIdx
+}
+
+// VoxelLatLon returns the geographic center of a voxel in degrees
+// This is the inverse of VoxelAtGeographic
+func (p *VoxelPlanet) VoxelLatLon(coord VoxelCoord) (lat, lon float64) {
+	shell := &p.Shells[coord.Shell]
+	lat = GetLatitudeForBand(coord.Lat, shell.LatBands)
+	lon = (float64(coord.Lon)+0.5)/float64(shell.LonCounts[coord.Lat])*360.0 - 180.0
+	return lat, lon
+}
+
+// ShellForRadius returns the index of the shell containing the point r meters
+// from the center at latitude latDeg, where a flattened planet's shells lie
+// closer in than at the equator (see ShellRadiusAt)
+// Shell boundaries belong to the outer shell; returns -1 outside the grid
+func (p *VoxelPlanet) ShellForRadius(r, latDeg float64) int {
+	scale := p.ShellRadiusAt(1, latDeg)
+	for i := range p.Shells {
+		shell := &p.Shells[i]
+		if r < shell.InnerRadius*scale {
+			break
+		}
+		if r < shell.OuterRadius*scale || (i == len(p.Shells)-1 && r == shell.OuterRadius*scale) {
+			return i
+		}
+	}
+	return -1
+}
+
 // GetSurfaceVoxel finds the topmost non-air voxel at a given lat/lon
 func (p *VoxelPlanet) GetSurfaceVoxel(lat, lon int) (*VoxelMaterial, int) {
 	// Search from top shell downward
@@ -317,77 +463,40 @@ func (p *VoxelPlanet) ClearActiveCells() {
 	p.ActiveCells = make(map[VoxelCoord]bool)
 }
 
-// CalculateTotalWaterVolume computes the total water volume on the planet
-func (p *VoxelPlanet) CalculateTotalWaterVolume() float64 {
-	totalVolume := 0.0
+// shellVersions numbers shell changes across every planet, so two shells with
+// the same version hold the same voxels even when one is a copy
+var shellVersions atomic.Uint64
 
-	// Only check surface shell for water
-	if len(p.Shells) < 2 {
-		return 0
-	}
-
-	surfaceShell := len(p.Shells) - 2
-	shell := &p.Shells[surfaceShell]
-
-	for latIdx := range shell.Voxels {
-		lat := getLatitudeForBand(latIdx, shell.LatBands)
-		latRad := lat * math.Pi / 180.0
-
-		for lonIdx := range shell.Voxels[latIdx] {
-			voxel := &shell.Voxels[latIdx][lonIdx]
-
-			if voxel.Type == MatWater {
-				// Calculate voxel volume considering spherical geometry
-				// Volume = r²Δr × ΔθΔφ × sin(θ)
-				r := (shell.InnerRadius + shell.OuterRadius) / 2
-				dr := shell.OuterRadius - shell.InnerRadius
-				dTheta := math.Pi / float64(shell.LatBands)
-				dPhi := 2 * math.Pi / float64(shell.LonCounts[latIdx])
-
-				// Volume in m³
-				volume := r * r * dr * dTheta * dPhi * math.Abs(math.Cos(latRad))
-
-				// Consider water depth (elevation below sea level)
-				if voxel.Elevation < 0 {
-					// Water column height = sea level - ocean floor
-					waterDepth := p.SeaLevel - float64(voxel.Elevation)
-					// Adjust volume based on actual water depth vs shell thickness
-					depthRatio := waterDepth / dr
-					if depthRatio > 1 {
-						depthRatio = 1 // Cap at shell thickness
-					}
-					volume *= depthRatio
-				}
+// MarkShellDirty records that a shell's voxels changed, so consumers like the
+// renderer's textures take it again. Fields nothing draws, like pressure and
+// age, change without it
+func (p *VoxelPlanet) MarkShellDirty(shellIdx int) {
+	p.Shells[shellIdx].Version = shellVersions.Add(1)
+	p.MeshDirty = true
+}
 
-				totalVolume += volume
-			}
-		}
+// MarkAllShellsDirty records that every shell changed
+func (p *VoxelPlanet) MarkAllShellsDirty() {
+	for i := range p.Shells {
+		p.MarkShellDirty(i)
 	}
-
-	return totalVolume
 }
 
-// UpdateSeaLevel recalculates sea level to maintain constant water volume
-func (p *VoxelPlanet) UpdateSeaLevel() {
-	if p.TotalWaterVolume <= 0 {
-		// Initialize on first call
-		p.TotalWaterVolume = p.CalculateTotalWaterVolume()
-		p.SeaLevel = 0 // Initial sea level at 0m
-		return
-	}
+// TotalWaterVolume returns the ocean water on the planet now: the column of
+// every water voxel of the surface shell from its floor up to sea level. It
+// is what UpdateSeaLevel conserves against WaterBudget
+func (p *VoxelPlanet) TotalWaterVolume() float64 {
+	return p.CalculateWaterVolumeAtSeaLevel(p.SeaLevel)
+}
 
-	// Binary search for the sea level that gives us the target water volume
+// SeaLevelForVolume returns the sea level at which the planet's water voxels
+// hold volume m³, to within a centimeter
+func (p *VoxelPlanet) SeaLevelForVolume(volume float64) float64 {
 	minLevel := -5000.0 // Deepest ocean
 	maxLevel := 1000.0  // Potential high sea level
-	tolerance := 10.0   // Increased tolerance to 10 meters to reduce oscillation
-
-	for maxLevel-minLevel > tolerance {
+	for maxLevel-minLevel > 0.01 {
 		testLevel := (minLevel + maxLevel) / 2
-
-		// Calculate volume at this sea level
-		currentVolume := p.CalculateWaterVolumeAtSeaLevel(testLevel)
-
-		if currentVolume < p.TotalWaterVolume {
+		if p.CalculateWaterVolumeAtSeaLevel(testLevel) < volume {
 			// Need higher sea level
 			minLevel = testLevel
 		} else {
@@ -395,8 +504,19 @@ func (p *VoxelPlanet) UpdateSeaLevel() {
 			maxLevel = testLevel
 		}
 	}
+	return (minLevel + maxLevel) / 2
+}
 
-	newSeaLevel := (minLevel + maxLevel) / 2
+// UpdateSeaLevel recalculates sea level to maintain constant water volume
+func (p *VoxelPlanet) UpdateSeaLevel() {
+	if p.WaterBudget <= 0 {
+		// Initialize on first call
+		p.SeaLevel = 0 // Initial sea level at 0m
+		p.WaterBudget = p.TotalWaterVolume()
+		return
+	}
+
+	newSeaLevel := p.SeaLevelForVolume(p.WaterBudget)
 
 	// Apply damping to prevent oscillation
 	// Only update if change is significant (more than 20 meters)
@@ -406,7 +526,44 @@ func (p *VoxelPlanet) UpdateSeaLevel() {
 	}
 }
 
-// CalculateWaterVolumeAtSeaLevel calculates water volume if sea level was at given elevation
+// SetSeaLevelTarget forces sea level toward meters at rateMetersPerMy instead of
+// solving for it from the water budget, until ClearSeaLevelTarget is called
+// A rate of 0 moves sea level to the target on the next step
+func (p *VoxelPlanet) SetSeaLevelTarget(meters, rateMetersPerMy float64) {
+	p.SeaLevelForced = true
+	p.SeaLevelTarget = meters
+	p.SeaLevelRate = math.Abs(rateMetersPerMy)
+}
+
+// ClearSeaLevelTarget returns sea level to water conservation
+// The budget is reset to the ocean the forcing left behind, so sea level
+// stays where it is rather than snapping back
+func (p *VoxelPlanet) ClearSeaLevelTarget() {
+	p.SeaLevelForced = false
+	p.WaterBudget = p.TotalWaterVolume()
+}
+
+// StepSeaLevel advances sea level by dt years, following the forcing target
+// when one is set and the water budget otherwise
+func (p *VoxelPlanet) StepSeaLevel(dt float64) {
+	if !p.SeaLevelForced {
+		p.UpdateSeaLevel()
+		return
+	}
+
+	remaining := p.SeaLevelTarget - p.SeaLevel
+	maxChange := p.SeaLevelRate * dt / 1e6
+	if p.SeaLevelRate == 0 || math.Abs(remaining) <= maxChange {
+		p.SeaLevel = p.SeaLevelTarget
+	} else {
+		p.SeaLevel += math.Copysign(maxChange, remaining)
+	}
+}
+
+// CalculateWaterVolumeAtSeaLevel calculates the ocean water volume if sea
+// level was at the given elevation: each water voxel of the surface shell
+// holds the column from its floor up to sea level, at most the shell's
+// thickness. Land and air hold none, whatever their elevation
 func (p *VoxelPlanet) CalculateWaterVolumeAtSeaLevel(seaLevel float64) float64 {
 	totalVolume := 0.0
 
@@ -416,34 +573,23 @@ func (p *VoxelPlanet) CalculateWaterVolumeAtSeaLevel(seaLevel float64) float64 {
 
 	surfaceShell := len(p.Shells) - 2
 	shell := &p.Shells[surfaceShell]
+	dr := shell.OuterRadius - shell.InnerRadius
 
 	for latIdx := range shell.Voxels {
-		lat := getLatitudeForBand(latIdx, shell.LatBands)
-		latRad := lat * math.Pi / 180.0
+		area := p.VoxelArea(shell, latIdx)
 
 		for lonIdx := range shell.Voxels[latIdx] {
 			voxel := &shell.Voxels[latIdx][lonIdx]
+			if voxel.Type != MatWater {
+				continue
+			}
 
-			// Any voxel below sea level contributes to water volume
-			if float64(voxel.Elevation) < seaLevel {
-				r := (shell.InnerRadius + shell.OuterRadius) / 2
-				dr := shell.OuterRadius - shell.InnerRadius
-				dTheta := math.Pi / float64(shell.LatBands)
-				dPhi := 2 * math.Pi / float64(shell.LonCounts[latIdx])
-
-				// Base voxel volume
-				volume := r * r * dr * dTheta * dPhi * math.Abs(math.Cos(latRad))
-
-				// Water depth at this location
-				waterDepth := seaLevel - float64(voxel.Elevation)
-				depthRatio := waterDepth / dr
-				if depthRatio > 1 {
-					depthRatio = 1
-				}
-				volume *= depthRatio
-
-				totalVolume += volume
+			// Water depth at this location
+			waterDepth := seaLevel - float64(voxel.Elevation)
+			if waterDepth <= 0 {
+				continue
 			}
+			totalVolume += area * math.Min(waterDepth, dr)
 		}
 	}
 
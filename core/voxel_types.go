@@ -13,6 +13,26 @@ const (
 	MatSediment   // Accumulated sediments
 	MatIce        // Frozen water
 	MatSand       // Weathered rock
+	MatEclogite   // High-pressure form of subducted basalt
+)
+
+// CoreBoundaryMode selects the thermal boundary condition at the core-mantle boundary
+type CoreBoundaryMode uint8
+
+const (
+	CoreBoundaryNone             CoreBoundaryMode = iota // Legacy deep-shell heating
+	CoreBoundaryFixedTemperature                         // Innermost shell held at CoreTemperature
+	CoreBoundaryFixedFlux                                // CoreHeatFlux enters through the innermost shell
+)
+
+// PhysicsCheckMode selects what happens when a physics phase leaves a voxel in
+// an impossible state
+type PhysicsCheckMode uint8
+
+const (
+	PhysicsCheckOff  PhysicsCheckMode = iota // No checking
+	PhysicsCheckLog                          // Log the first offending voxel
+	PhysicsCheckHalt                         // Log it and stop stepping
 )
 
 // PhaseType represents the state of matter
@@ -33,6 +53,8 @@ type VoxelMaterial struct {
 	Pressure    float32 // Pascals
 
 	// Material flow velocity in spherical coordinates (m/s)
+	// Rock velocities are in m/year, matching the simulation's time step;
+	// air voxels carry the atmosphere's winds in m/s instead
 	// VelR: radial velocity (positive = outward)
 	// VelNorth: latitudinal velocity (positive = northward)
 	// VelEast: longitudinal velocity (positive = eastward)
@@ -69,9 +91,19 @@ type VoxelMaterial struct {
 	WaterVolume   float32    // Volume of water in this cell (0-1, where 1 = full)
 	WaterVelocity [3]float32 // Water flow velocity (m/s) in spherical coords [r, theta, phi]
 
-	// Atmospheric water (for future precipitation)
-	WaterVapor   float32 // Atmospheric water content (kg/m³)
-	CloudDensity float32 // Cloud formation (0-1)
+	// Atmospheric water and the rain it drops
+	WaterVapor    float32 // Atmospheric water content (kg/m³)
+	CloudDensity  float32 // Cloud formation (0-1)
+	Precipitation float32 // Rain and snow reaching a surface voxel (m/year)
+
+	// Glacial ice resting on a land voxel, see physics.UpdateIceSheets
+	IceThickness float32 // Meters
+
+	// Eroded rock on its way downslope, see physics.TransportSediment
+	Sediment float32 // Meters, as a layer over the voxel's area
+
+	// Climate zone of a surface voxel (see physics.ClassifyBiomes)
+	Biome Biome
 
 	// Melting state
 	MeltFraction float32 // Fraction of material that is molten (0-1)
@@ -87,6 +119,15 @@ type VoxelMaterial struct {
 	StretchFactor float32 // How much this voxel is stretched (1.0 = normal)
 }
 
+// SurfaceType returns the material a voxel shows from above: ice where an ice
+// sheet covers it, otherwise its own type
+func (v *VoxelMaterial) SurfaceType() MaterialType {
+	if v.IceThickness > 0 {
+		return MatIce
+	}
+	return v.Type
+}
+
 // Compatibility methods for coordinate system transition
 // These provide clearer naming while maintaining backward compatibility
 
@@ -141,6 +182,10 @@ type SphericalShell struct {
 
 	// Longitude divisions per latitude band
 	LonCounts []int
+
+	// Changes with the voxels, see MarkShellDirty (0 = never marked, so
+	// always treated as changed)
+	Version uint64
 }
 
 // VoxelPlanet represents the entire planet as a voxel grid
@@ -149,10 +194,12 @@ type VoxelPlanet struct {
 	Shells []SphericalShell
 
 	// Planet properties
-	Radius      float64 // Surface radius in meters
-	Mass        float64 // Total mass in kg
-	Time        float64 // Simulation time in years
-	RotationVel float64 // Radians per second
+	Radius       float64 // Surface radius in meters, at the equator when flattened
+	Flattening   float64 // Polar flattening (a-b)/a of every shell, see ShellRadiusAt (0 = sphere)
+	Mass         float64 // Total mass in kg
+	Time         float64 // Simulation time in years, 0 at a northern spring equinox
+	RotationRate float64 // Spin in radians per second
+	AxialTilt    float64 // Obliquity in degrees, sets the seasons
 
 	// Optimization structures
 	ActiveCells map[VoxelCoord]bool // Cells needing updates
@@ -163,15 +210,91 @@ type VoxelPlanet struct {
 
 	// Physics subsystems (created on demand)
 	Physics interface{} // *physics.VoxelPhysics but avoid import cycle
+	Seismic interface{} // *physics.SeismicLog, shared by every copy of the planet
 
 	// Virtual voxel system (optional)
 	VirtualVoxelSystem *VirtualVoxelSystem
 	UseVirtualVoxels   bool
 
+	// Core-mantle boundary condition
+	CoreBoundary    CoreBoundaryMode
+	CoreTemperature float64 // Kelvin, used by CoreBoundaryFixedTemperature
+	CoreHeatFlux    float64 // W/m² into the mantle, used by CoreBoundaryFixedFlux
+
+	// Subduction geometry
+	SlabDip float64 // Degrees below horizontal, sets trench-to-arc distance (0 = default)
+
+	// Surface stability
+	MaxElevationRate float64 // Max crustal elevation change in m/year (0 = unlimited)
+
+	// Plate topology
+	MaxPlates      int                         // Cap on plates created by rifting, see PlateLimit (0 = DefaultMaxPlates)
+	ReferencePlate int                         // Plate held still, other plates move relative to it (0 = absolute frame)
+	PlateHues      map[int32]float32           // Plate view hue of each plate in degrees, see simulation.PlateColorRegistry
+	PlateTracks    map[int32][]PlateTrackPoint // Where each plate has been, oldest first, see simulation.PlateTrack
+	Plates         interface{}                 // *simulation.PlateSnapshot of this state, published by physics for other threads
+
+	// Baseline mantle flow, for visible drift over strict physical fidelity
+	ConvectionForcing float64 // Minimum plate speed in cm/year (0 = forces only)
+	MaxPlateVelocity  float64 // Maximum plate speed in cm/year, see PlateSpeedLimit (0 = DefaultMaxPlateVelocity)
+
+	// Atmosphere
+	GreenhouseStrength float64 // Longwave emissivity of the air layer, 0-1 (0 = Earth default, GreenhouseOff = none)
+
+	// Radioactive heating of the deep interior, see RadiogenicHeat
+	InitialRadiogenicHeat float64 // K per year at Time 0 (0 = DefaultRadiogenicHeat)
+	HeatHalfLifeYears     float64 // Years for heat production to halve (0 = no decay)
+
 	// Global conservation tracking
-	TotalWaterVolume float64 // Total water volume on planet (m³)
-	TotalRockVolume  float64 // Total rock volume (for mass conservation)
-	SeaLevel         float64 // Current sea level elevation (m)
+	WaterBudget     float64 // Ocean water the planet conserves (m³), see TotalWaterVolume
+	TotalRockVolume float64 // Total rock volume (for mass conservation)
+	SeaLevel        float64 // Current sea level elevation (m)
+
+	// Sea level forcing for climate scenarios, overrides water conservation
+	SeaLevelForced bool    // Sea level follows SeaLevelTarget instead of the water budget
+	SeaLevelTarget float64 // Elevation sea level is moving toward (m)
+	SeaLevelRate   float64 // Rate of approach in m per million years (0 = jump)
+
+	// Sanity checking after every physics phase, for tracking down instability
+	PhysicsCheck PhysicsCheckMode
+	PhysicsFault error // First impossible state the check found (nil = none)
+
+	// Goroutines the per-band CPU physics passes split across (0 or 1 = serial)
+	WorkerThreads int
+
+	// Hotspots rising from the core-mantle boundary, kept hot every step
+	Plumes []MantlePlume
+
+	// Random seed the planet was generated from (0 = not generated from a seed)
+	seed int64
+}
+
+// MantlePlume is a persistent upwelling rooted at the core-mantle boundary,
+// the source of hotspot volcanism and island chains
+type MantlePlume struct {
+	Lat, Lon         float64 // Degrees
+	TemperatureBoost float64 // K above the surrounding mantle at the plume's center
+}
+
+// PlateTrackPoint is where a plate's centroid stood at one time in its past
+type PlateTrackPoint struct {
+	Time     float64 // Years
+	Lat, Lon float64 // Degrees
+}
+
+// Seed returns the random seed the planet was generated from
+func (p *VoxelPlanet) Seed() int64 {
+	return p.seed
+}
+
+// SetSeed records the random seed that produced the planet
+func (p *VoxelPlanet) SetSeed(seed int64) {
+	p.seed = seed
+}
+
+// Halted reports whether a physics check stopped the simulation
+func (p *VoxelPlanet) Halted() bool {
+	return p.PhysicsCheck == PhysicsCheckHalt && p.PhysicsFault != nil
 }
 
 // TriangleMesh for rendering
@@ -182,15 +305,22 @@ type TriangleMesh struct {
 	Triangles []int32
 }
 
-// Material properties database
-var MaterialProperties = map[MaterialType]struct {
+// MaterialProps holds the physical constants of a material
+type MaterialProps struct {
 	DefaultDensity      float32
 	MeltingPoint        float32 // Kelvin at 1 atm
 	SpecificHeat        float32 // J/(kg·K)
 	ThermalConductivity float32 // W/(m·K)
 	Viscosity           float32 // Pa·s (for liquids/gases)
 	Strength            float32 // Pa (yield strength for solids)
-}{
+
+	// Only used by materials added with RegisterMaterial
+	Name  string
+	Color Vector3 // RGB 0-1
+}
+
+// Material properties database
+var MaterialProperties = map[MaterialType]MaterialProps{
 	MatAir: {
 		DefaultDensity:      1.225,
 		MeltingPoint:        0, // N/A
@@ -247,6 +377,14 @@ var MaterialProperties = map[MaterialType]struct {
 		Viscosity:           1e18, // Deforms slowly
 		Strength:            50e6, // Weaker than solid rock
 	},
+	MatEclogite: {
+		DefaultDensity:      3500, // Denser than the mantle it sinks through
+		MeltingPoint:        1723,
+		SpecificHeat:        1000,
+		ThermalConductivity: 3.0,
+		Viscosity:           1e21,
+		Strength:            400e6,
+	},
 	MatIce: {
 		DefaultDensity:      917,
 		MeltingPoint:        273.15,
@@ -257,6 +395,16 @@ var MaterialProperties = map[MaterialType]struct {
 	},
 }
 
+// MaterialDiffusivity returns thermal diffusivity α = k/(ρ·c) in m²/s
+// Materials without thermal properties don't conduct (returns 0)
+func MaterialDiffusivity(mat MaterialType) float32 {
+	props, ok := MaterialProperties[mat]
+	if !ok || props.DefaultDensity <= 0 || props.SpecificHeat <= 0 {
+		return 0
+	}
+	return props.ThermalConductivity / (props.DefaultDensity * props.SpecificHeat)
+}
+
 // Helper methods
 // Note: GetLatitudeForBand is now defined in coordinates.go
 
@@ -0,0 +1,450 @@
+//go:build cuda
+// +build cuda
+
+package cuda
+
+/*
+#cgo linux CFLAGS: -I/usr/local/cuda/include
+#cgo linux LDFLAGS: -L/usr/local/cuda/lib64 -lcuda -lcudart -lnvrtc
+#cgo windows CFLAGS: -I"${CUDA_PATH}/include"
+#cgo windows LDFLAGS: -L"${CUDA_PATH}/lib/x64" -lcuda -lcudart -lnvrtc
+
+#include <cuda.h>
+#include <cuda_runtime.h>
+#include <nvrtc.h>
+#include <stdio.h>
+#include <stdlib.h>
+
+typedef struct {
+    CUcontext context; // The device's primary context, shared with the runtime API
+    CUmodule module;
+    CUfunction temperature;
+    CUfunction convection;
+    CUfunction storeField;
+} CUDAContext;
+
+static char cudaErrorText[4096];
+
+static const char* driverError(const char* what, CUresult result) {
+    const char* name = "unknown error";
+    cuGetErrorString(result, &name);
+    snprintf(cudaErrorText, sizeof(cudaErrorText), "%s: %s", what, name);
+    return cudaErrorText;
+}
+
+static const char* runtimeError(const char* what, cudaError_t result) {
+    snprintf(cudaErrorText, sizeof(cudaErrorText), "%s: %s", what, cudaGetErrorString(result));
+    return cudaErrorText;
+}
+
+// createCUDAContext picks device 0, compiles source for it with NVRTC and
+// loads the kernels. On failure it returns NULL and sets *err
+static CUDAContext* createCUDAContext(const char* source, const char** err) {
+    int count = 0;
+    cudaError_t status = cudaGetDeviceCount(&count);
+    if (status != cudaSuccess) {
+        *err = runtimeError("no CUDA device", status);
+        return NULL;
+    }
+    if (count == 0) {
+        *err = "no CUDA device found";
+        return NULL;
+    }
+    if ((status = cudaSetDevice(0)) != cudaSuccess || (status = cudaFree(0)) != cudaSuccess) {
+        *err = runtimeError("initializing CUDA device", status);
+        return NULL;
+    }
+    struct cudaDeviceProp prop;
+    if ((status = cudaGetDeviceProperties(&prop, 0)) != cudaSuccess) {
+        *err = runtimeError("querying CUDA device", status);
+        return NULL;
+    }
+
+    char arch[64];
+    snprintf(arch, sizeof(arch), "--gpu-architecture=compute_%d%d", prop.major, prop.minor);
+    const char* options[] = {arch, "--device-as-default-execution-space"};
+
+    nvrtcProgram program;
+    if (nvrtcCreateProgram(&program, source, "physics.cu", 0, NULL, NULL) != NVRTC_SUCCESS) {
+        *err = "creating CUDA program failed";
+        return NULL;
+    }
+    if (nvrtcCompileProgram(program, 2, options) != NVRTC_SUCCESS) {
+        size_t logSize = 0;
+        nvrtcGetProgramLogSize(program, &logSize);
+        char* log = malloc(logSize + 1);
+        nvrtcGetProgramLog(program, log);
+        log[logSize] = 0;
+        snprintf(cudaErrorText, sizeof(cudaErrorText), "compiling CUDA kernels: %s", log);
+        free(log);
+        nvrtcDestroyProgram(&program);
+        *err = cudaErrorText;
+        return NULL;
+    }
+    size_t ptxSize = 0;
+    nvrtcGetPTXSize(program, &ptxSize);
+    char* ptx = malloc(ptxSize);
+    nvrtcGetPTX(program, ptx);
+    nvrtcDestroyProgram(&program);
+
+    CUDAContext* ctx = calloc(1, sizeof(CUDAContext));
+    CUresult result = cuCtxGetCurrent(&ctx->context);
+    if (result == CUDA_SUCCESS) {
+        result = cuModuleLoadData(&ctx->module, ptx);
+    }
+    free(ptx);
+    if (result != CUDA_SUCCESS) {
+        *err = driverError("loading CUDA kernels", result);
+        free(ctx);
+        return NULL;
+    }
+    if ((result = cuModuleGetFunction(&ctx->temperature, ctx->module, "updateTemperatureFast")) != CUDA_SUCCESS ||
+        (result = cuModuleGetFunction(&ctx->convection, ctx->module, "updateConvection")) != CUDA_SUCCESS ||
+        (result = cuModuleGetFunction(&ctx->storeField, ctx->module, "storeVoxelField")) != CUDA_SUCCESS) {
+        *err = driverError("finding CUDA kernels", result);
+        cuModuleUnload(ctx->module);
+        free(ctx);
+        return NULL;
+    }
+    return ctx;
+}
+
+// Every call makes the context current first, since Go may run it on any
+// OS thread
+
+static void releaseCUDAContext(CUDAContext* ctx) {
+    cuCtxSetCurrent(ctx->context);
+    cuModuleUnload(ctx->module);
+    free(ctx);
+}
+
+static const char* deviceAlloc(CUDAContext* ctx, void** ptr, size_t size) {
+    cuCtxSetCurrent(ctx->context);
+    cudaError_t status = cudaMalloc(ptr, size > 0 ? size : 4);
+    return status == cudaSuccess ? NULL : runtimeError("allocating device memory", status);
+}
+
+static void deviceFree(CUDAContext* ctx, void* ptr) {
+    cuCtxSetCurrent(ctx->context);
+    cudaFree(ptr);
+}
+
+static const char* copyToDevice(CUDAContext* ctx, void* dst, const void* src, size_t size) {
+    cuCtxSetCurrent(ctx->context);
+    cudaError_t status = cudaMemcpy(dst, src, size, cudaMemcpyHostToDevice);
+    return status == cudaSuccess ? NULL : runtimeError("copying to the device", status);
+}
+
+static const char* copyFromDevice(CUDAContext* ctx, void* dst, const void* src, size_t size) {
+    cuCtxSetCurrent(ctx->context);
+    cudaError_t status = cudaMemcpy(dst, src, size, cudaMemcpyDeviceToHost);
+    return status == cudaSuccess ? NULL : runtimeError("copying from the device", status);
+}
+
+static const char* launch(CUDAContext* ctx, CUfunction kernel, unsigned int count, unsigned int blockSize, void** args) {
+    cuCtxSetCurrent(ctx->context);
+    unsigned int blocks = (count + blockSize - 1) / blockSize;
+    CUresult result = cuLaunchKernel(kernel, blocks, 1, 1, blockSize, 1, 1, 0, NULL, args, NULL);
+    if (result == CUDA_SUCCESS) {
+        result = cuCtxSynchronize();
+    }
+    return result == CUDA_SUCCESS ? NULL : driverError("running CUDA kernel", result);
+}
+
+static const char* launchTemperature(CUDAContext* ctx, void* voxels, void* neighbors, void* temperatures,
+                                     void* diffusivity, unsigned int voxelCount, float dt, float radiogenicHeat,
+                                     unsigned int blockSize) {
+    void* args[] = {&voxels, &neighbors, &temperatures, &diffusivity, &voxelCount, &dt, &radiogenicHeat};
+    return launch(ctx, ctx->temperature, voxelCount, blockSize, args);
+}
+
+static const char* launchConvection(CUDAContext* ctx, void* voxels, void* neighbors, void* velocities,
+                                    void* viscosity, void* lengths, unsigned int materialCount,
+                                    unsigned int voxelCount, float dt, unsigned int blockSize) {
+    void* args[] = {&voxels, &neighbors, &velocities, &viscosity, &lengths, &materialCount, &voxelCount, &dt};
+    return launch(ctx, ctx->convection, voxelCount, blockSize, args);
+}
+
+static const char* launchStoreField(CUDAContext* ctx, void* voxels, void* values, unsigned int field,
+                                    unsigned int voxelCount, unsigned int blockSize) {
+    void* args[] = {&voxels, &values, &field, &voxelCount};
+    return launch(ctx, ctx->storeField, voxelCount, blockSize, args);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"sync"
+	"unsafe"
+
+	"worldgenerator/core"
+	"worldgenerator/gpu"
+)
+
+// CUDACompute runs heat diffusion and convection on an NVIDIA GPU. The
+// physics engine hands it each planet buffer it steps through
+// StepTemperature and StepConvection; advection stays on the CPU
+type CUDACompute struct {
+	mu  sync.Mutex // The C error buffer and staging slices are shared
+	ctx *C.CUDAContext
+
+	// Device buffers
+	voxels       unsafe.Pointer
+	neighbors    unsafe.Pointer
+	temperatures unsafe.Pointer
+	velocities   unsafe.Pointer
+	diffusivity  unsafe.Pointer
+	viscosity    unsafe.Pointer
+	lengths      unsafe.Pointer
+
+	totalVoxels    int
+	materialCount  int
+	staging        []gpu.GPUVoxelMaterial
+	results        []float32
+	radiogenicHeat float32 // Deep heating in K per year, from the planet stepped last
+}
+
+// NewCUDACompute compiles the kernels for the first CUDA device and uploads
+// the planet's neighbor and material tables. It returns an error when there
+// is no usable device, so callers can fall back to the CPU
+func NewCUDACompute(planet *core.VoxelPlanet) (*CUDACompute, error) {
+	source := C.CString(kernelSource)
+	defer C.free(unsafe.Pointer(source))
+
+	var cErr *C.char
+	ctx := C.createCUDAContext(source, &cErr)
+	if ctx == nil {
+		return nil, fmt.Errorf("CUDA unavailable: %s", C.GoString(cErr))
+	}
+	cc := &CUDACompute{ctx: ctx, radiogenicHeat: float32(planet.RadiogenicHeat())}
+
+	if err := cc.createBuffers(planet); err != nil {
+		cc.Cleanup()
+		return nil, err
+	}
+	fmt.Printf("✅ CUDA compute ready for %d voxels\n", cc.totalVoxels)
+	return cc, nil
+}
+
+// createBuffers allocates the device buffers and uploads the voxels and the
+// tables that don't change between steps
+func (cc *CUDACompute) createBuffers(planet *core.VoxelPlanet) error {
+	neighbors := gpu.NeighborIndices(planet)
+	diffusivity := gpu.MaterialDiffusivities()
+	viscosity := gpu.MaterialViscosityScales()
+	lengths := gpu.ConvectionLengthScales(planet)
+
+	cc.totalVoxels = len(lengths)
+	cc.materialCount = len(viscosity)
+	cc.staging = make([]gpu.GPUVoxelMaterial, cc.totalVoxels)
+	cc.results = make([]float32, cc.totalVoxels)
+	if cc.totalVoxels == 0 {
+		return fmt.Errorf("planet has no voxels")
+	}
+
+	for _, buffer := range []struct {
+		ptr  *unsafe.Pointer
+		size int
+		data unsafe.Pointer
+	}{
+		{&cc.voxels, cc.totalVoxels * gpu.GPUVoxelSize, nil},
+		{&cc.neighbors, len(neighbors) * 4, unsafe.Pointer(&neighbors[0])},
+		{&cc.temperatures, cc.totalVoxels * 4, nil},
+		{&cc.velocities, cc.totalVoxels * 4, nil},
+		{&cc.diffusivity, len(diffusivity) * 4, unsafe.Pointer(&diffusivity[0])},
+		{&cc.viscosity, len(viscosity) * 4, unsafe.Pointer(&viscosity[0])},
+		{&cc.lengths, len(lengths) * 4, unsafe.Pointer(&lengths[0])},
+	} {
+		if msg := C.deviceAlloc(cc.ctx, buffer.ptr, C.size_t(buffer.size)); msg != nil {
+			return fmt.Errorf("CUDA: %s", C.GoString(msg))
+		}
+		if buffer.data != nil {
+			if msg := C.copyToDevice(cc.ctx, *buffer.ptr, buffer.data, C.size_t(buffer.size)); msg != nil {
+				return fmt.Errorf("CUDA: %s", C.GoString(msg))
+			}
+		}
+	}
+	return cc.upload(planet)
+}
+
+// upload copies planet's voxels to the device voxel buffer
+func (cc *CUDACompute) upload(planet *core.VoxelPlanet) error {
+	idx := 0
+	for _, shell := range planet.Shells {
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				if idx >= cc.totalVoxels {
+					return fmt.Errorf("planet has more voxels than the %d the CUDA buffers hold", cc.totalVoxels)
+				}
+				cc.staging[idx] = gpu.ConvertToGPUVoxel(&shell.Voxels[latIdx][lonIdx])
+				idx++
+			}
+		}
+	}
+	if idx != cc.totalVoxels {
+		return fmt.Errorf("planet has %d voxels, CUDA buffers hold %d", idx, cc.totalVoxels)
+	}
+
+	if msg := C.copyToDevice(cc.ctx, cc.voxels, unsafe.Pointer(&cc.staging[0]), C.size_t(idx*gpu.GPUVoxelSize)); msg != nil {
+		return fmt.Errorf("CUDA: %s", C.GoString(msg))
+	}
+	return nil
+}
+
+// download copies a per-voxel result buffer into cc.results
+func (cc *CUDACompute) download(buffer unsafe.Pointer) error {
+	if msg := C.copyFromDevice(cc.ctx, unsafe.Pointer(&cc.results[0]), buffer, C.size_t(cc.totalVoxels*4)); msg != nil {
+		return fmt.Errorf("CUDA: %s", C.GoString(msg))
+	}
+	return nil
+}
+
+// runTemperature diffuses the device voxels' heat into the temperature buffer
+func (cc *CUDACompute) runTemperature(dt float32) error {
+	if msg := C.launchTemperature(cc.ctx, cc.voxels, cc.neighbors, cc.temperatures, cc.diffusivity,
+		C.uint(cc.totalVoxels), C.float(dt), C.float(cc.radiogenicHeat), cudaBlockSize); msg != nil {
+		return fmt.Errorf("temperature kernel failed: %s", C.GoString(msg))
+	}
+	return nil
+}
+
+// runConvection writes the device voxels' new radial velocities to the
+// velocity buffer
+func (cc *CUDACompute) runConvection(dt float32) error {
+	if msg := C.launchConvection(cc.ctx, cc.voxels, cc.neighbors, cc.velocities, cc.viscosity, cc.lengths,
+		C.uint(cc.materialCount), C.uint(cc.totalVoxels), C.float(dt), cudaBlockSize); msg != nil {
+		return fmt.Errorf("convection kernel failed: %s", C.GoString(msg))
+	}
+	return nil
+}
+
+// storeField copies a result buffer into one field of the device voxels
+func (cc *CUDACompute) storeField(values unsafe.Pointer, field int) error {
+	if msg := C.launchStoreField(cc.ctx, cc.voxels, values, C.uint(field), C.uint(cc.totalVoxels), cudaBlockSize); msg != nil {
+		return fmt.Errorf("CUDA: %s", C.GoString(msg))
+	}
+	return nil
+}
+
+// StepTemperature diffuses heat through planet for dt years on the GPU and
+// writes the new temperatures back
+func (cc *CUDACompute) StepTemperature(planet *core.VoxelPlanet, dt float32) error {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if cc.ctx == nil {
+		return fmt.Errorf("CUDA compute released")
+	}
+
+	if err := cc.upload(planet); err != nil {
+		return err
+	}
+	cc.radiogenicHeat = float32(planet.RadiogenicHeat())
+	if err := cc.runTemperature(dt); err != nil {
+		return err
+	}
+	if err := cc.download(cc.temperatures); err != nil {
+		return err
+	}
+
+	idx := 0
+	for _, shell := range planet.Shells {
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				shell.Voxels[latIdx][lonIdx].Temperature = cc.results[idx]
+				idx++
+			}
+		}
+	}
+	return nil
+}
+
+// StepConvection sets the radial convection velocities of planet's voxels
+// for a step of dt years on the GPU
+func (cc *CUDACompute) StepConvection(planet *core.VoxelPlanet, dt float32) error {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if cc.ctx == nil {
+		return fmt.Errorf("CUDA compute released")
+	}
+
+	if err := cc.upload(planet); err != nil {
+		return err
+	}
+	if err := cc.runConvection(dt); err != nil {
+		return err
+	}
+	if err := cc.download(cc.velocities); err != nil {
+		return err
+	}
+
+	idx := 0
+	for _, shell := range planet.Shells {
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				shell.Voxels[latIdx][lonIdx].VelR = cc.results[idx]
+				idx++
+			}
+		}
+	}
+	return nil
+}
+
+// SetRadiogenicHeat sets the deep heating RunTemperatureKernel applies
+func (cc *CUDACompute) SetRadiogenicHeat(kelvinPerYear float32) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.radiogenicHeat = kelvinPerYear
+}
+
+// RunTemperatureKernel diffuses heat through the voxels on the device
+func (cc *CUDACompute) RunTemperatureKernel(dt float32) error {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if cc.ctx == nil {
+		return fmt.Errorf("CUDA compute released")
+	}
+	if err := cc.runTemperature(dt); err != nil {
+		return err
+	}
+	return cc.storeField(cc.temperatures, temperatureField)
+}
+
+// RunConvectionKernel updates the radial velocities of the voxels on the
+// device
+func (cc *CUDACompute) RunConvectionKernel(dt float32) error {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if cc.ctx == nil {
+		return fmt.Errorf("CUDA compute released")
+	}
+	if err := cc.runConvection(dt); err != nil {
+		return err
+	}
+	return cc.storeField(cc.velocities, velRField)
+}
+
+// RunAdvectionKernel is a no-op: advection runs on the CPU, as it does for
+// the CPU backend
+func (cc *CUDACompute) RunAdvectionKernel(dt float32) error {
+	return nil
+}
+
+// Cleanup frees the device buffers and unloads the kernels
+func (cc *CUDACompute) Cleanup() {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if cc.ctx == nil {
+		return
+	}
+
+	for _, buffer := range []*unsafe.Pointer{&cc.voxels, &cc.neighbors, &cc.temperatures, &cc.velocities,
+		&cc.diffusivity, &cc.viscosity, &cc.lengths} {
+		if *buffer != nil {
+			C.deviceFree(cc.ctx, *buffer)
+			*buffer = nil
+		}
+	}
+	C.releaseCUDAContext(cc.ctx)
+	cc.ctx = nil
+}
@@ -0,0 +1,21 @@
+//go:build !cuda
+// +build !cuda
+
+package cuda
+
+import (
+	"fmt"
+
+	"worldgenerator/core"
+	"worldgenerator/gpu"
+)
+
+// CUDACompute placeholder for builds without the cuda tag
+type CUDACompute struct {
+	gpu.CPUCompute
+}
+
+// NewCUDACompute always fails without the cuda build tag
+func NewCUDACompute(planet *core.VoxelPlanet) (*CUDACompute, error) {
+	return nil, fmt.Errorf("CUDA unavailable: built without CUDA support (rebuild with -tags cuda)")
+}
@@ -0,0 +1,131 @@
+// Package cuda runs the temperature and convection kernels on NVIDIA GPUs.
+// The backend needs the CUDA toolkit and is only built with -tags cuda;
+// other builds get a NewCUDACompute that always fails
+package cuda
+
+import (
+	"strconv"
+
+	"worldgenerator/gpu"
+)
+
+// cudaBlockSize is the threads per block every kernel launches with
+const cudaBlockSize = 64
+
+// Word offsets within a voxel of the fields the kernels write back, see
+// gpu.GPUVoxelFields
+const (
+	temperatureField = 2
+	velRField        = 6
+)
+
+// kernelPrelude declares the types and GLSL builtins the shared kernel
+// bodies use
+const kernelPrelude = `
+typedef unsigned int uint;
+typedef unsigned int uint32_t;
+typedef int int32_t;
+
+#define log(x) logf(x)
+#define exp(x) expf(x)
+#define abs(x) fabsf(x)
+#define max(a, b) fmaxf(a, b)
+inline float clamp(float x, float lo, float hi) { return fminf(fmaxf(x, lo), hi); }
+`
+
+// kernelSource is the CUDA port of the OpenGL temperature and convection
+// kernels, compiled at startup with NVRTC. Functions without an execution
+// space are device functions (--device-as-default-execution-space), so the
+// convection body is shared with GLSL and Metal unchanged. Both kernels
+// write their results to a separate buffer; storeVoxelField copies one
+// back into the voxels when the data stays on the device
+var kernelSource = kernelPrelude + gpu.ConvectionShaderDefines() + `
+struct Voxel {
+` + gpu.GPUVoxelStructFields("    ") + `};
+` + gpu.ConvectedVelRSource + `
+// Each voxel relaxes toward the mean of its non-air neighbors, as
+// gpu.DiffuseTemperatureFast does on the CPU
+extern "C" __global__ void updateTemperatureFast(
+    const Voxel* voxels,
+    const int* neighborIndices, // 6 per voxel: -r,+r,-lat,+lat,-lon,+lon
+    float* temperatures,
+    const float* materialDiffusivity,
+    uint voxelCount,
+    float dt,
+    float radiogenicHeat // K per year in the deep interior
+) {
+    uint voxelIndex = blockIdx.x * blockDim.x + threadIdx.x;
+    if (voxelIndex >= voxelCount) return;
+
+    Voxel voxel = voxels[voxelIndex];
+
+    // Skip air voxels
+    if (voxel.matType == 0u) {
+        temperatures[voxelIndex] = voxel.temperature;
+        return;
+    }
+    float thermalDiffusivity = materialDiffusivity[voxel.matType];
+
+    // Calculate average neighbor temperature
+    float avgTemp = voxel.temperature;
+    int neighborCount = 1;
+
+    for (uint i = 0u; i < 6u; i++) {
+        int neighborIdx = neighborIndices[voxelIndex * 6u + i];
+        if (neighborIdx >= 0 && uint(neighborIdx) < voxelCount) {
+            if (voxels[neighborIdx].matType != 0u) { // Not air
+                avgTemp += voxels[neighborIdx].temperature;
+                neighborCount++;
+            }
+        }
+    }
+
+    avgTemp /= float(neighborCount);
+
+    // Apply diffusion
+    float dTemp = thermalDiffusivity * (avgTemp - voxel.temperature) * dt / (1000.0f * 1000.0f);
+
+    // Add internal heating for deep voxels
+    if (voxel.temperature > 4000.0f) { // Deep mantle/core
+        dTemp += radiogenicHeat * dt;
+    }
+
+    temperatures[voxelIndex] = clamp(voxel.temperature + dTemp, 0.0f, 6000.0f);
+}
+
+// Radial velocity from buoyancy against each voxel's outer neighbor, the
+// same step as the CPU's UpdateConvection
+extern "C" __global__ void updateConvection(
+    const Voxel* voxels,
+    const int* neighborIndices,
+    float* velocities,
+    const float* materialViscosity,
+    const float* lengthScales,
+    uint materialCount,
+    uint voxelCount,
+    float dt
+) {
+    uint voxelIndex = blockIdx.x * blockDim.x + threadIdx.x;
+    if (voxelIndex >= voxelCount) return;
+
+    Voxel voxel = voxels[voxelIndex];
+    float velR = voxel.velR;
+
+    int outerIdx = neighborIndices[voxelIndex * 6u + NEIGHBOR_OUTER];
+    if (outerIdx >= 0 && uint(outerIdx) < voxelCount && voxel.matType < materialCount) {
+        Voxel outer = voxels[outerIdx];
+        velR = convectedVelR(voxel.matType, voxel.density, voxel.temperature, voxel.pressure, voxel.velR,
+            outer.matType, outer.density, outer.temperature,
+            materialViscosity[voxel.matType], lengthScales[voxelIndex], dt);
+    }
+
+    velocities[voxelIndex] = velR;
+}
+
+// Copies one float per voxel into the given word of every voxel
+extern "C" __global__ void storeVoxelField(float* voxelWords, const float* values, uint field, uint voxelCount) {
+    uint voxelIndex = blockIdx.x * blockDim.x + threadIdx.x;
+    if (voxelIndex >= voxelCount) return;
+    voxelWords[voxelIndex * ` + strconv.Itoa(gpu.GPUVoxelSize/4) + `u + field] = values[voxelIndex];
+}
+`
@@ -0,0 +1,85 @@
+// Package gltest opens OpenGL contexts for tests of the compute shaders.
+// Only tests import it, so the packages under test don't depend on GLFW
+package gltest
+
+import (
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/go-gl/gl/v4.3-core/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// GLFW windows must be created on the main thread
+func init() {
+	runtime.LockOSThread()
+}
+
+// OpenComputeContext makes a hidden OpenGL 4.3 context current, skipping the
+// test without a display. The returned function closes it
+func OpenComputeContext(t testing.TB) func() {
+	t.Helper()
+	if runtime.GOOS == "linux" && os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+		t.Skip("no display")
+	}
+	if err := glfw.Init(); err != nil {
+		t.Skipf("no GLFW: %v", err)
+	}
+	glfw.WindowHint(glfw.ContextVersionMajor, 4)
+	glfw.WindowHint(glfw.ContextVersionMinor, 3)
+	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+	glfw.WindowHint(glfw.Visible, glfw.False)
+	window, err := glfw.CreateWindow(1, 1, "test", nil, nil)
+	if err != nil {
+		glfw.Terminate()
+		t.Skipf("no OpenGL 4.3 context: %v", err)
+	}
+	window.MakeContextCurrent()
+	if err := gl.Init(); err != nil {
+		window.Destroy()
+		glfw.Terminate()
+		t.Skipf("no OpenGL: %v", err)
+	}
+	return func() {
+		window.Destroy()
+		glfw.Terminate()
+	}
+}
+
+// Context is a hidden window sharing objects with the context
+// OpenComputeContext made current, for kernels run off the test's thread
+type Context struct {
+	window *glfw.Window
+}
+
+// SharedContext opens a Context sharing with the current one. Call it after
+// OpenComputeContext; the kernel owning it destroys it
+func SharedContext(t testing.TB) *Context {
+	t.Helper()
+	glfw.WindowHint(glfw.Visible, glfw.False)
+	window, err := glfw.CreateWindow(1, 1, "compute", nil, glfw.GetCurrentContext())
+	if err != nil {
+		t.Fatalf("shared context: %v", err)
+	}
+	return &Context{window: window}
+}
+
+// MakeCurrent makes the context current on the calling OS thread and returns
+// a function that restores the context current before
+func (c *Context) MakeCurrent() func() {
+	previous := glfw.GetCurrentContext()
+	c.window.MakeContextCurrent()
+	return func() {
+		if previous != nil {
+			previous.MakeContextCurrent()
+		} else {
+			glfw.DetachCurrentContext()
+		}
+	}
+}
+
+// Destroy closes the hidden window
+func (c *Context) Destroy() {
+	c.window.Destroy()
+}
@@ -0,0 +1,161 @@
+package gpu
+
+import (
+	"math"
+
+	"worldgenerator/core"
+
+	"github.com/go-gl/gl/v4.3-core/gl"
+)
+
+// BoundaryParams set how dramatic plate boundaries are in plateBoundaryShader.
+// Stress on transform faults builds in the voxel's pressure, which the shader
+// uses as its stress accumulator, until the fault slips
+type BoundaryParams struct {
+	FrictionCoefficient float32 // Shear stress built per year per m/s of slip on transform faults, Pa
+	FrictionHeating     float32 // Warming of subducting crust by friction, K per year
+	StressRelease       float32 // Stress at which a fault slips in an earthquake, Pa
+	CollisionStress     float32 // Compression of colliding continental crust per step, as a fraction of its density
+	CollisionUplift     float32 // Rise of colliding continental crust
+	SubductionRate      float32 // Sinking of oceanic crust under a continent
+}
+
+// DefaultBoundaryParams are the strengths the boundary shader was tuned with
+func DefaultBoundaryParams() BoundaryParams {
+	return BoundaryParams{
+		FrictionCoefficient: 1e9,
+		FrictionHeating:     10,
+		StressRelease:       1e10,
+		CollisionStress:     1e-4,
+		CollisionUplift:     0.001,
+		SubductionRate:      0.01,
+	}
+}
+
+// setUniforms uploads the parameters to the boundary program, which must be
+// in use
+func (p BoundaryParams) setUniforms(program uint32) {
+	gl.Uniform1f(gl.GetUniformLocation(program, gl.Str("frictionCoefficient\x00")), p.FrictionCoefficient)
+	gl.Uniform1f(gl.GetUniformLocation(program, gl.Str("frictionHeating\x00")), p.FrictionHeating)
+	gl.Uniform1f(gl.GetUniformLocation(program, gl.Str("stressRelease\x00")), p.StressRelease)
+	gl.Uniform1f(gl.GetUniformLocation(program, gl.Str("collisionStress\x00")), p.CollisionStress)
+	gl.Uniform1f(gl.GetUniformLocation(program, gl.Str("collisionUplift\x00")), p.CollisionUplift)
+	gl.Uniform1f(gl.GetUniformLocation(program, gl.Str("subductionRate\x00")), p.SubductionRate)
+}
+
+// BoundaryType is how two plates move at a boundary, numbered as the
+// BOUNDARY_ constants in plateBoundaryShader
+type BoundaryType int
+
+const (
+	BoundaryNone BoundaryType = iota
+	BoundaryDivergent
+	BoundaryConvergent
+	BoundaryTransform
+)
+
+// PlateBoundaries is the CPU reference for plateBoundaryShader, returning the
+// voxels after dt years at plate boundaries. Each boundary voxel meets its
+// east, west, north and south neighbors in turn, and Interact applies every
+// one on another plate. Neighbors are read as the step starts, where the
+// shader races with a neighbor that is itself a boundary voxel
+func (p BoundaryParams) PlateBoundaries(voxels []GPUVoxelMaterial, shells []PlateShellGPU, lonCounts []int32, dt float32) []GPUVoxelMaterial {
+	out := make([]GPUVoxelMaterial, len(voxels))
+	copy(out, voxels)
+	for _, v := range plateVoxels(shells, lonCounts, len(shells)-1, len(shells)-1) {
+		voxel := &out[v.idx]
+		if voxel.IsBoundary == 0 {
+			continue
+		}
+		lonCount := int(lonCounts[int(shells[v.shell].LonCountOffset)+v.lat])
+		neighbors := [4]int{
+			plateVoxelIndex(shells, lonCounts, v.shell, v.lat, (v.lon+1)%lonCount),
+			plateVoxelIndex(shells, lonCounts, v.shell, v.lat, (v.lon-1+lonCount)%lonCount),
+			plateVoxelIndex(shells, lonCounts, v.shell, v.lat-1, v.lon),
+			plateVoxelIndex(shells, lonCounts, v.shell, v.lat+1, v.lon),
+		}
+		for i, n := range neighbors {
+			if n < 0 {
+				continue
+			}
+			neighbor := voxels[n]
+			if neighbor.PlateID == voxel.PlateID || neighbor.PlateID < 0 {
+				continue
+			}
+			normal := vec3{1, 0, 0}
+			if i >= 2 {
+				normal = vec3{0, 1, 0}
+			}
+			boundary := classifyBoundary(vec3{voxel.VelEast, voxel.VelNorth, voxel.VelR},
+				vec3{neighbor.VelEast, neighbor.VelNorth, neighbor.VelR}, normal)
+			p.Interact(voxel, neighbor, boundary, v.idx, dt)
+		}
+	}
+	return out
+}
+
+// classifyBoundary mirrors the shader's classifyBoundary: how a voxel moving
+// at vel1 and its neighbor across normal moving at vel2 meet
+func classifyBoundary(vel1, vel2, normal vec3) BoundaryType {
+	relVel := vel2.sub(vel1)
+	normalComponent := relVel.dot(normal)
+	tangentialComponent := relVel.sub(normal.scale(normalComponent)).length()
+
+	switch {
+	case normalComponent > 1e-6:
+		return BoundaryDivergent
+	case normalComponent < -1e-6 && tangentialComponent > -normalComponent*0.5:
+		return BoundaryTransform
+	case normalComponent < -1e-6:
+		return BoundaryConvergent
+	}
+	return BoundaryNone
+}
+
+// Interact is the CPU reference for one neighbor in plateBoundaryShader: the
+// processes a voxel undergoes over dt years against a neighbor on another
+// plate. idx is the voxel's buffer index, which seeds the chance of melting
+func (p BoundaryParams) Interact(voxel *GPUVoxelMaterial, neighbor GPUVoxelMaterial, boundary BoundaryType, idx int, dt float32) {
+	switch boundary {
+	case BoundaryDivergent:
+		// Seafloor spreading - create new basalt
+		if voxel.Type == uint32(core.MatBasalt) || voxel.Type == uint32(core.MatWater) {
+			voxel.Age = 0
+			voxel.Temperature = 1500
+			voxel.Type = uint32(core.MatBasalt)
+		}
+
+	case BoundaryConvergent:
+		oceanic := voxel.Type == uint32(core.MatBasalt)
+		neighborOceanic := neighbor.Type == uint32(core.MatBasalt)
+		if oceanic && !neighborOceanic {
+			voxel.VelR = -p.SubductionRate
+			voxel.Temperature += p.FrictionHeating * dt
+			if voxel.Temperature > 1200 && voxel.Pressure > 1e9 {
+				hash := math.Sin(float64(idx)*12.9898) * 43758.5453
+				if hash-math.Floor(hash) < 0.001*float64(dt) {
+					voxel.Type = uint32(core.MatMagma)
+				}
+			}
+		} else if !oceanic && !neighborOceanic {
+			voxel.VelR = p.CollisionUplift
+			voxel.Density *= 1 + p.CollisionStress
+		}
+
+	case BoundaryTransform:
+		dEast := voxel.VelEast - neighbor.VelEast
+		dNorth := voxel.VelNorth - neighbor.VelNorth
+		dR := voxel.VelR - neighbor.VelR
+		slip := float32(math.Sqrt(float64(dEast*dEast + dNorth*dNorth + dR*dR)))
+		voxel.Pressure += slip * p.FrictionCoefficient * dt
+		p.releaseStress(voxel)
+	}
+}
+
+// releaseStress slips a fault loaded past StressRelease, keeping a tenth of
+// its stress
+func (p BoundaryParams) releaseStress(voxel *GPUVoxelMaterial) {
+	if voxel.Pressure > p.StressRelease {
+		voxel.Pressure *= 0.1
+	}
+}
@@ -0,0 +1,178 @@
+package gpu
+
+import (
+	"math"
+	"testing"
+	"unsafe"
+
+	"worldgenerator/core"
+	"worldgenerator/gpu/gltest"
+	"worldgenerator/simulation"
+
+	"github.com/go-gl/gl/v4.3-core/gl"
+)
+
+// TestCollisionStressScales checks the compression a continental collision
+// imparts is proportional to CollisionStress
+func TestCollisionStressScales(t *testing.T) {
+	continent := GPUVoxelMaterial{Type: uint32(core.MatGranite), Density: 1, VelEast: 1e-9}
+	neighbor := GPUVoxelMaterial{Type: uint32(core.MatGranite), Density: 1, VelEast: -1e-9}
+
+	imparted := func(collisionStress float32) float32 {
+		params := DefaultBoundaryParams()
+		params.CollisionStress = collisionStress
+		voxel := continent
+		params.Interact(&voxel, neighbor, BoundaryConvergent, 0, 1000)
+		if voxel.Pressure != continent.Pressure {
+			t.Fatalf("collision loaded the margin from %g Pa to %g Pa", continent.Pressure, voxel.Pressure)
+		}
+		return voxel.Density - continent.Density
+	}
+
+	const base = 1e-2
+	if got := imparted(base); math.Abs(float64(got-base)) > 1e-3*base {
+		t.Fatalf("collision compressed the crust by %g, want %g", got, base)
+	}
+	for _, factor := range []float32{2, 5} {
+		got := imparted(base * factor)
+		if ratio := got / imparted(base); math.Abs(float64(ratio-factor)) > 1e-3 {
+			t.Errorf("%gx collision stress imparted %gx the compression", factor, ratio)
+		}
+	}
+}
+
+// TestStressRelease checks a transform fault loaded past StressRelease slips
+// and keeps a tenth of its stress, and one below it keeps loading
+func TestStressRelease(t *testing.T) {
+	params := DefaultBoundaryParams()
+	params.StressRelease = 1e8
+	neighbor := GPUVoxelMaterial{Type: uint32(core.MatBasalt), VelNorth: 1e-3} // 1e6 Pa a year
+
+	fault := GPUVoxelMaterial{Type: uint32(core.MatBasalt), Pressure: 0.9e8}
+	params.Interact(&fault, neighbor, BoundaryTransform, 0, 1)
+	if want := float32(0.91e8); math.Abs(float64(fault.Pressure-want)) > 100 {
+		t.Errorf("fault below release at %g Pa, want %g Pa", fault.Pressure, want)
+	}
+
+	params.Interact(&fault, neighbor, BoundaryTransform, 0, 100)
+	if want := float32(1.91e7); math.Abs(float64(fault.Pressure-want)) > 100 {
+		t.Errorf("fault loaded past release left at %g Pa, want a tenth of 1.91e8 Pa", fault.Pressure)
+	}
+}
+
+// boundaryPlates sets up every kind of boundary along the eastern edge of
+// plate 0 in twoPlates, against continental plate 1 at rest. Going down the
+// edge its voxels rift away, subduct, collide and slide past in turn
+func boundaryPlates(t *testing.T) ([]GPUVoxelMaterial, []PlateShellGPU, []int32) {
+	t.Helper()
+	voxels, shells, lonCounts, _ := twoPlates(t)
+	for i := range voxels {
+		voxel := &voxels[i]
+		if voxel.PlateID == 1 {
+			voxel.Type = uint32(core.MatGranite)
+		}
+		if voxel.IsBoundary == 0 {
+			continue
+		}
+		voxel.Pressure, voxel.VelR = 0, 0
+		switch i % 4 {
+		case 0:
+			voxel.Type = uint32(core.MatWater)
+			voxel.VelEast = -1e-3
+		case 1:
+			voxel.Type = uint32(core.MatBasalt)
+			voxel.VelEast = 1e-3
+		case 2:
+			voxel.Type = uint32(core.MatGranite)
+			voxel.VelEast = 1e-3
+		case 3:
+			voxel.Type = uint32(core.MatBasalt)
+			voxel.VelEast, voxel.VelNorth = 1e-4, 1e-3
+		}
+	}
+	return voxels, shells, lonCounts
+}
+
+// TestPlateBoundaries checks each boundary voxel of boundaryPlates goes
+// through the process its motion against plate 1 calls for
+func TestPlateBoundaries(t *testing.T) {
+	voxels, shells, lonCounts := boundaryPlates(t)
+	params := DefaultBoundaryParams()
+	const dt = 100
+
+	out := params.PlateBoundaries(voxels, shells, lonCounts, dt)
+
+	seen := map[int]int{}
+	for i, voxel := range out {
+		before := voxels[i]
+		if before.IsBoundary == 0 {
+			if voxel != before {
+				t.Fatalf("voxel %d off the boundary changed", i)
+			}
+			continue
+		}
+		seen[i%4]++
+		switch i % 4 {
+		case 0:
+			if voxel.Type != uint32(core.MatBasalt) || voxel.Age != 0 || voxel.Temperature != 1500 {
+				t.Fatalf("rifting voxel %d is material %d aged %g at %g K, want new basalt", i, voxel.Type, voxel.Age, voxel.Temperature)
+			}
+		case 1:
+			if voxel.VelR != -params.SubductionRate || voxel.Temperature <= before.Temperature {
+				t.Fatalf("subducting voxel %d sinks at %g and went from %g K to %g K", i, voxel.VelR, before.Temperature, voxel.Temperature)
+			}
+		case 2:
+			if voxel.VelR != params.CollisionUplift || voxel.Density <= before.Density {
+				t.Fatalf("colliding voxel %d rises at %g and went from %g to %g kg/m³", i, voxel.VelR, before.Density, voxel.Density)
+			}
+		case 3:
+			if voxel.Pressure <= 0 {
+				t.Fatalf("sliding voxel %d built no stress", i)
+			}
+		}
+	}
+	if len(seen) != 4 {
+		t.Fatalf("plate 0 has boundary voxels of %d kinds, want 4", len(seen))
+	}
+}
+
+// TestComputeBoundariesMatchCPU runs the boundary shader on boundaryPlates
+// and compares it against PlateBoundaries. It needs an OpenGL 4.3 context and
+// skips without a display
+func TestComputeBoundariesMatchCPU(t *testing.T) {
+	defer gltest.OpenComputeContext(t)()
+
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 4, 24)
+	voxels, shells, lonCounts := boundaryPlates(t)
+	const radius, dt = 6371000.0, 100
+
+	pt, err := NewComputePlateTectonics(planet, &simulation.PlateManager{})
+	if err != nil {
+		t.Fatalf("NewComputePlateTectonics: %v", err)
+	}
+	defer pt.Release()
+	pt.SetPlates(make([]PlateDataGPU, 2))
+
+	gl.GenBuffers(1, &pt.voxelSSBO)
+	defer gl.DeleteBuffers(1, &pt.voxelSSBO)
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, pt.voxelSSBO)
+	gl.BufferData(gl.SHADER_STORAGE_BUFFER, len(voxels)*GPUVoxelSize, gl.Ptr(voxels), gl.DYNAMIC_DRAW)
+
+	want := pt.Boundary.PlateBoundaries(voxels, shells, lonCounts, dt)
+	pt.RunBoundaryInteractions(dt, int32(len(shells)-2), radius)
+
+	got := make([]GPUVoxelMaterial, len(voxels))
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, pt.voxelSSBO)
+	gl.GetBufferSubData(gl.SHADER_STORAGE_BUFFER, 0, len(got)*GPUVoxelSize, unsafe.Pointer(&got[0]))
+
+	near := func(a, b float32) bool {
+		return math.Abs(float64(a-b)) <= 1e-5*math.Max(math.Abs(float64(b)), 1e-3)
+	}
+	for i := range got {
+		g, w := got[i], want[i]
+		if g.Type != w.Type || !near(g.Age, w.Age) || !near(g.Temperature, w.Temperature) ||
+			!near(g.Pressure, w.Pressure) || !near(g.Density, w.Density) || !near(g.VelR, w.VelR) {
+			t.Fatalf("voxel %d is %+v on the GPU, %+v on the CPU", i, g, w)
+		}
+	}
+}
@@ -14,7 +14,10 @@ import (
 /*
 #cgo LDFLAGS: -lGL
 
+#define GL_GLEXT_PROTOTYPES
 #include <GL/gl.h>
+#include <GL/glext.h>
+#include <stdlib.h>
 #include <string.h>
 
 // Since we're not using CUDA directly yet, we'll use OpenGL Persistent Mapped Buffers
@@ -79,6 +82,16 @@ void ensureGPUVisibility() {
 */
 import "C"
 
+// ShellMetadataGPU matches the std430 ShellData layout in the plate shaders
+type ShellMetadataGPU struct {
+	InnerRadius    float32
+	OuterRadius    float32
+	LatBands       int32
+	VoxelOffset    int32
+	LonCountOffset int32
+	_              [3]float32 // Padding
+}
+
 // PersistentBufferManager manages GPU buffers with CPU/GPU shared memory
 // This is a simpler approach that works on Windows/Linux without CUDA
 type PersistentBufferManager struct {
@@ -0,0 +1,141 @@
+package gpu
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"worldgenerator/core"
+)
+
+// Advection constants, shared with the OpenCL kernel through
+// AdvectionShaderDefines. They follow the Metal advectMaterial kernel
+const (
+	advectionSurfaceShell = 9     // Shells from here out drift east, deeper ones upwell
+	advectionCellYears    = 1e5   // Years to drift one cell at the equator
+	advectionDriftSpeed   = 0.1   // Eastward velocity of drifting rock at the equator, m/year
+	advectionUpwelling    = 1e-4  // Radial velocity above which a deep voxel mixes into the one above
+	advectionMixFactor    = 0.001 // Fraction of the temperature difference mixed per step
+	advectionLastFluid    = core.MatWater
+)
+
+// Slots of each voxel's entry in the advection cell buffer
+const (
+	advectionShell     = iota // Shell index
+	advectionBand             // Latitude band within the shell
+	advectionBands            // Latitude bands in the shell
+	advectionBandStart        // Flat index of the band's first voxel
+	advectionBandSize         // Voxels in the band
+	advectionCellInts
+)
+
+// AdvectionShaderDefines returns the material numbers, slots and constants
+// the advection kernel uses, as preprocessor defines
+func AdvectionShaderDefines() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "#define MAT_AIR %du\n", core.MatAir)
+	fmt.Fprintf(&b, "#define MAT_MAGMA %du\n", core.MatMagma)
+	fmt.Fprintf(&b, "#define ADVECTION_LAST_FLUID %du\n", advectionLastFluid)
+	fmt.Fprintf(&b, "#define NEIGHBOR_INNER %du\n", neighborInner)
+	for _, slot := range []struct {
+		name  string
+		value int
+	}{
+		{"ADVECTION_SURFACE_SHELL", advectionSurfaceShell},
+		{"CELL_SHELL", advectionShell},
+		{"CELL_BAND", advectionBand},
+		{"CELL_BANDS", advectionBands},
+		{"CELL_BAND_START", advectionBandStart},
+		{"CELL_BAND_SIZE", advectionBandSize},
+		{"CELL_INTS", advectionCellInts},
+	} {
+		fmt.Fprintf(&b, "#define %s %d\n", slot.name, slot.value)
+	}
+	for _, c := range []struct {
+		name  string
+		value float64
+	}{
+		{"ADVECTION_PI", math.Pi},
+		{"ADVECTION_CELL_YEARS", advectionCellYears},
+		{"ADVECTION_DRIFT_SPEED", advectionDriftSpeed},
+		{"ADVECTION_UPWELLING", advectionUpwelling},
+		{"ADVECTION_MIX_FACTOR", advectionMixFactor},
+	} {
+		fmt.Fprintf(&b, "#define %s %s\n", c.name, shaderFloat(c.value))
+	}
+	return b.String()
+}
+
+// AdvectionCells returns where every voxel sits in the planet, in the flat
+// order of NeighborIndices: advectionCellInts ints per voxel giving its
+// shell, latitude band, the shell's band count and the band's first voxel
+// and size. Bands differ in length, so the kernel can't derive these from
+// the flat index alone
+func AdvectionCells(planet *core.VoxelPlanet) []int32 {
+	var cells []int32
+	start := 0
+	for s, shell := range planet.Shells {
+		for lat, band := range shell.Voxels {
+			for range band {
+				cells = append(cells, int32(s), int32(lat), int32(len(shell.Voxels)), int32(start), int32(len(band)))
+			}
+			start += len(band)
+		}
+	}
+	return cells
+}
+
+// AdvectVoxels is the CPU reference for the advection kernels, returning the
+// voxels after one step of dt years. Rock in the surface shells drifts east
+// at up to one cell per advectionCellYears, fastest at the equator, and
+// deep voxels rising faster than advectionUpwelling mix their heat, and
+// magma its composition, into the voxel above. Each voxel gathers from its
+// western and inner neighbors, so no voxel depends on another's result
+func AdvectVoxels(voxels []GPUVoxelMaterial, neighbors []int32, cells []int32, dt float32) []GPUVoxelMaterial {
+	advected := make([]GPUVoxelMaterial, len(voxels))
+	copy(advected, voxels)
+	for i := range voxels {
+		voxel := &voxels[i]
+		if voxel.Type == uint32(core.MatAir) {
+			continue
+		}
+		cell := cells[i*advectionCellInts : (i+1)*advectionCellInts]
+		out := &advected[i]
+
+		// Plate drift, whole cells at a time
+		if cell[advectionShell] >= advectionSurfaceShell && voxel.Type > uint32(advectionLastFluid) {
+			latitude := (float64(cell[advectionBand])/float64(cell[advectionBands]) - 0.5) * 180.0
+			speedFactor := float32(math.Cos(latitude * math.Pi / 180.0))
+			shift := int32(dt / advectionCellYears * speedFactor)
+
+			out.Age += dt
+			if shift > 0 {
+				n := cell[advectionBandSize]
+				lon := int32(i) - cell[advectionBandStart]
+				source := &voxels[cell[advectionBandStart]+((lon-shift)%n+n)%n]
+				if source.Type > uint32(advectionLastFluid) {
+					out.Type = source.Type
+					out.Density = source.Density
+					out.Composition = source.Composition
+					out.Age = source.Age + dt
+				}
+			}
+			out.VelEast = advectionDriftSpeed * speedFactor
+		}
+
+		// Upwelling from the shell below
+		innerIdx := neighbors[i*neighborsPerVoxel+neighborInner]
+		if innerIdx < 0 || int(innerIdx) >= len(voxels) || cells[int(innerIdx)*advectionCellInts+advectionShell] >= advectionSurfaceShell {
+			continue
+		}
+		inner := &voxels[innerIdx]
+		if inner.VelR <= advectionUpwelling || inner.Type == uint32(core.MatAir) {
+			continue
+		}
+		out.Temperature += (inner.Temperature - out.Temperature) * advectionMixFactor
+		if inner.Type == uint32(core.MatMagma) {
+			out.Composition = (out.Composition + inner.Composition*advectionMixFactor) / (1.0 + advectionMixFactor)
+		}
+	}
+	return advected
+}
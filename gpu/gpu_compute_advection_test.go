@@ -0,0 +1,99 @@
+package gpu
+
+import (
+	"math"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// TestAdvectVoxelsDrift checks equatorial surface rock moves east by whole
+// cells and ages by the step, while air stays put
+func TestAdvectVoxelsDrift(t *testing.T) {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 12, 24)
+	shell := &planet.Shells[advectionSurfaceShell]
+	band := shell.Voxels[len(shell.Voxels)/2] // Latitude 0
+	for lon := range band {
+		band[lon].Type = core.MatBasalt
+		band[lon].Composition = float32(lon)
+		band[lon].Age = 10
+	}
+
+	voxels := flatVoxels(planet)
+	cells := AdvectionCells(planet)
+	if len(cells) != len(voxels)*advectionCellInts {
+		t.Fatalf("%d cell ints for %d voxels, want %d", len(cells), len(voxels), len(voxels)*advectionCellInts)
+	}
+
+	const dt = 2 * advectionCellYears
+	advected := AdvectVoxels(voxels, NeighborIndices(planet), cells, dt)
+
+	n := len(band)
+	for i := range voxels {
+		cell := cells[i*advectionCellInts : (i+1)*advectionCellInts]
+		if int(cell[advectionShell]) != advectionSurfaceShell || int(cell[advectionBand]) != len(shell.Voxels)/2 {
+			if voxels[i].Type == uint32(core.MatAir) && advected[i] != voxels[i] {
+				t.Fatalf("air voxel %d changed", i)
+			}
+			continue
+		}
+		lon := i - int(cell[advectionBandStart])
+		if want := float32((lon - 2 + n) % n); advected[i].Composition != want {
+			t.Errorf("lon %d has composition %g, want %g from two cells west", lon, advected[i].Composition, want)
+		}
+		if advected[i].Age != 10+dt {
+			t.Errorf("lon %d is %g years old, want %g", lon, advected[i].Age, 10+dt)
+		}
+		if advected[i].VelEast != advectionDriftSpeed {
+			t.Errorf("lon %d drifts at %g m/s, want %g", lon, advected[i].VelEast, advectionDriftSpeed)
+		}
+	}
+}
+
+// TestAdvectVoxelsUpwelling checks a rising magma voxel mixes its heat and
+// composition into the voxels above it and nowhere else
+func TestAdvectVoxelsUpwelling(t *testing.T) {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 12, 24)
+	rising := &planet.Shells[3].Voxels[6][0]
+	rising.Type = core.MatMagma
+	rising.VelR = 1
+	rising.Temperature = 5000
+	rising.Composition = 1
+
+	voxels := flatVoxels(planet)
+	neighbors := NeighborIndices(planet)
+	cells := AdvectionCells(planet)
+	advected := AdvectVoxels(voxels, neighbors, cells, 1)
+
+	risingIdx := int32(0)
+	for _, shell := range planet.Shells[:3] {
+		for _, band := range shell.Voxels {
+			risingIdx += int32(len(band))
+		}
+	}
+	for _, band := range planet.Shells[3].Voxels[:6] {
+		risingIdx += int32(len(band))
+	}
+
+	mixed := 0
+	for i := range voxels {
+		if neighbors[i*neighborsPerVoxel+neighborInner] != risingIdx || voxels[i].Type == uint32(core.MatAir) {
+			if advected[i].Temperature != voxels[i].Temperature {
+				t.Fatalf("voxel %d heated without upwelling below it", i)
+			}
+			continue
+		}
+		mixed++
+		want := voxels[i].Temperature + (5000-voxels[i].Temperature)*advectionMixFactor
+		if math.Abs(float64(advected[i].Temperature-want)) > 1e-3 {
+			t.Errorf("voxel %d at %g K, want %g K", i, advected[i].Temperature, want)
+		}
+		wantComposition := (voxels[i].Composition + advectionMixFactor) / (1 + advectionMixFactor)
+		if math.Abs(float64(advected[i].Composition-wantComposition)) > 1e-6 {
+			t.Errorf("voxel %d composition %g, want %g", i, advected[i].Composition, wantComposition)
+		}
+	}
+	if mixed == 0 {
+		t.Fatal("no voxel above the rising magma")
+	}
+}
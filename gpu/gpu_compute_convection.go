@@ -0,0 +1,360 @@
+package gpu
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"worldgenerator/core"
+
+	"github.com/go-gl/gl/v4.3-core/gl"
+)
+
+// Convection constants, shared by the GLSL and Metal kernels through
+// ConvectionShaderDefines. They follow UpdateConvection in the physics
+// package, which the kernels must match
+const (
+	convectionGravity            = 9.81     // m/s²
+	convectionThermalExpansion   = 3e-5     // Rock, per K
+	convectionThermalDiffusivity = 1e-6     // m²/s
+	convectionCriticalRayleigh   = 1000     // Onset of convection
+	convectionBaseViscosity      = 1e21     // Pa·s at reference conditions
+	convectionActivationEnergy   = 300000.0 // J/mol
+	convectionGasConstant        = 8.314    // J/(mol·K)
+	convectionMinViscosity       = 1e-5     // Pa·s
+	convectionMaxViscosity       = 1e25     // Pa·s
+	convectionOceanicDensity     = 2900.0   // Reference oceanic crust under a continent, kg/m³
+	convectionMantleDensity      = 3300.0   // Upper mantle eclogite slabs sink through, kg/m³
+	convectionVelocityDecay      = 0.95     // Radial velocity kept per step below critical
+)
+
+// Buffer bindings of the convection kernel. It runs on its own, so it reuses
+// the temperature kernel's binding points rather than going past the eight
+// OpenGL 4.3 guarantees
+const (
+	convectionVoxelBinding    = 4
+	convectionNeighborBinding = 5
+	convectionOutputBinding   = 6
+	convectionTableBinding    = 7
+)
+
+// convectionLocalSize must match local_size_x in convectionFastShader
+const convectionLocalSize = 64
+
+// shaderFloat formats a constant as a float literal both GLSL and Metal read
+// as single precision
+func shaderFloat(v float64) string {
+	return strconv.FormatFloat(v, 'e', -1, 32) + "f"
+}
+
+// ConvectionShaderDefines returns the material numbers, neighbor slot and
+// constants convectedVelR uses, as preprocessor defines
+func ConvectionShaderDefines() string {
+	var b strings.Builder
+	for _, m := range []struct {
+		name string
+		mat  core.MaterialType
+	}{
+		{"MAT_AIR", core.MatAir},
+		{"MAT_WATER", core.MatWater},
+		{"MAT_BASALT", core.MatBasalt},
+		{"MAT_GRANITE", core.MatGranite},
+		{"MAT_PERIDOTITE", core.MatPeridotite},
+		{"MAT_ECLOGITE", core.MatEclogite},
+	} {
+		fmt.Fprintf(&b, "#define %s %du\n", m.name, m.mat)
+	}
+	fmt.Fprintf(&b, "#define NEIGHBOR_OUTER %du\n", neighborOuter)
+	for _, c := range []struct {
+		name  string
+		value float64
+	}{
+		{"CONVECTION_PI", math.Pi},
+		{"CONVECTION_GRAVITY", convectionGravity},
+		{"THERMAL_EXPANSION", convectionThermalExpansion},
+		{"THERMAL_DIFFUSIVITY", convectionThermalDiffusivity},
+		{"CRITICAL_RAYLEIGH", convectionCriticalRayleigh},
+		{"BASE_VISCOSITY", convectionBaseViscosity},
+		{"ACTIVATION_ENERGY", convectionActivationEnergy},
+		{"GAS_CONSTANT", convectionGasConstant},
+		{"MIN_VISCOSITY", convectionMinViscosity},
+		{"MAX_VISCOSITY", convectionMaxViscosity},
+		{"OCEANIC_DENSITY", convectionOceanicDensity},
+		{"MANTLE_DENSITY", convectionMantleDensity},
+		{"VELOCITY_DECAY", convectionVelocityDecay},
+	} {
+		fmt.Fprintf(&b, "#define %s %s\n", c.name, shaderFloat(c.value))
+	}
+	return b.String()
+}
+
+// ConvectedVelRSource is the body of the convection kernels, valid as both
+// GLSL and Metal: the radial velocity a voxel is driven to over dt years by
+// buoyancy against the voxel above it. Keep in sync with ConvectVoxels
+const ConvectedVelRSource = `
+float convectedVelR(uint matType, float density, float temperature, float pressure, float velR,
+                    uint outerType, float outerDensity, float outerTemperature,
+                    float viscosityScale, float lengthScale, float dt) {
+    // Skip air and water
+    if (matType == MAT_AIR || matType == MAT_WATER) {
+        return velR;
+    }
+
+    // Density difference due to temperature, and the buoyancy it gives
+    float deltaDensity = density * THERMAL_EXPANSION * (temperature - outerTemperature);
+    float buoyancyForce = -deltaDensity * CONVECTION_GRAVITY;
+
+    // Buoyant continents and sinking eclogite slabs
+    if (matType == MAT_GRANITE) {
+        float avgDensity = outerDensity;
+        if (outerType == MAT_BASALT || outerType == MAT_PERIDOTITE) {
+            avgDensity = OCEANIC_DENSITY;
+        }
+        buoyancyForce += (avgDensity - density) * CONVECTION_GRAVITY / 100.0f;
+    } else if (matType == MAT_ECLOGITE) {
+        buoyancyForce += (MANTLE_DENSITY - density) * CONVECTION_GRAVITY / 100.0f;
+    }
+
+    // Arrhenius viscosity scaled by pressure and material, summed as
+    // logarithms so cold rock clamps instead of overflowing
+    float viscosity = MIN_VISCOSITY;
+    float pressureFactor = 1.0f + (pressure - 101325.0f) / 1e9f;
+    if (pressureFactor > 0.0f && viscosityScale > 0.0f) {
+        float logViscosity = log(BASE_VISCOSITY * viscosityScale) +
+            ACTIVATION_ENERGY / (GAS_CONSTANT * max(temperature, 1.0f)) + log(pressureFactor);
+        viscosity = exp(clamp(logViscosity, log(MIN_VISCOSITY), log(MAX_VISCOSITY)));
+    }
+
+    // Stokes velocity, once the Rayleigh number passes the onset of convection
+    float velocity = buoyancyForce * lengthScale * lengthScale / (6.0f * CONVECTION_PI * viscosity);
+    float rayleigh = abs(deltaDensity * CONVECTION_GRAVITY * lengthScale * lengthScale * lengthScale /
+        (THERMAL_DIFFUSIVITY * viscosity));
+    if (rayleigh > CRITICAL_RAYLEIGH) {
+        return velocity * dt;
+    }
+    return velR * VELOCITY_DECAY;
+}
+`
+
+// convectionFastShader runs convectedVelR for every voxel against its outer
+// neighbor from the precomputed index buffer, writing the new radial
+// velocities to their own buffer. The table buffer holds the material
+// viscosity scales followed by each voxel's length scale
+var convectionFastShader = `#version 430 core
+#define uint32_t uint
+#define int32_t int
+` + ConvectionShaderDefines() + `
+layout(local_size_x = 64) in;
+
+struct Voxel {
+` + GPUVoxelStructFields("    ") + `};
+
+layout(std430, binding = 4) readonly buffer Voxels {
+    Voxel voxels[];
+};
+
+layout(std430, binding = 5) readonly buffer Neighbors {
+    int neighborIndices[]; // 6 per voxel: -r,+r,-lat,+lat,-lon,+lon
+};
+
+layout(std430, binding = 6) writeonly buffer Velocities {
+    float velocities[];
+};
+
+layout(std430, binding = 7) readonly buffer Tables {
+    float convectionTables[]; // materialCount viscosity scales, then voxelCount length scales
+};
+
+uniform float dt;
+uniform uint voxelCount;
+uniform uint materialCount;
+` + ConvectedVelRSource + `
+void main() {
+    uint voxelIndex = gl_GlobalInvocationID.x;
+    if (voxelIndex >= voxelCount) return;
+
+    Voxel voxel = voxels[voxelIndex];
+    float velR = voxel.velR;
+
+    int outerIdx = neighborIndices[voxelIndex * 6u + NEIGHBOR_OUTER];
+    if (outerIdx >= 0 && uint(outerIdx) < voxelCount && voxel.matType < materialCount) {
+        Voxel outer = voxels[outerIdx];
+        velR = convectedVelR(voxel.matType, voxel.density, voxel.temperature, voxel.pressure, voxel.velR,
+            outer.matType, outer.density, outer.temperature,
+            convectionTables[voxel.matType], convectionTables[materialCount + voxelIndex], dt);
+    }
+
+    velocities[voxelIndex] = velR;
+}
+`
+
+// MaterialViscosityScales builds the table the convection kernels index by
+// material type: each material's viscosity relative to the mantle law.
+// Magma flows 1e15 times more easily, and registered materials scale by their
+// own reference viscosity
+func MaterialViscosityScales() []float32 {
+	table := make([]float32, core.MaterialCount())
+	for mat := range table {
+		switch {
+		case core.MaterialType(mat) == core.MatMagma:
+			table[mat] = 1e-15
+		case core.IsCustomMaterial(core.MaterialType(mat)):
+			table[mat] = core.MaterialProperties[core.MaterialType(mat)].Viscosity / convectionBaseViscosity
+		default:
+			table[mat] = 1
+		}
+	}
+	return table
+}
+
+// ConvectionLengthScales returns every voxel's convection length scale, a
+// tenth of its shell's thickness, in the flat order of NeighborIndices
+func ConvectionLengthScales(planet *core.VoxelPlanet) []float32 {
+	var scales []float32
+	for _, shell := range planet.Shells {
+		scale := float32((shell.OuterRadius - shell.InnerRadius) / 10.0)
+		for _, band := range shell.Voxels {
+			for range band {
+				scales = append(scales, scale)
+			}
+		}
+	}
+	return scales
+}
+
+// ConvectVoxels is the CPU reference for the convection kernels, returning
+// the radial velocity of every voxel after one step of dt years
+func ConvectVoxels(voxels []GPUVoxelMaterial, neighbors []int32, viscosityScales, lengthScales []float32, dt float32) []float32 {
+	velocities := make([]float32, len(voxels))
+	for i := range voxels {
+		voxel := &voxels[i]
+		velocities[i] = voxel.VelR
+
+		outerIdx := neighbors[i*neighborsPerVoxel+neighborOuter]
+		if outerIdx < 0 || int(outerIdx) >= len(voxels) || int(voxel.Type) >= len(viscosityScales) {
+			continue
+		}
+		if voxel.Type == uint32(core.MatAir) || voxel.Type == uint32(core.MatWater) {
+			continue
+		}
+		outer := &voxels[outerIdx]
+
+		deltaDensity := voxel.Density * convectionThermalExpansion * (voxel.Temperature - outer.Temperature)
+		buoyancyForce := -deltaDensity * convectionGravity
+
+		switch voxel.Type {
+		case uint32(core.MatGranite):
+			avgDensity := outer.Density
+			if outer.Type == uint32(core.MatBasalt) || outer.Type == uint32(core.MatPeridotite) {
+				avgDensity = convectionOceanicDensity
+			}
+			buoyancyForce += (avgDensity - voxel.Density) * convectionGravity / 100.0
+		case uint32(core.MatEclogite):
+			buoyancyForce += (convectionMantleDensity - voxel.Density) * convectionGravity / 100.0
+		}
+
+		viscosity := float32(convectionMinViscosity)
+		pressureFactor := 1.0 + (voxel.Pressure-101325.0)/1e9
+		if scale := viscosityScales[voxel.Type]; pressureFactor > 0 && scale > 0 {
+			logViscosity := math.Log(convectionBaseViscosity*float64(scale)) +
+				convectionActivationEnergy/(convectionGasConstant*math.Max(float64(voxel.Temperature), 1)) +
+				math.Log(float64(pressureFactor))
+			logViscosity = math.Max(math.Log(convectionMinViscosity), math.Min(logViscosity, math.Log(convectionMaxViscosity)))
+			viscosity = float32(math.Exp(logViscosity))
+		}
+
+		length := lengthScales[i]
+		velocity := buoyancyForce * length * length / (6.0 * math.Pi * viscosity)
+		rayleigh := float32(math.Abs(float64(deltaDensity * convectionGravity * length * length * length /
+			(convectionThermalDiffusivity * viscosity))))
+		if rayleigh > convectionCriticalRayleigh {
+			velocities[i] = velocity * dt
+		} else {
+			velocities[i] = voxel.VelR * convectionVelocityDecay
+		}
+	}
+	return velocities
+}
+
+// createConvectionBuffers uploads the viscosity and length scale tables and
+// allocates the velocity buffer. The voxel and neighbor buffers are the
+// temperature kernel's
+func (cp *ComputePhysics) createConvectionBuffers(planet *core.VoxelPlanet) {
+	viscosity := MaterialViscosityScales()
+	tables := append(viscosity, ConvectionLengthScales(planet)...)
+	cp.convectionMaterials = len(viscosity)
+	cp.velocities = make([]float32, cp.totalVoxels)
+
+	gl.GenBuffers(1, &cp.velocitySSBO)
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, cp.velocitySSBO)
+	gl.BufferData(gl.SHADER_STORAGE_BUFFER, max(cp.totalVoxels, 1)*4, nil, gl.DYNAMIC_READ)
+
+	gl.GenBuffers(1, &cp.convectionTableSSBO)
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, cp.convectionTableSSBO)
+	gl.BufferData(gl.SHADER_STORAGE_BUFFER, len(tables)*4, gl.Ptr(tables), gl.STATIC_DRAW)
+
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, 0)
+}
+
+// RunConvection runs one convection step of deltaTime years on the voxels
+// already in the compute buffers
+func (cp *ComputePhysics) RunConvection(deltaTime float32) {
+	gl.UseProgram(cp.convectionProgram)
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, convectionVoxelBinding, cp.voxelSSBO)
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, convectionNeighborBinding, cp.neighborSSBO)
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, convectionOutputBinding, cp.velocitySSBO)
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, convectionTableBinding, cp.convectionTableSSBO)
+
+	// Set uniforms
+	gl.Uniform1f(gl.GetUniformLocation(cp.convectionProgram, gl.Str("dt\x00")), deltaTime)
+	gl.Uniform1ui(gl.GetUniformLocation(cp.convectionProgram, gl.Str("voxelCount\x00")), uint32(cp.totalVoxels))
+	gl.Uniform1ui(gl.GetUniformLocation(cp.convectionProgram, gl.Str("materialCount\x00")), uint32(cp.convectionMaterials))
+
+	groups := uint32((cp.totalVoxels + convectionLocalSize - 1) / convectionLocalSize)
+	gl.DispatchCompute(groups, 1, 1)
+	gl.MemoryBarrier(gl.SHADER_STORAGE_BARRIER_BIT | gl.BUFFER_UPDATE_BARRIER_BIT)
+}
+
+// StepConvection sets the radial convection velocities of planet's voxels
+// for a step of dt years on the GPU. Any goroutine may call it
+func (cp *ComputePhysics) StepConvection(planet *core.VoxelPlanet, dt float32) error {
+	count, err := cp.runOnContext(planet, func(count int) error {
+		cp.RunConvection(dt)
+
+		gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, cp.velocitySSBO)
+		gl.GetBufferSubData(gl.SHADER_STORAGE_BUFFER, 0, count*4, gl.Ptr(cp.velocities))
+		gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, 0)
+		if code := gl.GetError(); code != gl.NO_ERROR {
+			return fmt.Errorf("convection kernel failed: GL error 0x%x", code)
+		}
+		return nil
+	})
+	if err != nil || count == 0 {
+		return err
+	}
+
+	idx := 0
+	for _, shell := range planet.Shells {
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				shell.Voxels[latIdx][lonIdx].VelR = cp.velocities[idx]
+				idx++
+			}
+		}
+	}
+	return nil
+}
+
+// releaseConvectionBuffers frees the convection kernel's own buffers
+func (cp *ComputePhysics) releaseConvectionBuffers() {
+	cp.contextMu.Lock()
+	defer cp.contextMu.Unlock()
+
+	for _, buffer := range []uint32{cp.velocitySSBO, cp.convectionTableSSBO} {
+		if buffer != 0 {
+			gl.DeleteBuffers(1, &buffer)
+		}
+	}
+	cp.velocitySSBO, cp.convectionTableSSBO = 0, 0
+}
@@ -0,0 +1,48 @@
+package gpu
+
+import (
+	"math"
+	"testing"
+
+	"worldgenerator/core"
+	"worldgenerator/gpu/gltest"
+)
+
+// TestComputeConvectionMatchesCPU runs the convection compute shader on a
+// small planet with magma plumes and compares it against the CPU reference.
+// It needs an OpenGL 4.3 context and skips without a display
+func TestComputeConvectionMatchesCPU(t *testing.T) {
+	defer gltest.OpenComputeContext(t)()
+
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 4, 24)
+	for shellIdx := range planet.Shells[:len(planet.Shells)-1] {
+		shell := &planet.Shells[shellIdx]
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				if (latIdx+lonIdx)%3 == 0 {
+					voxel := &shell.Voxels[latIdx][lonIdx]
+					voxel.Type = core.MatMagma
+					voxel.Temperature = 2000
+				}
+			}
+		}
+	}
+
+	cp, err := NewComputePhysics(planet, gltest.SharedContext(t))
+	if err != nil {
+		t.Fatalf("NewComputePhysics: %v", err)
+	}
+	defer cp.Release()
+
+	const dt = 1e3
+	want := ConvectVoxels(flatVoxels(planet), NeighborIndices(planet), MaterialViscosityScales(), ConvectionLengthScales(planet), dt)
+	if err := cp.StepConvection(planet, dt); err != nil {
+		t.Fatalf("StepConvection: %v", err)
+	}
+
+	for i, voxel := range flatVoxels(planet) {
+		if math.Abs(float64(voxel.VelR-want[i])) > 1e-4*math.Abs(float64(want[i]))+1e-30 {
+			t.Fatalf("voxel %d velR %g on the GPU, %g on the CPU", i, voxel.VelR, want[i])
+		}
+	}
+}
@@ -2,78 +2,69 @@ package gpu
 
 import (
 	"fmt"
+	"strings"
+	"unsafe"
 	"worldgenerator/core"
 	"worldgenerator/simulation"
 
 	"github.com/go-gl/gl/v4.3-core/gl"
 )
 
-// Plate motion compute shader - calculates plate velocities from mantle flow
-const plateDynamicsShader = `
-#version 430 core
-
+// plateShaderCommon opens every plate shader: the buffers they share and the
+// helpers that place voxels on the sphere. Positions are in the frame of
+// simulation's Euler poles, Z toward the north pole and X toward 0° longitude
+var plateShaderCommon = `#version 430 core
+#define uint32_t uint
+#define int32_t int
+` + PlateShaderDefines() + `
 layout(local_size_x = 32, local_size_y = 1, local_size_z = 1) in;
 
-// Voxel data SSBO
-layout(std430, binding = 0) buffer VoxelData {
-    int type;
-    float density;
-    float temperature;
-    float pressure;
-    float VelNorth;
-    float VelEast;
-    float velR;
-    float age;
-    float viscosity;
-    float heatCapacity;
-    float thermalConductivity;
-    float radioactiveHeat;
-    int plateID;
-    int isBoundary;
-    float padding[2];
-} voxels[];
-
-// Shell metadata SSBO
-layout(std430, binding = 1) readonly buffer ShellData {
+struct Voxel {
+` + GPUVoxelStructFields("    ") + `};
+
+// Voxels, flattened in shell, band, longitude order
+layout(std430, binding = 0) buffer Voxels {
+    Voxel voxels[];
+};
+
+// Shell metadata, see PlateShellGPU
+struct Shell {
     float innerRadius;
     float outerRadius;
     int latBands;
-    int voxelOffset;
-    int lonCountOffset;
+    int voxelOffset;    // Flat index of the shell's first voxel
+    int lonCountOffset; // Index of the shell's first band in lonCounts
     float padding[3];
-} shells[];
+};
+
+layout(std430, binding = 1) readonly buffer Shells {
+    Shell shells[];
+};
 
-// Longitude counts SSBO
+// Longitude count of every band of every shell
 layout(std430, binding = 2) readonly buffer LonCounts {
-    int counts[];
-} lonCounts;
+    int lonCounts[];
+};
 
-// Plate data SSBO
-layout(std430, binding = 3) buffer PlateData {
-    vec4 eulerPole;      // xyz = pole position, w = angular velocity
+// Plate data, see PlateDataGPU
+struct Plate {
+    vec4 eulerPole;      // xyz = unit rotation axis, w = angular velocity
     vec4 ridgePush;      // xyz = force vector, w = magnitude
     vec4 slabPull;       // xyz = force vector, w = magnitude
     vec4 basalDrag;      // xyz = force vector, w = magnitude
     vec4 properties;     // x = total mass, y = area, z = avg thickness, w = type (0=oceanic, 1=continental, 2=mixed)
-    vec4 motion;         // xyz = linear velocity at centroid, w = strain rate
+    vec4 motion;         // xyz = mean velocity, w = strain rate
     int memberCount;
     int boundaryCount;
     float avgAge;
     float convergence;
-} plates[];
+};
 
-// Uniforms
-uniform float deltaTime;
-uniform int shellCount;
-uniform int plateCount;
-uniform float planetRadius;
-uniform int surfaceShell;    // Which shell index is the surface
-uniform int lithosphereDepth; // How many shells down to include in plate
+layout(std430, binding = 3) buffer Plates {
+    Plate plates[];
+};
 
-// Constants
-const float EARTH_RADIUS = 6371000.0;
-const float MAX_PLATE_VELOCITY = 0.2; // m/year = 20 cm/year
-const float MANTLE_VISCOSITY = 1e21; // Pa·s
+uniform int shellCount;
 
 // Find voxel index from shell/lat/lon
 int getVoxelIndex(int shell, int lat, int lon) {
@@ -85,113 +76,111 @@ int getVoxelIndex(int shell, int lat, int lon) {
     if (lat < 0 || lat >= latBands) return -1;
     
     int lonCountOffset = shells[shell].lonCountOffset;
-    int lonCount = lonCounts.counts[lonCountOffset + lat];
+    int lonCount = lonCounts[lonCountOffset + lat];
     
     if (lon < 0 || lon >= lonCount) return -1;
     
     for (int i = 0; i < lat; i++) {
-        offset += lonCounts.counts[lonCountOffset + i];
+        offset += lonCounts[lonCountOffset + i];
     }
     
     return offset + lon;
 }
 
-// Convert lat/lon indices to spherical coordinates
-vec3 getSphericalPosition(int shell, int lat, int lon) {
-    float radius = (shells[shell].innerRadius + shells[shell].outerRadius) * 0.5;
-    int latBands = shells[shell].latBands;
-    int lonCountOffset = shells[shell].lonCountOffset;
-    int lonCount = lonCounts.counts[lonCountOffset + lat];
-    
-    // Latitude from -90 to +90
-    float latitude = -90.0 + (180.0 * float(lat) / float(latBands - 1));
-    float latRad = radians(latitude);
-    
-    // Longitude from 0 to 360
-    float longitude = 360.0 * float(lon) / float(lonCount);
-    float lonRad = radians(longitude);
-    
-    // Convert to Cartesian
-    vec3 pos;
-    pos.x = radius * cos(latRad) * cos(lonRad);
-    pos.y = radius * cos(latRad) * sin(lonRad);
-    pos.z = radius * sin(latRad);
-    
-    return pos;
-}
-
-// Calculate coupling between mantle flow and plate motion
-vec3 calculateMantleCoupling(int voxelIdx, int plateID) {
-    vec3 coupling = vec3(0.0);
-    
-    // Get voxel beneath this lithosphere point
-    // Find shell/lat/lon from index
-    int shell = -1;
-    int lat = -1; 
-    int lon = -1;
+// Find shell/lat/lon from a voxel index, false outside the grid
+bool locateVoxel(int idx, out int shell, out int lat, out int lon) {
+    shell = -1;
+    lat = -1;
+    lon = -1;
     
-    // Reverse lookup (inefficient but necessary)
-    int accumIdx = 0;
     for (int s = 0; s < shellCount; s++) {
         int shellStart = shells[s].voxelOffset;
         int shellEnd = (s < shellCount - 1) ? shells[s + 1].voxelOffset : int(voxels.length());
         
-        if (voxelIdx >= shellStart && voxelIdx < shellEnd) {
+        if (idx >= shellStart && idx < shellEnd) {
             shell = s;
-            int offsetInShell = voxelIdx - shellStart;
+            int offsetInShell = idx - shellStart;
             int lonCountOffset = shells[s].lonCountOffset;
             int latBands = shells[s].latBands;
             
-            accumIdx = 0;
+            int accumOffset = 0;
             for (int l = 0; l < latBands; l++) {
-                int lonCount = lonCounts.counts[lonCountOffset + l];
-                if (offsetInShell < accumIdx + lonCount) {
+                int lonCount = lonCounts[lonCountOffset + l];
+                if (offsetInShell < accumOffset + lonCount) {
                     lat = l;
-                    lon = offsetInShell - accumIdx;
-                    break;
+                    lon = offsetInShell - accumOffset;
+                    return true;
                 }
-                accumIdx += lonCount;
+                accumOffset += lonCount;
             }
-            break;
+            return false;
         }
     }
+    return false;
+}
+
+// Cartesian position of a voxel's center at the middle of its shell, where
+// core.VoxelLatLon places it
+vec3 getSphericalPosition(int shell, int lat, int lon) {
+    float radius = (shells[shell].innerRadius + shells[shell].outerRadius) * 0.5;
+    int latBands = shells[shell].latBands;
+    int lonCount = lonCounts[shells[shell].lonCountOffset + lat];
     
-    if (shell < 0 || lat < 0 || lon < 0) return coupling;
+    // Latitude from -90 to +90, longitude from -180 to +180
+    float latRad = radians((float(lat) + 0.5) / float(latBands) * 180.0 - 90.0);
+    float lonRad = radians((float(lon) + 0.5) / float(lonCount) * 360.0 - 180.0);
+    
+    // Convert to Cartesian
+    return radius * vec3(cos(latRad) * cos(lonRad), cos(latRad) * sin(lonRad), sin(latRad));
+}
+
+// Unit up, east and north vectors at a position
+void localBasis(vec3 pos, out vec3 up, out vec3 east, out vec3 north) {
+    up = normalize(pos);
+    east = normalize(vec3(-pos.y, pos.x, 0.0));
+    north = cross(up, east);
+}
+`
+
+// Plate motion compute shader - calculates plate forces and Euler poles from
+// the lithosphere voxels at their true positions. One invocation per plate;
+// PlateDynamics is its CPU reference
+var plateDynamicsShader = plateShaderCommon + `
+// Uniforms
+uniform float deltaTime;
+uniform int plateCount;
+uniform float planetRadius;
+uniform float maxPlateVelocity; // m/year at the surface
+uniform int surfaceShell;    // Which shell index is the surface
+uniform int lithosphereDepth; // How many shells down to include in plate
+
+// Calculate coupling between mantle flow and plate motion, as east, north
+// and radial velocity
+vec3 calculateMantleCoupling(int voxelIdx, int shell, int lat, int lon) {
+    vec3 coupling = vec3(0.0);
     
     // Sample mantle velocity from deeper shells
     float totalWeight = 0.0;
     vec3 mantleVel = vec3(0.0);
     
     for (int depthOffset = 1; depthOffset <= 3; depthOffset++) {
-        int mantleShell = shell - depthOffset;
-        if (mantleShell < 0) continue;
-        
-        int mantleIdx = getVoxelIndex(mantleShell, lat, lon);
+        int mantleIdx = getVoxelIndex(shell - depthOffset, lat, lon);
         if (mantleIdx < 0) continue;
         
         // Weight by proximity
         float weight = 1.0 / float(depthOffset);
         
-        mantleVel += vec3(voxels[mantleIdx].VelEast, voxels[mantleIdx].VelNorth, voxels[mantleIdx].velR) * weight;
+        mantleVel += vec3(voxels[mantleIdx].velEast, voxels[mantleIdx].velNorth, voxels[mantleIdx].velR) * weight;
         totalWeight += weight;
     }
     
     if (totalWeight > 0.0) {
         mantleVel /= totalWeight;
         
-        // Coupling strength depends on temperature and viscosity
-        float temp = voxels[voxelIdx].temperature;
-        float viscosity = voxels[voxelIdx].viscosity;
-        
         // Higher temperature = stronger coupling
-        float tempFactor = clamp((temp - 1000.0) / 2000.0, 0.0, 1.0);
-        
-        // Lower viscosity = stronger coupling
-        float viscFactor = clamp(1.0 - log(viscosity / 1e19) / 10.0, 0.0, 1.0);
-        
-        float couplingStrength = tempFactor * viscFactor * 0.5;
+        float tempFactor = clamp((voxels[voxelIdx].temperature - 1000.0) / 2000.0, 0.0, 1.0);
         
-        coupling = mantleVel * couplingStrength;
+        coupling = mantleVel * (tempFactor * 0.5);
     }
     
     return coupling;
@@ -200,197 +189,148 @@ vec3 calculateMantleCoupling(int voxelIdx, int plateID) {
 void main() {
     uint plateID = gl_GlobalInvocationID.x;
     
-    if (plateID >= plateCount) return;
+    if (plateID >= uint(plateCount)) return;
     
     // Reset forces
     plates[plateID].ridgePush = vec4(0.0);
     plates[plateID].slabPull = vec4(0.0);
     plates[plateID].basalDrag = vec4(0.0);
     
-    // Accumulate forces from all voxels in this plate
-    vec3 totalRidgePush = vec3(0.0);
-    vec3 totalSlabPull = vec3(0.0);
-    vec3 totalBasalDrag = vec3(0.0);
+    int lowestShell = max(surfaceShell - lithosphereDepth, 0);
+    
+    // Mass, centroid and mean velocity of the plate's lithosphere
     vec3 plateVelocity = vec3(0.0);
     vec3 plateCentroid = vec3(0.0);
     float totalMass = 0.0;
     int memberCount = 0;
+    
+    for (int s = surfaceShell; s >= lowestShell; s--) {
+        int idx = shells[s].voxelOffset;
+        for (int lat = 0; lat < shells[s].latBands; lat++) {
+            int lonCount = lonCounts[shells[s].lonCountOffset + lat];
+            for (int lon = 0; lon < lonCount; lon++, idx++) {
+                if (voxels[idx].plateID != int(plateID)) continue;
+                
+                memberCount++;
+                
+                vec3 pos = getSphericalPosition(s, lat, lon);
+                vec3 up, east, north;
+                localBasis(pos, up, east, north);
+                
+                float voxelMass = voxels[idx].density * planetRadius * planetRadius * 0.001; // Simplified
+                totalMass += voxelMass;
+                plateCentroid += pos * voxelMass;
+                plateVelocity += (east * voxels[idx].velEast + north * voxels[idx].velNorth + up * voxels[idx].velR) * voxelMass;
+            }
+        }
+    }
+    
+    if (totalMass <= 0.0) return;
+    plateCentroid /= totalMass;
+    
+    // Forces, and their torque about the planet's center
+    vec3 totalRidgePush = vec3(0.0);
+    vec3 totalSlabPull = vec3(0.0);
+    vec3 totalBasalDrag = vec3(0.0);
+    vec3 torque = vec3(0.0);
     int boundaryCount = 0;
     
-    // Scan all surface voxels
-    for (int s = surfaceShell; s >= max(surfaceShell - lithosphereDepth, 0); s--) {
-        int shellStart = shells[s].voxelOffset;
-        int shellEnd = (s < shellCount - 1) ? shells[s + 1].voxelOffset : int(voxels.length());
-        
-        for (int idx = shellStart; idx < shellEnd; idx++) {
-            if (voxels[idx].plateID != int(plateID)) continue;
-            
-            memberCount++;
-            
-            // Get position for force calculations
-            vec3 pos = normalize(vec3(idx * 0.1, idx * 0.2, idx * 0.3)); // Placeholder - need proper position
-            
-            // Mass contribution
-            float voxelMass = voxels[idx].density * planetRadius * planetRadius * 0.001; // Simplified
-            totalMass += voxelMass;
-            
-            // Velocity contribution
-            plateVelocity += vec3(voxels[idx].VelEast, voxels[idx].VelNorth, voxels[idx].velR) * voxelMass;
-            
-            // Boundary forces
-            if (voxels[idx].isBoundary > 0) {
-                boundaryCount++;
+    for (int s = surfaceShell; s >= lowestShell; s--) {
+        int idx = shells[s].voxelOffset;
+        for (int lat = 0; lat < shells[s].latBands; lat++) {
+            int lonCount = lonCounts[shells[s].lonCountOffset + lat];
+            for (int lon = 0; lon < lonCount; lon++, idx++) {
+                if (voxels[idx].plateID != int(plateID)) continue;
                 
-                // Ridge push at divergent boundaries
-                if (voxels[idx].temperature > 1500.0 && voxels[idx].velR > 0.0) {
-                    // Elevated temperature and upwelling = ridge
-                    float ridgeForce = voxels[idx].velR * 1e12;
-                    totalRidgePush += pos * ridgeForce;
-                }
+                vec3 pos = getSphericalPosition(s, lat, lon);
+                vec3 up, east, north;
+                localBasis(pos, up, east, north);
+                
+                // Along the surface toward the plate's interior
+                vec3 toCentroid = plateCentroid - pos;
+                vec3 inward = toCentroid - up * dot(toCentroid, up);
+                inward = length(inward) > 0.0 ? normalize(inward) : vec3(0.0);
                 
-                // Slab pull at subduction zones
-                if (voxels[idx].type == 2 && voxels[idx].velR < 0.0) { // Basalt going down
-                    float slabForce = -voxels[idx].velR * voxels[idx].density * 1e13;
-                    totalSlabPull += vec3(0.0, 0.0, -slabForce);
+                vec3 force = vec3(0.0);
+                if (voxels[idx].isBoundary > 0) {
+                    boundaryCount++;
+                    
+                    // Ridge push at divergent boundaries drives the plate away
+                    if (voxels[idx].temperature > 1500.0 && voxels[idx].velR > 0.0) {
+                        // Elevated temperature and upwelling = ridge
+                        vec3 push = inward * (voxels[idx].velR * 1e12);
+                        totalRidgePush += push;
+                        force += push;
+                    }
+                    
+                    // Slab pull at subduction zones drags it toward the trench, dense eclogite slabs hardest
+                    if ((voxels[idx].matType == MAT_BASALT || voxels[idx].matType == MAT_ECLOGITE) && voxels[idx].velR < 0.0) {
+                        vec3 pull = inward * (voxels[idx].velR * voxels[idx].density * 1e13);
+                        totalSlabPull += pull;
+                        force += pull;
+                    }
                 }
+                
+                // Basal drag from mantle coupling resists motion over the mantle
+                vec3 mantleVel = calculateMantleCoupling(idx, s, lat, lon);
+                vec3 relativeVel = east * (voxels[idx].velEast - mantleVel.x) + north * (voxels[idx].velNorth - mantleVel.y);
+                vec3 drag = relativeVel * (-PLATE_MANTLE_VISCOSITY * 0.001); // Simplified drag
+                totalBasalDrag += drag;
+                force += drag;
+                
+                torque += cross(pos, force);
             }
-            
-            // Basal drag from mantle coupling
-            vec3 mantleVel = calculateMantleCoupling(idx, int(plateID));
-            vec3 relativeVel = vec3(voxels[idx].VelEast, voxels[idx].VelNorth, 0.0) - mantleVel;
-            totalBasalDrag -= relativeVel * MANTLE_VISCOSITY * 0.001; // Simplified drag
         }
     }
     
-    // Normalize by total mass
-    if (totalMass > 0.0) {
-        plateVelocity /= totalMass;
-        
-        // Update plate forces
-        plates[plateID].ridgePush = vec4(totalRidgePush / totalMass, length(totalRidgePush));
-        plates[plateID].slabPull = vec4(totalSlabPull / totalMass, length(totalSlabPull));
-        plates[plateID].basalDrag = vec4(totalBasalDrag / totalMass, length(totalBasalDrag));
-        
-        // Calculate net force
-        vec3 netForce = totalRidgePush + totalSlabPull + totalBasalDrag;
-        
-        // Update angular velocity based on torque
-        // Simplified - assumes force acts at edge of plate
-        float torque = length(cross(plateCentroid, netForce));
-        float momentOfInertia = totalMass * planetRadius * planetRadius * 0.4; // Sphere approximation
-        
-        float angularAccel = torque / momentOfInertia;
-        plates[plateID].eulerPole.w += angularAccel * deltaTime;
-        
-        // Limit angular velocity
-        plates[plateID].eulerPole.w = clamp(plates[plateID].eulerPole.w, 
-                                           -MAX_PLATE_VELOCITY / planetRadius,
-                                            MAX_PLATE_VELOCITY / planetRadius);
-        
-        // Update motion
-        plates[plateID].motion = vec4(plateVelocity, 0.0);
-        plates[plateID].memberCount = memberCount;
-        plates[plateID].boundaryCount = boundaryCount;
-    }
+    // Update plate forces
+    plateVelocity /= totalMass;
+    plates[plateID].ridgePush = vec4(totalRidgePush / totalMass, length(totalRidgePush));
+    plates[plateID].slabPull = vec4(totalSlabPull / totalMass, length(totalSlabPull));
+    plates[plateID].basalDrag = vec4(totalBasalDrag / totalMass, length(totalBasalDrag));
+    
+    // Spin up the Euler vector by the torque
+    float momentOfInertia = totalMass * planetRadius * planetRadius * 0.4; // Sphere approximation
+    vec3 euler = plates[plateID].eulerPole.xyz * plates[plateID].eulerPole.w;
+    euler += torque * (deltaTime / momentOfInertia);
+    
+    // Limit angular velocity
+    float angularVel = length(euler);
+    vec3 axis = angularVel > 0.0 ? euler / angularVel : euler;
+    plates[plateID].eulerPole = vec4(axis, min(angularVel, maxPlateVelocity / planetRadius));
+    
+    // Update motion
+    plates[plateID].motion = vec4(plateVelocity, 0.0);
+    plates[plateID].memberCount = memberCount;
+    plates[plateID].boundaryCount = boundaryCount;
 }
 `
 
 // Plate boundary interaction shader - handles collisions, subduction, spreading
-const plateBoundaryShader = `
-#version 430 core
-
-layout(local_size_x = 32, local_size_y = 1, local_size_z = 1) in;
-
-// Same buffer bindings as plate dynamics shader
-layout(std430, binding = 0) buffer VoxelData {
-    int type;
-    float density;
-    float temperature;
-    float pressure;
-    float VelNorth;
-    float VelEast;
-    float velR;
-    float age;
-    float viscosity;
-    float heatCapacity;
-    float thermalConductivity;
-    float radioactiveHeat;
-    int plateID;
-    int isBoundary;
-    float padding[2];
-} voxels[];
-
-layout(std430, binding = 1) readonly buffer ShellData {
-    float innerRadius;
-    float outerRadius;
-    int latBands;
-    int voxelOffset;
-    int lonCountOffset;
-    float padding[3];
-} shells[];
-
-layout(std430, binding = 2) readonly buffer LonCounts {
-    int counts[];
-} lonCounts;
-
-layout(std430, binding = 3) readonly buffer PlateData {
-    vec4 eulerPole;
-    vec4 ridgePush;
-    vec4 slabPull;
-    vec4 basalDrag;
-    vec4 properties;
-    vec4 motion;
-    int memberCount;
-    int boundaryCount;
-    float avgAge;
-    float convergence;
-} plates[];
-
+var plateBoundaryShader = plateShaderCommon + `
 // Boundary type classification
 const int BOUNDARY_NONE = 0;
 const int BOUNDARY_DIVERGENT = 1;
 const int BOUNDARY_CONVERGENT = 2;
 const int BOUNDARY_TRANSFORM = 3;
 
-// Material types
-const int MAT_AIR = 0;
-const int MAT_WATER = 1;
-const int MAT_BASALT = 2;
-const int MAT_GRANITE = 3;
-const int MAT_PERIDOTITE = 4;
-const int MAT_MAGMA = 5;
-
 uniform float deltaTime;
-uniform int shellCount;
 uniform int surfaceShell;
 uniform float planetRadius;
 
-// Find voxel index from shell/lat/lon (same as in dynamics shader)
-int getVoxelIndex(int shell, int lat, int lon) {
-    if (shell < 0 || shell >= shellCount) return -1;
-    
-    int offset = shells[shell].voxelOffset;
-    int latBands = shells[shell].latBands;
-    
-    if (lat < 0 || lat >= latBands) return -1;
-    
-    int lonCountOffset = shells[shell].lonCountOffset;
-    int lonCount = lonCounts.counts[lonCountOffset + lat];
-    
-    if (lon < 0 || lon >= lonCount) return -1;
-    
-    for (int i = 0; i < lat; i++) {
-        offset += lonCounts.counts[lonCountOffset + i];
-    }
-    
-    return offset + lon;
-}
+// Boundary process strengths, see BoundaryParams
+uniform float frictionCoefficient;
+uniform float frictionHeating;
+uniform float stressRelease;
+uniform float collisionStress;
+uniform float collisionUplift;
+uniform float subductionRate;
 
 // Get neighbor indices
 void getNeighbors(int idx, int shell, int lat, int lon, out int neighbors[4]) {
     int latBands = shells[shell].latBands;
     int lonCountOffset = shells[shell].lonCountOffset;
-    int lonCount = lonCounts.counts[lonCountOffset + lat];
+    int lonCount = lonCounts[lonCountOffset + lat];
     
     // East/West with wrapping
     int eastLon = (lon + 1) % lonCount;
@@ -404,6 +344,14 @@ void getNeighbors(int idx, int shell, int lat, int lon, out int neighbors[4]) {
     neighbors[3] = (lat < latBands - 1) ? getVoxelIndex(shell, lat + 1, lon) : -1;
 }
 
+// Earthquake: a fault loaded past stressRelease slips, keeping a tenth of
+// its stress. Unlike the CPU path's, these aren't logged as SeismicEvents
+void releaseStress(uint idx) {
+    if (voxels[idx].pressure > stressRelease) {
+        voxels[idx].pressure *= 0.1;
+    }
+}
+
 // Classify boundary type based on relative motion
 int classifyBoundary(vec3 vel1, vec3 vel2, vec3 normal) {
     vec3 relVel = vel2 - vel1;
@@ -429,41 +377,14 @@ int classifyBoundary(vec3 vel1, vec3 vel2, vec3 normal) {
 void main() {
     uint idx = gl_GlobalInvocationID.x;
     
-    if (idx >= voxels.length()) return;
+    if (idx >= uint(voxels.length())) return;
     
     // Only process boundary voxels
     if (voxels[idx].isBoundary == 0) return;
     
     // Find shell/lat/lon
-    int shell = -1;
-    int lat = -1;
-    int lon = -1;
-    
-    for (int s = 0; s < shellCount; s++) {
-        int shellStart = shells[s].voxelOffset;
-        int shellEnd = (s < shellCount - 1) ? shells[s + 1].voxelOffset : int(voxels.length());
-        
-        if (idx >= shellStart && idx < shellEnd) {
-            shell = s;
-            int offsetInShell = int(idx) - shellStart;
-            int lonCountOffset = shells[s].lonCountOffset;
-            int latBands = shells[s].latBands;
-            
-            int accumOffset = 0;
-            for (int l = 0; l < latBands; l++) {
-                int lonCount = lonCounts.counts[lonCountOffset + l];
-                if (offsetInShell < accumOffset + lonCount) {
-                    lat = l;
-                    lon = offsetInShell - accumOffset;
-                    break;
-                }
-                accumOffset += lonCount;
-            }
-            break;
-        }
-    }
-    
-    if (shell < 0 || lat < 0 || lon < 0) return;
+    int shell, lat, lon;
+    if (!locateVoxel(int(idx), shell, lat, lon)) return;
     
     // Get neighbors
     int neighbors[4];
@@ -479,8 +400,8 @@ void main() {
         if (neighborPlate == myPlate || neighborPlate < 0) continue;
         
         // Different plates - this is a boundary
-        vec3 myVel = vec3(voxels[idx].VelEast, voxels[idx].VelNorth, voxels[idx].velR);
-        vec3 neighborVel = vec3(voxels[neighbors[i]].VelEast, voxels[neighbors[i]].VelNorth, voxels[neighbors[i]].velR);
+        vec3 myVel = vec3(voxels[idx].velEast, voxels[idx].velNorth, voxels[idx].velR);
+        vec3 neighborVel = vec3(voxels[neighbors[i]].velEast, voxels[neighbors[i]].velNorth, voxels[neighbors[i]].velR);
         
         // Normal direction (simplified - should be proper spherical)
         vec3 normal = normalize(vec3(float(i < 2 ? 1 : 0), float(i >= 2 ? 1 : 0), 0));
@@ -491,96 +412,55 @@ void main() {
         switch (boundaryType) {
             case BOUNDARY_DIVERGENT:
                 // Seafloor spreading - create new basalt
-                if (voxels[idx].type == MAT_BASALT || voxels[idx].type == MAT_WATER) {
+                if (voxels[idx].matType == MAT_BASALT || voxels[idx].matType == MAT_WATER) {
                     voxels[idx].age = 0.0; // New crust
                     voxels[idx].temperature = 1500.0; // Hot from mantle
-                    if (voxels[idx].type == MAT_WATER) {
-                        voxels[idx].type = MAT_BASALT;
+                    if (voxels[idx].matType == MAT_WATER) {
+                        voxels[idx].matType = MAT_BASALT;
                     }
                 }
                 break;
                 
             case BOUNDARY_CONVERGENT:
                 // Subduction or collision
-                bool iOceanic = (voxels[idx].type == MAT_BASALT);
-                bool neighborOceanic = (voxels[neighbors[i]].type == MAT_BASALT);
+                bool iOceanic = (voxels[idx].matType == MAT_BASALT);
+                bool neighborOceanic = (voxels[neighbors[i]].matType == MAT_BASALT);
                 
                 if (iOceanic && !neighborOceanic) {
                     // Oceanic plate subducts under continental
-                    voxels[idx].velR = -0.01; // Downward motion
-                    voxels[idx].temperature += 10.0 * deltaTime; // Heating from friction
+                    voxels[idx].velR = -subductionRate; // Downward motion
+                    voxels[idx].temperature += frictionHeating * deltaTime;
                     
                     // Partial melting creates magma
                     if (voxels[idx].temperature > 1200.0 && voxels[idx].pressure > 1e9) {
                         // Small chance to create magma
                         if (fract(sin(float(idx) * 12.9898) * 43758.5453) < 0.001 * deltaTime) {
-                            voxels[idx].type = MAT_MAGMA;
+                            voxels[idx].matType = MAT_MAGMA;
                         }
                     }
                 } else if (!iOceanic && !neighborOceanic) {
                     // Continental collision - thicken crust
-                    voxels[idx].velR = 0.001; // Slight uplift
-                    voxels[idx].density *= 1.0001; // Compression
+                    voxels[idx].velR = collisionUplift;
+                    voxels[idx].density *= 1.0 + collisionStress; // Compression
                 }
                 break;
                 
             case BOUNDARY_TRANSFORM:
                 // Strike-slip motion - accumulate stress
-                float shearStress = length(myVel - neighborVel) * 1e9;
+                float shearStress = length(myVel - neighborVel) * frictionCoefficient;
                 voxels[idx].pressure += shearStress * deltaTime;
-                
-                // Earthquake potential (simplified)
-                if (voxels[idx].pressure > 1e10) {
-                    // Release stress
-                    voxels[idx].pressure *= 0.1;
-                    // Could trigger actual earthquake mechanics here
-                }
+                releaseStress(idx);
                 break;
         }
     }
 }
 `
 
-// Apply plate motion shader - updates voxel velocities based on plate rotation
-const applyPlateMotionShader = `
-#version 430 core
-
-layout(local_size_x = 32, local_size_y = 1, local_size_z = 1) in;
-
-// Buffer bindings...
-layout(std430, binding = 0) buffer VoxelData {
-    int type;
-    float density;
-    float temperature;
-    float pressure;
-    float VelNorth;
-    float VelEast;
-    float velR;
-    float age;
-    float viscosity;
-    float heatCapacity;
-    float thermalConductivity;
-    float radioactiveHeat;
-    int plateID;
-    int isBoundary;
-    float padding[2];
-} voxels[];
-
-layout(std430, binding = 3) readonly buffer PlateData {
-    vec4 eulerPole;
-    vec4 ridgePush;
-    vec4 slabPull;
-    vec4 basalDrag;
-    vec4 properties;
-    vec4 motion;
-    int memberCount;
-    int boundaryCount;
-    float avgAge;
-    float convergence;
-} plates[];
-
+// Apply plate motion shader - moves voxel velocities toward their plate's
+// rotation about its Euler pole; PlateMotion is its CPU reference
+var applyPlateMotionShader = plateShaderCommon + `
 uniform float deltaTime;
-uniform float planetRadius;
+uniform int plateCount;
 
 // Convert Euler pole rotation to velocity at a point
 vec3 eulerPoleVelocity(vec3 poleAxis, float angularVel, vec3 position) {
@@ -592,47 +472,36 @@ vec3 eulerPoleVelocity(vec3 poleAxis, float angularVel, vec3 position) {
 void main() {
     uint idx = gl_GlobalInvocationID.x;
     
-    if (idx >= voxels.length()) return;
+    if (idx >= uint(voxels.length())) return;
     
     int plateID = voxels[idx].plateID;
-    if (plateID < 0 || plateID >= plates.length()) return;
+    if (plateID < 0 || plateID >= plateCount) return;
     
     // Skip boundary voxels - they have special dynamics
     if (voxels[idx].isBoundary > 0) return;
     
+    int shell, lat, lon;
+    if (!locateVoxel(int(idx), shell, lat, lon)) return;
+    
     // Get Euler pole for this plate
     vec3 poleAxis = normalize(plates[plateID].eulerPole.xyz);
     float angularVel = plates[plateID].eulerPole.w;
     
-    // Get voxel position (simplified - should use proper spherical coords)
-    vec3 position = normalize(vec3(
-        cos(float(idx) * 0.1) * cos(float(idx) * 0.2),
-        sin(float(idx) * 0.1) * cos(float(idx) * 0.2),
-        sin(float(idx) * 0.2)
-    )) * planetRadius;
-    
-    // Calculate velocity from plate rotation
+    // Calculate velocity from plate rotation at the voxel's position
+    vec3 position = getSphericalPosition(shell, lat, lon);
     vec3 plateVel = eulerPoleVelocity(poleAxis, angularVel, position);
     
-    // Convert to spherical velocity components
-    // This is a simplified conversion - proper spherical coords are complex
-    float r = length(position);
-    float theta = acos(position.z / r);
-    float phi = atan(position.y, position.x);
-    
-    // Project velocity onto spherical basis vectors
-    vec3 r_hat = position / r;
-    vec3 theta_hat = vec3(-sin(theta) * cos(phi), -sin(theta) * sin(phi), cos(theta));
-    vec3 phi_hat = vec3(-sin(phi), cos(phi), 0.0);
+    // Project velocity onto the local north and east directions
+    vec3 up, east, north;
+    localBasis(position, up, east, north);
     
     // Update velocities (blend with existing for smooth transition)
-    float blendFactor = 0.1; // How quickly to adopt plate motion
-    voxels[idx].VelNorth = mix(voxels[idx].VelNorth, dot(plateVel, theta_hat), blendFactor);
-    voxels[idx].VelEast = mix(voxels[idx].VelEast, dot(plateVel, phi_hat), blendFactor);
+    voxels[idx].velNorth = mix(voxels[idx].velNorth, dot(plateVel, north), PLATE_MOTION_BLEND);
+    voxels[idx].velEast = mix(voxels[idx].velEast, dot(plateVel, east), PLATE_MOTION_BLEND);
     // velR is controlled by thermal/convection processes, not plate motion
     
     // Update age
-    voxels[idx].age += deltaTime / (365.25 * 24.0 * 3600.0); // Convert to years
+    voxels[idx].age += deltaTime;
 }
 `
 
@@ -642,17 +511,29 @@ type ComputePlateTectonics struct {
 	boundaryProgram      uint32
 	applyMotionProgram   uint32
 
+	// Voxels the shaders work on, the physics engine's voxel buffer
+	voxelSSBO uint32
+
 	plateDataSSBO uint32
+	shellSSBO     uint32
+	lonCountSSBO  uint32
 	plateCount    int
 	shellCount    int
 
+	// Boundary strengths uploaded each RunBoundaryInteractions
+	Boundary BoundaryParams
+
+	// Fastest a plate's surface may move in m/year, the planet's
+	// PlateSpeedLimit, uploaded each RunPlateDynamics
+	MaxPlateVelocity float32
+
 	workGroupSize int32
 	numWorkGroups int
 }
 
 // PlateDataGPU matches the GPU struct layout
 type PlateDataGPU struct {
-	EulerPole     [4]float32 // xyz = axis, w = angular velocity
+	EulerPole     [4]float32 // xyz = unit axis, w = angular velocity in radians per year
 	RidgePush     [4]float32 // xyz = force, w = magnitude
 	SlabPull      [4]float32 // xyz = force, w = magnitude
 	BasalDrag     [4]float32 // xyz = force, w = magnitude
@@ -664,20 +545,49 @@ type PlateDataGPU struct {
 	Convergence   float32
 }
 
+// PlateShaderDefines returns the material numbers and constants the plate
+// shaders use, as preprocessor defines
+func PlateShaderDefines() string {
+	var b strings.Builder
+	for _, m := range []struct {
+		name string
+		mat  core.MaterialType
+	}{
+		{"MAT_WATER", core.MatWater},
+		{"MAT_BASALT", core.MatBasalt},
+		{"MAT_MAGMA", core.MatMagma},
+		{"MAT_ECLOGITE", core.MatEclogite},
+	} {
+		fmt.Fprintf(&b, "#define %s %du\n", m.name, m.mat)
+	}
+	for _, c := range []struct {
+		name  string
+		value float64
+	}{
+		{"PLATE_MANTLE_VISCOSITY", plateMantleViscosity},
+		{"PLATE_MOTION_BLEND", plateMotionBlend},
+	} {
+		fmt.Fprintf(&b, "#define %s %s\n", c.name, shaderFloat(c.value))
+	}
+	return b.String()
+}
+
 // NewComputePlateTectonics creates plate tectonics compute shaders
 func NewComputePlateTectonics(planet *core.VoxelPlanet, plateManager *simulation.PlateManager) (*ComputePlateTectonics, error) {
+	shells, lonCounts := PlateShells(planet)
 	cp := &ComputePlateTectonics{
 		plateCount:    len(plateManager.Plates),
-		shellCount:    len(planet.Shells),
+		shellCount:    len(shells),
 		workGroupSize: 32,
+		Boundary:      DefaultBoundaryParams(),
+
+		MaxPlateVelocity: float32(planet.PlateSpeedLimit() / 100), // cm/year to m/year
 	}
 
 	// Calculate work groups
 	totalVoxels := 0
-	for _, shell := range planet.Shells {
-		for _, count := range shell.LonCounts {
-			totalVoxels += count
-		}
+	for _, count := range lonCounts {
+		totalVoxels += int(count)
 	}
 	cp.numWorkGroups = (totalVoxels + int(cp.workGroupSize) - 1) / int(cp.workGroupSize)
 
@@ -698,64 +608,74 @@ func NewComputePlateTectonics(planet *core.VoxelPlanet, plateManager *simulation
 		return nil, fmt.Errorf("failed to compile apply motion shader: %v", err)
 	}
 
+	// Shell table the shaders locate voxels with
+	gl.GenBuffers(1, &cp.shellSSBO)
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, cp.shellSSBO)
+	gl.BufferData(gl.SHADER_STORAGE_BUFFER, max(len(shells), 1)*int(unsafe.Sizeof(PlateShellGPU{})), gl.Ptr(append(shells, PlateShellGPU{})), gl.STATIC_DRAW)
+
+	gl.GenBuffers(1, &cp.lonCountSSBO)
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, cp.lonCountSSBO)
+	gl.BufferData(gl.SHADER_STORAGE_BUFFER, max(len(lonCounts), 1)*4, gl.Ptr(append(lonCounts, 0)), gl.STATIC_DRAW)
+
 	// Create plate data SSBO
 	gl.GenBuffers(1, &cp.plateDataSSBO)
-	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, cp.plateDataSSBO)
+	cp.SetPlates(PlatesGPU(plateManager))
 
-	// Initialize plate data
-	plateData := make([]PlateDataGPU, cp.plateCount)
-	for i, plate := range plateManager.Plates {
-		// Convert plate data to GPU format
-		plateData[i].EulerPole[0] = float32(plate.EulerPoleLat)
-		plateData[i].EulerPole[1] = float32(plate.EulerPoleLon)
-		plateData[i].EulerPole[2] = 0.0 // z component
-		plateData[i].EulerPole[3] = float32(plate.AngularVelocity)
-
-		plateData[i].Properties[0] = float32(plate.TotalMass)
-		plateData[i].Properties[1] = float32(plate.TotalArea)
-		plateData[i].Properties[2] = float32(plate.AverageThickness)
-
-		// Plate type encoding
-		switch plate.Type {
-		case "oceanic":
-			plateData[i].Properties[3] = 0
-		case "continental":
-			plateData[i].Properties[3] = 1
-		default:
-			plateData[i].Properties[3] = 2
-		}
+	fmt.Printf("✅ Plate tectonics compute shaders compiled successfully (%d plates)\n", cp.plateCount)
 
-		plateData[i].MemberCount = int32(len(plate.MemberVoxels))
-		plateData[i].BoundaryCount = int32(len(plate.BoundaryVoxels))
-		plateData[i].AvgAge = float32(plate.AverageAge)
+	return cp, nil
+}
+
+// SetPlates replaces the plates on the GPU
+func (cp *ComputePlateTectonics) SetPlates(plates []PlateDataGPU) {
+	cp.plateCount = len(plates)
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, cp.plateDataSSBO)
+	if len(plates) > 0 {
+		gl.BufferData(gl.SHADER_STORAGE_BUFFER, len(plates)*int(unsafe.Sizeof(PlateDataGPU{})), gl.Ptr(plates), gl.DYNAMIC_DRAW)
+	} else {
+		gl.BufferData(gl.SHADER_STORAGE_BUFFER, int(unsafe.Sizeof(PlateDataGPU{})), nil, gl.DYNAMIC_DRAW)
 	}
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, 0)
+}
 
-	// Upload plate data
-	if len(plateData) > 0 {
-		gl.BufferData(gl.SHADER_STORAGE_BUFFER, len(plateData)*64, gl.Ptr(plateData), gl.DYNAMIC_DRAW)
+// ReadPlates returns the plates as the shaders last left them
+func (cp *ComputePlateTectonics) ReadPlates() []PlateDataGPU {
+	plates := make([]PlateDataGPU, cp.plateCount)
+	if len(plates) == 0 {
+		return plates
 	}
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, cp.plateDataSSBO)
+	gl.GetBufferSubData(gl.SHADER_STORAGE_BUFFER, 0, len(plates)*int(unsafe.Sizeof(PlateDataGPU{})), unsafe.Pointer(&plates[0]))
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, 0)
+	return plates
+}
 
-	fmt.Printf("✅ Plate tectonics compute shaders compiled successfully (%d plates)\n", cp.plateCount)
+// useProgram makes program current with the plate buffers bound and the
+// shell and plate counts set
+func (cp *ComputePlateTectonics) useProgram(program uint32) {
+	gl.UseProgram(program)
 
-	return cp, nil
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 0, cp.voxelSSBO)
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 1, cp.shellSSBO)
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 2, cp.lonCountSSBO)
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 3, cp.plateDataSSBO)
+
+	gl.Uniform1i(gl.GetUniformLocation(program, gl.Str("shellCount\x00")), int32(cp.shellCount))
+	gl.Uniform1i(gl.GetUniformLocation(program, gl.Str("plateCount\x00")), int32(cp.plateCount))
 }
 
 // RunPlateDynamics calculates plate forces and velocities
 func (cp *ComputePlateTectonics) RunPlateDynamics(deltaTime float32, planetRadius float32, surfaceShell int32) {
-	gl.UseProgram(cp.plateDynamicsProgram)
-
-	// Bind plate data SSBO
-	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 3, cp.plateDataSSBO)
+	cp.useProgram(cp.plateDynamicsProgram)
 
 	// Set uniforms
 	gl.Uniform1f(gl.GetUniformLocation(cp.plateDynamicsProgram, gl.Str("deltaTime\x00")), deltaTime)
-	gl.Uniform1i(gl.GetUniformLocation(cp.plateDynamicsProgram, gl.Str("shellCount\x00")), int32(cp.shellCount))
-	gl.Uniform1i(gl.GetUniformLocation(cp.plateDynamicsProgram, gl.Str("plateCount\x00")), int32(cp.plateCount))
 	gl.Uniform1f(gl.GetUniformLocation(cp.plateDynamicsProgram, gl.Str("planetRadius\x00")), planetRadius)
+	gl.Uniform1f(gl.GetUniformLocation(cp.plateDynamicsProgram, gl.Str("maxPlateVelocity\x00")), cp.MaxPlateVelocity)
 	gl.Uniform1i(gl.GetUniformLocation(cp.plateDynamicsProgram, gl.Str("surfaceShell\x00")), surfaceShell)
-	gl.Uniform1i(gl.GetUniformLocation(cp.plateDynamicsProgram, gl.Str("lithosphereDepth\x00")), 2) // 2 shells deep
+	gl.Uniform1i(gl.GetUniformLocation(cp.plateDynamicsProgram, gl.Str("lithosphereDepth\x00")), plateLithosphereDepth)
 
-	// Dispatch one work group per plate
+	// Dispatch one invocation per plate
 	plateWorkGroups := (cp.plateCount + int(cp.workGroupSize) - 1) / int(cp.workGroupSize)
 	gl.DispatchCompute(uint32(plateWorkGroups), 1, 1)
 
@@ -764,16 +684,13 @@ func (cp *ComputePlateTectonics) RunPlateDynamics(deltaTime float32, planetRadiu
 
 // RunBoundaryInteractions handles collisions, subduction, spreading
 func (cp *ComputePlateTectonics) RunBoundaryInteractions(deltaTime float32, surfaceShell int32, planetRadius float32) {
-	gl.UseProgram(cp.boundaryProgram)
-
-	// Bind plate data SSBO
-	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 3, cp.plateDataSSBO)
+	cp.useProgram(cp.boundaryProgram)
 
 	// Set uniforms
 	gl.Uniform1f(gl.GetUniformLocation(cp.boundaryProgram, gl.Str("deltaTime\x00")), deltaTime)
-	gl.Uniform1i(gl.GetUniformLocation(cp.boundaryProgram, gl.Str("shellCount\x00")), int32(cp.shellCount))
 	gl.Uniform1i(gl.GetUniformLocation(cp.boundaryProgram, gl.Str("surfaceShell\x00")), surfaceShell)
 	gl.Uniform1f(gl.GetUniformLocation(cp.boundaryProgram, gl.Str("planetRadius\x00")), planetRadius)
+	cp.Boundary.setUniforms(cp.boundaryProgram)
 
 	// Process all voxels
 	gl.DispatchCompute(uint32(cp.numWorkGroups), 1, 1)
@@ -782,15 +699,11 @@ func (cp *ComputePlateTectonics) RunBoundaryInteractions(deltaTime float32, surf
 }
 
 // ApplyPlateMotion updates voxel velocities based on plate rotation
-func (cp *ComputePlateTectonics) ApplyPlateMotion(deltaTime float32, planetRadius float32) {
-	gl.UseProgram(cp.applyMotionProgram)
-
-	// Bind plate data SSBO
-	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 3, cp.plateDataSSBO)
+func (cp *ComputePlateTectonics) ApplyPlateMotion(deltaTime float32) {
+	cp.useProgram(cp.applyMotionProgram)
 
 	// Set uniforms
 	gl.Uniform1f(gl.GetUniformLocation(cp.applyMotionProgram, gl.Str("deltaTime\x00")), deltaTime)
-	gl.Uniform1f(gl.GetUniformLocation(cp.applyMotionProgram, gl.Str("planetRadius\x00")), planetRadius)
 
 	// Process all voxels
 	gl.DispatchCompute(uint32(cp.numWorkGroups), 1, 1)
@@ -807,7 +720,7 @@ func (cp *ComputePlateTectonics) RunFullPlateStep(deltaTime float32, planetRadiu
 	cp.RunBoundaryInteractions(deltaTime, surfaceShell, planetRadius)
 
 	// 3. Apply plate motion to voxels
-	cp.ApplyPlateMotion(deltaTime, planetRadius)
+	cp.ApplyPlateMotion(deltaTime)
 }
 
 // Release cleans up GPU resources
@@ -821,7 +734,10 @@ func (cp *ComputePlateTectonics) Release() {
 	if cp.applyMotionProgram != 0 {
 		gl.DeleteProgram(cp.applyMotionProgram)
 	}
-	if cp.plateDataSSBO != 0 {
-		gl.DeleteBuffers(1, &cp.plateDataSSBO)
+	for _, buffer := range []*uint32{&cp.plateDataSSBO, &cp.shellSSBO, &cp.lonCountSSBO} {
+		if *buffer != 0 {
+			gl.DeleteBuffers(1, buffer)
+			*buffer = 0
+		}
 	}
 }
@@ -0,0 +1,464 @@
+package gpu
+
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+
+	"worldgenerator/core"
+
+	"github.com/go-gl/gl/v4.3-core/gl"
+)
+
+// Slots of each voxel's entry in the neighbor index buffer, shared by the
+// OpenGL and Metal kernels (band index grows north)
+const (
+	neighborInner = iota
+	neighborOuter
+	neighborSouth
+	neighborNorth
+	neighborWest
+	neighborEast
+	neighborsPerVoxel
+)
+
+// Buffer bindings of the temperature kernel, clear of the 0-3 the renderer
+// and the other compute shaders bind
+const (
+	temperatureVoxelBinding       = 4
+	temperatureNeighborBinding    = 5
+	temperatureOutputBinding      = 6
+	temperatureDiffusivityBinding = 7
+	temperatureSpacingBinding     = 8
+)
+
+// deepHeatedShells is how many of the innermost shells radiogenic heat warms
+// while no core boundary condition replaces it, as on the CPU
+const deepHeatedShells = 5
+
+// temperatureLocalSize must match local_size_x in temperatureFastShader
+const temperatureLocalSize = 64
+
+// temperatureFastShader is the OpenGL port of the CPU heat diffusion
+// (updateTemperatureCPU in physics): each voxel takes heat from its neighbors
+// in proportion to their temperature difference over the squared distance
+// between them, found through the precomputed index and spacing buffers,
+// and the deep shells warm by radiogenic heat. Stage 0 writes the new
+// temperatures to their own buffer so no invocation reads a neighbor already
+// updated this step; stage 1 copies them back into the voxels
+var temperatureFastShader = `#version 430 core
+#define uint32_t uint
+#define int32_t int
+
+layout(local_size_x = 64) in;
+
+struct Voxel {
+` + GPUVoxelStructFields("    ") + `};
+
+layout(std430, binding = 4) buffer Voxels {
+    Voxel voxels[];
+};
+
+layout(std430, binding = 5) readonly buffer Neighbors {
+    int neighborIndices[]; // 6 per voxel: -r,+r,-lat,+lat,-lon,+lon
+};
+
+layout(std430, binding = 6) buffer Temperatures {
+    float temperatures[];
+};
+
+layout(std430, binding = 7) readonly buffer Diffusivity {
+    float materialDiffusivity[];
+};
+
+layout(std430, binding = 8) readonly buffer Spacing {
+    float neighborSpacing[]; // Squared distance per neighbor slot, 0 where heat doesn't flow
+};
+
+uniform float dt;
+uniform float radiogenicHeat; // K per year in the deep shells
+uniform uint deepVoxels;      // The deep shells come first in the buffer
+uniform uint voxelCount;
+uniform int stage;
+
+void main() {
+    uint voxelIndex = gl_GlobalInvocationID.x;
+    if (voxelIndex >= voxelCount) return;
+
+    if (stage == 1) {
+        voxels[voxelIndex].temperature = temperatures[voxelIndex];
+        return;
+    }
+
+    Voxel voxel = voxels[voxelIndex];
+
+    // Skip air
+    if (voxel.matType == 0u) {
+        temperatures[voxelIndex] = voxel.temperature;
+        return;
+    }
+
+    // Per-material diffusivity - oceans respond slower than rock
+    float alpha = materialDiffusivity[voxel.matType];
+
+    // Calculate heat flow from neighbors
+    float heatFlow = 0.0;
+    for (uint i = 0u; i < 6u; i++) {
+        uint slot = voxelIndex * 6u + i;
+        int neighborIdx = neighborIndices[slot];
+        float spacing = neighborSpacing[slot];
+        if (neighborIdx >= 0 && uint(neighborIdx) < voxelCount && spacing > 0.0) {
+            float dT = voxels[neighborIdx].temperature - voxel.temperature;
+            heatFlow += dT * alpha / spacing;
+        }
+    }
+
+    float temperature = voxel.temperature + heatFlow * dt;
+
+    // Add radioactive heating in deep shells
+    if (voxelIndex < deepVoxels) {
+        temperature += radiogenicHeat * dt;
+    }
+
+    temperatures[voxelIndex] = temperature;
+}
+`
+
+// MaterialDiffusivities builds the thermal diffusivity table the temperature
+// kernels index by material type
+func MaterialDiffusivities() []float32 {
+	table := make([]float32, core.MaterialCount())
+	for mat := range table {
+		table[mat] = core.MaterialDiffusivity(core.MaterialType(mat))
+	}
+	return table
+}
+
+// NeighborIndices builds the neighbor index buffer for a planet's voxels,
+// flattened in shell, band, longitude order as SharedGPUBuffers lays them
+// out. Shells and bands differ in resolution, so radial and cross-band
+// neighbors are the voxel whose cell contains this one's center. Missing
+// neighbors are -1
+func NeighborIndices(planet *core.VoxelPlanet) []int32 {
+	// First flat index of every band
+	offsets := make([][]int, len(planet.Shells))
+	total := 0
+	for s, shell := range planet.Shells {
+		offsets[s] = make([]int, len(shell.Voxels))
+		for lat, band := range shell.Voxels {
+			offsets[s][lat] = total
+			total += len(band)
+		}
+	}
+
+	// centered scales index i of count to the matching index of another count
+	centered := func(i, count, other int) int {
+		return min((2*i+1)*other/(2*count), other-1)
+	}
+	// mapped returns the voxel of a band at lon out of lonCount
+	mapped := func(s, lat, lon, lonCount int) int32 {
+		n := len(planet.Shells[s].Voxels[lat])
+		if n == 0 {
+			return -1
+		}
+		return int32(offsets[s][lat] + centered(lon, lonCount, n))
+	}
+	// radial returns the voxel above or below a band in shell s, in the band
+	// at the nearest latitude
+	radial := func(s, fromBands, lat, lon, lonCount int) int32 {
+		bands := len(planet.Shells[s].Voxels)
+		if bands == 0 {
+			return -1
+		}
+		return mapped(s, core.GetBandForLatitude(core.GetLatitudeForBand(lat, fromBands), bands), lon, lonCount)
+	}
+
+	indices := make([]int32, total*neighborsPerVoxel)
+	for i := range indices {
+		indices[i] = -1
+	}
+	for s, shell := range planet.Shells {
+		bands := len(shell.Voxels)
+		for lat, band := range shell.Voxels {
+			n := len(band)
+			for lon := range band {
+				slots := indices[(offsets[s][lat]+lon)*neighborsPerVoxel:][:neighborsPerVoxel]
+				if s > 0 {
+					slots[neighborInner] = radial(s-1, bands, lat, lon, n)
+				}
+				if s < len(planet.Shells)-1 {
+					slots[neighborOuter] = radial(s+1, bands, lat, lon, n)
+				}
+				if lat > 0 {
+					slots[neighborSouth] = mapped(s, lat-1, lon, n)
+				}
+				if lat < bands-1 {
+					slots[neighborNorth] = mapped(s, lat+1, lon, n)
+				}
+				if n > 1 {
+					slots[neighborWest] = int32(offsets[s][lat] + (lon-1+n)%n)
+					slots[neighborEast] = int32(offsets[s][lat] + (lon+1)%n)
+				}
+			}
+		}
+	}
+	return indices
+}
+
+// NeighborSpacings builds the squared distance to each neighbor in
+// NeighborIndices that heat flows across, the way the CPU diffusion measures
+// it: between shells by the gap separating them, around a band by its
+// voxel width. Slots heat doesn't flow across are 0
+func NeighborSpacings(planet *core.VoxelPlanet) []float32 {
+	var spacings []float32
+	for s, shell := range planet.Shells {
+		inner, outer := float32(0), float32(0)
+		if s > 0 {
+			if dr := shell.InnerRadius - planet.Shells[s-1].OuterRadius; dr > 0 {
+				inner = float32(dr * dr)
+			}
+		}
+		if s < len(planet.Shells)-1 {
+			if dr := planet.Shells[s+1].InnerRadius - shell.OuterRadius; dr > 0 {
+				outer = float32(dr * dr)
+			}
+		}
+
+		radius := (shell.InnerRadius + shell.OuterRadius) / 2
+		for _, band := range shell.Voxels {
+			lateral := float32(0)
+			if len(band) > 1 {
+				dx := radius * 2 * 3.14159 / float64(len(band))
+				lateral = float32(dx * dx)
+			}
+			for range band {
+				slots := make([]float32, neighborsPerVoxel)
+				slots[neighborInner] = inner
+				slots[neighborOuter] = outer
+				slots[neighborWest] = lateral
+				slots[neighborEast] = lateral
+				spacings = append(spacings, slots...)
+			}
+		}
+	}
+	return spacings
+}
+
+// deepVoxelCount returns how many voxels the deep heated shells hold, which
+// come first in the flattened buffers
+func deepVoxelCount(planet *core.VoxelPlanet) int {
+	count := 0
+	for _, shell := range planet.Shells[:min(deepHeatedShells, len(planet.Shells))] {
+		for _, band := range shell.Voxels {
+			count += len(band)
+		}
+	}
+	return count
+}
+
+// deepHeating returns the radiogenic heat in K per year the deep shells of
+// planet take, none once a core boundary condition replaces it
+func deepHeating(planet *core.VoxelPlanet) float32 {
+	if planet.CoreBoundary != core.CoreBoundaryNone {
+		return 0
+	}
+	return float32(planet.RadiogenicHeat())
+}
+
+// DiffuseTemperatureFast is the CPU reference for the Metal
+// updateTemperatureFast kernel and its CUDA and OpenCL ports, returning the temperatures one step of dt years leaves the voxels at, with
+// voxels hotter than 4000 K heated by radiogenicHeat K per year
+func DiffuseTemperatureFast(voxels []GPUVoxelMaterial, neighbors []int32, diffusivity []float32, dt, radiogenicHeat float32) []float32 {
+	temperatures := make([]float32, len(voxels))
+	for i := range voxels {
+		voxel := &voxels[i]
+		if voxel.Type == uint32(core.MatAir) {
+			temperatures[i] = voxel.Temperature
+			continue
+		}
+		thermalDiffusivity := diffusivity[voxel.Type]
+
+		avgTemp := voxel.Temperature
+		neighborCount := 1
+		for _, n := range neighbors[i*neighborsPerVoxel : (i+1)*neighborsPerVoxel] {
+			if n >= 0 && int(n) < len(voxels) && voxels[n].Type != uint32(core.MatAir) {
+				avgTemp += voxels[n].Temperature
+				neighborCount++
+			}
+		}
+		avgTemp /= float32(neighborCount)
+
+		dTemp := thermalDiffusivity * (avgTemp - voxel.Temperature) * dt / (1000.0 * 1000.0)
+		if voxel.Temperature > 4000 {
+			dTemp += radiogenicHeat * dt
+		}
+		temperatures[i] = min(max(voxel.Temperature+dTemp, 0), 6000)
+	}
+	return temperatures
+}
+
+// createTemperatureBuffers uploads the neighbor and diffusivity tables and
+// allocates the voxel and temperature buffers the kernel works in
+func (cp *ComputePhysics) createTemperatureBuffers(planet *core.VoxelPlanet) {
+	neighbors := NeighborIndices(planet)
+	spacings := NeighborSpacings(planet)
+	diffusivity := MaterialDiffusivities()
+	cp.deepVoxels = deepVoxelCount(planet)
+	cp.voxelStaging = make([]GPUVoxelMaterial, cp.totalVoxels)
+	cp.temperatures = make([]float32, cp.totalVoxels)
+
+	gl.GenBuffers(1, &cp.voxelSSBO)
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, cp.voxelSSBO)
+	gl.BufferData(gl.SHADER_STORAGE_BUFFER, max(cp.totalVoxels, 1)*GPUVoxelSize, nil, gl.DYNAMIC_DRAW)
+
+	gl.GenBuffers(1, &cp.neighborSSBO)
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, cp.neighborSSBO)
+	if len(neighbors) > 0 {
+		gl.BufferData(gl.SHADER_STORAGE_BUFFER, len(neighbors)*4, gl.Ptr(neighbors), gl.STATIC_DRAW)
+	} else {
+		gl.BufferData(gl.SHADER_STORAGE_BUFFER, 4, nil, gl.STATIC_DRAW)
+	}
+
+	gl.GenBuffers(1, &cp.spacingSSBO)
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, cp.spacingSSBO)
+	if len(spacings) > 0 {
+		gl.BufferData(gl.SHADER_STORAGE_BUFFER, len(spacings)*4, gl.Ptr(spacings), gl.STATIC_DRAW)
+	} else {
+		gl.BufferData(gl.SHADER_STORAGE_BUFFER, 4, nil, gl.STATIC_DRAW)
+	}
+
+	gl.GenBuffers(1, &cp.temperatureSSBO)
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, cp.temperatureSSBO)
+	gl.BufferData(gl.SHADER_STORAGE_BUFFER, max(cp.totalVoxels, 1)*4, nil, gl.DYNAMIC_READ)
+
+	gl.GenBuffers(1, &cp.diffusivitySSBO)
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, cp.diffusivitySSBO)
+	gl.BufferData(gl.SHADER_STORAGE_BUFFER, len(diffusivity)*4, gl.Ptr(diffusivity), gl.STATIC_DRAW)
+
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, 0)
+}
+
+// SetRadiogenicHeat sets the deep heating RunTemperatureDiffusion applies,
+// 0 for none
+func (cp *ComputePhysics) SetRadiogenicHeat(kelvinPerYear float32) {
+	cp.radiogenicHeat = kelvinPerYear
+}
+
+// RunTemperatureDiffusion runs one temperature diffusion step of deltaTime
+// years on the voxels already in the compute buffers, heating the deep
+// shells by the rate last set
+func (cp *ComputePhysics) RunTemperatureDiffusion(deltaTime float32) {
+	gl.UseProgram(cp.temperatureDiffusionProgram)
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, temperatureVoxelBinding, cp.voxelSSBO)
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, temperatureNeighborBinding, cp.neighborSSBO)
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, temperatureOutputBinding, cp.temperatureSSBO)
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, temperatureDiffusivityBinding, cp.diffusivitySSBO)
+	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, temperatureSpacingBinding, cp.spacingSSBO)
+
+	// Set uniforms
+	gl.Uniform1f(gl.GetUniformLocation(cp.temperatureDiffusionProgram, gl.Str("dt\x00")), deltaTime)
+	gl.Uniform1f(gl.GetUniformLocation(cp.temperatureDiffusionProgram, gl.Str("radiogenicHeat\x00")), cp.radiogenicHeat)
+	gl.Uniform1ui(gl.GetUniformLocation(cp.temperatureDiffusionProgram, gl.Str("deepVoxels\x00")), uint32(cp.deepVoxels))
+	gl.Uniform1ui(gl.GetUniformLocation(cp.temperatureDiffusionProgram, gl.Str("voxelCount\x00")), uint32(cp.totalVoxels))
+	stage := gl.GetUniformLocation(cp.temperatureDiffusionProgram, gl.Str("stage\x00"))
+
+	groups := uint32((cp.totalVoxels + temperatureLocalSize - 1) / temperatureLocalSize)
+	gl.Uniform1i(stage, 0)
+	gl.DispatchCompute(groups, 1, 1)
+	gl.MemoryBarrier(gl.SHADER_STORAGE_BARRIER_BIT)
+	gl.Uniform1i(stage, 1)
+	gl.DispatchCompute(groups, 1, 1)
+	gl.MemoryBarrier(gl.SHADER_STORAGE_BARRIER_BIT | gl.BUFFER_UPDATE_BARRIER_BIT)
+}
+
+// StepTemperature diffuses heat through planet for dt years on the GPU and
+// writes the new temperatures back. Any goroutine may call it; the kernel
+// runs on the compute context, not the renderer's. Deep shells only warm
+// while no core boundary condition replaces their heating
+func (cp *ComputePhysics) StepTemperature(planet *core.VoxelPlanet, dt float32) error {
+	cp.SetRadiogenicHeat(deepHeating(planet))
+	count, err := cp.runOnContext(planet, func(count int) error {
+		cp.RunTemperatureDiffusion(dt)
+
+		gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, cp.temperatureSSBO)
+		gl.GetBufferSubData(gl.SHADER_STORAGE_BUFFER, 0, count*4, unsafe.Pointer(&cp.temperatures[0]))
+		gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, 0)
+		if code := gl.GetError(); code != gl.NO_ERROR {
+			return fmt.Errorf("temperature kernel failed: GL error 0x%x", code)
+		}
+		return nil
+	})
+	if err != nil || count == 0 {
+		return err
+	}
+
+	idx := 0
+	for _, shell := range planet.Shells {
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				shell.Voxels[latIdx][lonIdx].Temperature = cp.temperatures[idx]
+				idx++
+			}
+		}
+	}
+	return nil
+}
+
+// runOnContext uploads planet's voxels to the compute buffer and calls run
+// with the compute context current and the voxel count. It returns the count,
+// and doesn't call run for a planet without voxels
+func (cp *ComputePhysics) runOnContext(planet *core.VoxelPlanet, run func(count int) error) (int, error) {
+	cp.contextMu.Lock()
+	defer cp.contextMu.Unlock()
+	if cp.context == nil {
+		return 0, fmt.Errorf("compute context released")
+	}
+
+	idx := 0
+	for _, shell := range planet.Shells {
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				if idx >= len(cp.voxelStaging) {
+					return 0, fmt.Errorf("planet has more voxels than the %d the compute buffers hold", cp.totalVoxels)
+				}
+				cp.voxelStaging[idx] = ConvertToGPUVoxel(&shell.Voxels[latIdx][lonIdx])
+				idx++
+			}
+		}
+	}
+	if idx != cp.totalVoxels {
+		return 0, fmt.Errorf("planet has %d voxels, compute buffers hold %d", idx, cp.totalVoxels)
+	}
+	if idx == 0 {
+		return 0, nil
+	}
+
+	// GL contexts are current per OS thread
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	defer cp.context.MakeCurrent()()
+
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, cp.voxelSSBO)
+	gl.BufferSubData(gl.SHADER_STORAGE_BUFFER, 0, idx*GPUVoxelSize, unsafe.Pointer(&cp.voxelStaging[0]))
+
+	return idx, run(idx)
+}
+
+// releaseTemperatureBuffers frees the kernel's buffers and its context
+func (cp *ComputePhysics) releaseTemperatureBuffers() {
+	cp.contextMu.Lock()
+	defer cp.contextMu.Unlock()
+
+	buffers := []uint32{cp.voxelSSBO, cp.neighborSSBO, cp.spacingSSBO, cp.temperatureSSBO, cp.diffusivitySSBO}
+	for _, buffer := range buffers {
+		if buffer != 0 {
+			gl.DeleteBuffers(1, &buffer)
+		}
+	}
+	cp.voxelSSBO, cp.neighborSSBO, cp.spacingSSBO, cp.temperatureSSBO, cp.diffusivitySSBO = 0, 0, 0, 0, 0
+
+	if cp.context != nil {
+		cp.context.Destroy()
+		cp.context = nil
+	}
+}
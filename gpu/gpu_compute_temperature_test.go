@@ -0,0 +1,114 @@
+package gpu
+
+import (
+	"math"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// flatVoxels flattens a planet in the order the compute buffers use
+func flatVoxels(planet *core.VoxelPlanet) []GPUVoxelMaterial {
+	var voxels []GPUVoxelMaterial
+	for _, shell := range planet.Shells {
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				voxels = append(voxels, ConvertToGPUVoxel(&shell.Voxels[latIdx][lonIdx]))
+			}
+		}
+	}
+	return voxels
+}
+
+// TestNeighborIndices checks every voxel's neighbors are in range, that
+// east and west are each other's inverse, and that each radial neighbor is
+// the voxel of the next shell containing this one's center
+func TestNeighborIndices(t *testing.T) {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 4, 24)
+	voxels := flatVoxels(planet)
+	neighbors := NeighborIndices(planet)
+	if len(neighbors) != len(voxels)*neighborsPerVoxel {
+		t.Fatalf("%d neighbor slots for %d voxels, want %d", len(neighbors), len(voxels), len(voxels)*neighborsPerVoxel)
+	}
+
+	// Position of every flat index
+	type cell struct{ shell, lat, lon int }
+	cells := make([]cell, 0, len(voxels))
+	for s, shell := range planet.Shells {
+		for lat, band := range shell.Voxels {
+			for lon := range band {
+				cells = append(cells, cell{s, lat, lon})
+			}
+		}
+	}
+
+	for i, c := range cells {
+		slots := neighbors[i*neighborsPerVoxel : (i+1)*neighborsPerVoxel]
+		for slot, n := range slots {
+			if n < -1 || int(n) >= len(voxels) {
+				t.Fatalf("voxel %d slot %d points at %d of %d voxels", i, slot, n, len(voxels))
+			}
+		}
+		if (slots[neighborInner] < 0) != (c.shell == 0) {
+			t.Errorf("voxel %v inner neighbor %d", c, slots[neighborInner])
+		}
+		if (slots[neighborOuter] < 0) != (c.shell == len(planet.Shells)-1) {
+			t.Errorf("voxel %v outer neighbor %d", c, slots[neighborOuter])
+		}
+		if east := slots[neighborEast]; east >= 0 && neighbors[int(east)*neighborsPerVoxel+neighborWest] != int32(i) {
+			t.Errorf("voxel %v: west of its east neighbor is not itself", c)
+		}
+
+		// Fractions of the way up and around at the voxel's center
+		bands := len(planet.Shells[c.shell].Voxels)
+		lonCount := len(planet.Shells[c.shell].Voxels[c.lat])
+		lat := (float64(c.lat) + 0.5) / float64(bands)
+		lon := (float64(c.lon) + 0.5) / float64(lonCount)
+		for _, slot := range []int{neighborInner, neighborOuter} {
+			n := slots[slot]
+			if n < 0 {
+				continue
+			}
+			other := cells[n]
+			otherBands := len(planet.Shells[other.shell].Voxels)
+			otherCount := len(planet.Shells[other.shell].Voxels[other.lat])
+			otherLat := (float64(other.lat) + 0.5) / float64(otherBands)
+			otherLon := (float64(other.lon) + 0.5) / float64(otherCount)
+			if math.Abs(otherLat-lat) > 0.5/float64(otherBands)+1e-9 || math.Abs(otherLon-lon) > 0.5/float64(otherCount)+1e-9 {
+				t.Errorf("voxel %v has radial neighbor %v, whose cell doesn't contain its center", c, other)
+			}
+		}
+	}
+}
+
+// TestDiffuseTemperatureFast checks the CPU reference kernel cools a hot
+// voxel, warms its neighbors and leaves air alone
+func TestDiffuseTemperatureFast(t *testing.T) {
+	rock := GPUVoxelMaterial{Type: uint32(core.MatGranite), Temperature: 1000}
+	voxels := []GPUVoxelMaterial{rock, rock, rock, {Type: uint32(core.MatAir), Temperature: 250}}
+	voxels[1].Temperature = 2000
+	neighbors := []int32{
+		-1, -1, -1, -1, 3, 1, // 0: air west, hot east
+		-1, -1, -1, -1, 0, 2, // 1: the hot voxel
+		-1, -1, -1, -1, 1, -1,
+		-1, -1, -1, -1, 2, 0,
+	}
+	diffusivity := make([]float32, core.MaterialCount())
+	diffusivity[core.MatGranite] = 1e6
+
+	temps := DiffuseTemperatureFast(voxels, neighbors, diffusivity, 0.5, 0)
+
+	// (1000 + 2000 + 1000)/3 is 1333.3, so the hot voxel moves half way
+	if want := float32(2000 + (4000.0/3-2000)*0.5); math.Abs(float64(temps[1]-want)) > 0.01 {
+		t.Errorf("hot voxel at %.2f K, want %.2f K", temps[1], want)
+	}
+	if temps[0] <= 1000 || temps[2] <= 1000 {
+		t.Errorf("neighbors at %.1f K and %.1f K, want both warmed above 1000 K", temps[0], temps[2])
+	}
+	if math.Abs(float64(temps[0]-temps[2])) > 0.01 {
+		t.Errorf("air counted as a neighbor: %.2f K beside air, %.2f K without", temps[0], temps[2])
+	}
+	if temps[3] != 250 {
+		t.Errorf("air moved to %.1f K, want 250 K", temps[3])
+	}
+}
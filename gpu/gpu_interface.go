@@ -1,9 +1,39 @@
 package gpu
 
+import "worldgenerator/core"
+
 // GPUCompute interface for different GPU backends
 type GPUCompute interface {
 	RunTemperatureKernel(dt float32) error
 	RunConvectionKernel(dt float32) error
 	RunAdvectionKernel(dt float32) error
 	Cleanup()
-}
\ No newline at end of file
+}
+
+// TemperatureStepper is implemented by backends that can run heat diffusion
+// on whichever planet buffer the physics engine is stepping, with the rest
+// of the step left to the CPU
+type TemperatureStepper interface {
+	StepTemperature(planet *core.VoxelPlanet, dt float32) error
+}
+
+// RadiogenicHeater is implemented by backends whose temperature kernel can
+// run on voxels already on the device, which then needs telling how fast the
+// planet's interior is heating, see core.VoxelPlanet.RadiogenicHeat
+type RadiogenicHeater interface {
+	SetRadiogenicHeat(kelvinPerYear float32)
+}
+
+// ConvectionStepper is implemented by backends that can set the radial
+// convection velocities of whichever planet buffer the physics engine is
+// stepping, matching the CPU's UpdateConvection
+type ConvectionStepper interface {
+	StepConvection(planet *core.VoxelPlanet, dt float32) error
+}
+
+// AdvectionStepper is implemented by backends that can run the advection
+// kernel, see AdvectVoxels, on whichever planet buffer the physics engine is
+// stepping
+type AdvectionStepper interface {
+	StepAdvection(planet *core.VoxelPlanet, dt float32) error
+}
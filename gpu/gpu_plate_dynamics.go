@@ -0,0 +1,310 @@
+package gpu
+
+import (
+	"math"
+
+	"worldgenerator/core"
+	"worldgenerator/simulation"
+)
+
+// Plate dynamics constants, shared with the plate shaders through
+// PlateShaderDefines
+const (
+	plateMantleViscosity = 1e21 // Pa·s, scales basal drag
+	plateMotionBlend     = 0.1  // Fraction of the way voxel velocities move to the plate's per step
+
+	// plateLithosphereDepth is how many shells below the surface shell
+	// RunPlateDynamics counts as the plates' lithosphere
+	plateLithosphereDepth = 2
+)
+
+// PlateShellGPU matches the Shell struct of the plate shaders
+type PlateShellGPU struct {
+	InnerRadius    float32
+	OuterRadius    float32
+	LatBands       int32
+	VoxelOffset    int32 // Flat index of the shell's first voxel
+	LonCountOffset int32 // Index of the shell's first band in the longitude counts
+	_              [3]float32
+}
+
+// PlateShells returns the shell table and the flat longitude count of every
+// band the plate shaders locate voxels with
+func PlateShells(planet *core.VoxelPlanet) ([]PlateShellGPU, []int32) {
+	shells := make([]PlateShellGPU, len(planet.Shells))
+	var lonCounts []int32
+	offset := 0
+	for s, shell := range planet.Shells {
+		shells[s] = PlateShellGPU{
+			InnerRadius:    float32(shell.InnerRadius),
+			OuterRadius:    float32(shell.OuterRadius),
+			LatBands:       int32(len(shell.Voxels)),
+			VoxelOffset:    int32(offset),
+			LonCountOffset: int32(len(lonCounts)),
+		}
+		for _, band := range shell.Voxels {
+			lonCounts = append(lonCounts, int32(len(band)))
+			offset += len(band)
+		}
+	}
+	return shells, lonCounts
+}
+
+// PlatesGPU converts the plate manager's plates to the plate shaders' layout,
+// with each Euler pole as a unit axis and its angular velocity
+func PlatesGPU(plateManager *simulation.PlateManager) []PlateDataGPU {
+	plates := make([]PlateDataGPU, len(plateManager.Plates))
+	for i, plate := range plateManager.Plates {
+		axis := latLonPosition(plate.EulerPoleLat*math.Pi/180, plate.EulerPoleLon*math.Pi/180, 1)
+		plates[i].EulerPole = [4]float32{axis[0], axis[1], axis[2], float32(plate.AngularVelocity)}
+
+		plates[i].Properties[0] = float32(plate.TotalMass)
+		plates[i].Properties[1] = float32(plate.TotalArea)
+		plates[i].Properties[2] = float32(plate.AverageThickness)
+		switch plate.Type {
+		case "oceanic":
+			plates[i].Properties[3] = 0
+		case "continental":
+			plates[i].Properties[3] = 1
+		default:
+			plates[i].Properties[3] = 2
+		}
+
+		plates[i].MemberCount = int32(len(plate.MemberVoxels))
+		plates[i].BoundaryCount = int32(len(plate.BoundaryVoxels))
+		plates[i].AvgAge = float32(plate.AverageAge)
+	}
+	return plates
+}
+
+// vec3 is a Cartesian vector in the frame of simulation's Euler poles:
+// Z toward the north pole, X toward 0° longitude
+type vec3 [3]float32
+
+func (a vec3) add(b vec3) vec3      { return vec3{a[0] + b[0], a[1] + b[1], a[2] + b[2]} }
+func (a vec3) sub(b vec3) vec3      { return vec3{a[0] - b[0], a[1] - b[1], a[2] - b[2]} }
+func (a vec3) scale(s float32) vec3 { return vec3{a[0] * s, a[1] * s, a[2] * s} }
+func (a vec3) dot(b vec3) float32   { return a[0]*b[0] + a[1]*b[1] + a[2]*b[2] }
+func (a vec3) length() float32      { return float32(math.Sqrt(float64(a.dot(a)))) }
+func (a vec3) cross(b vec3) vec3 {
+	return vec3{a[1]*b[2] - a[2]*b[1], a[2]*b[0] - a[0]*b[2], a[0]*b[1] - a[1]*b[0]}
+}
+func (a vec3) normalize() vec3 {
+	if l := a.length(); l > 0 {
+		return a.scale(1 / l)
+	}
+	return a
+}
+
+// latLonPosition returns the point at a latitude and longitude in radians
+func latLonPosition(lat, lon, radius float64) vec3 {
+	return vec3{
+		float32(radius * math.Cos(lat) * math.Cos(lon)),
+		float32(radius * math.Cos(lat) * math.Sin(lon)),
+		float32(radius * math.Sin(lat)),
+	}
+}
+
+// sphericalPosition mirrors getSphericalPosition in the plate shaders: the
+// center of a voxel at the middle of its shell, placed as core.VoxelLatLon
+// places it
+func sphericalPosition(shell PlateShellGPU, lonCount int32, lat, lon int) vec3 {
+	latitude := (float64(lat)+0.5)/float64(shell.LatBands)*180 - 90
+	longitude := (float64(lon)+0.5)/float64(lonCount)*360 - 180
+	radius := (float64(shell.InnerRadius) + float64(shell.OuterRadius)) / 2
+	return latLonPosition(latitude*math.Pi/180, longitude*math.Pi/180, radius)
+}
+
+// localBasis returns the unit up, east and north vectors at pos
+func localBasis(pos vec3) (up, east, north vec3) {
+	up = pos.normalize()
+	east = vec3{-pos[1], pos[0], 0}.normalize()
+	north = up.cross(east)
+	return up, east, north
+}
+
+// plateVoxel is one lithosphere voxel of the plate shaders' scan
+type plateVoxel struct {
+	idx, shell, lat, lon int
+	pos                  vec3
+}
+
+// plateVoxels lists the voxels the plate dynamics shader scans, from the
+// surface shell down lithosphereDepth shells
+func plateVoxels(shells []PlateShellGPU, lonCounts []int32, surfaceShell, lithosphereDepth int) []plateVoxel {
+	var scanned []plateVoxel
+	for s := surfaceShell; s >= max(surfaceShell-lithosphereDepth, 0); s-- {
+		shell := shells[s]
+		idx := int(shell.VoxelOffset)
+		for lat := 0; lat < int(shell.LatBands); lat++ {
+			lonCount := lonCounts[int(shell.LonCountOffset)+lat]
+			for lon := 0; lon < int(lonCount); lon++ {
+				scanned = append(scanned, plateVoxel{idx, s, lat, lon, sphericalPosition(shell, lonCount, lat, lon)})
+				idx++
+			}
+		}
+	}
+	return scanned
+}
+
+// mantleCoupling mirrors calculateMantleCoupling: the drag velocity the
+// mantle under a lithosphere voxel exerts, from the three shells below it,
+// stronger the hotter the voxel. It is east, north and radial
+func mantleCoupling(voxels []GPUVoxelMaterial, shells []PlateShellGPU, lonCounts []int32, v plateVoxel) vec3 {
+	var mantleVel vec3
+	var totalWeight float32
+	for depth := 1; depth <= 3; depth++ {
+		idx := plateVoxelIndex(shells, lonCounts, v.shell-depth, v.lat, v.lon)
+		if idx < 0 {
+			continue
+		}
+		weight := 1 / float32(depth)
+		mantleVel = mantleVel.add(vec3{voxels[idx].VelEast, voxels[idx].VelNorth, voxels[idx].VelR}.scale(weight))
+		totalWeight += weight
+	}
+	if totalWeight == 0 {
+		return vec3{}
+	}
+	tempFactor := min(max((voxels[v.idx].Temperature-1000)/2000, 0), 1)
+	return mantleVel.scale(1 / totalWeight * tempFactor * 0.5)
+}
+
+// plateVoxelIndex mirrors getVoxelIndex, -1 outside the grid
+func plateVoxelIndex(shells []PlateShellGPU, lonCounts []int32, shell, lat, lon int) int {
+	if shell < 0 || shell >= len(shells) || lat < 0 || lat >= int(shells[shell].LatBands) {
+		return -1
+	}
+	bands := lonCounts[shells[shell].LonCountOffset:]
+	if lon < 0 || lon >= int(bands[lat]) {
+		return -1
+	}
+	idx := int(shells[shell].VoxelOffset)
+	for _, count := range bands[:lat] {
+		idx += int(count)
+	}
+	return idx + lon
+}
+
+// PlateDynamics is the CPU reference for plateDynamicsShader, returning the
+// plates after dt years of the forces on them. Every lithosphere voxel of a
+// plate sits at its true position on the sphere: ridge push drives the plate
+// away from its ridges, slab pull toward its trenches, and basal drag
+// against its motion over the mantle. Their torque about the planet's center
+// spins up the plate's Euler vector, until its surface moves maxVelocity
+// m/year
+func PlateDynamics(voxels []GPUVoxelMaterial, shells []PlateShellGPU, lonCounts []int32, plates []PlateDataGPU,
+	surfaceShell, lithosphereDepth int, planetRadius, maxVelocity, dt float32) []PlateDataGPU {
+	scanned := plateVoxels(shells, lonCounts, surfaceShell, lithosphereDepth)
+	out := make([]PlateDataGPU, len(plates))
+	copy(out, plates)
+
+	for plateID := range out {
+		plate := &out[plateID]
+		plate.RidgePush, plate.SlabPull, plate.BasalDrag = [4]float32{}, [4]float32{}, [4]float32{}
+
+		// Mass, centroid and mean velocity
+		var totalMass float32
+		var centroid, velocity vec3
+		memberCount := 0
+		for _, v := range scanned {
+			voxel := voxels[v.idx]
+			if voxel.PlateID != int32(plateID) {
+				continue
+			}
+			memberCount++
+			mass := voxel.Density * planetRadius * planetRadius * 0.001 // Simplified
+			totalMass += mass
+			up, east, north := localBasis(v.pos)
+			centroid = centroid.add(v.pos.scale(mass))
+			velocity = velocity.add(east.scale(voxel.VelEast).add(north.scale(voxel.VelNorth)).add(up.scale(voxel.VelR)).scale(mass))
+		}
+		if totalMass <= 0 {
+			continue
+		}
+		centroid = centroid.scale(1 / totalMass)
+
+		// Forces and their torque
+		var ridgePush, slabPull, basalDrag, torque vec3
+		boundaryCount := 0
+		for _, v := range scanned {
+			voxel := voxels[v.idx]
+			if voxel.PlateID != int32(plateID) {
+				continue
+			}
+			up, east, north := localBasis(v.pos)
+
+			// Along the surface toward the plate's interior
+			toCentroid := centroid.sub(v.pos)
+			inward := toCentroid.sub(up.scale(toCentroid.dot(up))).normalize()
+
+			var force vec3
+			if voxel.IsBoundary > 0 {
+				boundaryCount++
+
+				// Ridges push the plate away from them
+				if voxel.Temperature > 1500 && voxel.VelR > 0 {
+					push := inward.scale(voxel.VelR * 1e12)
+					ridgePush = ridgePush.add(push)
+					force = force.add(push)
+				}
+
+				// Sinking slabs pull it toward the trench, dense eclogite
+				// slabs hardest
+				if (voxel.Type == uint32(core.MatBasalt) || voxel.Type == uint32(core.MatEclogite)) && voxel.VelR < 0 {
+					pull := inward.scale(voxel.VelR * voxel.Density * 1e13)
+					slabPull = slabPull.add(pull)
+					force = force.add(pull)
+				}
+			}
+
+			// Drag against the plate's motion over the mantle
+			mantle := mantleCoupling(voxels, shells, lonCounts, v)
+			relative := east.scale(voxel.VelEast - mantle[0]).add(north.scale(voxel.VelNorth - mantle[1]))
+			drag := relative.scale(-plateMantleViscosity * 0.001)
+			basalDrag = basalDrag.add(drag)
+			force = force.add(drag)
+
+			torque = torque.add(v.pos.cross(force))
+		}
+
+		plate.RidgePush = [4]float32{ridgePush[0] / totalMass, ridgePush[1] / totalMass, ridgePush[2] / totalMass, ridgePush.length()}
+		plate.SlabPull = [4]float32{slabPull[0] / totalMass, slabPull[1] / totalMass, slabPull[2] / totalMass, slabPull.length()}
+		plate.BasalDrag = [4]float32{basalDrag[0] / totalMass, basalDrag[1] / totalMass, basalDrag[2] / totalMass, basalDrag.length()}
+
+		// Spin up the Euler vector, for a thin spherical cap
+		momentOfInertia := totalMass * planetRadius * planetRadius * 0.4
+		euler := vec3{plate.EulerPole[0], plate.EulerPole[1], plate.EulerPole[2]}.scale(plate.EulerPole[3])
+		euler = euler.add(torque.scale(dt / momentOfInertia))
+		axis := euler.normalize()
+		plate.EulerPole = [4]float32{axis[0], axis[1], axis[2], min(euler.length(), maxVelocity/planetRadius)}
+
+		velocity = velocity.scale(1 / totalMass)
+		plate.Motion = [4]float32{velocity[0], velocity[1], velocity[2], 0}
+		plate.MemberCount = int32(memberCount)
+		plate.BoundaryCount = int32(boundaryCount)
+	}
+	return out
+}
+
+// PlateMotion is the CPU reference for applyPlateMotionShader, returning the
+// voxels after one step: each interior voxel of a plate moves part of the way
+// to the velocity of the plate's rotation about its Euler pole at the
+// voxel's position, and ages dt years
+func PlateMotion(voxels []GPUVoxelMaterial, shells []PlateShellGPU, lonCounts []int32, plates []PlateDataGPU, dt float32) []GPUVoxelMaterial {
+	out := make([]GPUVoxelMaterial, len(voxels))
+	copy(out, voxels)
+	for _, v := range plateVoxels(shells, lonCounts, len(shells)-1, len(shells)-1) {
+		voxel := &out[v.idx]
+		if voxel.PlateID < 0 || int(voxel.PlateID) >= len(plates) || voxel.IsBoundary > 0 {
+			continue
+		}
+		pole := plates[voxel.PlateID].EulerPole
+		velocity := vec3{pole[0], pole[1], pole[2]}.normalize().scale(pole[3]).cross(v.pos)
+		_, east, north := localBasis(v.pos)
+
+		voxel.VelNorth += (velocity.dot(north) - voxel.VelNorth) * plateMotionBlend
+		voxel.VelEast += (velocity.dot(east) - voxel.VelEast) * plateMotionBlend
+		voxel.Age += dt
+	}
+	return out
+}
@@ -0,0 +1,201 @@
+package gpu
+
+import (
+	"math"
+	"testing"
+	"unsafe"
+
+	"worldgenerator/core"
+	"worldgenerator/gpu/gltest"
+	"worldgenerator/simulation"
+
+	"github.com/go-gl/gl/v4.3-core/gl"
+)
+
+// twoPlates builds a small planet's voxels with two surface plates: plate 0
+// from 90°W to 0° with a sinking basalt slab along its eastern edge, and
+// plate 1 from 0° to 90°E at rest. Everything is cold, so the mantle doesn't
+// drag on them
+func twoPlates(t *testing.T) ([]GPUVoxelMaterial, []PlateShellGPU, []int32, int) {
+	t.Helper()
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 4, 24)
+	shells, lonCounts := PlateShells(planet)
+	voxels := flatVoxels(planet)
+	surface := len(shells) - 2
+
+	for i := range voxels {
+		voxels[i].PlateID = -1
+		voxels[i].Temperature = 300
+		voxels[i].Density = 3000
+		voxels[i].VelNorth, voxels[i].VelEast, voxels[i].VelR = 0, 0, 0
+	}
+	shell := shells[surface]
+	for lat := 0; lat < int(shell.LatBands); lat++ {
+		lonCount := int(lonCounts[int(shell.LonCountOffset)+lat])
+		for lon := 0; lon < lonCount; lon++ {
+			longitude := (float64(lon)+0.5)/float64(lonCount)*360 - 180
+			voxel := &voxels[plateVoxelIndex(shells, lonCounts, surface, lat, lon)]
+			switch {
+			case longitude >= -90 && longitude < 0:
+				voxel.PlateID = 0
+				if longitude+360/float64(lonCount) >= 0 {
+					voxel.IsBoundary = 1
+					voxel.Type = uint32(core.MatBasalt)
+					voxel.VelR = -0.01
+				}
+			case longitude >= 0 && longitude < 90:
+				voxel.PlateID = 1
+			}
+		}
+	}
+	return voxels, shells, lonCounts, surface
+}
+
+// TestPlateDynamicsSlabPull checks a slab sinking along a plate's edge turns
+// the plate toward its trench, within the speed limit, while a plate with no
+// forces on it stays put
+func TestPlateDynamicsSlabPull(t *testing.T) {
+	voxels, shells, lonCounts, surface := twoPlates(t)
+	const radius = 6371000.0
+
+	const maxVelocity = core.DefaultMaxPlateVelocity / 100 // m/year
+	plates := PlateDynamics(voxels, shells, lonCounts, make([]PlateDataGPU, 2), surface, 0, radius, maxVelocity, 1)
+
+	pulled := plates[0]
+	if pulled.BoundaryCount == 0 || pulled.SlabPull[3] <= 0 {
+		t.Fatalf("plate 0 has %d boundary voxels and slab pull %g, want both", pulled.BoundaryCount, pulled.SlabPull[3])
+	}
+	w := pulled.EulerPole[3]
+	if w <= 0 || w > maxVelocity/radius*1.0001 {
+		t.Fatalf("plate 0 turns at %g rad/year, want up to %g", w, maxVelocity/radius)
+	}
+
+	// The middle of the plate, 45°W on the equator, heads east for the trench
+	middle := latLonPosition(0, -math.Pi/4, radius)
+	velocity := vec3{pulled.EulerPole[0], pulled.EulerPole[1], pulled.EulerPole[2]}.scale(w).cross(middle)
+	_, east, _ := localBasis(middle)
+	if velocity.dot(east) <= 0.9*velocity.length() {
+		t.Errorf("plate 0 moves %v at its middle, want eastward toward its trench", velocity)
+	}
+
+	if still := plates[1]; still.EulerPole[3] != 0 || still.MemberCount == 0 {
+		t.Errorf("plate 1 of %d voxels turns at %g rad/year, want 0", still.MemberCount, still.EulerPole[3])
+	}
+
+	// A planet with a lower speed limit holds the plate to it
+	slow := PlateDynamics(voxels, shells, lonCounts, make([]PlateDataGPU, 2), surface, 0, radius, maxVelocity/4, 1)[0].EulerPole[3]
+	if want := min(w, maxVelocity/4/radius); math.Abs(float64(slow-want)) > 1e-4*float64(want) {
+		t.Errorf("plate 0 turns at %g rad/year under a quarter of the speed limit, want %g", slow, want)
+	}
+}
+
+// TestPlateDynamicsEclogitePull checks a slab that has turned to eclogite
+// pulls its plate harder than the same slab of basalt
+func TestPlateDynamicsEclogitePull(t *testing.T) {
+	voxels, shells, lonCounts, surface := twoPlates(t)
+	basaltPull := PlateDynamics(voxels, shells, lonCounts, make([]PlateDataGPU, 2), surface, 0, 6371000.0, core.DefaultMaxPlateVelocity/100, 1)[0].SlabPull[3]
+
+	eclogite := core.MaterialProperties[core.MatEclogite].DefaultDensity
+	for i := range voxels {
+		if voxels[i].Type == uint32(core.MatBasalt) && voxels[i].VelR < 0 {
+			voxels[i].Type = uint32(core.MatEclogite)
+			voxels[i].Density = eclogite
+		}
+	}
+	eclogitePull := PlateDynamics(voxels, shells, lonCounts, make([]PlateDataGPU, 2), surface, 0, 6371000.0, core.DefaultMaxPlateVelocity/100, 1)[0].SlabPull[3]
+
+	if eclogitePull <= basaltPull {
+		t.Errorf("eclogite slab pulls %g, no more than the basalt slab's %g", eclogitePull, basaltPull)
+	}
+}
+
+// TestPlateMotionFollowsEulerPole checks interior voxels move part of the way
+// to the plate's rotation at their own position: eastward, faster nearer the
+// equator, for a pole at the north pole
+func TestPlateMotionFollowsEulerPole(t *testing.T) {
+	voxels, shells, lonCounts, surface := twoPlates(t)
+	const w = 1e-8
+	plates := []PlateDataGPU{{EulerPole: [4]float32{0, 0, 1, w}}, {}}
+
+	moved := PlateMotion(voxels, shells, lonCounts, plates, 100)
+
+	shell := shells[surface]
+	radius := (float64(shell.InnerRadius) + float64(shell.OuterRadius)) / 2
+	checked := 0
+	for lat := 0; lat < int(shell.LatBands); lat++ {
+		lonCount := int(lonCounts[int(shell.LonCountOffset)+lat])
+		latitude := ((float64(lat)+0.5)/float64(shell.LatBands)*180 - 90) * math.Pi / 180
+		for lon := 0; lon < lonCount; lon++ {
+			idx := plateVoxelIndex(shells, lonCounts, surface, lat, lon)
+			voxel := moved[idx]
+			if voxel.PlateID != 0 || voxel.IsBoundary > 0 {
+				continue
+			}
+			want := plateMotionBlend * w * radius * math.Cos(latitude)
+			if math.Abs(float64(voxel.VelEast)-want) > 1e-3*want || math.Abs(float64(voxel.VelNorth)) > 1e-3*want {
+				t.Fatalf("voxel at band %d moves %g east, %g north, want %g east", lat, voxel.VelEast, voxel.VelNorth, want)
+			}
+			if voxel.Age != voxels[idx].Age+100 {
+				t.Fatalf("voxel aged from %g to %g years, want 100 more", voxels[idx].Age, voxel.Age)
+			}
+			checked++
+		}
+	}
+	if checked == 0 {
+		t.Fatal("no interior voxels of plate 0")
+	}
+}
+
+// TestComputePlatesMatchCPU runs the plate dynamics and motion shaders on the
+// two plates and compares them against the CPU references. It needs an
+// OpenGL 4.3 context and skips without a display
+func TestComputePlatesMatchCPU(t *testing.T) {
+	defer gltest.OpenComputeContext(t)()
+
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 4, 24)
+	planet.MaxPlateVelocity = 5
+	voxels, shells, lonCounts, surface := twoPlates(t)
+	const radius, dt = 6371000.0, 100
+
+	pt, err := NewComputePlateTectonics(planet, &simulation.PlateManager{})
+	if err != nil {
+		t.Fatalf("NewComputePlateTectonics: %v", err)
+	}
+	defer pt.Release()
+	if pt.MaxPlateVelocity != 0.05 {
+		t.Fatalf("plates capped at %g m/year, want the planet's 5 cm/year", pt.MaxPlateVelocity)
+	}
+	pt.SetPlates(make([]PlateDataGPU, 2))
+
+	gl.GenBuffers(1, &pt.voxelSSBO)
+	defer gl.DeleteBuffers(1, &pt.voxelSSBO)
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, pt.voxelSSBO)
+	gl.BufferData(gl.SHADER_STORAGE_BUFFER, len(voxels)*GPUVoxelSize, gl.Ptr(voxels), gl.DYNAMIC_DRAW)
+
+	wantPlates := PlateDynamics(voxels, shells, lonCounts, make([]PlateDataGPU, 2), surface, plateLithosphereDepth, radius, pt.MaxPlateVelocity, dt)
+	wantVoxels := PlateMotion(voxels, shells, lonCounts, wantPlates, dt)
+	pt.RunPlateDynamics(dt, radius, int32(surface))
+	pt.ApplyPlateMotion(dt)
+
+	for i, plate := range pt.ReadPlates() {
+		want := wantPlates[i].EulerPole
+		for k := range plate.EulerPole {
+			tolerance := 1e-3 // Axis components
+			if k == 3 {
+				tolerance *= math.Abs(float64(want[3]))
+			}
+			if math.Abs(float64(plate.EulerPole[k]-want[k])) > tolerance {
+				t.Fatalf("plate %d Euler pole %v on the GPU, %v on the CPU", i, plate.EulerPole, want)
+			}
+		}
+	}
+
+	got := make([]GPUVoxelMaterial, len(voxels))
+	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, pt.voxelSSBO)
+	gl.GetBufferSubData(gl.SHADER_STORAGE_BUFFER, 0, len(got)*GPUVoxelSize, unsafe.Pointer(&got[0]))
+	for i := range got {
+		if math.Abs(float64(got[i].VelEast-wantVoxels[i].VelEast)) > 1e-3*math.Max(1e-3, math.Abs(float64(wantVoxels[i].VelEast))) {
+			t.Fatalf("voxel %d moves %g east on the GPU, %g on the CPU", i, got[i].VelEast, wantVoxels[i].VelEast)
+		}
+	}
+}
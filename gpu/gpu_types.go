@@ -5,6 +5,7 @@ import (
 )
 
 // GPUVoxelMaterial is a GPU-compatible version of VoxelMaterial
+// Its layout is shared with every backend's shaders, see GPUVoxelFields
 type GPUVoxelMaterial struct {
 	Type        uint32
 	Density     float32
@@ -14,13 +15,27 @@ type GPUVoxelMaterial struct {
 	VelEast     float32
 	VelR        float32
 	Age         float32
-	PlateID     int32    // Which plate this voxel belongs to
-	IsBoundary  int32    // 1 if on plate boundary, 0 otherwise
-	_padding    [2]int32 // Ensure 16-byte alignment
+	PlateID     int32   // Which plate this voxel belongs to
+	IsBoundary  int32   // 1 if on plate boundary, 0 otherwise
+	Composition float32 // Fills the 16-byte alignment padding
+	Flags       uint32  // GPUVoxelBrittle | GPUVoxelFractured
 }
 
+// Bits of GPUVoxelMaterial.Flags
+const (
+	GPUVoxelBrittle   = 1 << 0
+	GPUVoxelFractured = 1 << 1
+)
+
 // ConvertToGPUVoxel converts a VoxelMaterial to GPU format
 func ConvertToGPUVoxel(v *core.VoxelMaterial) GPUVoxelMaterial {
+	var flags uint32
+	if v.IsBrittle {
+		flags |= GPUVoxelBrittle
+	}
+	if v.IsFractured {
+		flags |= GPUVoxelFractured
+	}
 	return GPUVoxelMaterial{
 		Type:        uint32(v.Type),
 		Density:     v.Density,
@@ -30,5 +45,7 @@ func ConvertToGPUVoxel(v *core.VoxelMaterial) GPUVoxelMaterial {
 		VelEast:     v.VelEast,
 		VelR:        v.VelR,
 		Age:         v.Age,
+		Composition: v.Composition,
+		Flags:       flags,
 	}
 }
@@ -0,0 +1,50 @@
+package gpu
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+// The build fails if GPUVoxelMaterial's size or first and last fields drift
+// from the shared layout
+const (
+	_ = uint(GPUVoxelSize - unsafe.Sizeof(GPUVoxelMaterial{}))
+	_ = uint(unsafe.Sizeof(GPUVoxelMaterial{}) - GPUVoxelSize)
+	_ = uint(unsafe.Offsetof(GPUVoxelMaterial{}.Type) - 0)
+	_ = uint(44 - unsafe.Offsetof(GPUVoxelMaterial{}.Flags))
+	_ = uint(unsafe.Offsetof(GPUVoxelMaterial{}.Flags) - 44)
+)
+
+// TestGPUVoxelLayout checks GPUVoxelMaterial matches GPUVoxelFields field by
+// field, so buffers filled on the Go side read correctly in every shader
+func TestGPUVoxelLayout(t *testing.T) {
+	goType := reflect.TypeOf(GPUVoxelMaterial{})
+	if goType.NumField() != len(GPUVoxelFields) {
+		t.Fatalf("GPUVoxelMaterial has %d fields, shared layout has %d", goType.NumField(), len(GPUVoxelFields))
+	}
+
+	var end uintptr
+	for i, want := range GPUVoxelFields {
+		got := goType.Field(i)
+		if got.Offset != want.Offset {
+			t.Errorf("%s at offset %d, want %s at %d", got.Name, got.Offset, want.Name, want.Offset)
+		}
+		if got.Offset != end {
+			t.Errorf("%s at offset %d leaves a gap after %d", got.Name, got.Offset, end)
+		}
+		if got.Type.Size() != 4 {
+			t.Errorf("%s is %d bytes, shader types are 4", got.Name, got.Type.Size())
+		}
+		end = got.Offset + got.Type.Size()
+	}
+	if end != GPUVoxelSize {
+		t.Errorf("fields end at %d bytes, want %d", end, GPUVoxelSize)
+	}
+
+	decls := GPUVoxelStructFields("")
+	if !strings.HasPrefix(decls, "uint32_t matType;\n") || strings.Count(decls, ";\n") != len(GPUVoxelFields) {
+		t.Errorf("unexpected struct declarations:\n%s", decls)
+	}
+}
@@ -0,0 +1,45 @@
+package gpu
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GPUVoxelField is one field of the voxel layout shared by the GPU backends
+type GPUVoxelField struct {
+	Name   string  // As C, Metal and GLSL sources spell it
+	CType  string  // C/Metal type, always 4 bytes
+	Offset uintptr // Bytes from the start of the voxel
+}
+
+// GPUVoxelSize is the size in bytes of one voxel on every GPU backend
+const GPUVoxelSize = 48
+
+// GPUVoxelFields is the voxel layout shared by the OpenGL buffers
+// (GPUVoxelMaterial) and the Metal kernels, in memory order. Shader sources
+// declare their voxel struct from it with GPUVoxelStructFields; the Metal cgo
+// header writes the same fields out by hand and is checked against it
+var GPUVoxelFields = []GPUVoxelField{
+	{"matType", "uint32_t", 0},
+	{"density", "float", 4},
+	{"temperature", "float", 8},
+	{"pressure", "float", 12},
+	{"velNorth", "float", 16},
+	{"velEast", "float", 20},
+	{"velR", "float", 24},
+	{"age", "float", 28},
+	{"plateID", "int32_t", 32},
+	{"isBoundary", "int32_t", 36},
+	{"composition", "float", 40},
+	{"flags", "uint32_t", 44},
+}
+
+// GPUVoxelStructFields returns the layout as C-family field declarations,
+// one per line with the given indent, for the body of a shader struct
+func GPUVoxelStructFields(indent string) string {
+	var b strings.Builder
+	for _, f := range GPUVoxelFields {
+		fmt.Fprintf(&b, "%s%s %s;\n", indent, f.CType, f.Name)
+	}
+	return b.String()
+}
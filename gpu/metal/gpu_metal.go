@@ -21,19 +21,7 @@ typedef struct {
 } MetalContext;
 
 // Voxel data structure for GPU
-typedef struct {
-    uint8_t core.MaterialType;
-    float density;
-    float temperature;
-    float pressure;
-    float velR, VelNorth, VelEast;
-    float age;
-    float stress;
-    float composition;
-    float yieldStrength;
-    uint8_t flags; // brittle, fractured, etc
-    uint8_t padding[2];
-} GPUVoxel;
+#include "gpu_voxel.h"
 
 // Shell metadata
 typedef struct {
@@ -51,14 +39,15 @@ void* createBuffer(MetalContext* ctx, size_t size, const void* data);
 void releaseBuffer(void* buffer);
 void* getBufferContents(void* buffer);
 int runTemperatureKernel(MetalContext* ctx, void* voxelBuffer, void* shellBuffer,
-                        int voxelCount, float dt, float thermalDiffusivity);
-int runConvectionKernel(MetalContext* ctx, void* voxelBuffer, void* shellBuffer,
-                       int voxelCount, float dt);
+                        int voxelCount, float dt, const float* materialDiffusivity, int materialCount,
+                        float radiogenicHeat);
+int runConvectionKernel(MetalContext* ctx, void* voxelBuffer, void* neighborBuffer, void* lengthBuffer,
+                       int voxelCount, float dt, const float* materialViscosity, int materialCount);
 int runAdvectionKernel(MetalContext* ctx, void* voxelBuffer, void* newVoxelBuffer,
                       void* shellBuffer, int voxelCount, float dt);
-int computeNeighborIndices(MetalContext* ctx, void* neighborBuffer, void* shellBuffer, int voxelCount);
 int runTemperatureFastKernel(MetalContext* ctx, void* voxelBuffer, void* neighborBuffer,
-                            int voxelCount, float dt, float thermalDiffusivity);
+                            int voxelCount, float dt, const float* materialDiffusivity, int materialCount,
+                            float radiogenicHeat);
 */
 import "C"
 
@@ -66,6 +55,7 @@ import (
 	"fmt"
 	"unsafe"
 	"worldgenerator/core"
+	"worldgenerator/gpu"
 )
 
 // MetalCompute handles GPU acceleration for voxel physics
@@ -75,10 +65,12 @@ type MetalCompute struct {
 	shellBuffer    unsafe.Pointer // GPU buffer for shell metadata
 	tempBuffer     unsafe.Pointer // Temporary buffer for advection
 	neighborBuffer unsafe.Pointer // Precomputed neighbor indices
+	lengthBuffer   unsafe.Pointer // Convection length scale of every voxel
 	totalVoxels    int
 	shellCount     int
 	initialized    bool
 	neighborsReady bool
+	radiogenicHeat float32 // Deep heating in K per year, from the planet stepped last
 }
 
 // NewMetalCompute creates a new Metal compute context
@@ -89,9 +81,10 @@ func NewMetalCompute(planet *core.VoxelPlanet) (*MetalCompute, error) {
 	}
 
 	mc := &MetalCompute{
-		ctx:         ctx,
-		initialized: false,
-		shellCount:  len(planet.Shells),
+		ctx:            ctx,
+		initialized:    false,
+		shellCount:     len(planet.Shells),
+		radiogenicHeat: float32(planet.RadiogenicHeat()),
 	}
 
 	// Compile shaders
@@ -159,19 +152,26 @@ func (mc *MetalCompute) InitializeForPlanet(planet *core.VoxelPlanet) error {
 		return fmt.Errorf("failed to allocate shell buffer")
 	}
 
-	// Allocate neighbor indices buffer (6 neighbors per voxel)
-	mc.neighborBuffer = C.createBuffer(mc.ctx, C.size_t(totalVoxels*6)*C.size_t(4), nil) // 4 bytes per int
+	// Neighbor indices (6 per voxel) and convection length scales are built
+	// on the CPU, which knows each band's longitude count
+	neighbors := gpu.NeighborIndices(planet)
+	if len(neighbors) == 0 {
+		return fmt.Errorf("planet has no voxels")
+	}
+	mc.neighborBuffer = C.createBuffer(mc.ctx, C.size_t(len(neighbors))*C.size_t(4), unsafe.Pointer(&neighbors[0])) // 4 bytes per int
 	if mc.neighborBuffer == nil {
 		return fmt.Errorf("failed to allocate neighbor buffer")
 	}
+	mc.neighborsReady = true
 
-	// Copy initial data to GPU
-	if err := mc.uploadPlanetData(planet); err != nil {
-		return err
+	lengths := gpu.ConvectionLengthScales(planet)
+	mc.lengthBuffer = C.createBuffer(mc.ctx, C.size_t(len(lengths))*C.size_t(4), unsafe.Pointer(&lengths[0]))
+	if mc.lengthBuffer == nil {
+		return fmt.Errorf("failed to allocate length scale buffer")
 	}
 
-	// Compute neighbor indices
-	if err := mc.computeNeighborIndices(); err != nil {
+	// Copy initial data to GPU
+	if err := mc.uploadPlanetData(planet); err != nil {
 		return err
 	}
 
@@ -185,6 +185,10 @@ func (mc *MetalCompute) UpdateTemperature(dt float64) error {
 		return fmt.Errorf("Metal compute not initialized")
 	}
 
+	// Per-material thermal diffusivity, indexed by material type
+	diffusivity := gpu.MaterialDiffusivities()
+	diffusivityPtr := (*C.float)(unsafe.Pointer(&diffusivity[0]))
+
 	// Use fast kernel if neighbors are precomputed
 	if mc.neighborsReady {
 		result := C.runTemperatureFastKernel(
@@ -193,7 +197,9 @@ func (mc *MetalCompute) UpdateTemperature(dt float64) error {
 			mc.neighborBuffer,
 			C.int(mc.totalVoxels),
 			C.float(dt),
-			C.float(1e-6), // thermal diffusivity
+			diffusivityPtr,
+			C.int(len(diffusivity)),
+			C.float(mc.radiogenicHeat),
 		)
 
 		if result != 0 {
@@ -207,7 +213,9 @@ func (mc *MetalCompute) UpdateTemperature(dt float64) error {
 			mc.shellBuffer,
 			C.int(mc.totalVoxels),
 			C.float(dt),
-			C.float(1e-6), // thermal diffusivity
+			diffusivityPtr,
+			C.int(len(diffusivity)),
+			C.float(mc.radiogenicHeat),
 		)
 
 		if result != 0 {
@@ -218,19 +226,52 @@ func (mc *MetalCompute) UpdateTemperature(dt float64) error {
 	return nil
 }
 
+// StepTemperature diffuses heat through planet for dt years with the Metal
+// kernel and writes the new temperatures back
+func (mc *MetalCompute) StepTemperature(planet *core.VoxelPlanet, dt float32) error {
+	if !mc.initialized {
+		return fmt.Errorf("Metal compute not initialized")
+	}
+	if err := mc.uploadPlanetData(planet); err != nil {
+		return err
+	}
+	mc.SetRadiogenicHeat(float32(planet.RadiogenicHeat()))
+	if err := mc.UpdateTemperature(float64(dt)); err != nil {
+		return err
+	}
+
+	voxelData := (*[1 << 30]C.GPUVoxel)(C.getBufferContents(mc.voxelBuffer))[:mc.totalVoxels:mc.totalVoxels]
+	voxelIndex := 0
+	for _, shell := range planet.Shells {
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				shell.Voxels[latIdx][lonIdx].Temperature = float32(voxelData[voxelIndex].temperature)
+				voxelIndex++
+			}
+		}
+	}
+	return nil
+}
+
 // UpdateConvection calculates convection velocities on GPU
 func (mc *MetalCompute) UpdateConvection(dt float64) error {
 	if !mc.initialized {
 		return fmt.Errorf("Metal compute not initialized")
 	}
 
+	// Per-material viscosity relative to the mantle law
+	viscosity := gpu.MaterialViscosityScales()
+
 	// Run convection kernel
 	result := C.runConvectionKernel(
 		mc.ctx,
 		mc.voxelBuffer,
-		mc.shellBuffer,
+		mc.neighborBuffer,
+		mc.lengthBuffer,
 		C.int(mc.totalVoxels),
 		C.float(dt),
+		(*C.float)(unsafe.Pointer(&viscosity[0])),
+		C.int(len(viscosity)),
 	)
 
 	if result != 0 {
@@ -240,6 +281,32 @@ func (mc *MetalCompute) UpdateConvection(dt float64) error {
 	return nil
 }
 
+// StepConvection sets the radial convection velocities of planet's voxels
+// for a step of dt years with the Metal kernel
+func (mc *MetalCompute) StepConvection(planet *core.VoxelPlanet, dt float32) error {
+	if !mc.initialized {
+		return fmt.Errorf("Metal compute not initialized")
+	}
+	if err := mc.uploadPlanetData(planet); err != nil {
+		return err
+	}
+	if err := mc.UpdateConvection(float64(dt)); err != nil {
+		return err
+	}
+
+	voxelData := (*[1 << 30]C.GPUVoxel)(C.getBufferContents(mc.voxelBuffer))[:mc.totalVoxels:mc.totalVoxels]
+	voxelIndex := 0
+	for _, shell := range planet.Shells {
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				shell.Voxels[latIdx][lonIdx].VelR = float32(voxelData[voxelIndex].velR)
+				voxelIndex++
+			}
+		}
+	}
+	return nil
+}
+
 // Release cleans up Metal resources
 func (mc *MetalCompute) Release() {
 	if mc.voxelBuffer != nil {
@@ -258,6 +325,10 @@ func (mc *MetalCompute) Release() {
 		C.releaseBuffer(mc.neighborBuffer)
 		mc.neighborBuffer = nil
 	}
+	if mc.lengthBuffer != nil {
+		C.releaseBuffer(mc.lengthBuffer)
+		mc.lengthBuffer = nil
+	}
 	if mc.ctx != nil {
 		C.releaseMetalContext(mc.ctx)
 		mc.ctx = nil
@@ -265,25 +336,12 @@ func (mc *MetalCompute) Release() {
 }
 
 // Metal shader source code
-const metalShaderSource = `
+var metalShaderSource = `
 #include <metal_stdlib>
 using namespace metal;
 
 struct Voxel {
-    uint8_t core.MaterialType;
-    float density;
-    float temperature;
-    float pressure;
-    float velR;
-    float VelNorth;
-    float VelEast;
-    float age;
-    float stress;
-    float composition;
-    float yieldStrength;
-    uint8_t flags;
-    uint8_t padding[2];
-};
+` + gpu.GPUVoxelStructFields("    ") + `};
 
 struct Shell {
     float innerRadius;
@@ -298,7 +356,8 @@ kernel void updateTemperature(
     device Voxel* voxels [[buffer(0)]],
     device const Shell* shells [[buffer(1)]],
     constant float& dt [[buffer(2)]],
-    constant float& thermalDiffusivity [[buffer(3)]],
+    constant float* materialDiffusivity [[buffer(3)]],
+    constant float& radiogenicHeat [[buffer(4)]], // K per year in the deep interior
     uint3 gid [[thread_position_in_grid]],
     uint3 gridSize [[threads_per_grid]]
 ) {
@@ -308,9 +367,10 @@ kernel void updateTemperature(
     device Voxel& voxel = voxels[voxelIndex];
     
     // Skip air voxels
-    if (voxel.core.MaterialType == 0) { // MatAir
+    if (voxel.matType == 0) { // MatAir
         return;
     }
+    float thermalDiffusivity = materialDiffusivity[voxel.matType];
     
     // Find which shell this voxel belongs to
     int shellIdx = -1;
@@ -357,7 +417,7 @@ kernel void updateTemperature(
     
     // Add internal heating for deep shells
     if (shellIdx < 5) {
-        dTemp += 1e-9 * dt; // Radioactive heating
+        dTemp += radiogenicHeat * dt; // Radioactive heating
     }
     
     voxel.temperature += dTemp;
@@ -367,83 +427,33 @@ kernel void updateTemperature(
     if (voxel.temperature > 6000) voxel.temperature = 6000;
 }
 
-// Convection velocity calculation
+` + gpu.ConvectionShaderDefines() + gpu.ConvectedVelRSource + `
+// Convection velocity calculation against each voxel's outer neighbor, the
+// same step as the CPU's UpdateConvection
 kernel void updateConvection(
     device Voxel* voxels [[buffer(0)]],
-    device const Shell* shells [[buffer(1)]],
-    constant float& dt [[buffer(2)]],
-    uint3 gid [[thread_position_in_grid]],
-    uint3 gridSize [[threads_per_grid]]
+    device const int* neighborIndices [[buffer(1)]], // 6 neighbors per voxel: -r,+r,-lat,+lat,-lon,+lon
+    device const float* lengthScales [[buffer(2)]],
+    constant float& dt [[buffer(3)]],
+    constant float* materialViscosity [[buffer(4)]],
+    constant uint& materialCount [[buffer(5)]],
+    constant uint& voxelCount [[buffer(6)]],
+    uint3 gid [[thread_position_in_grid]]
 ) {
     uint voxelIndex = gid.x;
-    if (voxelIndex >= gridSize.x) return;
-    
+    if (voxelIndex >= voxelCount) return;
+
     device Voxel& voxel = voxels[voxelIndex];
-    
-    // Skip air and water
-    if (voxel.core.MaterialType == 0 || voxel.core.MaterialType == 2) { // MatAir or MatWater
-        return;
-    }
-    
-    // Find which shell this voxel belongs to
-    int shellIdx = -1;
-    for (int i = 0; i < 32; i++) {
-        if (shells[i].voxelOffset <= (int)voxelIndex && 
-            (i == 31 || shells[i+1].voxelOffset > (int)voxelIndex)) {
-            shellIdx = i;
-            break;
-        }
-    }
-    
-    if (shellIdx < 0 || shellIdx >= 31) return;
-    
-    // Get temperature gradient with outer neighbor
-    int localIdx = voxelIndex - shells[shellIdx].voxelOffset;
-    int outerIdx = shells[shellIdx+1].voxelOffset + localIdx;
-    
-    if (outerIdx >= (int)gridSize.x) return;
-    
-    float outerTemp = voxels[outerIdx].temperature;
-    float deltaT = voxel.temperature - outerTemp;
-    
-    // Buoyancy calculation
-    float alpha = 3e-5; // Thermal expansion coefficient
-    float g = 9.81;
-    float deltaDensity = voxel.density * alpha * deltaT;
-    float buoyancyForce = -deltaDensity * g;
-    
-    // Continental crust extra buoyancy
-    if (voxel.core.MaterialType == 6) { // MatGranite
-        float avgDensity = 2900.0;
-        if (voxels[outerIdx].core.MaterialType == 5 || voxels[outerIdx].core.MaterialType == 7) { // Basalt or Peridotite
-            avgDensity = 2900.0;
-        }
-        float compositionalBuoyancy = (avgDensity - voxel.density) * g / 100.0;
-        buoyancyForce += compositionalBuoyancy;
-    }
-    
-    // Simple velocity update (simplified viscosity)
-    float viscosity = 1e21; // Pa·s
-    float lengthScale = (shells[shellIdx].outerRadius - shells[shellIdx].innerRadius) / 10.0;
-    float velocity = buoyancyForce * lengthScale * lengthScale / (6.0 * 3.14159 * viscosity);
-    
-    // Apply Rayleigh number criterion
-    float thermalDiff = 1e-6;
-    float rayleigh = abs(deltaDensity * g * lengthScale * lengthScale * lengthScale / 
-        (thermalDiff * viscosity));
-    
-    if (rayleigh > 1000) {
-        voxel.velR = velocity * dt;
-        
-        // Add some lateral circulation
-        voxel.VelNorth = velocity * 0.1 * sin(float(localIdx) * 0.1) * dt;
-        voxel.VelEast = velocity * 0.1 * cos(float(localIdx) * 0.15) * dt;
-    } else {
-        // Decay velocities
-        voxel.velR *= 0.95;
-        voxel.VelNorth *= 0.95;
-        voxel.VelEast *= 0.95;
-    }
+
+    // Only the radial velocity is written, which no voxel reads from its
+    // neighbors, so the update can be made in place
+    int outerIdx = neighborIndices[voxelIndex * 6 + NEIGHBOR_OUTER];
+    if (outerIdx < 0 || uint(outerIdx) >= voxelCount || voxel.matType >= materialCount) return;
+    device const Voxel& outer = voxels[outerIdx];
+
+    voxel.velR = convectedVelR(voxel.matType, voxel.density, voxel.temperature, voxel.pressure, voxel.velR,
+        outer.matType, outer.density, outer.temperature,
+        materialViscosity[voxel.matType], lengthScales[voxelIndex], dt);
 }
 
 // Material advection kernel
@@ -465,7 +475,7 @@ kernel void advectMaterial(
     newVoxel = voxel;
     
     // Skip air voxels
-    if (voxel.core.MaterialType == 0) { // MatAir
+    if (voxel.matType == 0) { // MatAir
         return;
     }
     
@@ -504,7 +514,7 @@ kernel void advectMaterial(
         // For now, only shift if we've accumulated enough movement
         int cellsToShift = int(cellsToShiftFloat);
         
-        if (cellsToShift > 0 && voxel.core.MaterialType > 2) { // Skip air and water
+        if (cellsToShift > 0 && voxel.matType > 2) { // Skip air and water
             // Calculate source position
             int sourceLon = (lonIdx - cellsToShift + 1000 * shells[shellIdx].maxLonCount) % 
                            shells[shellIdx].maxLonCount;
@@ -513,22 +523,22 @@ kernel void advectMaterial(
             if (sourceIdx >= 0 && sourceIdx < (int)gridSize.x) {
                 // Copy material properties from source
                 device Voxel& sourceVoxel = voxels[sourceIdx];
-                if (sourceVoxel.core.MaterialType > 2) { // Don't copy air/water
-                    newVoxel.core.MaterialType = sourceVoxel.core.MaterialType;
+                if (sourceVoxel.matType > 2) { // Don't copy air/water
+                    newVoxel.matType = sourceVoxel.matType;
                     newVoxel.density = sourceVoxel.density;
                     newVoxel.composition = sourceVoxel.composition;
                     newVoxel.age = sourceVoxel.age + float(yearsPerSecond);
                 }
             }
-        } else if (voxel.core.MaterialType > 2) {
+        } else if (voxel.matType > 2) {
             // Still increment age even if not shifting
             newVoxel.age += float(yearsPerSecond);
         }
         
         // Update velocity to reflect actual movement
-        if (voxel.core.MaterialType > 2) {
-            // Velocity in m/s (10 cm/year at equator)
-            newVoxel.VelEast = 3e-9 * speedFactor;
+        if (voxel.matType > 2) {
+            // Velocity in m/year (10 cm/year at equator)
+            newVoxel.velEast = 0.1 * speedFactor;
         }
     }
     
@@ -546,7 +556,7 @@ kernel void advectMaterial(
                 outerVoxel.temperature += tempDiff * mixFactor;
                 
                 // Transfer magma composition
-                if (voxel.core.MaterialType == 4 && outerVoxel.core.MaterialType != 0) { // MatMagma
+                if (voxel.matType == 4 && outerVoxel.matType != 0) { // MatMagma
                     outerVoxel.composition = (outerVoxel.composition + voxel.composition * mixFactor) / 
                                            (1.0 + mixFactor);
                 }
@@ -555,80 +565,13 @@ kernel void advectMaterial(
     }
 }
 
-// Neighbor lookup kernel - precomputes neighbor indices for faster physics
-kernel void computeNeighborIndices(
-    device int* neighborIndices [[buffer(0)]], // 6 neighbors per voxel: -r,+r,-lat,+lat,-lon,+lon
-    device const Shell* shells [[buffer(1)]],
-    uint3 gid [[thread_position_in_grid]],
-    uint3 gridSize [[threads_per_grid]]
-) {
-    uint voxelIndex = gid.x;
-    if (voxelIndex >= gridSize.x) return;
-    
-    // Initialize all neighbors to -1 (no neighbor)
-    for (int i = 0; i < 6; i++) {
-        neighborIndices[voxelIndex * 6 + i] = -1;
-    }
-    
-    // Find which shell this voxel belongs to
-    int shellIdx = -1;
-    int localIdx = voxelIndex;
-    for (int i = 0; i < 32; i++) {
-        if (shells[i].voxelOffset <= (int)voxelIndex && 
-            (i == 31 || shells[i+1].voxelOffset > (int)voxelIndex)) {
-            shellIdx = i;
-            localIdx = voxelIndex - shells[i].voxelOffset;
-            break;
-        }
-    }
-    
-    if (shellIdx < 0) return;
-    
-    int latBands = shells[shellIdx].latBands;
-    int latIdx = localIdx / shells[shellIdx].maxLonCount;
-    int lonIdx = localIdx % shells[shellIdx].maxLonCount;
-    int lonCount = shells[shellIdx].maxLonCount; // Approximate
-    
-    // Radial neighbors
-    if (shellIdx > 0) {
-        // Inner neighbor (-r)
-        neighborIndices[voxelIndex * 6 + 0] = shells[shellIdx-1].voxelOffset + 
-            min(localIdx, shells[shellIdx-1].latBands * shells[shellIdx-1].maxLonCount - 1);
-    }
-    if (shellIdx < 31) {
-        // Outer neighbor (+r)
-        neighborIndices[voxelIndex * 6 + 1] = shells[shellIdx+1].voxelOffset + 
-            min(localIdx, shells[shellIdx+1].latBands * shells[shellIdx+1].maxLonCount - 1);
-    }
-    
-    // Latitude neighbors
-    if (latIdx > 0) {
-        // North neighbor (-lat)
-        neighborIndices[voxelIndex * 6 + 2] = shells[shellIdx].voxelOffset + 
-            (latIdx - 1) * lonCount + lonIdx;
-    }
-    if (latIdx < latBands - 1) {
-        // South neighbor (+lat)
-        neighborIndices[voxelIndex * 6 + 3] = shells[shellIdx].voxelOffset + 
-            (latIdx + 1) * lonCount + lonIdx;
-    }
-    
-    // Longitude neighbors (with wrapping)
-    // West neighbor (-lon)
-    neighborIndices[voxelIndex * 6 + 4] = shells[shellIdx].voxelOffset + 
-        latIdx * lonCount + ((lonIdx - 1 + lonCount) % lonCount);
-    
-    // East neighbor (+lon)
-    neighborIndices[voxelIndex * 6 + 5] = shells[shellIdx].voxelOffset + 
-        latIdx * lonCount + ((lonIdx + 1) % lonCount);
-}
-
 // Optimized temperature diffusion using precomputed neighbors
 kernel void updateTemperatureFast(
     device Voxel* voxels [[buffer(0)]],
     device const int* neighborIndices [[buffer(1)]],
     constant float& dt [[buffer(2)]],
-    constant float& thermalDiffusivity [[buffer(3)]],
+    constant float* materialDiffusivity [[buffer(3)]],
+    constant float& radiogenicHeat [[buffer(4)]], // K per year in the deep interior
     uint3 gid [[thread_position_in_grid]],
     uint3 gridSize [[threads_per_grid]]
 ) {
@@ -638,7 +581,8 @@ kernel void updateTemperatureFast(
     device Voxel& voxel = voxels[voxelIndex];
     
     // Skip air voxels
-    if (voxel.core.MaterialType == 0) return;
+    if (voxel.matType == 0) return;
+    float thermalDiffusivity = materialDiffusivity[voxel.matType];
     
     // Get neighbor indices
     device const int* neighbors = &neighborIndices[voxelIndex * 6];
@@ -651,7 +595,7 @@ kernel void updateTemperatureFast(
         int neighborIdx = neighbors[i];
         if (neighborIdx >= 0 && neighborIdx < (int)gridSize.x) {
             device Voxel& neighbor = voxels[neighborIdx];
-            if (neighbor.core.MaterialType != 0) { // Not air
+            if (neighbor.matType != 0) { // Not air
                 avgTemp += neighbor.temperature;
                 neighborCount++;
             }
@@ -665,7 +609,7 @@ kernel void updateTemperatureFast(
     
     // Add internal heating for deep voxels
     if (voxel.temperature > 4000) { // Deep mantle/core
-        dTemp += 1e-9 * dt;
+        dTemp += radiogenicHeat * dt;
     }
     
     voxel.temperature += dTemp;
@@ -675,6 +619,31 @@ kernel void updateTemperatureFast(
 }
 `
 
+// cVoxel reinterprets a voxel in the shared GPU layout as its C struct
+func cVoxel(v gpu.GPUVoxelMaterial) C.GPUVoxel {
+	return *(*C.GPUVoxel)(unsafe.Pointer(&v))
+}
+
+// cVoxelLayout returns the size of the C voxel struct and its field offsets
+// in gpu.GPUVoxelFields order, for checking the two agree
+func cVoxelLayout() (uintptr, []uintptr) {
+	var v C.GPUVoxel
+	return unsafe.Sizeof(v), []uintptr{
+		unsafe.Offsetof(v.matType),
+		unsafe.Offsetof(v.density),
+		unsafe.Offsetof(v.temperature),
+		unsafe.Offsetof(v.pressure),
+		unsafe.Offsetof(v.velNorth),
+		unsafe.Offsetof(v.velEast),
+		unsafe.Offsetof(v.velR),
+		unsafe.Offsetof(v.age),
+		unsafe.Offsetof(v.plateID),
+		unsafe.Offsetof(v.isBoundary),
+		unsafe.Offsetof(v.composition),
+		unsafe.Offsetof(v.flags),
+	}
+}
+
 // uploadPlanetData transfers planet voxel data to GPU
 func (mc *MetalCompute) uploadPlanetData(planet *core.VoxelPlanet) error {
 	// Get GPU buffer pointers
@@ -698,27 +667,7 @@ func (mc *MetalCompute) uploadPlanetData(planet *core.VoxelPlanet) error {
 					return fmt.Errorf("voxel index overflow")
 				}
 
-				voxelData[voxelIndex].core.MaterialType = C.uint8_t(voxel.Type)
-				voxelData[voxelIndex].density = C.float(voxel.Density)
-				voxelData[voxelIndex].temperature = C.float(voxel.Temperature)
-				voxelData[voxelIndex].pressure = C.float(voxel.Pressure)
-				voxelData[voxelIndex].velR = C.float(voxel.VelR)
-				voxelData[voxelIndex].VelNorth = C.float(voxel.VelNorth)
-				voxelData[voxelIndex].VelEast = C.float(voxel.VelEast)
-				voxelData[voxelIndex].age = C.float(voxel.Age)
-				voxelData[voxelIndex].stress = C.float(voxel.Stress)
-				voxelData[voxelIndex].composition = C.float(voxel.Composition)
-				voxelData[voxelIndex].yieldStrength = C.float(voxel.YieldStrength)
-
-				// Pack flags
-				flags := C.uint8_t(0)
-				if voxel.IsBrittle {
-					flags |= 1
-				}
-				if voxel.IsFractured {
-					flags |= 2
-				}
-				voxelData[voxelIndex].flags = flags
+				voxelData[voxelIndex] = cVoxel(gpu.ConvertToGPUVoxel(&voxel))
 
 				voxelIndex++
 			}
@@ -745,21 +694,18 @@ func (mc *MetalCompute) downloadPlanetData(planet *core.VoxelPlanet) error {
 				voxel := &shell.Voxels[latIdx][lonIdx]
 				gpuVoxel := &voxelData[voxelIndex]
 
-				voxel.Type = core.MaterialType(gpuVoxel.core.MaterialType)
+				// Stress and yield strength aren't part of the GPU layout and keep their CPU values
+				voxel.Type = core.MaterialType(gpuVoxel.matType)
 				voxel.Density = float32(gpuVoxel.density)
 				voxel.Temperature = float32(gpuVoxel.temperature)
 				voxel.Pressure = float32(gpuVoxel.pressure)
 				voxel.VelR = float32(gpuVoxel.velR)
-				voxel.VelNorth = float32(gpuVoxel.VelNorth)
-				voxel.VelEast = float32(gpuVoxel.VelEast)
+				voxel.VelNorth = float32(gpuVoxel.velNorth)
+				voxel.VelEast = float32(gpuVoxel.velEast)
 				voxel.Age = float32(gpuVoxel.age)
-				voxel.Stress = float32(gpuVoxel.stress)
 				voxel.Composition = float32(gpuVoxel.composition)
-				voxel.YieldStrength = float32(gpuVoxel.yieldStrength)
-
-				// Unpack flags
-				voxel.IsBrittle = (gpuVoxel.flags & 1) != 0
-				voxel.IsFractured = (gpuVoxel.flags & 2) != 0
+				voxel.IsBrittle = gpuVoxel.flags&gpu.GPUVoxelBrittle != 0
+				voxel.IsFractured = gpuVoxel.flags&gpu.GPUVoxelFractured != 0
 
 				voxelIndex++
 			}
@@ -769,23 +715,6 @@ func (mc *MetalCompute) downloadPlanetData(planet *core.VoxelPlanet) error {
 	return nil
 }
 
-// computeNeighborIndices precomputes neighbor relationships for fast lookup
-func (mc *MetalCompute) computeNeighborIndices() error {
-	result := C.computeNeighborIndices(
-		mc.ctx,
-		mc.neighborBuffer,
-		mc.shellBuffer,
-		C.int(mc.totalVoxels),
-	)
-
-	if result != 0 {
-		return fmt.Errorf("failed to compute neighbor indices")
-	}
-
-	mc.neighborsReady = true
-	return nil
-}
-
 // UpdateAdvection runs material advection on GPU
 func (mc *MetalCompute) UpdateAdvection(dt float64) error {
 	if !mc.initialized {
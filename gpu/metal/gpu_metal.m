@@ -17,19 +17,7 @@ typedef struct {
 } MetalContext;
 
 // Voxel data structure for GPU
-typedef struct {
-    uint8_t materialType;
-    float density;
-    float temperature;
-    float pressure;
-    float velR, VelNorth, VelEast;
-    float age;
-    float stress;
-    float composition;
-    float yieldStrength;
-    uint8_t flags; // brittle, fractured, etc
-    uint8_t padding[2];
-} GPUVoxel;
+#include "gpu_voxel.h"
 
 // Shell metadata
 typedef struct {
@@ -184,7 +172,8 @@ void* getBufferContents(void* buffer) {
 }
 
 int runTemperatureKernel(MetalContext* ctx, void* voxelBuffer, void* shellBuffer, 
-                        int voxelCount, float dt, float thermalDiffusivity) {
+                        int voxelCount, float dt, const float* materialDiffusivity, int materialCount,
+                        float radiogenicHeat) {
     @autoreleasepool {
         // Create command buffer
         id<MTLCommandBuffer> commandBuffer = [ctx->commandQueue commandBuffer];
@@ -205,7 +194,8 @@ int runTemperatureKernel(MetalContext* ctx, void* voxelBuffer, void* shellBuffer
         
         // Set constants
         [encoder setBytes:&dt length:sizeof(float) atIndex:2];
-        [encoder setBytes:&thermalDiffusivity length:sizeof(float) atIndex:3];
+        [encoder setBytes:materialDiffusivity length:sizeof(float) * materialCount atIndex:3];
+        [encoder setBytes:&radiogenicHeat length:sizeof(float) atIndex:4];
         
         // Calculate thread groups
         NSUInteger threadsPerThreadgroup = MIN(ctx->temperaturePipeline.maxTotalThreadsPerThreadgroup, 256);
@@ -229,8 +219,8 @@ int runTemperatureKernel(MetalContext* ctx, void* voxelBuffer, void* shellBuffer
     }
 }
 
-int runConvectionKernel(MetalContext* ctx, void* voxelBuffer, void* shellBuffer, 
-                       int voxelCount, float dt) {
+int runConvectionKernel(MetalContext* ctx, void* voxelBuffer, void* neighborBuffer, void* lengthBuffer,
+                       int voxelCount, float dt, const float* materialViscosity, int materialCount) {
     @autoreleasepool {
         // Create command buffer
         id<MTLCommandBuffer> commandBuffer = [ctx->commandQueue commandBuffer];
@@ -245,23 +235,27 @@ int runConvectionKernel(MetalContext* ctx, void* voxelBuffer, void* shellBuffer,
         
         // Set buffers
         id<MTLBuffer> voxelMTLBuffer = (__bridge id<MTLBuffer>)voxelBuffer;
-        id<MTLBuffer> shellMTLBuffer = (__bridge id<MTLBuffer>)shellBuffer;
+        id<MTLBuffer> neighborMTLBuffer = (__bridge id<MTLBuffer>)neighborBuffer;
+        id<MTLBuffer> lengthMTLBuffer = (__bridge id<MTLBuffer>)lengthBuffer;
         [encoder setBuffer:voxelMTLBuffer offset:0 atIndex:0];
-        [encoder setBuffer:shellMTLBuffer offset:0 atIndex:1];
+        [encoder setBuffer:neighborMTLBuffer offset:0 atIndex:1];
+        [encoder setBuffer:lengthMTLBuffer offset:0 atIndex:2];
         
         // Set constants
-        [encoder setBytes:&dt length:sizeof(float) atIndex:2];
+        uint32_t materials = (uint32_t)materialCount;
+        uint32_t voxels = (uint32_t)voxelCount;
+        [encoder setBytes:&dt length:sizeof(float) atIndex:3];
+        [encoder setBytes:materialViscosity length:sizeof(float) * materialCount atIndex:4];
+        [encoder setBytes:&materials length:sizeof(uint32_t) atIndex:5];
+        [encoder setBytes:&voxels length:sizeof(uint32_t) atIndex:6];
         
         // Calculate thread groups
         NSUInteger threadsPerThreadgroup = MIN(ctx->convectionPipeline.maxTotalThreadsPerThreadgroup, 256);
         NSUInteger threadgroupsPerGrid = (voxelCount + threadsPerThreadgroup - 1) / threadsPerThreadgroup;
         
-        MTLSize gridSize = MTLSizeMake(voxelCount, 1, 1);
-        MTLSize threadgroupSize = MTLSizeMake(threadsPerThreadgroup, 1, 1);
-        
         // Dispatch threads
         [encoder dispatchThreadgroups:MTLSizeMake(threadgroupsPerGrid, 1, 1) 
-                threadsPerThreadgroup:threadgroupSize];
+                threadsPerThreadgroup:MTLSizeMake(threadsPerThreadgroup, 1, 1)];
         
         // End encoding
         [encoder endEncoding];
@@ -320,62 +314,10 @@ int runAdvectionKernel(MetalContext* ctx, void* voxelBuffer, void* newVoxelBuffe
         return 0;
     }
 }
-int computeNeighborIndices(MetalContext* ctx, void* neighborBuffer, void* shellBuffer, int voxelCount) {
-    @autoreleasepool {
-        // Get the compute neighbor indices function
-        id<MTLFunction> function = [ctx->library newFunctionWithName:@"computeNeighborIndices"];
-        if (!function) {
-            NSLog(@"Failed to find computeNeighborIndices function");
-            return -1;
-        }
-        
-        // Create pipeline state
-        NSError* error = nil;
-        id<MTLComputePipelineState> pipeline = [ctx->device newComputePipelineStateWithFunction:function 
-                                                                                          error:&error];
-        if (!pipeline) {
-            NSLog(@"Failed to create neighbor indices pipeline: %@", error);
-            return -1;
-        }
-        
-        // Create command buffer
-        id<MTLCommandBuffer> commandBuffer = [ctx->commandQueue commandBuffer];
-        if (!commandBuffer) return -1;
-        
-        // Create compute encoder
-        id<MTLComputeCommandEncoder> encoder = [commandBuffer computeCommandEncoder];
-        if (!encoder) return -1;
-        
-        // Set pipeline
-        [encoder setComputePipelineState:pipeline];
-        
-        // Set buffers
-        id<MTLBuffer> neighborMTLBuffer = (__bridge id<MTLBuffer>)neighborBuffer;
-        id<MTLBuffer> shellMTLBuffer = (__bridge id<MTLBuffer>)shellBuffer;
-        [encoder setBuffer:neighborMTLBuffer offset:0 atIndex:0];
-        [encoder setBuffer:shellMTLBuffer offset:0 atIndex:1];
-        
-        // Calculate thread groups
-        NSUInteger threadsPerThreadgroup = MIN(pipeline.maxTotalThreadsPerThreadgroup, 256);
-        NSUInteger threadgroupsPerGrid = (voxelCount + threadsPerThreadgroup - 1) / threadsPerThreadgroup;
-        
-        // Dispatch threads
-        [encoder dispatchThreadgroups:MTLSizeMake(threadgroupsPerGrid, 1, 1) 
-                threadsPerThreadgroup:MTLSizeMake(threadsPerThreadgroup, 1, 1)];
-        
-        // End encoding
-        [encoder endEncoding];
-        
-        // Commit and wait
-        [commandBuffer commit];
-        [commandBuffer waitUntilCompleted];
-        
-        return 0;
-    }
-}
 
 int runTemperatureFastKernel(MetalContext* ctx, void* voxelBuffer, void* neighborBuffer, 
-                            int voxelCount, float dt, float thermalDiffusivity) {
+                            int voxelCount, float dt, const float* materialDiffusivity, int materialCount,
+                            float radiogenicHeat) {
     @autoreleasepool {
         // Get the fast temperature function
         id<MTLFunction> function = [ctx->library newFunctionWithName:@"updateTemperatureFast"];
@@ -412,7 +354,8 @@ int runTemperatureFastKernel(MetalContext* ctx, void* voxelBuffer, void* neighbo
         
         // Set constants
         [encoder setBytes:&dt length:sizeof(float) atIndex:2];
-        [encoder setBytes:&thermalDiffusivity length:sizeof(float) atIndex:3];
+        [encoder setBytes:materialDiffusivity length:sizeof(float) * materialCount atIndex:3];
+        [encoder setBytes:&radiogenicHeat length:sizeof(float) atIndex:4];
         
         // Calculate thread groups
         NSUInteger threadsPerThreadgroup = MIN(pipeline.maxTotalThreadsPerThreadgroup, 256);
@@ -0,0 +1,64 @@
+//go:build darwin
+// +build darwin
+
+package metal
+
+import (
+	"math"
+	"testing"
+
+	"worldgenerator/core"
+	"worldgenerator/gpu"
+)
+
+// TestMetalConvectionMatchesCPU runs the Metal convection kernel on a small
+// planet with magma plumes and compares it against the CPU reference
+func TestMetalConvectionMatchesCPU(t *testing.T) {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 4, 24)
+	for shellIdx := range planet.Shells[:len(planet.Shells)-1] {
+		shell := &planet.Shells[shellIdx]
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				if (latIdx+lonIdx)%3 == 0 {
+					voxel := &shell.Voxels[latIdx][lonIdx]
+					voxel.Type = core.MatMagma
+					voxel.Temperature = 2000
+				}
+			}
+		}
+	}
+
+	var voxels []gpu.GPUVoxelMaterial
+	for _, shell := range planet.Shells {
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				voxels = append(voxels, gpu.ConvertToGPUVoxel(&shell.Voxels[latIdx][lonIdx]))
+			}
+		}
+	}
+
+	mc, err := NewMetalCompute(planet)
+	if err != nil {
+		t.Skipf("no Metal device: %v", err)
+	}
+	defer mc.Release()
+
+	const dt = 1e3
+	want := gpu.ConvectVoxels(voxels, gpu.NeighborIndices(planet), gpu.MaterialViscosityScales(), gpu.ConvectionLengthScales(planet), dt)
+	if err := mc.StepConvection(planet, dt); err != nil {
+		t.Fatalf("StepConvection: %v", err)
+	}
+
+	idx := 0
+	for _, shell := range planet.Shells {
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				got := shell.Voxels[latIdx][lonIdx].VelR
+				if math.Abs(float64(got-want[idx])) > 1e-4*math.Abs(float64(want[idx]))+1e-30 {
+					t.Fatalf("voxel %d velR %g on Metal, %g on the CPU", idx, got, want[idx])
+				}
+				idx++
+			}
+		}
+	}
+}
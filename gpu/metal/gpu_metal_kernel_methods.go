@@ -12,6 +12,11 @@ func (mc *MetalCompute) RunConvectionKernel(dt float32) error {
 	return mc.UpdateConvection(float64(dt))
 }
 
+// SetRadiogenicHeat sets the deep heating RunTemperatureKernel applies
+func (mc *MetalCompute) SetRadiogenicHeat(kelvinPerYear float32) {
+	mc.radiogenicHeat = kelvinPerYear
+}
+
 // RunTemperatureKernel implements the GPUCompute interface method
 func (mc *MetalCompute) RunTemperatureKernel(dt float32) error {
 	return mc.UpdateTemperature(float64(dt))
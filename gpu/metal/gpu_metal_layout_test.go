@@ -0,0 +1,28 @@
+//go:build darwin
+// +build darwin
+
+package metal
+
+import (
+	"testing"
+
+	"worldgenerator/gpu"
+)
+
+// TestMetalVoxelLayout checks the cgo voxel struct has the shared GPU layout,
+// so the Metal buffer can be shared with OpenGL and copied to and from
+// gpu.GPUVoxelMaterial as raw memory
+func TestMetalVoxelLayout(t *testing.T) {
+	size, offsets := cVoxelLayout()
+	if size != gpu.GPUVoxelSize {
+		t.Errorf("C GPUVoxel is %d bytes, want %d", size, gpu.GPUVoxelSize)
+	}
+	if len(offsets) != len(gpu.GPUVoxelFields) {
+		t.Fatalf("C GPUVoxel has %d fields, shared layout has %d", len(offsets), len(gpu.GPUVoxelFields))
+	}
+	for i, f := range gpu.GPUVoxelFields {
+		if offsets[i] != f.Offset {
+			t.Errorf("C %s at offset %d, want %d", f.Name, offsets[i], f.Offset)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+// Voxel layout shared with gpu.GPUVoxelMaterial and the Metal kernels
+// Field order and types must follow gpu.GPUVoxelFields; gpu_metal_layout_test.go checks them
+
+#ifndef GPU_VOXEL_H
+#define GPU_VOXEL_H
+
+#include <stdint.h>
+
+typedef struct {
+    uint32_t matType;
+    float density;
+    float temperature;
+    float pressure;
+    float velNorth;
+    float velEast;
+    float velR;
+    float age;
+    int32_t plateID;
+    int32_t isBoundary;
+    float composition;
+    uint32_t flags; // brittle, fractured
+} GPUVoxel;
+
+#endif
@@ -1,31 +1,587 @@
-//go:build !darwin
-// +build !darwin
+//go:build opencl
+// +build opencl
 
 package opencl
 
+/*
+#cgo linux LDFLAGS: -lOpenCL
+#cgo windows LDFLAGS: -lOpenCL
+#cgo darwin LDFLAGS: -framework OpenCL
+
+#define CL_TARGET_OPENCL_VERSION 120
+#ifdef __APPLE__
+#include <OpenCL/opencl.h>
+#else
+#include <CL/cl.h>
+#endif
+#include <stdio.h>
+#include <stdlib.h>
+
+typedef struct {
+    cl_context context;
+    cl_command_queue queue;
+    cl_program program;
+    cl_kernel temperature;
+    cl_kernel convection;
+    cl_kernel advection;
+    cl_kernel storeField;
+} CLContext;
+
+static char clErrorText[4096];
+
+static const char* clError(const char* what, cl_int status) {
+    snprintf(clErrorText, sizeof(clErrorText), "%s: error %d", what, status);
+    return clErrorText;
+}
+
+// findDevice picks the first GPU of any platform, or failing that the first
+// device of any kind
+static cl_int findDevice(cl_device_id* device) {
+    cl_platform_id platforms[8];
+    cl_uint count = 0;
+    cl_int status = clGetPlatformIDs(8, platforms, &count);
+    if (status != CL_SUCCESS) {
+        return status;
+    }
+    if (count > 8) {
+        count = 8;
+    }
+    cl_device_type types[] = {CL_DEVICE_TYPE_GPU, CL_DEVICE_TYPE_ALL};
+    for (int t = 0; t < 2; t++) {
+        for (cl_uint p = 0; p < count; p++) {
+            if (clGetDeviceIDs(platforms[p], types[t], 1, device, NULL) == CL_SUCCESS) {
+                return CL_SUCCESS;
+            }
+        }
+    }
+    return CL_DEVICE_NOT_FOUND;
+}
+
+static void releaseCLContext(CLContext* ctx) {
+    cl_kernel kernels[] = {ctx->temperature, ctx->convection, ctx->advection, ctx->storeField};
+    for (int i = 0; i < 4; i++) {
+        if (kernels[i] != NULL) {
+            clReleaseKernel(kernels[i]);
+        }
+    }
+    if (ctx->program != NULL) {
+        clReleaseProgram(ctx->program);
+    }
+    if (ctx->queue != NULL) {
+        clReleaseCommandQueue(ctx->queue);
+    }
+    if (ctx->context != NULL) {
+        clReleaseContext(ctx->context);
+    }
+    free(ctx);
+}
+
+// createCLContext picks a device, builds source for it and creates the
+// kernels. On failure it returns NULL and sets *err
+static CLContext* createCLContext(const char* source, const char** err) {
+    cl_device_id device;
+    cl_int status = findDevice(&device);
+    if (status != CL_SUCCESS) {
+        *err = clError("no OpenCL device", status);
+        return NULL;
+    }
+
+    CLContext* ctx = calloc(1, sizeof(CLContext));
+    ctx->context = clCreateContext(NULL, 1, &device, NULL, NULL, &status);
+    if (status != CL_SUCCESS) {
+        *err = clError("creating OpenCL context", status);
+        releaseCLContext(ctx);
+        return NULL;
+    }
+    ctx->queue = clCreateCommandQueue(ctx->context, device, 0, &status);
+    if (status != CL_SUCCESS) {
+        *err = clError("creating OpenCL command queue", status);
+        releaseCLContext(ctx);
+        return NULL;
+    }
+
+    ctx->program = clCreateProgramWithSource(ctx->context, 1, &source, NULL, &status);
+    if (status != CL_SUCCESS) {
+        *err = clError("creating OpenCL program", status);
+        releaseCLContext(ctx);
+        return NULL;
+    }
+    if (clBuildProgram(ctx->program, 1, &device, "-cl-std=CL1.2", NULL, NULL) != CL_SUCCESS) {
+        size_t logSize = 0;
+        clGetProgramBuildInfo(ctx->program, device, CL_PROGRAM_BUILD_LOG, 0, NULL, &logSize);
+        char* log = malloc(logSize + 1);
+        clGetProgramBuildInfo(ctx->program, device, CL_PROGRAM_BUILD_LOG, logSize, log, NULL);
+        log[logSize] = 0;
+        snprintf(clErrorText, sizeof(clErrorText), "compiling OpenCL kernels: %s", log);
+        free(log);
+        *err = clErrorText;
+        releaseCLContext(ctx);
+        return NULL;
+    }
+
+    const char* names[] = {"updateTemperatureFast", "updateConvection", "advectMaterial", "storeVoxelField"};
+    cl_kernel* kernels[] = {&ctx->temperature, &ctx->convection, &ctx->advection, &ctx->storeField};
+    for (int i = 0; i < 4; i++) {
+        *kernels[i] = clCreateKernel(ctx->program, names[i], &status);
+        if (status != CL_SUCCESS) {
+            *kernels[i] = NULL;
+            *err = clError("finding OpenCL kernels", status);
+            releaseCLContext(ctx);
+            return NULL;
+        }
+    }
+    return ctx;
+}
+
+static const char* deviceAlloc(CLContext* ctx, cl_mem* mem, size_t size) {
+    cl_int status;
+    *mem = clCreateBuffer(ctx->context, CL_MEM_READ_WRITE, size > 0 ? size : 4, NULL, &status);
+    return status == CL_SUCCESS ? NULL : clError("allocating device memory", status);
+}
+
+static void deviceFree(cl_mem mem) {
+    clReleaseMemObject(mem);
+}
+
+static const char* copyToDevice(CLContext* ctx, cl_mem dst, const void* src, size_t size) {
+    cl_int status = clEnqueueWriteBuffer(ctx->queue, dst, CL_TRUE, 0, size, src, 0, NULL, NULL);
+    return status == CL_SUCCESS ? NULL : clError("copying to the device", status);
+}
+
+static const char* copyFromDevice(CLContext* ctx, void* dst, cl_mem src, size_t size) {
+    cl_int status = clEnqueueReadBuffer(ctx->queue, src, CL_TRUE, 0, size, dst, 0, NULL, NULL);
+    return status == CL_SUCCESS ? NULL : clError("copying from the device", status);
+}
+
+typedef struct {
+    size_t size;
+    const void* value;
+} KernelArg;
+
+static const char* launch(CLContext* ctx, cl_kernel kernel, unsigned int count, size_t localSize,
+                          const KernelArg* args, int argCount) {
+    for (int i = 0; i < argCount; i++) {
+        cl_int status = clSetKernelArg(kernel, i, args[i].size, args[i].value);
+        if (status != CL_SUCCESS) {
+            return clError("setting kernel argument", status);
+        }
+    }
+    size_t global = (count + localSize - 1) / localSize * localSize;
+    cl_int status = clEnqueueNDRangeKernel(ctx->queue, kernel, 1, NULL, &global, &localSize, 0, NULL, NULL);
+    if (status == CL_SUCCESS) {
+        status = clFinish(ctx->queue);
+    }
+    return status == CL_SUCCESS ? NULL : clError("running OpenCL kernel", status);
+}
+
+static const char* launchTemperature(CLContext* ctx, cl_mem voxels, cl_mem neighbors, cl_mem temperatures,
+                                     cl_mem diffusivity, unsigned int voxelCount, float dt, float radiogenicHeat,
+                                     size_t localSize) {
+    cl_uint count = voxelCount;
+    KernelArg args[] = {
+        {sizeof(cl_mem), &voxels}, {sizeof(cl_mem), &neighbors}, {sizeof(cl_mem), &temperatures},
+        {sizeof(cl_mem), &diffusivity}, {sizeof(cl_uint), &count}, {sizeof(float), &dt},
+        {sizeof(float), &radiogenicHeat},
+    };
+    return launch(ctx, ctx->temperature, voxelCount, localSize, args, 7);
+}
+
+static const char* launchConvection(CLContext* ctx, cl_mem voxels, cl_mem neighbors, cl_mem velocities,
+                                    cl_mem viscosity, cl_mem lengths, unsigned int materialCount,
+                                    unsigned int voxelCount, float dt, size_t localSize) {
+    cl_uint materials = materialCount, count = voxelCount;
+    KernelArg args[] = {
+        {sizeof(cl_mem), &voxels}, {sizeof(cl_mem), &neighbors}, {sizeof(cl_mem), &velocities},
+        {sizeof(cl_mem), &viscosity}, {sizeof(cl_mem), &lengths}, {sizeof(cl_uint), &materials},
+        {sizeof(cl_uint), &count}, {sizeof(float), &dt},
+    };
+    return launch(ctx, ctx->convection, voxelCount, localSize, args, 8);
+}
+
+static const char* launchAdvection(CLContext* ctx, cl_mem voxels, cl_mem advected, cl_mem neighbors,
+                                   cl_mem cells, unsigned int voxelCount, float dt, size_t localSize) {
+    cl_uint count = voxelCount;
+    KernelArg args[] = {
+        {sizeof(cl_mem), &voxels}, {sizeof(cl_mem), &advected}, {sizeof(cl_mem), &neighbors},
+        {sizeof(cl_mem), &cells}, {sizeof(cl_uint), &count}, {sizeof(float), &dt},
+    };
+    return launch(ctx, ctx->advection, voxelCount, localSize, args, 6);
+}
+
+static const char* launchStoreField(CLContext* ctx, cl_mem voxels, cl_mem values, unsigned int field,
+                                    unsigned int voxelCount, size_t localSize) {
+    cl_uint word = field, count = voxelCount;
+    KernelArg args[] = {
+        {sizeof(cl_mem), &voxels}, {sizeof(cl_mem), &values}, {sizeof(cl_uint), &word}, {sizeof(cl_uint), &count},
+    };
+    return launch(ctx, ctx->storeField, voxelCount, localSize, args, 4);
+}
+*/
+import "C"
+
 import (
 	"fmt"
+	"sync"
+	"unsafe"
+
 	"worldgenerator/core"
 	"worldgenerator/gpu"
 )
 
-// OpenCLCompute placeholder for non-Darwin platforms
-type OpenCLCompute struct{}
+// OpenCLCompute runs heat diffusion, convection and advection on any OpenCL
+// device. The physics engine hands it each planet buffer it steps through
+// the Step methods, which read their results straight back for the CPU
+// phases in between. The Run*Kernel methods instead work on the voxels
+// left on the device, which Download reads back only when asked
+type OpenCLCompute struct {
+	mu  sync.Mutex // The C error buffer and staging slices are shared
+	ctx *C.CLContext
 
-func NewOpenCLCompute(planet *core.VoxelPlanet) (gpu.GPUCompute, error) {
-	return &OpenCLCompute{}, fmt.Errorf("OpenCL compute not yet implemented")
+	// Device buffers. Advection writes advected and the two are swapped
+	voxels       C.cl_mem
+	advected     C.cl_mem
+	neighbors    C.cl_mem
+	cells        C.cl_mem
+	temperatures C.cl_mem
+	velocities   C.cl_mem
+	diffusivity  C.cl_mem
+	viscosity    C.cl_mem
+	lengths      C.cl_mem
+
+	totalVoxels    int
+	materialCount  int
+	staging        []gpu.GPUVoxelMaterial
+	results        []float32
+	radiogenicHeat float32 // Deep heating in K per year, from the planet stepped last
 }
 
-func (o *OpenCLCompute) RunTemperatureKernel(dt float32) error {
-	return fmt.Errorf("OpenCL not implemented")
+// NewOpenCLCompute builds the kernels for the first OpenCL GPU and uploads
+// the planet's voxels and tables. It returns an error when there is no
+// usable device
+func NewOpenCLCompute(planet *core.VoxelPlanet) (*OpenCLCompute, error) {
+	source := C.CString(kernelSource)
+	defer C.free(unsafe.Pointer(source))
+
+	var cErr *C.char
+	ctx := C.createCLContext(source, &cErr)
+	if ctx == nil {
+		return nil, fmt.Errorf("OpenCL unavailable: %s", C.GoString(cErr))
+	}
+	oc := &OpenCLCompute{ctx: ctx, radiogenicHeat: float32(planet.RadiogenicHeat())}
+
+	if err := oc.createBuffers(planet); err != nil {
+		oc.Cleanup()
+		return nil, err
+	}
+	fmt.Printf("✅ OpenCL compute ready for %d voxels\n", oc.totalVoxels)
+	return oc, nil
 }
 
-func (o *OpenCLCompute) RunConvectionKernel(dt float32) error {
-	return fmt.Errorf("OpenCL not implemented")
+// createBuffers allocates the device buffers and uploads the voxels and the
+// tables that don't change between steps
+func (oc *OpenCLCompute) createBuffers(planet *core.VoxelPlanet) error {
+	neighbors := gpu.NeighborIndices(planet)
+	cells := gpu.AdvectionCells(planet)
+	diffusivity := gpu.MaterialDiffusivities()
+	viscosity := gpu.MaterialViscosityScales()
+	lengths := gpu.ConvectionLengthScales(planet)
+
+	oc.totalVoxels = len(lengths)
+	oc.materialCount = len(viscosity)
+	oc.staging = make([]gpu.GPUVoxelMaterial, oc.totalVoxels)
+	oc.results = make([]float32, oc.totalVoxels)
+	if oc.totalVoxels == 0 {
+		return fmt.Errorf("planet has no voxels")
+	}
+
+	for _, buffer := range []struct {
+		mem  *C.cl_mem
+		size int
+		data unsafe.Pointer
+	}{
+		{&oc.voxels, oc.totalVoxels * gpu.GPUVoxelSize, nil},
+		{&oc.advected, oc.totalVoxels * gpu.GPUVoxelSize, nil},
+		{&oc.neighbors, len(neighbors) * 4, unsafe.Pointer(&neighbors[0])},
+		{&oc.cells, len(cells) * 4, unsafe.Pointer(&cells[0])},
+		{&oc.temperatures, oc.totalVoxels * 4, nil},
+		{&oc.velocities, oc.totalVoxels * 4, nil},
+		{&oc.diffusivity, len(diffusivity) * 4, unsafe.Pointer(&diffusivity[0])},
+		{&oc.viscosity, len(viscosity) * 4, unsafe.Pointer(&viscosity[0])},
+		{&oc.lengths, len(lengths) * 4, unsafe.Pointer(&lengths[0])},
+	} {
+		if msg := C.deviceAlloc(oc.ctx, buffer.mem, C.size_t(buffer.size)); msg != nil {
+			return fmt.Errorf("OpenCL: %s", C.GoString(msg))
+		}
+		if buffer.data != nil {
+			if msg := C.copyToDevice(oc.ctx, *buffer.mem, buffer.data, C.size_t(buffer.size)); msg != nil {
+				return fmt.Errorf("OpenCL: %s", C.GoString(msg))
+			}
+		}
+	}
+	return oc.upload(planet)
 }
 
-func (o *OpenCLCompute) RunAdvectionKernel(dt float32) error {
-	return fmt.Errorf("OpenCL not implemented")
+// upload copies planet's voxels to the device voxel buffer
+func (oc *OpenCLCompute) upload(planet *core.VoxelPlanet) error {
+	idx := 0
+	for _, shell := range planet.Shells {
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				if idx >= oc.totalVoxels {
+					return fmt.Errorf("planet has more voxels than the %d the OpenCL buffers hold", oc.totalVoxels)
+				}
+				oc.staging[idx] = gpu.ConvertToGPUVoxel(&shell.Voxels[latIdx][lonIdx])
+				idx++
+			}
+		}
+	}
+	if idx != oc.totalVoxels {
+		return fmt.Errorf("planet has %d voxels, OpenCL buffers hold %d", idx, oc.totalVoxels)
+	}
+
+	if msg := C.copyToDevice(oc.ctx, oc.voxels, unsafe.Pointer(&oc.staging[0]), C.size_t(idx*gpu.GPUVoxelSize)); msg != nil {
+		return fmt.Errorf("OpenCL: %s", C.GoString(msg))
+	}
+	return nil
+}
+
+// download copies a per-voxel result buffer into oc.results
+func (oc *OpenCLCompute) download(buffer C.cl_mem) error {
+	if msg := C.copyFromDevice(oc.ctx, unsafe.Pointer(&oc.results[0]), buffer, C.size_t(oc.totalVoxels*4)); msg != nil {
+		return fmt.Errorf("OpenCL: %s", C.GoString(msg))
+	}
+	return nil
+}
+
+// downloadVoxels copies the device voxels into planet. Stress, yield strength
+// and plate IDs aren't part of the GPU layout and keep their CPU values
+func (oc *OpenCLCompute) downloadVoxels(planet *core.VoxelPlanet) error {
+	if msg := C.copyFromDevice(oc.ctx, unsafe.Pointer(&oc.staging[0]), oc.voxels, C.size_t(oc.totalVoxels*gpu.GPUVoxelSize)); msg != nil {
+		return fmt.Errorf("OpenCL: %s", C.GoString(msg))
+	}
+
+	idx := 0
+	for _, shell := range planet.Shells {
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				if idx >= oc.totalVoxels {
+					return fmt.Errorf("planet has more voxels than the %d the OpenCL buffers hold", oc.totalVoxels)
+				}
+				voxel := &shell.Voxels[latIdx][lonIdx]
+				gpuVoxel := &oc.staging[idx]
+				voxel.Type = core.MaterialType(gpuVoxel.Type)
+				voxel.Density = gpuVoxel.Density
+				voxel.Temperature = gpuVoxel.Temperature
+				voxel.Pressure = gpuVoxel.Pressure
+				voxel.VelNorth = gpuVoxel.VelNorth
+				voxel.VelEast = gpuVoxel.VelEast
+				voxel.VelR = gpuVoxel.VelR
+				voxel.Age = gpuVoxel.Age
+				voxel.Composition = gpuVoxel.Composition
+				voxel.IsBrittle = gpuVoxel.Flags&gpu.GPUVoxelBrittle != 0
+				voxel.IsFractured = gpuVoxel.Flags&gpu.GPUVoxelFractured != 0
+				idx++
+			}
+		}
+	}
+	return nil
+}
+
+// runTemperature diffuses the device voxels' heat into the temperature buffer
+func (oc *OpenCLCompute) runTemperature(dt float32) error {
+	if msg := C.launchTemperature(oc.ctx, oc.voxels, oc.neighbors, oc.temperatures, oc.diffusivity,
+		C.uint(oc.totalVoxels), C.float(dt), C.float(oc.radiogenicHeat), openCLLocalSize); msg != nil {
+		return fmt.Errorf("temperature kernel failed: %s", C.GoString(msg))
+	}
+	return nil
+}
+
+// runConvection writes the device voxels' new radial velocities to the
+// velocity buffer
+func (oc *OpenCLCompute) runConvection(dt float32) error {
+	if msg := C.launchConvection(oc.ctx, oc.voxels, oc.neighbors, oc.velocities, oc.viscosity, oc.lengths,
+		C.uint(oc.materialCount), C.uint(oc.totalVoxels), C.float(dt), openCLLocalSize); msg != nil {
+		return fmt.Errorf("convection kernel failed: %s", C.GoString(msg))
+	}
+	return nil
 }
 
-func (o *OpenCLCompute) Cleanup() {}
+// runAdvection advects the device voxels into the spare voxel buffer and
+// swaps the two, leaving the result in oc.voxels
+func (oc *OpenCLCompute) runAdvection(dt float32) error {
+	if msg := C.launchAdvection(oc.ctx, oc.voxels, oc.advected, oc.neighbors, oc.cells,
+		C.uint(oc.totalVoxels), C.float(dt), openCLLocalSize); msg != nil {
+		return fmt.Errorf("advection kernel failed: %s", C.GoString(msg))
+	}
+	oc.voxels, oc.advected = oc.advected, oc.voxels
+	return nil
+}
+
+// storeField copies a result buffer into one field of the device voxels
+func (oc *OpenCLCompute) storeField(values C.cl_mem, field int) error {
+	if msg := C.launchStoreField(oc.ctx, oc.voxels, values, C.uint(field), C.uint(oc.totalVoxels), openCLLocalSize); msg != nil {
+		return fmt.Errorf("OpenCL: %s", C.GoString(msg))
+	}
+	return nil
+}
+
+// StepTemperature diffuses heat through planet for dt years on the GPU and
+// writes the new temperatures back
+func (oc *OpenCLCompute) StepTemperature(planet *core.VoxelPlanet, dt float32) error {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	if oc.ctx == nil {
+		return fmt.Errorf("OpenCL compute released")
+	}
+
+	if err := oc.upload(planet); err != nil {
+		return err
+	}
+	oc.radiogenicHeat = float32(planet.RadiogenicHeat())
+	if err := oc.runTemperature(dt); err != nil {
+		return err
+	}
+	if err := oc.download(oc.temperatures); err != nil {
+		return err
+	}
+
+	idx := 0
+	for _, shell := range planet.Shells {
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				shell.Voxels[latIdx][lonIdx].Temperature = oc.results[idx]
+				idx++
+			}
+		}
+	}
+	return nil
+}
+
+// StepConvection sets the radial convection velocities of planet's voxels
+// for a step of dt years on the GPU
+func (oc *OpenCLCompute) StepConvection(planet *core.VoxelPlanet, dt float32) error {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	if oc.ctx == nil {
+		return fmt.Errorf("OpenCL compute released")
+	}
+
+	if err := oc.upload(planet); err != nil {
+		return err
+	}
+	if err := oc.runConvection(dt); err != nil {
+		return err
+	}
+	if err := oc.download(oc.velocities); err != nil {
+		return err
+	}
+
+	idx := 0
+	for _, shell := range planet.Shells {
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				shell.Voxels[latIdx][lonIdx].VelR = oc.results[idx]
+				idx++
+			}
+		}
+	}
+	return nil
+}
+
+// StepAdvection drifts planet's surface rock and mixes upwelling material
+// for a step of dt years on the GPU and writes the voxels back
+func (oc *OpenCLCompute) StepAdvection(planet *core.VoxelPlanet, dt float32) error {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	if oc.ctx == nil {
+		return fmt.Errorf("OpenCL compute released")
+	}
+
+	if err := oc.upload(planet); err != nil {
+		return err
+	}
+	if err := oc.runAdvection(dt); err != nil {
+		return err
+	}
+	return oc.downloadVoxels(planet)
+}
+
+// UpdateAdvection advects the voxels on the device for dt years without
+// reading them back
+func (oc *OpenCLCompute) UpdateAdvection(dt float64) error {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	if oc.ctx == nil {
+		return fmt.Errorf("OpenCL compute released")
+	}
+	return oc.runAdvection(float32(dt))
+}
+
+// Download copies the voxels on the device into planet, for when the
+// renderer needs the results of the Run*Kernel methods
+func (oc *OpenCLCompute) Download(planet *core.VoxelPlanet) error {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	if oc.ctx == nil {
+		return fmt.Errorf("OpenCL compute released")
+	}
+	return oc.downloadVoxels(planet)
+}
+
+// SetRadiogenicHeat sets the deep heating RunTemperatureKernel applies
+func (oc *OpenCLCompute) SetRadiogenicHeat(kelvinPerYear float32) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	oc.radiogenicHeat = kelvinPerYear
+}
+
+// RunTemperatureKernel diffuses heat through the voxels on the device
+func (oc *OpenCLCompute) RunTemperatureKernel(dt float32) error {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	if oc.ctx == nil {
+		return fmt.Errorf("OpenCL compute released")
+	}
+	if err := oc.runTemperature(dt); err != nil {
+		return err
+	}
+	return oc.storeField(oc.temperatures, temperatureField)
+}
+
+// RunConvectionKernel updates the radial velocities of the voxels on the
+// device
+func (oc *OpenCLCompute) RunConvectionKernel(dt float32) error {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	if oc.ctx == nil {
+		return fmt.Errorf("OpenCL compute released")
+	}
+	if err := oc.runConvection(dt); err != nil {
+		return err
+	}
+	return oc.storeField(oc.velocities, velRField)
+}
+
+// RunAdvectionKernel implements the GPUCompute interface method
+func (oc *OpenCLCompute) RunAdvectionKernel(dt float32) error {
+	return oc.UpdateAdvection(float64(dt))
+}
+
+// Cleanup frees the device buffers and releases the kernels
+func (oc *OpenCLCompute) Cleanup() {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+	if oc.ctx == nil {
+		return
+	}
+
+	for _, buffer := range []*C.cl_mem{&oc.voxels, &oc.advected, &oc.neighbors, &oc.cells, &oc.temperatures,
+		&oc.velocities, &oc.diffusivity, &oc.viscosity, &oc.lengths} {
+		if *buffer != nil {
+			C.deviceFree(*buffer)
+			*buffer = nil
+		}
+	}
+	C.releaseCLContext(oc.ctx)
+	oc.ctx = nil
+}
@@ -1,9 +1,21 @@
-// +build darwin
+//go:build !opencl
+// +build !opencl
 
 package opencl
 
-// Stub for OpenCLCompute on macOS
-func NewOpenCLCompute(planet *core.VoxelPlanet) (GPUCompute, error) {
-	// On macOS, we use Metal instead
-	return NewMetalCompute(planet)
-}
\ No newline at end of file
+import (
+	"fmt"
+
+	"worldgenerator/core"
+	"worldgenerator/gpu"
+)
+
+// OpenCLCompute placeholder for builds without the opencl tag
+type OpenCLCompute struct {
+	gpu.CPUCompute
+}
+
+// NewOpenCLCompute always fails without the opencl build tag
+func NewOpenCLCompute(planet *core.VoxelPlanet) (*OpenCLCompute, error) {
+	return nil, fmt.Errorf("OpenCL unavailable: built without OpenCL support (rebuild with -tags opencl)")
+}
@@ -0,0 +1,149 @@
+//go:build opencl
+// +build opencl
+
+package opencl
+
+import (
+	"math"
+	"testing"
+
+	"worldgenerator/core"
+	"worldgenerator/gpu"
+)
+
+// flatVoxels converts planet's voxels to the GPU layout, shell by shell
+func flatVoxels(planet *core.VoxelPlanet) []gpu.GPUVoxelMaterial {
+	var voxels []gpu.GPUVoxelMaterial
+	for _, shell := range planet.Shells {
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				voxels = append(voxels, gpu.ConvertToGPUVoxel(&shell.Voxels[latIdx][lonIdx]))
+			}
+		}
+	}
+	return voxels
+}
+
+// advectionPlanet builds a planet with rock across the surface and rising
+// magma below it, so both halves of the advection kernel have work
+func advectionPlanet(bands int) *core.VoxelPlanet {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 12, bands)
+	for shellIdx := range planet.Shells {
+		shell := &planet.Shells[shellIdx]
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				voxel := &shell.Voxels[latIdx][lonIdx]
+				switch {
+				case shellIdx == 9 || shellIdx == 10:
+					voxel.Type = core.MatGranite
+					voxel.Composition = float32(lonIdx)
+				case shellIdx < 9 && (latIdx+lonIdx)%3 == 0:
+					voxel.Type = core.MatMagma
+					voxel.Temperature = 2000
+					voxel.VelR = 1
+				}
+			}
+		}
+	}
+	return planet
+}
+
+// TestOpenCLTemperatureMatchesCPU runs the temperature kernel on a small
+// planet with a hot spot and compares it against the CPU reference
+func TestOpenCLTemperatureMatchesCPU(t *testing.T) {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 4, 24)
+	shell := &planet.Shells[len(planet.Shells)-2]
+	shell.Voxels[12][0].Temperature = 5000
+
+	oc, err := NewOpenCLCompute(planet)
+	if err != nil {
+		t.Skipf("no OpenCL device: %v", err)
+	}
+	defer oc.Cleanup()
+
+	const dt = 1e5
+	want := gpu.DiffuseTemperatureFast(flatVoxels(planet), gpu.NeighborIndices(planet), gpu.MaterialDiffusivities(), dt, float32(planet.RadiogenicHeat()))
+	if err := oc.StepTemperature(planet, dt); err != nil {
+		t.Fatalf("StepTemperature: %v", err)
+	}
+
+	for i, voxel := range flatVoxels(planet) {
+		if math.Abs(float64(voxel.Temperature-want[i])) > 1e-3*math.Max(1, float64(want[i])) {
+			t.Fatalf("voxel %d at %.4f K on OpenCL, %.4f K on the CPU", i, voxel.Temperature, want[i])
+		}
+	}
+}
+
+// TestOpenCLAdvectionMatchesCPU runs the advection kernel on a planet with
+// drifting surface rock and upwelling magma and compares it against the CPU
+// reference
+func TestOpenCLAdvectionMatchesCPU(t *testing.T) {
+	planet := advectionPlanet(24)
+
+	oc, err := NewOpenCLCompute(planet)
+	if err != nil {
+		t.Skipf("no OpenCL device: %v", err)
+	}
+	defer oc.Cleanup()
+
+	const dt = 3e5
+	want := gpu.AdvectVoxels(flatVoxels(planet), gpu.NeighborIndices(planet), gpu.AdvectionCells(planet), dt)
+	if err := oc.StepAdvection(planet, dt); err != nil {
+		t.Fatalf("StepAdvection: %v", err)
+	}
+
+	for i, voxel := range flatVoxels(planet) {
+		w := want[i]
+		if voxel.Type != w.Type || voxel.Composition != w.Composition || voxel.Age != w.Age {
+			t.Fatalf("voxel %d is type %d, composition %g, age %g on OpenCL, type %d, composition %g, age %g on the CPU",
+				i, voxel.Type, voxel.Composition, voxel.Age, w.Type, w.Composition, w.Age)
+		}
+		if math.Abs(float64(voxel.Temperature-w.Temperature)) > 1e-3 || math.Abs(float64(voxel.VelEast-w.VelEast)) > 1e-12 {
+			t.Fatalf("voxel %d at %g K, %g m/s east on OpenCL, %g K, %g m/s on the CPU",
+				i, voxel.Temperature, voxel.VelEast, w.Temperature, w.VelEast)
+		}
+	}
+}
+
+// BenchmarkAdvection compares one advection step on the CPU reference with
+// the OpenCL kernel, both with the voxels staying on the device and with the
+// upload and readback every physics step pays
+func BenchmarkAdvection(b *testing.B) {
+	planet := advectionPlanet(180)
+	const dt = 1e5
+
+	b.Run("cpu", func(b *testing.B) {
+		voxels := flatVoxels(planet)
+		neighbors := gpu.NeighborIndices(planet)
+		cells := gpu.AdvectionCells(planet)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			voxels = gpu.AdvectVoxels(voxels, neighbors, cells, dt)
+		}
+		b.ReportMetric(float64(len(voxels))*float64(b.N)/b.Elapsed().Seconds(), "voxels/s")
+	})
+
+	oc, err := NewOpenCLCompute(planet)
+	if err != nil {
+		b.Skipf("no OpenCL device: %v", err)
+	}
+	defer oc.Cleanup()
+
+	b.Run("opencl", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := oc.RunAdvectionKernel(dt); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(oc.totalVoxels)*float64(b.N)/b.Elapsed().Seconds(), "voxels/s")
+	})
+
+	b.Run("opencl-readback", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := oc.StepAdvection(planet, dt); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(oc.totalVoxels)*float64(b.N)/b.Elapsed().Seconds(), "voxels/s")
+	})
+}
@@ -0,0 +1,188 @@
+// Package opencl runs the temperature, convection and advection kernels on
+// any GPU with an OpenCL 1.2 driver, such as AMD cards on Linux. The backend
+// needs the OpenCL headers and loader and is only built with -tags opencl;
+// other builds get a NewOpenCLCompute that always fails
+package opencl
+
+import (
+	"strconv"
+
+	"worldgenerator/gpu"
+)
+
+// openCLLocalSize is the work-group size every kernel launches with
+const openCLLocalSize = 64
+
+// Word offsets within a voxel of the fields the kernels write back, see
+// gpu.GPUVoxelFields
+const (
+	temperatureField = 2
+	velRField        = 6
+)
+
+// kernelPrelude declares the types and GLSL builtins the shared kernel
+// bodies use. OpenCL's abs is for integers only
+const kernelPrelude = `
+typedef uint uint32_t;
+typedef int int32_t;
+
+#define abs(x) fabs(x)
+`
+
+// kernelSource is the OpenCL port of the OpenGL temperature and convection
+// kernels and of the Metal advectMaterial kernel, built for the device at
+// startup. Temperature and convection write their results to a separate
+// buffer and storeVoxelField copies one back into the voxels when the data
+// stays on the device; advection writes whole voxels to a second voxel
+// buffer
+var kernelSource = kernelPrelude + gpu.ConvectionShaderDefines() + gpu.AdvectionShaderDefines() + `
+typedef struct {
+` + gpu.GPUVoxelStructFields("    ") + `} Voxel;
+` + gpu.ConvectedVelRSource + `
+// Each voxel relaxes toward the mean of its non-air neighbors, as
+// gpu.DiffuseTemperatureFast does on the CPU
+__kernel void updateTemperatureFast(
+    __global const Voxel* voxels,
+    __global const int* neighborIndices, // 6 per voxel: -r,+r,-lat,+lat,-lon,+lon
+    __global float* temperatures,
+    __global const float* materialDiffusivity,
+    uint voxelCount,
+    float dt,
+    float radiogenicHeat // K per year in the deep interior
+) {
+    uint voxelIndex = get_global_id(0);
+    if (voxelIndex >= voxelCount) return;
+
+    Voxel voxel = voxels[voxelIndex];
+
+    // Skip air voxels
+    if (voxel.matType == 0u) {
+        temperatures[voxelIndex] = voxel.temperature;
+        return;
+    }
+    float thermalDiffusivity = materialDiffusivity[voxel.matType];
+
+    // Calculate average neighbor temperature
+    float avgTemp = voxel.temperature;
+    int neighborCount = 1;
+
+    for (uint i = 0u; i < 6u; i++) {
+        int neighborIdx = neighborIndices[voxelIndex * 6u + i];
+        if (neighborIdx >= 0 && (uint)neighborIdx < voxelCount) {
+            if (voxels[neighborIdx].matType != 0u) { // Not air
+                avgTemp += voxels[neighborIdx].temperature;
+                neighborCount++;
+            }
+        }
+    }
+
+    avgTemp /= (float)neighborCount;
+
+    // Apply diffusion
+    float dTemp = thermalDiffusivity * (avgTemp - voxel.temperature) * dt / (1000.0f * 1000.0f);
+
+    // Add internal heating for deep voxels
+    if (voxel.temperature > 4000.0f) { // Deep mantle/core
+        dTemp += radiogenicHeat * dt;
+    }
+
+    temperatures[voxelIndex] = clamp(voxel.temperature + dTemp, 0.0f, 6000.0f);
+}
+
+// Radial velocity from buoyancy against each voxel's outer neighbor, the
+// same step as the CPU's UpdateConvection
+__kernel void updateConvection(
+    __global const Voxel* voxels,
+    __global const int* neighborIndices,
+    __global float* velocities,
+    __global const float* materialViscosity,
+    __global const float* lengthScales,
+    uint materialCount,
+    uint voxelCount,
+    float dt
+) {
+    uint voxelIndex = get_global_id(0);
+    if (voxelIndex >= voxelCount) return;
+
+    Voxel voxel = voxels[voxelIndex];
+    float velR = voxel.velR;
+
+    int outerIdx = neighborIndices[voxelIndex * 6u + NEIGHBOR_OUTER];
+    if (outerIdx >= 0 && (uint)outerIdx < voxelCount && voxel.matType < materialCount) {
+        Voxel outer = voxels[outerIdx];
+        velR = convectedVelR(voxel.matType, voxel.density, voxel.temperature, voxel.pressure, voxel.velR,
+            outer.matType, outer.density, outer.temperature,
+            materialViscosity[voxel.matType], lengthScales[voxelIndex], dt);
+    }
+
+    velocities[voxelIndex] = velR;
+}
+
+// Surface plate drift and upwelling mixing, as gpu.AdvectVoxels does on the
+// CPU. Every voxel gathers from its western and inner neighbors into its own
+// slot of advected, so no work item reads another's result
+__kernel void advectMaterial(
+    __global const Voxel* voxels,
+    __global Voxel* advected,
+    __global const int* neighborIndices,
+    __global const int* cells, // CELL_INTS per voxel, see gpu.AdvectionCells
+    uint voxelCount,
+    float dt
+) {
+    uint voxelIndex = get_global_id(0);
+    if (voxelIndex >= voxelCount) return;
+
+    Voxel voxel = voxels[voxelIndex];
+    Voxel out = voxel;
+
+    // Skip air voxels
+    if (voxel.matType == MAT_AIR) {
+        advected[voxelIndex] = out;
+        return;
+    }
+    __global const int* cell = &cells[voxelIndex * CELL_INTS];
+
+    // Plate drift, whole cells at a time
+    if (cell[CELL_SHELL] >= ADVECTION_SURFACE_SHELL && voxel.matType > ADVECTION_LAST_FLUID) {
+        float latitude = ((float)cell[CELL_BAND] / (float)cell[CELL_BANDS] - 0.5f) * 180.0f;
+        float speedFactor = cos(latitude * ADVECTION_PI / 180.0f);
+        int shift = (int)(dt / ADVECTION_CELL_YEARS * speedFactor);
+
+        out.age += dt;
+        if (shift > 0) {
+            int n = cell[CELL_BAND_SIZE];
+            int lon = (int)voxelIndex - cell[CELL_BAND_START];
+            Voxel source = voxels[cell[CELL_BAND_START] + ((lon - shift) % n + n) % n];
+            if (source.matType > ADVECTION_LAST_FLUID) {
+                out.matType = source.matType;
+                out.density = source.density;
+                out.composition = source.composition;
+                out.age = source.age + dt;
+            }
+        }
+        out.velEast = ADVECTION_DRIFT_SPEED * speedFactor;
+    }
+
+    // Upwelling from the shell below
+    int innerIdx = neighborIndices[voxelIndex * 6u + NEIGHBOR_INNER];
+    if (innerIdx >= 0 && (uint)innerIdx < voxelCount && cells[innerIdx * CELL_INTS + CELL_SHELL] < ADVECTION_SURFACE_SHELL) {
+        Voxel inner = voxels[innerIdx];
+        if (inner.velR > ADVECTION_UPWELLING && inner.matType != MAT_AIR) {
+            out.temperature += (inner.temperature - out.temperature) * ADVECTION_MIX_FACTOR;
+            if (inner.matType == MAT_MAGMA) {
+                out.composition = (out.composition + inner.composition * ADVECTION_MIX_FACTOR) /
+                    (1.0f + ADVECTION_MIX_FACTOR);
+            }
+        }
+    }
+
+    advected[voxelIndex] = out;
+}
+
+// Copies one float per voxel into the given word of every voxel
+__kernel void storeVoxelField(__global float* voxelWords, __global const float* values, uint field, uint voxelCount) {
+    uint voxelIndex = get_global_id(0);
+    if (voxelIndex >= voxelCount) return;
+    voxelWords[voxelIndex * ` + strconv.Itoa(gpu.GPUVoxelSize/4) + `u + field] = values[voxelIndex];
+}
+`
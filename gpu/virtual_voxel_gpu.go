@@ -368,7 +368,7 @@ func (vvg *VirtualVoxelGPU) readBackGridGPU() {
 	}
 
 	// Mark as dirty for rendering
-	vvg.planet.MeshDirty = true
+	vvg.planet.MarkShellDirty(surfaceShell)
 }
 
 // GPUGridVoxel matches the shader GridVoxel structure
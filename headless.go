@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"worldgenerator/core"
+	"worldgenerator/gpu"
+	"worldgenerator/physics"
+)
+
+// headlessPollInterval is how often a headless run checks whether the
+// physics thread has finished the steps it was given
+const headlessPollInterval = 20 * time.Millisecond
+
+// headlessStepsPerRequest is how many physics steps a headless run hands the
+// physics thread at once, between progress reports and end condition checks
+const headlessStepsPerRequest = 10
+
+// runHeadless advances the planet on the threaded physics engine without a
+// window until years more have passed (0 = no limit), the end condition is
+// met, physics halts or a shutdown signal arrives. The engine stays paused
+// and is stepped in whole steps, so the run ends exactly on the target year
+// and a seeded run with fixed steps repeats bit for bit. It returns the
+// final planet once the physics thread has stopped, ready for exports
+func runHeadless(planet *core.VoxelPlanet, years float64, gpuCompute gpu.GPUCompute, simSpeed, stepYears float64,
+	endCondition *core.EndCondition, shutdown *shutdownSignal) *core.VoxelPlanet {
+	startYear := planet.Time
+	targetYear := startYear + years
+	if years > 0 {
+		fmt.Printf("Running headless to %.1f My...\n", targetYear/1e6)
+	} else {
+		fmt.Printf("Running headless until %v...\n", endCondition)
+	}
+
+	engine := physics.NewThreadedPhysicsInterface(planet, gpuCompute, simSpeed)
+	engine.SetPaused(true)
+	if stepYears > 0 {
+		engine.SetFixedTimestep(stepYears)
+	} else {
+		stepYears = simSpeed * engine.GetPhysicsUpdateInterval() // One variable step per tick
+	}
+
+	start := time.Now()
+	for !shutdown.Requested() {
+		request := stepYears * headlessStepsPerRequest
+		if years > 0 {
+			request = math.Min(request, targetYear-planet.Time)
+		}
+		if !engine.StepYears(request) {
+			break
+		}
+		for {
+			time.Sleep(headlessPollInterval)
+			if updated, ok := engine.Update(); ok {
+				planet = updated
+				break
+			}
+		}
+
+		if years > 0 {
+			printProgress(start, (planet.Time-startYear)/years, planet.Time)
+		} else {
+			fmt.Printf("\r%8.1f My   ", planet.Time/1e6)
+		}
+
+		if planet.Halted() {
+			fmt.Printf("\n❌ Physics halted at %.1f My: %v\n", planet.Time/1e6, planet.PhysicsFault)
+			break
+		}
+		if endCondition != nil && endCondition.Met(planet) {
+			fmt.Printf("\n🏁 %v met at %.1f My (%s = %g)\n", endCondition, planet.Time/1e6,
+				endCondition.Stat, endCondition.Value(planet))
+			break
+		}
+		if years > 0 && planet.Time >= targetYear {
+			fmt.Println()
+			break
+		}
+	}
+
+	engine.Stop()
+	planet = engine.GetCurrentPlanet()
+	fmt.Printf("✅ Headless run finished at %.2f My in %v\n", planet.Time/1e6, time.Since(start).Round(time.Millisecond))
+	return planet
+}
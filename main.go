@@ -1,353 +1,949 @@
-package main
-
-import (
-	"flag"
-	"fmt"
-	"log"
-	"runtime"
-	"time"
-
-	"worldgenerator/core"
-	"worldgenerator/gpu"
-	"worldgenerator/gpu/opencl"
-	"worldgenerator/physics"
-	"worldgenerator/rendering/opengl"
-)
-
-func main() {
-	runtime.LockOSThread()
-
-	// Parse command line flags
-	var (
-		radius        = flag.Float64("radius", 6371000, "Planet radius in meters")
-		shellCount    = flag.Int("shells", 20, "Number of spherical shells")
-		gpuType       = flag.String("gpu", "cpu", "GPU compute backend (metal, opencl, cuda, compute, cpu)")
-		width         = flag.Int("width", 1280, "Window width")
-		height        = flag.Int("height", 720, "Window height")
-		quiet         = flag.Bool("quiet", false, "Disable console output for smooth rendering")
-		seed          = flag.Int64("seed", 0, "Random seed for planet generation (0 = use current time)")
-		continents    = flag.Int("continents", 7, "Number of initial continental masses")
-		oceanFraction = flag.Float64("ocean", 0.7, "Fraction of surface covered by ocean (0.0-1.0)")
-		virtualVoxels = flag.Bool("virtual", false, "Use virtual voxel system (experimental)")
-	)
-	flag.Parse()
-
-	// Initialize random seed
-	actualSeed := *seed
-	if actualSeed == 0 {
-		actualSeed = time.Now().Unix()
-	}
-
-	fmt.Println("=== Voxel Planet Evolution Simulator (Native Renderer) ===")
-	fmt.Printf("Planet radius: %.0f m\n", *radius)
-	fmt.Printf("Shell count: %d\n", *shellCount)
-	fmt.Printf("GPU backend: %s\n", *gpuType)
-	fmt.Printf("Window: %dx%d\n", *width, *height)
-	fmt.Printf("Random seed: %d\n", actualSeed)
-	fmt.Printf("Continents: %d masses\n", *continents)
-	fmt.Printf("Ocean coverage: %.0f%%\n", *oceanFraction*100)
-
-	// Create voxel planet with randomization
-	genParams := core.PlanetGenerationParams{
-		Seed:               actualSeed,
-		ContinentCount:     *continents,
-		OceanFraction:      *oceanFraction,
-		MinContinentSize:   0.01, // 1% of surface minimum
-		MaxContinentSize:   0.15, // 15% of surface maximum
-		ContinentRoughness: 0.7,  // Moderately irregular shapes
-	}
-	planet := core.CreateRandomizedPlanet(*radius, *shellCount, genParams)
-
-	// Initialize virtual voxel system if requested
-	if *virtualVoxels {
-		fmt.Println("Initializing virtual voxel system...")
-		vvs := core.NewVirtualVoxelSystem(planet)
-		vvs.ConvertToVirtualVoxels()
-		fmt.Printf("Converting surface voxels to virtual voxels...\n")
-		vvs.CreateBonds()
-		planet.VirtualVoxelSystem = vvs
-		planet.UseVirtualVoxels = true
-		fmt.Printf("Created %d virtual voxels with %d bonds\n", len(vvs.VirtualVoxels), len(vvs.Bonds))
-	}
-
-	// Count voxels
-	totalVoxels := 0
-	for _, shell := range planet.Shells {
-		for _, latBand := range shell.Voxels {
-			totalVoxels += len(latBand)
-		}
-	}
-	fmt.Printf("Total voxels: %d (%.1f million)\n", totalVoxels, float64(totalVoxels)/1000000)
-	fmt.Printf("Data size: %.1f MB per frame\n", float64(totalVoxels*64)/(1024*1024))
-
-	// Initialize GPU compute
-	var gpuCompute gpu.GPUCompute
-	var err error
-
-	switch *gpuType {
-	case "metal":
-		if runtime.GOOS != "darwin" {
-			log.Fatal("Metal is only available on macOS")
-		}
-		// Metal compute
-		mc, err := gpu.NewMetalCompute(planet)
-		if err != nil {
-			log.Fatalf("Failed to initialize Metal compute: %v", err)
-		}
-		gpuCompute = mc
-	case "opencl":
-		gpuCompute, err = opencl.NewOpenCLCompute(planet)
-		if err != nil {
-			log.Fatalf("Failed to initialize OpenCL compute: %v", err)
-		}
-	case "cuda":
-		log.Fatal("CUDA support not yet implemented")
-	case "cpu", "compute":
-		// For compute shaders, we still need a fallback CPU compute for initialization
-		gpuCompute, err = gpu.NewCPUCompute(planet)
-		if err != nil {
-			log.Fatalf("Failed to initialize CPU compute: %v", err)
-		}
-	default:
-		log.Fatalf("Unknown GPU backend: %s", *gpuType)
-	}
-	defer gpuCompute.Cleanup()
-
-	// Create native OpenGL renderer
-	renderer, err := opengl.NewVoxelRenderer(*width, *height)
-	if err != nil {
-		log.Fatalf("Failed to create renderer: %v", err)
-	}
-	defer renderer.Terminate()
-
-	// Set planet reference for mouse picking
-	renderer.PlanetRef = planet
-
-	// Try to create GPU compute physics (OpenGL 4.3 compute shaders)
-	var computePhysics *gpu.ComputePhysics
-	useGPUPhysics := false
-	if *gpuType == "compute" {
-		cp, err := gpu.NewComputePhysics(planet)
-		if err == nil {
-			computePhysics = cp
-			useGPUPhysics = true
-			defer computePhysics.Release()
-			fmt.Println("✅ Using GPU compute shaders for physics")
-
-			// Initialize plate tectonics if available
-			// TODO: Fix this when physics package is properly integrated
-			// For now, skip plate tectonics initialization
-			fmt.Println("⚠️  GPU plate tectonics not yet integrated")
-		} else {
-			fmt.Printf("⚠️  Compute shader physics not available: %v\n", err)
-		}
-	}
-
-	// Try to create optimized GPU buffer manager
-	var gpuBufferMgr *gpu.WindowsGPUBufferManager
-	if runtime.GOOS == "windows" || runtime.GOOS == "linux" {
-		if mgr, err := gpu.NewWindowsGPUBufferManager(planet); err == nil {
-			gpuBufferMgr = mgr
-			defer gpuBufferMgr.Release()
-			fmt.Println("✅ Using optimized GPU buffer sharing")
-			if mgr.UsePersistent {
-				fmt.Println("✅ Using persistent mapped buffers (zero-copy)")
-			} else {
-				fmt.Println("⚠️  Using standard buffers (requires copy)")
-			}
-		} else {
-			fmt.Printf("❌ GPU buffer optimization not available: %v\n", err)
-		}
-	}
-
-	// Create shared buffer manager (fallback)
-	var sharedBuffers *gpu.SharedGPUBuffers
-	if gpuBufferMgr == nil {
-		sharedBuffers = gpu.NewSharedGPUBuffers(planet)
-		sharedBuffers.UpdateFromPlanet(planet)
-		// Create OpenGL buffers
-		renderer.CreateBuffers(sharedBuffers)
-	} else {
-		// Use optimized buffers
-		gpuBufferMgr.UpdateFromPlanet(planet)
-		renderer.SetOptimizedBuffers(gpuBufferMgr)
-		// Using texture mode
-		fmt.Println("Using texture rendering mode with optimized GPU buffers")
-	}
-
-	// Initialize voxel textures
-	renderer.UpdateVoxelTextures(planet)
-
-	// Virtual voxel system removed - using standard grid
-
-	// Simulation parameters
-	simSpeed := 1000000.0 // 1 million years per second
-	//speedMultiplier := 1.0 // Additional speed control
-	// lastTime := time.Now() // Not needed with threaded physics
-	frameCount := 0
-	totalFrameCount := 0 // Never reset this one
-	lastFPSTime := time.Now()
-
-	// Create continental drift tracker (removed - not needed with new approach)
-	// driftState := physics.NewContinentalDriftState(planet)
-
-	// Create accelerated physics params (removed - not needed with new approach)
-	// accelParams := physics.DefaultAcceleratedParams()
-
-	// Create threaded physics engine
-	// Use GPU compute physics if available, otherwise use the standard GPU compute interface
-	var physicsCompute gpu.GPUCompute
-	if useGPUPhysics && computePhysics != nil {
-		// Cast ComputePhysics to GPUCompute interface
-		physicsCompute = computePhysics
-		fmt.Println("✅ Physics engine using GPU compute shaders")
-	} else {
-		physicsCompute = gpuCompute
-	}
-	physicsEngine := physics.NewThreadedPhysicsInterface(planet, physicsCompute, simSpeed)
-	defer physicsEngine.Stop()
-
-	// Track last GPU update time
-	//var lastGPUUpdateTime float64 = -1
-
-	fmt.Println("\nControls:")
-	fmt.Println("  1-8: Change visualization (Material/Temp/Velocity/Age/Plates/Stress/SubPos/Elevation)")
-	fmt.Println("  X/Y/Z: Toggle cross-section view")
-	fmt.Println("  Mouse: Click and drag to rotate")
-	fmt.Println("  Scroll: Zoom in/out")
-	fmt.Println("  +/-: Speed up/slow down time (current: 1.0x)")
-	fmt.Println("  Shift+1 to 5: Set speed to 10x, 100x, 1000x, 10000x, 100000x")
-	fmt.Println("  0: Reset speed to 1x")
-	fmt.Println("  P: Pause/unpause simulation")
-	fmt.Println("  H: Create hotspot at cursor")
-	fmt.Println("  ESC: Exit")
-	fmt.Println("\nStarting simulation...")
-
-	// Main loop
-	for !renderer.ShouldClose() {
-		renderer.PollEvents()
-
-		// Calculate delta time
-		now := time.Now()
-		// dt := now.Sub(lastTime).Seconds() // Not used anymore
-		// lastTime = now // Not needed anymore
-
-		// Apply speed multiplier from renderer controls
-		currentSpeed := simSpeed * float64(renderer.SpeedMultiplier)
-
-		// Update physics engine with new speed
-		physicsEngine.UpdateSimSpeed(currentSpeed)
-
-		// Check if physics thread has new data (unless paused)
-		physicsUpdated := false
-		if !renderer.Paused {
-			if updatedPlanet, hasUpdate := physicsEngine.Update(); hasUpdate {
-				// Use the updated planet data from physics thread
-				planet = updatedPlanet
-				physicsUpdated = true
-				renderer.PlanetRef = planet // Update renderer's reference
-
-				// Debug output removed for cleaner display
-
-				// Don't apply additional acceleration - let physics handle it
-			}
-			// Time is already updated in physics thread
-		}
-
-		// Update GPU data only when physics updated
-		if physicsUpdated {
-			// Updates tracked internally
-
-			if gpuBufferMgr != nil {
-				// Using optimized GPU buffer manager
-				// Only include plate data when in plate visualization mode
-				if renderer.RenderMode == 4 {
-					// Get plate manager through the safe interface
-					plateManager := core.GetPlateManager(planet)
-					if plateManager != nil {
-						// Need to get the concrete type for the GPU buffer manager
-						if physicsSystem := core.GetPhysics(planet); physicsSystem != nil {
-							// Get the raw physics interface from planet
-							if vp, ok := planet.Physics.(*physics.VoxelPhysics); ok {
-								gpuBufferMgr.UpdateFromPlanetWithPlates(planet, vp.GetPlateManagerDirect())
-							} else {
-								gpuBufferMgr.UpdateFromPlanet(planet)
-							}
-						} else {
-							gpuBufferMgr.UpdateFromPlanet(planet)
-						}
-					} else {
-						gpuBufferMgr.UpdateFromPlanet(planet)
-					}
-				} else {
-					gpuBufferMgr.UpdateFromPlanet(planet)
-				}
-				// Ensure buffers are synced to GPU
-				gpuBufferMgr.BindBuffers()
-			} else {
-				// Fallback path - copy through shared buffers
-				if renderer.RenderMode == 4 {
-					// Get plate manager through the safe interface
-					plateManager := core.GetPlateManager(planet)
-					if plateManager != nil {
-						// Need to get the concrete type for the shared buffer update
-						if physicsSystem := core.GetPhysics(planet); physicsSystem != nil {
-							// Get the raw physics interface from planet
-							if vp, ok := planet.Physics.(*physics.VoxelPhysics); ok {
-								gpu.UpdateSharedBuffersWithPlates(sharedBuffers, planet, vp.GetPlateManagerDirect())
-							} else {
-								sharedBuffers.UpdateFromPlanet(planet)
-							}
-						} else {
-							sharedBuffers.UpdateFromPlanet(planet)
-						}
-					} else {
-						sharedBuffers.UpdateFromPlanet(planet)
-					}
-				} else {
-					sharedBuffers.UpdateFromPlanet(planet)
-				}
-				renderer.UpdateBuffers(sharedBuffers)
-			}
-
-			// Also update voxel textures when physics updated
-			renderer.UpdateVoxelTextures(planet)
-		}
-
-		// Render
-		renderer.Render()
-
-		// FPS counter and performance report
-		frameCount++
-		totalFrameCount++
-
-		// Update stats overlay and console output
-		if now.Sub(lastFPSTime).Seconds() >= 5.0 { // Update every 5 seconds
-			fps := float64(frameCount) / now.Sub(lastFPSTime).Seconds()
-			renderer.UpdateStats(fps)
-
-			// Also print to console if not quiet
-			if !*quiet {
-				// Calculate zoom level
-				cameraDistance := renderer.GetCameraDistance()
-				zoomLevel := float64(*radius) * 3.0 / float64(cameraDistance)
-				// Get physics performance
-				physicsTime := physicsEngine.GetPhysicsFrameTime() * 1000 // Convert to ms
-				// Output with zoom info
-				speedStr := ""
-				if renderer.SpeedMultiplier != 1.0 {
-					speedStr = fmt.Sprintf(" | Speed: %.0fx", renderer.SpeedMultiplier)
-				}
-				if renderer.Paused {
-					speedStr = " | PAUSED"
-				}
-				fmt.Printf("\rFPS: %.1f | Physics: %.1fms | Zoom: %.3f | Distance: %.0f km | Sim Time: %.1f My%s    ",
-					fps, physicsTime, zoomLevel, cameraDistance/1000.0, planet.Time/1000000, speedStr)
-			}
-			frameCount = 0
-			lastFPSTime = now
-		}
-	}
-
-	fmt.Println("\nShutting down...")
-}
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"worldgenerator/core"
+	"worldgenerator/gpu"
+	"worldgenerator/gpu/cuda"
+	"worldgenerator/gpu/opencl"
+	"worldgenerator/physics"
+	"worldgenerator/rendering/export"
+	"worldgenerator/rendering/opengl"
+	"worldgenerator/rendering/textures"
+	"worldgenerator/rendering/vulkan"
+)
+
+// deterministicStepYears is the fixed timestep -deterministic uses when
+// -physics-dt isn't given, one interactive tick at default speed
+const deterministicStepYears = 100000.0
+
+func main() {
+	// Parse command line flags
+	var (
+		radius        = flag.Float64("radius", 6371000, "Planet radius in meters")
+		shellCount    = flag.Int("shells", 20, "Number of spherical shells")
+		surfaceBands  = flag.Int("surface-lat-bands", 0, "Latitude bands of the surface and atmosphere shells alone, for finer coastlines without a finer mantle (0 = same as the interior)")
+		shellBands    = flag.String("shell-lat-bands", "", "Latitude bands of every shell from the core out, comma separated, e.g. a coarse mantle under a fine crust (empty = default schedule)")
+		gpuType       = flag.String("gpu", "cpu", "GPU compute backend (metal, opencl, cuda, compute, cpu)")
+		rendererType  = flag.String("renderer", "opengl", "Window renderer (opengl, vulkan); vulkan so far draws only the material and elevation views and needs -tags vulkan")
+		width         = flag.Int("width", 1280, "Window width")
+		height        = flag.Int("height", 720, "Window height")
+		quiet         = flag.Bool("quiet", false, "Disable console output for smooth rendering")
+		seed          = flag.Int64("seed", 0, "Random seed for planet generation (0 = use current time)")
+		continents    = flag.Int("continents", 7, "Number of initial continental masses")
+		oceanFraction = flag.Float64("ocean", 0.7, "Fraction of surface covered by ocean (0.0-1.0)")
+		virtualVoxels = flag.Bool("virtual", false, "Use virtual voxel system (experimental)")
+		glDebug       = flag.Bool("gl-debug", false, "Abort on the first OpenGL error")
+		physicsDt     = flag.Float64("physics-dt", 0, "Fixed physics timestep in years per step (0 = one variable step per tick)")
+		fixedTick     = flag.Bool("fixedstep", false, "Advance every physics tick by exactly the simulation speed × tick interval, so stalls such as window drags slow the simulation instead of making it jump")
+		deterministic = flag.Bool("deterministic", false, "Make a seeded run reproducible: CPU physics on a fixed timestep, so every step matches across runs (needs -seed)")
+		physicsCheck  = flag.Bool("physics-check", false, "Scan for NaN and out-of-range values after every physics phase and log the first bad voxel")
+		physicsHalt   = flag.Bool("physics-check-halt", false, "Stop the simulation at the first bad voxel (implies -physics-check)")
+		threads       = flag.Int("threads", 1, "Goroutines the CPU temperature, convection and advection passes split latitude bands across (results match any count)")
+		coreTemp      = flag.Float64("core-temp", 0, "Fixed core-mantle boundary temperature in K (0 = disabled)")
+		coreFlux      = flag.Float64("core-flux", 0, "Core-mantle boundary heat flux in W/m² (used when -core-temp is 0)")
+		slabDip       = flag.Float64("slab-dip", 45, "Subducting slab dip in degrees (sets trench-to-arc distance)")
+		maxUplift     = flag.Float64("max-uplift", 0, "Max crustal elevation change in m/year per step, e.g. 0.01 to stop spikes at high speed (0 = unlimited)")
+		spinup        = flag.Float64("spinup", 0, "Simulate headlessly to this year before opening the window (0 = start immediately)")
+		stepYears     = flag.Float64("step-years", 1, "Years advanced by the N key while paused")
+		hypsometry    = flag.String("hypsometry", "", "Write the final hypsometric curve to this CSV file on exit")
+		summaryPath   = flag.String("summary", "", "Write land and ocean fractions, plate count, temperature, extremes, water and time as JSON to this file on exit")
+		loadPath      = flag.String("load", "", "Resume from a planet saved with -save instead of generating a new one")
+		savePath      = flag.String("save", "", "Save the final planet to this file on exit, to resume later with -load")
+		heightmap     = flag.String("export-heightmap", "", "Write the surface elevation as a 16-bit grayscale PNG to this file and exit (after -spinup)")
+		gltfPath      = flag.String("export-gltf", "", "Write the final surface as a binary glTF (.glb) mesh to this file on exit")
+		heightmapSize = flag.Int("heightmap-width", 4096, "Width of the -export-heightmap image; the height is half of it")
+		headless      = flag.Bool("headless", false, "Run the simulation without a window, then write exports and exit (needs -years or -until)")
+		years         = flag.Float64("years", 0, "Years to simulate with -headless (0 = until the -until condition holds)")
+		until         = flag.String("until", "", "Shut down and write exports once a condition holds: year, seaLevel or continents compared to a number, e.g. until:continents==1")
+		legendDir     = flag.String("legend", "", "Write legend PNGs for the material, temperature, age and elevation views to this directory and exit")
+		maxPlates     = flag.Int("max-plates", 20, "Maximum number of tectonic plates (0 = default)")
+		forceConvect  = flag.Bool("force-convection", false, "Drive every plate with a baseline mantle flow so continents always drift visibly")
+		convectSpeed  = flag.Float64("convection-strength", 5, "Minimum plate speed in cm/year with -force-convection")
+		maxPlateSpeed = flag.Float64("max-plate-speed", core.DefaultMaxPlateVelocity, "Maximum plate speed in cm/year, reining in plates the forces spin up too far")
+		textureUpload = flag.String("texture-upload", "auto", "Voxel texture upload path (auto, copy, mapped)")
+		verifyUpload  = flag.Bool("verify-upload", false, "Check the first texture upload against the copy path")
+		greenhouse    = flag.Float64("greenhouse", physics.DefaultGreenhouseStrength, "Atmospheric greenhouse strength as longwave emissivity (0-1, -1 = none)")
+		radioHeat     = flag.Float64("radiogenic-heat", core.DefaultRadiogenicHeat, "Radioactive heating of the deep interior in K per year at year 0")
+		heatHalfLife  = flag.Float64("heat-half-life", 0, "Years for radioactive heat production to halve, so the interior cools over billions of years (0 = constant)")
+		plumeHeat     = flag.Float64("plume-heat", 1000, "Temperature boost in K at the center of mantle plumes injected with Shift+H")
+		axialTilt     = flag.Float64("axial-tilt", core.DefaultAxialTilt, "Axial tilt in degrees, sets the strength of the seasons (-1 = upright, no seasons)")
+		flattening    = flag.Float64("flattening", 0, "Polar flattening (a-b)/a, bulging the planet at the equator, e.g. 0.00335 for Earth (0 = sphere)")
+		dayLength     = flag.Float64("day-length", 0, "Hours the planet takes to spin once, setting how far the Coriolis force turns the winds, e.g. 8766 for a planet tidally locked over a year (0 = Earth's)")
+		spin          = flag.Float64("spin", 0, "Show the planet spinning on its tilted axis at this many degrees per second (0 = still)")
+		oceanClarity  = flag.Float64("ocean-transparency", 0.5, "How much shallow seafloor shows through water in material view (0-1)")
+		lavaGlow      = flag.Float64("glow", 1, "Brightness of hot magma and cooling lava glow (0 = off)")
+		paletteFile   = flag.String("material-palette", "", "JSON file of material view colors by material name, e.g. {\"granite\": [0.55, 0.5, 0.4]} (empty = natural defaults)")
+		supersample   = flag.Int("ssaa", 1, "Supersampling factor: render at N times the window resolution and downsample (1 = off, S cycles 1, 2 and 4)")
+		autoOrbit     = flag.Bool("auto-orbit", false, "Slowly orbit the camera around the planet (pauses on mouse input)")
+		orbitSpeed    = flag.Float64("orbit-speed", 3, "Auto-orbit speed in degrees per second")
+		fieldOfView   = flag.Float64("fov", opengl.DefaultFieldOfView, "Camera vertical field of view in degrees (- and = adjust it)")
+		orthographic  = flag.Bool("ortho", false, "Orthographic projection, the globe seen from infinitely far away (V toggles, Shift+V cycles every projection)")
+		mapView       = flag.String("map", "globe", "Start as a flat world map: globe, equirectangular or mollweide (M cycles)")
+		depthOfField  = flag.Bool("dof", false, "Depth of field blur for cinematic recordings (F toggles, click to focus)")
+		focusDistance = flag.Float64("focus-distance", 0, "Depth of field focus distance in meters from the camera (0 = the nearest surface)")
+		aperture      = flag.Float64("aperture", opengl.DefaultAperture, "Depth of field blur radius in pixels far beyond the focus")
+		interpolate   = flag.Bool("interpolate", true, "Blend temperature, elevation and velocity between physics updates for smooth motion; materials never blend (I toggles)")
+		seaTarget     = flag.Float64("sea-level-target", 0, "Forced sea level in meters for climate scenarios (used when -sea-level-rate > 0)")
+		seaRate       = flag.Float64("sea-level-rate", 0, "Rate sea level moves toward -sea-level-target in m per million years (0 = water conservation)")
+		snapshotCount = flag.Int("snapshots", 0, "Snapshots kept for scrubbing with the arrow keys while paused (0 = disabled)")
+		snapshotEvery = flag.Float64("snapshot-interval", 1e6, "Simulation years between snapshots")
+		title         = flag.String("title", opengl.DefaultWindowTitle, "Window title shown before the live stats")
+		recordDir     = flag.String("record", "", "Save rendered frames as numbered PNGs in this directory for a timelapse (empty = disabled)")
+		recordEvery   = flag.Int("record-every", 1, "Save every Nth rendered frame with -record")
+		httpAddr      = flag.String("http", "", "Serve analysis data over HTTP on this address, e.g. :8080 (empty = disabled)")
+	)
+	flag.Parse()
+
+	// Initialize random seed
+	actualSeed := *seed
+	if actualSeed == 0 {
+		actualSeed = time.Now().Unix()
+	}
+
+	fmt.Println("=== Voxel Planet Evolution Simulator (Native Renderer) ===")
+	fmt.Printf("Planet radius: %.0f m\n", *radius)
+	fmt.Printf("Shell count: %d\n", *shellCount)
+	fmt.Printf("GPU backend: %s\n", *gpuType)
+	fmt.Printf("Renderer: %s\n", *rendererType)
+	fmt.Printf("Window: %dx%d\n", *width, *height)
+	fmt.Printf("Random seed: %d\n", actualSeed)
+	fmt.Printf("Continents: %d masses\n", *continents)
+	fmt.Printf("Ocean coverage: %.0f%%\n", *oceanFraction*100)
+
+	// Create voxel planet with randomization
+	genParams := core.PlanetGenerationParams{
+		Seed:                  actualSeed,
+		ContinentCount:        *continents,
+		OceanFraction:         *oceanFraction,
+		MinContinentSize:      0.01, // 1% of surface minimum
+		MaxContinentSize:      0.15, // 15% of surface maximum
+		ContinentRoughness:    0.7,  // Moderately irregular shapes
+		CoreTemperature:       *coreTemp,
+		CoreHeatFlux:          *coreFlux,
+		SlabDip:               *slabDip,
+		MaxElevationRate:      *maxUplift,
+		MaxPlates:             *maxPlates,
+		MaxPlateVelocity:      *maxPlateSpeed,
+		GreenhouseStrength:    *greenhouse,
+		InitialRadiogenicHeat: *radioHeat,
+		HeatHalfLifeYears:     *heatHalfLife,
+		AxialTilt:             *axialTilt,
+		Flattening:            *flattening,
+		RotationPeriodHours:   *dayLength,
+		SurfaceLatBands:       *surfaceBands,
+	}
+
+	// Reject out-of-range settings now rather than failing deep inside generation
+	var scheduleErr error
+	genParams.LatBands, scheduleErr = core.ParseShellLatBands(*shellBands)
+	flagErrs := []error{core.ValidatePlanetSize(*radius, *shellCount), genParams.Validate(*shellCount), scheduleErr}
+	checkFlag := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			flagErrs = append(flagErrs, fmt.Errorf(format, args...))
+		}
+	}
+	checkFlag(*width > 0 && *height > 0, "-width and -height must be positive, got %dx%d", *width, *height)
+	checkFlag(*supersample >= 1, "-ssaa must be at least 1, got %d", *supersample)
+	checkFlag(*physicsDt >= 0, "-physics-dt can't be negative, got %g", *physicsDt)
+	checkFlag(*stepYears > 0, "-step-years must be positive, got %g", *stepYears)
+	checkFlag(*spinup >= 0, "-spinup can't be negative, got %g", *spinup)
+	checkFlag(*snapshotCount >= 0, "-snapshots can't be negative, got %d", *snapshotCount)
+	checkFlag(*snapshotEvery > 0, "-snapshot-interval must be positive, got %g", *snapshotEvery)
+	checkFlag(*seaRate >= 0, "-sea-level-rate can't be negative, got %g", *seaRate)
+	checkFlag(*oceanClarity >= 0 && *oceanClarity <= 1, "-ocean-transparency must be between 0 and 1, got %g", *oceanClarity)
+	checkFlag(*lavaGlow >= 0, "-glow can't be negative, got %g", *lavaGlow)
+	checkFlag(*focusDistance >= 0, "-focus-distance can't be negative, got %g", *focusDistance)
+	checkFlag(!*deterministic || *seed != 0, "-deterministic needs a -seed to reproduce")
+	checkFlag(!*deterministic || *gpuType == "cpu", "-deterministic runs physics on the CPU, got -gpu %s", *gpuType)
+	checkFlag(*years >= 0, "-years can't be negative, got %g", *years)
+	checkFlag(!*headless || *years > 0 || *until != "", "-headless needs -years or -until to know when to stop")
+	checkFlag(!*headless || *gpuType != "compute", "-gpu compute needs an OpenGL context, use another backend with -headless")
+	checkFlag(*rendererType == "opengl" || *rendererType == "vulkan", "-renderer must be opengl or vulkan, got %s", *rendererType)
+	checkFlag(*rendererType != "vulkan" || *gpuType != "compute", "-gpu compute needs an OpenGL context, use another backend with -renderer vulkan")
+	checkFlag(*heightmapSize >= 2, "-heightmap-width must be at least 2, got %d", *heightmapSize)
+	checkFlag(*recordEvery >= 1, "-record-every must be at least 1, got %d", *recordEvery)
+	checkFlag(*recordDir == "" || (*rendererType == "opengl" && !*headless), "-record needs an OpenGL window, not -headless or -renderer vulkan")
+	checkFlag(*aperture >= 0, "-aperture can't be negative, got %g", *aperture)
+	checkFlag(*plumeHeat > 0, "-plume-heat must be positive, got %g", *plumeHeat)
+	mapProjection, mapErr := opengl.ParseMapProjection(*mapView)
+	flagErrs = append(flagErrs, mapErr)
+	var palette opengl.MaterialPalette
+	if *paletteFile != "" {
+		var paletteErr error
+		palette, paletteErr = opengl.LoadMaterialPalette(*paletteFile)
+		flagErrs = append(flagErrs, paletteErr)
+	}
+	var endCondition *core.EndCondition
+	if *until != "" {
+		var untilErr error
+		endCondition, untilErr = core.ParseEndCondition(*until)
+		flagErrs = append(flagErrs, untilErr)
+	}
+	if err := errors.Join(flagErrs...); err != nil {
+		log.Fatalf("Invalid settings:\n%v", err)
+	}
+
+	// Legends depend only on the color tables and palette, so no planet is needed
+	if *legendDir != "" {
+		legends := []struct {
+			mode core.RenderMode
+			name string
+		}{
+			{core.RenderMaterial, "material"},
+			{core.RenderTemperature, "temperature"},
+			{core.RenderAge, "age"},
+			{core.RenderElevation, "elevation"},
+		}
+		for _, legend := range legends {
+			path := filepath.Join(*legendDir, "legend_"+legend.name+".png")
+			if err := core.ExportLegend(legend.mode, path, palette.LegendColor); err != nil {
+				log.Fatalf("Failed to write legend: %v", err)
+			}
+			fmt.Printf("✅ Legend written to %s\n", path)
+		}
+		return
+	}
+	if genParams.LatBands != nil {
+		fmt.Printf("Shell resolution: %v latitude bands from the core out\n", genParams.LatBands)
+	} else if *surfaceBands > 0 {
+		// The finest interior shell is the one just under the surface
+		schedule := core.DefaultShellLatBands(*shellCount, core.DefaultSurfaceBands, *surfaceBands)
+		fmt.Printf("Surface resolution: %d latitude bands over an interior of up to %d\n", *surfaceBands, schedule[len(schedule)-3])
+	}
+	if *forceConvect {
+		genParams.ConvectionForcing = *convectSpeed
+		fmt.Printf("Convection forcing: plates move at least %.1f cm/year\n", *convectSpeed)
+	}
+	if *coreTemp > 0 {
+		genParams.CoreBoundary = core.CoreBoundaryFixedTemperature
+		fmt.Printf("Core boundary: fixed temperature %.0f K\n", *coreTemp)
+	} else if *coreFlux > 0 {
+		genParams.CoreBoundary = core.CoreBoundaryFixedFlux
+		fmt.Printf("Core boundary: fixed heat flux %.3f W/m²\n", *coreFlux)
+	}
+	if *seaRate > 0 {
+		fmt.Printf("Sea level: forced toward %.0f m at %.0f m/My\n", *seaTarget, *seaRate)
+	}
+	physicsCheckMode := core.PhysicsCheckOff
+	if *physicsHalt {
+		physicsCheckMode = core.PhysicsCheckHalt
+	} else if *physicsCheck {
+		physicsCheckMode = core.PhysicsCheckLog
+	}
+	if physicsCheckMode != core.PhysicsCheckOff {
+		fmt.Println("Physics check: scanning every voxel after each physics phase (slow)")
+	}
+	// Variable steps follow the wall clock, so only fixed steps repeat
+	if *deterministic {
+		if *physicsDt == 0 {
+			*physicsDt = deterministicStepYears
+		}
+		fmt.Printf("Deterministic: seed %d, fixed %.0f-year steps\n", actualSeed, *physicsDt)
+	}
+	if endCondition != nil {
+		fmt.Printf("End condition: %v\n", endCondition)
+	}
+
+	// preparePlanet applies the run settings that aren't part of generation
+	preparePlanet := func(planet *core.VoxelPlanet) *core.VoxelPlanet {
+		planet.PhysicsCheck = physicsCheckMode
+		planet.WorkerThreads = *threads
+
+		// Climate scenario: drive sea level instead of conserving water
+		if *seaRate > 0 {
+			planet.SetSeaLevelTarget(*seaTarget, *seaRate)
+		}
+
+		// Initialize virtual voxel system if requested
+		if *virtualVoxels {
+			fmt.Println("Initializing virtual voxel system...")
+			vvs := core.NewVirtualVoxelSystem(planet)
+			vvs.ConvertToVirtualVoxels()
+			fmt.Printf("Converting surface voxels to virtual voxels...\n")
+			vvs.CreateBonds()
+			planet.VirtualVoxelSystem = vvs
+			planet.UseVirtualVoxels = true
+			fmt.Printf("Created %d virtual voxels with %d bonds\n", len(vvs.VirtualVoxels), len(vvs.Bonds))
+		}
+
+		return planet
+	}
+
+	// generatePlanet creates a planet from the command line settings
+	generatePlanet := func(seed int64) *core.VoxelPlanet {
+		params := genParams
+		params.Seed = seed
+		generated, err := core.CreateRandomizedPlanet(*radius, *shellCount, params)
+		if err != nil {
+			log.Fatalf("Failed to generate planet: %v", err)
+		}
+		return preparePlanet(generated)
+	}
+
+	var planet *core.VoxelPlanet
+	if *loadPath != "" {
+		loaded, err := core.LoadPlanet(*loadPath)
+		if err != nil {
+			log.Fatalf("Failed to load planet: %v", err)
+		}
+		fmt.Printf("Loaded planet from %s at %.2f My (seed %d)\n", *loadPath, loaded.Time/1e6, loaded.Seed())
+		planet = preparePlanet(loaded)
+	} else {
+		planet = generatePlanet(actualSeed)
+	}
+
+	// Count voxels
+	totalVoxels := 0
+	for _, shell := range planet.Shells {
+		for _, latBand := range shell.Voxels {
+			totalVoxels += len(latBand)
+		}
+	}
+	fmt.Printf("Total voxels: %d (%.1f million)\n", totalVoxels, float64(totalVoxels)/1000000)
+	fmt.Printf("Data size: %.1f MB per frame\n", float64(totalVoxels*64)/(1024*1024))
+
+	// newGPUCompute initializes the selected compute backend for a planet
+	newGPUCompute := func(planet *core.VoxelPlanet) (gpu.GPUCompute, error) {
+		switch *gpuType {
+		case "metal":
+			if runtime.GOOS != "darwin" {
+				return nil, fmt.Errorf("Metal is only available on macOS")
+			}
+			// Metal compute
+			mc, err := gpu.NewMetalCompute(planet)
+			if err != nil {
+				return nil, fmt.Errorf("failed to initialize Metal compute: %v", err)
+			}
+			return mc, nil
+		case "opencl":
+			oc, err := opencl.NewOpenCLCompute(planet)
+			if err != nil {
+				fmt.Printf("⚠️  %v, falling back to CPU compute\n", err)
+				return gpu.NewCPUCompute(planet)
+			}
+			return oc, nil
+		case "cuda":
+			cc, err := cuda.NewCUDACompute(planet)
+			if err != nil {
+				fmt.Printf("⚠️  %v, falling back to CPU compute\n", err)
+				return gpu.NewCPUCompute(planet)
+			}
+			return cc, nil
+		case "cpu", "compute":
+			// For compute shaders, we still need a fallback CPU compute for initialization
+			cc, err := gpu.NewCPUCompute(planet)
+			if err != nil {
+				return nil, fmt.Errorf("failed to initialize CPU compute: %v", err)
+			}
+			return cc, nil
+		default:
+			return nil, fmt.Errorf("unknown GPU backend: %s", *gpuType)
+		}
+	}
+
+	// Initialize GPU compute
+	gpuCompute, err := newGPUCompute(planet)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	defer func() { gpuCompute.Cleanup() }()
+
+	// spinUp ages a planet before it is shown
+	spinUp := func(planet *core.VoxelPlanet) {
+		if *spinup > planet.Time {
+			stepYears := 100000.0 // Matches one interactive tick at default speed
+			if *physicsDt > 0 {
+				stepYears = *physicsDt
+			}
+			runSpinup(planet, *spinup, stepYears, gpuCompute)
+		}
+	}
+	spinUp(planet)
+
+	// A heightmap needs only the generated planet, so no window is opened
+	if *heightmap != "" {
+		if err := textures.ExportHeightmap(planet, *heightmapSize, *heightmapSize/2, *heightmap); err != nil {
+			log.Fatalf("Failed to write heightmap: %v", err)
+		}
+		fmt.Printf("✅ Heightmap written to %s (sea level = %d)\n", *heightmap, textures.HeightmapSeaLevel)
+		return
+	}
+
+	// writeExports writes the files requested for the end of a run
+	writeExports := func(planet *core.VoxelPlanet) {
+		if *hypsometry != "" {
+			if err := core.WriteHypsometryCSV(*hypsometry, core.HypsometricCurve(planet, 100)); err != nil {
+				fmt.Printf("❌ %v\n", err)
+			} else {
+				fmt.Printf("✅ Hypsometric curve written to %s\n", *hypsometry)
+			}
+		}
+		if *summaryPath != "" {
+			if err := planet.WriteSummaryJSON(*summaryPath); err != nil {
+				fmt.Printf("❌ %v\n", err)
+			} else {
+				fmt.Printf("✅ Planet summary written to %s\n", *summaryPath)
+			}
+		}
+		if *gltfPath != "" {
+			if err := export.WriteGLTF(planet, *gltfPath); err != nil {
+				fmt.Printf("❌ %v\n", err)
+			} else {
+				fmt.Printf("✅ Surface mesh written to %s\n", *gltfPath)
+			}
+		}
+		if *savePath != "" {
+			if err := core.SavePlanet(planet, *savePath); err != nil {
+				fmt.Printf("❌ %v\n", err)
+			} else {
+				fmt.Printf("✅ Planet saved to %s\n", *savePath)
+			}
+		}
+	}
+
+	simSpeed := 1000000.0 // 1 million years per second
+
+	// Headless runs never touch OpenGL, so they work without a display
+	if *headless {
+		planet = runHeadless(planet, *years, gpuCompute, simSpeed, *physicsDt, endCondition, watchShutdownSignals())
+		writeExports(planet)
+		return
+	}
+
+	// GLFW and OpenGL calls must all come from the main thread
+	runtime.LockOSThread()
+
+	// The Vulkan renderer runs its own loop with the views it supports so far
+	if *rendererType == "vulkan" {
+		renderer, err := vulkan.NewVoxelRenderer(*width, *height)
+		if err != nil {
+			log.Fatalf("Failed to create renderer: %v", err)
+		}
+		renderer.WindowTitle = *title
+		planet = runVulkan(renderer, planet, gpuCompute, simSpeed, *physicsDt, *stepYears, *fixedTick, endCondition, *quiet,
+			watchShutdownSignals())
+		renderer.Terminate()
+		writeExports(planet)
+		return
+	}
+
+	// Create native OpenGL renderer
+	renderer, err := opengl.NewVoxelRenderer(*width, *height)
+	if err != nil {
+		log.Fatalf("Failed to create renderer: %v", err)
+	}
+	defer renderer.Terminate()
+
+	// Set planet reference for mouse picking
+	renderer.PlanetRef = planet
+	renderer.SetSeed(planet.Seed())
+	renderer.WindowTitle = *title
+
+	// Seafloor visibility through shallow water
+	renderer.OceanTransparency = float32(*oceanClarity)
+
+	// Magma and fresh basalt glow
+	renderer.EmissiveScale = float32(*lavaGlow)
+
+	// Material view colors
+	renderer.SetMaterialPalette(palette)
+
+	// Supersampled anti-aliasing
+	if *supersample > 1 {
+		if err := renderer.SetSupersampling(*supersample); err != nil {
+			fmt.Printf("⚠️  %v, rendering at native resolution\n", err)
+		} else {
+			fmt.Printf("✅ Supersampling %dx (%d samples per pixel)\n", *supersample, *supersample**supersample)
+		}
+	}
+
+	// Unattended demo orbit
+	renderer.AutoOrbitSpeed = float32(*orbitSpeed)
+	renderer.SetAutoOrbit(*autoOrbit)
+	if *spin != 0 {
+		renderer.SetSpin(float32(planet.AxialTilt), float32(*spin))
+	}
+	renderer.SetFieldOfView(float32(*fieldOfView))
+	renderer.SetOrthographic(*orthographic)
+	renderer.SetMapProjection(mapProjection)
+	renderer.InterpolateFrames = *interpolate
+	renderer.PlumeHeat = *plumeHeat
+	renderer.FocusDistance = float32(*focusDistance)
+	renderer.Aperture = float32(*aperture)
+	if err := renderer.SetDepthOfField(*depthOfField); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+	}
+
+	// Timelapse frame dump
+	if *recordDir != "" {
+		if err := renderer.StartRecording(*recordDir, *recordEvery); err != nil {
+			log.Fatalf("Failed to start recording: %v", err)
+		}
+		fmt.Printf("🎬 Recording to %s, one frame in %d\n", *recordDir, *recordEvery)
+	}
+
+	// OpenGL errors are fatal with -gl-debug, otherwise just reported
+	reportGLError := func(err error) {
+		if err == nil {
+			return
+		}
+		if *glDebug {
+			log.Fatalf("%v", err)
+		}
+		fmt.Printf("⚠️  %v\n", err)
+	}
+
+	// newComputePhysics starts the compute shader physics on a hidden context
+	// sharing the renderer's objects, for the physics goroutine to run on
+	newComputePhysics := func(planet *core.VoxelPlanet) (*gpu.ComputePhysics, error) {
+		context, err := renderer.NewSharedContext()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create compute context: %v", err)
+		}
+		cp, err := gpu.NewComputePhysics(planet, context)
+		if err != nil {
+			context.Destroy()
+			return nil, err
+		}
+		return cp, nil
+	}
+
+	// Try to create GPU compute physics (OpenGL 4.3 compute shaders)
+	var computePhysics *gpu.ComputePhysics
+	useGPUPhysics := false
+	if *gpuType == "compute" {
+		cp, err := newComputePhysics(planet)
+		if err == nil {
+			computePhysics = cp
+			useGPUPhysics = true
+			fmt.Println("✅ Using GPU compute shaders for physics")
+
+			// Initialize plate tectonics if available
+			// TODO: Fix this when physics package is properly integrated
+			// For now, skip plate tectonics initialization
+			fmt.Println("⚠️  GPU plate tectonics not yet integrated")
+		} else {
+			fmt.Printf("⚠️  Compute shader physics not available: %v\n", err)
+		}
+	}
+
+	defer func() {
+		if computePhysics != nil {
+			computePhysics.Release()
+		}
+	}()
+
+	// createBuffers allocates the voxel SSBOs for a planet
+	var gpuBufferMgr *gpu.WindowsGPUBufferManager
+	var sharedBuffers *gpu.SharedGPUBuffers
+	createBuffers := func(planet *core.VoxelPlanet) {
+		// Try to create optimized GPU buffer manager
+		if runtime.GOOS == "windows" || runtime.GOOS == "linux" {
+			if mgr, err := gpu.NewWindowsGPUBufferManager(planet); err == nil {
+				gpuBufferMgr = mgr
+				fmt.Println("✅ Using optimized GPU buffer sharing")
+				if mgr.UsePersistent {
+					fmt.Println("✅ Using persistent mapped buffers (zero-copy)")
+				} else {
+					fmt.Println("⚠️  Using standard buffers (requires copy)")
+				}
+			} else {
+				fmt.Printf("❌ GPU buffer optimization not available: %v\n", err)
+			}
+		}
+
+		// Create shared buffer manager (fallback)
+		if gpuBufferMgr == nil {
+			sharedBuffers = gpu.NewSharedGPUBuffers(planet)
+			sharedBuffers.UpdateFromPlanet(planet)
+			// Create OpenGL buffers
+			reportGLError(renderer.CreateBuffers(sharedBuffers))
+		} else {
+			// Use optimized buffers
+			gpuBufferMgr.UpdateFromPlanet(planet)
+			renderer.SetOptimizedBuffers(gpuBufferMgr)
+			// Using texture mode
+			fmt.Println("Using texture rendering mode with optimized GPU buffers")
+		}
+	}
+	createBuffers(planet)
+	defer func() {
+		if gpuBufferMgr != nil {
+			gpuBufferMgr.Release()
+		}
+	}()
+
+	// Initialize voxel textures
+	uploadMode, err := textures.ParseUploadMode(*textureUpload)
+	if err != nil {
+		fmt.Printf("⚠️  %v, using auto\n", err)
+	}
+	renderer.SetTextureUploadMode(uploadMode)
+	if renderer.UsingMappedTextureUpload() {
+		fmt.Println("✅ Using mapped pixel buffers for texture upload")
+	}
+	reportGLError(renderer.UpdateVoxelTextures(planet))
+	if *verifyUpload {
+		if err := renderer.VerifyTextureUpload(planet); err != nil {
+			fmt.Printf("❌ Texture upload mismatch: %v\n", err)
+		} else {
+			fmt.Println("✅ Texture upload matches the copy path")
+		}
+	}
+
+	// Virtual voxel system removed - using standard grid
+
+	// Simulation parameters
+	//speedMultiplier := 1.0 // Additional speed control
+	// lastTime := time.Now() // Not needed with threaded physics
+	frameCount := 0
+	totalFrameCount := 0 // Never reset this one
+	lastFPSTime := time.Now()
+
+	// Create continental drift tracker (removed - not needed with new approach)
+	// driftState := physics.NewContinentalDriftState(planet)
+
+	// Create accelerated physics params (removed - not needed with new approach)
+	// accelParams := physics.DefaultAcceleratedParams()
+
+	// startPhysics creates the threaded physics engine for a planet
+	// Use GPU compute physics if available, otherwise use the standard GPU compute interface
+	startPhysics := func(planet *core.VoxelPlanet) *physics.ThreadedPhysicsInterface {
+		var physicsCompute gpu.GPUCompute
+		if useGPUPhysics && computePhysics != nil {
+			// Cast ComputePhysics to GPUCompute interface
+			physicsCompute = computePhysics
+			fmt.Println("✅ Physics engine using GPU compute shaders")
+		} else {
+			physicsCompute = gpuCompute
+		}
+		engine := physics.NewThreadedPhysicsInterface(planet, physicsCompute, simSpeed)
+		if *physicsDt > 0 {
+			engine.SetFixedTimestep(*physicsDt)
+			fmt.Printf("Physics timestep: fixed %.0f years per step\n", *physicsDt)
+		}
+		if *fixedTick {
+			engine.SetFixedTick(engine.GetPhysicsUpdateInterval())
+			fmt.Printf("Physics ticks: fixed %.0f years each\n", simSpeed*engine.GetPhysicsUpdateInterval())
+		}
+		return engine
+	}
+	physicsEngine := startPhysics(planet)
+	defer func() { physicsEngine.Stop() }()
+
+	// Track last GPU update time
+	//var lastGPUUpdateTime float64 = -1
+
+	fmt.Println("\nControls (press ? in the window for this list):")
+	for _, line := range renderer.ControlsHelp() {
+		fmt.Println("  " + line)
+	}
+	fmt.Println("\nStarting simulation...")
+
+	// Optional HTTP analysis API
+	var api *apiServer
+	if *httpAddr != "" {
+		api = startAPIServer(*httpAddr)
+	}
+
+	// uploadPlanet copies a planet's voxels to the GPU buffers and textures
+	uploadPlanet := func(planet *core.VoxelPlanet) {
+		if gpuBufferMgr != nil {
+			// Using optimized GPU buffer manager
+			// Only include plate data when in plate visualization mode
+			if renderer.RenderMode == 4 {
+				// Get plate manager through the safe interface
+				plateManager := core.GetPlateManager(planet)
+				if plateManager != nil {
+					// Need to get the concrete type for the GPU buffer manager
+					if physicsSystem := core.GetPhysics(planet); physicsSystem != nil {
+						// Get the raw physics interface from planet
+						if vp, ok := planet.Physics.(*physics.VoxelPhysics); ok {
+							gpuBufferMgr.UpdateFromPlanetWithPlates(planet, vp.GetPlateManagerDirect())
+						} else {
+							gpuBufferMgr.UpdateFromPlanet(planet)
+						}
+					} else {
+						gpuBufferMgr.UpdateFromPlanet(planet)
+					}
+				} else {
+					gpuBufferMgr.UpdateFromPlanet(planet)
+				}
+			} else {
+				gpuBufferMgr.UpdateFromPlanet(planet)
+			}
+			// Ensure buffers are synced to GPU
+			gpuBufferMgr.BindBuffers()
+		} else {
+			// Fallback path - copy through shared buffers
+			if renderer.RenderMode == 4 {
+				// Get plate manager through the safe interface
+				plateManager := core.GetPlateManager(planet)
+				if plateManager != nil {
+					// Need to get the concrete type for the shared buffer update
+					if physicsSystem := core.GetPhysics(planet); physicsSystem != nil {
+						// Get the raw physics interface from planet
+						if vp, ok := planet.Physics.(*physics.VoxelPhysics); ok {
+							gpu.UpdateSharedBuffersWithPlates(sharedBuffers, planet, vp.GetPlateManagerDirect())
+						} else {
+							sharedBuffers.UpdateFromPlanet(planet)
+						}
+					} else {
+						sharedBuffers.UpdateFromPlanet(planet)
+					}
+				} else {
+					sharedBuffers.UpdateFromPlanet(planet)
+				}
+			} else {
+				sharedBuffers.UpdateFromPlanet(planet)
+			}
+			reportGLError(renderer.UpdateBuffers(sharedBuffers))
+		}
+
+		reportGLError(renderer.UpdateVoxelTextures(planet))
+	}
+
+	// Landmass count shown in the stats overlay and served over HTTP,
+	// refreshed with the overlay rather than per request
+	continentCount := len(core.IdentifyContinents(planet))
+	renderer.SetContinentCount(continentCount)
+
+	// Snapshot history for scrubbing while paused
+	var snapshots *physics.SnapshotStore
+	viewing := -1 // Index of the snapshot on screen, -1 = live planet
+	if *snapshotCount > 0 {
+		snapshots = physics.NewSnapshotStore(*snapshotCount, *snapshotEvery)
+		snapshots.Record(planet)
+		fmt.Printf("📼 Keeping %d snapshots every %.1f My (up to %.0f MB)\n", *snapshotCount, *snapshotEvery/1e6,
+			float64(*snapshotCount*physics.SnapshotBytes(planet))/(1024*1024))
+	}
+
+	// regenerate replaces the planet with a fresh one from a new seed while
+	// keeping the window: physics and the compute backend are shut down, the
+	// voxel buffers freed and reallocated, and physics restarted. Voxel
+	// textures do not depend on the planet and are reused as they are
+	regenerate := func(seed int64) {
+		physicsEngine.Stop()
+
+		oldBuffers := renderer.BufferIDs()
+		reportGLError(renderer.ReleaseBuffers(gpuBufferMgr))
+		gpuBufferMgr, sharedBuffers = nil, nil
+		if computePhysics != nil {
+			computePhysics.Release()
+			computePhysics = nil
+		}
+		gpuCompute.Cleanup()
+
+		planet = generatePlanet(seed)
+		gpuCompute, err = newGPUCompute(planet)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+		if useGPUPhysics {
+			if cp, err := newComputePhysics(planet); err == nil {
+				computePhysics = cp
+			} else {
+				fmt.Printf("⚠️  Compute shader physics not available: %v\n", err)
+				useGPUPhysics = false
+			}
+		}
+		spinUp(planet)
+
+		createBuffers(planet)
+		reportGLError(renderer.UpdateVoxelTextures(planet))
+		physicsEngine = startPhysics(planet)
+		renderer.ReferencePlate = 0 // Plate IDs don't carry over
+
+		if snapshots != nil {
+			snapshots = physics.NewSnapshotStore(*snapshotCount, *snapshotEvery)
+			snapshots.Record(planet)
+		}
+		viewing = -1
+
+		renderer.PlanetRef = planet
+		if *spin != 0 {
+			renderer.SetSpin(float32(planet.AxialTilt), float32(*spin)) // The new planet's own tilt
+		}
+		renderer.SetSeed(seed)
+		renderer.SetSimTime(planet.Time, "")
+		continentCount = len(core.IdentifyContinents(planet))
+		renderer.SetContinentCount(continentCount)
+		fmt.Printf("🌍 Regenerated planet with seed %d (voxel buffers %v freed, now %v)\n",
+			seed, oldBuffers, renderer.BufferIDs())
+	}
+
+	// Ctrl-C and SIGTERM close the window like the Esc key does
+	shutdown := watchShutdownSignals()
+
+	// endReached checks the -until condition against the live planet, which
+	// ends the run like closing the window
+	endReached := func() bool {
+		if endCondition == nil || !endCondition.Met(planet) {
+			return false
+		}
+		fmt.Printf("\n🏁 %v met at %.1f My (%s = %g)\n", endCondition, planet.Time/1e6,
+			endCondition.Stat, endCondition.Value(planet))
+		return true
+	}
+	ended := endReached() // Spin-up may already have got there
+
+	// Main loop
+	for !ended && !renderer.ShouldClose() && !shutdown.Requested() {
+		renderer.PollEvents()
+
+		// Calculate delta time
+		now := time.Now()
+		// dt := now.Sub(lastTime).Seconds() // Not used anymore
+		// lastTime = now // Not needed anymore
+
+		// Confirmed R presses start over with a new planet
+		if renderer.RegenerateRequested {
+			renderer.RegenerateRequested = false
+			regenerate(time.Now().UnixNano())
+		}
+
+		// Apply speed multiplier from renderer controls
+		currentSpeed := simSpeed * float64(renderer.SpeedMultiplier)
+
+		// Update physics engine with new speed
+		physicsEngine.UpdateSimSpeed(currentSpeed)
+
+		// Pause the physics thread with the renderer, hold its reference plate
+		// and forward step requests
+		physicsEngine.SetPaused(renderer.Paused)
+		physicsEngine.SetReferencePlate(renderer.ReferencePlate)
+		if renderer.StepRequested {
+			renderer.StepRequested = false
+			if physicsEngine.StepOnce() {
+				fmt.Println("Stepping one physics step")
+			}
+		}
+		if renderer.StepYearsRequested {
+			renderer.StepYearsRequested = false
+			if physicsEngine.StepYears(*stepYears) {
+				fmt.Printf("Stepping %.0f years\n", *stepYears)
+			}
+		}
+		for _, plume := range renderer.PlumeRequests {
+			if physicsEngine.InjectPlume(plume.Lat, plume.Lon, plume.TemperatureBoost) {
+				fmt.Printf("Mantle plume injected under %.1f°, %.1f° (+%.0f K)\n", plume.Lat, plume.Lon, plume.TemperatureBoost)
+			}
+		}
+		renderer.PlumeRequests = nil
+
+		// Check if physics thread has new data (only manual steps while paused)
+		physicsUpdated := false
+		if updatedPlanet, hasUpdate := physicsEngine.Update(); hasUpdate {
+			// Use the updated planet data from physics thread
+			planet = updatedPlanet
+			physicsUpdated = true
+			renderer.PlanetRef = planet // Update renderer's reference
+
+			// Freeze on the broken state so it can be inspected
+			if planet.Halted() && !renderer.Paused {
+				renderer.Paused = true
+				fmt.Printf("Simulation paused: %v\n", planet.PhysicsFault)
+			}
+
+			// Debug output removed for cleaner display
+
+			// Don't apply additional acceleration - let physics handle it
+		}
+		// Time is already updated in physics thread
+
+		// Update GPU data only when physics updated
+		if physicsUpdated {
+			uploadPlanet(planet)
+			ended = endReached()
+		}
+
+		// Left/right arrows step through recorded snapshots while paused
+		if snapshots != nil {
+			if physicsUpdated {
+				// New physics state always comes from the live planet
+				snapshots.Record(planet)
+				viewing = -1
+			}
+			if renderer.ScrubSteps != 0 && renderer.Paused && snapshots.Len() > 0 {
+				if viewing < 0 {
+					viewing = snapshots.Len()
+				}
+				viewing += renderer.ScrubSteps
+				viewing = max(0, min(viewing, snapshots.Len()-1))
+				shown := snapshots.At(viewing)
+				uploadPlanet(shown)
+				renderer.PlanetRef = shown
+				label := fmt.Sprintf("snapshot %d/%d", viewing+1, snapshots.Len())
+				renderer.SetSimTime(shown.Time, label)
+				fmt.Printf("⏪ %s at %.1f My\n", label, shown.Time/1e6)
+			} else if viewing >= 0 && !renderer.Paused {
+				// Resuming continues from the live planet, not the snapshot on screen
+				viewing = -1
+				uploadPlanet(planet)
+				renderer.PlanetRef = planet
+			}
+		}
+		renderer.ScrubSteps = 0
+
+		// Answer HTTP queries against the planet on screen
+		api.serve(planet, &renderer.ReferencePlate, continentCount)
+
+		// Render
+		reportGLError(renderer.Render())
+
+		// FPS counter and performance report
+		frameCount++
+		totalFrameCount++
+
+		// Update stats overlay and console output
+		if now.Sub(lastFPSTime).Seconds() >= 5.0 { // Update every 5 seconds
+			fps := float64(frameCount) / now.Sub(lastFPSTime).Seconds()
+			renderer.UpdateStats(fps)
+			timings := physicsEngine.GetTimings()
+			renderer.SetPhysicsTimings(timings)
+			continentCount = len(core.IdentifyContinents(planet))
+			renderer.SetContinentCount(continentCount)
+			renderer.UpdateTitle(planet.Time, fps)
+			if viewing < 0 {
+				renderer.SetSimTime(planet.Time, "")
+			}
+
+			// Also print to console if not quiet
+			if !*quiet {
+				// Calculate zoom level
+				cameraDistance := renderer.GetCameraDistance()
+				zoomLevel := float64(*radius) * 3.0 / float64(cameraDistance)
+				// Get physics performance
+				physicsTime := physicsEngine.GetPhysicsFrameTime() * 1000 // Convert to ms
+				// Output with zoom info
+				speedStr := ""
+				if renderer.SpeedMultiplier != 1.0 {
+					speedStr = fmt.Sprintf(" | Speed: %.0fx", renderer.SpeedMultiplier)
+				}
+				if renderer.Paused {
+					speedStr = " | PAUSED"
+				}
+				uploadTime := renderer.TextureUploadTime().Seconds() * 1000
+				fmt.Printf("\rFPS: %.1f | Physics: %.1fms (%s) | Upload: %.1fms | Zoom: %.3f | Distance: %.0f km | Sim Time: %.1f My | Continents: %d%s    ",
+					fps, physicsTime, timings, uploadTime, zoomLevel, cameraDistance/1000.0, planet.Time/1000000, continentCount, speedStr)
+			}
+			frameCount = 0
+			lastFPSTime = now
+		}
+	}
+
+	fmt.Println("\nShutting down...")
+
+	// Let the physics thread finish its step so exports see a settled planet
+	physicsEngine.Stop()
+	planet = physicsEngine.GetCurrentPlanet()
+	writeExports(planet)
+}
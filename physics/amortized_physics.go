@@ -106,6 +106,7 @@ func UpdateAmortizedPhysics(planet *core.VoxelPlanet, dt float64, state *Amortiz
 					vp.plates.IdentifyPlates()
 				}
 				vp.plates.UpdatePlateMotion(state.targetDeltaTime)
+				vp.plates.UpdatePlateTopology(state.targetDeltaTime)
 			}
 		}
 		state.currentPhase++
@@ -118,6 +119,9 @@ func UpdateAmortizedPhysics(planet *core.VoxelPlanet, dt float64, state *Amortiz
 				vp.mechanics.UpdateCollisions(state.targetDeltaTime)
 				vp.mechanics.UpdateContinentalBreakup(state.targetDeltaTime)
 			}
+			if vp, ok := planet.Physics.(*VoxelPhysics); ok && vp.advection != nil {
+				vp.advection.UpdateArcVolcanism(state.targetDeltaTime)
+			}
 		}
 		state.currentPhase++
 		
@@ -162,8 +166,7 @@ func updateTemperatureAmortized(planet *core.VoxelPlanet, dt float64, state *Amo
 				}
 				
 				// Get material properties
-				props := core.MaterialProperties[voxel.Type]
-				alpha := props.ThermalConductivity / (props.DefaultDensity * props.SpecificHeat)
+				alpha := core.MaterialDiffusivity(voxel.Type)
 				
 				// Calculate heat flow from neighbors
 				heatFlow := float32(0.0)
@@ -174,8 +177,7 @@ func updateTemperatureAmortized(planet *core.VoxelPlanet, dt float64, state *Amo
 					innerShell := &planet.Shells[shellIdx-1]
 					outerShell := &planet.Shells[shellIdx+1]
 					
-					if latIdx < len(innerShell.Voxels) && lonIdx < len(innerShell.Voxels[latIdx]) {
-						innerVoxel := &innerShell.Voxels[latIdx][lonIdx]
+					if innerVoxel := containingVoxel(planet, shellIdx, shellIdx-1, latIdx, lonIdx); innerVoxel != nil {
 						dr := shell.InnerRadius - innerShell.OuterRadius
 						if dr > 0 {
 							dT := innerVoxel.Temperature - voxel.Temperature
@@ -184,8 +186,7 @@ func updateTemperatureAmortized(planet *core.VoxelPlanet, dt float64, state *Amo
 						}
 					}
 					
-					if latIdx < len(outerShell.Voxels) && lonIdx < len(outerShell.Voxels[latIdx]) {
-						outerVoxel := &outerShell.Voxels[latIdx][lonIdx]
+					if outerVoxel := containingVoxel(planet, shellIdx, shellIdx+1, latIdx, lonIdx); outerVoxel != nil {
 						dr := outerShell.InnerRadius - shell.OuterRadius
 						if dr > 0 {
 							dT := outerVoxel.Temperature - voxel.Temperature
@@ -215,17 +216,8 @@ func updateTemperatureAmortized(planet *core.VoxelPlanet, dt float64, state *Amo
 		state.currentShell = 0
 		state.currentPhase++
 		
-		// Apply surface boundary conditions when done
-		if len(planet.Shells) > 0 {
-			surfaceShell := len(planet.Shells) - 1
-			for latIdx, latVoxels := range planet.Shells[surfaceShell].Voxels {
-				lat := core.GetLatitudeForBand(latIdx, planet.Shells[surfaceShell].LatBands)
-				for lonIdx := range latVoxels {
-					surfaceTemp := float32(288 - 50*absFloat32(float32(lat))/90.0)
-					planet.Shells[surfaceShell].Voxels[latIdx][lonIdx].Temperature = surfaceTemp
-				}
-			}
-		}
+		// Atmosphere sets the surface boundary temperature when done
+		UpdateAtmosphere(planet, dt)
 	}
 }
 
@@ -249,8 +241,7 @@ func updatePressureAmortized(planet *core.VoxelPlanet, dt float64, state *Amorti
 					voxel.Pressure = 101325 // Surface pressure
 				} else {
 					outerShell := &planet.Shells[shellIdx+1]
-					if latIdx < len(outerShell.Voxels) && lonIdx < len(outerShell.Voxels[latIdx]) {
-						outerVoxel := &outerShell.Voxels[latIdx][lonIdx]
+					if outerVoxel := containingVoxel(planet, shellIdx, shellIdx+1, latIdx, lonIdx); outerVoxel != nil {
 						dr := outerShell.InnerRadius - shell.OuterRadius
 						g := 9.8
 						dP := outerVoxel.Density * float32(g*dr)
@@ -269,7 +260,8 @@ func updatePressureAmortized(planet *core.VoxelPlanet, dt float64, state *Amorti
 	}
 }
 
-// updatePhaseTransitionsAmortized handles melting/solidification for a subset of shells
+// updatePhaseTransitionsAmortized handles melting/solidification and the
+// eclogite transition for a subset of shells
 func updatePhaseTransitionsAmortized(planet *core.VoxelPlanet, dt float64, state *AmortizedPhysicsState) {
 	endShell := state.currentShell + state.shellsPerFrame
 	if endShell > len(planet.Shells) {
@@ -310,6 +302,7 @@ func updatePhaseTransitionsAmortized(planet *core.VoxelPlanet, dt float64, state
 				}
 			}
 		}
+		updateEclogiteShell(planet, shellIdx)
 	}
 	
 	state.currentShell = endShell
@@ -389,11 +382,3 @@ func updateAgeAmortized(planet *core.VoxelPlanet, dt float64, state *AmortizedPh
 		state.currentPhase = PhaseComplete
 	}
 }
-
-// absFloat32 is a helper function for absolute value
-func absFloat32(x float32) float32 {
-	if x < 0 {
-		return -x
-	}
-	return x
-}
\ No newline at end of file
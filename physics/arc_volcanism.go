@@ -0,0 +1,115 @@
+package physics
+
+import (
+	"math"
+	"worldgenerator/core"
+)
+
+const (
+	// arcMeltingDepth is the slab depth where released fluids melt the
+	// overlying mantle wedge - arcs sit above the slab at ~100 km on Earth
+	arcMeltingDepth = 100e3
+
+	// defaultSlabDip is used when the planet does not set SlabDip
+	defaultSlabDip = 45.0
+
+	// arcTemperature is the minimum temperature of an active arc voxel (K)
+	arcTemperature = 1600.0
+)
+
+// ArcDistance returns the horizontal trench-to-arc distance in meters
+// Shallow slabs reach melting depth far inboard, steep slabs close to the trench
+func ArcDistance(slabDip float64) float64 {
+	if slabDip <= 0 || slabDip > 90 {
+		slabDip = defaultSlabDip
+	}
+	return arcMeltingDepth / math.Tan(slabDip*math.Pi/180.0)
+}
+
+// UpdateArcVolcanism places volcanic activity on the overriding plate
+// ArcDistance inboard of each subduction zone, forming arcs parallel to trenches
+func (va *VoxelAdvection) UpdateArcVolcanism(dt float64) {
+	surfaceShell := len(va.planet.Shells) - 2
+	if surfaceShell < 0 {
+		return
+	}
+	shell := &va.planet.Shells[surfaceShell]
+
+	// Convert arc distance to an angle along the surface
+	arcAngle := ArcDistance(va.planet.SlabDip) / shell.OuterRadius * 180.0 / math.Pi
+	midRadius := (shell.InnerRadius + shell.OuterRadius) / 2
+
+	for _, zone := range va.DetectSubductionZones() {
+		north, east, ok := overridingDirection(shell, zone)
+		if !ok {
+			continue
+		}
+
+		// Step from the trench toward the overriding plate
+		lat, lon := va.planet.VoxelLatLon(core.VoxelCoord{Shell: surfaceShell, Lat: zone.LatIdx, Lon: zone.LonIdx})
+		arcLat := math.Max(-90, math.Min(90, lat+north*arcAngle))
+		arcLon := lon + east*arcAngle/math.Max(math.Cos(lat*math.Pi/180.0), 0.01)
+
+		voxel, _ := va.planet.VoxelAtGeographic(arcLat, arcLon, va.planet.ShellAltitude(midRadius, arcLat))
+		if voxel == nil || voxel.Type == core.MatAir {
+			continue
+		}
+
+		// Hot, rising material reads as active volcanism
+		voxel.Temperature = float32(math.Max(float64(voxel.Temperature), arcTemperature))
+		voxel.VelR = float32(math.Max(float64(voxel.VelR), 0.000005*dt))
+	}
+}
+
+// arcNeighbor is a voxel next to a subduction zone and its direction from it
+type arcNeighbor struct {
+	lat, lon    int
+	north, east float64
+}
+
+// overridingDirection returns a unit north/east vector from a subduction zone
+// toward the plate riding over the slab
+// ok is false when no neighboring crust is overriding
+func overridingDirection(shell *core.SphericalShell, zone SubductionZone) (north, east float64, ok bool) {
+	slab := &shell.Voxels[zone.LatIdx][zone.LonIdx]
+	lonCount := len(shell.Voxels[zone.LatIdx])
+
+	// Neighbors with their direction from the zone
+	neighbors := []arcNeighbor{
+		{zone.LatIdx, (zone.LonIdx + 1) % lonCount, 0, 1},
+		{zone.LatIdx, (zone.LonIdx - 1 + lonCount) % lonCount, 0, -1},
+	}
+	for _, dlat := range []int{-1, 1} {
+		nlat := zone.LatIdx + dlat
+		if nlat < 0 || nlat >= len(shell.Voxels) {
+			continue
+		}
+		nlon := zone.LonIdx * len(shell.Voxels[nlat]) / lonCount
+		neighbors = append(neighbors, arcNeighbor{nlat, nlon, float64(dlat), 0})
+	}
+
+	for _, n := range neighbors {
+		voxel := &shell.Voxels[n.lat][n.lon]
+
+		// Overriding crust is not itself descending, and either belongs to
+		// another plate or is buoyant continental crust
+		if voxel.Type != core.MatGranite && voxel.Type != core.MatBasalt {
+			continue
+		}
+		if voxel.VelR < -0.0001 {
+			continue
+		}
+		if voxel.PlateID == slab.PlateID && voxel.Type != core.MatGranite {
+			continue
+		}
+
+		north += n.north
+		east += n.east
+	}
+
+	length := math.Hypot(north, east)
+	if length == 0 {
+		return 0, 0, false
+	}
+	return north / length, east / length, true
+}
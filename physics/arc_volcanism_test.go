@@ -0,0 +1,90 @@
+package physics
+
+import (
+	"math"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// newTrenchPlanet builds a north-south trench on the equator at lonIdx
+// Oceanic plate 1 to the west subducts under continental plate 2 to the east
+func newTrenchPlanet(slabDip float64, lonIdx int) (*core.VoxelPlanet, int) {
+	planet := core.CreateVoxelPlanet(6371000.0, 20)
+	planet.SlabDip = slabDip
+
+	shell := &planet.Shells[len(planet.Shells)-2]
+	latIdx := shell.LatBands / 2
+	for lon := range shell.Voxels[latIdx] {
+		voxel := &shell.Voxels[latIdx][lon]
+		voxel.Temperature = 288
+		voxel.VelR = 0
+		if lon < lonIdx {
+			voxel.Type = core.MatBasalt
+			voxel.PlateID = 1
+		} else {
+			voxel.Type = core.MatGranite
+			voxel.PlateID = 2
+		}
+	}
+
+	// Leading edge of the oceanic plate is descending
+	slab := &shell.Voxels[latIdx][lonIdx-1]
+	slab.VelR = -0.001
+
+	return planet, latIdx
+}
+
+// TestArcVolcanismOffsetFromTrench checks arcs form on the overriding plate, not at the trench
+func TestArcVolcanismOffsetFromTrench(t *testing.T) {
+	const trenchLon = 100
+
+	offsets := make(map[float64]int)
+	for _, dip := range []float64{30, 60} {
+		planet, latIdx := newTrenchPlanet(dip, trenchLon)
+		va := &VoxelAdvection{planet: planet}
+
+		if zones := va.DetectSubductionZones(); len(zones) != 1 {
+			t.Fatalf("dip %.0f: got %d subduction zones, want 1", dip, len(zones))
+		}
+
+		va.UpdateArcVolcanism(1000.0)
+
+		shell := &planet.Shells[len(planet.Shells)-2]
+		row := shell.Voxels[latIdx]
+		volcanic := -1
+		for lon := range row {
+			if row[lon].Temperature > 1500 && row[lon].VelR > 0 {
+				if volcanic >= 0 {
+					t.Fatalf("dip %.0f: more than one arc voxel (%d and %d)", dip, volcanic, lon)
+				}
+				volcanic = lon
+			}
+		}
+
+		if volcanic < 0 {
+			t.Fatalf("dip %.0f: no volcanism placed", dip)
+		}
+		slabLon := trenchLon - 1
+		if volcanic <= slabLon {
+			t.Errorf("dip %.0f: arc at lon %d, want inboard of the trench at %d", dip, volcanic, slabLon)
+		}
+		if row[slabLon].Temperature > 1500 {
+			t.Errorf("dip %.0f: subduction zone itself became volcanic", dip)
+		}
+
+		// Offset should match the slab geometry to within one cell
+		cellWidth := 2 * math.Pi * shell.OuterRadius / float64(len(row))
+		want := ArcDistance(dip) / cellWidth
+		got := float64(volcanic - slabLon)
+		if got < want-1 || got > want+1 {
+			t.Errorf("dip %.0f: arc %.0f cells from trench, want ~%.1f", dip, got, want)
+		}
+		offsets[dip] = volcanic - slabLon
+	}
+
+	if offsets[30] <= offsets[60] {
+		t.Errorf("shallow slab arc (%d cells) should be farther inboard than steep slab arc (%d cells)",
+			offsets[30], offsets[60])
+	}
+}
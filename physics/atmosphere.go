@@ -0,0 +1,279 @@
+package physics
+
+import (
+	"math"
+
+	"worldgenerator/core"
+)
+
+const (
+	// solarIrradiance is the solar constant at the planet's orbit (W/m²)
+	solarIrradiance = 1361.0
+
+	// stefanBoltzmannConstant in W/(m²·K⁴)
+	stefanBoltzmannConstant = 5.67e-8
+
+	// DefaultGreenhouseStrength is the longwave emissivity of the atmosphere
+	// layer that brings Earth's 255 K effective temperature to ~288 K
+	DefaultGreenhouseStrength = 0.78
+
+	// Bond albedos of open surfaces and ice sheets
+	surfaceAlbedo = 0.3
+	iceAlbedo     = 0.6
+
+	// maxIceAlbedoCooling caps how much colder ice's albedo makes the air
+	// over it (K), standing in for the heat the wind brings from bare ground
+	// and open water nearby, so ice reinforces itself without spreading
+	// into a snowball or outlasting any warming
+	maxIceAlbedoCooling = 10.0
+
+	// airRelaxationTime is how quickly air temperature adjusts to radiative
+	// equilibrium, in years
+	airRelaxationTime = 0.1
+
+	// airMixingRate is the fraction of a cell's temperature difference with
+	// its neighbors exchanged per year by eddies and weather systems
+	// Weather mixes air within days, so at yearly steps this saturates at maxAirMixing
+	airMixingRate = 2.0
+	maxAirMixing  = 0.5
+
+	// seasonalResponse is the fraction of the radiative seasonal temperature
+	// swing that is realized; the ocean mixed layer and ground store and
+	// release the rest. It gives high latitudes Earth-like swings of ~40 K
+	seasonalResponse = 0.25
+
+	// Water freezes below freezingPoint-iceHysteresis and melts above freezingPoint
+	freezingPoint = 273.15
+	iceHysteresis = 2.0
+)
+
+// annualInsolation returns the annual mean top-of-atmosphere insolation at a
+// latitude in degrees, using the standard second Legendre polynomial fit
+// The fit's 0.482 is for Earth's tilt; it scales with P2(cos tilt), so a
+// steeper axis evens out the equator-to-pole contrast and past ~55° reverses it
+func annualInsolation(lat, axialTilt float64) float64 {
+	x := math.Sin(lat * math.Pi / 180.0)
+	p2 := (3*x*x - 1) / 2
+	s2 := 0.482 * legendreP2(axialTilt) / legendreP2(core.DefaultAxialTilt)
+	return solarIrradiance / 4 * (1 - s2*p2)
+}
+
+// legendreP2 returns P2(cos θ) for an angle in degrees
+func legendreP2(deg float64) float64 {
+	c := math.Cos(deg * math.Pi / 180.0)
+	return (3*c*c - 1) / 2
+}
+
+// solarDeclination returns the latitude in degrees where the sun stands
+// overhead at noon, for a planet tilted axialTilt degrees at the given time in
+// years. Orbits are circular and years start at the northern spring equinox
+func solarDeclination(axialTilt, years float64) float64 {
+	phase := 2 * math.Pi * (years - math.Floor(years))
+	tilt := axialTilt * math.Pi / 180.0
+	return math.Asin(math.Sin(tilt)*math.Sin(phase)) * 180.0 / math.Pi
+}
+
+// dailyInsolation returns the day-averaged top-of-atmosphere insolation at a
+// latitude with the sun at a declination, both in degrees. Within the polar
+// circles it is zero through the polar night and continuous in polar summer
+func dailyInsolation(lat, declination float64) float64 {
+	phi := lat * math.Pi / 180.0
+	delta := declination * math.Pi / 180.0
+
+	// Hour angle of sunset, clamped for polar day and night
+	cosH := math.Max(-1, math.Min(1, -math.Tan(phi)*math.Tan(delta)))
+	h0 := math.Acos(cosH)
+	return solarIrradiance / math.Pi * (h0*math.Sin(phi)*math.Sin(delta) + math.Cos(phi)*math.Cos(delta)*math.Sin(h0))
+}
+
+// SurfaceEquilibriumTemperature returns the radiative equilibrium surface
+// temperature (K) below a single-layer atmosphere of longwave emissivity
+// greenhouse at latitude lat (degrees) for a surface of the given albedo,
+// averaged over a year on a planet with Earth's tilt
+func SurfaceEquilibriumTemperature(lat, albedo, greenhouse float64) float64 {
+	return equilibriumTemperature(annualInsolation(lat, core.DefaultAxialTilt), albedo, greenhouse)
+}
+
+// equilibriumTemperature returns the radiative equilibrium surface
+// temperature (K) for a top-of-atmosphere insolation in W/m²
+func equilibriumTemperature(insolation, albedo, greenhouse float64) float64 {
+	greenhouse = math.Max(0, math.Min(1, greenhouse))
+	absorbed := insolation * (1 - albedo)
+	return math.Pow(absorbed/(stefanBoltzmannConstant*(1-greenhouse/2)), 0.25)
+}
+
+// seasonalEquilibriumTemperature returns the equilibrium surface temperature
+// (K) at latitude lat (degrees) with the sun at a declination. The season
+// perturbs the annual equilibrium through the linearized radiative response,
+// scaled by seasonalResponse, rather than solving the instantaneous balance,
+// which would freeze the polar night to absolute zero
+func seasonalEquilibriumTemperature(lat, declination, axialTilt, albedo, greenhouse float64) float64 {
+	greenhouse = math.Max(0, math.Min(1, greenhouse))
+	annual := annualInsolation(lat, axialTilt)
+	mean := equilibriumTemperature(annual, albedo, greenhouse)
+	anomaly := (dailyInsolation(lat, declination) - annual) * (1 - albedo)
+	response := 4 * stefanBoltzmannConstant * math.Pow(mean, 3) * (1 - greenhouse/2)
+	return mean + seasonalResponse*anomaly/response
+}
+
+// greenhouseStrength returns the planet's greenhouse emissivity, the Earth
+// default when it is unset and none when switched off with core.GreenhouseOff
+func greenhouseStrength(planet *core.VoxelPlanet) float64 {
+	if planet.GreenhouseStrength == core.GreenhouseOff {
+		return 0
+	}
+	if planet.GreenhouseStrength <= 0 {
+		return DefaultGreenhouseStrength
+	}
+	return planet.GreenhouseStrength
+}
+
+// UpdateAtmosphere steps the atmosphere shell, the outermost shell of air
+// Air relaxes toward the radiative equilibrium of the surface below it, is
+// carried and mixed horizontally, and sets the temperature of open water and
+// ice beneath it, freezing and thawing the surface
+// Air voxels are skipped by the solid-earth diffusion and advection passes,
+// so this is the only place their temperature changes
+func UpdateAtmosphere(planet *core.VoxelPlanet, dt float64) {
+	if len(planet.Shells) < 2 || dt <= 0 {
+		return
+	}
+	air := &planet.Shells[len(planet.Shells)-1]
+	surface := &planet.Shells[len(planet.Shells)-2]
+	greenhouse := greenhouseStrength(planet)
+	relax := 1 - math.Exp(-dt/airRelaxationTime)
+
+	// Steps of a year or more span every season, so they see the annual mean
+	seasonal := dt < 1
+	declination := solarDeclination(planet.AxialTilt, planet.Time)
+
+	// Radiative adjustment toward the column's equilibrium
+	for latIdx := range air.Voxels {
+		lat := core.GetLatitudeForBand(latIdx, air.LatBands)
+		for lonIdx := range air.Voxels[latIdx] {
+			voxel := &air.Voxels[latIdx][lonIdx]
+			equilibrium := func(albedo float64) float64 {
+				if seasonal {
+					return seasonalEquilibriumTemperature(lat, declination, planet.AxialTilt, albedo, greenhouse)
+				}
+				return equilibriumTemperature(annualInsolation(lat, planet.AxialTilt), albedo, greenhouse)
+			}
+			target := equilibrium(surfaceAlbedo)
+			if below := columnBelow(air, surface, latIdx, lonIdx); below != nil && below.SurfaceType() == core.MatIce {
+				target = math.Max(equilibrium(iceAlbedo), target-maxIceAlbedoCooling)
+			}
+			voxel.Temperature += float32((target - float64(voxel.Temperature)) * relax)
+		}
+	}
+
+	advectAirTemperature(air, dt)
+	mixAirTemperature(air, dt)
+
+	// Oceans and ice sheets take the temperature of the air above them
+	for latIdx := range air.Voxels {
+		for lonIdx := range air.Voxels[latIdx] {
+			below := columnBelow(air, surface, latIdx, lonIdx)
+			if below == nil || (below.Type != core.MatWater && below.Type != core.MatIce) {
+				continue
+			}
+			airTemp := air.Voxels[latIdx][lonIdx].Temperature
+			below.Temperature += (airTemp - below.Temperature) * float32(relax)
+
+			if below.Type == core.MatWater && below.Temperature < freezingPoint-iceHysteresis {
+				below.Type = core.MatIce
+				below.Density = core.MaterialProperties[core.MatIce].DefaultDensity
+			} else if below.Type == core.MatIce && below.Temperature > freezingPoint {
+				below.Type = core.MatWater
+				below.Density = core.MaterialProperties[core.MatWater].DefaultDensity
+			}
+		}
+	}
+}
+
+// columnBelow returns the surface voxel under an air voxel
+func columnBelow(air, surface *core.SphericalShell, latIdx, lonIdx int) *core.VoxelMaterial {
+	if len(surface.Voxels) == 0 {
+		return nil
+	}
+	surfLat := latIdx * len(surface.Voxels) / len(air.Voxels)
+	surfLon := lonIdx * len(surface.Voxels[surfLat]) / len(air.Voxels[latIdx])
+	return &surface.Voxels[surfLat][surfLon]
+}
+
+// advectAirTemperature carries heat with the wind using first-order upwinding
+// The Courant number is capped at one cell per step to stay stable at long dt
+func advectAirTemperature(air *core.SphericalShell, dt float64) {
+	radius := (air.InnerRadius + air.OuterRadius) / 2
+	seconds := dt * secondsPerYear
+	dLat := math.Pi * radius / float64(air.LatBands)
+
+	newTemps := make([][]float32, len(air.Voxels))
+	for latIdx := range air.Voxels {
+		row := air.Voxels[latIdx]
+		newTemps[latIdx] = make([]float32, len(row))
+		lat := core.GetLatitudeForBand(latIdx, air.LatBands) * math.Pi / 180.0
+		dLon := 2 * math.Pi * radius * math.Max(math.Cos(lat), 0.01) / float64(len(row))
+
+		for lonIdx := range row {
+			voxel := &row[lonIdx]
+			temp := voxel.Temperature
+
+			// Zonal transport
+			courant := math.Min(math.Abs(float64(voxel.VelEast))*seconds/dLon, 1)
+			upwind := (lonIdx - 1 + len(row)) % len(row)
+			if voxel.VelEast < 0 {
+				upwind = (lonIdx + 1) % len(row)
+			}
+			temp += float32(courant) * (row[upwind].Temperature - voxel.Temperature)
+
+			// Meridional transport
+			courant = math.Min(math.Abs(float64(voxel.VelNorth))*seconds/dLat, 1)
+			upLat := latIdx - 1 // South of this band
+			if voxel.VelNorth < 0 {
+				upLat = latIdx + 1
+			}
+			if courant > 0 && upLat >= 0 && upLat < len(air.Voxels) {
+				upLon := lonIdx * len(air.Voxels[upLat]) / len(row)
+				temp += float32(courant) * (air.Voxels[upLat][upLon].Temperature - voxel.Temperature)
+			}
+
+			newTemps[latIdx][lonIdx] = temp
+		}
+	}
+
+	for latIdx := range air.Voxels {
+		for lonIdx := range air.Voxels[latIdx] {
+			air.Voxels[latIdx][lonIdx].Temperature = newTemps[latIdx][lonIdx]
+		}
+	}
+}
+
+// mixAirTemperature spreads heat between neighboring air cells, carrying
+// warmth from the tropics toward the poles
+func mixAirTemperature(air *core.SphericalShell, dt float64) {
+	mix := math.Min(airMixingRate*dt, maxAirMixing)
+
+	newTemps := make([][]float32, len(air.Voxels))
+	for latIdx := range air.Voxels {
+		row := air.Voxels[latIdx]
+		newTemps[latIdx] = make([]float32, len(row))
+		for lonIdx := range row {
+			coord := core.VoxelCoord{Lat: latIdx, Lon: lonIdx}
+			neighbors := core.ShellNeighbors(air, coord)
+
+			sum := 0.0
+			for _, n := range neighbors {
+				sum += float64(air.Voxels[n.Lat][n.Lon].Temperature)
+			}
+			temp := float64(row[lonIdx].Temperature)
+			mean := sum / float64(len(neighbors))
+			newTemps[latIdx][lonIdx] = float32(temp + (mean-temp)*mix)
+		}
+	}
+
+	for latIdx := range air.Voxels {
+		for lonIdx := range air.Voxels[latIdx] {
+			air.Voxels[latIdx][lonIdx].Temperature = newTemps[latIdx][lonIdx]
+		}
+	}
+}
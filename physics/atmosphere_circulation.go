@@ -0,0 +1,188 @@
+package physics
+
+import (
+	"math"
+
+	"worldgenerator/core"
+)
+
+const (
+	// DefaultCirculationInterval is how many physics steps the threaded
+	// engine runs between wind solves. The cells follow the zonal mean
+	// temperature, which changes far slower than a step
+	DefaultCirculationInterval = 10
+
+	// meridionalWindSpeed is the peak surface flow in m/s toward or away
+	// from the equator within each cell
+	meridionalWindSpeed = 1.0
+
+	// tropopauseHeight is the depth in m of the overturning Hadley cell
+	tropopauseHeight = 15000.0
+
+	// surfaceGravity in m/s² for the Hadley cell's buoyancy
+	surfaceGravity = 9.81
+
+	// Bounds on the Hadley cell's edge in degrees from the thermal equator
+	minHadleyExtent = 15.0
+	maxHadleyExtent = 40.0
+
+	// Wind speeds scale with the equator-to-pole contrast against Earth's,
+	// within these factors
+	minWindScale = 0.25
+	maxWindScale = 2.0
+)
+
+// AtmosphereSolver sets the prevailing winds of the atmosphere shell, the
+// outermost shell, from its temperature. The zonal mean air temperature
+// places the thermal equator and sets the width of the Hadley cells; the
+// Ferrel and polar cells split the rest of each hemisphere. Winds are stored
+// as VelNorth/VelEast on the air voxels in m/s, where UpdateAtmosphere and
+// UpdatePrecipitation pick them up
+type AtmosphereSolver struct {
+	Interval int // Physics steps between solves (<= 1 = every step)
+
+	// Steps since each planet was last solved; the threaded engine steps
+	// its two buffers in turn and each needs its own winds
+	steps map[*core.VoxelPlanet]int
+}
+
+// NewAtmosphereSolver creates a solver running every DefaultCirculationInterval steps
+func NewAtmosphereSolver() *AtmosphereSolver {
+	return &AtmosphereSolver{
+		Interval: DefaultCirculationInterval,
+		steps:    make(map[*core.VoxelPlanet]int),
+	}
+}
+
+// Update counts one physics step on planet and solves its winds when the
+// interval has passed, or on the first call. Returns whether it solved
+func (s *AtmosphereSolver) Update(planet *core.VoxelPlanet) bool {
+	count, seen := s.steps[planet]
+	if seen && count+1 < s.Interval {
+		s.steps[planet] = count + 1
+		return false
+	}
+	s.steps[planet] = 0
+	s.Solve(planet)
+	return true
+}
+
+// Solve recomputes the winds of every air voxel from the current air temperatures
+func (s *AtmosphereSolver) Solve(planet *core.VoxelPlanet) {
+	if len(planet.Shells) < 2 {
+		return
+	}
+	air := &planet.Shells[len(planet.Shells)-1]
+	if air.LatBands < 2 {
+		return
+	}
+
+	// Zonal mean temperature per band
+	means := make([]float64, len(air.Voxels))
+	warmest := 0
+	for latIdx, band := range air.Voxels {
+		for _, voxel := range band {
+			means[latIdx] += float64(voxel.Temperature)
+		}
+		if len(band) > 0 {
+			means[latIdx] /= float64(len(band))
+		}
+		if means[latIdx] > means[warmest] {
+			warmest = latIdx
+		}
+	}
+
+	// The thermal equator is the peak of a parabola through the warmest band
+	// and its neighbors, so two equally warm bands put it between them
+	thermalEquator := core.GetLatitudeForBand(warmest, air.LatBands)
+	if warmest > 0 && warmest < len(means)-1 {
+		south, peak, north := means[warmest-1], means[warmest], means[warmest+1]
+		if curvature := south - 2*peak + north; curvature < 0 {
+			thermalEquator += 0.5 * (south - north) / curvature * 180 / float64(air.LatBands-1)
+		}
+	}
+	warm := means[warmest]
+	cold := (means[0] + means[len(means)-1]) / 2
+
+	hadley := hadleyExtent(planet, warm, cold)
+	ferrel := (hadley + 90) / 2
+
+	reference := SurfaceEquilibriumTemperature(0, surfaceAlbedo, DefaultGreenhouseStrength) -
+		SurfaceEquilibriumTemperature(90, surfaceAlbedo, DefaultGreenhouseStrength)
+	scale := math.Max(minWindScale, math.Min(maxWindScale, (warm-cold)/reference))
+
+	rotation := planet.AngularVelocity()
+	for latIdx := range air.Voxels {
+		lat := core.GetLatitudeForBand(latIdx, air.LatBands)
+		east, north := cellWinds(lat, thermalEquator, hadley, ferrel)
+		east, north = deflectWind(east, north, rotation)
+		for lonIdx := range air.Voxels[latIdx] {
+			voxel := &air.Voxels[latIdx][lonIdx]
+			voxel.VelEast = float32(east * scale)
+			voxel.VelNorth = float32(north * scale)
+		}
+	}
+}
+
+// hadleyExtent returns the poleward edge of the Hadley cell in degrees from
+// the thermal equator, from the Held-Hou angular momentum model: cells widen
+// with a stronger equator-to-pole contrast and narrow on faster spinning or
+// larger planets
+func hadleyExtent(planet *core.VoxelPlanet, warm, cold float64) float64 {
+	rotation := planet.AngularVelocity()
+	if warm <= 0 || warm <= cold {
+		return minHadleyExtent
+	}
+	contrast := (warm - cold) / warm
+	extent := math.Sqrt(5.0/3.0*surfaceGravity*tropopauseHeight*contrast) / (rotation * planet.Radius)
+	return math.Max(minHadleyExtent, math.Min(maxHadleyExtent, extent*180/math.Pi))
+}
+
+// cellWinds returns the eastward and northward surface wind in m/s at lat
+// for cells edged hadley and ferrel degrees from the thermal equator. Each
+// hemisphere is stretched so its cells span the thermal equator to the pole.
+// Winds peak mid-cell and calm at the edges: the doldrums, horse latitudes
+// and polar front
+func cellWinds(lat, thermalEquator, hadley, ferrel float64) (east, north float64) {
+	poleward := 1.0 // Direction of the nearer pole
+	span := 90 - thermalEquator
+	if lat < thermalEquator {
+		poleward = -1
+		span = 90 + thermalEquator
+	}
+	if span <= 0 {
+		return 0, 0
+	}
+	psi := math.Abs(lat-thermalEquator) / span * 90
+
+	// Surface flow in each cell: trades blow west and toward the equator,
+	// westerlies east and poleward, polar easterlies west and equatorward
+	var zonal, meridional, lo, hi float64
+	switch {
+	case psi < hadley:
+		zonal, meridional, lo, hi = -tradeWindSpeed, -meridionalWindSpeed, 0, hadley
+	case psi < ferrel:
+		zonal, meridional, lo, hi = westerlyWindSpeed, meridionalWindSpeed, hadley, ferrel
+	default:
+		zonal, meridional, lo, hi = -polarEasterlySpeed, -meridionalWindSpeed, ferrel, 90
+	}
+	profile := math.Sin(math.Pi * (psi - lo) / (hi - lo))
+	return zonal * profile, poleward * meridional * profile
+}
+
+// deflectWind turns a surface wind of east and north m/s, as it blows on
+// Earth, for a planet spinning at rotation rad/s. The Coriolis force,
+// f = 2Ω sin(lat), turns air flowing toward or away from the equator until
+// surface friction r balances it, leaving the wind at an angle θ off the
+// meridian with tan θ = f/r. At any latitude f scales with Ω, so tan θ
+// scales from Earth's by Ω/Ω⊕, and the wind keeps its speed: a slow spinner's
+// winds blow nearly straight poleward and back, a fast one's nearly along
+// the latitude lines
+func deflectWind(east, north, rotation float64) (float64, float64) {
+	if north == 0 {
+		return east, north
+	}
+	tan := east / north * rotation / core.DefaultRotationRate
+	north = math.Copysign(math.Hypot(east, north)/math.Sqrt(1+tan*tan), north)
+	return north * tan, north
+}
@@ -0,0 +1,101 @@
+package physics
+
+import (
+	"math"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// earthLikeAir sets the atmosphere shell to Earth's radiative equilibrium
+func earthLikeAir() *core.VoxelPlanet {
+	planet := core.CreateVoxelPlanet(6371000.0, 8)
+	air := &planet.Shells[len(planet.Shells)-1]
+	for latIdx := range air.Voxels {
+		temp := SurfaceEquilibriumTemperature(core.GetLatitudeForBand(latIdx, air.LatBands), surfaceAlbedo, DefaultGreenhouseStrength)
+		for lonIdx := range air.Voxels[latIdx] {
+			air.Voxels[latIdx][lonIdx].Temperature = float32(temp)
+		}
+	}
+	return planet
+}
+
+// TestAtmosphereSolverWindBands checks an Earth-like planet gets easterly
+// trade winds near the equator and westerlies at mid-latitudes, mirrored
+// across the equator
+func TestAtmosphereSolverWindBands(t *testing.T) {
+	planet := earthLikeAir()
+	NewAtmosphereSolver().Solve(planet)
+	air := &planet.Shells[len(planet.Shells)-1]
+
+	for _, tc := range []struct {
+		lat      float64
+		westerly bool
+	}{
+		{12, false}, {-12, false},
+		{45, true}, {-45, true},
+		{75, false}, {-75, false},
+	} {
+		latIdx := core.GetBandForLatitude(tc.lat, air.LatBands)
+		for lonIdx, voxel := range air.Voxels[latIdx] {
+			if east := voxel.VelEast; (east > 0) != tc.westerly || east == 0 {
+				t.Fatalf("lat %.0f lon %d: eastward wind %.2f m/s, want westerly %v", tc.lat, lonIdx, east, tc.westerly)
+			}
+		}
+	}
+
+	// Trades converge on the equator, westerlies carry air poleward
+	tropics := air.Voxels[core.GetBandForLatitude(12, air.LatBands)][0]
+	midLatitudes := air.Voxels[core.GetBandForLatitude(-45, air.LatBands)][0]
+	if tropics.VelNorth >= 0 || midLatitudes.VelNorth >= 0 {
+		t.Errorf("northward wind %.2f m/s at 12°N and %.2f m/s at 45°S, want both southward",
+			tropics.VelNorth, midLatitudes.VelNorth)
+	}
+}
+
+// TestAtmosphereSolverCadence checks winds are solved on the first step and
+// then once per interval, separately for each planet
+func TestAtmosphereSolverCadence(t *testing.T) {
+	solver := NewAtmosphereSolver()
+	solver.Interval = 3
+	a, b := earthLikeAir(), earthLikeAir()
+
+	var solved []bool
+	for step := 0; step < 7; step++ {
+		solved = append(solved, solver.Update(a))
+	}
+	want := []bool{true, false, false, true, false, false, true}
+	for i := range want {
+		if solved[i] != want[i] {
+			t.Fatalf("solves per step %v, want %v", solved, want)
+		}
+	}
+
+	if !solver.Update(b) {
+		t.Error("second planet not solved on its first step")
+	}
+}
+
+// TestRotationDeflectsWinds checks the Coriolis force turns the westerlies
+// further from the meridian the faster the planet spins: a tidally locked
+// planet's winds blow almost straight poleward, Earth's and a fast
+// spinner's mostly east
+func TestRotationDeflectsWinds(t *testing.T) {
+	deflection := func(hours float64) float64 {
+		planet := earthLikeAir()
+		planet.RotationRate = core.RotationRateForPeriod(hours)
+		NewAtmosphereSolver().Solve(planet)
+		air := &planet.Shells[len(planet.Shells)-1]
+		voxel := air.Voxels[core.GetBandForLatitude(50, air.LatBands)][0]
+		return math.Abs(float64(voxel.VelEast) / float64(voxel.VelNorth))
+	}
+
+	locked, earth, fast := deflection(365.25*24), deflection(24), deflection(10)
+	if !(locked < earth && earth < fast) {
+		t.Errorf("east/north wind ratio at 50°N: %.3f for a tidally locked planet, %.3f for Earth, %.3f for a 10 h day, want rising with spin",
+			locked, earth, fast)
+	}
+	if locked > 0.1 {
+		t.Errorf("tidally locked planet's winds are turned %.3f east per north, want nearly none", locked)
+	}
+}
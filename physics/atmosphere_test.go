@@ -0,0 +1,135 @@
+package physics
+
+import (
+	"math"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// meanSurfaceTemperature averages the open-water surface under the atmosphere
+func meanSurfaceTemperature(planet *core.VoxelPlanet) float64 {
+	shell := &planet.Shells[len(planet.Shells)-2]
+	sum, count := 0.0, 0
+	for _, band := range shell.Voxels {
+		for _, voxel := range band {
+			sum += float64(voxel.Temperature)
+			count++
+		}
+	}
+	return sum / float64(count)
+}
+
+// TestGreenhouseWarmsSurface checks a stronger greenhouse raises surface
+// equilibrium temperature, and that core.GreenhouseOff switches it off
+func TestGreenhouseWarmsSurface(t *testing.T) {
+	for _, lat := range []float64{0, 45, 80} {
+		weak := SurfaceEquilibriumTemperature(lat, surfaceAlbedo, 0.2)
+		strong := SurfaceEquilibriumTemperature(lat, surfaceAlbedo, 0.9)
+		if strong <= weak {
+			t.Errorf("lat %.0f: %.1f K with strong greenhouse, want above %.1f K", lat, strong, weak)
+		}
+	}
+
+	// Earth-like emissivity gives an Earth-like global mean
+	if equator := SurfaceEquilibriumTemperature(0, surfaceAlbedo, DefaultGreenhouseStrength); equator < 290 || equator > 315 {
+		t.Errorf("equatorial equilibrium %.1f K, want ~300 K", equator)
+	}
+
+	// The coupled atmosphere carries the difference down to the ocean surface
+	surfaceTemps := make(map[float64]float64)
+	for _, strength := range []float64{core.GreenhouseOff, 0.5, 0.9} {
+		planet := core.CreateVoxelPlanet(6371000.0, 8)
+		planet.GreenhouseStrength = strength
+		for step := 0; step < 20; step++ {
+			UpdateAtmosphere(planet, 1.0)
+		}
+		surfaceTemps[strength] = meanSurfaceTemperature(planet)
+	}
+	if surfaceTemps[0.9] <= surfaceTemps[0.5]+5 {
+		t.Errorf("surface at %.1f K with strong greenhouse vs %.1f K with weak, want clearly warmer",
+			surfaceTemps[0.9], surfaceTemps[0.5])
+	}
+	if surfaceTemps[core.GreenhouseOff] >= surfaceTemps[0.5] {
+		t.Errorf("surface at %.1f K with no greenhouse vs %.1f K with weak, want colder", surfaceTemps[core.GreenhouseOff], surfaceTemps[0.5])
+	}
+}
+
+// TestAtmosphereFreezesPolarOcean checks cold air freezes polar water and warm air melts ice
+func TestAtmosphereFreezesPolarOcean(t *testing.T) {
+	planet := core.CreateVoxelPlanet(6371000.0, 8)
+	surface := &planet.Shells[len(planet.Shells)-2]
+	polar := &surface.Voxels[0][0]
+	tropical := &surface.Voxels[surface.LatBands/2][0]
+
+	for step := 0; step < 20; step++ {
+		UpdateAtmosphere(planet, 1.0)
+	}
+	if polar.Type != core.MatIce {
+		t.Errorf("polar ocean at %.1f K is %d, want ice", polar.Temperature, polar.Type)
+	}
+	if tropical.Type != core.MatWater {
+		t.Errorf("tropical ocean at %.1f K froze", tropical.Temperature)
+	}
+
+	// Ice under warm tropical air melts
+	tropical.Type = core.MatIce
+	tropical.Temperature = 260
+	for step := 0; step < 20; step++ {
+		UpdateAtmosphere(planet, 1.0)
+	}
+	if tropical.Type != core.MatWater {
+		t.Errorf("tropical ice at %.1f K did not melt", tropical.Temperature)
+	}
+}
+
+// TestSeasonalInsolation checks a tilted axis brings polar day and polar
+// night over a year, and that high-latitude air follows with a seasonal swing
+// an untilted planet doesn't have
+func TestSeasonalInsolation(t *testing.T) {
+	polarRange := func(tilt float64) (lo, hi float64) {
+		lo, hi = math.Inf(1), math.Inf(-1)
+		for month := 0; month < 12; month++ {
+			q := dailyInsolation(80, solarDeclination(tilt, float64(month)/12))
+			lo, hi = math.Min(lo, q), math.Max(hi, q)
+		}
+		return lo, hi
+	}
+
+	lo, hi := polarRange(core.DefaultAxialTilt)
+	if lo > 1 {
+		t.Errorf("80°N gets %.0f W/m² at its darkest, want polar night", lo)
+	}
+	if hi < 400 {
+		t.Errorf("80°N gets %.0f W/m² at most, want a bright polar summer", hi)
+	}
+	if lo, hi := polarRange(0); hi-lo > 1 {
+		t.Errorf("80°N varies %.0f-%.0f W/m² without tilt, want constant", lo, hi)
+	}
+
+	// Monthly steps over two years; the second shows the settled cycle
+	airSwing := func(tilt float64) float64 {
+		planet := core.CreateVoxelPlanetWithResolution(6371000.0, 5, 60)
+		planet.AxialTilt = tilt
+		air := &planet.Shells[len(planet.Shells)-1]
+		polar := &air.Voxels[air.LatBands-1][0]
+
+		lo, hi := math.Inf(1), math.Inf(-1)
+		for month := 0; month < 24; month++ {
+			UpdateAtmosphere(planet, 1.0/12)
+			planet.Time += 1.0 / 12
+			if month >= 12 {
+				lo = math.Min(lo, float64(polar.Temperature))
+				hi = math.Max(hi, float64(polar.Temperature))
+			}
+		}
+		return hi - lo
+	}
+
+	if swing := airSwing(core.DefaultAxialTilt); swing < 15 {
+		t.Errorf("polar air swings %.1f K over a year, want a clear seasonal cycle", swing)
+	}
+	if swing := airSwing(0); swing > 2 {
+		t.Errorf("polar air swings %.1f K over a year without tilt, want steady", swing)
+	}
+}
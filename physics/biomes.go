@@ -0,0 +1,193 @@
+package physics
+
+import (
+	"container/heap"
+	"math"
+
+	"worldgenerator/core"
+)
+
+const (
+	// biomeLapseRate is how much colder the climate is per meter above sea level (K/m)
+	biomeLapseRate = 6.5e-3
+
+	// continentalityLength is how far inland in meters the moisture from
+	// open water falls to 1/e
+	continentalityLength = 2.0e6
+
+	// Annual mean temperatures in °C bounding the thermal zones
+	iceCapTemperature   = -12.0 // Below this the ground stays frozen
+	tundraTemperature   = -3.0  // Below this trees don't grow
+	borealTemperature   = 6.0   // Below this forests are boreal
+	tropicalTemperature = 20.0  // Above this there is no real winter
+
+	// alpineHeight is the height above sea level in meters where mountains
+	// too cold for temperate forest turn alpine
+	alpineHeight = 3000.0
+
+	// rainforestMoisture is the moisture a hot climate needs to stay wet all year
+	rainforestMoisture = 0.6
+
+	// How far a voxel's climate must move past a biome boundary before its
+	// biome changes, so voxels on a boundary don't flicker as the surface
+	// evolves
+	biomeTemperatureMargin = 1.0   // K
+	biomeMoistureMargin    = 0.03  // Moisture index
+	biomeHeightMargin      = 100.0 // m
+)
+
+// ClassifyBiomes assigns each surface voxel its biome. Water and ice, afloat
+// or covering land, take their own categories; land is classified by the
+// annual mean temperature its latitude receives, cooled with height, and by
+// its moisture, which falls off with distance from open water. A voxel keeps
+// its biome until its climate moves clearly into another, and the surface
+// shell is marked dirty only when a biome changes
+func ClassifyBiomes(planet *core.VoxelPlanet) {
+	if len(planet.Shells) < 2 {
+		return
+	}
+	surface := len(planet.Shells) - 2
+	shell := &planet.Shells[surface]
+	distances := distanceFromWater(planet)
+	greenhouse := greenhouseStrength(planet)
+
+	changed := false
+	i := 0
+	for latIdx := range shell.Voxels {
+		for lonIdx := range shell.Voxels[latIdx] {
+			voxel := &shell.Voxels[latIdx][lonIdx]
+			biome := core.BiomeNone
+			switch voxel.SurfaceType() {
+			case core.MatAir:
+			case core.MatWater:
+				biome = core.BiomeOcean
+			case core.MatIce:
+				biome = core.BiomeIce
+			default:
+				lat, _ := planet.VoxelLatLon(core.VoxelCoord{Shell: surface, Lat: latIdx, Lon: lonIdx})
+				height := math.Max(0, float64(voxel.Elevation)-planet.SeaLevel)
+				temp := equilibriumTemperature(annualInsolation(lat, planet.AxialTilt), surfaceAlbedo, greenhouse) -
+					freezingPoint - biomeLapseRate*height
+				moisture := math.Exp(-distances[i] / continentalityLength)
+				biome = stableBiome(voxel.Biome, temp, moisture, height)
+			}
+			if biome != voxel.Biome {
+				voxel.Biome = biome
+				changed = true
+			}
+			i++
+		}
+	}
+
+	if changed {
+		planet.MarkShellDirty(surface)
+	}
+}
+
+// stableBiome returns the land biome for a climate, unless the current
+// biome is still within the margins of it
+func stableBiome(current core.Biome, temp, moisture, height float64) core.Biome {
+	biome := landBiome(temp, moisture, height)
+	if biome == current || current == core.BiomeNone {
+		return biome
+	}
+	for _, nudge := range [][3]float64{
+		{-biomeTemperatureMargin, 0, 0}, {biomeTemperatureMargin, 0, 0},
+		{0, -biomeMoistureMargin, 0}, {0, biomeMoistureMargin, 0},
+		{0, 0, -biomeHeightMargin}, {0, 0, biomeHeightMargin},
+	} {
+		if landBiome(temp+nudge[0], moisture+nudge[1], height+nudge[2]) == current {
+			return current
+		}
+	}
+	return biome
+}
+
+// landBiome classifies land by its annual mean temperature in °C, moisture
+// from 0 (far inland) to 1 (on the coast) and height above sea level in
+// meters. As in Köppen's scheme, warmer climates need more moisture to
+// escape being dry
+func landBiome(temp, moisture, height float64) core.Biome {
+	switch {
+	case height > alpineHeight && temp < borealTemperature:
+		return core.BiomeAlpine
+	case temp < iceCapTemperature:
+		return core.BiomeIce
+	case temp < tundraTemperature:
+		return core.BiomeTundra
+	}
+
+	dry := math.Max(0.1, math.Min(0.5, 0.2+0.01*temp))
+	switch {
+	case moisture < dry/2:
+		return core.BiomeDesert
+	case moisture < dry && temp >= tropicalTemperature:
+		return core.BiomeSavanna
+	case moisture < dry:
+		return core.BiomeGrassland
+	case temp < borealTemperature:
+		return core.BiomeBorealForest
+	case temp < tropicalTemperature:
+		return core.BiomeTemperateForest
+	case moisture < rainforestMoisture:
+		return core.BiomeSavanna
+	default:
+		return core.BiomeTropicalRainforest
+	}
+}
+
+// distanceFromWater returns the distance in meters over the surface from
+// each surface voxel to the nearest open water, band by band south to north,
+// +Inf on a planet without water. Paths step between neighboring voxels as
+// in core.ShellNeighbors
+func distanceFromWater(planet *core.VoxelPlanet) []float64 {
+	surface := len(planet.Shells) - 2
+	shell := &planet.Shells[surface]
+
+	offsets := make([]int, shell.LatBands+1)
+	for lat := 0; lat < shell.LatBands; lat++ {
+		offsets[lat+1] = offsets[lat] + len(shell.Voxels[lat])
+	}
+	count := offsets[shell.LatBands]
+	index := func(c core.VoxelCoord) int { return offsets[c.Lat] + c.Lon }
+
+	coords := make([]core.VoxelCoord, count)
+	centers := make([]core.Vector3, count)
+	distances := make([]float64, count)
+	queue := &floodQueue{}
+	for lat := range shell.Voxels {
+		for lon := range shell.Voxels[lat] {
+			coord := core.VoxelCoord{Shell: surface, Lat: lat, Lon: lon}
+			i := index(coord)
+			coords[i] = coord
+			latDeg, lonDeg := planet.VoxelLatLon(coord)
+			p := core.GeographicToCartesian(core.Geographic{
+				Lat: core.DegreesToRadians(latDeg),
+				Lon: core.DegreesToRadians(lonDeg),
+			}, planet.Radius, planet.Flattening)
+			centers[i] = core.Vector3{X: p.X, Y: p.Y, Z: p.Z}
+			distances[i] = math.Inf(1)
+			if shell.Voxels[lat][lon].Type == core.MatWater {
+				distances[i] = 0
+				heap.Push(queue, floodEntry{i, 0})
+			}
+		}
+	}
+
+	for queue.Len() > 0 {
+		entry := heap.Pop(queue).(floodEntry)
+		i := entry.index
+		if entry.priority > distances[i] {
+			continue // Reached by a shorter path since it was queued
+		}
+		for _, n := range core.ShellNeighbors(shell, coords[i]) {
+			j := index(n)
+			if d := distances[i] + centers[i].Sub(centers[j]).Length(); d < distances[j] {
+				distances[j] = d
+				heap.Push(queue, floodEntry{j, d})
+			}
+		}
+	}
+
+	return distances
+}
@@ -0,0 +1,130 @@
+package physics
+
+import (
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// oceanStrip makes the surface an ocean from 30°W to 30°E, lowland
+// everywhere else, with one patch of sea ice
+func oceanStrip() *core.VoxelPlanet {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 4, 24)
+	planet.SeaLevel = 0
+	surface := len(planet.Shells) - 2
+	shell := &planet.Shells[surface]
+	for latIdx := range shell.Voxels {
+		for lonIdx := range shell.Voxels[latIdx] {
+			_, lon := planet.VoxelLatLon(core.VoxelCoord{Shell: surface, Lat: latIdx, Lon: lonIdx})
+			voxel := &shell.Voxels[latIdx][lonIdx]
+			voxel.Type, voxel.Elevation = core.MatGranite, 200
+			if lon > -30 && lon < 30 {
+				voxel.Type, voxel.Elevation = core.MatWater, -3000
+			}
+		}
+	}
+	ice, _ := planet.VoxelAtGeographic(-80, 0, -1000)
+	ice.Type = core.MatIce
+	return planet
+}
+
+// TestLandBiomeClimates checks familiar climates land in familiar biomes
+func TestLandBiomeClimates(t *testing.T) {
+	for _, tc := range []struct {
+		name                   string
+		temp, moisture, height float64
+		want                   core.Biome
+	}{
+		{"Amazon", 26, 0.9, 100, core.BiomeTropicalRainforest},
+		{"Sahel", 27, 0.35, 300, core.BiomeSavanna},
+		{"Sahara", 25, 0.05, 400, core.BiomeDesert},
+		{"Western Europe", 10, 0.8, 100, core.BiomeTemperateForest},
+		{"Great Plains", 10, 0.25, 600, core.BiomeGrassland},
+		{"Siberia", -1, 0.5, 300, core.BiomeBorealForest},
+		{"Arctic coast", -8, 0.8, 10, core.BiomeTundra},
+		{"Antarctic plateau", -30, 0.2, 500, core.BiomeIce},
+		{"Tibet", -4, 0.3, 4500, core.BiomeAlpine},
+	} {
+		if got := landBiome(tc.temp, tc.moisture, tc.height); got != tc.want {
+			t.Errorf("%s: %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+// TestClassifyBiomes checks every surface voxel gets a biome, water and ice
+// their own, and that land follows latitude and distance from the sea
+func TestClassifyBiomes(t *testing.T) {
+	planet := oceanStrip()
+	ClassifyBiomes(planet)
+	surface := len(planet.Shells) - 2
+	shell := &planet.Shells[surface]
+
+	for latIdx := range shell.Voxels {
+		for lonIdx, voxel := range shell.Voxels[latIdx] {
+			want := voxel.Biome
+			switch voxel.Type {
+			case core.MatWater:
+				want = core.BiomeOcean
+			case core.MatIce:
+				want = core.BiomeIce
+			}
+			if voxel.Biome == core.BiomeNone || voxel.Biome != want {
+				t.Fatalf("%v voxel at band %d lon %d classified %v", voxel.Type, latIdx, lonIdx, voxel.Biome)
+			}
+		}
+	}
+
+	for _, tc := range []struct {
+		lat, lon float64
+		want     core.Biome
+	}{
+		{2, 31, core.BiomeTropicalRainforest}, // Tropical coast
+		{2, 180, core.BiomeDesert},            // Half a world from the sea
+		{-80, 0, core.BiomeIce},               // Sea ice
+		{85, 90, core.BiomeIce},               // Polar land
+	} {
+		voxel, _ := planet.VoxelAtGeographic(tc.lat, tc.lon, -1000)
+		if voxel.Biome != tc.want {
+			t.Errorf("%.0f°, %.0f°: %v, want %v", tc.lat, tc.lon, voxel.Biome, tc.want)
+		}
+	}
+}
+
+// TestClassifyBiomesStable checks small changes in the surface leave every
+// biome and the shell version alone
+func TestClassifyBiomesStable(t *testing.T) {
+	planet := oceanStrip()
+	ClassifyBiomes(planet)
+	surface := len(planet.Shells) - 2
+	shell := &planet.Shells[surface]
+	version := shell.Version
+
+	before := make([][]core.Biome, len(shell.Voxels))
+	for latIdx := range shell.Voxels {
+		for _, voxel := range shell.Voxels[latIdx] {
+			before[latIdx] = append(before[latIdx], voxel.Biome)
+		}
+	}
+
+	for step := 0; step < 5; step++ {
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				if voxel := &shell.Voxels[latIdx][lonIdx]; voxel.Type == core.MatGranite {
+					voxel.Elevation += float32(20 * (1 - 2*(step%2)))
+				}
+			}
+		}
+		ClassifyBiomes(planet)
+	}
+
+	for latIdx := range shell.Voxels {
+		for lonIdx, voxel := range shell.Voxels[latIdx] {
+			if voxel.Biome != before[latIdx][lonIdx] {
+				t.Fatalf("band %d lon %d flickered from %v to %v", latIdx, lonIdx, before[latIdx][lonIdx], voxel.Biome)
+			}
+		}
+	}
+	if shell.Version != version {
+		t.Error("surface marked dirty with no biome changed")
+	}
+}
@@ -0,0 +1,58 @@
+package physics
+
+import (
+	"math"
+	"testing"
+
+	"worldgenerator/gpu"
+	"worldgenerator/gpu/gltest"
+)
+
+// TestComputeTemperatureMatchesCPU steps the OpenGL temperature compute
+// shader and the CPU diffusion from the same planet and checks every voxel
+// changes alike. A step only moves temperatures by about a millikelvin, so
+// each voxel must land within a float32 rounding of the CPU result plus a
+// hundredth of its change. It needs an OpenGL 4.3 context and skips without
+// a display
+func TestComputeTemperatureMatchesCPU(t *testing.T) {
+	defer gltest.OpenComputeContext(t)()
+
+	planet := testPlanet(5)
+	want := testPlanet(5)
+	before := testPlanet(5)
+	const dt = 1000.0
+
+	cp, err := gpu.NewComputePhysics(planet, gltest.SharedContext(t))
+	if err != nil {
+		t.Fatalf("NewComputePhysics: %v", err)
+	}
+	defer cp.Release()
+
+	if err := cp.StepTemperature(planet, dt); err != nil {
+		t.Fatalf("StepTemperature: %v", err)
+	}
+	updateTemperatureCPU(want, dt)
+
+	changed := 0
+	for s, shell := range want.Shells {
+		for latIdx, band := range shell.Voxels {
+			for lonIdx, voxel := range band {
+				start := before.Shells[s].Voxels[latIdx][lonIdx].Temperature
+				got := planet.Shells[s].Voxels[latIdx][lonIdx].Temperature
+				change := math.Abs(float64(voxel.Temperature - start))
+				if change > 0 {
+					changed++
+				}
+
+				rounding := float64(math.Nextafter32(voxel.Temperature, float32(math.Inf(1))) - voxel.Temperature)
+				if diff := math.Abs(float64(got - voxel.Temperature)); diff > rounding+0.01*change {
+					t.Fatalf("shell %d voxel %d,%d from %.4f K to %.6f K on the GPU, %.6f K on the CPU",
+						s, latIdx, lonIdx, start, got, voxel.Temperature)
+				}
+			}
+		}
+	}
+	if changed == 0 {
+		t.Fatal("no voxel changed temperature on the CPU, test planet has no gradients")
+	}
+}
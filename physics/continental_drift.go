@@ -68,7 +68,7 @@ func UpdateContinentalDrift(planet *core.VoxelPlanet, state *ContinentalDriftSta
 				continue
 			}
 
-			// Convert velocity (m/s) to degrees per time
+			// Convert velocity (m/year) to degrees per time
 			// Circumference at this latitude = 2*pi*radius*cos(lat)
 			circumference := 2.0 * math.Pi * radius * cosLat
 			degreesPerMeter := 360.0 / circumference
@@ -153,5 +153,5 @@ func UpdateContinentalDrift(planet *core.VoxelPlanet, state *ContinentalDriftSta
 	shell.Voxels = newSurface
 
 	// Update the rendering textures
-	planet.MeshDirty = true
+	planet.MarkShellDirty(surfaceShell)
 }
@@ -0,0 +1,73 @@
+package physics
+
+import (
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// uniformMantle sets the whole interior to one temperature, so there is no
+// perturbation to drive convection
+func uniformMantle(planet *core.VoxelPlanet) {
+	for i := range planet.Shells[:len(planet.Shells)-2] {
+		for _, band := range planet.Shells[i].Voxels {
+			for j := range band {
+				band[j].Temperature = 2000
+			}
+		}
+	}
+}
+
+// movingCrust returns how many surface crust voxels there are and how many of
+// them have a horizontal velocity
+func movingCrust(planet *core.VoxelPlanet) (crust, moving int) {
+	for _, band := range planet.Shells[len(planet.Shells)-2].Voxels {
+		for _, voxel := range band {
+			if voxel.Type != core.MatGranite && voxel.Type != core.MatBasalt {
+				continue
+			}
+			crust++
+			if voxel.VelNorth != 0 || voxel.VelEast != 0 {
+				moving++
+			}
+		}
+	}
+	return crust, moving
+}
+
+// TestConvectionForcing checks forced plates carry nearly all surface crust
+// along within a few steps, even when a uniform mantle gives convection
+// nothing to work with, and that moving plates don't erase their own crust
+func TestConvectionForcing(t *testing.T) {
+	const dt = 100000.0
+
+	// Without forcing a uniform mantle leaves most of the crust at rest
+	unforced := testPlanet(10)
+	uniformMantle(unforced)
+	for step := 0; step < 3; step++ {
+		StepCPU(unforced, dt)
+	}
+	if crust, moving := movingCrust(unforced); moving*2 > crust {
+		t.Fatalf("unforced uniform mantle moved %d of %d crust voxels, test no longer exercises forcing", moving, crust)
+	}
+
+	for _, uniform := range []bool{false, true} {
+		planet := testPlanet(10, forcing(5))
+		if uniform {
+			uniformMantle(planet)
+		}
+		startCrust, _ := movingCrust(planet)
+
+		for step := 0; step < 3; step++ {
+			StepCPU(planet, dt)
+		}
+
+		crust, moving := movingCrust(planet)
+		if moving*10 < crust*8 {
+			t.Errorf("uniform mantle %v: only %d of %d crust voxels moving with forcing", uniform, moving, crust)
+		}
+		if crust*10 < startCrust*9 {
+			t.Errorf("uniform mantle %v: crust shrank from %d to %d voxels in 3 steps", uniform, startCrust, crust)
+		}
+	}
+}
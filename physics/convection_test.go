@@ -0,0 +1,150 @@
+package physics
+
+import (
+	"math"
+	"testing"
+
+	"worldgenerator/core"
+	"worldgenerator/gpu"
+)
+
+// TestConvectionMatchesGPUReference checks CPU convection and the reference
+// the Metal and OpenGL kernels follow set the same radial velocities, so
+// switching backends doesn't change the physics
+func TestConvectionMatchesGPUReference(t *testing.T) {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 6, 24)
+	vp := NewVoxelPhysics(planet)
+
+	// Hot magma plumes, a continent over oceanic crust, an eclogite slab and
+	// leftover velocities to decay, spread over shells of every resolution
+	for shellIdx := range planet.Shells {
+		shell := &planet.Shells[shellIdx]
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				voxel := &shell.Voxels[latIdx][lonIdx]
+				if voxel.Type == core.MatAir {
+					continue
+				}
+				voxel.VelR = 1e-9
+				switch (latIdx*7 + lonIdx + shellIdx) % 5 {
+				case 0:
+					voxel.Type = core.MatMagma
+					voxel.Density = 2800
+					voxel.Temperature = 1800 + float32(lonIdx%11)*20
+				case 1:
+					voxel.Type = core.MatGranite
+					voxel.Density = 2700
+				case 2:
+					voxel.Type = core.MatEclogite
+					voxel.Density = 3500
+				case 3:
+					voxel.Type = core.MatBasalt
+					voxel.Density = 2900
+				}
+			}
+		}
+	}
+
+	var voxels []gpu.GPUVoxelMaterial
+	for _, shell := range planet.Shells {
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				voxels = append(voxels, gpu.ConvertToGPUVoxel(&shell.Voxels[latIdx][lonIdx]))
+			}
+		}
+	}
+	const dt = 1000.0
+	want := gpu.ConvectVoxels(voxels, gpu.NeighborIndices(planet), gpu.MaterialViscosityScales(), gpu.ConvectionLengthScales(planet), dt)
+
+	vp.advection.UpdateConvection(dt)
+
+	idx, convecting := 0, 0
+	for shellIdx, shell := range planet.Shells {
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				got := float64(shell.Voxels[latIdx][lonIdx].VelR)
+				expected := float64(want[idx])
+				if math.Abs(got-expected) > 1e-4*math.Abs(expected)+1e-30 {
+					t.Fatalf("voxel %d/%d/%d: CPU velR %g, GPU reference %g", shellIdx, latIdx, lonIdx, got, expected)
+				}
+				if expected != 1e-9 && expected != float64(float32(1e-9)*0.95) && expected != 0 {
+					convecting++
+				}
+				idx++
+			}
+		}
+	}
+	if convecting == 0 {
+		t.Error("no voxel convected, so only the decay was compared")
+	}
+}
+
+// TestUpwellingAcrossResolutionBoundary checks a plume rising from a coarse
+// shell heats every voxel of the finer shell above it and nothing beside it,
+// and that one rising from a fine shell heats the coarse voxel above it
+func TestUpwellingAcrossResolutionBoundary(t *testing.T) {
+	planet := core.CreateVoxelPlanetWithShellBands(6371000.0, []int{12, 12, 36, 12, 36, 36})
+	va := NewVoxelPhysics(planet).advection
+	for shellIdx := 0; shellIdx <= 4; shellIdx++ {
+		shell := &planet.Shells[shellIdx]
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				voxel := &shell.Voxels[latIdx][lonIdx]
+				voxel.Type = core.MatPeridotite
+				voxel.Temperature = 1500
+				voxel.VelR = 0
+			}
+		}
+	}
+
+	// Coarse shell 1 rising into fine shell 2, fine shell 2 into coarse shell 3
+	coarsePlume := core.VoxelCoord{Shell: 1, Lat: 6, Lon: 4}
+	finePlume := core.VoxelCoord{Shell: 2, Lat: 30, Lon: 5}
+	for _, plume := range []core.VoxelCoord{coarsePlume, finePlume} {
+		voxel := planet.GetVoxel(plume)
+		voxel.Temperature = 3000
+		voxel.VelR = 1
+	}
+	const dt = 1000.0
+	va.AdvectMaterial(dt)
+
+	// The fine voxels whose centers lie in the coarse plume warm, the rest don't
+	heated := 0
+	fine := &planet.Shells[2]
+	for latIdx := range fine.Voxels {
+		for lonIdx := range fine.Voxels[latIdx] {
+			coord := core.VoxelCoord{Shell: 2, Lat: latIdx, Lon: lonIdx}
+			if coord == finePlume {
+				continue
+			}
+			lat, lon := planet.VoxelLatLon(coord)
+			above := planet.Column(lat, lon)[1] == planet.GetVoxel(coarsePlume)
+			warmed := fine.Voxels[latIdx][lonIdx].Temperature > 1500
+			if warmed != above {
+				t.Errorf("fine voxel %d/%d warmed=%v, above the plume=%v", latIdx, lonIdx, warmed, above)
+			}
+			if above {
+				heated++
+			}
+		}
+	}
+	if heated < 4 {
+		t.Errorf("coarse plume heated %d fine voxels, want every one over it", heated)
+	}
+	if temp := planet.GetVoxel(coarsePlume).Temperature; temp >= 3000 {
+		t.Errorf("coarse plume stayed at %.1f K after heating the shell above", temp)
+	}
+
+	// The coarse voxel over the fine plume warms, its neighbors don't
+	lat, lon := planet.VoxelLatLon(finePlume)
+	target := planet.Column(lat, lon)[3]
+	coarse := &planet.Shells[3]
+	for latIdx := range coarse.Voxels {
+		for lonIdx := range coarse.Voxels[latIdx] {
+			voxel := &coarse.Voxels[latIdx][lonIdx]
+			if warmed := voxel.Temperature > 1500; warmed != (voxel == target) {
+				t.Errorf("coarse voxel %d/%d warmed=%v, over the plume=%v", latIdx, lonIdx, warmed, voxel == target)
+			}
+		}
+	}
+}
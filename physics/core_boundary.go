@@ -0,0 +1,81 @@
+package physics
+
+import (
+	"worldgenerator/core"
+)
+
+// secondsPerYear converts simulation time (years) to SI seconds
+const secondsPerYear = 365.25 * 24 * 3600
+
+// applyCoreBoundary applies the core-mantle boundary condition to the
+// innermost shell. temps holds the new temperatures for shell 0, dt is in years.
+func applyCoreBoundary(planet *core.VoxelPlanet, temps [][]float32, dt float64) {
+	if len(planet.Shells) == 0 {
+		return
+	}
+	shell := &planet.Shells[0]
+
+	switch planet.CoreBoundary {
+	case core.CoreBoundaryFixedTemperature:
+		// Dirichlet: the shell touching the core is pinned to the core temperature
+		coreTemp := float32(planet.CoreTemperature)
+		for latIdx := range temps {
+			for lonIdx := range temps[latIdx] {
+				temps[latIdx][lonIdx] = coreTemp
+			}
+		}
+
+	case core.CoreBoundaryFixedFlux:
+		// Neumann: q W/m² enters through the inner face of the shell
+		// Per unit inner area the shell holds (r_o³ - r_i³) / (3 r_i²) m³ of rock
+		ri := shell.InnerRadius
+		ro := shell.OuterRadius
+		if ri <= 0 {
+			return
+		}
+		thickness := (ro*ro*ro - ri*ri*ri) / (3 * ri * ri)
+		energy := planet.CoreHeatFlux * dt * secondsPerYear // J per m² of inner face
+
+		for latIdx := range temps {
+			for lonIdx := range temps[latIdx] {
+				voxel := &shell.Voxels[latIdx][lonIdx]
+				props, ok := core.MaterialProperties[voxel.Type]
+				if !ok || voxel.Density <= 0 || props.SpecificHeat <= 0 {
+					continue
+				}
+				heatCapacity := float64(voxel.Density) * float64(props.SpecificHeat) * thickness
+				temps[latIdx][lonIdx] += float32(energy / heatCapacity)
+			}
+		}
+	}
+}
+
+// applyCoreBoundaryInPlace applies the core-mantle boundary condition to the
+// temperatures a GPU kernel already wrote into the innermost shell
+func applyCoreBoundaryInPlace(planet *core.VoxelPlanet, dt float64) {
+	if len(planet.Shells) == 0 {
+		return
+	}
+	shell := &planet.Shells[0]
+	temps := make([][]float32, len(shell.Voxels))
+	for latIdx, band := range shell.Voxels {
+		temps[latIdx] = make([]float32, len(band))
+		for lonIdx := range band {
+			temps[latIdx][lonIdx] = band[lonIdx].Temperature
+		}
+	}
+
+	applyCoreBoundary(planet, temps, dt)
+
+	for latIdx, band := range shell.Voxels {
+		for lonIdx := range band {
+			band[lonIdx].Temperature = temps[latIdx][lonIdx]
+		}
+	}
+}
+
+// usesLegacyDeepHeating reports whether the ad-hoc deep-shell heating should run
+// An explicit core boundary condition replaces it
+func usesLegacyDeepHeating(planet *core.VoxelPlanet) bool {
+	return planet.CoreBoundary == core.CoreBoundaryNone
+}
@@ -0,0 +1,47 @@
+package physics
+
+import (
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// TestCoreBoundaryFixedTemperature checks the innermost shell holds the core temperature
+func TestCoreBoundaryFixedTemperature(t *testing.T) {
+	planet := core.CreateVoxelPlanet(6371000.0, 8)
+	planet.CoreBoundary = core.CoreBoundaryFixedTemperature
+	planet.CoreTemperature = 4200.0
+
+	for step := 0; step < 5; step++ {
+		updateTemperatureCPU(planet, 1000.0)
+
+		for latIdx, band := range planet.Shells[0].Voxels {
+			for lonIdx, voxel := range band {
+				if voxel.Temperature != 4200.0 {
+					t.Fatalf("step %d: voxel [%d][%d] at %.1f K, want 4200 K",
+						step, latIdx, lonIdx, voxel.Temperature)
+				}
+			}
+		}
+	}
+}
+
+// TestCoreBoundaryFixedFlux checks a positive core heat flux warms the innermost shell
+func TestCoreBoundaryFixedFlux(t *testing.T) {
+	withFlux := core.CreateVoxelPlanet(6371000.0, 8)
+	withFlux.CoreBoundary = core.CoreBoundaryFixedFlux
+	withFlux.CoreHeatFlux = 0.1
+
+	noFlux := core.CreateVoxelPlanet(6371000.0, 8)
+	noFlux.CoreBoundary = core.CoreBoundaryFixedFlux
+	noFlux.CoreHeatFlux = 0
+
+	updateTemperatureCPU(withFlux, 1e6)
+	updateTemperatureCPU(noFlux, 1e6)
+
+	heated := withFlux.Shells[0].Voxels[0][0].Temperature
+	baseline := noFlux.Shells[0].Voxels[0][0].Temperature
+	if heated <= baseline {
+		t.Errorf("core flux did not heat innermost shell: %.4f K vs %.4f K without flux", heated, baseline)
+	}
+}
@@ -0,0 +1,58 @@
+package physics
+
+import (
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// TestRegisteredMaterialPhysics places a runtime-registered material in a
+// voxel and checks the physics passes use its properties
+func TestRegisteredMaterialPhysics(t *testing.T) {
+	count := core.MaterialCount()
+	t.Cleanup(func() { core.UnregisterMaterials(count) })
+
+	foam := core.RegisterMaterial(core.MaterialProps{
+		Name:                "Foam Crust",
+		DefaultDensity:      1500,
+		MeltingPoint:        600,
+		SpecificHeat:        800,
+		ThermalConductivity: 0.5,
+		Viscosity:           1e19,
+		Strength:            20e6,
+		Color:               core.Vector3{X: 0.9, Y: 0.9, Z: 0.7},
+	})
+	if !core.IsCustomMaterial(foam) || int(foam) >= core.MaterialCount() {
+		t.Fatalf("registered type %d is outside the material table (%d types)", foam, core.MaterialCount())
+	}
+	if name := core.MaterialName(foam); name != "foam_crust" {
+		t.Errorf("material name %q, want foam_crust", name)
+	}
+	if want := float32(0.5 / (1500 * 800)); core.MaterialDiffusivity(foam) != want {
+		t.Errorf("diffusivity %g, want %g", core.MaterialDiffusivity(foam), want)
+	}
+
+	// Foam melts well below where basalt would
+	planet := core.CreateVoxelPlanet(6371000.0, 8)
+	surface := &planet.Shells[len(planet.Shells)-2]
+	foamVoxel := &surface.Voxels[100][0]
+	basaltVoxel := &surface.Voxels[100][1]
+	*foamVoxel = core.VoxelMaterial{Type: foam, Density: 1500, Temperature: 1300}
+	*basaltVoxel = core.VoxelMaterial{Type: core.MatBasalt, Density: 2900, Temperature: 1300}
+
+	updatePhaseTransitionsCPU(planet, 1.0)
+	if foamVoxel.Type != core.MatMagma {
+		t.Errorf("foam crust at 1300 K is type %d, want magma", foamVoxel.Type)
+	}
+	if basaltVoxel.Type != core.MatBasalt {
+		t.Errorf("basalt at 1300 K melted to type %d", basaltVoxel.Type)
+	}
+
+	// Advection scales viscosity by the registered reference viscosity
+	va := &VoxelAdvection{planet: planet}
+	foamViscosity := va.getViscosity(&core.VoxelMaterial{Type: foam, Temperature: 5000, Pressure: 101325})
+	rockViscosity := va.getViscosity(&core.VoxelMaterial{Type: core.MatPeridotite, Temperature: 5000, Pressure: 101325})
+	if ratio := foamViscosity / rockViscosity; ratio < 0.009 || ratio > 0.011 {
+		t.Errorf("foam/rock viscosity ratio %g, want 0.01", ratio)
+	}
+}
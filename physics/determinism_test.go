@@ -0,0 +1,58 @@
+package physics
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"worldgenerator/core"
+	"worldgenerator/gpu"
+)
+
+// voxelBytes encodes every field of every voxel, so planets compare bit for
+// bit rather than by float equality
+func voxelBytes(t *testing.T, planet *core.VoxelPlanet) []byte {
+	var buf bytes.Buffer
+	for _, shell := range planet.Shells {
+		for _, band := range shell.Voxels {
+			if err := binary.Write(&buf, binary.LittleEndian, band); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return buf.Bytes()
+}
+
+// TestDeterministicSteps checks two planets from the same seed are bit
+// identical after 100 steps, on the CPU and through the CPU compute backend
+func TestDeterministicSteps(t *testing.T) {
+	for _, backend := range []string{"cpu", "cpu compute"} {
+		t.Run(backend, func(t *testing.T) {
+			var results [2][]byte
+			for run := range results {
+				planet := testPlanet(5)
+				var compute gpu.GPUCompute
+				if backend == "cpu compute" {
+					cc, err := gpu.NewCPUCompute(planet)
+					if err != nil {
+						t.Fatal(err)
+					}
+					defer cc.Cleanup()
+					compute = cc
+				}
+				for step := 0; step < 100; step++ {
+					Step(planet, 10000.0, compute)
+				}
+				results[run] = voxelBytes(t, planet)
+			}
+
+			if !bytes.Equal(results[0], results[1]) {
+				first := 0
+				for first < len(results[0]) && results[0][first] == results[1][first] {
+					first++
+				}
+				t.Errorf("runs diverge at byte %d of %d", first, len(results[0]))
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package physics
+
+import (
+	"reflect"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// staleShells returns the shells whose version moved past versions
+func staleShells(planet *core.VoxelPlanet, versions []uint64) []int {
+	var stale []int
+	for i, shell := range planet.Shells {
+		if shell.Version != versions[i] {
+			stale = append(stale, i)
+		}
+	}
+	return stale
+}
+
+// shellVersions returns the version of every shell
+func shellVersions(planet *core.VoxelPlanet) []uint64 {
+	versions := make([]uint64, len(planet.Shells))
+	for i, shell := range planet.Shells {
+		versions[i] = shell.Version
+	}
+	return versions
+}
+
+// TestStepMarksWrittenShells checks a physics step only marks the shells its
+// passes write: the deep shells radiogenic heat warms, the surface and the
+// air, not the laterally uniform mantle between that neither diffuses nor
+// moves. Advection on its own only marks the surface
+func TestStepMarksWrittenShells(t *testing.T) {
+	planet := core.CreateVoxelPlanet(6371000.0, 10)
+	const dt = 1000.0
+	StepCPU(planet, dt)
+
+	versions := shellVersions(planet)
+	StepCPU(planet, dt)
+	if got, want := staleShells(planet, versions), []int{0, 1, 2, 3, 4, 8, 9}; !reflect.DeepEqual(got, want) {
+		t.Errorf("step marked shells %v, want %v", got, want)
+	}
+
+	// Nothing rises, so no shell below the surface upwells
+	for _, shell := range planet.Shells {
+		for _, band := range shell.Voxels {
+			for i := range band {
+				band[i].VelR = 0
+			}
+		}
+	}
+	versions = shellVersions(planet)
+	NewVoxelAdvection(planet, nil).AdvectMaterial(dt)
+	if got, want := staleShells(planet, versions), []int{8}; !reflect.DeepEqual(got, want) {
+		t.Errorf("advection marked shells %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,86 @@
+package physics
+
+import (
+	"math"
+	"worldgenerator/core"
+)
+
+const (
+	// eclogitePressure is where subducted basalt recrystallizes to eclogite,
+	// around 50 km down on Earth
+	eclogitePressure = 1.5e9
+
+	// eclogiteMinTemperature is the coldest slab that still converts (K);
+	// colder slabs carry metastable basalt deeper
+	eclogiteMinTemperature = 700.0
+
+	// mantleReferenceDensity is the upper mantle density slabs sink through (kg/m³)
+	mantleReferenceDensity = 3300.0
+)
+
+// lithostaticPressure estimates the pressure in Pa at depth meters below the surface
+func lithostaticPressure(depth float64) float64 {
+	return mantleReferenceDensity * 9.8 * depth
+}
+
+// updateEclogiteTransition converts basalt carried below the transition
+// pressure into eclogite. Eclogite is denser than the mantle around it, so a
+// slab that has converted sinks under its own weight and pulls harder on the
+// plate behind it. The Metal step runs no phase transitions, so slabs never
+// turn to eclogite there
+func updateEclogiteTransition(planet *core.VoxelPlanet) {
+	for shellIdx := range planet.Shells {
+		updateEclogiteShell(planet, shellIdx)
+	}
+}
+
+// updateEclogiteShell converts the basalt of one shell past the transition
+func updateEclogiteShell(planet *core.VoxelPlanet, shellIdx int) {
+	eclogite := core.MaterialProperties[core.MatEclogite]
+	shell := &planet.Shells[shellIdx]
+
+	// Shell tops are the shallowest point of each voxel; a whole shell
+	// above the transition can be skipped
+	shellPressure := lithostaticPressure(planet.Radius - shell.OuterRadius)
+
+	changed := false
+	for latIdx := range shell.Voxels {
+		for lonIdx := range shell.Voxels[latIdx] {
+			voxel := &shell.Voxels[latIdx][lonIdx]
+			if voxel.Type != core.MatBasalt || voxel.Temperature < eclogiteMinTemperature {
+				continue
+			}
+
+			pressure := math.Max(shellPressure, float64(voxel.Pressure))
+			if pressure < eclogitePressure {
+				continue
+			}
+
+			voxel.Type = core.MatEclogite
+			voxel.Density = eclogite.DefaultDensity
+			changed = true
+		}
+	}
+	if changed {
+		planet.MarkShellDirty(shellIdx)
+	}
+}
+
+// compositionalBuoyancy returns the upward force per unit volume (N/m³) from a
+// voxel's composition rather than its temperature
+func compositionalBuoyancy(voxel, outerVoxel *core.VoxelMaterial, g float64) float64 {
+	switch voxel.Type {
+	case core.MatGranite:
+		// Continental crust is ~2700 kg/m³ vs oceanic ~2900 kg/m³
+		// This creates persistent upward force that keeps continents from subducting
+		avgDensity := float64(outerVoxel.Density)
+		if outerVoxel.Type == core.MatBasalt || outerVoxel.Type == core.MatPeridotite {
+			avgDensity = 2900.0 // Reference oceanic density
+		}
+		return (avgDensity - float64(voxel.Density)) * g / 100.0
+	case core.MatEclogite:
+		// Eclogite outweighs the mantle, so converted slabs keep sinking
+		return (mantleReferenceDensity - float64(voxel.Density)) * g / 100.0
+	}
+	return 0
+}
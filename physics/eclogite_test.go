@@ -0,0 +1,69 @@
+package physics
+
+import (
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// TestEclogiteTransition checks basalt carried below the transition depth
+// becomes eclogite and gains negative buoyancy, while shallow or cold basalt
+// stays as it is
+func TestEclogiteTransition(t *testing.T) {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 8, 60)
+	surface := len(planet.Shells) - 2
+	deep := &planet.Shells[surface-1]
+	if depth := planet.Radius - deep.OuterRadius; lithostaticPressure(depth) < eclogitePressure {
+		t.Fatalf("shell below the surface starts at %.0f km, above the transition", depth/1000)
+	}
+
+	basalt := core.VoxelMaterial{Type: core.MatBasalt, Density: 2900, Temperature: 900, Pressure: 101325}
+	mantle := core.VoxelMaterial{Type: core.MatPeridotite, Density: 3300, Temperature: 1400}
+
+	slab := &deep.Voxels[5][0]
+	coldSlab := &deep.Voxels[5][1]
+	crust := &planet.Shells[surface].Voxels[20][0]
+	*slab = basalt
+	*coldSlab = basalt
+	coldSlab.Temperature = 500
+	*crust = basalt
+
+	before := compositionalBuoyancy(slab, &mantle, 9.81)
+	updateEclogiteTransition(planet)
+
+	if slab.Type != core.MatEclogite {
+		t.Fatalf("subducted basalt is type %d, want eclogite", slab.Type)
+	}
+	if want := core.MaterialProperties[core.MatEclogite].DefaultDensity; slab.Density != want {
+		t.Errorf("eclogite density %.0f kg/m³, want %.0f", slab.Density, want)
+	}
+	if after := compositionalBuoyancy(slab, &mantle, 9.81); after >= 0 || after >= before {
+		t.Errorf("buoyancy %.3g N/m³ after the transition (%.3g before), want more strongly negative", after, before)
+	}
+
+	if coldSlab.Type != core.MatBasalt {
+		t.Errorf("500 K slab converted to type %d, want metastable basalt", coldSlab.Type)
+	}
+	if crust.Type != core.MatBasalt || crust.Density != 2900 {
+		t.Errorf("surface basalt became type %d at %.0f kg/m³", crust.Type, crust.Density)
+	}
+}
+
+// TestEclogiteAmortized checks the amortized phase transitions convert a deep
+// slab once they reach its shell
+func TestEclogiteAmortized(t *testing.T) {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 8, 60)
+	deepIdx := len(planet.Shells) - 3
+	slab := &planet.Shells[deepIdx].Voxels[5][0]
+	*slab = core.VoxelMaterial{Type: core.MatBasalt, Density: 2900, Temperature: 900, Pressure: 101325}
+
+	state := NewAmortizedPhysicsState()
+	state.currentPhase = PhasePhaseTransitions
+	for state.currentPhase == PhasePhaseTransitions {
+		updatePhaseTransitionsAmortized(planet, 1.0, state)
+	}
+
+	if slab.Type != core.MatEclogite {
+		t.Errorf("subducted basalt is type %d after the amortized phase transitions, want eclogite", slab.Type)
+	}
+}
@@ -0,0 +1,47 @@
+package physics
+
+import (
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// TestElevationClampLimitsSpikes checks an absurd VelR can't move crust past the clamp
+func TestElevationClampLimitsSpikes(t *testing.T) {
+	planet := core.CreateVoxelPlanet(6371000.0, 8)
+	planet.MaxElevationRate = 0.01
+	shell := &planet.Shells[len(planet.Shells)-2]
+	va := &VoxelAdvection{planet: planet}
+
+	const dt = 1000.0
+	maxStep := float32(planet.MaxElevationRate * dt)
+
+	for _, velR := range []float32{1e6, -1e6} {
+		voxel := &core.VoxelMaterial{Type: core.MatGranite, VelR: velR}
+		for step := 0; step < 5; step++ {
+			before := voxel.Elevation
+			va.applyVerticalMotion(voxel, shell, dt)
+			if change := voxel.Elevation - before; change > maxStep+1e-3 || change < -maxStep-1e-3 {
+				t.Fatalf("VelR %.0e step %d: elevation changed %.1f m, clamp allows %.1f m", velR, step, change, maxStep)
+			}
+		}
+		if want := 5 * maxStep; abs(voxel.Elevation) < want-1e-3 {
+			t.Errorf("VelR %.0e: elevation %.1f m after 5 steps, want ±%.1f m at the clamp", velR, voxel.Elevation, want)
+		}
+	}
+
+	// Water is not crust and moves freely
+	water := &core.VoxelMaterial{Type: core.MatWater, VelR: 1}
+	va.applyVerticalMotion(water, shell, dt)
+	if water.Elevation != dt {
+		t.Errorf("water elevation %.1f m, want unclamped %.1f m", water.Elevation, dt)
+	}
+
+	// A zero rate disables the clamp
+	planet.MaxElevationRate = 0
+	free := &core.VoxelMaterial{Type: core.MatBasalt, VelR: 1}
+	va.applyVerticalMotion(free, shell, dt)
+	if free.Elevation != dt {
+		t.Errorf("unclamped elevation %.1f m, want %.1f m", free.Elevation, dt)
+	}
+}
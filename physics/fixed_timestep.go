@@ -0,0 +1,47 @@
+package physics
+
+// FixedTimestep converts wall-clock-scaled simulation time into a whole number
+// of equal physics steps, carrying the remainder over to the next tick.
+// This keeps numerical stability independent of frame rate and frame hitches.
+type FixedTimestep struct {
+	StepYears float64 // Simulation years per physics step
+	MaxSteps  int     // Upper bound on steps per tick (0 = unlimited)
+
+	accumulated  float64 // Simulation years not yet simulated
+	TotalSteps   int64   // Steps handed out so far
+	DroppedYears float64 // Time discarded because MaxSteps was hit
+}
+
+// NewFixedTimestep creates an accumulator for steps of stepYears
+func NewFixedTimestep(stepYears float64, maxSteps int) *FixedTimestep {
+	return &FixedTimestep{
+		StepYears: stepYears,
+		MaxSteps:  maxSteps,
+	}
+}
+
+// Advance adds elapsed simulation years and returns how many fixed steps to run
+func (f *FixedTimestep) Advance(years float64) int {
+	if f.StepYears <= 0 {
+		return 0
+	}
+
+	f.accumulated += years
+	steps := int(f.accumulated / f.StepYears)
+
+	// Drop the backlog rather than spiral when physics can't keep up
+	if f.MaxSteps > 0 && steps > f.MaxSteps {
+		f.DroppedYears += float64(steps-f.MaxSteps) * f.StepYears
+		f.accumulated -= float64(steps-f.MaxSteps) * f.StepYears
+		steps = f.MaxSteps
+	}
+
+	f.accumulated -= float64(steps) * f.StepYears
+	f.TotalSteps += int64(steps)
+	return steps
+}
+
+// Pending returns simulation years accumulated but not yet stepped
+func (f *FixedTimestep) Pending() float64 {
+	return f.accumulated
+}
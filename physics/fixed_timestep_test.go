@@ -0,0 +1,76 @@
+package physics
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// TestFixedTimestepStepCount checks steps track sim time regardless of frame pacing
+func TestFixedTimestepStepCount(t *testing.T) {
+	const stepYears = 1000.0
+
+	pacings := map[string][]float64{
+		"steady":  repeat(250.0, 400),                       // Many small ticks
+		"coarse":  repeat(7000.0, 20),                       // Several steps per tick
+		"hitch":   append(repeat(250.0, 100), 60000.0, 500), // One long frame
+		"jittery": jitter(300, 10, 4000, 42),                // Random frame times
+	}
+
+	for name, ticks := range pacings {
+		t.Run(name, func(t *testing.T) {
+			ts := NewFixedTimestep(stepYears, 0)
+
+			total := 0.0
+			steps := 0
+			for _, years := range ticks {
+				total += years
+				steps += ts.Advance(years)
+			}
+
+			want := int(total / stepYears)
+			if steps != want {
+				t.Errorf("got %d steps for %.0f years, want %d", steps, total, want)
+			}
+			if int64(steps) != ts.TotalSteps {
+				t.Errorf("TotalSteps %d does not match steps returned %d", ts.TotalSteps, steps)
+			}
+			if pending := ts.Pending(); pending < 0 || pending >= stepYears {
+				t.Errorf("leftover %.1f years outside [0, %.0f)", pending, stepYears)
+			}
+		})
+	}
+}
+
+// TestFixedTimestepMaxSteps checks the per-tick cap drops backlog instead of accumulating it
+func TestFixedTimestepMaxSteps(t *testing.T) {
+	ts := NewFixedTimestep(1000.0, 10)
+
+	if steps := ts.Advance(50500.0); steps != 10 {
+		t.Fatalf("got %d steps, want cap of 10", steps)
+	}
+	if ts.DroppedYears != 40000.0 {
+		t.Errorf("dropped %.0f years, want 40000", ts.DroppedYears)
+	}
+	if ts.Pending() != 500.0 {
+		t.Errorf("pending %.0f years, want 500", ts.Pending())
+	}
+}
+
+// repeat returns n copies of years
+func repeat(years float64, n int) []float64 {
+	ticks := make([]float64, n)
+	for i := range ticks {
+		ticks[i] = years
+	}
+	return ticks
+}
+
+// jitter returns n whole-year tick lengths in [min, max)
+func jitter(n int, min, max int, seed int64) []float64 {
+	rng := rand.New(rand.NewSource(seed))
+	ticks := make([]float64, n)
+	for i := range ticks {
+		ticks[i] = float64(min + rng.Intn(max-min))
+	}
+	return ticks
+}
@@ -0,0 +1,93 @@
+package physics
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// benchmarkPlanetSizes are the grids BenchmarkFullStep steps, from a quick
+// test planet to the interactive default's surface resolution
+var benchmarkPlanetSizes = []struct {
+	shells, bands int
+}{
+	{8, 45},
+	{12, 90},
+	{16, 180},
+}
+
+// BenchmarkFullStep measures one complete CPU physics step - temperature,
+// convection, mechanics, plates and advection, as StepCPU runs them - on
+// planets of growing shell count and resolution, reporting voxels stepped
+// per second. It ends with a table splitting each size's step by subsystem,
+// to show where GPU offload would pay off. No rendering is involved:
+//
+//	go test ./physics -run '^$' -bench FullStep
+func BenchmarkFullStep(b *testing.B) {
+	const dt = 1000.0
+
+	type result struct {
+		name    string
+		voxels  int
+		perStep time.Duration
+		timings PhysicsTimings // Per step
+	}
+	var results []result
+
+	for _, size := range benchmarkPlanetSizes {
+		name := fmt.Sprintf("shells=%d/bands=%d", size.shells, size.bands)
+		planet := testPlanet(size.shells, surfaceBands(size.bands))
+		voxels := 0
+		for _, shell := range planet.Shells {
+			for _, band := range shell.Voxels {
+				voxels += len(band)
+			}
+		}
+
+		// The first step builds the physics systems and identifies plates
+		StepCPU(planet, dt)
+
+		var last result
+		b.Run(name, func(b *testing.B) {
+			var timings PhysicsTimings
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				updateVoxelPhysics(planet, dt, nil, &timings)
+				planet.Time += dt
+			}
+			b.StopTimer()
+
+			perStep := b.Elapsed() / time.Duration(b.N)
+			b.ReportMetric(float64(voxels)/perStep.Seconds(), "voxels/s")
+			last = result{name: name, voxels: voxels, perStep: perStep, timings: timings.perStep(b.N)}
+		})
+		if last.name != "" {
+			results = append(results, last)
+		}
+	}
+
+	var table strings.Builder
+	fmt.Fprintf(&table, "\n%-22s %9s %10s %12s  %s\n", "planet", "voxels", "ms/step", "voxels/s", "subsystems (ms/step)")
+	for _, r := range results {
+		fmt.Fprintf(&table, "%-22s %9d %10.1f %12.3g  %s\n", r.name, r.voxels,
+			r.perStep.Seconds()*1000, float64(r.voxels)/r.perStep.Seconds(), r.timings)
+	}
+	// Printed rather than logged: go test hides a parent benchmark's log
+	fmt.Print(table.String())
+}
+
+// perStep divides timings summed over steps steps down to one step
+func (t PhysicsTimings) perStep(steps int) PhysicsTimings {
+	n := time.Duration(max(steps, 1))
+	return PhysicsTimings{
+		Temperature: t.Temperature / n,
+		Convection:  t.Convection / n,
+		Mechanics:   t.Mechanics / n,
+		Plates:      t.Plates / n,
+		Advection:   t.Advection / n,
+		Other:       t.Other / n,
+		Climate:     t.Climate / n,
+		Total:       t.Total / n,
+	}
+}
@@ -0,0 +1,74 @@
+package physics
+
+import (
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// updateGolden rewrites the golden hash instead of checking it. After an
+// intentional physics change, regenerate with:
+//
+//	go test ./physics -run TestGoldenPlanet -update-golden
+//
+// and commit testdata/golden_planet.hash along with the change
+var updateGolden = flag.Bool("update-golden", false, "rewrite testdata/golden_planet.hash from the current physics")
+
+const goldenPlanetPath = "testdata/golden_planet.hash"
+
+// hashPlanet hashes every voxel's material, elevation to the meter and
+// temperature to a tenth of a kelvin, so only meaningful changes alter the hash
+func hashPlanet(planet *core.VoxelPlanet) string {
+	h := fnv.New64a()
+	var buf [12]byte
+	for _, shell := range planet.Shells {
+		for _, band := range shell.Voxels {
+			for _, voxel := range band {
+				binary.LittleEndian.PutUint32(buf[0:], uint32(voxel.Type))
+				binary.LittleEndian.PutUint32(buf[4:], uint32(int32(math.Round(float64(voxel.Elevation)))))
+				binary.LittleEndian.PutUint32(buf[8:], uint32(int32(math.Round(float64(voxel.Temperature)*10))))
+				h.Write(buf[:])
+			}
+		}
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// TestGoldenPlanet steps the small testPlanet(5) and compares the result
+// against the checked-in hash, catching any change in physics behavior
+func TestGoldenPlanet(t *testing.T) {
+	planet := testPlanet(5)
+	for step := 0; step < 20; step++ {
+		StepCPU(planet, 10000.0)
+	}
+	got := hashPlanet(planet)
+
+	if *updateGolden {
+		if err := os.MkdirAll(filepath.Dir(goldenPlanetPath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(goldenPlanetPath, []byte(got+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("wrote golden hash %s", got)
+		return
+	}
+
+	data, err := os.ReadFile(goldenPlanetPath)
+	if err != nil {
+		t.Fatalf("missing golden hash (run with -update-golden to create it): %v", err)
+	}
+	if want := strings.TrimSpace(string(data)); got != want {
+		t.Errorf("golden planet hash %s, want %s\n"+
+			"If this physics change is intentional, regenerate with: go test ./physics -run TestGoldenPlanet -update-golden",
+			got, want)
+	}
+}
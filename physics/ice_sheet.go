@@ -0,0 +1,125 @@
+package physics
+
+import (
+	"math"
+
+	"worldgenerator/core"
+)
+
+const (
+	// maxIceThickness caps an ice sheet (m): past a few kilometers ice flows
+	// out to its margins as fast as snow piles onto it
+	maxIceThickness = 4000.0
+
+	// iceAblationRate is the meters of ice melted a year for each kelvin the
+	// surface stands above freezing
+	iceAblationRate = 1.0
+
+	// stormSnowRate is the snowfall in m/year of water per kg/m³ of vapor
+	// cold air can hold. It stands in for the moisture storms carry poleward,
+	// which UpdatePrecipitation, blowing only along latitude bands and
+	// recharging over open water, leaves out: ~0.3 m/year at freezing, a few
+	// centimeters in polar air
+	stormSnowRate = 60.0
+)
+
+// holdsIceSheet reports whether snow can pile up into an ice sheet on a
+// surface voxel: land, not open water, sea ice or lava
+func holdsIceSheet(voxel *core.VoxelMaterial) bool {
+	switch voxel.Type {
+	case core.MatAir, core.MatWater, core.MatIce, core.MatMagma:
+		return false
+	}
+	return true
+}
+
+// UpdateIceSheets grows and melts the ice sheets on land over dt years
+// Snow falling where the air, cooled with height above sea level, stays
+// below freezing builds ice on the voxel's IceThickness; above freezing the
+// ice melts back. Snow is the voxel's Precipitation, or what storms bring
+// where that is less, and colder air brings less. The bedrock sinks under
+// the ice's weight, by the ratio of ice to mantle density, and rebounds as
+// it melts. Water locked into ice leaves the ocean's budget and meltwater
+// returns to it, so sea level falls as the caps grow and rises as they
+// shrink. Ice on a voxel that is no longer land - drifted out to sea or
+// melted into lava - calves into the ocean
+// Ice shows as MatIce from above (see core.VoxelMaterial.SurfaceType), so it
+// takes the ice albedo in UpdateAtmosphere and cools the air that built it.
+// The feedback is bounded: ice stops at maxIceThickness, and the colder the
+// air the less snow it brings
+func UpdateIceSheets(planet *core.VoxelPlanet, dt float64) {
+	if len(planet.Shells) < 2 || dt <= 0 {
+		return
+	}
+	air := &planet.Shells[len(planet.Shells)-1]
+	surface := &planet.Shells[len(planet.Shells)-2]
+
+	iceDensity := float64(core.MaterialProperties[core.MatIce].DefaultDensity)
+	waterDensity := float64(core.MaterialProperties[core.MatWater].DefaultDensity)
+	isostasy := iceDensity / float64(core.MaterialProperties[core.MatPeridotite].DefaultDensity)
+
+	// Net ice formed this step (m³)
+	grown := 0.0
+	for latIdx := range surface.Voxels {
+		area := planet.VoxelArea(surface, latIdx)
+		for lonIdx := range surface.Voxels[latIdx] {
+			voxel := &surface.Voxels[latIdx][lonIdx]
+			old := float64(voxel.IceThickness)
+			if !holdsIceSheet(voxel) {
+				if old > 0 {
+					voxel.IceThickness = 0
+					grown -= old * area
+				}
+				continue
+			}
+
+			above := columnAbove(air, surface, latIdx, lonIdx)
+			if above == nil {
+				continue
+			}
+			height := math.Max(0, float64(voxel.Elevation)+old-planet.SeaLevel)
+			temp := float64(above.Temperature) - biomeLapseRate*height
+
+			thickness := old
+			if temp < freezingPoint {
+				snow := math.Max(float64(voxel.Precipitation), stormSnowRate*saturationVaporDensity(temp))
+				thickness += snow * dt * waterDensity / iceDensity
+			} else {
+				thickness -= iceAblationRate * (temp - freezingPoint) * dt
+			}
+			thickness = math.Max(0, math.Min(maxIceThickness, thickness))
+			if thickness == old {
+				continue
+			}
+
+			voxel.IceThickness = float32(thickness)
+			voxel.Elevation -= float32((thickness - old) * isostasy)
+			grown += (thickness - old) * area
+		}
+	}
+
+	exchangeIce(planet, grown)
+}
+
+// iceVolume returns the volume of the ice sheets on a surface shell (m³)
+func iceVolume(planet *core.VoxelPlanet, shell *core.SphericalShell) float64 {
+	volume := 0.0
+	for latIdx := range shell.Voxels {
+		area := planet.VoxelArea(shell, latIdx)
+		for lonIdx := range shell.Voxels[latIdx] {
+			volume += float64(shell.Voxels[latIdx][lonIdx].IceThickness) * area
+		}
+	}
+	return volume
+}
+
+// exchangeIce moves the water in grown m³ of new ice out of the ocean's
+// budget, or back into it when grown is negative
+func exchangeIce(planet *core.VoxelPlanet, grown float64) {
+	if grown == 0 || planet.WaterBudget <= 0 {
+		return
+	}
+	iceDensity := float64(core.MaterialProperties[core.MatIce].DefaultDensity)
+	waterDensity := float64(core.MaterialProperties[core.MatWater].DefaultDensity)
+	planet.WaterBudget = math.Max(0, planet.WaterBudget-grown*iceDensity/waterDensity)
+}
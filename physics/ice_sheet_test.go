@@ -0,0 +1,145 @@
+package physics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"worldgenerator/core"
+)
+
+// TestIceSheetLoadsAndReleasesWater grows ice on one cold land voxel and
+// melts it again: the bedrock sinks and rebounds, and the water the ice held
+// leaves the ocean and comes back
+func TestIceSheetLoadsAndReleasesWater(t *testing.T) {
+	planet := core.CreateVoxelPlanet(6371000.0, 8)
+	planet.SeaLevel = 0
+	planet.WaterBudget = planet.TotalWaterVolume()
+	budget := planet.WaterBudget
+	air := &planet.Shells[len(planet.Shells)-1]
+	surface := &planet.Shells[len(planet.Shells)-2]
+
+	latIdx, lonIdx := 2, 0
+	land := &surface.Voxels[latIdx][lonIdx]
+	*land = core.VoxelMaterial{Type: core.MatGranite, Density: 2700, Temperature: 250, Elevation: 500, Precipitation: 0.5}
+	columnAbove(air, surface, latIdx, lonIdx).Temperature = 250
+
+	UpdateIceSheets(planet, 100)
+	ice := 0.5 * 100 * 1000 / 917.0
+	if math.Abs(float64(land.IceThickness)-ice) > 0.01 {
+		t.Fatalf("100 years of snow built %.2f m of ice, want %.2f m", land.IceThickness, ice)
+	}
+	if land.SurfaceType() != core.MatIce {
+		t.Errorf("ice-covered land shows as %s", core.MaterialName(land.SurfaceType()))
+	}
+	if depressed := 500 - float64(land.Elevation); math.Abs(depressed-ice*917/3300) > 0.01 {
+		t.Errorf("bedrock sank %.2f m under %.2f m of ice, want %.2f m", depressed, ice, ice*917/3300)
+	}
+	locked := ice * planet.VoxelArea(surface, latIdx) * 917 / 1000
+	if got := budget - planet.WaterBudget; math.Abs(got-locked) > 1e-6*locked {
+		t.Errorf("ocean lost %.4e m³ to the ice, want %.4e m³", got, locked)
+	}
+
+	// A warm spell melts it all back
+	columnAbove(air, surface, latIdx, lonIdx).Temperature = 290
+	UpdateIceSheets(planet, 100)
+	if land.IceThickness != 0 || land.SurfaceType() != core.MatGranite {
+		t.Errorf("%.2f m of ice left at 290 K", land.IceThickness)
+	}
+	if math.Abs(float64(land.Elevation)-500) > 0.01 {
+		t.Errorf("bedrock rebounded to %.2f m, want 500 m", land.Elevation)
+	}
+	if math.Abs(planet.WaterBudget-budget) > 1e-9*budget {
+		t.Errorf("ocean holds %.6e m³ after the melt, want %.6e m³", planet.WaterBudget, budget)
+	}
+}
+
+// TestPolarCapsFollowClimate runs the climate over a pole-to-pole plateau:
+// ice caps grow from its ends but stop short of the tropics, and a stronger
+// greenhouse drives their edges back toward the poles
+func TestPolarCapsFollowClimate(t *testing.T) {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 8, 36)
+	surfaceIdx := len(planet.Shells) - 2
+	surface := &planet.Shells[surfaceIdx]
+	for latIdx := range surface.Voxels {
+		for lonIdx := range surface.Voxels[latIdx] {
+			_, lon := planet.VoxelLatLon(core.VoxelCoord{Shell: surfaceIdx, Lat: latIdx, Lon: lonIdx})
+			if math.Abs(lon) < 30 {
+				surface.Voxels[latIdx][lonIdx] = core.VoxelMaterial{Type: core.MatGranite, Density: 2700, Temperature: 288, Elevation: 2000}
+			}
+		}
+	}
+	planet.SeaLevel = 0
+	planet.WaterBudget = planet.TotalWaterVolume()
+
+	climate := func(years int) {
+		for year := 0; year < years; year++ {
+			UpdateAtmosphere(planet, 1.0)
+			UpdatePrecipitation(planet)
+			UpdateIceSheets(planet, 1.0)
+		}
+	}
+	// Latitude of the ice nearest the equator, and whether any covers a pole
+	iceEdge := func() (edge float64, polar bool) {
+		edge = 90
+		for latIdx := range surface.Voxels {
+			lat := math.Abs(core.GetLatitudeForBand(latIdx, surface.LatBands))
+			for _, voxel := range surface.Voxels[latIdx] {
+				if voxel.IceThickness > 0 {
+					edge = math.Min(edge, lat)
+					polar = polar || lat > 70
+				}
+			}
+		}
+		return edge, polar
+	}
+
+	climate(200)
+	glacial, polar := iceEdge()
+	if !polar {
+		t.Fatal("no ice built up at the poles")
+	}
+	if glacial < 30 {
+		t.Errorf("glaciation reached %.1f°, into the tropics", glacial)
+	}
+
+	planet.GreenhouseStrength = 1
+	climate(200)
+	if warm, _ := iceEdge(); warm <= glacial {
+		t.Errorf("ice reaches %.1f° in a warmer climate, want its edge back from %.1f°", warm, glacial)
+	}
+}
+
+// TestEngineConservesIceAndOcean grows ice caps through the threaded engine,
+// whose two buffers take turns stepping while plates carry the ice about,
+// and checks the ocean's budget and the water held as ice add up to the
+// ocean the planet started with
+func TestEngineConservesIceAndOcean(t *testing.T) {
+	planet := testPlanet(5)
+	planet.SeaLevel = 0
+	planet.WaterBudget = planet.TotalWaterVolume()
+	surface := len(planet.Shells) - 2
+	water := func(p *core.VoxelPlanet) float64 {
+		return p.WaterBudget + iceVolume(p, &p.Shells[surface])*917/1000
+	}
+	want := water(planet)
+	ice := iceVolume(planet, &planet.Shells[surface])
+
+	engine := NewThreadedPhysicsEngine(planet, nil, 100000.0)
+	engine.SetFixedTick(0.1)
+	now := engine.lastPhysicsTime
+	for tick := 1; tick <= 8; tick++ {
+		now = now.Add(100 * time.Millisecond)
+		engine.tick(now)
+
+		live := engine.GetCurrentPlanet()
+		if got := water(live); math.Abs(got-want) > 1e-6*want {
+			t.Errorf("tick %d: ocean %.6e m³ and ice %.6e m³ of water add to %.6e m³, want %.6e m³",
+				tick, live.WaterBudget, got-live.WaterBudget, got, want)
+		}
+	}
+	live := engine.GetCurrentPlanet()
+	if grown := iceVolume(live, &live.Shells[surface]); grown <= ice {
+		t.Errorf("ice went from %.4e m³ to %.4e m³, want the caps to grow", ice, grown)
+	}
+}
@@ -0,0 +1,95 @@
+package physics
+
+import (
+	"math"
+
+	"worldgenerator/core"
+)
+
+const (
+	plumeRadiusDegrees = 10.0  // Gaussian radius of a plume's hot root
+	plumeSourceShells  = 3     // Shells of the core-mantle boundary a plume heats, as initializeMantlePlumes
+	plumeConduitWeight = 0.5   // Share of the center's heat within which voxels rise in the conduit
+	plumeRiseRate      = 0.001 // Radial velocity per year of step given to the conduit, as UpdateMantlePlumes
+)
+
+// InjectPlume roots a mantle plume at the core-mantle boundary under
+// latDeg/lonDeg, heating a Gaussian region temperatureBoost K above the
+// surrounding mantle at its center. The plume is recorded on the planet and
+// kept hot and rising by UpdatePlumes every step, so it keeps feeding the
+// surface above it as plates drift over it
+func (va *VoxelAdvection) InjectPlume(latDeg, lonDeg, temperatureBoost float64) {
+	injectPlume(va.planet, core.MantlePlume{Lat: latDeg, Lon: lonDeg, TemperatureBoost: temperatureBoost})
+}
+
+// UpdatePlumes keeps every injected plume's root hot and its conduit rising,
+// so upwelling carries the heat toward the surface
+func (va *VoxelAdvection) UpdatePlumes(dt float64) {
+	for _, plume := range va.planet.Plumes {
+		heatPlume(va.planet, plume)
+		raisePlume(va.planet, plume, dt)
+	}
+}
+
+// injectPlume records a plume on the planet and heats its root at once
+func injectPlume(planet *core.VoxelPlanet, plume core.MantlePlume) {
+	planet.Plumes = append(planet.Plumes, plume)
+	heatPlume(planet, plume)
+}
+
+// plumeWeight is the share of a plume's temperature boost at lat/lon in
+// degrees, 1 at its center and falling off as a Gaussian
+func plumeWeight(plume core.MantlePlume, lat, lon float64) float64 {
+	d := core.AngularDistance(lat, lon, plume.Lat, plume.Lon) / plumeRadiusDegrees
+	return math.Exp(-d * d)
+}
+
+// heatPlume raises the voxels of a plume's root to at least their shell's
+// average temperature plus the plume's boost, weighted by distance from its
+// center. Raising rather than adding keeps a persistent plume from running
+// away
+func heatPlume(planet *core.VoxelPlanet, plume core.MantlePlume) {
+	for shellIdx := 0; shellIdx < plumeSourceShells && shellIdx < len(planet.Shells)-2; shellIdx++ {
+		shell := &planet.Shells[shellIdx]
+		ambient := averageTemperature(shell)
+		changed := false
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				lat, lon := planet.VoxelLatLon(core.VoxelCoord{Shell: shellIdx, Lat: latIdx, Lon: lonIdx})
+				weight := plumeWeight(plume, lat, lon)
+				if weight < 0.01 {
+					continue
+				}
+				voxel := &shell.Voxels[latIdx][lonIdx]
+				if target := float32(ambient + plume.TemperatureBoost*weight); voxel.Temperature < target {
+					voxel.Temperature = target
+					changed = true
+				}
+			}
+		}
+		if changed {
+			planet.MarkShellDirty(shellIdx)
+		}
+	}
+}
+
+// raisePlume gives the conduit above a plume's root an upward velocity from
+// the core-mantle boundary up to the crust below the surface shell
+func raisePlume(planet *core.VoxelPlanet, plume core.MantlePlume, dt float64) {
+	rise := float32(plumeRiseRate * dt)
+	for shellIdx := 0; shellIdx < len(planet.Shells)-2; shellIdx++ {
+		shell := &planet.Shells[shellIdx]
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				voxel := &shell.Voxels[latIdx][lonIdx]
+				if voxel.Type == core.MatAir || voxel.Type == core.MatWater {
+					continue
+				}
+				lat, lon := planet.VoxelLatLon(core.VoxelCoord{Shell: shellIdx, Lat: latIdx, Lon: lonIdx})
+				if plumeWeight(plume, lat, lon) >= plumeConduitWeight && voxel.VelR < rise {
+					voxel.VelR = rise
+				}
+			}
+		}
+	}
+}
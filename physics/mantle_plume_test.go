@@ -0,0 +1,57 @@
+package physics
+
+import (
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// TestInjectPlume checks a plume heats the core-mantle boundary under it and
+// nowhere else, survives the physics double buffering, and keeps its root hot
+// and its heat rising over later steps
+func TestInjectPlume(t *testing.T) {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 8, 24)
+	vp := NewVoxelPhysics(planet)
+	planet.Physics = vp
+
+	// Centered on a voxel of the innermost shell, so it gets the full boost
+	const boost = 1000.0
+	lat, lon := planet.VoxelLatLon(core.VoxelCoord{Shell: 0, Lat: 12, Lon: 5})
+	root := planet.Column(lat, lon)
+	far := planet.Column(-lat, lon+180)
+	ambient := averageTemperature(&planet.Shells[0])
+	farBefore := far[0].Temperature
+
+	vp.advection.InjectPlume(lat, lon, boost)
+	if len(planet.Plumes) != 1 {
+		t.Fatalf("planet has %d plumes after one injection", len(planet.Plumes))
+	}
+	if got := float64(root[0].Temperature); got < ambient+0.9*boost {
+		t.Errorf("plume root at %.0f K, want about %.0f K", got, ambient+boost)
+	}
+	if far[0].Temperature != farBefore {
+		t.Errorf("voxel across the planet went from %.0f K to %.0f K", farBefore, far[0].Temperature)
+	}
+	if copied := deepCopyPlanet(planet); len(copied.Plumes) != 1 {
+		t.Errorf("copied planet has %d plumes", len(copied.Plumes))
+	}
+
+	// The root stays hot and the conduit above it warms faster than the mantle
+	// away from it
+	const conduitShell = 3
+	conduitBefore, farConduitBefore := root[conduitShell].Temperature, far[conduitShell].Temperature
+	for step := 0; step < 5; step++ {
+		StepCPU(planet, 10000.0)
+	}
+	if got, want := float64(root[0].Temperature), averageTemperature(&planet.Shells[0])+0.9*boost; got < want {
+		t.Errorf("plume root cooled to %.0f K, want at least %.0f K", got, want)
+	}
+	if root[conduitShell-1].VelR <= 0 {
+		t.Errorf("plume conduit isn't rising, radial velocity %g", root[conduitShell-1].VelR)
+	}
+	warming := root[conduitShell].Temperature - conduitBefore
+	farWarming := far[conduitShell].Temperature - farConduitBefore
+	if warming <= farWarming {
+		t.Errorf("shell %d warmed %.2f K over the plume and %.2f K away from it", conduitShell, warming, farWarming)
+	}
+}
@@ -0,0 +1,33 @@
+package physics
+
+import "sync"
+
+// forEachBand calls fn for every latitude band below bands, splitting them
+// into contiguous ranges across workers goroutines and returning once all are
+// done. fn may read anything but must write only to its own band, so the
+// result is the same for any worker count; passes that write neighboring
+// bands stay serial. workers of 1 or less runs in order on the caller
+func forEachBand(workers, bands int, fn func(latIdx int)) {
+	if workers > bands {
+		workers = bands
+	}
+	if workers <= 1 {
+		for latIdx := 0; latIdx < bands; latIdx++ {
+			fn(latIdx)
+		}
+		return
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		start, end := w*bands/workers, (w+1)*bands/workers
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for latIdx := start; latIdx < end; latIdx++ {
+				fn(latIdx)
+			}
+		}()
+	}
+	wg.Wait()
+}
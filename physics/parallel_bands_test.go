@@ -0,0 +1,72 @@
+package physics
+
+import (
+	"bytes"
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// TestWorkerThreadsMatchSerial checks splitting the CPU passes across
+// goroutines leaves the planet bit identical to stepping it serially
+func TestWorkerThreadsMatchSerial(t *testing.T) {
+	var results [2][]byte
+	var seaLevels [2]float64
+	for run, threads := range []int{1, 4} {
+		planet := testPlanet(5)
+		planet.WorkerThreads = threads
+		for step := 0; step < 20; step++ {
+			StepCPU(planet, 10000.0)
+		}
+		results[run] = voxelBytes(t, planet)
+		seaLevels[run] = planet.SeaLevel
+	}
+
+	if !bytes.Equal(results[0], results[1]) {
+		first := 0
+		for first < len(results[0]) && results[0][first] == results[1][first] {
+			first++
+		}
+		t.Errorf("4 threads diverge from serial at byte %d of %d", first, len(results[0]))
+	}
+	if seaLevels[0] != seaLevels[1] {
+		t.Errorf("sea level %g m with 4 threads, %g m serially", seaLevels[1], seaLevels[0])
+	}
+}
+
+// BenchmarkWorkerThreads steps the largest BenchmarkFullStep planet with
+// more and more worker goroutines, reporting the speedup over one:
+//
+//	go test ./physics -run '^$' -bench WorkerThreads
+func BenchmarkWorkerThreads(b *testing.B) {
+	const dt = 1000.0
+	size := benchmarkPlanetSizes[len(benchmarkPlanetSizes)-1]
+	planet := testPlanet(size.shells, surfaceBands(size.bands))
+	StepCPU(planet, dt)
+
+	counts := []int{1, 2, 4}
+	if runtime.NumCPU() > 4 {
+		counts = append(counts, runtime.NumCPU())
+	}
+
+	var serial time.Duration
+	for _, threads := range counts {
+		b.Run(fmt.Sprintf("threads=%d", threads), func(b *testing.B) {
+			planet.WorkerThreads = threads
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				StepCPU(planet, dt)
+			}
+			b.StopTimer()
+
+			perStep := b.Elapsed() / time.Duration(b.N)
+			if threads == 1 {
+				serial = perStep
+			}
+			if serial > 0 {
+				b.ReportMetric(serial.Seconds()/perStep.Seconds(), "speedup")
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package physics
+
+import (
+	"fmt"
+	"math"
+
+	"worldgenerator/core"
+)
+
+// Limits past which a voxel's state can only come from a numerical blow-up
+const (
+	maxCheckTemperature = 10000.0 // K
+	maxCheckSpeed       = 1000.0  // m/s of wind or m/year of rock, far beyond either
+)
+
+// PhysicsViolation is a voxel a physics phase left in an impossible state
+type PhysicsViolation struct {
+	Phase string  // Physics phase that just ran
+	Year  float64 // Simulation time of the step
+	Coord core.VoxelCoord
+	Type  core.MaterialType
+	Field string // Offending VoxelMaterial field
+	Value float64
+}
+
+func (v *PhysicsViolation) Error() string {
+	return fmt.Sprintf("after %s at year %.0f: voxel shell %d lat %d lon %d (%s) has %s = %g",
+		v.Phase, v.Year, v.Coord.Shell, v.Coord.Lat, v.Coord.Lon, core.MaterialName(v.Type), v.Field, v.Value)
+}
+
+// checkedField reads one value of a voxel and reports whether it is possible
+type checkedField struct {
+	name  string
+	value func(voxel *core.VoxelMaterial) float32
+	valid func(value, radius float64) bool
+}
+
+// finite accepts any value that isn't NaN or infinite
+func finite(value, radius float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
+
+// speed accepts a finite velocity component below maxCheckSpeed
+func speed(value, radius float64) bool {
+	return math.Abs(value) <= maxCheckSpeed
+}
+
+var checkedFields = []checkedField{
+	{"Temperature", func(v *core.VoxelMaterial) float32 { return v.Temperature }, func(value, radius float64) bool {
+		return value >= 0 && value <= maxCheckTemperature
+	}},
+	{"Density", func(v *core.VoxelMaterial) float32 { return v.Density }, finite},
+	{"Pressure", func(v *core.VoxelMaterial) float32 { return v.Pressure }, finite},
+	// Convection scales VelR with the step length, so only NaN is an error
+	{"VelR", func(v *core.VoxelMaterial) float32 { return v.VelR }, finite},
+	{"VelNorth", func(v *core.VoxelMaterial) float32 { return v.VelNorth }, speed},
+	{"VelEast", func(v *core.VoxelMaterial) float32 { return v.VelEast }, speed},
+	{"Elevation", func(v *core.VoxelMaterial) float32 { return v.Elevation }, func(value, radius float64) bool {
+		return math.Abs(value) <= radius
+	}},
+	{"Age", func(v *core.VoxelMaterial) float32 { return v.Age }, finite},
+	{"Stress", func(v *core.VoxelMaterial) float32 { return v.Stress }, finite},
+	{"SubPosLat", func(v *core.VoxelMaterial) float32 { return v.SubPosLat }, finite},
+	{"SubPosLon", func(v *core.VoxelMaterial) float32 { return v.SubPosLon }, finite},
+	{"SubPosR", func(v *core.VoxelMaterial) float32 { return v.SubPosR }, finite},
+	{"WaterVapor", func(v *core.VoxelMaterial) float32 { return v.WaterVapor }, finite},
+	{"Precipitation", func(v *core.VoxelMaterial) float32 { return v.Precipitation }, finite},
+	{"IceThickness", func(v *core.VoxelMaterial) float32 { return v.IceThickness }, func(value, radius float64) bool {
+		return value >= 0 && value <= maxIceThickness
+	}},
+	{"Sediment", func(v *core.VoxelMaterial) float32 { return v.Sediment }, func(value, radius float64) bool {
+		return value >= 0 && value <= radius
+	}},
+}
+
+// CheckPlanet scans every voxel for NaN, infinite or out-of-range values and
+// returns the first it finds, blamed on phase, or nil if the planet is sound
+// Comparisons are written so NaN always fails them
+func CheckPlanet(planet *core.VoxelPlanet, phase string) *PhysicsViolation {
+	for shellIdx := range planet.Shells {
+		shell := &planet.Shells[shellIdx]
+		for latIdx := range shell.Voxels {
+			for lonIdx := range shell.Voxels[latIdx] {
+				voxel := &shell.Voxels[latIdx][lonIdx]
+				for _, field := range checkedFields {
+					value := float64(field.value(voxel))
+					if !field.valid(value, planet.Radius) {
+						return &PhysicsViolation{
+							Phase: phase,
+							Year:  planet.Time,
+							Coord: core.VoxelCoord{Shell: shellIdx, Lat: latIdx, Lon: lonIdx},
+							Type:  voxel.Type,
+							Field: field.name,
+							Value: value,
+						}
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// checkPhase runs the planet's physics check after a phase, recording and
+// logging the first violation. Once one is found later phases aren't checked,
+// since a NaN spreads to every neighbor and would only repeat it
+func checkPhase(planet *core.VoxelPlanet, phase string) {
+	if planet.PhysicsCheck == core.PhysicsCheckOff || planet.PhysicsFault != nil {
+		return
+	}
+	if violation := CheckPlanet(planet, phase); violation != nil {
+		planet.PhysicsFault = violation
+		fmt.Printf("❌ Physics check failed %v\n", violation)
+		if planet.PhysicsCheck == core.PhysicsCheckHalt {
+			fmt.Println("⏸️  Physics halted by -physics-check-halt")
+		}
+	}
+}
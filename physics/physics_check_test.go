@@ -0,0 +1,71 @@
+package physics
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// TestPhysicsCheckFindsNaN checks a clean step passes the physics check, that
+// a NaN injected into a voxel is reported against the first phase after it
+// and that halting stops further steps
+func TestPhysicsCheckFindsNaN(t *testing.T) {
+	planet := testPlanet(8, surfaceBands(core.DefaultSurfaceBands))
+	planet.PhysicsCheck = core.PhysicsCheckHalt
+
+	StepCPU(planet, 1000)
+	if planet.PhysicsFault != nil {
+		t.Fatalf("clean step failed the physics check: %v", planet.PhysicsFault)
+	}
+
+	shellIdx := len(planet.Shells) / 2
+	planet.Shells[shellIdx].Voxels[3][5].Temperature = float32(math.NaN())
+	StepCPU(planet, 1000)
+
+	var violation *PhysicsViolation
+	if !errors.As(planet.PhysicsFault, &violation) {
+		t.Fatalf("NaN temperature not detected, fault = %v", planet.PhysicsFault)
+	}
+	if violation.Phase != "temperature" || violation.Field != "Temperature" || !math.IsNaN(violation.Value) {
+		t.Errorf("reported %v, want the NaN temperature after the temperature phase", violation)
+	}
+	if violation.Year != 1000 {
+		t.Errorf("reported year %.0f, want 1000", violation.Year)
+	}
+
+	if !planet.Halted() {
+		t.Fatal("planet not halted after the violation")
+	}
+	StepCPU(planet, 1000)
+	if planet.Time != 2000 {
+		t.Errorf("halted planet stepped to year %.0f, want it held at 2000", planet.Time)
+	}
+}
+
+// TestCheckPlanetRanges checks finite values outside their physical ranges
+// are reported too
+func TestCheckPlanetRanges(t *testing.T) {
+	tests := []struct {
+		field  string
+		modify func(voxel *core.VoxelMaterial, radius float64)
+	}{
+		{"Temperature", func(v *core.VoxelMaterial, radius float64) { v.Temperature = -1 }},
+		{"Temperature", func(v *core.VoxelMaterial, radius float64) { v.Temperature = 2e4 }},
+		{"VelEast", func(v *core.VoxelMaterial, radius float64) { v.VelEast = 1e5 }},
+		{"Elevation", func(v *core.VoxelMaterial, radius float64) { v.Elevation = float32(2 * radius) }},
+		{"Pressure", func(v *core.VoxelMaterial, radius float64) { v.Pressure = float32(math.Inf(1)) }},
+	}
+	for _, tt := range tests {
+		planet := testPlanet(8, surfaceBands(core.DefaultSurfaceBands))
+		if v := CheckPlanet(planet, "generation"); v != nil {
+			t.Fatalf("fresh planet failed the check: %v", v)
+		}
+		tt.modify(&planet.Shells[2].Voxels[1][1], planet.Radius)
+		v := CheckPlanet(planet, "test")
+		if v == nil || v.Field != tt.field || v.Coord != (core.VoxelCoord{Shell: 2, Lat: 1, Lon: 1}) {
+			t.Errorf("%s out of range reported %v", tt.field, v)
+		}
+	}
+}
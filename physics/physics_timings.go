@@ -0,0 +1,26 @@
+package physics
+
+import (
+	"fmt"
+	"time"
+)
+
+// PhysicsTimings is how long each subsystem took over one physics tick,
+// summed over every step the tick ran. Durations count nanoseconds
+type PhysicsTimings struct {
+	Temperature time.Duration // Heat diffusion, on the GPU when the backend can
+	Convection  time.Duration // Mantle convection velocities
+	Mechanics   time.Duration // Material mechanics and plate boundary processes
+	Plates      time.Duration // Plate identification, motion and topology
+	Advection   time.Duration // Material movement and arc volcanism
+	Other       time.Duration // Pressure, phase changes, surface, aging and physics checks
+	Climate     time.Duration // Winds and biomes between steps
+	Total       time.Duration // The whole tick
+}
+
+// String lists each subsystem in milliseconds, for the console and overlay
+func (t PhysicsTimings) String() string {
+	ms := func(d time.Duration) float64 { return d.Seconds() * 1000 }
+	return fmt.Sprintf("temp %.1f conv %.1f mech %.1f plates %.1f adv %.1f other %.1f climate %.1f ms",
+		ms(t.Temperature), ms(t.Convection), ms(t.Mechanics), ms(t.Plates), ms(t.Advection), ms(t.Other), ms(t.Climate))
+}
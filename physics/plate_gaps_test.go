@@ -0,0 +1,279 @@
+package physics
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// newPlateGapPlanet floods the surface shell and lays plates side by side on the equator
+// Each entry of plateIDs covers 20 longitude cells of a 21-band-tall block
+func newPlateGapPlanet(plateIDs ...int32) (*VoxelAdvection, *core.SphericalShell, int, int) {
+	planet := core.CreateVoxelPlanet(6371000.0, 8)
+	shell := &planet.Shells[len(planet.Shells)-2]
+
+	for latIdx := range shell.Voxels {
+		for lonIdx := range shell.Voxels[latIdx] {
+			shell.Voxels[latIdx][lonIdx] = core.VoxelMaterial{Type: core.MatWater, StretchFactor: 1.0}
+		}
+	}
+
+	midLat := shell.LatBands / 2
+	startLon := 100
+	for latIdx := midLat - 10; latIdx <= midLat+10; latIdx++ {
+		for i, plateID := range plateIDs {
+			for lonIdx := startLon + i*20; lonIdx < startLon+(i+1)*20; lonIdx++ {
+				shell.Voxels[latIdx][lonIdx] = core.VoxelMaterial{
+					Type:          core.MatGranite,
+					Density:       2700,
+					Temperature:   300,
+					PlateID:       plateID,
+					StretchFactor: 1.0,
+				}
+			}
+		}
+	}
+
+	return &VoxelAdvection{planet: planet}, shell, midLat, startLon
+}
+
+// TestFillPlateGapsEnclosedHole checks a hole inside one plate is filled from that plate
+func TestFillPlateGapsEnclosedHole(t *testing.T) {
+	va, shell, midLat, startLon := newPlateGapPlanet(1)
+
+	hole := [][2]int{{midLat, startLon + 9}, {midLat, startLon + 10}, {midLat + 1, startLon + 9}, {midLat + 1, startLon + 10}}
+	for _, h := range hole {
+		shell.Voxels[h[0]][h[1]] = core.VoxelMaterial{Type: core.MatWater, StretchFactor: 1.0}
+	}
+
+	va.fillPlateGaps(&shell.Voxels, shell)
+
+	for _, h := range hole {
+		voxel := &shell.Voxels[h[0]][h[1]]
+		if voxel.Type != core.MatGranite || voxel.PlateID != 1 || !voxel.IsTransient {
+			t.Errorf("hole voxel %v: got type %v plate %d transient %v, want filled granite of plate 1",
+				h, voxel.Type, voxel.PlateID, voxel.IsTransient)
+		}
+		if voxel.Temperature != 300 || voxel.Density != 2700 {
+			t.Errorf("hole voxel %v: got %.0f K, %.0f kg/m³, want plate averages", h, voxel.Temperature, voxel.Density)
+		}
+	}
+
+	// Open ocean around the plate stays water
+	if ocean := &shell.Voxels[midLat][startLon-1]; ocean.Type != core.MatWater {
+		t.Errorf("ocean next to plate became %v", ocean.Type)
+	}
+	if ocean := &shell.Voxels[midLat][0]; ocean.Type != core.MatWater {
+		t.Errorf("distant ocean became %v", ocean.Type)
+	}
+}
+
+// TestFillPlateGapsBetweenPlates checks a hole on a boundary is split between
+// the plates on either side, each voxel going to the plate surrounding it
+func TestFillPlateGapsBetweenPlates(t *testing.T) {
+	va, shell, midLat, startLon := newPlateGapPlanet(1, 2)
+
+	// Straddles the boundary between plate 1 and plate 2
+	hole := [][2]int{{midLat, startLon + 19}, {midLat, startLon + 20}}
+	for _, h := range hole {
+		shell.Voxels[h[0]][h[1]] = core.VoxelMaterial{Type: core.MatWater, StretchFactor: 1.0}
+	}
+
+	va.fillPlateGaps(&shell.Voxels, shell)
+
+	for i, h := range hole {
+		want := int32(i + 1)
+		if voxel := &shell.Voxels[h[0]][h[1]]; voxel.Type != core.MatGranite || voxel.PlateID != want {
+			t.Errorf("boundary hole voxel %v: got %v of plate %d, want granite of plate %d", h, voxel.Type, voxel.PlateID, want)
+		}
+	}
+
+	// Ocean along the plates' edges and corners is only partly surrounded
+	for _, c := range [][2]int{{midLat - 11, startLon + 19}, {midLat + 11, startLon}, {midLat - 11, startLon - 1}} {
+		if ocean := &shell.Voxels[c[0]][c[1]]; ocean.Type != core.MatWater {
+			t.Errorf("ocean at %v along the plates became %v", c, ocean.Type)
+		}
+	}
+}
+
+// TestFillPlateGapsAcrossAntimeridian checks a hole in a plate straddling
+// longitude index 0 is filled from both sides of the seam
+func TestFillPlateGapsAcrossAntimeridian(t *testing.T) {
+	va, shell, midLat, _ := newPlateGapPlanet()
+
+	var hole [][2]int
+	for latIdx := midLat - 10; latIdx <= midLat+10; latIdx++ {
+		lonCount := len(shell.Voxels[latIdx])
+		for offset := -10; offset < 10; offset++ {
+			lonIdx := (offset + lonCount) % lonCount
+			voxel := core.VoxelMaterial{Type: core.MatGranite, Density: 2700, Temperature: 300, PlateID: 1, StretchFactor: 1.0}
+			if (latIdx == midLat || latIdx == midLat+1) && (offset == -1 || offset == 0) {
+				voxel = core.VoxelMaterial{Type: core.MatWater, StretchFactor: 1.0}
+				hole = append(hole, [2]int{latIdx, lonIdx})
+			}
+			shell.Voxels[latIdx][lonIdx] = voxel
+		}
+	}
+
+	va.fillPlateGaps(&shell.Voxels, shell)
+
+	for _, h := range hole {
+		if voxel := &shell.Voxels[h[0]][h[1]]; voxel.Type != core.MatGranite || voxel.PlateID != 1 || !voxel.IsTransient {
+			t.Errorf("hole voxel %v: got type %v plate %d transient %v, want filled granite of plate 1",
+				h, voxel.Type, voxel.PlateID, voxel.IsTransient)
+		}
+	}
+	if ocean := &shell.Voxels[midLat][len(shell.Voxels[midLat])/2]; ocean.Type != core.MatWater {
+		t.Errorf("ocean across the globe became %v", ocean.Type)
+	}
+}
+
+// TestFillPlateGapsCoastalNotch checks a notch in a plate's coast, open to
+// the ocean on one side, is healed, while the middle of an inland lake too
+// wide to be a gap stays water
+func TestFillPlateGapsCoastalNotch(t *testing.T) {
+	va, shell, midLat, startLon := newPlateGapPlanet(1)
+
+	notch := [2]int{midLat + 10, startLon + 5}
+	shell.Voxels[notch[0]][notch[1]] = core.VoxelMaterial{Type: core.MatWater, StretchFactor: 1.0}
+	for latIdx := midLat - 2; latIdx <= midLat+2; latIdx++ {
+		for lonIdx := startLon + 8; lonIdx <= startLon+12; lonIdx++ {
+			shell.Voxels[latIdx][lonIdx] = core.VoxelMaterial{Type: core.MatWater, StretchFactor: 1.0}
+		}
+	}
+
+	va.fillPlateGaps(&shell.Voxels, shell)
+
+	if voxel := &shell.Voxels[notch[0]][notch[1]]; voxel.Type != core.MatGranite || voxel.PlateID != 1 {
+		t.Errorf("coastal notch: got %v of plate %d, want granite of plate 1", voxel.Type, voxel.PlateID)
+	}
+	if lake := &shell.Voxels[midLat][startLon+10]; lake.Type != core.MatWater {
+		t.Errorf("middle of the lake became %v", lake.Type)
+	}
+}
+
+// baselineGapFill applies the gap fill rule on raw band indices, as it was
+// written before it moved onto the shell's neighbor lookups: a water or air
+// voxel beside a plate of at least 10 voxels joins it when 4 of the 8
+// voxels around it are on that plate. It returns the plate each filled
+// voxel joins
+func baselineGapFill(voxels [][]core.VoxelMaterial) map[[2]int]int32 {
+	members := make(map[int32][][2]int)
+	var plateIDs []int32
+	for latIdx := range voxels {
+		for lonIdx, voxel := range voxels[latIdx] {
+			if voxel.PlateID > 0 && (voxel.Type == core.MatGranite || voxel.Type == core.MatBasalt) {
+				if members[voxel.PlateID] == nil {
+					plateIDs = append(plateIDs, voxel.PlateID)
+				}
+				members[voxel.PlateID] = append(members[voxel.PlateID], [2]int{latIdx, lonIdx})
+			}
+		}
+	}
+	sort.Slice(plateIDs, func(i, j int) bool { return plateIDs[i] < plateIDs[j] })
+
+	filled := make(map[[2]int]int32)
+	isGap := func(lat, lon int) bool {
+		if _, ok := filled[[2]int{lat, lon}]; ok {
+			return false
+		}
+		return voxels[lat][lon].Type == core.MatWater || voxels[lat][lon].Type == core.MatAir
+	}
+	plateAt := func(lat, lon int) int32 {
+		if id, ok := filled[[2]int{lat, lon}]; ok {
+			return id
+		}
+		return voxels[lat][lon].PlateID
+	}
+
+	for _, plateID := range plateIDs {
+		if len(members[plateID]) < 10 {
+			continue
+		}
+		var gaps [][2]int
+		queued := make(map[[2]int]bool)
+		for _, v := range members[plateID] {
+			for _, d := range [][2]int{{-1, 0}, {1, 0}, {0, -1}, {0, 1}} {
+				nlat := v[0] + d[0]
+				if nlat < 0 || nlat >= len(voxels) {
+					continue
+				}
+				nlon := (v[1] + d[1] + len(voxels[v[0]])) % len(voxels[v[0]])
+				if nlon >= len(voxels[nlat]) || queued[[2]int{nlat, nlon}] || !isGap(nlat, nlon) {
+					continue
+				}
+
+				around := 0
+				for dlat := -1; dlat <= 1; dlat++ {
+					for dlon := -1; dlon <= 1; dlon++ {
+						nnlat := nlat + dlat
+						nnlon := (nlon + dlon + len(voxels[nlat])) % len(voxels[nlat])
+						if (dlat != 0 || dlon != 0) && nnlat >= 0 && nnlat < len(voxels) &&
+							nnlon < len(voxels[nnlat]) && plateAt(nnlat, nnlon) == plateID {
+							around++
+						}
+					}
+				}
+				if around >= 4 {
+					gaps = append(gaps, [2]int{nlat, nlon})
+					queued[[2]int{nlat, nlon}] = true
+				}
+			}
+		}
+		for _, gap := range gaps {
+			filled[gap] = plateID
+		}
+	}
+	return filled
+}
+
+// TestFillPlateGapsMatchesBaseline checks the fill on the shell's neighbor
+// lookups picks exactly the voxels the raw index rule did, on ragged plates
+// of a grid whose bands all have the same longitude count, where the two
+// ways of finding neighbors agree
+func TestFillPlateGapsMatchesBaseline(t *testing.T) {
+	const latBands, lonCount = 24, 48
+	for seed := int64(1); seed <= 5; seed++ {
+		rng := rand.New(rand.NewSource(seed))
+		shell := &core.SphericalShell{LatBands: latBands, LonCounts: make([]int, latBands), Voxels: make([][]core.VoxelMaterial, latBands)}
+		for latIdx := range shell.Voxels {
+			shell.LonCounts[latIdx] = lonCount
+			shell.Voxels[latIdx] = make([]core.VoxelMaterial, lonCount)
+			for lonIdx := range shell.Voxels[latIdx] {
+				voxel := core.VoxelMaterial{Type: core.MatWater, StretchFactor: 1.0}
+				// Polar bands stay open ocean, where the shell's lookup also
+				// joins the whole band around the pole
+				if latIdx >= 2 && latIdx < latBands-2 && rng.Float64() < 0.7 {
+					voxel = core.VoxelMaterial{Type: core.MatBasalt, PlateID: int32(1 + 3*lonIdx/lonCount), StretchFactor: 1.0}
+				}
+				shell.Voxels[latIdx][lonIdx] = voxel
+			}
+		}
+		want := baselineGapFill(shell.Voxels)
+		if len(want) == 0 {
+			t.Fatalf("seed %d: no gaps to fill, test no longer exercises the rule", seed)
+		}
+
+		va := &VoxelAdvection{planet: core.CreateVoxelPlanet(6371000.0, 4)}
+		before := make([][]core.VoxelMaterial, latBands)
+		for latIdx := range shell.Voxels {
+			before[latIdx] = append([]core.VoxelMaterial(nil), shell.Voxels[latIdx]...)
+		}
+		va.fillPlateGaps(&shell.Voxels, shell)
+
+		for latIdx := range shell.Voxels {
+			for lonIdx, voxel := range shell.Voxels[latIdx] {
+				wantID, fill := want[[2]int{latIdx, lonIdx}]
+				if !fill {
+					wantID = before[latIdx][lonIdx].PlateID
+				}
+				if voxel.PlateID != wantID || voxel.IsTransient != fill {
+					t.Fatalf("seed %d voxel (%d, %d): got plate %d filled %v, baseline rule gives plate %d filled %v",
+						seed, latIdx, lonIdx, voxel.PlateID, voxel.IsTransient, wantID, fill)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package physics
+
+import (
+	"math"
+
+	"worldgenerator/core"
+)
+
+const (
+	// Prevailing surface winds of the three-cell circulation in m/s, used
+	// where the atmosphere shell carries no wind of its own
+	tradeWindSpeed     = 6.0 // Easterly, equator to 30°
+	westerlyWindSpeed  = 8.0 // Westerly, 30° to 60°
+	polarEasterlySpeed = 4.0 // Easterly, 60° to the poles
+
+	// vaporScaleHeight is the depth of the moist layer the wind carries (m)
+	vaporScaleHeight = 1500.0
+
+	// rainoutLength is how far air that neither rises nor sinks travels
+	// before losing 1/e of its vapor as rain (m)
+	rainoutLength = 3.0e6
+
+	// orographicLift is the climb in meters that wrings all the vapor out
+	// of the air; air descending the lee side dries by the same measure
+	orographicLift = 3000.0
+
+	// evaporationLength is the fetch of open water over which air recovers
+	// 1/e of its deficit from saturation (m)
+	evaporationLength = 5.0e5
+
+	// rainDensity converts rain mass to depth (kg/m³)
+	rainDensity = 1000.0
+
+	// erosionCoefficient is the meters of rock removed per meter of rain per
+	// unit slope: 1 m/year of rain on a 1% slope erodes 0.02 mm/year
+	erosionCoefficient = 2e-3
+)
+
+// prevailingWind returns the zonal surface wind in m/s at a latitude in
+// degrees, positive eastward
+func prevailingWind(lat float64) float64 {
+	switch a := math.Abs(lat); {
+	case a < 30:
+		return -tradeWindSpeed
+	case a < 60:
+		return westerlyWindSpeed
+	default:
+		return -polarEasterlySpeed
+	}
+}
+
+// zonalWind returns the eastward wind in m/s of an air voxel, or the
+// prevailing wind where the atmosphere is still
+func zonalWind(air *core.VoxelMaterial, lat float64) float64 {
+	if air != nil && air.VelEast != 0 {
+		return float64(air.VelEast)
+	}
+	return prevailingWind(lat)
+}
+
+// saturationVaporDensity returns the most water vapor air at a temperature
+// in K can hold, in kg/m³, from the Magnus form of Clausius-Clapeyron
+func saturationVaporDensity(temp float64) float64 {
+	const waterVaporGasConstant = 461.5 // J/(kg·K)
+	if temp <= 30 {
+		return 0
+	}
+	pressure := 611.2 * math.Exp(17.67*(temp-273.15)/(temp-29.65))
+	return pressure / (waterVaporGasConstant * temp)
+}
+
+// columnAbove returns the air voxel over a surface voxel
+func columnAbove(air, surface *core.SphericalShell, latIdx, lonIdx int) *core.VoxelMaterial {
+	if len(air.Voxels) == 0 {
+		return nil
+	}
+	airLat := latIdx * len(air.Voxels) / len(surface.Voxels)
+	airLon := lonIdx * len(air.Voxels[airLat]) / len(surface.Voxels[latIdx])
+	return &air.Voxels[airLat][airLon]
+}
+
+// surfaceHeight is the height in meters the wind climbs over: the ground on
+// land and sea level over water
+func surfaceHeight(voxel *core.VoxelMaterial, seaLevel float64) float64 {
+	if voxel.Type == core.MatWater {
+		return seaLevel
+	}
+	return math.Max(float64(voxel.Elevation), seaLevel)
+}
+
+// UpdatePrecipitation carries water vapor around each latitude band with the
+// zonal wind and sets every surface voxel's Precipitation
+// Air picks up vapor toward saturation over open water and rains it out with
+// distance, all at once where it cools past saturation, and faster where the
+// ground rises beneath it. Sinking air down a lee slope rains less, leaving a
+// rain shadow. The vapor left over each column is kept in the air voxel's
+// WaterVapor
+func UpdatePrecipitation(planet *core.VoxelPlanet) {
+	if len(planet.Shells) < 2 {
+		return
+	}
+	air := &planet.Shells[len(planet.Shells)-1]
+	surface := &planet.Shells[len(planet.Shells)-2]
+
+	for latIdx := range surface.Voxels {
+		row := surface.Voxels[latIdx]
+		n := len(row)
+		if n == 0 {
+			continue
+		}
+		lat := core.GetLatitudeForBand(latIdx, surface.LatBands)
+		dx := 2 * math.Pi * surface.OuterRadius * math.Max(math.Cos(lat*math.Pi/180.0), 0.01) / float64(n)
+
+		// The band's mean wind sets which way its vapor travels
+		wind := 0.0
+		for lonIdx := range row {
+			wind += zonalWind(columnAbove(air, surface, latIdx, lonIdx), lat)
+		}
+		wind /= float64(n)
+		step := 1
+		if wind < 0 {
+			step = -1
+		}
+
+		// The first lap brings the vapor to a steady state around the band,
+		// the second records it
+		vapor := 0.0
+		recharge := 1 - math.Exp(-dx/evaporationLength)
+		rainout := 1 - math.Exp(-dx/rainoutLength)
+		for i := 0; i < 2*n; i++ {
+			lonIdx := ((i*step)%n + n) % n
+			upwind := (lonIdx - step + n) % n
+			voxel := &row[lonIdx]
+			above := columnAbove(air, surface, latIdx, lonIdx)
+
+			temp := float64(voxel.Temperature)
+			if above != nil {
+				temp = float64(above.Temperature)
+			}
+			saturation := saturationVaporDensity(temp)
+			if voxel.Type == core.MatWater {
+				vapor += (saturation - vapor) * recharge
+			}
+
+			lift := surfaceHeight(voxel, planet.SeaLevel) - surfaceHeight(&row[upwind], planet.SeaLevel)
+			rain := math.Max(0, vapor-saturation)
+			rain += (vapor - rain) * math.Max(0, math.Min(1, rainout+lift/orographicLift))
+			vapor -= rain
+
+			if i >= n {
+				// Vapor dropped from the flux through the cell, spread over its length
+				flux := rain * math.Abs(wind) * vaporScaleHeight
+				voxel.Precipitation = float32(flux / dx / rainDensity * secondsPerYear)
+				if above != nil {
+					above.WaterVapor = float32(vapor)
+				}
+			}
+		}
+	}
+}
+
+// erodible reports whether rain wears a surface voxel down
+func erodible(voxel *core.VoxelMaterial) bool {
+	switch voxel.Type {
+	case core.MatAir, core.MatWater, core.MatIce, core.MatMagma:
+		return false
+	}
+	return true
+}
+
+// centralAngle returns the angle in radians between two points given in degrees
+func centralAngle(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1, phi2 := lat1*math.Pi/180.0, lat2*math.Pi/180.0
+	dPhi := phi2 - phi1
+	dLambda := (lon2 - lon1) * math.Pi / 180.0
+	h := math.Sin(dPhi/2)*math.Sin(dPhi/2) + math.Cos(phi1)*math.Cos(phi2)*math.Sin(dLambda/2)*math.Sin(dLambda/2)
+	return 2 * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// erodeSurface lowers land by stream-power erosion: the rain falling on each
+// voxel times its steepest downhill slope. A voxel never erodes below its
+// lowest neighbor or sea level, and every rate comes from the elevations at
+// the start of the step, so the sweep order doesn't matter. The rock worn
+// away joins the voxel's Sediment for TransportSediment to carry off
+func erodeSurface(planet *core.VoxelPlanet, dt float64) {
+	if len(planet.Shells) < 2 {
+		return
+	}
+	surfaceIdx := len(planet.Shells) - 2
+	shell := &planet.Shells[surfaceIdx]
+	seaLevel := float32(planet.SeaLevel)
+
+	drops := make([][]float32, len(shell.Voxels))
+	for latIdx := range shell.Voxels {
+		drops[latIdx] = make([]float32, len(shell.Voxels[latIdx]))
+		for lonIdx := range shell.Voxels[latIdx] {
+			voxel := &shell.Voxels[latIdx][lonIdx]
+			if !erodible(voxel) || voxel.Elevation <= seaLevel || voxel.Precipitation <= 0 {
+				continue
+			}
+
+			coord := core.VoxelCoord{Shell: surfaceIdx, Lat: latIdx, Lon: lonIdx}
+			lat, lon := planet.VoxelLatLon(coord)
+			slope := 0.0
+			floor := voxel.Elevation
+			for _, n := range core.ShellNeighbors(shell, coord) {
+				neighbor := &shell.Voxels[n.Lat][n.Lon]
+				height := neighbor.Elevation
+				if height < seaLevel || neighbor.Type == core.MatWater {
+					height = seaLevel
+				}
+				if height >= voxel.Elevation {
+					continue
+				}
+				nLat, nLon := planet.VoxelLatLon(n)
+				dist := shell.OuterRadius * centralAngle(lat, lon, nLat, nLon)
+				if dist > 0 {
+					slope = math.Max(slope, float64(voxel.Elevation-height)/dist)
+				}
+				floor = min(floor, height)
+			}
+
+			drop := float32(erosionCoefficient * float64(voxel.Precipitation) * slope * dt)
+			drops[latIdx][lonIdx] = min(drop, voxel.Elevation-floor)
+		}
+	}
+
+	for latIdx := range shell.Voxels {
+		for lonIdx := range shell.Voxels[latIdx] {
+			shell.Voxels[latIdx][lonIdx].Elevation -= drops[latIdx][lonIdx]
+			shell.Voxels[latIdx][lonIdx].Sediment += drops[latIdx][lonIdx]
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package physics
+
+import (
+	"math"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// TestOrographicErosion checks a north-south ridge under a west wind gets more
+// rain and erodes faster on its windward (west) flank than on its lee
+func TestOrographicErosion(t *testing.T) {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 10, 36)
+	planet.SeaLevel = 0
+	air := &planet.Shells[len(planet.Shells)-1]
+	surface := &planet.Shells[len(planet.Shells)-2]
+	surfaceIdx := len(planet.Shells) - 2
+
+	for latIdx := range air.Voxels {
+		for lonIdx := range air.Voxels[latIdx] {
+			air.Voxels[latIdx][lonIdx].VelEast = 10
+			air.Voxels[latIdx][lonIdx].Temperature = 288
+		}
+	}
+
+	// Ocean with a ridge peaking 3200 m on the prime meridian, 30° to each side
+	for latIdx := range surface.Voxels {
+		for lonIdx := range surface.Voxels[latIdx] {
+			voxel := &surface.Voxels[latIdx][lonIdx]
+			_, lon := planet.VoxelLatLon(core.VoxelCoord{Shell: surfaceIdx, Lat: latIdx, Lon: lonIdx})
+			if lon < -30 || lon > 30 {
+				voxel.Type = core.MatWater
+				voxel.Elevation = -4000
+				continue
+			}
+			voxel.Type = core.MatGranite
+			voxel.Elevation = float32(200 + 3000*(1-math.Abs(lon)/30))
+		}
+	}
+
+	band := surface.LatBands / 2
+	row := surface.Voxels[band]
+	before := make([]float32, len(row))
+	for lonIdx := range row {
+		before[lonIdx] = row[lonIdx].Elevation
+	}
+
+	UpdatePrecipitation(planet)
+	erodeSurface(planet, 1e5)
+
+	// Compare each windward voxel with its mirror image across the crest
+	compared := 0
+	for west := range row {
+		_, lon := planet.VoxelLatLon(core.VoxelCoord{Shell: surfaceIdx, Lat: band, Lon: west})
+		if lon < -30 || lon >= 0 {
+			continue
+		}
+		lee := len(row) - 1 - west
+		if row[west].Precipitation <= row[lee].Precipitation {
+			t.Errorf("at %.1f° from the crest: %.3f m/yr windward, %.3f m/yr in the lee", -lon, row[west].Precipitation, row[lee].Precipitation)
+		}
+		windward, leeward := before[west]-row[west].Elevation, before[lee]-row[lee].Elevation
+		if windward <= leeward || leeward < 0 {
+			t.Errorf("at %.1f° from the crest: windward eroded %.3f m, lee %.3f m", -lon, windward, leeward)
+		}
+		compared++
+	}
+	if compared < 3 {
+		t.Fatalf("only %d flank voxels compared", compared)
+	}
+}
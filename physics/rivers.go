@@ -0,0 +1,263 @@
+package physics
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+
+	"worldgenerator/core"
+)
+
+// RiverMinDrainageArea is the upstream area in m² a surface voxel must drain
+// before ComputeRiverNetwork counts it as part of a river, about a tenth of
+// the Mississippi basin
+const RiverMinDrainageArea = 3e11
+
+// RiverPoint is one vertex of a river polyline, at a surface voxel center
+type RiverPoint struct {
+	Coord        core.VoxelCoord
+	Lat, Lon     float64 // Degrees
+	DrainageArea float64 // Area upstream of and including this voxel in m²
+}
+
+// River is one channel from its source downstream to where it ends: the sea,
+// a larger river it joins, or the lowest point of a planet without oceans
+type River struct {
+	Points []RiverPoint // Source first, the point it flows into last
+	Joins  int          // Index of the river this one flows into, -1 if it ends on its own
+}
+
+// ComputeRiverNetwork routes rain over the surface shell and returns every
+// river draining at least RiverMinDrainageArea, largest first. Each river's
+// tributaries follow it, ending on the point where they join it
+func ComputeRiverNetwork(planet *core.VoxelPlanet) []River {
+	return riverNetwork(planet, RiverMinDrainageArea)
+}
+
+// flowRouting is where every surface voxel drains and how much drains
+// through it. Voxels are numbered band by band, south to north
+type flowRouting struct {
+	coords   []core.VoxelCoord
+	ocean    []bool    // Sea voxels, where rivers end
+	receiver []int     // Downhill neighbor each voxel drains to, -1 for outlets
+	drainage []float64 // Upstream area in m², including the voxel itself
+}
+
+// routeFlow sends each land voxel's water to its steepest downhill neighbor
+// and accumulates drainage area downstream. Depressions are filled first
+// (priority flood from the sea), so water crossing an endorheic basin spills
+// over its lowest rim instead of stopping. Neighbors wrap in longitude and
+// meet across the poles, as in core.ShellNeighbors
+func routeFlow(planet *core.VoxelPlanet) *flowRouting {
+	surface := len(planet.Shells) - 2
+	shell := &planet.Shells[surface]
+
+	offsets := make([]int, shell.LatBands+1)
+	for lat := 0; lat < shell.LatBands; lat++ {
+		offsets[lat+1] = offsets[lat] + len(shell.Voxels[lat])
+	}
+	count := offsets[shell.LatBands]
+	index := func(c core.VoxelCoord) int { return offsets[c.Lat] + c.Lon }
+
+	fr := &flowRouting{
+		coords:   make([]core.VoxelCoord, count),
+		ocean:    make([]bool, count),
+		receiver: make([]int, count),
+		drainage: make([]float64, count),
+	}
+	elevation := make([]float64, count)
+	centers := make([]core.Vector3, count)
+	for lat := range shell.Voxels {
+		for lon := range shell.Voxels[lat] {
+			coord := core.VoxelCoord{Shell: surface, Lat: lat, Lon: lon}
+			i := index(coord)
+			fr.coords[i] = coord
+			elevation[i] = float64(shell.Voxels[lat][lon].Elevation)
+			fr.receiver[i] = -1
+			latDeg, lonDeg := planet.VoxelLatLon(coord)
+			p := core.GeographicToCartesian(core.Geographic{
+				Lat: core.DegreesToRadians(latDeg),
+				Lon: core.DegreesToRadians(lonDeg),
+			}, 1, 0)
+			centers[i] = core.Vector3{X: p.X, Y: p.Y, Z: p.Z}
+		}
+	}
+
+	// The sea is open water and whatever below sea level it floods; a basin
+	// below sea level walled off from it is dry land
+	for i, coord := range fr.coords {
+		voxel := &shell.Voxels[coord.Lat][coord.Lon]
+		if fr.ocean[i] || voxel.Type != core.MatWater || elevation[i] >= planet.SeaLevel {
+			continue
+		}
+		submerged := func(v *core.VoxelMaterial) bool { return float64(v.Elevation) < planet.SeaLevel }
+		for _, c := range core.SphericalFloodFill(shell, coord, submerged) {
+			fr.ocean[index(c)] = true
+		}
+	}
+
+	// Flood inward from the sea, or from the lowest voxel of a dry planet.
+	// Each voxel reached is raised just above the one that reached it, so
+	// every land voxel ends up with a strictly lower neighbor
+	filled := append([]float64(nil), elevation...)
+	visited := make([]bool, count)
+	queue := &floodQueue{}
+	lowest := 0
+	for i := range elevation {
+		if fr.ocean[i] {
+			visited[i] = true
+			heap.Push(queue, floodEntry{i, filled[i]})
+		}
+		if elevation[i] < elevation[lowest] {
+			lowest = i
+		}
+	}
+	if queue.Len() == 0 {
+		visited[lowest] = true
+		heap.Push(queue, floodEntry{lowest, filled[lowest]})
+	}
+
+	neighbors := make([][]int, count)
+	order := make([]int, 0, count) // Lowest filled elevation first
+	for queue.Len() > 0 {
+		i := heap.Pop(queue).(floodEntry).index
+		order = append(order, i)
+		for _, n := range core.ShellNeighbors(shell, fr.coords[i]) {
+			j := index(n)
+			neighbors[i] = append(neighbors[i], j)
+			if visited[j] {
+				continue
+			}
+			visited[j] = true
+			filled[j] = math.Max(filled[j], math.Nextafter(filled[i], math.Inf(1)))
+			heap.Push(queue, floodEntry{j, filled[j]})
+		}
+	}
+
+	// Steepest descent on the filled surface
+	for i := range fr.receiver {
+		if fr.ocean[i] {
+			continue
+		}
+		steepest := 0.0
+		for _, j := range neighbors[i] {
+			distance := centers[i].Sub(centers[j]).Length()
+			if drop := filled[i] - filled[j]; drop > 0 && distance > 0 && drop/distance > steepest {
+				steepest = drop / distance
+				fr.receiver[i] = j
+			}
+		}
+	}
+
+	// Every receiver comes earlier in the flood order, so walking it
+	// backwards visits each voxel after everything upstream of it
+	for k := len(order) - 1; k >= 0; k-- {
+		i := order[k]
+		if fr.ocean[i] {
+			continue
+		}
+		fr.drainage[i] += planet.VoxelArea(shell, fr.coords[i].Lat)
+		if r := fr.receiver[i]; r >= 0 && !fr.ocean[r] {
+			fr.drainage[r] += fr.drainage[i]
+		}
+	}
+
+	return fr
+}
+
+// riverNetwork traces the voxels draining at least minArea into polylines.
+// Starting at each mouth it follows the largest upstream branch to the
+// source; every other branch becomes a tributary traced the same way
+func riverNetwork(planet *core.VoxelPlanet, minArea float64) []River {
+	if len(planet.Shells) < 2 {
+		return nil
+	}
+	fr := routeFlow(planet)
+
+	isRiver := func(i int) bool { return !fr.ocean[i] && fr.drainage[i] >= minArea }
+	upstream := make(map[int][]int)
+	var mouths []int
+	for i := range fr.receiver {
+		if !isRiver(i) {
+			continue
+		}
+		if r := fr.receiver[i]; r >= 0 && isRiver(r) {
+			upstream[r] = append(upstream[r], i)
+		} else {
+			mouths = append(mouths, i)
+		}
+	}
+	sort.SliceStable(mouths, func(a, b int) bool { return fr.drainage[mouths[a]] > fr.drainage[mouths[b]] })
+
+	point := func(i int, drainage float64) RiverPoint {
+		lat, lon := planet.VoxelLatLon(fr.coords[i])
+		return RiverPoint{Coord: fr.coords[i], Lat: lat, Lon: lon, DrainageArea: drainage}
+	}
+
+	// A branch is traced from its lowest voxel upstream, then reversed
+	type branch struct {
+		start, joins int // First voxel, and the river it flows into (-1 = none)
+	}
+	var rivers []River
+	for _, mouth := range mouths {
+		pending := []branch{{mouth, -1}}
+		for len(pending) > 0 {
+			b := pending[0]
+			pending = pending[1:]
+			index := len(rivers)
+
+			var points []RiverPoint
+			for i := b.start; i >= 0; {
+				points = append(points, point(i, fr.drainage[i]))
+				next := -1
+				for _, u := range upstream[i] {
+					if next < 0 || fr.drainage[u] > fr.drainage[next] {
+						next = u
+					}
+				}
+				for _, u := range upstream[i] {
+					if u != next {
+						pending = append(pending, branch{u, index})
+					}
+				}
+				i = next
+			}
+			for l, r := 0, len(points)-1; l < r; l, r = l+1, r-1 {
+				points[l], points[r] = points[r], points[l]
+			}
+
+			// End on the voxel the river flows into: the sea, or the
+			// confluence on the river it joins
+			if r := fr.receiver[b.start]; r >= 0 {
+				drainage := fr.drainage[b.start]
+				if !fr.ocean[r] {
+					drainage = fr.drainage[r]
+				}
+				points = append(points, point(r, drainage))
+			}
+			rivers = append(rivers, River{Points: points, Joins: b.joins})
+		}
+	}
+
+	return rivers
+}
+
+// floodEntry is a voxel waiting in a priority flood
+type floodEntry struct {
+	index    int
+	priority float64 // Filled elevation, or distance for distances from water
+}
+
+// floodQueue is a min-heap of voxels by priority
+type floodQueue []floodEntry
+
+func (q floodQueue) Len() int            { return len(q) }
+func (q floodQueue) Less(i, j int) bool  { return q[i].priority < q[j].priority }
+func (q floodQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *floodQueue) Push(x interface{}) { *q = append(*q, x.(floodEntry)) }
+func (q *floodQueue) Pop() interface{} {
+	old := *q
+	entry := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return entry
+}
@@ -0,0 +1,158 @@
+package physics
+
+import (
+	"math"
+	"slices"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// coneIsland makes the surface a cone rising away from a small sea centered
+// on lat/lon in degrees, with a closed pit dug into its slope
+func coneIsland(lat, lon float64) *core.VoxelPlanet {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 4, 24)
+	planet.SeaLevel = 0
+	surface := len(planet.Shells) - 2
+	shell := &planet.Shells[surface]
+	sea := core.GeographicToCartesian(core.Geographic{Lat: core.DegreesToRadians(lat), Lon: core.DegreesToRadians(lon)}, 1, 0)
+
+	for latIdx := range shell.Voxels {
+		for lonIdx := range shell.Voxels[latIdx] {
+			vLat, vLon := planet.VoxelLatLon(core.VoxelCoord{Shell: surface, Lat: latIdx, Lon: lonIdx})
+			p := core.GeographicToCartesian(core.Geographic{Lat: core.DegreesToRadians(vLat), Lon: core.DegreesToRadians(vLon)}, 1, 0)
+			angle := math.Acos(math.Max(-1, math.Min(1, p.X*sea.X+p.Y*sea.Y+p.Z*sea.Z)))
+			elevation := 4000*angle - 700 // Below sea level within 10° of the center
+			voxel := &shell.Voxels[latIdx][lonIdx]
+			voxel.Elevation = float32(elevation)
+			voxel.Type = core.MatGranite
+			if elevation < 0 {
+				voxel.Type = core.MatWater
+			}
+		}
+	}
+
+	// A dry pit below sea level on the far slope, with no way out
+	pit, _ := planet.VoxelAtGeographic(-lat, lon+180, -1000)
+	pit.Elevation = -500
+	return planet
+}
+
+// TestRouteFlowFillsDepressions checks every land voxel drains to the sea,
+// including the pit, and that drainage area is conserved
+func TestRouteFlowFillsDepressions(t *testing.T) {
+	planet := coneIsland(0, 0)
+	shell := &planet.Shells[len(planet.Shells)-2]
+	fr := routeFlow(planet)
+
+	landArea, outflow := 0.0, 0.0
+	for i := range fr.coords {
+		if fr.ocean[i] {
+			if shell.Voxels[fr.coords[i].Lat][fr.coords[i].Lon].Type != core.MatWater {
+				t.Fatalf("dry pit at %v counted as sea", fr.coords[i])
+			}
+			continue
+		}
+		landArea += planet.VoxelArea(shell, fr.coords[i].Lat)
+
+		r := fr.receiver[i]
+		if r < 0 {
+			t.Fatalf("land voxel %v has nowhere to drain", fr.coords[i])
+		}
+		if fr.ocean[r] {
+			outflow += fr.drainage[i]
+		}
+
+		// Follow the water down; it must reach the sea without looping
+		for steps := 0; !fr.ocean[i]; steps++ {
+			if steps > len(fr.coords) {
+				t.Fatalf("flow from %v loops", fr.coords[i])
+			}
+			i = fr.receiver[i]
+		}
+	}
+
+	if math.Abs(outflow-landArea) > 1e-9*landArea {
+		t.Errorf("%.6g m² reaches the sea, want the whole land area %.6g m²", outflow, landArea)
+	}
+}
+
+// TestRiverNetworkAcrossAntimeridian checks rivers are continuous chains of
+// neighboring voxels ending in the sea or on the river they join, with no
+// river cut at the ±180° seam
+func TestRiverNetworkAcrossAntimeridian(t *testing.T) {
+	planet := coneIsland(0, 165)
+	shell := &planet.Shells[len(planet.Shells)-2]
+	rivers := riverNetwork(planet, 0)
+	if len(rivers) == 0 {
+		t.Fatal("no rivers")
+	}
+
+	crossings := 0
+	for r, river := range rivers {
+		if len(river.Points) < 2 {
+			t.Fatalf("river %d has %d points", r, len(river.Points))
+		}
+		for k := 1; k < len(river.Points); k++ {
+			from, to := river.Points[k-1], river.Points[k]
+			if !slices.Contains(core.ShellNeighbors(shell, from.Coord), to.Coord) {
+				t.Fatalf("river %d jumps from %v to %v", r, from.Coord, to.Coord)
+			}
+			if to.DrainageArea < from.DrainageArea {
+				t.Errorf("river %d drains less downstream at %v", r, to.Coord)
+			}
+			if math.Abs(to.Lon-from.Lon) > 180 {
+				crossings++
+			}
+		}
+
+		mouth := river.Points[len(river.Points)-1]
+		if river.Joins < 0 {
+			if float64(shell.Voxels[mouth.Coord.Lat][mouth.Coord.Lon].Elevation) >= planet.SeaLevel {
+				t.Errorf("river %d ends on land at %v", r, mouth.Coord)
+			}
+			continue
+		}
+		if river.Joins >= r {
+			t.Errorf("river %d joins river %d listed after it", r, river.Joins)
+		}
+		joined := false
+		for _, p := range rivers[river.Joins].Points {
+			joined = joined || p.Coord == mouth.Coord
+		}
+		if !joined {
+			t.Errorf("river %d ends at %v, not on river %d", r, mouth.Coord, river.Joins)
+		}
+	}
+
+	if crossings == 0 {
+		t.Error("no river crosses the antimeridian toward a sea centered at 165°")
+	}
+}
+
+// TestComputeRiverNetworkLargestFirst checks the threshold and ordering on a
+// generated planet
+func TestComputeRiverNetworkLargestFirst(t *testing.T) {
+	planet := coneIsland(30, -60)
+	rivers := ComputeRiverNetwork(planet)
+	if len(rivers) == 0 {
+		t.Fatal("no rivers")
+	}
+
+	last := math.Inf(1)
+	for r, river := range rivers {
+		for _, p := range river.Points[:len(river.Points)-1] {
+			if p.DrainageArea < RiverMinDrainageArea {
+				t.Fatalf("river %d includes %v draining only %.3g m²", r, p.Coord, p.DrainageArea)
+			}
+		}
+		if river.Joins >= 0 {
+			continue
+		}
+		mouth := river.Points[len(river.Points)-1].DrainageArea
+		if mouth > last {
+			t.Errorf("river %d drains %.3g m², more than the one before it", r, mouth)
+		}
+		last = mouth
+	}
+}
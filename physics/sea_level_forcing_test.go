@@ -0,0 +1,61 @@
+package physics
+
+import (
+	"math"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// TestSeaLevelForcing checks forced sea level ramps toward its target at the
+// requested rate and floods low coastal land while higher ground stays dry
+func TestSeaLevelForcing(t *testing.T) {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 5, 60)
+	shell := &planet.Shells[len(planet.Shells)-2]
+	va := &VoxelAdvection{planet: planet}
+
+	// A coastal plain 30 m up and a plateau at 500 m
+	lowland := &shell.Voxels[30][0]
+	plateau := &shell.Voxels[30][1]
+	*lowland = core.VoxelMaterial{Type: core.MatGranite, Density: 2700, Elevation: 30, PlateID: 1}
+	*plateau = core.VoxelMaterial{Type: core.MatGranite, Density: 2700, Elevation: 500, PlateID: 1}
+
+	// 1000 m/My is 10 m per 10,000 year step, stopping at the 80 m target
+	planet.SetSeaLevelTarget(80, 1000)
+	const dt = 10000.0
+	for step := 1; step <= 10; step++ {
+		planet.StepSeaLevel(dt)
+		va.applySeaLevelChange(shell)
+
+		want := math.Min(float64(step)*10, 80)
+		if math.Abs(planet.SeaLevel-want) > 1e-9 {
+			t.Fatalf("step %d: sea level %.2f m, want %.2f m", step, planet.SeaLevel, want)
+		}
+		if flooded := lowland.Type == core.MatWater; flooded != (planet.SeaLevel > 30) {
+			t.Fatalf("step %d: lowland at 30 m is type %d with the sea at %.0f m", step, lowland.Type, planet.SeaLevel)
+		}
+	}
+	if plateau.Type != core.MatGranite {
+		t.Errorf("plateau at 500 m flooded to type %d", plateau.Type)
+	}
+	if lowland.PlateID != 0 || lowland.Elevation != 30 {
+		t.Errorf("flooded lowland has plate %d at %.0f m, want plate 0 keeping its 30 m floor", lowland.PlateID, lowland.Elevation)
+	}
+
+	// Falling back past the old shoreline exposes the drowned plain as sediment
+	planet.SetSeaLevelTarget(0, 1000)
+	for step := 0; step < 8; step++ {
+		planet.StepSeaLevel(dt)
+		va.applySeaLevelChange(shell)
+	}
+	if planet.SeaLevel != 0 || lowland.Type != core.MatSediment {
+		t.Errorf("sea at %.0f m, lowland type %d, want 0 m and exposed sediment", planet.SeaLevel, lowland.Type)
+	}
+
+	// Releasing the forcing keeps sea level where it is
+	planet.ClearSeaLevelTarget()
+	planet.StepSeaLevel(dt)
+	if math.Abs(planet.SeaLevel) > 20 {
+		t.Errorf("sea level jumped to %.1f m after releasing the forcing", planet.SeaLevel)
+	}
+}
@@ -0,0 +1,46 @@
+package physics
+
+import (
+	"worldgenerator/core"
+)
+
+// shelfBreakDepth separates continental shelves and flooded lowlands from
+// the deep ocean floor (m below the datum)
+const shelfBreakDepth = 200.0
+
+// updateSeafloorSubsidence sets the seafloor elevation of every ocean voxel
+// from the age of its oceanic crust, so ridges stand high and old basins lie
+// deep. Exposed basalt uses its own age; deep water carries the age of the
+// floor beneath it, which updateAgeCPU doesn't advance, so it ages here.
+// Water no deeper than the shelf break sits on continental crust, or on the
+// shelves TransportSediment builds, and keeps its depth, and slabs already
+// sinking into a trench are left to their vertical motion
+func updateSeafloorSubsidence(planet *core.VoxelPlanet, dt float64) {
+	surfaceShell := len(planet.Shells) - 2
+	if surfaceShell < 0 {
+		return
+	}
+	shell := &planet.Shells[surfaceShell]
+
+	for latIdx := range shell.Voxels {
+		for lonIdx := range shell.Voxels[latIdx] {
+			voxel := &shell.Voxels[latIdx][lonIdx]
+
+			switch voxel.Type {
+			case core.MatBasalt:
+				if voxel.VelR < 0 {
+					continue
+				}
+			case core.MatWater:
+				if voxel.Elevation >= -shelfBreakDepth {
+					continue
+				}
+				voxel.Age += float32(dt)
+			default:
+				continue
+			}
+
+			voxel.Elevation = float32(-core.SeafloorDepth(float64(voxel.Age)))
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package physics
+
+import (
+	"math"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// TestSeafloorSubsidence checks ocean floor depth follows the √age cooling
+// law, from ridge depth at 0 My to abyssal depth at 100 My, and that a
+// flooded continental shelf keeps its own depth
+func TestSeafloorSubsidence(t *testing.T) {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 5, 60)
+	shell := &planet.Shells[len(planet.Shells)-2]
+
+	// A fresh ridge exposed at the surface
+	ridge := &shell.Voxels[30][0]
+	*ridge = core.VoxelMaterial{Type: core.MatBasalt, Density: 2900}
+
+	// Old ocean: deep water over 100 My floor
+	basin := &shell.Voxels[30][10]
+	*basin = core.VoxelMaterial{Type: core.MatWater, Density: 1000, Elevation: -4000, Age: 100e6}
+
+	// Flooded shelf
+	shelf := &shell.Voxels[30][20]
+	*shelf = core.VoxelMaterial{Type: core.MatWater, Density: 1000, Elevation: -50, Age: 100e6}
+
+	dt := 1000.0
+	updateSeafloorSubsidence(planet, dt)
+
+	if math.Abs(float64(ridge.Elevation)+core.RidgeDepth) > 1 {
+		t.Errorf("0 My seafloor at %.0f m, want ridge depth -%.0f m", ridge.Elevation, core.RidgeDepth)
+	}
+	if basin.Elevation > -5500 || basin.Elevation < -6500 {
+		t.Errorf("100 My seafloor at %.0f m, want an abyssal plain around -6 km", basin.Elevation)
+	}
+	if basin.Age != float32(100e6+dt) {
+		t.Errorf("seafloor age %.0f after %.0f years, want %.0f", basin.Age, dt, 100e6+dt)
+	}
+	if shelf.Elevation != -50 {
+		t.Errorf("flooded shelf moved to %.0f m, want its -50 m floor kept", shelf.Elevation)
+	}
+}
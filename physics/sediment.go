@@ -0,0 +1,124 @@
+package physics
+
+import (
+	"sort"
+
+	"worldgenerator/core"
+)
+
+// sedimentRoutingPasses bounds the sweeps one TransportSediment call makes;
+// sediment still moving after them waits for the next call
+const sedimentRoutingPasses = 8
+
+// TransportSediment carries the eroded rock in each surface voxel's
+// Sediment downslope and lays it down, conserving its volume: every meter
+// taken off one voxel is added to the Elevation or Sediment of another,
+// scaled by their areas. On land it follows the steepest descent, as rivers
+// would, and fills the pits it runs into up to their rim; a filled basin
+// keeps its bedrock's material, as land under ice does, so continents stay
+// whole. Reaching the sea it settles on the floor up to the shelf break and
+// moves on down the slope with the rest, so river mouths build deltas and
+// coasts grow shelves that step out to sea. A delta built above sea level
+// becomes new MatSediment land
+// Voxels are swept from the highest down, so most sediment reaches its
+// resting place in one pass; what runs onto level ground can take more
+func TransportSediment(planet *core.VoxelPlanet) {
+	if len(planet.Shells) < 2 {
+		return
+	}
+	surfaceIdx := len(planet.Shells) - 2
+	shell := &planet.Shells[surfaceIdx]
+	seaLevel := float32(planet.SeaLevel)
+	shelf := float32(-shelfBreakDepth)
+
+	// Every surface voxel, highest first, as sediment can end up anywhere
+	var order []core.VoxelCoord
+	carrying := false
+	for latIdx := range shell.Voxels {
+		for lonIdx := range shell.Voxels[latIdx] {
+			order = append(order, core.VoxelCoord{Shell: surfaceIdx, Lat: latIdx, Lon: lonIdx})
+			carrying = carrying || shell.Voxels[latIdx][lonIdx].Sediment > 0
+		}
+	}
+	if !carrying {
+		return
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return shell.Voxels[order[i].Lat][order[i].Lon].Elevation > shell.Voxels[order[j].Lat][order[j].Lon].Elevation
+	})
+	areas := make([]float64, len(shell.Voxels))
+	for latIdx := range areas {
+		areas[latIdx] = planet.VoxelArea(shell, latIdx)
+	}
+
+	for pass := 0; pass < sedimentRoutingPasses; pass++ {
+		moving := false
+		for _, coord := range order {
+			voxel := &shell.Voxels[coord.Lat][coord.Lon]
+			if voxel.Sediment <= 0 {
+				continue
+			}
+
+			var lowest *core.VoxelMaterial
+			lowestLat := 0
+			for _, n := range core.ShellNeighbors(shell, coord) {
+				if n == coord {
+					continue // A band one voxel round is its own neighbor
+				}
+				neighbor := &shell.Voxels[n.Lat][n.Lon]
+				if lowest == nil || neighbor.Elevation < lowest.Elevation {
+					lowest, lowestLat = neighbor, n.Lat
+				}
+			}
+
+			// How much settles here before the rest moves on
+			deposit := voxel.Sediment
+			switch {
+			case lowest == nil:
+			case voxel.Type == core.MatWater || voxel.Elevation < seaLevel:
+				// On the sea floor, up to the shelf break; with nowhere deeper
+				// to go from there, all of it
+				if room := max(0, shelf-voxel.Elevation); deposit > room && lowest.Elevation < max(voxel.Elevation, shelf) {
+					deposit = room
+				}
+			case lowest.Elevation < voxel.Elevation:
+				// Running downhill on land
+				deposit = 0
+			default:
+				// In a pit, up to the rim
+				deposit = min(deposit, lowest.Elevation-voxel.Elevation)
+			}
+
+			// Take off what the elevation really gained: a thin layer on a
+			// deep floor can round away, and then stays in transit
+			before := voxel.Elevation
+			voxel.Elevation += deposit
+			deposit = voxel.Elevation - before
+			voxel.Sediment = max(0, voxel.Sediment-deposit)
+			if deposit > 0 {
+				settleSediment(voxel, seaLevel)
+			}
+			if voxel.Sediment > 0 && lowest != nil {
+				lowest.Sediment += float32(float64(voxel.Sediment) * areas[coord.Lat] / areas[lowestLat])
+				voxel.Sediment = 0
+				moving = true
+			}
+		}
+		if !moving {
+			return
+		}
+	}
+}
+
+// settleSediment turns sea floor sediment has built above sea level into
+// new MatSediment land, as applySeaLevelChange exposes it
+func settleSediment(voxel *core.VoxelMaterial, seaLevel float32) {
+	if voxel.Type != core.MatWater || voxel.Elevation <= seaLevel {
+		return
+	}
+	voxel.Type = core.MatSediment
+	voxel.Density = core.MaterialProperties[core.MatSediment].DefaultDensity
+	voxel.PlateID = 0 // No plate initially
+	voxel.IsBrittle = true
+	voxel.Age = 0
+}
@@ -0,0 +1,95 @@
+package physics
+
+import (
+	"math"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// TestSedimentConservesMass wears down a rainy ridge standing in a flat
+// ocean: every cubic meter eroded from it ends up in a pit on its flank or
+// on the sea floor, where it builds shelves up to the shelf break
+func TestSedimentConservesMass(t *testing.T) {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 8, 36)
+	planet.SeaLevel = 0
+	surfaceIdx := len(planet.Shells) - 2
+	surface := &planet.Shells[surfaceIdx]
+
+	// A ridge 1500 m high along the prime meridian, with a pit on its flank
+	band := surface.LatBands / 2
+	pitLon := -1
+	for latIdx := range surface.Voxels {
+		for lonIdx := range surface.Voxels[latIdx] {
+			voxel := &surface.Voxels[latIdx][lonIdx]
+			_, lon := planet.VoxelLatLon(core.VoxelCoord{Shell: surfaceIdx, Lat: latIdx, Lon: lonIdx})
+			if math.Abs(lon) >= 40 {
+				*voxel = core.VoxelMaterial{Type: core.MatWater, Density: 1000, Elevation: -3000}
+				continue
+			}
+			*voxel = core.VoxelMaterial{Type: core.MatGranite, Density: 2700, IsBrittle: true, PlateID: 1,
+				Elevation: float32(1500 * (1 - math.Abs(lon)/40)), Precipitation: 1}
+			if latIdx == band && pitLon < 0 && lon > 15 {
+				pitLon = lonIdx
+			}
+		}
+	}
+	pit := &surface.Voxels[band][pitLon]
+	pit.Elevation -= 300
+	pitFloor := pit.Elevation
+
+	// Rock in place plus rock in transit, in m³ relative to the sea surface
+	mass := func() float64 {
+		total := 0.0
+		for latIdx := range surface.Voxels {
+			area := planet.VoxelArea(surface, latIdx)
+			for _, voxel := range surface.Voxels[latIdx] {
+				total += (float64(voxel.Elevation) + float64(voxel.Sediment)) * area
+			}
+		}
+		return total
+	}
+
+	before := mass()
+	eroded := 0.0
+	for round := 0; round < 20; round++ {
+		erodeSurface(planet, 1e4)
+		for latIdx := range surface.Voxels {
+			for _, voxel := range surface.Voxels[latIdx] {
+				eroded += float64(voxel.Sediment) * planet.VoxelArea(surface, latIdx)
+			}
+		}
+		TransportSediment(planet)
+	}
+	if eroded == 0 {
+		t.Fatal("nothing eroded off the ridge")
+	}
+	if drift := mass() - before; math.Abs(drift) > 1e-3*eroded {
+		t.Errorf("rock volume changed by %.4g m³ moving %.4g m³ of sediment", drift, eroded)
+	}
+
+	inTransit := 0.0
+	deposited := 0.0
+	for latIdx := range surface.Voxels {
+		area := planet.VoxelArea(surface, latIdx)
+		for lonIdx, voxel := range surface.Voxels[latIdx] {
+			inTransit += float64(voxel.Sediment) * area
+			if voxel.Type == core.MatWater && voxel.Elevation > -3000 {
+				deposited += float64(voxel.Elevation+3000) * area
+				if voxel.Elevation > -shelfBreakDepth+1e-3 {
+					t.Errorf("sea floor built up to %.1f m at %d,%d, above the shelf break", voxel.Elevation, latIdx, lonIdx)
+				}
+			}
+		}
+	}
+	if inTransit > 0.01*eroded {
+		t.Errorf("%.3g of %.3g m³ eroded still in transit", inTransit, eroded)
+	}
+	if deposited < 0.5*eroded {
+		t.Errorf("only %.3g of %.3g m³ eroded reached the sea floor", deposited, eroded)
+	}
+	if pit.Elevation <= pitFloor || pit.Type != core.MatGranite {
+		t.Errorf("pit on the ridge's flank is %s at %.2f m, want sediment above its %.2f m granite floor",
+			core.MaterialName(pit.Type), pit.Elevation, pitFloor)
+	}
+}
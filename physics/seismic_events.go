@@ -0,0 +1,103 @@
+package physics
+
+import (
+	"math"
+	"sync"
+
+	"worldgenerator/core"
+)
+
+// maxSeismicEvents bounds the earthquake log; past it the oldest events are
+// overwritten
+const maxSeismicEvents = 4096
+
+// SeismicEvent is an earthquake: a transform fault slipping once its
+// accumulated stress passed the rock's yield strength, or a convergent
+// boundary rupturing as collision loads it past yield. Only the CPU boundary
+// physics records them; faults slipping in the GPU boundary shader
+// (gpu.BoundaryParams.StressRelease) go unlogged, as the shader has no way to
+// report them back
+type SeismicEvent struct {
+	Lat       float64 // Epicenter latitude in degrees
+	Lon       float64 // Epicenter longitude in degrees
+	Magnitude float64 // Moment magnitude
+	Time      float64 // Simulation years
+}
+
+// SeismicLog keeps the most recent earthquakes in a fixed-size ring buffer
+// The physics thread records while the main thread reads, so it is locked
+type SeismicLog struct {
+	mu     sync.Mutex
+	events []SeismicEvent
+	start  int // Index of the oldest event
+	count  int
+}
+
+// NewSeismicLog creates a log holding up to capacity events
+func NewSeismicLog(capacity int) *SeismicLog {
+	return &SeismicLog{events: make([]SeismicEvent, max(capacity, 1))}
+}
+
+// Record adds an event, overwriting the oldest when the log is full
+func (l *SeismicLog) Record(event SeismicEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.count < len(l.events) {
+		l.events[(l.start+l.count)%len(l.events)] = event
+		l.count++
+		return
+	}
+	l.events[l.start] = event
+	l.start = (l.start + 1) % len(l.events)
+}
+
+// Since returns the events recorded after sinceTime (simulation years),
+// oldest first. Passing the last event's time polls for new ones only
+func (l *SeismicLog) Since(sinceTime float64) []SeismicEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var events []SeismicEvent
+	for i := 0; i < l.count; i++ {
+		event := l.events[(l.start+i)%len(l.events)]
+		if event.Time > sinceTime {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// Len returns how many events the log holds
+func (l *SeismicLog) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.count
+}
+
+// seismicLog returns the planet's earthquake log, creating it on first use
+func seismicLog(planet *core.VoxelPlanet) *SeismicLog {
+	if log, ok := planet.Seismic.(*SeismicLog); ok {
+		return log
+	}
+	log := NewSeismicLog(maxSeismicEvents)
+	planet.Seismic = log
+	return log
+}
+
+// GetRecentSeismicEvents returns the earthquakes recorded after sinceTime
+// (simulation years), oldest first
+func (vp *VoxelPhysics) GetRecentSeismicEvents(sinceTime float64) []SeismicEvent {
+	return seismicLog(vp.planet).Since(sinceTime)
+}
+
+// momentMagnitude is the magnitude of a slip releasing stress (Pa) over a
+// rupture of area (m²). The seismic moment of a crack with that stress drop
+// scales as stress × area^1.5, and Mw = 2/3 (log10 M0 - 9.1)
+func momentMagnitude(stress, area float64) float64 {
+	moment := stress * math.Pow(area, 1.5)
+	if moment <= 0 {
+		return 0
+	}
+	return 2.0 / 3.0 * (math.Log10(moment) - 9.1)
+}
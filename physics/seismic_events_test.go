@@ -0,0 +1,99 @@
+package physics
+
+import (
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// TestReleaseStressLogsEarthquake checks a fault loaded past yield slips and
+// is logged where it broke, bigger for a larger stress drop, while one below
+// yield stays quiet
+func TestReleaseStressLogsEarthquake(t *testing.T) {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 4, 20)
+	planet.Time = 5000
+	surface := len(planet.Shells) - 2
+	vm := NewVoxelMechanics(planet, nil)
+
+	shell := &planet.Shells[surface]
+	small, big, quiet := &shell.Voxels[10][0], &shell.Voxels[10][5], &shell.Voxels[12][0]
+	for _, voxel := range []*core.VoxelMaterial{small, big, quiet} {
+		voxel.YieldStrength = 1e8
+	}
+	small.Stress, big.Stress, quiet.Stress = 2e8, 2e9, 5e7
+
+	vm.releaseStress(surface, 10, 0)
+	vm.releaseStress(surface, 10, 5)
+	vm.releaseStress(surface, 12, 0)
+
+	logged := seismicLog(planet).Since(0)
+	if len(logged) != 2 {
+		t.Fatalf("logged %d earthquakes, want 2", len(logged))
+	}
+	if small.Stress != 0 || !small.IsFractured || quiet.Stress != 5e7 || quiet.IsFractured {
+		t.Errorf("stress %g and %g after release, want 0 on the slipped fault and 5e7 below yield", small.Stress, quiet.Stress)
+	}
+
+	lat, lon := planet.VoxelLatLon(core.VoxelCoord{Shell: surface, Lat: 10, Lon: 0})
+	if e := logged[0]; e.Lat != lat || e.Lon != lon || e.Time != 5000 {
+		t.Errorf("earthquake at %.2f°, %.2f° in year %.0f, want %.2f°, %.2f° in year 5000", e.Lat, e.Lon, e.Time, lat, lon)
+	}
+	if logged[0].Magnitude <= 0 || logged[1].Magnitude <= logged[0].Magnitude {
+		t.Errorf("magnitudes %.2f and %.2f, want positive and growing with the stress drop", logged[0].Magnitude, logged[1].Magnitude)
+	}
+	if len(seismicLog(planet).Since(5000)) != 0 {
+		t.Error("polling from the last event's time returned it again")
+	}
+}
+
+// TestCollisionLogsEarthquake converges two continental voxels on different
+// plates: each logs one earthquake as collision loads it past yield, and
+// keeps its stress
+func TestCollisionLogsEarthquake(t *testing.T) {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 4, 20)
+	surface := len(planet.Shells) - 2
+	vm := NewVoxelMechanics(planet, nil)
+
+	shell := &planet.Shells[surface]
+	west, east := &shell.Voxels[10][0], &shell.Voxels[10][1]
+	for i, voxel := range []*core.VoxelMaterial{west, east} {
+		voxel.Type = core.MatGranite
+		voxel.YieldStrength = 1e8
+		voxel.PlateID = int32(i + 1)
+	}
+
+	// Each step loads both sides by 0.06 m/year × 1000 years × 1e6 Pa, past
+	// yield on the second
+	for step := 0; step < 3; step++ {
+		west.VelEast, east.VelEast = 0.06, 0
+		vm.UpdateCollisions(1000)
+	}
+
+	if got := len(seismicLog(planet).Since(-1)); got != 2 {
+		t.Errorf("logged %d earthquakes, want one per side", got)
+	}
+	if west.Stress <= west.YieldStrength || east.Stress <= east.YieldStrength || !west.IsFractured {
+		t.Errorf("stress %g and %g after rupture, want both kept past the %g yield", west.Stress, east.Stress, west.YieldStrength)
+	}
+}
+
+// TestSeismicLogRing checks the log keeps only its newest events, oldest first
+func TestSeismicLogRing(t *testing.T) {
+	log := NewSeismicLog(3)
+	for year := 1.0; year <= 5; year++ {
+		log.Record(SeismicEvent{Magnitude: 6, Time: year})
+	}
+
+	if log.Len() != 3 {
+		t.Fatalf("log holds %d events, want 3", log.Len())
+	}
+	events := log.Since(0)
+	for i, want := range []float64{3, 4, 5} {
+		if events[i].Time != want {
+			t.Errorf("event %d in year %.0f, want %.0f", i, events[i].Time, want)
+		}
+	}
+	if got := log.Since(4); len(got) != 1 || got[0].Time != 5 {
+		t.Errorf("events after year 4: %v, want only year 5", got)
+	}
+}
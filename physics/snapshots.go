@@ -0,0 +1,79 @@
+package physics
+
+import (
+	"unsafe"
+
+	"worldgenerator/core"
+)
+
+// SnapshotStore keeps a ring buffer of planet copies taken at a fixed spacing
+// in simulation time, so past states can be reviewed without re-running physics
+// Once full, each new snapshot reuses the oldest one's memory
+type SnapshotStore struct {
+	snapshots []*core.VoxelPlanet
+	start     int // Index of the oldest snapshot
+	count     int
+
+	interval float64 // Simulation years between snapshots
+	lastTime float64
+}
+
+// NewSnapshotStore creates a store holding up to capacity snapshots taken at
+// least intervalYears apart
+func NewSnapshotStore(capacity int, intervalYears float64) *SnapshotStore {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &SnapshotStore{
+		snapshots: make([]*core.VoxelPlanet, 0, capacity),
+		interval:  intervalYears,
+	}
+}
+
+// SnapshotBytes estimates the memory one snapshot of planet takes
+func SnapshotBytes(planet *core.VoxelPlanet) int {
+	voxels := 0
+	for _, shell := range planet.Shells {
+		for _, band := range shell.Voxels {
+			voxels += len(band)
+		}
+	}
+	return voxels * int(unsafe.Sizeof(core.VoxelMaterial{}))
+}
+
+// Record copies planet into the store if at least the snapshot interval has
+// passed since the last snapshot, and reports whether it did
+func (s *SnapshotStore) Record(planet *core.VoxelPlanet) bool {
+	if s.count > 0 && planet.Time-s.lastTime < s.interval {
+		return false
+	}
+	s.lastTime = planet.Time
+
+	// Still filling: every snapshot gets its own copy
+	if len(s.snapshots) < cap(s.snapshots) {
+		s.snapshots = append(s.snapshots, deepCopyPlanet(planet))
+		s.count++
+		return true
+	}
+
+	// Full: overwrite the oldest and advance the start of the ring
+	oldest := s.snapshots[s.start]
+	copyPlanetState(oldest, planet)
+	s.start = (s.start + 1) % len(s.snapshots)
+	return true
+}
+
+// Len returns the number of stored snapshots
+func (s *SnapshotStore) Len() int {
+	return s.count
+}
+
+// At returns snapshot i, where 0 is the oldest and Len()-1 the newest
+// The returned planet is owned by the store and is overwritten once the ring
+// wraps around to it
+func (s *SnapshotStore) At(i int) *core.VoxelPlanet {
+	if i < 0 || i >= s.count {
+		return nil
+	}
+	return s.snapshots[(s.start+i)%len(s.snapshots)]
+}
@@ -0,0 +1,41 @@
+package physics
+
+import (
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// TestSnapshotStoreRing checks snapshots respect their spacing, are independent
+// copies, and that the oldest are dropped once the ring is full
+func TestSnapshotStoreRing(t *testing.T) {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 4, 20)
+	voxel := &planet.Shells[len(planet.Shells)-2].Voxels[10][0]
+	store := NewSnapshotStore(3, 1000)
+
+	// Record at years 0, 500 (too soon), 1000, 2000 and 3000
+	for _, year := range []float64{0, 500, 1000, 2000, 3000} {
+		planet.Time = year
+		voxel.Temperature = float32(year)
+		recorded := store.Record(planet)
+		if want := year != 500; recorded != want {
+			t.Errorf("year %.0f: recorded %v, want %v", year, recorded, want)
+		}
+	}
+
+	if store.Len() != 3 {
+		t.Fatalf("store holds %d snapshots, want 3", store.Len())
+	}
+	for i, want := range []float64{1000, 2000, 3000} {
+		snap := store.At(i)
+		if snap.Time != want {
+			t.Errorf("snapshot %d at year %.0f, want %.0f", i, snap.Time, want)
+		}
+		if temp := snap.Shells[len(snap.Shells)-2].Voxels[10][0].Temperature; temp != float32(want) {
+			t.Errorf("snapshot %d voxel temperature %.0f, want the %.0f recorded then", i, temp, want)
+		}
+	}
+	if store.At(-1) != nil || store.At(3) != nil {
+		t.Error("out of range snapshot index returned a planet")
+	}
+}
@@ -0,0 +1,35 @@
+package physics
+
+import (
+	"math"
+	"worldgenerator/core"
+	"worldgenerator/gpu"
+)
+
+// SpinUp advances the planet to targetYear as fast as possible, without rendering
+// Steps are stepYears long except the last, which lands exactly on targetYear
+// progress is called after every step with the current simulation year (may be nil)
+// Returns the number of steps run, stopping early if a physics check halts it
+func SpinUp(planet *core.VoxelPlanet, targetYear, stepYears float64, gpuCompute gpu.GPUCompute, progress func(year float64)) int {
+	return spinUp(planet, targetYear, stepYears, gpuCompute, progress, &PhysicsTimings{})
+}
+
+// spinUp is SpinUp, adding each subsystem's time to timings
+func spinUp(planet *core.VoxelPlanet, targetYear, stepYears float64, gpuCompute gpu.GPUCompute, progress func(year float64), timings *PhysicsTimings) int {
+	if stepYears <= 0 {
+		return 0
+	}
+
+	steps := 0
+	for planet.Time < targetYear && !planet.Halted() {
+		dt := math.Min(stepYears, targetYear-planet.Time)
+		step(planet, dt, gpuCompute, timings)
+		steps++
+
+		if progress != nil {
+			progress(planet.Time)
+		}
+	}
+
+	return steps
+}
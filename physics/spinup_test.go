@@ -0,0 +1,34 @@
+package physics
+
+import (
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// TestSpinUpReachesTarget checks spin-up stops exactly on the target year
+func TestSpinUpReachesTarget(t *testing.T) {
+	planet := core.CreateVoxelPlanet(6371000.0, 4)
+
+	var years []float64
+	steps := SpinUp(planet, 3500.0, 1000.0, nil, func(year float64) {
+		years = append(years, year)
+	})
+
+	if steps != 4 {
+		t.Errorf("got %d steps, want 4 (three full and one partial)", steps)
+	}
+	if planet.Time != 3500.0 {
+		t.Errorf("planet time %.1f, want 3500", planet.Time)
+	}
+	for i := 1; i < len(years); i++ {
+		if years[i] <= years[i-1] {
+			t.Errorf("progress went backwards: %.1f after %.1f", years[i], years[i-1])
+		}
+	}
+
+	// Already past the target - nothing to do
+	if steps := SpinUp(planet, 1000.0, 1000.0, nil, nil); steps != 0 {
+		t.Errorf("got %d steps past the target, want 0", steps)
+	}
+}
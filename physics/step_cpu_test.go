@@ -0,0 +1,123 @@
+package physics
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// surfaceWaterCount counts liquid and frozen water voxels on the surface shell
+func surfaceWaterCount(planet *core.VoxelPlanet) int {
+	count := 0
+	for _, band := range planet.Shells[len(planet.Shells)-2].Voxels {
+		for _, voxel := range band {
+			if voxel.Type == core.MatWater || voxel.Type == core.MatIce {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// TestStepCPUConservation runs one full physics step end to end and checks
+// the grid, the water inventory and the state stay physically consistent
+func TestStepCPUConservation(t *testing.T) {
+	planet := testPlanet(8, surfaceBands(core.DefaultSurfaceBands))
+	const dt = 1000.0
+
+	voxelCounts := make([]int, len(planet.Shells))
+	for i, shell := range planet.Shells {
+		for _, band := range shell.Voxels {
+			voxelCounts[i] += len(band)
+		}
+	}
+	waterBefore := surfaceWaterCount(planet)
+
+	StepCPU(planet, dt)
+
+	if planet.Time != dt {
+		t.Errorf("planet at year %.0f after one step, want %.0f", planet.Time, dt)
+	}
+
+	// No voxels are created or destroyed
+	for i, shell := range planet.Shells {
+		count := 0
+		for latIdx, band := range shell.Voxels {
+			count += len(band)
+			if len(band) != shell.LonCounts[latIdx] {
+				t.Fatalf("shell %d band %d has %d voxels, want %d", i, latIdx, len(band), shell.LonCounts[latIdx])
+			}
+		}
+		if count != voxelCounts[i] {
+			t.Errorf("shell %d has %d voxels after a step, want %d", i, count, voxelCounts[i])
+		}
+	}
+
+	// Water moves and freezes but is not lost or created
+	waterAfter := surfaceWaterCount(planet)
+	if drift := math.Abs(float64(waterAfter-waterBefore)) / float64(waterBefore); drift > 0.01 {
+		t.Errorf("surface water changed from %d to %d voxels (%.1f%%)", waterBefore, waterAfter, drift*100)
+	}
+
+	// Every voxel holds a finite, physical state
+	for i, shell := range planet.Shells {
+		for latIdx, band := range shell.Voxels {
+			for lonIdx, voxel := range band {
+				values := []float32{voxel.Temperature, voxel.Density, voxel.Pressure, voxel.VelR, voxel.VelNorth, voxel.VelEast}
+				for _, v := range values {
+					if math.IsNaN(float64(v)) || math.IsInf(float64(v), 0) {
+						t.Fatalf("shell %d voxel [%d][%d] has non-finite state %+v", i, latIdx, lonIdx, voxel)
+					}
+				}
+				if voxel.Temperature <= 0 {
+					t.Fatalf("shell %d voxel [%d][%d] at %.1f K", i, latIdx, lonIdx, voxel.Temperature)
+				}
+				if voxel.Type != core.MatAir && voxel.Density <= 0 {
+					t.Fatalf("shell %d voxel [%d][%d] of type %d has density %.1f", i, latIdx, lonIdx, voxel.Type, voxel.Density)
+				}
+			}
+		}
+	}
+
+	// The same planet and step give the same result
+	again := testPlanet(8, surfaceBands(core.DefaultSurfaceBands))
+	StepCPU(again, dt)
+	for i := range planet.Shells {
+		if !reflect.DeepEqual(planet.Shells[i].Voxels, again.Shells[i].Voxels) {
+			t.Errorf("shell %d differs between two identical steps", i)
+		}
+	}
+}
+
+// TestSummaryCountsSteppedPlates steps a planet headless, with no renderer
+// holding a plate manager, and checks the summary counts the plates physics
+// moved the surface with
+func TestSummaryCountsSteppedPlates(t *testing.T) {
+	planet := testPlanet(8, surfaceBands(core.DefaultSurfaceBands))
+	for step := 0; step < 2; step++ {
+		StepCPU(planet, 1000.0)
+	}
+
+	plates := planet.Physics.(*VoxelPhysics).GetPlateManagerDirect()
+	if got := planet.Summary().PlateCount; got == 0 || got != plates.GetPlateCount() {
+		t.Errorf("summary counts %d plates, physics moved the surface with %d", got, plates.GetPlateCount())
+	}
+}
+
+// TestUncappedPlatesStayBounded steps a planet that leaves MaxPlates unset
+// and checks rifting can't shatter it into ever more slivers
+func TestUncappedPlatesStayBounded(t *testing.T) {
+	planet := testPlanet(8, surfaceBands(core.DefaultSurfaceBands), func(p *core.PlanetGenerationParams) {
+		p.MaxPlates = 0
+	})
+
+	for step := 0; step < 5; step++ {
+		StepCPU(planet, 100000.0)
+		plates := planet.Physics.(*VoxelPhysics).GetPlateManagerDirect()
+		if count := plates.GetPlateCount(); count > core.DefaultMaxPlates {
+			t.Fatalf("%d plates after %d steps, want at most %d", count, step+1, core.DefaultMaxPlates)
+		}
+	}
+}
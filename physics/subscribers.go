@@ -0,0 +1,89 @@
+package physics
+
+import (
+	"worldgenerator/core"
+)
+
+// Subscribe returns a channel that receives a copy of the planet each time
+// physics publishes new state, for consumers outside the render loop such
+// as analytics. A snapshot shares nothing with the live planet but the
+// earthquake log, which locks itself, so it can be read from any goroutine
+// while physics carries on. Every subscriber receives the same copy, so
+// treat it as read-only
+// Copying takes SnapshotBytes of memory and a pass over every voxel on the
+// physics thread, once per published step however many subscribers there
+// are - Unsubscribe to stop paying for it. The channel holds one snapshot:
+// a consumer that falls behind gets the newest rather than a backlog, and
+// never holds physics up. Unsubscribe and Stop close it
+func (e *ThreadedPhysicsEngine) Subscribe() <-chan *core.VoxelPlanet {
+	e.subscribersMutex.Lock()
+	defer e.subscribersMutex.Unlock()
+	ch := make(chan *core.VoxelPlanet, 1)
+	if e.subscribersClosed {
+		close(ch)
+		return ch
+	}
+	e.subscribers = append(e.subscribers, ch)
+	return ch
+}
+
+// Unsubscribe stops and closes a channel from Subscribe. Once no channel is
+// left, physics stops copying the planet
+func (e *ThreadedPhysicsEngine) Unsubscribe(snapshots <-chan *core.VoxelPlanet) {
+	e.subscribersMutex.Lock()
+	defer e.subscribersMutex.Unlock()
+	for i, ch := range e.subscribers {
+		if ch == snapshots {
+			e.subscribers = append(e.subscribers[:i], e.subscribers[i+1:]...)
+			close(ch)
+			return
+		}
+	}
+}
+
+// publishSnapshot sends subscribers a copy of the planet just swapped in
+// for reading, which physics won't touch until its next step
+func (e *ThreadedPhysicsEngine) publishSnapshot() {
+	e.subscribersMutex.Lock()
+	defer e.subscribersMutex.Unlock()
+	if len(e.subscribers) == 0 {
+		return
+	}
+	snapshot := snapshotPlanet(e.currentRead.Load())
+	for _, ch := range e.subscribers {
+		// Replace a snapshot the consumer hasn't taken yet
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- snapshot
+	}
+}
+
+// closeSubscribers closes every subscriber's channel as the engine stops
+func (e *ThreadedPhysicsEngine) closeSubscribers() {
+	e.subscribersMutex.Lock()
+	defer e.subscribersMutex.Unlock()
+	for _, ch := range e.subscribers {
+		close(ch)
+	}
+	e.subscribers = nil
+	e.subscribersClosed = true
+}
+
+// snapshotPlanet deep copies a planet for readers on other goroutines: the
+// voxels and the plate colors and tracks physics keeps rewriting are its
+// own. The physics systems, which belong to the engine, are left out
+func snapshotPlanet(src *core.VoxelPlanet) *core.VoxelPlanet {
+	dst := deepCopyPlanet(src)
+	dst.Physics = nil
+	dst.PlateHues = make(map[int32]float32, len(src.PlateHues))
+	for id, hue := range src.PlateHues {
+		dst.PlateHues[id] = hue
+	}
+	dst.PlateTracks = make(map[int32][]core.PlateTrackPoint, len(src.PlateTracks))
+	for id, points := range src.PlateTracks {
+		dst.PlateTracks[id] = append([]core.PlateTrackPoint(nil), points...)
+	}
+	return dst
+}
@@ -0,0 +1,65 @@
+package physics
+
+import (
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// TestSurfaceLatBands checks a surface finer than the interior gets its own
+// band count, that the crust below lines up with it by position, and that
+// plates advect across the mixed-resolution shells without losing the land
+func TestSurfaceLatBands(t *testing.T) {
+	planet := testPlanet(10, forcing(5), surfaceBands(30), func(p *core.PlanetGenerationParams) {
+		p.SurfaceLatBands = 90
+	})
+
+	surfaceIdx := len(planet.Shells) - 2
+	for i, shell := range planet.Shells {
+		want := min(20+i*i*2, 30)
+		if i >= surfaceIdx {
+			want = 90
+		}
+		if shell.LatBands != want {
+			t.Errorf("shell %d has %d bands, want %d", i, shell.LatBands, want)
+		}
+	}
+
+	// Continental crust sits under continents, not at the same indices
+	surface := &planet.Shells[surfaceIdx]
+	crust := &planet.Shells[surfaceIdx-1]
+	for latIdx := range crust.Voxels {
+		for lonIdx := range crust.Voxels[latIdx] {
+			lat, lon := planet.VoxelLatLon(core.VoxelCoord{Shell: surfaceIdx - 1, Lat: latIdx, Lon: lonIdx})
+			land := planet.Column(lat, lon)[surfaceIdx].Type == core.MatGranite
+			if got := crust.Voxels[latIdx][lonIdx].Type == core.MatGranite; got != land {
+				t.Fatalf("crust at %.1f°, %.1f° is granite=%v under land=%v", lat, lon, got, land)
+			}
+		}
+	}
+
+	landBefore := landArea(planet, surface)
+	const dt = 100000.0
+	for step := 0; step < 5; step++ {
+		StepCPU(planet, dt)
+	}
+	if _, moving := movingCrust(planet); moving == 0 {
+		t.Error("no surface crust moved on the fine surface shell")
+	}
+	if landAfter := landArea(planet, surface); landAfter < landBefore*0.8 || landAfter > landBefore*1.2 {
+		t.Errorf("land area went from %.3g to %.3g m² over 5 steps", landBefore, landAfter)
+	}
+}
+
+// landArea sums the area of continental voxels in a shell
+func landArea(planet *core.VoxelPlanet, shell *core.SphericalShell) float64 {
+	area := 0.0
+	for latIdx, band := range shell.Voxels {
+		for _, voxel := range band {
+			if voxel.Type == core.MatGranite {
+				area += planet.VoxelArea(shell, latIdx)
+			}
+		}
+	}
+	return area
+}
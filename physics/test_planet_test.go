@@ -0,0 +1,44 @@
+package physics
+
+import "worldgenerator/core"
+
+// testPlanet generates the small fixed-seed planet with continents and
+// oceans that tests and benchmarks step. Tests needing a different grid,
+// seed or forcing change the settings in configure. The golden hash is taken
+// from testPlanet(5), so changing the defaults means regenerating it
+func testPlanet(shells int, configure ...func(*core.PlanetGenerationParams)) *core.VoxelPlanet {
+	params := core.PlanetGenerationParams{
+		Seed:               42,
+		ContinentCount:     4,
+		OceanFraction:      0.7,
+		MinContinentSize:   0.02,
+		MaxContinentSize:   0.1,
+		ContinentRoughness: 0.5,
+		MaxPlates:          20,
+		SurfaceBands:       60,
+		AxialTilt:          core.NoAxialTilt, // No seasons to alias with steps of many years
+	}
+	for _, c := range configure {
+		c(&params)
+	}
+
+	planet, err := core.CreateRandomizedPlanet(6371000.0, shells, params)
+	if err != nil {
+		panic(err)
+	}
+	return planet
+}
+
+// surfaceBands sets the latitude bands of the surface shell
+func surfaceBands(bands int) func(*core.PlanetGenerationParams) {
+	return func(p *core.PlanetGenerationParams) {
+		p.SurfaceBands = bands
+	}
+}
+
+// forcing sets the speed plates are forced to in cm/year
+func forcing(cmPerYear float64) func(*core.PlanetGenerationParams) {
+	return func(p *core.PlanetGenerationParams) {
+		p.ConvectionForcing = cmPerYear
+	}
+}
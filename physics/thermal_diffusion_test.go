@@ -0,0 +1,87 @@
+package physics
+
+import (
+	"math"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// newUniformPlanet fills every shell with one material at 300 K
+// and places a 1000 K voxel in the middle of a mid-depth shell
+func newUniformPlanet(mat core.MaterialType) (*core.VoxelPlanet, core.VoxelCoord) {
+	planet := core.CreateVoxelPlanet(6371000.0, 8)
+	for shellIdx := range planet.Shells {
+		for latIdx := range planet.Shells[shellIdx].Voxels {
+			for lonIdx := range planet.Shells[shellIdx].Voxels[latIdx] {
+				voxel := &planet.Shells[shellIdx].Voxels[latIdx][lonIdx]
+				voxel.Type = mat
+				voxel.Temperature = 300
+			}
+		}
+	}
+
+	// Deep heating would add the same offset to both runs - disable it
+	planet.CoreBoundary = core.CoreBoundaryFixedFlux
+
+	hot := core.VoxelCoord{Shell: 4, Lat: planet.Shells[4].LatBands / 2, Lon: 0}
+	planet.GetVoxel(hot).Temperature = 1000
+	return planet, hot
+}
+
+// TestConductivityEquilibrationRate checks a conductive material loses a hot spot faster
+func TestConductivityEquilibrationRate(t *testing.T) {
+	if core.MaterialDiffusivity(core.MatGranite) <= core.MaterialDiffusivity(core.MatWater) {
+		t.Fatalf("test expects granite to be more diffusive than water")
+	}
+
+	excess := make(map[core.MaterialType]float32)
+	for _, mat := range []core.MaterialType{core.MatGranite, core.MatWater} {
+		planet, hot := newUniformPlanet(mat)
+
+		// Pick a step that is stable but visible for the faster material
+		shell := &planet.Shells[hot.Shell]
+		dx := (shell.InnerRadius + shell.OuterRadius) / 2 * 2 * math.Pi / float64(shell.LonCounts[hot.Lat])
+		dt := 0.1 * dx * dx / float64(core.MaterialDiffusivity(core.MatGranite))
+
+		updateTemperatureCPU(planet, dt)
+
+		temp := planet.GetVoxel(hot).Temperature
+		if temp >= 1000 || temp <= 300 {
+			t.Fatalf("material %d: hot voxel at %.2f K, want between 300 and 1000", mat, temp)
+		}
+		excess[mat] = temp - 300
+	}
+
+	if excess[core.MatGranite] >= excess[core.MatWater] {
+		t.Errorf("granite hot spot %.2f K above background, water %.2f K - granite should cool faster",
+			excess[core.MatGranite], excess[core.MatWater])
+	}
+}
+
+// TestRadiogenicHeatHalves checks the deep interior heats half as fast one
+// half-life after formation
+func TestRadiogenicHeatHalves(t *testing.T) {
+	const halfLife, dt = 2e9, 1000.0
+	rise := func(year float64) float32 {
+		planet, _ := newUniformPlanet(core.MatPeridotite)
+		planet.CoreBoundary = core.CoreBoundaryNone // Deep heating on
+		planet.InitialRadiogenicHeat = 1e-3
+		planet.HeatHalfLifeYears = halfLife
+		planet.Time = year
+
+		// Shell 1 is deep and uniform, so only radiogenic heat warms it
+		deep := core.VoxelCoord{Shell: 1, Lat: planet.Shells[1].LatBands / 2, Lon: 0}
+		before := planet.GetVoxel(deep).Temperature
+		updateTemperatureCPU(planet, dt)
+		return planet.GetVoxel(deep).Temperature - before
+	}
+
+	young, old := rise(0), rise(halfLife)
+	if young <= 0 {
+		t.Fatalf("deep voxel warmed %.4f K at formation, want radiogenic heating", young)
+	}
+	if math.Abs(float64(old/young)-0.5) > 0.01 {
+		t.Errorf("deep voxel warmed %.4f K after one half-life, %.4f K at formation - want half", old, young)
+	}
+}
@@ -1,6 +1,7 @@
 package physics
 
 import (
+	"math"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -14,6 +15,7 @@ type ThreadedPhysicsEngine struct {
 	running    atomic.Bool
 	wg         sync.WaitGroup
 	updateChan chan physicsUpdate
+	stopOnce   sync.Once
 
 	// Double buffering for thread-safe data exchange
 	planetA      *core.VoxelPlanet
@@ -24,13 +26,39 @@ type ThreadedPhysicsEngine struct {
 
 	// Physics state
 	physics    *VoxelPhysics
+	atmosphere *AtmosphereSolver // Prevailing winds, on their own cadence
 	gpuCompute gpu.GPUCompute
 	simSpeed   float64
 
+	// Optional fixed timestep (nil = one variable step per tick)
+	fixedStep *FixedTimestep
+	stepMutex sync.Mutex
+
+	// Seconds of simulation speed each tick covers (0 = the measured time
+	// since the previous tick). Guarded by stepMutex
+	fixedTick float64
+
+	// Manual stepping while paused
+	paused      atomic.Bool
+	stepChan    chan float64 // Years to advance (0 = one step)
+	manualSteps atomic.Int64 // Completed manual steps
+
+	// Plumes to inject before the next step, from the main thread
+	plumeChan chan core.MantlePlume
+
+	// Plate held still as the reference frame (0 = absolute frame)
+	referencePlate atomic.Int64
+
+	// Channels receiving a copy of each published planet, see Subscribe
+	subscribers       []chan *core.VoxelPlanet
+	subscribersClosed bool
+	subscribersMutex  sync.Mutex
+
 	// Performance tracking
 	lastPhysicsTime   time.Time
 	physicsFrameTime  float64
 	physicsUpdateRate float64 // Updates per second
+	timings           atomic.Pointer[PhysicsTimings]
 }
 
 type physicsUpdate struct {
@@ -40,13 +68,25 @@ type physicsUpdate struct {
 
 // NewThreadedPhysicsEngine creates a new background physics engine
 func NewThreadedPhysicsEngine(planet *core.VoxelPlanet, gpuCompute gpu.GPUCompute, simSpeed float64) *ThreadedPhysicsEngine {
-	// Create a deep copy of the planet for double buffering
+	// Create a deep copy of the planet for double buffering, sharing one
+	// earthquake log, plate color table and plate tracks so they survive
+	// buffer swaps
+	seismicLog(planet)
+	if planet.PlateHues == nil {
+		planet.PlateHues = make(map[int32]float32)
+	}
+	if planet.PlateTracks == nil {
+		planet.PlateTracks = make(map[int32][]core.PlateTrackPoint)
+	}
 	planetCopy := deepCopyPlanet(planet)
 
 	engine := &ThreadedPhysicsEngine{
 		updateChan:        make(chan physicsUpdate, 10),
+		stepChan:          make(chan float64, 10),
+		plumeChan:         make(chan core.MantlePlume, 10),
 		planetA:           planet,
 		planetB:           planetCopy,
+		atmosphere:        NewAtmosphereSolver(),
 		gpuCompute:        gpuCompute,
 		simSpeed:          simSpeed,
 		lastPhysicsTime:   time.Now(),
@@ -70,11 +110,16 @@ func (e *ThreadedPhysicsEngine) Start() {
 	go e.physicsThread()
 }
 
-// Stop halts the physics thread
+// Stop halts the physics thread after its current step finishes
+// It is safe to call more than once, so shutdown paths can stop the engine early
+// and still leave a deferred Stop in place
 func (e *ThreadedPhysicsEngine) Stop() {
-	e.running.Store(false)
-	close(e.updateChan)
+	e.stopOnce.Do(func() {
+		e.running.Store(false)
+		close(e.updateChan)
+	})
 	e.wg.Wait()
+	e.closeSubscribers()
 }
 
 // GetCurrentPlanet returns the current read-safe planet data
@@ -99,6 +144,151 @@ func (e *ThreadedPhysicsEngine) UpdateSimSpeed(speed float64) {
 	e.simSpeed = speed
 }
 
+// maxFixedStepsPerTick bounds catch-up work so a slow step can't stall the thread
+const maxFixedStepsPerTick = 100
+
+// SetFixedTimestep switches to fixed physics steps of stepYears simulation years
+// A value <= 0 restores one variable-size step per tick
+func (e *ThreadedPhysicsEngine) SetFixedTimestep(stepYears float64) {
+	e.stepMutex.Lock()
+	defer e.stepMutex.Unlock()
+
+	if stepYears <= 0 {
+		e.fixedStep = nil
+		return
+	}
+	e.fixedStep = NewFixedTimestep(stepYears, maxFixedStepsPerTick)
+}
+
+// SetFixedTick makes every tick advance exactly seconds × simulation speed,
+// however long the tick actually took, so a stalled window slows the
+// simulation down instead of making it jump. A value <= 0 restores ticks
+// measured by the wall clock
+func (e *ThreadedPhysicsEngine) SetFixedTick(seconds float64) {
+	e.stepMutex.Lock()
+	defer e.stepMutex.Unlock()
+
+	e.fixedTick = math.Max(seconds, 0)
+}
+
+// SetPaused stops or resumes time-driven physics steps
+// Simulated time does not accumulate while paused
+func (e *ThreadedPhysicsEngine) SetPaused(paused bool) {
+	e.paused.Store(paused)
+}
+
+// IsPaused reports whether time-driven steps are stopped
+func (e *ThreadedPhysicsEngine) IsPaused() bool {
+	return e.paused.Load()
+}
+
+// SetReferencePlate holds a plate still so every other plate moves relative
+// to it, from the next step on (0 = absolute frame)
+func (e *ThreadedPhysicsEngine) SetReferencePlate(plateID int) {
+	e.referencePlate.Store(int64(plateID))
+}
+
+// RequestStep advances a paused simulation by years (0 = one physics step)
+// and leaves it paused. Returns false if the engine is running freely
+func (e *ThreadedPhysicsEngine) RequestStep(years float64) bool {
+	if !e.paused.Load() {
+		return false
+	}
+	select {
+	case e.stepChan <- years:
+		return true
+	default:
+		return false // Too many steps queued
+	}
+}
+
+// InjectPlume roots a mantle plume under latDeg/lonDeg before the next step,
+// see VoxelAdvection.InjectPlume. Returns false if too many are queued
+func (e *ThreadedPhysicsEngine) InjectPlume(latDeg, lonDeg, temperatureBoost float64) bool {
+	select {
+	case e.plumeChan <- core.MantlePlume{Lat: latDeg, Lon: lonDeg, TemperatureBoost: temperatureBoost}:
+		return true
+	default:
+		return false
+	}
+}
+
+// GetRecentSeismicEvents returns the earthquakes recorded after sinceTime
+// (simulation years), oldest first. Safe to call from any thread
+func (e *ThreadedPhysicsEngine) GetRecentSeismicEvents(sinceTime float64) []SeismicEvent {
+	return seismicLog(e.planetA).Since(sinceTime)
+}
+
+// injectQueuedPlumes adds the plumes queued since the last step to planet
+func (e *ThreadedPhysicsEngine) injectQueuedPlumes(planet *core.VoxelPlanet) {
+	for {
+		select {
+		case plume := <-e.plumeChan:
+			injectPlume(planet, plume)
+		default:
+			return
+		}
+	}
+}
+
+// ManualSteps returns the number of manual steps completed so far
+func (e *ThreadedPhysicsEngine) ManualSteps() int64 {
+	return e.manualSteps.Load()
+}
+
+// stepYears returns the size of one physics step in simulation years
+func (e *ThreadedPhysicsEngine) stepYears() float64 {
+	e.stepMutex.Lock()
+	defer e.stepMutex.Unlock()
+
+	if e.fixedStep != nil {
+		return e.fixedStep.StepYears
+	}
+	return e.simSpeed / e.physicsUpdateRate // One normal tick
+}
+
+// runManualStep advances the displayed state and publishes the result
+func (e *ThreadedPhysicsEngine) runManualStep(years float64) {
+	readPlanet := e.currentRead.Load()
+	writePlanet := e.currentWrite.Load()
+
+	// Step from what is on screen, not the older back buffer
+	copyPlanetState(writePlanet, readPlanet)
+	writePlanet.ReferencePlate = int(e.referencePlate.Load())
+	e.injectQueuedPlumes(writePlanet)
+
+	stepYears := e.stepYears()
+	if years <= 0 {
+		years = stepYears
+	}
+
+	startTime := time.Now()
+	var timings PhysicsTimings
+	spinUp(writePlanet, writePlanet.Time+years, stepYears, e.gpuCompute, nil, &timings)
+	climateStart := time.Now()
+	e.atmosphere.Solve(writePlanet)
+	ClassifyBiomes(writePlanet)
+	timings.Climate = time.Since(climateStart)
+	timings.Total = time.Since(startTime)
+	e.physicsFrameTime = timings.Total.Seconds()
+	e.timings.Store(&timings)
+
+	publishPlates(writePlanet)
+	e.SwapBuffers()
+	e.publishSnapshot()
+	e.manualSteps.Add(1)
+}
+
+// updateClimate follows a physics step with the winds and, when they are
+// solved, the biomes that depend on the same climate
+func (e *ThreadedPhysicsEngine) updateClimate(planet *core.VoxelPlanet, timings *PhysicsTimings) {
+	start := time.Now()
+	if e.atmosphere.Update(planet) {
+		ClassifyBiomes(planet)
+	}
+	timings.Climate += time.Since(start)
+}
+
 // physicsThread runs in the background
 func (e *ThreadedPhysicsEngine) physicsThread() {
 	defer e.wg.Done()
@@ -109,24 +299,12 @@ func (e *ThreadedPhysicsEngine) physicsThread() {
 	for e.running.Load() {
 		select {
 		case <-ticker.C:
-			// Calculate time since last physics update
-			now := time.Now()
-			dt := now.Sub(e.lastPhysicsTime).Seconds()
-			e.lastPhysicsTime = now
-
-			// Get the write buffer
-			writePlanet := e.currentWrite.Load()
-
-			// Run physics simulation
-			startTime := time.Now()
-			UpdateVoxelPhysicsWrapper(writePlanet, dt*e.simSpeed, e.gpuCompute)
-			e.physicsFrameTime = time.Since(startTime).Seconds()
-
-			// Update simulation time
-			writePlanet.Time += dt * e.simSpeed
+			e.tick(time.Now())
 
-			// Swap buffers for next frame
-			e.SwapBuffers()
+		case years := <-e.stepChan:
+			if e.paused.Load() {
+				e.runManualStep(years)
+			}
 
 		case update := <-e.updateChan:
 			// Handle parameter updates
@@ -135,11 +313,91 @@ func (e *ThreadedPhysicsEngine) physicsThread() {
 	}
 }
 
+// tick runs the time-driven physics for one tick of the physics thread
+func (e *ThreadedPhysicsEngine) tick(now time.Time) {
+	// Calculate time since last physics update
+	dt := now.Sub(e.lastPhysicsTime).Seconds()
+	e.lastPhysicsTime = now
+
+	// Paused time is dropped, not caught up on resume
+	if e.paused.Load() {
+		return
+	}
+
+	// Get the write buffer, caught up with the last published state so the
+	// two buffers don't advance separately on alternate ticks
+	writePlanet := e.currentWrite.Load()
+	copyPlanetState(writePlanet, e.currentRead.Load())
+	writePlanet.ReferencePlate = int(e.referencePlate.Load())
+
+	// Run physics simulation
+	startTime := time.Now()
+	var timings PhysicsTimings
+	e.stepMutex.Lock()
+	if e.fixedTick > 0 {
+		dt = e.fixedTick
+	}
+	if e.fixedStep != nil {
+		// Run as many fixed steps as the elapsed sim time covers
+		steps := e.fixedStep.Advance(dt * e.simSpeed)
+		if steps > 0 {
+			// Queued plumes wait for a tick that publishes its step
+			e.injectQueuedPlumes(writePlanet)
+		}
+		for i := 0; i < steps; i++ {
+			step(writePlanet, e.fixedStep.StepYears, e.gpuCompute, &timings)
+			e.updateClimate(writePlanet, &timings)
+		}
+		e.stepMutex.Unlock()
+
+		// Nothing new to show until at least one step has run
+		if steps == 0 {
+			return
+		}
+		e.recordTimings(&timings, startTime)
+	} else {
+		e.stepMutex.Unlock()
+		e.injectQueuedPlumes(writePlanet)
+		step(writePlanet, dt*e.simSpeed, e.gpuCompute, &timings)
+		e.updateClimate(writePlanet, &timings)
+		e.recordTimings(&timings, startTime)
+	}
+
+	// Swap buffers for next frame
+	publishPlates(writePlanet)
+	e.SwapBuffers()
+	e.publishSnapshot()
+}
+
+// publishPlates analyzes the plates of a planet about to be swapped in for
+// reading, so the main thread can query them without racing physics
+func publishPlates(planet *core.VoxelPlanet) {
+	if vp, ok := planet.Physics.(*VoxelPhysics); ok && vp.plates != nil {
+		planet.Plates = vp.plates.Snapshot()
+	}
+}
+
+// recordTimings publishes the timings of a tick that began at start
+func (e *ThreadedPhysicsEngine) recordTimings(timings *PhysicsTimings, start time.Time) {
+	timings.Total = time.Since(start)
+	e.physicsFrameTime = timings.Total.Seconds()
+	e.timings.Store(timings)
+}
+
 // GetPhysicsFrameTime returns the time taken for the last physics update
 func (e *ThreadedPhysicsEngine) GetPhysicsFrameTime() float64 {
 	return e.physicsFrameTime
 }
 
+// GetTimings returns how long each subsystem took in the last physics
+// update, zero before the first one
+func (e *ThreadedPhysicsEngine) GetTimings() PhysicsTimings {
+	if timings := e.timings.Load(); timings != nil {
+		return *timings
+	}
+	return PhysicsTimings{}
+}
+
 // GetPhysicsUpdateInterval returns the fixed timestep interval for physics updates
 func (e *ThreadedPhysicsEngine) GetPhysicsUpdateInterval() float64 {
 	return 1.0 / e.physicsUpdateRate
@@ -148,12 +406,52 @@ func (e *ThreadedPhysicsEngine) GetPhysicsUpdateInterval() float64 {
 // deepCopyPlanet creates a deep copy of the planet structure
 func deepCopyPlanet(src *core.VoxelPlanet) *core.VoxelPlanet {
 	dst := &core.VoxelPlanet{
-		Shells:    make([]core.SphericalShell, len(src.Shells)),
-		Radius:    src.Radius,
-		Time:      src.Time,
-		MeshDirty: src.MeshDirty,
-		Physics:   src.Physics, // Physics state can be shared
+		Shells:      make([]core.SphericalShell, len(src.Shells)),
+		Radius:      src.Radius,
+		Flattening:  src.Flattening,
+		Time:        src.Time,
+		MeshDirty:   src.MeshDirty,
+		Physics:     src.Physics,     // Physics state can be shared
+		Seismic:     src.Seismic,     // One earthquake log across both buffers
+		PlateHues:   src.PlateHues,   // Kept by the shared plate manager
+		PlateTracks: src.PlateTracks, // Likewise
+		Plates:      src.Plates,      // Never modified once published
+
+		CoreBoundary:    src.CoreBoundary,
+		CoreTemperature: src.CoreTemperature,
+		CoreHeatFlux:    src.CoreHeatFlux,
+		SlabDip:         src.SlabDip,
+
+		MaxElevationRate: src.MaxElevationRate,
+		MaxPlates:        src.MaxPlates,
+		ReferencePlate:   src.ReferencePlate,
+
+		ConvectionForcing: src.ConvectionForcing,
+		MaxPlateVelocity:  src.MaxPlateVelocity,
+
+		GreenhouseStrength: src.GreenhouseStrength,
+
+		InitialRadiogenicHeat: src.InitialRadiogenicHeat,
+		HeatHalfLifeYears:     src.HeatHalfLifeYears,
+
+		RotationRate: src.RotationRate,
+		AxialTilt:    src.AxialTilt,
+
+		WaterBudget:     src.WaterBudget,
+		TotalRockVolume: src.TotalRockVolume,
+		SeaLevel:        src.SeaLevel,
+		SeaLevelForced:  src.SeaLevelForced,
+		SeaLevelTarget:  src.SeaLevelTarget,
+		SeaLevelRate:    src.SeaLevelRate,
+
+		PhysicsCheck: src.PhysicsCheck,
+		PhysicsFault: src.PhysicsFault,
+
+		WorkerThreads: src.WorkerThreads,
+
+		Plumes: append([]core.MantlePlume(nil), src.Plumes...),
 	}
+	dst.SetSeed(src.Seed())
 
 	// Deep copy each shell
 	for i, srcShell := range src.Shells {
@@ -161,6 +459,7 @@ func deepCopyPlanet(src *core.VoxelPlanet) *core.VoxelPlanet {
 		dstShell.InnerRadius = srcShell.InnerRadius
 		dstShell.OuterRadius = srcShell.OuterRadius
 		dstShell.LatBands = srcShell.LatBands
+		dstShell.Version = srcShell.Version
 		dstShell.LonCounts = make([]int, len(srcShell.LonCounts))
 		copy(dstShell.LonCounts, srcShell.LonCounts)
 
@@ -175,12 +474,36 @@ func deepCopyPlanet(src *core.VoxelPlanet) *core.VoxelPlanet {
 	return dst
 }
 
+// copyPlanetState copies voxel data and time from src into dst, along with
+// the water budget, so the buffers conserve one ocean between them
+// Both planets must share the same grid layout
+func copyPlanetState(dst, src *core.VoxelPlanet) {
+	dst.Time = src.Time
+	dst.WaterBudget = src.WaterBudget
+	dst.TotalRockVolume = src.TotalRockVolume
+	dst.SeaLevel = src.SeaLevel
+	dst.SeaLevelForced = src.SeaLevelForced
+	dst.SeaLevelTarget = src.SeaLevelTarget
+	dst.SeaLevelRate = src.SeaLevelRate
+	dst.ReferencePlate = src.ReferencePlate
+	dst.PhysicsFault = src.PhysicsFault
+	dst.Plates = src.Plates
+	dst.Plumes = append(dst.Plumes[:0], src.Plumes...)
+	for i := range src.Shells {
+		for j := range src.Shells[i].Voxels {
+			copy(dst.Shells[i].Voxels[j], src.Shells[i].Voxels[j])
+		}
+		dst.Shells[i].Version = src.Shells[i].Version
+	}
+}
+
 // ThreadedPhysicsInterface provides a simple interface for the main thread
 type ThreadedPhysicsInterface struct {
 	engine           *ThreadedPhysicsEngine
 	lastUpdateTime   time.Time
 	updateInterval   time.Duration
 	lastReportedTime float64
+	lastManualStep   int64
 }
 
 // NewThreadedPhysicsInterface creates a new interface to the physics engine
@@ -197,6 +520,15 @@ func NewThreadedPhysicsInterface(planet *core.VoxelPlanet, gpuCompute gpu.GPUCom
 
 // Update checks if new physics data is available
 func (i *ThreadedPhysicsInterface) Update() (*core.VoxelPlanet, bool) {
+	// While paused only manual steps produce new data
+	if i.engine.IsPaused() {
+		if steps := i.engine.ManualSteps(); steps != i.lastManualStep {
+			i.lastManualStep = steps
+			return i.engine.GetCurrentPlanet(), true
+		}
+		return nil, false
+	}
+
 	now := time.Now()
 	if now.Sub(i.lastUpdateTime) >= i.updateInterval {
 		i.lastUpdateTime = now
@@ -215,16 +547,90 @@ func (i *ThreadedPhysicsInterface) Stop() {
 	i.engine.Stop()
 }
 
+// GetCurrentPlanet returns the latest completed physics state
+func (i *ThreadedPhysicsInterface) GetCurrentPlanet() *core.VoxelPlanet {
+	return i.engine.GetCurrentPlanet()
+}
+
 // GetPhysicsFrameTime returns physics calculation time
 func (i *ThreadedPhysicsInterface) GetPhysicsFrameTime() float64 {
 	return i.engine.GetPhysicsFrameTime()
 }
 
+// GetTimings returns the per-subsystem time of the last physics update
+func (i *ThreadedPhysicsInterface) GetTimings() PhysicsTimings {
+	return i.engine.GetTimings()
+}
+
+// GetRecentSeismicEvents returns the earthquakes recorded after sinceTime
+// (simulation years), oldest first
+func (i *ThreadedPhysicsInterface) GetRecentSeismicEvents(sinceTime float64) []SeismicEvent {
+	return i.engine.GetRecentSeismicEvents(sinceTime)
+}
+
 // UpdateSimSpeed updates the simulation speed multiplier
 func (i *ThreadedPhysicsInterface) UpdateSimSpeed(speed float64) {
 	i.engine.UpdateSimSpeed(speed)
 }
 
+// SetFixedTimestep sets the simulation years per physics step (<= 0 disables)
+func (i *ThreadedPhysicsInterface) SetFixedTimestep(stepYears float64) {
+	i.engine.SetFixedTimestep(stepYears)
+}
+
+// SetFixedTick advances every physics tick by exactly seconds × simulation
+// speed, ignoring stalls (<= 0 measures ticks by the wall clock)
+func (i *ThreadedPhysicsInterface) SetFixedTick(seconds float64) {
+	i.engine.SetFixedTick(seconds)
+}
+
+// SetPaused stops or resumes time-driven physics
+func (i *ThreadedPhysicsInterface) SetPaused(paused bool) {
+	i.engine.SetPaused(paused)
+}
+
+// IsPaused reports whether physics is paused
+func (i *ThreadedPhysicsInterface) IsPaused() bool {
+	return i.engine.IsPaused()
+}
+
+// SetReferencePlate holds a plate still as the frame other plates move in
+// (0 = absolute frame)
+func (i *ThreadedPhysicsInterface) SetReferencePlate(plateID int) {
+	i.engine.SetReferencePlate(plateID)
+}
+
+// StepOnce advances a paused simulation by one physics step, then stays paused
+// Returns false if the simulation is not paused
+func (i *ThreadedPhysicsInterface) StepOnce() bool {
+	return i.engine.RequestStep(0)
+}
+
+// StepYears advances a paused simulation by years, then stays paused
+// Returns false if the simulation is not paused
+func (i *ThreadedPhysicsInterface) StepYears(years float64) bool {
+	if years <= 0 {
+		return false
+	}
+	return i.engine.RequestStep(years)
+}
+
+// Subscribe returns a channel receiving a read-only deep copy of the planet
+// after every physics step, see ThreadedPhysicsEngine.Subscribe
+func (i *ThreadedPhysicsInterface) Subscribe() <-chan *core.VoxelPlanet {
+	return i.engine.Subscribe()
+}
+
+// Unsubscribe closes a channel from Subscribe and stops its copies
+func (i *ThreadedPhysicsInterface) Unsubscribe(snapshots <-chan *core.VoxelPlanet) {
+	i.engine.Unsubscribe(snapshots)
+}
+
+// InjectPlume roots a mantle plume under latDeg/lonDeg from the next step on
+func (i *ThreadedPhysicsInterface) InjectPlume(latDeg, lonDeg, temperatureBoost float64) bool {
+	return i.engine.InjectPlume(latDeg, lonDeg, temperatureBoost)
+}
+
 // GetPhysicsUpdateInterval returns the fixed timestep interval for physics updates
 func (i *ThreadedPhysicsInterface) GetPhysicsUpdateInterval() float64 {
 	return i.engine.GetPhysicsUpdateInterval()
@@ -0,0 +1,241 @@
+package physics
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"worldgenerator/core"
+	"worldgenerator/simulation"
+)
+
+// waitForSteps polls until the engine has completed want manual steps
+func waitForSteps(t *testing.T, engine *ThreadedPhysicsEngine, want int64) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for engine.ManualSteps() < want {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for manual step %d", want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestPausedStepping checks a paused engine only advances on request and stays paused
+func TestPausedStepping(t *testing.T) {
+	planet := core.CreateVoxelPlanet(6371000.0, 4)
+	engine := NewThreadedPhysicsEngine(planet, nil, 100000.0)
+	engine.SetPaused(true)
+	engine.Start()
+	defer engine.Stop()
+
+	// Several ticks pass without advancing time
+	time.Sleep(3 * time.Duration(1000.0/engine.physicsUpdateRate) * time.Millisecond)
+	if year := engine.GetCurrentPlanet().Time; year != 0 {
+		t.Fatalf("paused engine advanced to year %.0f", year)
+	}
+
+	// One step is one normal tick of simulated time
+	if !engine.RequestStep(0) {
+		t.Fatal("step request rejected while paused")
+	}
+	waitForSteps(t, engine, 1)
+	oneStep := engine.stepYears()
+	if year := engine.GetCurrentPlanet().Time; year != oneStep {
+		t.Errorf("after one step: year %.0f, want %.0f", year, oneStep)
+	}
+
+	// Advancing by years lands exactly, with a fixed timestep too
+	engine.SetFixedTimestep(1000.0)
+	engine.RequestStep(2500.0)
+	waitForSteps(t, engine, 2)
+	if year := engine.GetCurrentPlanet().Time; year != oneStep+2500.0 {
+		t.Errorf("after stepping 2500 years: year %.0f, want %.0f", year, oneStep+2500.0)
+	}
+
+	// Still paused afterwards
+	time.Sleep(3 * time.Duration(1000.0/engine.physicsUpdateRate) * time.Millisecond)
+	if year := engine.GetCurrentPlanet().Time; year != oneStep+2500.0 {
+		t.Errorf("engine kept running after a step: year %.0f", year)
+	}
+
+	// Steps are refused while running freely
+	engine.SetPaused(false)
+	if engine.RequestStep(0) {
+		t.Error("step request accepted while running")
+	}
+
+	// Stopping early for shutdown leaves the deferred Stop harmless
+	engine.Stop()
+}
+
+// TestFixedTick checks a fixed tick advances exactly seconds × speed per tick
+// however long the ticks really took, and that paused ticks add nothing
+func TestFixedTick(t *testing.T) {
+	const speed, fixedDt, ticks = 100000.0, 0.1, 5
+	planet := core.CreateVoxelPlanet(6371000.0, 4)
+	engine := NewThreadedPhysicsEngine(planet, nil, speed)
+	engine.SetFixedTick(fixedDt)
+
+	// Drive ticks by hand with irregular gaps, one a multi-second stall
+	now := engine.lastPhysicsTime
+	gaps := []time.Duration{16 * time.Millisecond, 3 * time.Second, time.Millisecond, 250 * time.Millisecond, 40 * time.Millisecond}
+	for _, gap := range gaps {
+		now = now.Add(gap)
+		engine.tick(now)
+	}
+	want := ticks * fixedDt * speed
+	if got := engine.GetCurrentPlanet().Time; math.Abs(got-want) > 1e-6*want {
+		t.Errorf("after %d fixed ticks: year %.3f, want %.3f", ticks, got, want)
+	}
+
+	engine.SetPaused(true)
+	engine.tick(now.Add(time.Minute))
+	if got := engine.GetCurrentPlanet().Time; math.Abs(got-want) > 1e-6*want {
+		t.Errorf("paused tick moved year to %.3f, want %.3f", got, want)
+	}
+}
+
+// TestTimings checks a tick reports its subsystems' time, and that together
+// they fit inside the tick
+func TestTimings(t *testing.T) {
+	planet := core.CreateVoxelPlanet(6371000.0, 4)
+	engine := NewThreadedPhysicsEngine(planet, nil, 100000.0)
+	if got := engine.GetTimings(); got != (PhysicsTimings{}) {
+		t.Fatalf("timings before the first tick: %+v", got)
+	}
+
+	engine.SetFixedTick(0.1)
+	engine.tick(engine.lastPhysicsTime.Add(100 * time.Millisecond))
+
+	timings := engine.GetTimings()
+	if timings.Temperature <= 0 || timings.Convection <= 0 || timings.Advection <= 0 || timings.Mechanics <= 0 || timings.Other <= 0 {
+		t.Errorf("a subsystem went untimed: %+v", timings)
+	}
+	sum := timings.Temperature + timings.Convection + timings.Mechanics + timings.Plates + timings.Advection + timings.Other + timings.Climate
+	if sum > timings.Total {
+		t.Errorf("subsystems took %v, more than the %v tick", sum, timings.Total)
+	}
+	if got := timings.Total.Seconds(); got != engine.GetPhysicsFrameTime() {
+		t.Errorf("tick took %vs, GetPhysicsFrameTime says %vs", got, engine.GetPhysicsFrameTime())
+	}
+}
+
+// TestSubscribeSnapshots checks a subscriber gets a copy of each published
+// step that shares no voxels or plate colors with either buffer, and that
+// unsubscribing closes the channel and stops the copies
+func TestSubscribeSnapshots(t *testing.T) {
+	planet := core.CreateVoxelPlanet(6371000.0, 4)
+	engine := NewThreadedPhysicsEngine(planet, nil, 100000.0)
+	engine.SetFixedTick(0.1)
+	snapshots := engine.Subscribe()
+
+	now := engine.lastPhysicsTime.Add(100 * time.Millisecond)
+	engine.tick(now)
+	var snapshot *core.VoxelPlanet
+	select {
+	case snapshot = <-snapshots:
+	default:
+		t.Fatal("no snapshot after a physics step")
+	}
+	live := engine.GetCurrentPlanet()
+	if snapshot.Time != live.Time {
+		t.Errorf("snapshot at year %.0f, published planet at %.0f", snapshot.Time, live.Time)
+	}
+
+	for _, buffer := range []*core.VoxelPlanet{engine.planetA, engine.planetB} {
+		for shellIdx, shell := range buffer.Shells {
+			for latIdx, band := range shell.Voxels {
+				if &snapshot.Shells[shellIdx].Voxels[latIdx][0] == &band[0] {
+					t.Fatalf("snapshot shares shell %d band %d with the live planet", shellIdx, latIdx)
+				}
+			}
+		}
+	}
+	before := live.Shells[1].Voxels[0][0].Temperature
+	snapshot.Shells[1].Voxels[0][0].Temperature = before + 1000
+	if live.Shells[1].Voxels[0][0].Temperature != before {
+		t.Error("writing the snapshot changed the live planet")
+	}
+	snapshot.PlateHues[99] = 1
+	if _, ok := live.PlateHues[99]; ok {
+		t.Error("snapshot shares plate colors with the live planet")
+	}
+
+	// A consumer that falls behind finds only the newest step
+	engine.tick(now.Add(100 * time.Millisecond))
+	engine.tick(now.Add(200 * time.Millisecond))
+	if latest := <-snapshots; latest.Time != engine.GetCurrentPlanet().Time {
+		t.Errorf("waiting snapshot is from year %.0f, want the latest %.0f", latest.Time, engine.GetCurrentPlanet().Time)
+	}
+
+	engine.Unsubscribe(snapshots)
+	if _, open := <-snapshots; open {
+		t.Error("channel still open after unsubscribing")
+	}
+	engine.tick(now.Add(300 * time.Millisecond))
+	if len(engine.subscribers) != 0 {
+		t.Errorf("%d subscribers left after unsubscribing", len(engine.subscribers))
+	}
+}
+
+// TestPublishedPlates checks each step publishes the plate statistics and
+// boundaries of the planet swapped in for reading, that subscribers get the same analysis, and
+// that later steps publish afresh rather than rewriting what was published
+func TestPublishedPlates(t *testing.T) {
+	// Hemispheres moving apart identify as separate plates
+	planet := testPlanet(5)
+	surface := len(planet.Shells) - 2
+	for latIdx, band := range planet.Shells[surface].Voxels {
+		for lonIdx := range band {
+			_, lon := planet.VoxelLatLon(core.VoxelCoord{Shell: surface, Lat: latIdx, Lon: lonIdx})
+			band[lonIdx].VelEast = float32(math.Copysign(2e-6, lon))
+		}
+	}
+	engine := NewThreadedPhysicsEngine(planet, nil, 100000.0)
+	engine.SetFixedTick(0.1)
+	snapshots := engine.Subscribe()
+
+	now := engine.lastPhysicsTime.Add(100 * time.Millisecond)
+	engine.tick(now)
+	live := engine.GetCurrentPlanet()
+	published := simulation.PublishedPlates(live)
+	if published == nil {
+		t.Fatal("no plate analysis published with the step")
+	}
+	pm := live.Physics.(*VoxelPhysics).plates
+	if !reflect.DeepEqual(published.Stats, pm.GetPlateStats()) {
+		t.Errorf("published stats %+v, the plates have %+v", published.Stats, pm.GetPlateStats())
+	}
+	for _, plate := range pm.Plates {
+		if !published.HasPlate(plate.ID) {
+			t.Errorf("plate %d missing from the published plates", plate.ID)
+		}
+	}
+	if published.HasPlate(0) {
+		t.Error("published plates include plate 0")
+	}
+	want := pm.BoundaryRates()
+	if len(want) == 0 {
+		t.Fatal("no plate boundaries to publish")
+	}
+	if !reflect.DeepEqual(published.Boundaries, want) {
+		t.Errorf("published %d boundary segments, the plates have %d", len(published.Boundaries), len(want))
+	}
+	if snapshot := <-snapshots; simulation.PublishedPlates(snapshot) != published {
+		t.Error("subscriber's snapshot carries a different plate analysis")
+	}
+
+	kept := append([]simulation.BoundarySegment(nil), published.Boundaries...)
+	keptStats := append([]simulation.PlateStats(nil), published.Stats...)
+	for i := 1; i <= 4; i++ {
+		engine.tick(now.Add(time.Duration(i) * 100 * time.Millisecond))
+	}
+	if simulation.PublishedPlates(engine.GetCurrentPlanet()) == published {
+		t.Error("later steps published the same plate analysis")
+	}
+	if !reflect.DeepEqual(published.Boundaries, kept) || !reflect.DeepEqual(published.Stats, keptStats) {
+		t.Error("later steps rewrote a published plate analysis")
+	}
+}
@@ -81,18 +81,22 @@ func (vm *VoxelMechanics) updateYieldStrength() {
 	}
 }
 
-// updateStress calculates stress from velocity gradients
+// updateStress calculates stress from velocity gradients, marking the shells
+// whose stress changed
 func (vm *VoxelMechanics) updateStress(dt float64) {
 	for shellIdx := range vm.planet.Shells {
 		shell := &vm.planet.Shells[shellIdx]
 
+		changed := false
 		for latIdx := range shell.Voxels {
 			for lonIdx := range shell.Voxels[latIdx] {
 				voxel := &shell.Voxels[latIdx][lonIdx]
+				before := voxel.Stress
 
 				// Skip fluids
 				if voxel.Type == core.MatAir || voxel.Type == core.MatWater || voxel.Type == core.MatMagma {
 					voxel.Stress = 0
+					changed = changed || before != 0
 					continue
 				}
 
@@ -109,8 +113,12 @@ func (vm *VoxelMechanics) updateStress(dt float64) {
 				// Maxwell relaxation: stress decays over time
 				relaxTime := viscosity / float64(voxel.YieldStrength)
 				voxel.Stress *= float32(math.Exp(-dt / relaxTime))
+				changed = changed || voxel.Stress != before
 			}
 		}
+		if changed {
+			vm.planet.MarkShellDirty(shellIdx)
+		}
 	}
 }
 
@@ -124,7 +132,7 @@ func (vm *VoxelMechanics) calculateStrainRate(shellIdx, latIdx, lonIdx int) floa
 
 	// Radial strain
 	if shellIdx < len(vm.planet.Shells)-1 {
-		outerVoxel := vm.physics.getRadialNeighbor(shellIdx, shellIdx+1, latIdx, lonIdx)
+		outerVoxel := vm.physics.getContainingNeighbor(shellIdx, shellIdx+1, latIdx, lonIdx)
 		if outerVoxel != nil {
 			dr := shell.OuterRadius - shell.InnerRadius
 			dVr := float64(outerVoxel.VelR - voxel.VelR)
@@ -179,11 +187,13 @@ func (vm *VoxelMechanics) getEffectiveViscosity(voxel *core.VoxelMaterial) float
 	return viscosity
 }
 
-// checkFracturing creates faults when stress exceeds strength
+// checkFracturing creates faults when stress exceeds strength, marking the
+// shells where any voxel released its stress
 func (vm *VoxelMechanics) checkFracturing() {
 	for shellIdx := range vm.planet.Shells {
 		shell := &vm.planet.Shells[shellIdx]
 
+		changed := false
 		for latIdx := range shell.Voxels {
 			for lonIdx := range shell.Voxels[latIdx] {
 				voxel := &shell.Voxels[latIdx][lonIdx]
@@ -197,6 +207,7 @@ func (vm *VoxelMechanics) checkFracturing() {
 				if voxel.Stress > voxel.YieldStrength {
 					// Fracture! Release stress
 					voxel.Stress = 0
+					changed = true
 
 					// Mark as fractured (could trigger earthquakes, etc.)
 					voxel.IsFractured = true
@@ -212,6 +223,9 @@ func (vm *VoxelMechanics) checkFracturing() {
 				}
 			}
 		}
+		if changed {
+			vm.planet.MarkShellDirty(shellIdx)
+		}
 	}
 }
 
@@ -332,8 +346,10 @@ func (vm *VoxelMechanics) ApplyRidgePush(dt float64) {
 
 			// Apply force in direction away from ridge
 			// This is simplified - in reality depends on ridge orientation
+			// Polar bands can hold fewer voxels than the push reaches
+			lonCount := len(shell.Voxels[boundary.LatIdx])
 			for dLon := -5; dLon <= 5; dLon++ {
-				targetLon := (boundary.LonIdx + dLon + len(shell.Voxels[boundary.LatIdx])) % len(shell.Voxels[boundary.LatIdx])
+				targetLon := ((boundary.LonIdx+dLon)%lonCount + lonCount) % lonCount
 				targetVoxel := &shell.Voxels[boundary.LatIdx][targetLon]
 
 				if targetVoxel.IsBrittle {
@@ -373,17 +389,49 @@ func (vm *VoxelMechanics) UpdateTransformFaults(dt float64) {
 			voxel.Stress += shearStress
 
 			// Check for strike-slip events (earthquakes)
-			if voxel.Stress > voxel.YieldStrength {
-				// Release stress in sudden slip
-				voxel.Stress = 0
-				voxel.IsFractured = true
-
-				// Could trigger earthquake event here
-			}
+			vm.releaseStress(surfaceShell, boundary.LatIdx, boundary.LonIdx)
 		}
 	}
 }
 
+// releaseStress slips a boundary fault loaded past its yield strength in one
+// sudden event, logging the earthquake
+func (vm *VoxelMechanics) releaseStress(shellIdx, latIdx, lonIdx int) {
+	shell := &vm.planet.Shells[shellIdx]
+	voxel := &shell.Voxels[latIdx][lonIdx]
+	if voxel.Stress <= voxel.YieldStrength {
+		return
+	}
+
+	released := float64(voxel.Stress)
+	voxel.Stress = 0
+	voxel.IsFractured = true
+	vm.recordEarthquake(shellIdx, latIdx, lonIdx, released)
+}
+
+// recordEarthquake logs a rupture of stress (Pa) across a voxel
+func (vm *VoxelMechanics) recordEarthquake(shellIdx, latIdx, lonIdx int, stress float64) {
+	lat, lon := vm.planet.VoxelLatLon(core.VoxelCoord{Shell: shellIdx, Lat: latIdx, Lon: lonIdx})
+	seismicLog(vm.planet).Record(SeismicEvent{
+		Lat:       lat,
+		Lon:       lon,
+		Magnitude: momentMagnitude(stress, vm.planet.VoxelArea(&vm.planet.Shells[shellIdx], latIdx)),
+		Time:      vm.planet.Time,
+	})
+}
+
+// ruptureOnLoading logs an earthquake at a collision voxel whose stress this
+// step loaded past yield. Collisions keep their stress, which builds the
+// mountain belt the stress view shows, so each side ruptures once as it
+// crosses yield instead of slipping back to zero like a transform fault
+func (vm *VoxelMechanics) ruptureOnLoading(shellIdx, latIdx, lonIdx int, before float32) {
+	voxel := &vm.planet.Shells[shellIdx].Voxels[latIdx][lonIdx]
+	if before <= voxel.YieldStrength && voxel.Stress > voxel.YieldStrength {
+		voxel.IsFractured = true
+		vm.recordEarthquake(shellIdx, latIdx, lonIdx, float64(voxel.Stress))
+	}
+}
+
 // abs returns absolute value of float32
 func abs(x float32) float32 {
 	if x < 0 {
@@ -425,14 +473,23 @@ func (vm *VoxelMechanics) UpdateCollisions(dt float64) {
 			eastLon := (lonIdx + 1) % len(shell.Voxels[latIdx])
 			eastVoxel := &shell.Voxels[latIdx][eastLon]
 
+			// Within one plate velocities differ only by its rigid rotation
+			if eastVoxel.PlateID == voxel.PlateID {
+				continue
+			}
+
 			// Continental-continental collision
 			if eastVoxel.Type == core.MatGranite {
 				velDiff := voxel.VelEast - eastVoxel.VelEast
 
 				// Convergent motion between continents
 				if velDiff > 1e-6 {
-					// Apply collision effects
+					// Apply collision effects, logging either side that
+					// ruptures under the load
+					westBefore, eastBefore := voxel.Stress, eastVoxel.Stress
 					vm.applyCollisionEffects(shell, latIdx, lonIdx, eastLon, dt)
+					vm.ruptureOnLoading(surfaceShell, latIdx, lonIdx, westBefore)
+					vm.ruptureOnLoading(surfaceShell, latIdx, eastLon, eastBefore)
 				}
 			}
 
@@ -498,12 +555,10 @@ func (vm *VoxelMechanics) applySubductionEffects(shell *core.SphericalShell, lat
 	// Oceanic crust subducts
 	oceanicVoxel.VelR = float32(math.Min(float64(oceanicVoxel.VelR)-0.00001*dt, -0.00001*dt))
 
-	// Continental margin uplifts (volcanic arc)
+	// Continental margin uplifts (forearc)
+	// The volcanic arc itself forms inboard, see UpdateArcVolcanism
 	continentalVoxel.VelR += float32(0.000005 * dt)
 
-	// Heat from subducting slab causes melting
-	continentalVoxel.Temperature += 10 * float32(dt)
-
 	// Create back-arc extension
 	continentalVoxel.VelEast += float32(0.000001 * dt)
 }
@@ -532,7 +587,7 @@ func (vm *VoxelMechanics) UpdateContinentalBreakup(dt float64) {
 
 			// 1. Check if over a mantle plume (hot spot)
 			if shellIdx := surfaceShell - 3; shellIdx >= 0 {
-				deeperVoxel := vm.physics.getRadialNeighbor(surfaceShell, shellIdx, latIdx, lonIdx)
+				deeperVoxel := vm.physics.getContainingNeighbor(surfaceShell, shellIdx, latIdx, lonIdx)
 				if deeperVoxel != nil && deeperVoxel.Temperature > 3000 {
 					// Hot mantle below - potential rifting
 					vm.applyRiftingEffects(shell, latIdx, lonIdx, dt, "plume")
@@ -543,7 +598,7 @@ func (vm *VoxelMechanics) UpdateContinentalBreakup(dt float64) {
 			eastLon := (lonIdx + 1) % len(shell.Voxels[latIdx])
 			eastVoxel := &shell.Voxels[latIdx][eastLon]
 
-			if eastVoxel.Type == core.MatGranite {
+			if eastVoxel.Type == core.MatGranite && eastVoxel.PlateID != voxel.PlateID {
 				velDiff := eastVoxel.VelEast - voxel.VelEast
 
 				// Divergent motion between continental blocks
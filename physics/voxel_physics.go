@@ -106,6 +106,9 @@ func (vp *VoxelPhysics) UpdatePhysics(deltaTime float64) {
 			fmt.Printf("GPU advection error: %v\n", err)
 		}
 
+		// The kernels rewrote every shell
+		vp.planet.MarkAllShellsDirty()
+
 		// Download results
 		// TODO: Need to export downloadPlanetData method or use a different approach
 		// if err := vp.gpuCompute.downloadPlanetData(vp.planet); err != nil {
@@ -114,13 +117,10 @@ func (vp *VoxelPhysics) UpdatePhysics(deltaTime float64) {
 		//     return
 		// }
 	} else {
-		// CPU fallback - simplified for demo
+		// CPU fallback - simplified for demo, marking the shells it moves
 		vp.advection.AdvectMaterial(deltaTime)
 	}
 
-	// Mark surface as needing remesh
-	vp.planet.MeshDirty = true
-
 	// Debug: Print average temperatures periodically
 	if vp.planet.Time-vp.lastPrintTime > 1000.0 {
 		vp.lastPrintTime = vp.planet.Time
@@ -210,7 +210,7 @@ func (vp *VoxelPhysics) updateTemperature(dt float64) {
 				// Radial neighbors (up/down between shells)
 				if shellIdx > 0 {
 					// Inner neighbor
-					innerVoxel := vp.getRadialNeighbor(shellIdx, shellIdx-1, latIdx, lonIdx)
+					innerVoxel := vp.getContainingNeighbor(shellIdx, shellIdx-1, latIdx, lonIdx)
 					if innerVoxel != nil && innerVoxel.Type != core.MatAir {
 						tempSum += float64(innerVoxel.Temperature)
 						neighborCount++
@@ -218,7 +218,7 @@ func (vp *VoxelPhysics) updateTemperature(dt float64) {
 				}
 				if shellIdx < len(vp.planet.Shells)-1 {
 					// Outer neighbor
-					outerVoxel := vp.getRadialNeighbor(shellIdx, shellIdx+1, latIdx, lonIdx)
+					outerVoxel := vp.getContainingNeighbor(shellIdx, shellIdx+1, latIdx, lonIdx)
 					if outerVoxel != nil && outerVoxel.Type != core.MatAir {
 						tempSum += float64(outerVoxel.Temperature)
 						neighborCount++
@@ -254,16 +254,20 @@ func (vp *VoxelPhysics) updateTemperature(dt float64) {
 				dTemp := alpha * (avgNeighborTemp - float64(voxel.Temperature)) * dt / (dr * dr)
 
 				// Internal heat generation (radioactive decay)
-				if shellIdx < len(vp.planet.Shells)/2 {
+				if shellIdx < len(vp.planet.Shells)/2 && usesLegacyDeepHeating(vp.planet) {
 					// More heating in deeper layers
-					internalHeat := 1e-9 * dt // Simplified radioactive heating
-					dTemp += internalHeat
+					dTemp += vp.planet.RadiogenicHeat() * dt
 				}
 
 				newTemps[latIdx][lonIdx] = voxel.Temperature + float32(dTemp)
 			}
 		}
 
+		// Apply core-mantle boundary condition to innermost shell
+		if shellIdx == 0 {
+			applyCoreBoundary(vp.planet, newTemps, dt)
+		}
+
 		// Apply surface heating/cooling for outermost shell
 		if shellIdx == len(vp.planet.Shells)-1 {
 			vp.applySurfaceHeatExchange(shell, newTemps, dt)
@@ -345,7 +349,7 @@ func (vp *VoxelPhysics) updatePressure() {
 					}
 				} else {
 					// Pressure from overlying shell
-					outerVoxel := vp.getRadialNeighbor(shellIdx, shellIdx+1, latIdx, lonIdx)
+					outerVoxel := vp.getContainingNeighbor(shellIdx, shellIdx+1, latIdx, lonIdx)
 					if outerVoxel != nil {
 						// Add weight of overlying material
 						dr := shell.OuterRadius - shell.InnerRadius
@@ -412,36 +416,52 @@ func (vp *VoxelPhysics) updatePhases() {
 	}
 }
 
-// getRadialNeighbor finds the corresponding voxel in an adjacent shell
-func (vp *VoxelPhysics) getRadialNeighbor(sourceShellIdx, targetShellIdx, latIdx, lonIdx int) *core.VoxelMaterial {
-	if targetShellIdx < 0 || targetShellIdx >= len(vp.planet.Shells) {
-		return nil
-	}
-	if sourceShellIdx < 0 || sourceShellIdx >= len(vp.planet.Shells) {
+// getContainingNeighbor finds the voxel of an adjacent shell whose cell
+// contains this voxel's center, the radial neighbor gpu.NeighborIndices gives
+// the GPU kernels
+func (vp *VoxelPhysics) getContainingNeighbor(sourceShellIdx, targetShellIdx, latIdx, lonIdx int) *core.VoxelMaterial {
+	return containingVoxel(vp.planet, sourceShellIdx, targetShellIdx, latIdx, lonIdx)
+}
+
+// containingVoxel returns the voxel of another shell whose cell contains the
+// center of a voxel, so shells of different resolution line up by position.
+// It returns nil when either shell or the voxel doesn't exist
+func containingVoxel(planet *core.VoxelPlanet, sourceShellIdx, targetShellIdx, latIdx, lonIdx int) *core.VoxelMaterial {
+	targetLat, targetLon, ok := containingIndex(planet, sourceShellIdx, targetShellIdx, latIdx, lonIdx)
+	if !ok {
 		return nil
 	}
+	return &planet.Shells[targetShellIdx].Voxels[targetLat][targetLon]
+}
 
-	sourceShell := &vp.planet.Shells[sourceShellIdx]
-	targetShell := &vp.planet.Shells[targetShellIdx]
-
-	// Map latitude index based on shell resolutions
-	targetLat := latIdx * targetShell.LatBands / sourceShell.LatBands
-	if targetLat >= targetShell.LatBands {
-		targetLat = targetShell.LatBands - 1
+// containingIndex is containingVoxel's band and longitude index in the
+// target shell
+func containingIndex(planet *core.VoxelPlanet, sourceShellIdx, targetShellIdx, latIdx, lonIdx int) (int, int, bool) {
+	if targetShellIdx < 0 || targetShellIdx >= len(planet.Shells) {
+		return -1, -1, false
+	}
+	if sourceShellIdx < 0 || sourceShellIdx >= len(planet.Shells) {
+		return -1, -1, false
 	}
 
-	// Map longitude accounting for different counts per latitude
-	if latIdx >= len(sourceShell.Voxels) {
-		return nil
+	sourceShell := &planet.Shells[sourceShellIdx]
+	targetShell := &planet.Shells[targetShellIdx]
+	if latIdx < 0 || latIdx >= len(sourceShell.Voxels) || len(targetShell.Voxels) == 0 {
+		return -1, -1, false
 	}
 	sourceLonCount := len(sourceShell.Voxels[latIdx])
-	targetLonCount := len(targetShell.Voxels[targetLat])
-	targetLon := lonIdx * targetLonCount / sourceLonCount
-	if targetLon >= targetLonCount {
-		targetLon = targetLonCount - 1
+	if lonIdx < 0 || lonIdx >= sourceLonCount {
+		return -1, -1, false
 	}
 
-	return &targetShell.Voxels[targetLat][targetLon]
+	// Bands sit at their GetLatitudeForBand latitudes, voxels at the centers
+	// of their longitude cells, as VoxelAtGeographic finds them
+	targetLat := core.GetBandForLatitude(core.GetLatitudeForBand(latIdx, sourceShell.LatBands), targetShell.LatBands)
+	targetLonCount := len(targetShell.Voxels[targetLat])
+	if targetLonCount == 0 {
+		return -1, -1, false
+	}
+	return targetLat, min((2*lonIdx+1)*targetLonCount/(2*sourceLonCount), targetLonCount-1), true
 }
 
 // GetAverageTemperature returns the average temperature at a given depth
@@ -449,8 +469,11 @@ func (vp *VoxelPhysics) GetAverageTemperature(shellIdx int) float32 {
 	if shellIdx < 0 || shellIdx >= len(vp.planet.Shells) {
 		return 0
 	}
+	return float32(averageTemperature(&vp.planet.Shells[shellIdx]))
+}
 
-	shell := &vp.planet.Shells[shellIdx]
+// averageTemperature is the mean temperature of every voxel in a shell
+func averageTemperature(shell *core.SphericalShell) float64 {
 	sum := float64(0)
 	count := 0
 
@@ -462,7 +485,7 @@ func (vp *VoxelPhysics) GetAverageTemperature(shellIdx int) float32 {
 	}
 
 	if count > 0 {
-		return float32(sum / float64(count))
+		return sum / float64(count)
 	}
 	return 0
 }
@@ -56,9 +56,9 @@ func ApplyAcceleratedPlateMotion(planet *core.VoxelPlanet, dt float64, params *A
 				}
 
 				// Set accelerated velocities based on material type
-				// Base velocity: 3 cm/year = 1e-9 m/s
-				// With 1M multiplier: 30 km/year = 1e-3 m/s
-				baseVel := float32(3e-9) // 3 cm/year
+				// Base velocity: 3 cm/year = 0.03 m/year
+				// With 1M multiplier: 30 km/year
+				baseVel := float32(0.03) // 3 cm/year
 				if voxel.Type == core.MatGranite {
 					// Continental plates move a bit slower
 					voxel.VelEast = baseVel * float32(params.PlateVelocityMultiplier) * (0.5 + 0.5*float32(lonIdx%3))
@@ -2,13 +2,34 @@ package physics
 
 import (
 	"runtime"
+	"time"
 	"worldgenerator/core"
 	"worldgenerator/gpu"
 )
 
+// StepCPU advances the planet by one deterministic physics step of dt years
+// Every process runs in order on the calling goroutine with no GPU, so the
+// same planet and dt always produce the same result - use it from tests and tools
+// A planet halted by its physics check doesn't step
+func StepCPU(planet *core.VoxelPlanet, dt float64) {
+	if planet.Halted() {
+		return
+	}
+	UpdateVoxelPhysicsCPU(planet, dt)
+	planet.Time += dt
+}
+
 // UpdateVoxelPhysicsCPU runs the complete physics simulation on CPU
 // This is used on Windows/Linux where Metal is not available
+// It does not advance planet.Time; see StepCPU
 func UpdateVoxelPhysicsCPU(planet *core.VoxelPlanet, dt float64) {
+	updateVoxelPhysics(planet, dt, nil, &PhysicsTimings{})
+}
+
+// updateVoxelPhysics runs the CPU physics step, handing temperature diffusion,
+// convection and advection to the backend when it can run them and falling
+// back to the CPU if it fails. Each subsystem's time is added to timings
+func updateVoxelPhysics(planet *core.VoxelPlanet, dt float64, backend gpu.GPUCompute, timings *PhysicsTimings) {
 	// TODO: Properly integrate physics system with VoxelPlanet
 	// For now, create a new physics system each time
 	var physics interface{}
@@ -19,68 +40,146 @@ func UpdateVoxelPhysicsCPU(planet *core.VoxelPlanet, dt float64) {
 		physics = planet.Physics
 	}
 
-	// Add timing
-	// start := time.Now()
+	// Whatever the named subsystems don't account for is Other
+	stepStart := time.Now()
+	var named time.Duration
+	timed := func(d *time.Duration, run func()) {
+		start := time.Now()
+		run()
+		elapsed := time.Since(start)
+		*d += elapsed
+		named += elapsed
+	}
 
 	// 1. Temperature diffusion and heat flow
-	updateTemperatureCPU(planet, dt)
+	timed(&timings.Temperature, func() {
+		if temperature, ok := backend.(gpu.TemperatureStepper); !ok || temperature.StepTemperature(planet, float32(dt)) != nil {
+			updateTemperatureCPU(planet, dt)
+		} else {
+			// The kernel rewrote every shell
+			planet.MarkAllShellsDirty()
+			applyCoreBoundaryInPlace(planet, dt)
+		}
+	})
+	checkPhase(planet, "temperature")
+
+	// Atmosphere sets the surface boundary temperature
+	UpdateAtmosphere(planet, dt)
+	checkPhase(planet, "atmosphere")
 
 	// 2. Pressure calculation from overlying material
 	updatePressureCPU(planet, dt)
+	checkPhase(planet, "pressure")
 
 	// 3. Phase transitions (melting/solidification)
 	updatePhaseTransitionsCPU(planet, dt)
+	updateEclogiteTransition(planet)
+	checkPhase(planet, "phase transitions")
 
 	// Type assert physics to VoxelPhysics
 	if vp, ok := physics.(*VoxelPhysics); ok {
 		// 4. Material properties and mechanics
-		if vp.mechanics != nil {
-			vp.mechanics.UpdateMechanics(dt)
-		}
+		timed(&timings.Mechanics, func() {
+			if vp.mechanics != nil {
+				vp.mechanics.UpdateMechanics(dt)
+			}
+		})
+		checkPhase(planet, "mechanics")
 
 		// 5. Mantle convection
-		if vp.advection != nil {
-			// Apply convection velocities
-			vp.advection.UpdateConvection(dt)
-		}
+		timed(&timings.Convection, func() {
+			if convection, ok := backend.(gpu.ConvectionStepper); !ok || convection.StepConvection(planet, float32(dt)) != nil {
+				// Apply convection velocities, only the radial ones no
+				// consumer of the shells reads
+				if vp.advection != nil {
+					vp.advection.UpdateConvection(dt)
+				}
+			} else {
+				// The kernel rewrote the velocities of every shell below the air
+				for shellIdx := 0; shellIdx < len(planet.Shells)-1; shellIdx++ {
+					planet.MarkShellDirty(shellIdx)
+				}
+			}
 
-		// 6. Plate identification and motion
-		if vp.plates != nil {
-			// Re-identify plates periodically (every ~1000 years)
-			// Only re-identify plates occasionally (every 10M years)
-			if int(planet.Time)%10000000 == 0 {
-				vp.plates.IdentifyPlates()
+			// Injected plumes stay hot and rising whatever the convection did
+			if vp.advection != nil {
+				vp.advection.UpdatePlumes(dt)
 			}
+		})
+		checkPhase(planet, "convection")
 
-			// Update plate-scale motion
-			vp.plates.UpdatePlateMotion(dt)
-		}
+		// 6. Plate identification and motion
+		timed(&timings.Plates, func() {
+			if vp.plates != nil {
+				// Re-identify plates periodically (every ~1000 years)
+				// Only re-identify plates occasionally (every 10M years)
+				if int(planet.Time)%10000000 == 0 {
+					vp.plates.IdentifyPlates()
+				}
+
+				// Update plate-scale motion
+				vp.plates.UpdatePlateMotion(dt)
+
+				// Split rifted plates and weld sutured ones
+				vp.plates.UpdatePlateTopology(dt)
+			}
+		})
+		checkPhase(planet, "plate motion")
 
 		// 7. Local plate boundary processes
-		if vp.mechanics != nil {
-			// These now act on boundary voxels identified by plate system
-			vp.mechanics.ApplyRidgePush(dt)
-			vp.mechanics.UpdateTransformFaults(dt)
-			vp.mechanics.UpdateCollisions(dt)
-			vp.mechanics.UpdateContinentalBreakup(dt)
-		}
+		timed(&timings.Mechanics, func() {
+			if vp.mechanics != nil {
+				// These now act on boundary voxels identified by plate system
+				vp.mechanics.ApplyRidgePush(dt)
+				vp.mechanics.UpdateTransformFaults(dt)
+				vp.mechanics.UpdateCollisions(dt)
+				vp.mechanics.UpdateContinentalBreakup(dt)
+			}
+		})
+		timed(&timings.Advection, func() {
+			if vp.advection != nil {
+				vp.advection.UpdateArcVolcanism(dt)
+			}
+		})
+		checkPhase(planet, "boundary processes")
 
 		// 8. Material advection (movement)
-		if vp.advection != nil {
-			vp.advection.AdvectMaterial(dt)
-		}
+		timed(&timings.Advection, func() {
+			if advection, ok := backend.(gpu.AdvectionStepper); !ok || advection.StepAdvection(planet, float32(dt)) != nil {
+				if vp.advection != nil {
+					vp.advection.AdvectMaterial(dt)
+				}
+			}
+		})
+		checkPhase(planet, "advection")
+
+		// Ocean floor deepens as it ages and cools
+		updateSeafloorSubsidence(planet, dt)
+		checkPhase(planet, "seafloor subsidence")
 	}
 
 	// 9. Surface processes (simplified for now)
 	updateSurfaceProcessesCPU(planet, dt)
+	checkPhase(planet, "surface processes")
 
 	// 10. Update material age
 	updateAgeCPU(planet, dt)
+	checkPhase(planet, "age")
+
+	// The atmosphere, plates, boundary and surface processes all write the
+	// surface and the air above it; the passes below the surface marked the
+	// shells they changed, and pressure and age aren't drawn
+	if len(planet.Shells) >= 2 {
+		planet.MarkShellDirty(len(planet.Shells) - 2)
+		planet.MarkShellDirty(len(planet.Shells) - 1)
+	}
+	timings.Other += time.Since(stepStart) - named
 }
 
 // updateTemperatureCPU handles heat diffusion
 func updateTemperatureCPU(planet *core.VoxelPlanet, dt float64) {
 	dtFloat := float32(dt)
+	radioHeat := float32(planet.RadiogenicHeat() * dt) // Deep heating this step
 
 	// Create temporary buffer for new temperatures
 	tempBuffer := make([][][]float32, len(planet.Shells))
@@ -91,9 +190,11 @@ func updateTemperatureCPU(planet *core.VoxelPlanet, dt float64) {
 		}
 	}
 
-	// Heat diffusion through shells
+	// Heat diffusion through shells, each band reading the old temperatures
+	// and writing only its own row of the buffer
 	for shellIdx, shell := range planet.Shells {
-		for latIdx, latVoxels := range shell.Voxels {
+		forEachBand(planet.WorkerThreads, len(shell.Voxels), func(latIdx int) {
+			latVoxels := shell.Voxels[latIdx]
 			for lonIdx, voxel := range latVoxels {
 				// Skip air
 				if voxel.Type == core.MatAir {
@@ -101,9 +202,8 @@ func updateTemperatureCPU(planet *core.VoxelPlanet, dt float64) {
 					continue
 				}
 
-				// Get material properties
-				props := core.MaterialProperties[voxel.Type]
-				alpha := props.ThermalConductivity / (props.DefaultDensity * props.SpecificHeat)
+				// Per-material diffusivity - oceans respond slower than rock
+				alpha := core.MaterialDiffusivity(voxel.Type)
 
 				// Calculate heat flow from neighbors
 				heatFlow := float32(0.0)
@@ -113,8 +213,7 @@ func updateTemperatureCPU(planet *core.VoxelPlanet, dt float64) {
 				if shellIdx > 0 {
 					// Inner neighbor
 					innerShell := &planet.Shells[shellIdx-1]
-					if latIdx < len(innerShell.Voxels) && lonIdx < len(innerShell.Voxels[latIdx]) {
-						innerVoxel := &innerShell.Voxels[latIdx][lonIdx]
+					if innerVoxel := containingVoxel(planet, shellIdx, shellIdx-1, latIdx, lonIdx); innerVoxel != nil {
 						dr := shell.InnerRadius - innerShell.OuterRadius
 						if dr > 0 {
 							dT := innerVoxel.Temperature - voxel.Temperature
@@ -127,8 +226,7 @@ func updateTemperatureCPU(planet *core.VoxelPlanet, dt float64) {
 				if shellIdx < len(planet.Shells)-1 {
 					// Outer neighbor
 					outerShell := &planet.Shells[shellIdx+1]
-					if latIdx < len(outerShell.Voxels) && lonIdx < len(outerShell.Voxels[latIdx]) {
-						outerVoxel := &outerShell.Voxels[latIdx][lonIdx]
+					if outerVoxel := containingVoxel(planet, shellIdx, shellIdx+1, latIdx, lonIdx); outerVoxel != nil {
 						dr := outerShell.InnerRadius - shell.OuterRadius
 						if dr > 0 {
 							dT := outerVoxel.Temperature - voxel.Temperature
@@ -166,35 +264,33 @@ func updateTemperatureCPU(planet *core.VoxelPlanet, dt float64) {
 				}
 
 				// Add radioactive heating in deep shells
-				if shellIdx < 5 { // Deep mantle/core
-					radioHeat := float32(1e-12) * dtFloat * 1e6 // Small heating rate
+				if shellIdx < 5 && usesLegacyDeepHeating(planet) { // Deep mantle/core
 					tempBuffer[shellIdx][latIdx][lonIdx] += radioHeat
 				}
 			}
-		}
+		})
 	}
 
-	// Apply surface boundary conditions
+	// Apply core-mantle boundary condition
 	if len(planet.Shells) > 0 {
-		surfaceShell := len(planet.Shells) - 1
-		for latIdx, latVoxels := range planet.Shells[surfaceShell].Voxels {
-			lat := core.GetLatitudeForBand(latIdx, planet.Shells[surfaceShell].LatBands)
-
-			for lonIdx := range latVoxels {
-				// Simple surface temperature based on latitude
-				surfaceTemp := float32(288 - 50*abs(float32(lat))/90.0) // Equator ~288K, poles ~238K
-				tempBuffer[surfaceShell][latIdx][lonIdx] = surfaceTemp
-			}
-		}
+		applyCoreBoundary(planet, tempBuffer[0], dt)
 	}
 
-	// Copy back to planet
+	// Copy back to planet, marking the shells whose temperatures moved
 	for shellIdx, shell := range planet.Shells {
+		changed := false
 		for latIdx, latVoxels := range shell.Voxels {
 			for lonIdx := range latVoxels {
-				planet.Shells[shellIdx].Voxels[latIdx][lonIdx].Temperature = tempBuffer[shellIdx][latIdx][lonIdx]
+				voxel := &latVoxels[lonIdx]
+				if voxel.Temperature != tempBuffer[shellIdx][latIdx][lonIdx] {
+					voxel.Temperature = tempBuffer[shellIdx][latIdx][lonIdx]
+					changed = true
+				}
 			}
 		}
+		if changed {
+			planet.MarkShellDirty(shellIdx)
+		}
 	}
 }
 
@@ -214,9 +310,7 @@ func updatePressureCPU(planet *core.VoxelPlanet, dt float64) {
 				} else {
 					// Get pressure from shell above
 					outerShell := &planet.Shells[shellIdx+1]
-					if latIdx < len(outerShell.Voxels) && lonIdx < len(outerShell.Voxels[latIdx]) {
-						outerVoxel := &outerShell.Voxels[latIdx][lonIdx]
-
+					if outerVoxel := containingVoxel(planet, shellIdx, shellIdx+1, latIdx, lonIdx); outerVoxel != nil {
 						// Add weight of overlying material
 						dr := outerShell.InnerRadius - shell.OuterRadius
 						g := 9.8 // Simplified constant gravity
@@ -230,9 +324,11 @@ func updatePressureCPU(planet *core.VoxelPlanet, dt float64) {
 	}
 }
 
-// updatePhaseTransitionsCPU handles melting and solidification
+// updatePhaseTransitionsCPU handles melting and solidification, marking the
+// shells where any voxel changed phase
 func updatePhaseTransitionsCPU(planet *core.VoxelPlanet, dt float64) {
-	for _, shell := range planet.Shells {
+	for shellIdx, shell := range planet.Shells {
+		changed := false
 		for latIdx, latVoxels := range shell.Voxels {
 			for lonIdx := range latVoxels {
 				voxel := &shell.Voxels[latIdx][lonIdx]
@@ -252,6 +348,7 @@ func updatePhaseTransitionsCPU(planet *core.VoxelPlanet, dt float64) {
 						// Convert to magma
 						voxel.Type = core.MatMagma
 						voxel.Density = core.MaterialProperties[core.MatMagma].DefaultDensity
+						changed = true
 					}
 				}
 
@@ -263,10 +360,14 @@ func updatePhaseTransitionsCPU(planet *core.VoxelPlanet, dt float64) {
 						voxel.Type = core.MatBasalt
 						voxel.Density = core.MaterialProperties[core.MatBasalt].DefaultDensity
 						voxel.Age = 0 // New rock
+						changed = true
 					}
 				}
 			}
 		}
+		if changed {
+			planet.MarkShellDirty(shellIdx)
+		}
 	}
 }
 
@@ -276,6 +377,13 @@ func updateSurfaceProcessesCPU(planet *core.VoxelPlanet, dt float64) {
 		return
 	}
 
+	// Rain falls where the wind drives moist air up slopes, and wears them down
+	UpdatePrecipitation(planet)
+	erodeSurface(planet, dt)
+
+	// Snow that outlasts the summer builds ice sheets
+	UpdateIceSheets(planet, dt)
+
 	surfaceShell := len(planet.Shells) - 2 // Below atmosphere
 	shell := &planet.Shells[surfaceShell]
 
@@ -313,13 +421,27 @@ func updateAgeCPU(planet *core.VoxelPlanet, dt float64) {
 	}
 }
 
-// Wrapper function that detects whether to use GPU or CPU
-func UpdateVoxelPhysicsWrapper(planet *core.VoxelPlanet, dt float64, gpu gpu.GPUCompute) {
-	if gpu != nil && runtime.GOOS == "darwin" {
+// Step advances the planet by dt years on the GPU when one is available,
+// otherwise through StepCPU. A planet halted by its physics check doesn't step
+func Step(planet *core.VoxelPlanet, dt float64, compute gpu.GPUCompute) {
+	step(planet, dt, compute, &PhysicsTimings{})
+}
+
+// step is Step, adding each subsystem's time to timings
+func step(planet *core.VoxelPlanet, dt float64, compute gpu.GPUCompute, timings *PhysicsTimings) {
+	if planet.Halted() {
+		return
+	}
+	if compute != nil && runtime.GOOS == "darwin" {
 		// Use GPU on macOS
-		UpdateVoxelPhysics(planet, dt, gpu)
+		updateVoxelPhysicsGPU(planet, dt, compute, timings)
+	} else if _, ok := compute.(gpu.TemperatureStepper); ok {
+		// OpenGL compute shaders, CUDA and OpenCL take heat diffusion and
+		// convection, and OpenCL advection too
+		updateVoxelPhysics(planet, dt, compute, timings)
 	} else {
 		// Use CPU on Windows/Linux
-		UpdateVoxelPhysicsCPU(planet, dt)
+		updateVoxelPhysics(planet, dt, nil, timings)
 	}
+	planet.Time += dt
 }
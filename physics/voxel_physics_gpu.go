@@ -1,33 +1,63 @@
 package physics
 
 import (
+	"time"
 	"worldgenerator/core"
 	"worldgenerator/gpu"
 )
 
-// UpdateVoxelPhysics updates the voxel simulation using GPU compute
-func UpdateVoxelPhysics(planet *core.VoxelPlanet, dt float64, gpu gpu.GPUCompute) {
+// UpdateVoxelPhysics updates the voxel simulation using GPU compute. It only
+// runs the temperature, convection and advection kernels: nothing melts,
+// freezes or turns to eclogite until the CPU phase transitions run
+func UpdateVoxelPhysics(planet *core.VoxelPlanet, dt float64, compute gpu.GPUCompute) {
+	updateVoxelPhysicsGPU(planet, dt, compute, &PhysicsTimings{})
+}
+
+// updateVoxelPhysicsGPU is UpdateVoxelPhysics, adding each kernel's time to
+// timings
+func updateVoxelPhysicsGPU(planet *core.VoxelPlanet, dt float64, compute gpu.GPUCompute, timings *PhysicsTimings) {
 	// Run physics kernels on GPU
 	dtFloat32 := float32(dt)
 
-	// Temperature diffusion
-	if err := gpu.RunTemperatureKernel(dtFloat32); err != nil {
+	// Temperature diffusion, heating the interior at the planet's current rate
+	// unless a core boundary condition replaces that heating
+	start := time.Now()
+	if heater, ok := compute.(gpu.RadiogenicHeater); ok {
+		heat := float32(0)
+		if usesLegacyDeepHeating(planet) {
+			heat = float32(planet.RadiogenicHeat())
+		}
+		heater.SetRadiogenicHeat(heat)
+	}
+	if err := compute.RunTemperatureKernel(dtFloat32); err != nil {
 		// Fall back to CPU if GPU fails
 		// TODO: Implement CPU fallback
+	} else {
+		planet.MarkAllShellsDirty()
 	}
+	timings.Temperature += time.Since(start)
 
 	// Convection
-	if err := gpu.RunConvectionKernel(dtFloat32); err != nil {
+	start = time.Now()
+	if err := compute.RunConvectionKernel(dtFloat32); err != nil {
 		// Fall back to CPU
 		// TODO: Implement CPU fallback
+	} else {
+		// Every shell below the air
+		for shellIdx := 0; shellIdx < len(planet.Shells)-1; shellIdx++ {
+			planet.MarkShellDirty(shellIdx)
+		}
 	}
+	timings.Convection += time.Since(start)
 
 	// Advection
-	if err := gpu.RunAdvectionKernel(dtFloat32); err != nil {
+	start = time.Now()
+	if err := compute.RunAdvectionKernel(dtFloat32); err != nil {
 		// Fall back to CPU
 		// TODO: Implement CPU fallback
+	} else if surfaceShell := len(planet.Shells) - 2; surfaceShell >= 0 {
+		// Plates only move across the surface shell
+		planet.MarkShellDirty(surfaceShell)
 	}
-
-	// Mark mesh as needing update
-	planet.MeshDirty = true
+	timings.Advection += time.Since(start)
 }
@@ -0,0 +1,121 @@
+package physics
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"worldgenerator/core"
+)
+
+// TestAdvectionConservesWater drifts a continent across a 3 km deep ocean and
+// checks the ocean keeps its volume: the water the continent pushes aside
+// fills in behind it, so neither the total nor sea level moves
+func TestAdvectionConservesWater(t *testing.T) {
+	planet := core.CreateVoxelPlanet(6371000.0, 8)
+	shell := &planet.Shells[len(planet.Shells)-2]
+	radius := (shell.InnerRadius + shell.OuterRadius) / 2
+
+	for latIdx := range shell.Voxels {
+		for lonIdx := range shell.Voxels[latIdx] {
+			shell.Voxels[latIdx][lonIdx] = core.VoxelMaterial{
+				Type:          core.MatWater,
+				Density:       1000,
+				Temperature:   288,
+				Elevation:     -3000,
+				StretchFactor: 1.0,
+			}
+		}
+	}
+
+	// Half a cell west per step along the equator. Moves are applied from
+	// west to east, so of a continent heading east only the leading column
+	// gets away, the rest colliding with the column ahead before it moves,
+	// and fillPlateGaps heals the tear with new crust that rightly raises
+	// sea level
+	const dt = 365.25 * 24 * 3600
+	midLat := shell.LatBands / 2
+	for latIdx := midLat - 5; latIdx <= midLat+5; latIdx++ {
+		lat := core.GetLatitudeForBand(latIdx, shell.LatBands) * math.Pi / 180
+		cellWidth := 2 * math.Pi * radius * math.Cos(lat) / float64(len(shell.Voxels[latIdx]))
+		for lonIdx := 100; lonIdx < 120; lonIdx++ {
+			shell.Voxels[latIdx][lonIdx] = core.VoxelMaterial{
+				Type:          core.MatGranite,
+				Density:       2700,
+				Temperature:   300,
+				Elevation:     500,
+				PlateID:       1,
+				StretchFactor: 1.0,
+				VelEast:       float32(-0.5 * cellWidth / dt),
+			}
+		}
+	}
+
+	planet.SeaLevel = 0
+	planet.WaterBudget = planet.TotalWaterVolume()
+	want := planet.WaterBudget
+	va := &VoxelAdvection{planet: planet, waterFlow: NewWaterFlow(planet)}
+
+	for step := 1; step <= 40; step++ {
+		va.advectSurfacePlates(dt)
+
+		got := planet.TotalWaterVolume()
+		if math.Abs(got-want) > waterDriftTolerance*want {
+			t.Fatalf("step %d: ocean holds %.6e m³, want %.6e m³", step, got, want)
+		}
+		if math.Abs(planet.SeaLevel) > 1 {
+			t.Fatalf("step %d: sea level moved to %.2f m to make up for lost water", step, planet.SeaLevel)
+		}
+	}
+
+	if moved := shell.Voxels[midLat][119].Type; moved == core.MatGranite {
+		t.Errorf("continent never left its starting cell")
+	}
+}
+
+// TestEngineConservesWater steps the threaded engine, which swaps buffers
+// every tick, and checks both buffers and the snapshots subscribers get
+// carry the one water budget, so the published sea level follows a single
+// ocean instead of alternating between what each buffer made of its own
+func TestEngineConservesWater(t *testing.T) {
+	planet := testPlanet(5)
+	planet.WaterBudget = planet.TotalWaterVolume()
+	engine := NewThreadedPhysicsEngine(planet, nil, 100000.0)
+	engine.SetFixedTick(0.1)
+	snapshots := engine.Subscribe()
+	if engine.planetB.WaterBudget != planet.WaterBudget || engine.planetB.TotalRockVolume != planet.TotalRockVolume {
+		t.Fatalf("second buffer starts with a budget of %.6e m³, want %.6e m³", engine.planetB.WaterBudget, planet.WaterBudget)
+	}
+
+	now := engine.lastPhysicsTime
+	var levels []float64
+	for tick := 1; tick <= 8; tick++ {
+		now = now.Add(100 * time.Millisecond)
+		engine.tick(now)
+
+		live := engine.GetCurrentPlanet()
+		levels = append(levels, live.SeaLevel)
+		if snapshot := <-snapshots; snapshot.WaterBudget != live.WaterBudget {
+			t.Errorf("tick %d: snapshot budget %.6e m³, published planet %.6e m³", tick, snapshot.WaterBudget, live.WaterBudget)
+		}
+
+		// The next tick starts the other buffer from the published state
+		behind := engine.currentWrite.Load()
+		copyPlanetState(behind, live)
+		if behind.WaterBudget != live.WaterBudget || behind.TotalRockVolume != live.TotalRockVolume {
+			t.Errorf("tick %d: caught up buffer holds %.6e m³, published planet %.6e m³", tick, behind.WaterBudget, live.WaterBudget)
+		}
+	}
+
+	// The sea follows the changing basins and ice, but never jumps back and
+	// forth between two budgets, which undoes each tick's change with one
+	// about as large
+	for i := 2; i < len(levels); i++ {
+		prev, next := math.Abs(levels[i-1]-levels[i-2]), math.Abs(levels[i]-levels[i-1])
+		reversed := (levels[i-1]-levels[i-2])*(levels[i]-levels[i-1]) < 0
+		if reversed && math.Min(prev, next) > math.Max(1, math.Max(prev, next)/2) {
+			t.Errorf("sea level blinks: %.2f m", levels)
+			break
+		}
+	}
+}
@@ -0,0 +1,302 @@
+// Package export writes planets in formats other tools can open
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+
+	"worldgenerator/core"
+)
+
+// GLB chunk and component constants from the glTF 2.0 specification
+const (
+	glbMagic        = 0x46546C67 // "glTF"
+	glbVersion      = 2
+	glbChunkJSON    = 0x4E4F534A // "JSON"
+	glbChunkBIN     = 0x004E4942 // "BIN\0"
+	gltfFloat       = 5126
+	gltfUnsignedInt = 5125
+	gltfArrayBuffer = 34962
+	gltfIndexBuffer = 34963
+	gltfTriangles   = 4
+)
+
+// surfaceMesh is the surface shell as a closed triangle mesh, one vertex per
+// voxel center plus one at each pole
+type surfaceMesh struct {
+	Positions []float32 // XYZ in meters, Y toward the north pole
+	Normals   []float32 // Unit XYZ
+	Colors    []float32 // Linear RGB
+	Indices   []uint32  // Triangles, counter-clockwise seen from outside
+}
+
+// WriteGLTF writes the surface shell as a binary glTF (.glb) mesh for
+// Blender and other 3D tools. Each voxel center becomes a vertex raised by
+// its elevation at true scale, in meters; bands with different longitude
+// counts are stitched into one closed surface. Vertices are colored by
+// material on land and by depth under the sea
+func WriteGLTF(planet *core.VoxelPlanet, path string) error {
+	if len(planet.Shells) < 2 {
+		return fmt.Errorf("planet has no surface shell")
+	}
+	mesh := buildSurfaceMesh(planet)
+
+	data, err := encodeGLB(mesh)
+	if err != nil {
+		return fmt.Errorf("failed to encode glTF: %v", err)
+	}
+	err = core.WriteFileAtomic(path, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write glTF file: %v", err)
+	}
+	return nil
+}
+
+// buildSurfaceMesh triangulates the surface shell
+func buildSurfaceMesh(planet *core.VoxelPlanet) *surfaceMesh {
+	surface := len(planet.Shells) - 2
+	shell := &planet.Shells[surface]
+	mesh := &surfaceMesh{}
+
+	addVertex := func(latDeg, lonDeg, elevation float64, color core.Vector3) uint32 {
+		p := core.GeographicToCartesian(core.Geographic{
+			Lat: core.DegreesToRadians(latDeg),
+			Lon: core.DegreesToRadians(lonDeg),
+			Alt: elevation,
+		}, planet.Radius, planet.Flattening)
+		mesh.Positions = append(mesh.Positions, float32(p.X), float32(p.Y), float32(p.Z))
+		mesh.Colors = append(mesh.Colors, linearRGB(color.X), linearRGB(color.Y), linearRGB(color.Z))
+		return uint32(len(mesh.Positions)/3 - 1)
+	}
+
+	// One ring of vertices per latitude band between the polar bands, south
+	// to north
+	rings := make([][]uint32, 0, shell.LatBands-2)
+	for latIdx := 1; latIdx < shell.LatBands-1; latIdx++ {
+		ring := make([]uint32, len(shell.Voxels[latIdx]))
+		for lonIdx := range shell.Voxels[latIdx] {
+			voxel := &shell.Voxels[latIdx][lonIdx]
+			lat, lon := planet.VoxelLatLon(core.VoxelCoord{Shell: surface, Lat: latIdx, Lon: lonIdx})
+			ring[lonIdx] = addVertex(lat, lon, float64(voxel.Elevation), voxelColor(planet, voxel))
+		}
+		rings = append(rings, ring)
+	}
+
+	// The polar bands are centered on the poles, so each becomes one vertex
+	// with the mean of the band
+	south := poleVertex(planet, shell.Voxels[0], -90, addVertex)
+	north := poleVertex(planet, shell.Voxels[shell.LatBands-1], 90, addVertex)
+
+	for i := range rings[0] {
+		mesh.addTriangle(south, rings[0][(i+1)%len(rings[0])], rings[0][i])
+	}
+	for latIdx := 0; latIdx+1 < len(rings); latIdx++ {
+		mesh.stitchRings(rings[latIdx], rings[latIdx+1])
+	}
+	top := rings[len(rings)-1]
+	for i := range top {
+		mesh.addTriangle(north, top[i], top[(i+1)%len(top)])
+	}
+
+	mesh.computeNormals()
+	return mesh
+}
+
+// poleVertex adds a vertex at a pole with the band's mean elevation and color
+func poleVertex(planet *core.VoxelPlanet, band []core.VoxelMaterial, lat float64,
+	addVertex func(lat, lon, elevation float64, color core.Vector3) uint32) uint32 {
+	elevation := 0.0
+	var color core.Vector3
+	for i := range band {
+		elevation += float64(band[i].Elevation)
+		color = color.Add(voxelColor(planet, &band[i]))
+	}
+	n := float64(len(band))
+	return addVertex(lat, 0, elevation/n, color.Scale(1/n))
+}
+
+// stitchRings fills the strip between a ring and the ring north of it. The
+// rings may have different vertex counts, so the walk advances along
+// whichever ring's next vertex comes first in longitude, giving
+// len(south)+len(north) triangles with no gaps or overlaps
+func (m *surfaceMesh) stitchRings(south, north []uint32) {
+	ns, nn := len(south), len(north)
+	i, j := 0, 0
+	for i < ns || j < nn {
+		nextSouth := (float64(i) + 1.5) / float64(ns)
+		nextNorth := (float64(j) + 1.5) / float64(nn)
+		if j == nn || (i < ns && nextSouth <= nextNorth) {
+			m.addTriangle(south[i%ns], south[(i+1)%ns], north[j%nn])
+			i++
+		} else {
+			m.addTriangle(south[i%ns], north[(j+1)%nn], north[j%nn])
+			j++
+		}
+	}
+}
+
+// addTriangle appends a triangle, flipping it if needed so it winds
+// counter-clockwise seen from outside the planet
+func (m *surfaceMesh) addTriangle(a, b, c uint32) {
+	pa, pb, pc := m.position(a), m.position(b), m.position(c)
+	normal := pb.Sub(pa).Cross(pc.Sub(pa))
+	centroid := pa.Add(pb).Add(pc)
+	if normal.Dot(centroid) < 0 {
+		b, c = c, b
+	}
+	m.Indices = append(m.Indices, a, b, c)
+}
+
+// position returns vertex i
+func (m *surfaceMesh) position(i uint32) core.Vector3 {
+	return core.Vector3{
+		X: float64(m.Positions[i*3]),
+		Y: float64(m.Positions[i*3+1]),
+		Z: float64(m.Positions[i*3+2]),
+	}
+}
+
+// computeNormals sets each vertex normal to the area-weighted average of the
+// triangles around it, so relief shades smoothly
+func (m *surfaceMesh) computeNormals() {
+	sums := make([]core.Vector3, len(m.Positions)/3)
+	for t := 0; t < len(m.Indices); t += 3 {
+		a, b, c := m.Indices[t], m.Indices[t+1], m.Indices[t+2]
+		pa := m.position(a)
+		normal := m.position(b).Sub(pa).Cross(m.position(c).Sub(pa)) // Length is twice the area
+		sums[a] = sums[a].Add(normal)
+		sums[b] = sums[b].Add(normal)
+		sums[c] = sums[c].Add(normal)
+	}
+
+	m.Normals = make([]float32, 0, len(m.Positions))
+	for i, sum := range sums {
+		if sum.Length() == 0 {
+			sum = m.position(uint32(i)) // Degenerate fan, fall back to radial
+		}
+		n := sum.Normalize()
+		m.Normals = append(m.Normals, float32(n.X), float32(n.Y), float32(n.Z))
+	}
+}
+
+// voxelColor is the material's display color on land and the elevation
+// colormap's sea shade at the voxel's depth under water
+func voxelColor(planet *core.VoxelPlanet, voxel *core.VoxelMaterial) core.Vector3 {
+	depth := float64(voxel.Elevation) - planet.SeaLevel
+	if voxel.Type == core.MatWater || depth < 0 {
+		return core.ElevationColormap.Color(math.Min(depth, -1))
+	}
+	return core.MaterialColor(voxel.SurfaceType())
+}
+
+// linearRGB converts an sRGB display channel to the linear value glTF
+// vertex colors hold
+func linearRGB(c float64) float32 {
+	if c <= 0.04045 {
+		return float32(c / 12.92)
+	}
+	return float32(math.Pow((c+0.055)/1.055, 2.4))
+}
+
+// gltfAccessor is one typed view of binary data in the glTF JSON
+type gltfAccessor struct {
+	BufferView    int       `json:"bufferView"`
+	ComponentType int       `json:"componentType"`
+	Count         int       `json:"count"`
+	Type          string    `json:"type"`
+	Min           []float32 `json:"min,omitempty"`
+	Max           []float32 `json:"max,omitempty"`
+}
+
+// gltfBufferView is a byte range of the binary chunk
+type gltfBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	Target     int `json:"target"`
+}
+
+// encodeGLB packs the mesh into a binary glTF: a JSON chunk describing one
+// node with one mesh, followed by a binary chunk holding the vertex and
+// index arrays
+func encodeGLB(mesh *surfaceMesh) ([]byte, error) {
+	var bin bytes.Buffer
+	var views []gltfBufferView
+	addView := func(data any, target int) int {
+		offset := bin.Len()
+		binary.Write(&bin, binary.LittleEndian, data)
+		views = append(views, gltfBufferView{ByteOffset: offset, ByteLength: bin.Len() - offset, Target: target})
+		return len(views) - 1
+	}
+
+	vertexCount := len(mesh.Positions) / 3
+	minPos := []float32{float32(math.Inf(1)), float32(math.Inf(1)), float32(math.Inf(1))}
+	maxPos := []float32{float32(math.Inf(-1)), float32(math.Inf(-1)), float32(math.Inf(-1))}
+	for i, v := range mesh.Positions {
+		minPos[i%3] = min(minPos[i%3], v)
+		maxPos[i%3] = max(maxPos[i%3], v)
+	}
+
+	accessors := []gltfAccessor{
+		{BufferView: addView(mesh.Positions, gltfArrayBuffer), ComponentType: gltfFloat, Count: vertexCount, Type: "VEC3", Min: minPos, Max: maxPos},
+		{BufferView: addView(mesh.Normals, gltfArrayBuffer), ComponentType: gltfFloat, Count: vertexCount, Type: "VEC3"},
+		{BufferView: addView(mesh.Colors, gltfArrayBuffer), ComponentType: gltfFloat, Count: vertexCount, Type: "VEC3"},
+		{BufferView: addView(mesh.Indices, gltfIndexBuffer), ComponentType: gltfUnsignedInt, Count: len(mesh.Indices), Type: "SCALAR"},
+	}
+
+	doc := map[string]any{
+		"asset":  map[string]any{"version": "2.0", "generator": "worldgenerator"},
+		"scene":  0,
+		"scenes": []any{map[string]any{"nodes": []int{0}}},
+		"nodes":  []any{map[string]any{"name": "Planet", "mesh": 0}},
+		"meshes": []any{map[string]any{
+			"name": "Surface",
+			"primitives": []any{map[string]any{
+				"attributes": map[string]int{"POSITION": 0, "NORMAL": 1, "COLOR_0": 2},
+				"indices":    3,
+				"material":   0,
+				"mode":       gltfTriangles,
+			}},
+		}},
+		// White and fully rough, so the vertex colors show as they are
+		"materials": []any{map[string]any{
+			"name": "Surface",
+			"pbrMetallicRoughness": map[string]any{
+				"baseColorFactor": []float64{1, 1, 1, 1},
+				"metallicFactor":  0,
+				"roughnessFactor": 1,
+			},
+		}},
+		"accessors":   accessors,
+		"bufferViews": views,
+		"buffers":     []any{map[string]int{"byteLength": bin.Len()}},
+	}
+	jsonData, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	// Chunks are padded to 4 bytes, JSON with spaces and binary with zeros
+	for len(jsonData)%4 != 0 {
+		jsonData = append(jsonData, ' ')
+	}
+	for bin.Len()%4 != 0 {
+		bin.WriteByte(0)
+	}
+
+	var out bytes.Buffer
+	total := 12 + 8 + len(jsonData) + 8 + bin.Len()
+	binary.Write(&out, binary.LittleEndian, []uint32{glbMagic, glbVersion, uint32(total)})
+	binary.Write(&out, binary.LittleEndian, []uint32{uint32(len(jsonData)), glbChunkJSON})
+	out.Write(jsonData)
+	binary.Write(&out, binary.LittleEndian, []uint32{uint32(bin.Len()), glbChunkBIN})
+	out.Write(bin.Bytes())
+	return out.Bytes(), nil
+}
@@ -0,0 +1,104 @@
+package export
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// TestSurfaceMeshClosed checks the stitched bands form a closed surface:
+// every edge is shared by exactly two triangles, once in each direction, so
+// there are no holes and all faces wind the same way
+func TestSurfaceMeshClosed(t *testing.T) {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 4, 24)
+	mesh := buildSurfaceMesh(planet)
+
+	type edge struct{ a, b uint32 }
+	edges := make(map[edge]int)
+	for i := 0; i < len(mesh.Indices); i += 3 {
+		tri := mesh.Indices[i : i+3]
+		for k := 0; k < 3; k++ {
+			edges[edge{tri[k], tri[(k+1)%3]}]++
+		}
+	}
+	for e, count := range edges {
+		if count != 1 || edges[edge{e.b, e.a}] != 1 {
+			t.Fatalf("edge %d-%d used %d times and %d times reversed", e.a, e.b, count, edges[edge{e.b, e.a}])
+		}
+	}
+
+	// A closed surface of V vertices has 2V-4 triangles
+	vertices := len(mesh.Positions) / 3
+	if triangles := len(mesh.Indices) / 3; triangles != 2*vertices-4 {
+		t.Errorf("%d triangles for %d vertices, want %d", triangles, vertices, 2*vertices-4)
+	}
+
+	// Normals are unit length and point outward
+	for i := 0; i < vertices; i++ {
+		n := core.Vector3{X: float64(mesh.Normals[i*3]), Y: float64(mesh.Normals[i*3+1]), Z: float64(mesh.Normals[i*3+2])}
+		if math.Abs(n.Length()-1) > 1e-4 || n.Dot(mesh.position(uint32(i))) <= 0 {
+			t.Fatalf("vertex %d normal %+v isn't an outward unit vector", i, n)
+		}
+	}
+}
+
+// TestWriteGLTF checks the file is a GLB whose accessors match the mesh and
+// fit in the binary chunk
+func TestWriteGLTF(t *testing.T) {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 4, 12)
+	path := filepath.Join(t.TempDir(), "planet.glb")
+	if err := WriteGLTF(planet, path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	le := binary.LittleEndian
+	if le.Uint32(data[0:]) != glbMagic || le.Uint32(data[4:]) != glbVersion || int(le.Uint32(data[8:])) != len(data) {
+		t.Fatalf("bad GLB header % x", data[:12])
+	}
+	jsonLen := int(le.Uint32(data[12:]))
+	if le.Uint32(data[16:]) != glbChunkJSON || jsonLen%4 != 0 {
+		t.Fatalf("first chunk isn't padded JSON")
+	}
+	binStart := 20 + jsonLen
+	binLen := int(le.Uint32(data[binStart:]))
+	if le.Uint32(data[binStart+4:]) != glbChunkBIN || binStart+8+binLen != len(data) {
+		t.Fatalf("second chunk isn't a BIN chunk filling the file")
+	}
+
+	var doc struct {
+		Accessors   []gltfAccessor
+		BufferViews []gltfBufferView
+		Buffers     []struct{ ByteLength int }
+	}
+	if err := json.Unmarshal(data[20:binStart], &doc); err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Buffers) != 1 || doc.Buffers[0].ByteLength > binLen {
+		t.Fatalf("buffers %+v don't fit the %d byte BIN chunk", doc.Buffers, binLen)
+	}
+
+	mesh := buildSurfaceMesh(planet)
+	wantCounts := []int{len(mesh.Positions) / 3, len(mesh.Normals) / 3, len(mesh.Colors) / 3, len(mesh.Indices)}
+	for i, accessor := range doc.Accessors {
+		view := doc.BufferViews[accessor.BufferView]
+		components := map[string]int{"SCALAR": 1, "VEC3": 3}[accessor.Type]
+		if accessor.Count != wantCounts[i] || view.ByteLength != accessor.Count*components*4 {
+			t.Errorf("accessor %d has %d elements in %d bytes, want %d", i, accessor.Count, view.ByteLength, wantCounts[i])
+		}
+		if view.ByteOffset+view.ByteLength > doc.Buffers[0].ByteLength {
+			t.Errorf("buffer view %d runs past the buffer", accessor.BufferView)
+		}
+	}
+	if len(doc.Accessors[0].Min) != 3 || doc.Accessors[0].Max[1] < float32(planet.Radius)*0.99 {
+		t.Errorf("position bounds %v-%v don't reach the pole", doc.Accessors[0].Min, doc.Accessors[0].Max)
+	}
+}
@@ -0,0 +1,63 @@
+package opengl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-gl/gl/v4.3-core/gl"
+)
+
+// glErrorNames maps OpenGL error codes to their symbolic names
+var glErrorNames = map[uint32]string{
+	gl.INVALID_ENUM:                  "GL_INVALID_ENUM",
+	gl.INVALID_VALUE:                 "GL_INVALID_VALUE",
+	gl.INVALID_OPERATION:             "GL_INVALID_OPERATION",
+	gl.STACK_OVERFLOW:                "GL_STACK_OVERFLOW",
+	gl.STACK_UNDERFLOW:               "GL_STACK_UNDERFLOW",
+	gl.OUT_OF_MEMORY:                 "GL_OUT_OF_MEMORY",
+	gl.INVALID_FRAMEBUFFER_OPERATION: "GL_INVALID_FRAMEBUFFER_OPERATION",
+}
+
+// maxGLErrors bounds how many queued errors are drained in one check
+// (a lost context can keep reporting errors forever)
+const maxGLErrors = 16
+
+// GLError describes one or more OpenGL errors raised during a rendering stage
+type GLError struct {
+	Stage string
+	Codes []uint32
+}
+
+// Error implements the error interface
+func (e *GLError) Error() string {
+	names := make([]string, len(e.Codes))
+	for i, code := range e.Codes {
+		names[i] = glErrorName(code)
+	}
+	return fmt.Sprintf("OpenGL error during %s: %s", e.Stage, strings.Join(names, ", "))
+}
+
+// glErrorName returns the symbolic name for an OpenGL error code
+func glErrorName(code uint32) string {
+	if name, ok := glErrorNames[code]; ok {
+		return fmt.Sprintf("%s (0x%x)", name, code)
+	}
+	return fmt.Sprintf("unknown error 0x%x", code)
+}
+
+// checkGLError drains the OpenGL error queue and returns a GLError labelled
+// with the given stage, or nil if no errors were pending
+func checkGLError(stage string) error {
+	var codes []uint32
+	for i := 0; i < maxGLErrors; i++ {
+		code := gl.GetError()
+		if code == gl.NO_ERROR {
+			break
+		}
+		codes = append(codes, code)
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return &GLError{Stage: stage, Codes: codes}
+}
@@ -0,0 +1,85 @@
+package overlay
+
+import (
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// ColumnLayer is one voxel of an inspected vertical column
+type ColumnLayer struct {
+	Depth       float32 // km below the surface at the voxel's center (negative = above)
+	Temperature float32 // K
+	Material    string
+	Color       mgl32.Vec4 // Material display color
+}
+
+var (
+	columnBarColor = mgl32.Vec4{1.0, 0.45, 0.15, 0.9}
+	columnDimColor = mgl32.Vec4{0.6, 0.6, 0.6, 1.0}
+)
+
+// RenderColumn draws a panel down the right edge of the screen listing a
+// column from the surface down, one row per layer with its depth, material
+// and a bar scaled to its temperature, so the geotherm reads as a profile
+func (so *StatsOverlay) RenderColumn(title string, layers []ColumnLayer) {
+	var maxTemp float32
+	for _, l := range layers {
+		maxTemp = max(maxTemp, l.Temperature)
+	}
+	if maxTemp <= 0 {
+		maxTemp = 1
+	}
+
+	const (
+		depthChars = 7  // "-1234.5"
+		barChars   = 16 // Temperature bar
+		tempChars  = 6  // "12345K"
+		nameChars  = 12
+	)
+	columns := 2 + depthChars + 1 + barChars + 1 + tempChars + 1 + nameChars
+	columns = max(columns, len([]rune(title)))
+	rows := len(layers) + 3 // Title, header and a blank line
+
+	pixel := float32(2)
+	if float32(columns*glyphAdvance+4)*pixel > so.width/2 || float32(rows*glyphLineStep+4)*pixel > so.height {
+		pixel = 1
+	}
+	margin := 2 * glyphAdvance * pixel
+	lineStep := glyphLineStep * pixel
+	char := glyphAdvance * pixel
+
+	panelW := float32(columns)*char + 2*margin
+	panelH := float32(rows)*lineStep + 2*margin
+	panelX := so.width - panelW - 10
+	panelY := float32(10)
+
+	vertices := appendQuad(nil, panelX, panelY, panelW, panelH, helpBackground)
+
+	x := panelX + margin
+	y := panelY + margin
+	vertices = appendText(vertices, x, y, pixel, title, helpTitleColor)
+	y += 2 * lineStep
+
+	depthX := x + 2*char
+	barX := depthX + float32(depthChars+1)*char
+	tempX := barX + float32(barChars+1)*char
+	nameX := tempX + float32(tempChars+1)*char
+	vertices = appendText(vertices, depthX, y, pixel, "KM", columnDimColor)
+	vertices = appendText(vertices, barX, y, pixel, "TEMPERATURE", columnDimColor)
+	vertices = appendText(vertices, nameX, y, pixel, "MATERIAL", columnDimColor)
+	y += lineStep
+
+	barH := glyphHeight * pixel
+	for _, l := range layers {
+		vertices = appendQuad(vertices, x, y, char-pixel, barH, l.Color)
+		vertices = appendText(vertices, depthX, y, pixel, fmt.Sprintf("%*.0f", depthChars, l.Depth), helpTextColor)
+		barW := float32(barChars) * char * max(l.Temperature, 0) / maxTemp
+		vertices = appendQuad(vertices, barX, y, barW, barH, columnBarColor)
+		vertices = appendText(vertices, tempX, y, pixel, fmt.Sprintf("%5.0fK", l.Temperature), helpStateColor)
+		vertices = appendText(vertices, nameX, y, pixel, l.Material, helpTextColor)
+		y += lineStep
+	}
+
+	so.drawVertices(vertices)
+}
@@ -0,0 +1,69 @@
+package overlay
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// HelpEntry is one line of the help screen
+type HelpEntry struct {
+	Keys   string // As the user presses them, e.g. "Shift+1-5"
+	Action string
+	State  string // Current setting, empty when the control has none
+}
+
+var (
+	helpBackground = mgl32.Vec4{0.05, 0.05, 0.15, 0.85}
+	helpTitleColor = mgl32.Vec4{1.0, 1.0, 1.0, 1.0}
+	helpKeyColor   = mgl32.Vec4{1.0, 0.85, 0.3, 1.0}
+	helpTextColor  = mgl32.Vec4{0.9, 0.9, 0.9, 1.0}
+	helpStateColor = mgl32.Vec4{0.3, 1.0, 1.0, 1.0}
+)
+
+// RenderHelp draws a centered panel listing every control, its action and
+// its current state. The font is scaled up on screens with room for it
+func (so *StatsOverlay) RenderHelp(title string, entries []HelpEntry) {
+	// Column widths in characters
+	keyChars, actionChars, stateChars := 0, 0, 0
+	for _, e := range entries {
+		keyChars = max(keyChars, len([]rune(e.Keys)))
+		actionChars = max(actionChars, len([]rune(e.Action)))
+		stateChars = max(stateChars, len([]rune(e.State)))
+	}
+	columns := keyChars + 2 + actionChars
+	if stateChars > 0 {
+		columns += 2 + stateChars
+	}
+	rows := len(entries) + 2 // Title and a blank line
+
+	pixel := float32(2)
+	if float32(columns*glyphAdvance+4)*pixel > so.width || float32(rows*glyphLineStep+4)*pixel > so.height {
+		pixel = 1
+	}
+	margin := 2 * glyphAdvance * pixel
+	lineStep := glyphLineStep * pixel
+
+	panelW := float32(columns*glyphAdvance)*pixel + 2*margin
+	panelH := float32(rows)*lineStep + 2*margin
+	panelX := (so.width - panelW) / 2
+	panelY := (so.height - panelH) / 2
+
+	vertices := appendQuad(nil, panelX, panelY, panelW, panelH, helpBackground)
+
+	x := panelX + margin
+	y := panelY + margin
+	vertices = appendText(vertices, x, y, pixel, title, helpTitleColor)
+	y += 2 * lineStep
+
+	actionX := x + float32((keyChars+2)*glyphAdvance)*pixel
+	stateX := actionX + float32((actionChars+2)*glyphAdvance)*pixel
+	for _, e := range entries {
+		vertices = appendText(vertices, x, y, pixel, e.Keys, helpKeyColor)
+		vertices = appendText(vertices, actionX, y, pixel, e.Action, helpTextColor)
+		if e.State != "" {
+			vertices = appendText(vertices, stateX, y, pixel, e.State, helpStateColor)
+		}
+		y += lineStep
+	}
+
+	so.drawVertices(vertices)
+}
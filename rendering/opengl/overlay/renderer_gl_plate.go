@@ -0,0 +1,47 @@
+package overlay
+
+// PlateStatLine is one row of the selected plate's panel
+type PlateStatLine struct {
+	Label string
+	Value string
+}
+
+// RenderPlateStats draws a panel in the bottom-left corner listing the
+// selected plate's statistics, one labeled value per row
+func (so *StatsOverlay) RenderPlateStats(title string, lines []PlateStatLine) {
+	labelChars, valueChars := 0, 0
+	for _, l := range lines {
+		labelChars = max(labelChars, len([]rune(l.Label)))
+		valueChars = max(valueChars, len([]rune(l.Value)))
+	}
+	columns := max(labelChars+2+valueChars, len([]rune(title)))
+	rows := len(lines) + 2 // Title and a blank line
+
+	pixel := float32(2)
+	if float32(columns*glyphAdvance+4)*pixel > so.width/2 || float32(rows*glyphLineStep+4)*pixel > so.height/2 {
+		pixel = 1
+	}
+	margin := 2 * glyphAdvance * pixel
+	lineStep := glyphLineStep * pixel
+
+	panelW := float32(columns*glyphAdvance)*pixel + 2*margin
+	panelH := float32(rows)*lineStep + 2*margin
+	panelX := float32(10)
+	panelY := so.height - panelH - 10
+
+	vertices := appendQuad(nil, panelX, panelY, panelW, panelH, helpBackground)
+
+	x := panelX + margin
+	y := panelY + margin
+	vertices = appendText(vertices, x, y, pixel, title, helpTitleColor)
+	y += 2 * lineStep
+
+	valueX := x + float32((labelChars+2)*glyphAdvance)*pixel
+	for _, l := range lines {
+		vertices = appendText(vertices, x, y, pixel, l.Label, helpKeyColor)
+		vertices = appendText(vertices, valueX, y, pixel, l.Value, helpStateColor)
+		y += lineStep
+	}
+
+	so.drawVertices(vertices)
+}
@@ -50,6 +50,12 @@ type StatsOverlay struct {
 	fps       float64
 	zoom      float64
 	distance  float32
+	continents int
+	seed       int64
+	simTime    float64 // Years
+	timeLabel  string  // Set while a snapshot is on screen
+	physics    string  // Per-subsystem physics timings
+	legend     []mgl32.Vec4 // Color ramp of the current view, low to high (nil = none)
 	
 	// Debug
 	renderCount int
@@ -121,6 +127,34 @@ func (so *StatsOverlay) UpdateStats(fps float64, zoom float64, distance float32)
 	so.distance = distance
 }
 
+// SetContinentCount updates the number of landmasses to display
+func (so *StatsOverlay) SetContinentCount(count int) {
+	so.continents = count
+}
+
+// SetSimTime updates the simulation time to display, with an optional label
+// such as the snapshot being shown
+func (so *StatsOverlay) SetSimTime(years float64, label string) {
+	so.simTime = years
+	so.timeLabel = label
+}
+
+// SetColorLegend sets the color ramp drawn under the stats, from low to high
+// values; nil hides it
+func (so *StatsOverlay) SetColorLegend(stops []mgl32.Vec4) {
+	so.legend = stops
+}
+
+// SetPhysicsTimings updates the per-subsystem physics timings to display
+func (so *StatsOverlay) SetPhysicsTimings(text string) {
+	so.physics = text
+}
+
+// SetSeed updates the planet seed to display
+func (so *StatsOverlay) SetSeed(seed int64) {
+	so.seed = seed
+}
+
 // Render draws the stats overlay
 func (so *StatsOverlay) Render() {
 	// Debug: print once to confirm render is being called
@@ -162,7 +196,7 @@ func (so *StatsOverlay) Render() {
 	boxX := float32(10)
 	boxY := float32(10) // Top left
 	boxW := float32(300)
-	boxH := float32(100)
+	boxH := float32(175)
 	
 	vertices := []float32{
 		// Position     Color (RGBA)
@@ -217,6 +251,31 @@ func (so *StatsOverlay) Render() {
 	// Distance bar (yellow)
 	so.drawTextBar(boxX + 10, textY + 50, fmt.Sprintf("Dist: %.0f km", so.distance/1000.0), mgl32.Vec4{1.0, 1.0, 0.0, 1.0})
 	
+	// Continent count bar (orange)
+	so.drawTextBar(boxX + 10, textY + 75, fmt.Sprintf("Continents: %d", so.continents), mgl32.Vec4{1.0, 0.6, 0.2, 1.0})
+	
+	// Seed bar (white)
+	so.drawTextBar(boxX + 10, textY + 100, fmt.Sprintf("Seed: %d", so.seed), mgl32.Vec4{1.0, 1.0, 1.0, 1.0})
+	
+	// Sim time bar (cyan, magenta while reviewing a snapshot)
+	timeText := fmt.Sprintf("Time: %.1f My", so.simTime/1e6)
+	timeColor := mgl32.Vec4{0.3, 1.0, 1.0, 1.0}
+	if so.timeLabel != "" {
+		timeText += " (" + so.timeLabel + ")"
+		timeColor = mgl32.Vec4{1.0, 0.3, 1.0, 1.0}
+	}
+	so.drawTextBar(boxX + 10, textY + 125, timeText, timeColor)
+	
+	// Physics timings bar (grey)
+	if so.physics != "" {
+		so.drawTextBar(boxX + 10, textY + 150, so.physics, mgl32.Vec4{0.7, 0.7, 0.7, 1.0})
+	}
+	
+	// Color ramp of the current view under the box
+	if len(so.legend) >= 2 {
+		so.drawColorRamp(boxX, boxY + boxH + 10, boxW, 15, so.legend)
+	}
+	
 	// Restore OpenGL state
 	gl.Enable(gl.DEPTH_TEST)
 	gl.Disable(gl.BLEND)
@@ -244,6 +303,28 @@ func (so *StatsOverlay) drawTextBar(x, y float32, text string, color mgl32.Vec4)
 	gl.DrawArrays(gl.TRIANGLES, 0, 6)
 }
 
+// drawColorRamp draws a horizontal gradient through the given colors
+func (so *StatsOverlay) drawColorRamp(x, y, width, height float32, stops []mgl32.Vec4) {
+	segment := width / float32(len(stops)-1)
+	vertices := make([]float32, 0, (len(stops)-1)*6*6)
+	for i := 0; i < len(stops)-1; i++ {
+		x0 := x + float32(i)*segment
+		x1 := x0 + segment
+		c0, c1 := stops[i], stops[i+1]
+		vertices = append(vertices,
+			x0, y, c0[0], c0[1], c0[2], c0[3],
+			x1, y, c1[0], c1[1], c1[2], c1[3],
+			x0, y+height, c0[0], c0[1], c0[2], c0[3],
+			x1, y, c1[0], c1[1], c1[2], c1[3],
+			x1, y+height, c1[0], c1[1], c1[2], c1[3],
+			x0, y+height, c0[0], c0[1], c0[2], c0[3],
+		)
+	}
+
+	gl.BufferData(gl.ARRAY_BUFFER, len(vertices)*4, gl.Ptr(vertices), gl.DYNAMIC_DRAW)
+	gl.DrawArrays(gl.TRIANGLES, 0, int32(len(vertices)/6))
+}
+
 // UpdateSize updates viewport size
 func (so *StatsOverlay) UpdateSize(width, height int) {
 	so.width = float32(width)
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"math"
 	"runtime"
+	"sync"
+	"time"
 	"unsafe"
 
 	"github.com/go-gl/gl/v4.3-core/gl"
@@ -12,11 +14,15 @@ import (
 
 	"worldgenerator/core"
 	"worldgenerator/gpu"
+	"worldgenerator/physics"
 	"worldgenerator/rendering/opengl/overlay"
 	"worldgenerator/rendering/opengl/shaders"
 	"worldgenerator/rendering/textures"
 )
 
+// regenerateConfirmWindow is how long a first R press waits for the second
+const regenerateConfirmWindow = 3 * time.Second
+
 // VoxelRenderer handles native OpenGL rendering of voxel data
 type VoxelRenderer struct {
 	window *glfw.Window
@@ -41,8 +47,14 @@ type VoxelRenderer struct {
 	// Voxel texture data
 	voxelTextures *textures.VoxelTextureData
 
+	// Blending between physics updates (see interpolationFraction)
+	InterpolateFrames bool
+	lastTextureUpload time.Time
+	uploadInterval    time.Duration
+
 	// Planet reference for shell count
 	planetShellCount int32
+	flattening       float32 // Of the planet's shells, see core.VoxelPlanet.Flattening
 
 	// Uniforms
 	viewMatrix   mgl32.Mat4
@@ -50,37 +62,149 @@ type VoxelRenderer struct {
 	cameraPos    mgl32.Vec3
 	planetRadius float32
 
+	// Ray march step as a fraction of the planet radius at every distance,
+	// 0 = rayStepScale. Benchmarks fix it to compare against a constant step
+	fixedRayStep float32
+
+	// Projection (see SetFieldOfView and SetOrthographic)
+	FieldOfView  float32 // Vertical degrees
+	Orthographic bool
+
+	// Flat world map instead of the globe (see SetMapProjection)
+	MapProjection MapProjection
+	mapCenterLon  float64 // Radians
+	mapPan        float64 // Map units up from the equator, see mapPoint
+	mapZoom       float64
+
 	// Render settings
 	width, height    int
-	RenderMode       int32 // 0=material, 1=temperature, 2=velocity, 3=age, 4=plates
+	RenderMode       int32 // 0=material, 1=temperature, 2=velocity, 3=age, 4=plates, 8=heat flux, 9=biomes
 	crossSection     bool
 	crossSectionAxis int32 // 0=X, 1=Y, 2=Z
 	elevationScale   float32 // Exaggeration factor for elevation (0=flat, 100=visible)
 	crossSectionPos  float32
 
+	// Temperature, elevation and age colormaps (see createColormapTexture)
+	colormapTexture uint32
+	colormapRanges  []float32 // Min and max value of each row
+
+	// Hue of each plate in plate view (see bindPlateColors)
+	plateHueTexture uint32
+
+	// Graticule overlay (lat/lon grid)
+	showGraticule    bool
+	GraticuleSpacing float32    // Degrees between grid lines
+	GraticuleColor   mgl32.Vec3 // Line color
+
+	// River overlay (see updateRivers)
+	showRivers       bool
+	RiverColor       mgl32.Vec3
+	riverVersion     uint64 // Surface shell version the overlay was drawn from
+	lastRiverRefresh time.Time
+
+	// Plate drift paths (see updatePlateTracks)
+	showPlateTracks       bool
+	lastPlateTrackRefresh time.Time
+
+	// Voxel grid overlay: cell edges with each band's longitude count, and
+	// shell boundaries on the cross-section face
+	showShellGrid bool
+
+	// Material view colors (see SetMaterialPalette), nil = DefaultMaterialPalette
+	materialPalette MaterialPalette
+
+	// Ocean appearance in material mode
+	OceanShallowColor mgl32.Vec3 // Water color at the coast
+	OceanDeepColor    mgl32.Vec3 // Water color over abyssal plains
+	OceanTransparency float32    // 0=opaque, 1=shallow seafloor fully visible
+
+	// Window title prefix for live stats (empty = DefaultWindowTitle)
+	WindowTitle string
+
+	// Magma and cooling lava glow, scaled by temperature in the shader
+	EmissiveScale float32 // 0=off, 1=default
+
 	// Plate visualization
 	ShowPlates          bool
 	selectedPlateID     int
 	highlightBoundaries bool
 
+	// Vertical column inspection (click the planet outside plate view)
+	columnOpen bool
+	columnLat  float64 // Degrees
+	columnLon  float64
+
 
 	// Mouse state for camera control
 	MouseDown       bool
 	lastMouseX      float64
 	lastMouseY      float64
+	pressX, pressY  float64 // Cursor at the last press, to tell clicks from drags
 	cameraRotationX float32
 	cameraRotationY float32
 
+	// Unattended camera orbit
+	autoOrbit       bool
+	AutoOrbitSpeed  float32       // Degrees per second
+	AutoOrbitIdle   time.Duration // Pause after camera input before resuming
+	lastCameraInput time.Time
+	lastOrbitUpdate time.Time
+
+	// Planet spin on its tilted axis (see SetSpin)
+	AxialTilt      float32 // Degrees the spin axis leans from vertical
+	SpinSpeed      float32 // Degrees per second of wall time (0 = still)
+	spinAngle      float32
+	lastSpinUpdate time.Time
+
 	// Planet reference for picking
 	PlanetRef interface{} // *core.VoxelPlanet but avoid import cycle
 
 	// Stats overlay
 	statsOverlay *overlay.StatsOverlay
 	showStats    bool
+	showHelp     bool // Controls listing over the frame
+
+	// Key bindings (see defaultKeyActions)
+	keys *KeyRegistry
 	
 	// Simulation control (public for main.go access)
 	SpeedMultiplier float32
 	Paused          bool
+
+	// Step requests while paused (consumed by main.go)
+	StepRequested      bool
+	StepYearsRequested bool
+	ScrubSteps         int // Snapshots to move through, negative = back in time
+
+	// Plate held still as the physics reference frame (0 = absolute frame, read by main.go)
+	ReferencePlate int
+
+	// Mantle plumes to root under picked points (consumed by main.go), and the
+	// heat in K each one gets
+	PlumeRequests []core.MantlePlume
+	PlumeHeat     float64
+
+	// New planet requested with a confirmed R press (consumed by main.go)
+	RegenerateRequested bool
+	regenerateArmedAt   time.Time
+
+	// Screenshot requested for the next frame, and PNG writes in progress
+	screenshotRequested bool
+	screenshotCount     int // Numbers screenshots, see nextScreenshotPath
+	screenshots         sync.WaitGroup
+
+	// Frame dump for timelapses, nil unless recording
+	recorder *frameRecorder
+
+	// Offscreen ray march target when supersampling (nil = render directly)
+	ssaa *supersampleTarget
+
+	// Depth of field (see SetDepthOfField; nil target = off)
+	FocusDistance float32    // Meters from the camera (0 = the nearest surface)
+	Aperture      float32    // Blur radius in pixels far beyond the focus distance
+	focusPoint    mgl32.Vec3 // Clicked surface point the focus follows, in planet coordinates
+	focusTracking bool
+	dof           *depthOfFieldTarget
 }
 
 // NewVoxelRenderer creates a native OpenGL voxel renderer
@@ -100,7 +224,7 @@ func NewVoxelRenderer(width, height int) (*VoxelRenderer, error) {
 	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
 
 	// Create window
-	window, err := glfw.CreateWindow(width, height, "Voxel Planet Evolution", nil, nil)
+	window, err := glfw.CreateWindow(width, height, DefaultWindowTitle, nil, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create window: %v", err)
 	}
@@ -128,9 +252,26 @@ func NewVoxelRenderer(width, height int) (*VoxelRenderer, error) {
 		cameraPos:       mgl32.Vec3{0, 0, float32(6371000 * 3)}, // 3x planet radius
 		cameraRotationX: 0,
 		cameraRotationY: 0,
+		FieldOfView:     DefaultFieldOfView,
+		Aperture:        DefaultAperture,
 		showStats:        true, // Show stats overlay by default
+		GraticuleSpacing: 15.0,
+		GraticuleColor:   mgl32.Vec3{1.0, 1.0, 1.0},
+		RiverColor:       mgl32.Vec3{0.15, 0.4, 0.9},
+		OceanShallowColor: mgl32.Vec3{0.2, 0.55, 0.75},
+		OceanDeepColor:    mgl32.Vec3{0.02, 0.07, 0.25},
+		OceanTransparency: 0.5,
+		EmissiveScale:     1.0,
+		AutoOrbitSpeed:    defaultOrbitSpeed,
+		AutoOrbitIdle:     defaultOrbitIdle,
 		SpeedMultiplier:  1.0,
 		Paused:           false,
+		keys:             NewKeyRegistry(),
+	}
+	for _, action := range defaultKeyActions() {
+		if err := r.keys.Register(action); err != nil {
+			return nil, fmt.Errorf("failed to bind keys: %v", err)
+		}
 	}
 
 	// Setup OpenGL state
@@ -154,6 +295,7 @@ func NewVoxelRenderer(width, height int) (*VoxelRenderer, error) {
 
 	// Create voxel texture storage
 	r.voxelTextures = textures.NewVoxelTextureData(30) // Support up to 30 shells
+	r.createColormapTexture()
 
 	// Test system can be enabled if needed for debugging
 	// r.CreateTestRenderers()
@@ -227,7 +369,7 @@ func (r *VoxelRenderer) createQuad() {
 }
 
 // CreateBuffers creates OpenGL SSBOs for voxel data
-func (r *VoxelRenderer) CreateBuffers(buffers *gpu.SharedGPUBuffers) {
+func (r *VoxelRenderer) CreateBuffers(buffers *gpu.SharedGPUBuffers) error {
 	// Create voxel SSBO
 	gl.GenBuffers(1, &r.voxelSSBO)
 	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, r.voxelSSBO)
@@ -264,6 +406,37 @@ func (r *VoxelRenderer) CreateBuffers(buffers *gpu.SharedGPUBuffers) {
 	}
 
 	gl.BindBufferBase(gl.SHADER_STORAGE_BUFFER, 1, r.shellSSBO)
+
+	return checkGLError("buffer creation")
+}
+
+// BufferIDs returns the voxel, shell and longitude count SSBO names in use
+func (r *VoxelRenderer) BufferIDs() [3]uint32 {
+	return [3]uint32{r.voxelSSBO, r.shellSSBO, r.lonCountSSBO}
+}
+
+// ReleaseBuffers frees the voxel SSBOs so buffers for another planet can be
+// created. When mgr supplied them (see SetOptimizedBuffers) it releases them,
+// otherwise the renderer deletes its own. Fails if any name is still allocated
+func (r *VoxelRenderer) ReleaseBuffers(mgr *gpu.WindowsGPUBufferManager) error {
+	ids := r.BufferIDs()
+	if mgr != nil {
+		mgr.Release()
+	} else {
+		for _, id := range ids {
+			if id != 0 {
+				gl.DeleteBuffers(1, &id)
+			}
+		}
+	}
+	r.voxelSSBO, r.shellSSBO, r.lonCountSSBO = 0, 0, 0
+
+	for _, id := range ids {
+		if id != 0 && gl.IsBuffer(id) {
+			return fmt.Errorf("voxel buffer %d still allocated after release", id)
+		}
+	}
+	return checkGLError("buffer release")
 }
 
 // HasComputeShaderSupport checks if compute shaders are available
@@ -279,6 +452,30 @@ func (r *VoxelRenderer) GetCameraDistance() float32 {
 	return r.cameraPos.Len()
 }
 
+// Ray march step limits as fractions of the planet radius
+const (
+	minRayStepScale = 0.002 // Closest zoom, finest detail
+	maxRayStepScale = 0.03  // Farthest zoom, fastest
+)
+
+// rayStepScale returns the ray march step for the current camera distance
+// The step shrinks with altitude above the surface, giving the historical
+// 0.01 at the default distance of three planet radii
+func (r *VoxelRenderer) rayStepScale() float32 {
+	if r.fixedRayStep > 0 {
+		return r.fixedRayStep
+	}
+	altitude := (r.GetCameraDistance() - r.planetRadius) / r.planetRadius
+	scale := 0.005 * altitude
+	if scale < minRayStepScale {
+		scale = minRayStepScale
+	}
+	if scale > maxRayStepScale {
+		scale = maxRayStepScale
+	}
+	return scale
+}
+
 // UpdateStats updates the stats overlay with current values
 func (r *VoxelRenderer) UpdateStats(fps float64) {
 	if r.statsOverlay != nil {
@@ -288,14 +485,64 @@ func (r *VoxelRenderer) UpdateStats(fps float64) {
 	}
 }
 
+// DefaultWindowTitle prefixes the live stats in the window title
+const DefaultWindowTitle = "Voxel Planet Evolution"
+
+// UpdateTitle shows sim time, FPS and speed in the window title, so recordings
+// and side-by-side runs can be told apart without the overlay
+func (r *VoxelRenderer) UpdateTitle(simYears, fps float64) {
+	name := r.WindowTitle
+	if name == "" {
+		name = DefaultWindowTitle
+	}
+
+	speed := fmt.Sprintf("%.0fx", r.SpeedMultiplier)
+	if r.Paused {
+		speed = "paused"
+	}
+
+	r.window.SetTitle(fmt.Sprintf("%s — %.1f My — %.0f FPS — %s", name, simYears/1e6, fps, speed))
+}
+
+// SetContinentCount updates the landmass count shown in the stats overlay
+func (r *VoxelRenderer) SetContinentCount(count int) {
+	if r.statsOverlay != nil {
+		r.statsOverlay.SetContinentCount(count)
+	}
+}
+
+// SetSimTime updates the simulation time shown in the stats overlay
+// label marks a recorded snapshot on screen instead of the live planet
+func (r *VoxelRenderer) SetSimTime(years float64, label string) {
+	if r.statsOverlay != nil {
+		r.statsOverlay.SetSimTime(years, label)
+	}
+}
+
+// SetPhysicsTimings updates the per-subsystem physics timings shown in the
+// stats overlay
+func (r *VoxelRenderer) SetPhysicsTimings(timings physics.PhysicsTimings) {
+	if r.statsOverlay != nil {
+		r.statsOverlay.SetPhysicsTimings(timings.String())
+	}
+}
+
+// SetSeed updates the planet seed shown in the stats overlay
+func (r *VoxelRenderer) SetSeed(seed int64) {
+	if r.statsOverlay != nil {
+		r.statsOverlay.SetSeed(seed)
+	}
+}
+
 // UpdateBuffers updates the GPU buffers with new voxel data
-func (r *VoxelRenderer) UpdateBuffers(buffers *gpu.SharedGPUBuffers) {
+func (r *VoxelRenderer) UpdateBuffers(buffers *gpu.SharedGPUBuffers) error {
 	// Update voxel data
 	gl.BindBuffer(gl.SHADER_STORAGE_BUFFER, r.voxelSSBO)
 	if len(buffers.VoxelData) > 0 {
 		voxelSize := len(buffers.VoxelData) * int(unsafe.Sizeof(gpu.GPUVoxelMaterial{}))
 		gl.BufferSubData(gl.SHADER_STORAGE_BUFFER, 0, voxelSize, unsafe.Pointer(&buffers.VoxelData[0]))
 	}
+	return checkGLError("buffer update")
 }
 
 // SetOptimizedBuffers uses optimized GPU buffer manager instead of copying data
@@ -316,18 +563,84 @@ func (r *VoxelRenderer) SetOptimizedBuffers(mgr *gpu.WindowsGPUBufferManager) {
 }
 
 // UpdateVoxelTextures updates the voxel textures from planet data
-func (r *VoxelRenderer) UpdateVoxelTextures(planet *core.VoxelPlanet) {
+func (r *VoxelRenderer) UpdateVoxelTextures(planet *core.VoxelPlanet) error {
+	if r.voxelTextures == nil {
+		return nil
+	}
+	r.voxelTextures.UpdateFromPlanet(planet)
+	r.updateRivers(planet, false)
+	r.updatePlateTracks(planet, false)
+	r.markTextureUpload(time.Now())
+	r.planetShellCount = int32(len(planet.Shells))
+	r.flattening = float32(planet.Flattening)
+	return checkGLError("texture upload")
+}
+
+// SetTextureUploadMode selects how voxel data is streamed into the textures
+func (r *VoxelRenderer) SetTextureUploadMode(mode textures.UploadMode) {
 	if r.voxelTextures != nil {
-		r.voxelTextures.UpdateFromPlanet(planet)
-		r.planetShellCount = int32(len(planet.Shells))
+		r.voxelTextures.SetUploadMode(mode)
+	}
+}
+
+// UsingMappedTextureUpload reports whether textures stream through mapped buffers
+func (r *VoxelRenderer) UsingMappedTextureUpload() bool {
+	return r.voxelTextures != nil && r.voxelTextures.UsingMappedUpload()
+}
+
+// TextureUploadTime returns how long the last voxel texture update took
+func (r *VoxelRenderer) TextureUploadTime() time.Duration {
+	if r.voxelTextures == nil {
+		return 0
+	}
+	return r.voxelTextures.LastUploadTime
+}
+
+// VerifyTextureUpload checks the uploaded textures match the copy path
+func (r *VoxelRenderer) VerifyTextureUpload(planet *core.VoxelPlanet) error {
+	if r.voxelTextures == nil {
+		return nil
+	}
+	if err := r.voxelTextures.VerifyUpload(planet); err != nil {
+		return err
 	}
+	return checkGLError("texture readback")
 }
 
 // Render performs one frame of voxel rendering
-func (r *VoxelRenderer) Render() {
-	// Check for OpenGL errors
-	if err := gl.GetError(); err != gl.NO_ERROR {
-		fmt.Printf("OpenGL error before render: 0x%x\n", err)
+func (r *VoxelRenderer) Render() error {
+	// Errors pending here come from GL calls made outside the renderer
+	// (buffer sync, compute dispatch) - report them but still draw the frame
+	preErr := checkGLError("pre-render")
+
+	r.updateAutoOrbit()
+	r.updateSpin()
+
+	// Supersampling ray marches into a larger offscreen image first
+	var viewport [4]int32
+	gl.GetIntegerv(gl.VIEWPORT, &viewport[0])
+	supersampling := false
+	if r.ssaa != nil {
+		if err := r.bindSupersampleTarget(viewport[2], viewport[3]); err != nil {
+			fmt.Printf("⚠️  Supersampling disabled: %v\n", err)
+			r.releaseSupersampling()
+		} else {
+			supersampling = true
+		}
+	}
+	
+
+	// Depth of field ray marches at the same resolution into its own target,
+	// which also keeps each pixel's distance for the blur
+	depthOfField := false
+	if r.dof != nil && !r.mapView() {
+		factor := int32(r.Supersampling())
+		if err := r.bindDepthOfFieldTarget(viewport[2]*factor, viewport[3]*factor); err != nil {
+			fmt.Printf("⚠️  Depth of field disabled: %v\n", err)
+			r.releaseDepthOfField()
+		} else {
+			depthOfField = true
+		}
 	}
 	
 	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
@@ -337,10 +650,21 @@ func (r *VoxelRenderer) Render() {
 	gl.UseProgram(r.shaderProgram)
 
 	// Set uniforms
-	invViewProj := r.projMatrix.Mul4(r.viewMatrix).Inv()
+	// Rays are marched in planet coordinates, so the spin and tilt fold into the camera
+	invViewProj := r.viewProj().Inv()
+	cameraPos := r.planetCameraPos()
 	gl.UniformMatrix4fv(gl.GetUniformLocation(r.shaderProgram, gl.Str("invViewProj\x00")), 1, false, &invViewProj[0])
-	gl.Uniform3fv(gl.GetUniformLocation(r.shaderProgram, gl.Str("cameraPos\x00")), 1, &r.cameraPos[0])
+	gl.Uniform3fv(gl.GetUniformLocation(r.shaderProgram, gl.Str("cameraPos\x00")), 1, &cameraPos[0])
 	gl.Uniform1f(gl.GetUniformLocation(r.shaderProgram, gl.Str("planetRadius\x00")), r.planetRadius)
+	gl.Uniform1f(gl.GetUniformLocation(r.shaderProgram, gl.Str("flattening\x00")), r.flattening)
+	gl.Uniform1f(gl.GetUniformLocation(r.shaderProgram, gl.Str("stepScale\x00")), r.rayStepScale())
+
+	// Map projection
+	gl.Uniform1i(gl.GetUniformLocation(r.shaderProgram, gl.Str("mapProjection\x00")), int32(r.MapProjection))
+	gl.Uniform1f(gl.GetUniformLocation(r.shaderProgram, gl.Str("mapCenterLon\x00")), float32(r.mapCenterLon))
+	gl.Uniform1f(gl.GetUniformLocation(r.shaderProgram, gl.Str("mapPan\x00")), float32(r.mapPan))
+	gl.Uniform1f(gl.GetUniformLocation(r.shaderProgram, gl.Str("mapZoom\x00")), float32(math.Max(r.mapZoom, minMapZoom)))
+	gl.Uniform1f(gl.GetUniformLocation(r.shaderProgram, gl.Str("viewportAspect\x00")), float32(r.width)/float32(r.height))
 	
 	// Debug render mode
 	renderModeLoc := gl.GetUniformLocation(r.shaderProgram, gl.Str("renderMode\x00"))
@@ -365,6 +689,52 @@ func (r *VoxelRenderer) Render() {
 	}
 	gl.Uniform1i(gl.GetUniformLocation(r.shaderProgram, gl.Str("shellCount\x00")), shellCount)
 	
+	// Graticule uniforms
+	showGraticuleInt := int32(0)
+	if r.showGraticule {
+		showGraticuleInt = 1
+	}
+	gl.Uniform1i(gl.GetUniformLocation(r.shaderProgram, gl.Str("showGraticule\x00")), showGraticuleInt)
+	gl.Uniform1f(gl.GetUniformLocation(r.shaderProgram, gl.Str("graticuleSpacing\x00")), r.GraticuleSpacing)
+	gl.Uniform3fv(gl.GetUniformLocation(r.shaderProgram, gl.Str("graticuleColor\x00")), 1, &r.GraticuleColor[0])
+
+	// River uniforms
+	showRiversInt := int32(0)
+	if r.showRivers {
+		showRiversInt = 1
+	}
+	gl.Uniform1i(gl.GetUniformLocation(r.shaderProgram, gl.Str("showRivers\x00")), showRiversInt)
+	gl.Uniform3fv(gl.GetUniformLocation(r.shaderProgram, gl.Str("riverColor\x00")), 1, &r.RiverColor[0])
+
+	// Plate track uniform
+	showPlateTracksInt := int32(0)
+	if r.showPlateTracks {
+		showPlateTracksInt = 1
+	}
+	gl.Uniform1i(gl.GetUniformLocation(r.shaderProgram, gl.Str("showPlateTracks\x00")), showPlateTracksInt)
+
+	// Voxel grid uniform
+	showShellGridInt := int32(0)
+	if r.showShellGrid {
+		showShellGridInt = 1
+	}
+	gl.Uniform1i(gl.GetUniformLocation(r.shaderProgram, gl.Str("showShellGrid\x00")), showShellGridInt)
+
+	// Material colors from the palette, including runtime-registered materials
+	materialColors := r.materialColorUniform()
+	gl.Uniform3fv(gl.GetUniformLocation(r.shaderProgram, gl.Str("materialColors\x00")), int32(core.MaterialCount()), &materialColors[0])
+	gl.Uniform1i(gl.GetUniformLocation(r.shaderProgram, gl.Str("materialCount\x00")), int32(core.MaterialCount()))
+	r.bindColormaps()
+	r.bindPlateColors()
+
+	// Ocean uniforms
+	gl.Uniform3fv(gl.GetUniformLocation(r.shaderProgram, gl.Str("oceanShallowColor\x00")), 1, &r.OceanShallowColor[0])
+	gl.Uniform3fv(gl.GetUniformLocation(r.shaderProgram, gl.Str("oceanDeepColor\x00")), 1, &r.OceanDeepColor[0])
+	gl.Uniform1f(gl.GetUniformLocation(r.shaderProgram, gl.Str("oceanTransparency\x00")), r.OceanTransparency)
+
+	// Lava glow
+	gl.Uniform1f(gl.GetUniformLocation(r.shaderProgram, gl.Str("emissiveScale\x00")), r.EmissiveScale)
+
 	// Add time uniform
 	gl.Uniform1f(gl.GetUniformLocation(r.shaderProgram, gl.Str("time\x00")), float32(glfw.GetTime()))
 
@@ -376,6 +746,16 @@ func (r *VoxelRenderer) Render() {
 		gl.Uniform1i(gl.GetUniformLocation(r.shaderProgram, gl.Str("temperatureTexture\x00")), 1)
 		gl.Uniform1i(gl.GetUniformLocation(r.shaderProgram, gl.Str("velocityTexture\x00")), 2)
 		gl.Uniform1i(gl.GetUniformLocation(r.shaderProgram, gl.Str("shellInfoTexture\x00")), 3)
+		gl.Uniform1i(gl.GetUniformLocation(r.shaderProgram, gl.Str("heatFluxTexture\x00")), 4)
+		gl.Uniform1i(gl.GetUniformLocation(r.shaderProgram, gl.Str("prevTemperatureTexture\x00")), 5)
+		gl.Uniform1i(gl.GetUniformLocation(r.shaderProgram, gl.Str("prevVelocityTexture\x00")), 6)
+		gl.Uniform1i(gl.GetUniformLocation(r.shaderProgram, gl.Str("lonCountTexture\x00")), 7)
+		gl.Uniform1i(gl.GetUniformLocation(r.shaderProgram, gl.Str("riverTexture\x00")), 9)
+		gl.Uniform1i(gl.GetUniformLocation(r.shaderProgram, gl.Str("biomeTexture\x00")), 10)
+		gl.Uniform1i(gl.GetUniformLocation(r.shaderProgram, gl.Str("plateTrackTexture\x00")), plateTrackTextureUnit)
+		gl.Uniform3fv(gl.GetUniformLocation(r.shaderProgram, gl.Str("biomeColors\x00")), int32(len(BiomeColors)), &BiomeColors[0][0])
+		gl.Uniform1f(gl.GetUniformLocation(r.shaderProgram, gl.Str("interpolation\x00")), r.interpolationFraction(time.Now()))
+		gl.Uniform2f(gl.GetUniformLocation(r.shaderProgram, gl.Str("heatFluxRange\x00")), r.voxelTextures.HeatFluxMin, r.voxelTextures.HeatFluxMax)
 		
 		// Debug: Add a debug value uniform
 		gl.Uniform1f(gl.GetUniformLocation(r.shaderProgram, gl.Str("debugValue\x00")), float32(glfw.GetTime()))
@@ -387,18 +767,58 @@ func (r *VoxelRenderer) Render() {
 	gl.BindVertexArray(r.quadVAO)
 	gl.DrawArrays(gl.TRIANGLE_STRIP, 0, 4)
 	
-	// Check for errors after draw
-	if err := gl.GetError(); err != gl.NO_ERROR {
-		fmt.Printf("OpenGL error after draw: 0x%x\n", err)
+	// Blur into the supersampled image or straight into the window
+	if depthOfField {
+		if supersampling {
+			gl.BindFramebuffer(gl.FRAMEBUFFER, r.ssaa.fbo)
+			gl.Viewport(0, 0, r.ssaa.width, r.ssaa.height)
+		} else {
+			gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+			gl.Viewport(viewport[0], viewport[1], viewport[2], viewport[3])
+		}
+		r.applyDepthOfField(float32(r.Supersampling()))
+	}
+
+	// Average the supersampled image down to the window
+	if supersampling {
+		r.resolveSupersampleTarget(viewport[2], viewport[3])
 	}
 	
+	// Check for errors after draw
+	drawErr := checkGLError("draw")
+
 
 	// Render stats overlay if enabled
 	if r.showStats {
 		r.RenderFullscreenStats()
 	}
+	if r.columnOpen {
+		r.RenderColumn()
+	}
+	r.RenderPlateStats()
+	if r.showHelp {
+		r.RenderHelp()
+	}
+
+	// Capture before swapping so the back buffer still holds this frame
+	if r.screenshotRequested {
+		r.screenshotRequested = false
+		if err := r.saveScreenshot(); err != nil {
+			fmt.Printf("❌ Screenshot failed: %v\n", err)
+		}
+	}
+	if r.recorder != nil {
+		if err := r.recordFrame(); err != nil {
+			fmt.Printf("❌ Recording frame failed: %v\n", err)
+		}
+	}
 
 	r.window.SwapBuffers()
+
+	if preErr != nil {
+		return preErr
+	}
+	return drawErr
 }
 
 // updateMatrices updates view and projection matrices
@@ -420,9 +840,8 @@ func (r *VoxelRenderer) updateMatrices() {
 		mgl32.Vec3{0, 1, 0},
 	)
 
-	// Projection matrix - adjust near/far planes for planet scale
-	aspect := float32(r.width) / float32(r.height)
-	r.projMatrix = mgl32.Perspective(mgl32.DegToRad(45.0), aspect, 1000.0, 100000000.0)
+	// Projection matrix - perspective or orthographic (see projection)
+	r.projMatrix = r.projection()
 }
 
 // Event handlers
@@ -434,119 +853,24 @@ func (r *VoxelRenderer) onResize(width, height int) {
 }
 
 func (r *VoxelRenderer) onKey(key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
-	if action != glfw.Press {
+	if action == glfw.Release {
 		return
 	}
 
-	switch key {
-	case glfw.KeyEscape:
-		r.window.SetShouldClose(true)
-	case glfw.KeyF1:
-		// Toggle stats overlay
-		r.showStats = !r.showStats
-		if r.showStats {
-			fmt.Println("Stats overlay: ON")
-		} else {
-			fmt.Println("Stats overlay: OFF")
-		}
-	case glfw.KeyB:
-		// Toggle boundary highlighting
-		r.highlightBoundaries = !r.highlightBoundaries
-		if r.highlightBoundaries {
-			fmt.Println("Plate boundaries highlighted")
-		} else {
-			fmt.Println("Plate boundaries normal")
-		}
-	case glfw.KeyX:
-		r.crossSection = !r.crossSection
-		r.crossSectionAxis = 0
-	case glfw.KeyY:
-		r.crossSection = !r.crossSection
-		r.crossSectionAxis = 1
-	case glfw.KeyZ:
-		r.crossSection = !r.crossSection
-		r.crossSectionAxis = 2
-	case glfw.Key0:
-		r.SpeedMultiplier = 1.0
-		fmt.Println("Time speed reset to 1x")
-	case glfw.Key1:
-		if mods&glfw.ModShift != 0 {
-			// Shift+1 = 10x speed
-			r.SpeedMultiplier = 10.0
-			fmt.Printf("Time speed: %.0fx\n", r.SpeedMultiplier)
-		} else {
-			// Normal 1 = material view
-			r.RenderMode = 0
-			fmt.Println("Switched to material view")
-		}
-	case glfw.Key2:
-		if mods&glfw.ModShift != 0 {
-			// Shift+2 = 100x speed
-			r.SpeedMultiplier = 100.0
-			fmt.Printf("Time speed: %.0fx\n", r.SpeedMultiplier)
-		} else {
-			// Normal 2 = temperature view
-			r.RenderMode = 1
-			fmt.Println("Switched to temperature view")
-		}
-	case glfw.Key3:
-		if mods&glfw.ModShift != 0 {
-			// Shift+3 = 1000x speed
-			r.SpeedMultiplier = 1000.0
-			fmt.Printf("Time speed: %.0fx\n", r.SpeedMultiplier)
-		} else {
-			// Normal 3 = velocity view
-			r.RenderMode = 2
-			fmt.Println("Switched to velocity view")
-		}
-	case glfw.Key4:
-		if mods&glfw.ModShift != 0 {
-			// Shift+4 = 10000x speed
-			r.SpeedMultiplier = 10000.0
-			fmt.Printf("Time speed: %.0fx\n", r.SpeedMultiplier)
-		} else {
-			// Normal 4 = age view
-			r.RenderMode = 3
-			fmt.Println("Switched to age view")
-		}
-	case glfw.Key5:
-		if mods&glfw.ModShift != 0 {
-			// Shift+5 = 100000x speed
-			r.SpeedMultiplier = 100000.0
-			fmt.Printf("Time speed: %.0fx (continents should move visibly!)\n", r.SpeedMultiplier)
-		} else {
-			// Normal 5 = plate view
-			r.RenderMode = 4
-			r.ShowPlates = true
-			fmt.Println("Switched to plate tectonics view")
-			fmt.Println("Click on plates to see their information")
-		}
-	case glfw.Key6:
-		// 6 = stress view
-		r.RenderMode = 5
-		fmt.Println("Switched to stress visualization")
-		fmt.Println("Red = high stress/velocity, Blue = low stress")
-	case glfw.Key7:
-		// 7 = sub-position view
-		r.RenderMode = 6
-		fmt.Println("Switched to sub-position visualization")
-		fmt.Println("Shows sub-cell positions: Red=lon, Green=lat, Blue=magnitude")
-	case glfw.Key8:
-		// 8 = elevation/altitude view
-		r.RenderMode = 7
-		fmt.Println("Switched to elevation visualization")
-		fmt.Println("Blue=ocean trenches, Green=lowlands, Yellow=highlands, Red=mountains, White=peaks")
-	case glfw.KeyP:
-		r.Paused = !r.Paused
-		if r.Paused {
-			fmt.Println("Simulation PAUSED")
-		} else {
-			fmt.Println("Simulation RESUMED")
-		}
+	bound, chord := r.keys.Lookup(key, mods)
+	if bound == nil || (action == glfw.Repeat && !bound.Repeat) {
+		return
 	}
+	bound.Handler(r, chord)
 }
 
 func (r *VoxelRenderer) onScroll(xoff, yoff float64) {
+	r.markCameraInput()
+	if r.mapView() {
+		r.zoomMap(yoff)
+		return
+	}
+
 	// Zoom camera
 	zoom := float32(1.0 - yoff*0.1) // Inverted for natural scrolling
 	dist := r.cameraPos.Len() * zoom
@@ -565,20 +889,39 @@ func (r *VoxelRenderer) onMouseButton(button glfw.MouseButton, action glfw.Actio
 	if button == glfw.MouseButtonLeft {
 		if action == glfw.Press {
 			r.MouseDown = true
+			r.markCameraInput()
 			r.lastMouseX, r.lastMouseY = r.window.GetCursorPos()
+			r.pressX, r.pressY = r.lastMouseX, r.lastMouseY
 
-			// Check for plate selection in plate mode
-			if r.RenderMode == 4 && r.PlanetRef != nil {
+			// Check for plate selection in plate mode, or a voxel on the
+			// cross-section
+			if (r.RenderMode == 4 || r.crossSection) && r.PlanetRef != nil {
 				r.HandleMouseClick(r.lastMouseX, r.lastMouseY, r.PlanetRef.(*core.VoxelPlanet))
 			}
 		} else if action == glfw.Release {
 			r.MouseDown = false
+
+			// A click without dragging focuses there and inspects the column under the cursor
+			x, y := r.window.GetCursorPos()
+			if math.Abs(x-r.pressX) <= columnClickSlop && math.Abs(y-r.pressY) <= columnClickSlop {
+				r.focusOn(x, y)
+				if r.RenderMode != 4 {
+					r.inspectColumn(x, y)
+				}
+			}
 		}
 	}
 }
 
 // onMouseMove handles mouse movement
 func (r *VoxelRenderer) onMouseMove(xpos, ypos float64) {
+	if r.MouseDown && r.mapView() {
+		r.panMap(xpos-r.lastMouseX, ypos-r.lastMouseY)
+		r.lastMouseX = xpos
+		r.lastMouseY = ypos
+		r.markCameraInput()
+		return
+	}
 	if r.MouseDown {
 		dx := float32(xpos - r.lastMouseX)
 		dy := float32(ypos - r.lastMouseY)
@@ -624,6 +967,7 @@ func (r *VoxelRenderer) onMouseMove(xpos, ypos float64) {
 		r.lastMouseX = xpos
 		r.lastMouseY = ypos
 
+		r.markCameraInput()
 		r.updateMatrices()
 	}
 }
@@ -641,9 +985,17 @@ func (r *VoxelRenderer) PollEvents() {
 
 // Terminate cleans up OpenGL resources
 func (r *VoxelRenderer) Terminate() {
+	r.screenshots.Wait()
+	r.StopRecording()
 	if r.voxelTextures != nil {
 		r.voxelTextures.Cleanup()
 	}
+	r.releaseSupersampling()
+	r.releaseDepthOfField()
+	gl.DeleteTextures(1, &r.colormapTexture)
+	if r.plateHueTexture != 0 {
+		gl.DeleteTextures(1, &r.plateHueTexture)
+	}
 	gl.DeleteProgram(r.shaderProgram)
 	gl.DeleteVertexArrays(1, &r.quadVAO)
 	gl.DeleteBuffers(1, &r.voxelSSBO)
@@ -0,0 +1,93 @@
+package opengl
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/go-gl/gl/v4.3-core/gl"
+	"github.com/go-gl/mathgl/mgl32"
+
+	"worldgenerator/core"
+	"worldgenerator/gpu"
+)
+
+// benchmarkRenderer opens a 1280x720 renderer drawing a freshly built planet,
+// skipping the benchmark without a display. It is closed when b finishes
+func benchmarkRenderer(b *testing.B) *VoxelRenderer {
+	b.Helper()
+	if runtime.GOOS == "linux" && os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+		b.Skip("no display")
+	}
+	r, err := NewVoxelRenderer(1280, 720)
+	if err != nil {
+		b.Skipf("no renderer: %v", err)
+	}
+	b.Cleanup(r.Terminate)
+
+	planet := core.CreateVoxelPlanet(6371000.0, 10)
+	buffers := gpu.NewSharedGPUBuffers(planet)
+	buffers.UpdateFromPlanet(planet)
+	if err := r.CreateBuffers(buffers); err != nil {
+		b.Fatal(err)
+	}
+	if err := r.UpdateVoxelTextures(planet); err != nil {
+		b.Fatal(err)
+	}
+	return r
+}
+
+// renderFrames draws b.N frames, waits for the GPU to finish them and
+// reports the frame rate
+func renderFrames(b *testing.B, r *VoxelRenderer) {
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := r.Render(); err != nil {
+			b.Fatal(err)
+		}
+	}
+	gl.Finish()
+	b.StopTimer()
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "fps")
+}
+
+// BenchmarkSupersampling measures a frame at each supersampling factor, the
+// cost of the offscreen ray march and downsample against drawing straight to
+// the window
+func BenchmarkSupersampling(b *testing.B) {
+	r := benchmarkRenderer(b)
+
+	for factor := 1; factor <= MaxSupersampling; factor *= 2 {
+		b.Run(fmt.Sprintf("%dx", factor), func(b *testing.B) {
+			if err := r.SetSupersampling(factor); err != nil {
+				b.Fatal(err)
+			}
+			renderFrames(b, r)
+		})
+	}
+}
+
+// BenchmarkRayStepZoom measures the frame rate from just above the surface
+// out to far away, with the step scaled to the camera distance and with the
+// constant 0.01 step the ray march used before. The scaled step is the same
+// at three radii, so the two only differ nearer and farther out
+func BenchmarkRayStepZoom(b *testing.B) {
+	r := benchmarkRenderer(b)
+
+	for _, radii := range []float32{1.2, 1.5, 3, 6, 10} {
+		for _, step := range []struct {
+			name  string
+			fixed float32
+		}{
+			{"scaled", 0},
+			{"fixed", 0.01},
+		} {
+			b.Run(fmt.Sprintf("%gR/%s", radii, step.name), func(b *testing.B) {
+				r.cameraPos = mgl32.Vec3{0, 0, r.planetRadius * radii}
+				r.fixedRayStep = step.fixed
+				renderFrames(b, r)
+			})
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package opengl
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+
+	"worldgenerator/core"
+)
+
+// BiomeColors is the biome view's color for each core.Biome, in biome order
+var BiomeColors = [core.BiomeCount]mgl32.Vec3{
+	core.BiomeNone:               {0.1, 0.1, 0.1},
+	core.BiomeOcean:              {0.1, 0.25, 0.6},
+	core.BiomeIce:                {0.95, 0.97, 1.0},
+	core.BiomeTundra:             {0.6, 0.65, 0.55},
+	core.BiomeBorealForest:       {0.1, 0.35, 0.25},
+	core.BiomeTemperateForest:    {0.2, 0.6, 0.2},
+	core.BiomeGrassland:          {0.65, 0.75, 0.35},
+	core.BiomeDesert:             {0.9, 0.8, 0.5},
+	core.BiomeSavanna:            {0.8, 0.65, 0.25},
+	core.BiomeTropicalRainforest: {0.0, 0.4, 0.1},
+	core.BiomeAlpine:             {0.55, 0.45, 0.45},
+}
+
+// biomeLegendText describes the biome view's colors
+const biomeLegendText = "Blue=ocean, White=ice, Grey-green=tundra, Dark green=boreal forest, Green=temperate forest, " +
+	"Light green=grassland, Tan=desert, Ochre=savanna, Deep green=rainforest, Mauve=alpine"
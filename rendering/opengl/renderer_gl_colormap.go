@@ -0,0 +1,45 @@
+package opengl
+
+import (
+	"github.com/go-gl/gl/v4.3-core/gl"
+
+	"worldgenerator/core"
+)
+
+// colormapResolution is how many samples of each colormap the shader
+// interpolates between, fine enough that hard edges like the coastline stay
+// within a few meters of elevation
+const colormapResolution = 1024
+
+// colormapTextureUnit follows the voxel textures bound by VoxelTextureData.Bind
+const colormapTextureUnit = 8
+
+// createColormapTexture bakes core.Colormaps into a texture with one row per
+// colormap, sampled with the same core.Colormap.Color that draws legends
+func (r *VoxelRenderer) createColormapTexture() {
+	texels := make([]float32, 0, len(core.Colormaps)*colormapResolution*3)
+	r.colormapRanges = r.colormapRanges[:0]
+	for _, colormap := range core.Colormaps {
+		texels = append(texels, colormap.Table(colormapResolution)...)
+		min, max := colormap.Range()
+		r.colormapRanges = append(r.colormapRanges, float32(min), float32(max))
+	}
+
+	gl.GenTextures(1, &r.colormapTexture)
+	gl.BindTexture(gl.TEXTURE_2D, r.colormapTexture)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGB32F, colormapResolution, int32(len(core.Colormaps)), 0, gl.RGB, gl.FLOAT, gl.Ptr(texels))
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+	gl.BindTexture(gl.TEXTURE_2D, 0)
+}
+
+// bindColormaps binds the colormap texture and the value range of each row
+func (r *VoxelRenderer) bindColormaps() {
+	gl.ActiveTexture(gl.TEXTURE0 + colormapTextureUnit)
+	gl.BindTexture(gl.TEXTURE_2D, r.colormapTexture)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.Uniform1i(gl.GetUniformLocation(r.shaderProgram, gl.Str("colormapTexture\x00")), colormapTextureUnit)
+	gl.Uniform2fv(gl.GetUniformLocation(r.shaderProgram, gl.Str("colormapRanges\x00")), int32(len(core.Colormaps)), &r.colormapRanges[0])
+}
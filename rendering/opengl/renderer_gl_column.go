@@ -0,0 +1,60 @@
+package opengl
+
+import (
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"worldgenerator/core"
+	"worldgenerator/rendering/opengl/overlay"
+)
+
+// columnClickSlop is how far in pixels the cursor may move between press and
+// release for the release to count as a click rather than a camera drag
+const columnClickSlop = 4.0
+
+// inspectColumn opens the column panel for the surface point under the
+// cursor, or closes it when the click misses the planet
+func (r *VoxelRenderer) inspectColumn(xpos, ypos float64) {
+	hitPoint, hit := r.pickSurface(xpos, ypos)
+	if !hit {
+		if r.columnOpen {
+			r.columnOpen = false
+			fmt.Println("Column panel closed")
+		}
+		return
+	}
+
+	r.columnLat, r.columnLon = surfaceLatLon(hitPoint, r.flattening)
+	r.columnOpen = true
+	fmt.Printf("Inspecting column at %.1f°, %.1f°\n", r.columnLat, r.columnLon)
+}
+
+// columnLayers describes the inspected column from the top shell down
+func (r *VoxelRenderer) columnLayers(planet *core.VoxelPlanet) []overlay.ColumnLayer {
+	column := planet.Column(r.columnLat, r.columnLon)
+	layers := make([]overlay.ColumnLayer, 0, len(column))
+	for i := len(column) - 1; i >= 0; i-- {
+		shell := &planet.Shells[i]
+		voxel := column[i]
+		color := core.MaterialColor(voxel.Type)
+		layers = append(layers, overlay.ColumnLayer{
+			Depth:       float32((planet.Radius - (shell.InnerRadius+shell.OuterRadius)/2) / 1000),
+			Temperature: voxel.Temperature,
+			Material:    core.MaterialName(voxel.Type),
+			Color:       mgl32.Vec4{float32(color.X), float32(color.Y), float32(color.Z), 1},
+		})
+	}
+	return layers
+}
+
+// RenderColumn draws the inspected column's temperature and material
+// against depth, refreshed every frame as the simulation runs
+func (r *VoxelRenderer) RenderColumn() {
+	planet, ok := r.PlanetRef.(*core.VoxelPlanet)
+	if !ok || planet == nil || r.statsOverlay == nil {
+		return
+	}
+	title := fmt.Sprintf("Column %.1f°, %.1f° (click off planet to close)", r.columnLat, r.columnLon)
+	r.statsOverlay.RenderColumn(title, r.columnLayers(planet))
+}
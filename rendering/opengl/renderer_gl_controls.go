@@ -0,0 +1,537 @@
+package opengl
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+
+	"worldgenerator/core"
+	"worldgenerator/rendering/opengl/overlay"
+)
+
+// renderModeNames names each RenderMode, in mode order
+var renderModeNames = []string{
+	"Material", "Temperature", "Velocity", "Age", "Plates",
+	"Stress", "Sub-position", "Elevation", "Heat flux", "Biomes",
+}
+
+// mouseControls are listed with the key bindings but handled by the mouse
+// callbacks
+var mouseControls = []overlay.HelpEntry{
+	{Keys: "Mouse drag", Action: "Rotate, or scroll the map"},
+	{Keys: "Mouse click", Action: "Select a plate and show its stats in plate view, otherwise inspect the column; focus depth of field there"},
+	{Keys: "Scroll", Action: "Zoom in/out"},
+}
+
+// chords returns an unmodified chord for each key
+func chords(keys ...glfw.Key) []KeyChord {
+	c := make([]KeyChord, len(keys))
+	for i, key := range keys {
+		c[i] = KeyChord{Key: key}
+	}
+	return c
+}
+
+// shifted returns a Shift chord for each key
+func shifted(keys ...glfw.Key) []KeyChord {
+	c := chords(keys...)
+	for i := range c {
+		c[i].Mods = glfw.ModShift
+	}
+	return c
+}
+
+// onOff returns on when the setting is enabled and off otherwise
+func onOff(enabled bool, on, off string) string {
+	if enabled {
+		return on
+	}
+	return off
+}
+
+// whilePaused runs step only when the simulation is paused
+func whilePaused(r *VoxelRenderer, step func()) {
+	if r.Paused {
+		step()
+	} else {
+		fmt.Println("Pause (P) before stepping")
+	}
+}
+
+// defaultKeyActions are the built-in controls, in the order help lists them
+func defaultKeyActions() []KeyAction {
+	return []KeyAction{
+		{
+			Name:        "1-9",
+			Description: "Change visualization",
+			Keys:        chords(glfw.Key1, glfw.Key2, glfw.Key3, glfw.Key4, glfw.Key5, glfw.Key6, glfw.Key7, glfw.Key8, glfw.Key9),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				r.setRenderMode(int32(chord.Key - glfw.Key1))
+			},
+			State: func(r *VoxelRenderer) string {
+				if int(r.RenderMode) < len(renderModeNames) {
+					return renderModeNames[r.RenderMode]
+				}
+				return fmt.Sprintf("mode %d", r.RenderMode)
+			},
+		},
+		{
+			Description: "Biome view",
+			Keys:        chords(glfw.KeyK),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				r.setRenderMode(9)
+			},
+		},
+		{
+			Name:        "Shift+1-5",
+			Description: "Time speed 10x, 100x, 1000x, 10000x, 100000x",
+			Keys:        shifted(glfw.Key1, glfw.Key2, glfw.Key3, glfw.Key4, glfw.Key5),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				r.SpeedMultiplier = float32(math.Pow(10, float64(chord.Key-glfw.Key0)))
+				if chord.Key == glfw.Key5 {
+					fmt.Printf("Time speed: %.0fx (continents should move visibly!)\n", r.SpeedMultiplier)
+				} else {
+					fmt.Printf("Time speed: %.0fx\n", r.SpeedMultiplier)
+				}
+			},
+			State: func(r *VoxelRenderer) string {
+				return fmt.Sprintf("%.0fx", r.SpeedMultiplier)
+			},
+		},
+		{
+			Description: "Reset time speed to 1x",
+			Keys:        chords(glfw.Key0),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				r.SpeedMultiplier = 1.0
+				fmt.Println("Time speed reset to 1x")
+			},
+		},
+		{
+			Description: "Pause/unpause simulation",
+			Keys:        chords(glfw.KeyP),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				r.Paused = !r.Paused
+				if r.Paused {
+					fmt.Println("Simulation PAUSED")
+				} else {
+					fmt.Println("Simulation RESUMED")
+				}
+			},
+			State: func(r *VoxelRenderer) string {
+				return onOff(r.Paused, "paused", "running")
+			},
+		},
+		{
+			Description: "Advance one physics step while paused",
+			Keys:        chords(glfw.KeyPeriod),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				whilePaused(r, func() { r.StepRequested = true })
+			},
+		},
+		{
+			Description: "Advance -step-years while paused",
+			Keys:        chords(glfw.KeyN),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				whilePaused(r, func() { r.StepYearsRequested = true })
+			},
+		},
+		{
+			Description: "Step through snapshots while paused (with -snapshots)",
+			Keys:        chords(glfw.KeyLeft, glfw.KeyRight),
+			Repeat:      true, // Holding an arrow key scrubs continuously
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				if !r.Paused {
+					fmt.Println("Pause (P) before scrubbing snapshots")
+				} else if chord.Key == glfw.KeyLeft {
+					r.ScrubSteps--
+				} else {
+					r.ScrubSteps++
+				}
+			},
+		},
+		{
+			Description: "Toggle cross-section along an axis (click the cut to print a voxel)",
+			Keys:        chords(glfw.KeyX, glfw.KeyY, glfw.KeyZ),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				r.crossSection = !r.crossSection
+				r.crossSectionAxis = int32(chord.Key - glfw.KeyX)
+			},
+			State: func(r *VoxelRenderer) string {
+				if !r.crossSection {
+					return "off"
+				}
+				return string(rune('X' + r.crossSectionAxis))
+			},
+		},
+		{
+			Description: "Toggle lat/lon grid",
+			Keys:        chords(glfw.KeyG),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				r.showGraticule = !r.showGraticule
+				if r.showGraticule {
+					fmt.Printf("Graticule: ON (every %.0f°)\n", r.GraticuleSpacing)
+				} else {
+					fmt.Println("Graticule: OFF")
+				}
+			},
+			State: func(r *VoxelRenderer) string {
+				return onOff(r.showGraticule, "on", "off")
+			},
+		},
+		{
+			Description: "Toggle river overlay",
+			Keys:        chords(glfw.KeyL),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				r.showRivers = !r.showRivers
+				if r.showRivers {
+					fmt.Println("Rivers: ON")
+					if planet, ok := r.PlanetRef.(*core.VoxelPlanet); ok {
+						r.updateRivers(planet, true)
+					}
+				} else {
+					fmt.Println("Rivers: OFF")
+				}
+			},
+			State: func(r *VoxelRenderer) string {
+				return onOff(r.showRivers, "on", "off")
+			},
+		},
+		{
+			Description: "Toggle plate drift tracks",
+			Keys:        chords(glfw.KeyT),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				r.showPlateTracks = !r.showPlateTracks
+				if r.showPlateTracks {
+					fmt.Println("Plate tracks: ON")
+					if planet, ok := r.PlanetRef.(*core.VoxelPlanet); ok {
+						r.updatePlateTracks(planet, true)
+					}
+				} else {
+					fmt.Println("Plate tracks: OFF")
+				}
+			},
+			State: func(r *VoxelRenderer) string {
+				return onOff(r.showPlateTracks, "on", "off")
+			},
+		},
+		{
+			Description: "Toggle voxel grid (shell boundaries show in cross-section)",
+			Keys:        shifted(glfw.KeyG),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				r.showShellGrid = !r.showShellGrid
+				if r.showShellGrid {
+					fmt.Println("Voxel grid: ON")
+				} else {
+					fmt.Println("Voxel grid: OFF")
+				}
+			},
+			State: func(r *VoxelRenderer) string {
+				return onOff(r.showShellGrid, "on", "off")
+			},
+		},
+		{
+			Description: "Toggle plate boundary highlighting",
+			Keys:        chords(glfw.KeyB),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				r.highlightBoundaries = !r.highlightBoundaries
+				if r.highlightBoundaries {
+					fmt.Println("Plate boundaries highlighted")
+				} else {
+					fmt.Println("Plate boundaries normal")
+				}
+			},
+			State: func(r *VoxelRenderer) string {
+				return onOff(r.highlightBoundaries, "on", "off")
+			},
+		},
+		{
+			Description: "Clearer/murkier ocean in material view",
+			Keys:        append(chords(glfw.KeyO), shifted(glfw.KeyO)...),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				step := float32(0.1)
+				if chord.Mods&glfw.ModShift != 0 {
+					step = -step
+				}
+				r.OceanTransparency = float32(math.Max(0, math.Min(1, float64(r.OceanTransparency+step))))
+				fmt.Printf("Ocean transparency: %.1f\n", r.OceanTransparency)
+			},
+			State: func(r *VoxelRenderer) string {
+				return fmt.Sprintf("%.1f", r.OceanTransparency)
+			},
+		},
+		{
+			Description: "Hold the selected plate still, or release it",
+			Keys:        chords(glfw.KeyH),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				switch {
+				case r.selectedPlateID != 0 && r.selectedPlateID != r.ReferencePlate:
+					r.ReferencePlate = r.selectedPlateID
+					fmt.Printf("Reference frame: plate %d held still\n", r.ReferencePlate)
+				case r.ReferencePlate != 0:
+					r.ReferencePlate = 0
+					fmt.Println("Reference frame: absolute")
+				default:
+					fmt.Println("Click a plate in plate view (5) to hold it still")
+				}
+			},
+			State: func(r *VoxelRenderer) string {
+				if r.ReferencePlate == 0 {
+					return "absolute"
+				}
+				return fmt.Sprintf("plate %d", r.ReferencePlate)
+			},
+		},
+		{
+			Description: "Inject a mantle plume under the cursor, which keeps rising",
+			Keys:        shifted(glfw.KeyH),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				x, y := r.window.GetCursorPos()
+				hitPoint, hit := r.pickSurface(x, y)
+				if !hit {
+					fmt.Println("Point at the planet to place a plume")
+					return
+				}
+				lat, lon := surfaceLatLon(hitPoint, r.flattening)
+				r.PlumeRequests = append(r.PlumeRequests, core.MantlePlume{Lat: lat, Lon: lon, TemperatureBoost: r.PlumeHeat})
+			},
+		},
+		{
+			Description: "Toggle auto-orbit camera",
+			Keys:        chords(glfw.KeyA),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				r.SetAutoOrbit(!r.autoOrbit)
+				if r.autoOrbit {
+					fmt.Printf("Auto-orbit: ON (%.2f°/s)\n", r.AutoOrbitSpeed)
+				} else {
+					fmt.Println("Auto-orbit: OFF")
+				}
+			},
+			State: func(r *VoxelRenderer) string {
+				return onOff(r.autoOrbit, "on", "off")
+			},
+		},
+		{
+			Description: "Slow down/speed up auto-orbit",
+			Keys:        chords(glfw.KeyLeftBracket, glfw.KeyRightBracket),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				if chord.Key == glfw.KeyLeftBracket {
+					r.scaleOrbitSpeed(0.5)
+				} else {
+					r.scaleOrbitSpeed(2.0)
+				}
+			},
+			State: func(r *VoxelRenderer) string {
+				return fmt.Sprintf("%.2f°/s", r.AutoOrbitSpeed)
+			},
+		},
+		{
+			Description: "Narrower/wider field of view",
+			Keys:        chords(glfw.KeyMinus, glfw.KeyEqual),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				if chord.Key == glfw.KeyMinus {
+					r.SetFieldOfView(r.FieldOfView - fieldOfViewStep)
+				} else {
+					r.SetFieldOfView(r.FieldOfView + fieldOfViewStep)
+				}
+				fmt.Printf("Projection: %s\n", r.projectionLabel())
+			},
+			State: func(r *VoxelRenderer) string {
+				return fmt.Sprintf("%.0f°", r.FieldOfView)
+			},
+		},
+		{
+			Description: "Toggle orthographic projection",
+			Keys:        chords(glfw.KeyV),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				r.SetOrthographic(!r.Orthographic)
+				fmt.Printf("Projection: %s\n", r.projectionLabel())
+			},
+			State: func(r *VoxelRenderer) string {
+				return onOff(r.Orthographic, "orthographic", "perspective")
+			},
+		},
+		{
+			Description: "Cycle perspective, orthographic and map projections",
+			Keys:        shifted(glfw.KeyV),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				r.SetProjection((r.Projection() + 1) % viewProjectionCount)
+				fmt.Printf("Projection: %s\n", r.Projection())
+			},
+			State: func(r *VoxelRenderer) string {
+				return r.Projection().String()
+			},
+		},
+		{
+			Description: "Cycle globe, equirectangular and Mollweide map (drag scrolls the map)",
+			Keys:        chords(glfw.KeyM),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				r.SetMapProjection((r.MapProjection + 1) % projectionCount)
+				fmt.Printf("View: %s\n", r.MapProjection)
+			},
+			State: func(r *VoxelRenderer) string {
+				return r.MapProjection.String()
+			},
+		},
+		{
+			Description: "Toggle depth of field (click to focus)",
+			Keys:        chords(glfw.KeyF),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				if err := r.SetDepthOfField(!r.DepthOfField()); err != nil {
+					fmt.Printf("⚠️  %v\n", err)
+					return
+				}
+				fmt.Printf("Depth of field: %s\n", onOff(r.DepthOfField(), "ON", "OFF"))
+			},
+			State: func(r *VoxelRenderer) string {
+				return onOff(r.DepthOfField(), "on", "off")
+			},
+		},
+		{
+			Description: "Cycle supersampling 1x, 2x, 4x for smooth coastlines",
+			Keys:        chords(glfw.KeyS),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				factor := r.Supersampling() * 2
+				if factor > MaxSupersampling {
+					factor = 1
+				}
+				if err := r.SetSupersampling(factor); err != nil {
+					fmt.Printf("⚠️  %v\n", err)
+					return
+				}
+				fmt.Printf("Supersampling: %dx\n", r.Supersampling())
+			},
+			State: func(r *VoxelRenderer) string {
+				return fmt.Sprintf("%dx", r.Supersampling())
+			},
+		},
+		{
+			Description: "Toggle blending between physics updates",
+			Keys:        chords(glfw.KeyI),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				r.InterpolateFrames = !r.InterpolateFrames
+				fmt.Printf("Frame interpolation: %s\n", onOff(r.InterpolateFrames, "ON", "OFF"))
+			},
+			State: func(r *VoxelRenderer) string {
+				return onOff(r.InterpolateFrames, "on", "off")
+			},
+		},
+		{
+			Name:        "R (twice)",
+			Description: "Regenerate the planet with a new seed",
+			Keys:        chords(glfw.KeyR),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				// Throwing the planet away needs a second press to confirm
+				if time.Since(r.regenerateArmedAt) < regenerateConfirmWindow {
+					r.RegenerateRequested = true
+					r.regenerateArmedAt = time.Time{}
+				} else {
+					r.regenerateArmedAt = time.Now()
+					fmt.Printf("Press R again within %.0f seconds to regenerate the planet with a new seed\n", regenerateConfirmWindow.Seconds())
+				}
+			},
+		},
+		{
+			Description: "Toggle stats overlay",
+			Keys:        chords(glfw.KeyF1),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				r.showStats = !r.showStats
+				if r.showStats {
+					fmt.Println("Stats overlay: ON")
+				} else {
+					fmt.Println("Stats overlay: OFF")
+				}
+			},
+			State: func(r *VoxelRenderer) string {
+				return onOff(r.showStats, "on", "off")
+			},
+		},
+		{
+			Description: "Save screenshot",
+			Keys:        chords(glfw.KeyF12),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				r.RequestScreenshot()
+			},
+		},
+		{
+			Name:        "?",
+			Description: "Toggle this help",
+			Keys:        append(shifted(glfw.KeySlash), chords(glfw.KeySlash)...),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				r.showHelp = !r.showHelp
+			},
+		},
+		{
+			Description: "Exit",
+			Keys:        chords(glfw.KeyEscape),
+			Handler: func(r *VoxelRenderer, chord KeyChord) {
+				r.window.SetShouldClose(true)
+			},
+		},
+	}
+}
+
+// setRenderMode switches the visualization and describes the new view
+func (r *VoxelRenderer) setRenderMode(mode int32) {
+	r.RenderMode = mode
+	switch mode {
+	case 0:
+		fmt.Println("Switched to material view")
+	case 1:
+		fmt.Println("Switched to temperature view")
+	case 2:
+		fmt.Println("Switched to velocity view")
+	case 3:
+		fmt.Println("Switched to age view")
+	case 4:
+		r.ShowPlates = true
+		fmt.Println("Switched to plate tectonics view")
+		fmt.Println("Click on plates to see their information")
+	case 5:
+		fmt.Println("Switched to stress visualization")
+		fmt.Println("Orange = high accumulated stress, Blue = low; glowing rock is near failure")
+	case 6:
+		fmt.Println("Switched to sub-position visualization")
+		fmt.Println("Shows sub-cell positions: Red=lon, Green=lat, Blue=magnitude")
+	case 7:
+		fmt.Println("Switched to elevation visualization")
+		fmt.Println("Blue=ocean trenches, Green=lowlands, Yellow=highlands, Red=mountains, White=peaks")
+	case 8:
+		fmt.Println("Switched to heat flux visualization")
+		fmt.Println(r.heatFluxLegendText())
+	case 9:
+		fmt.Println("Switched to biome visualization")
+		fmt.Println(biomeLegendText)
+	}
+}
+
+// helpEntries describes every control with its current state
+func (r *VoxelRenderer) helpEntries() []overlay.HelpEntry {
+	var entries []overlay.HelpEntry
+	for _, a := range r.keys.Actions() {
+		entry := overlay.HelpEntry{Keys: a.KeyLabel(), Action: a.Description}
+		if a.State != nil {
+			entry.State = a.State(r)
+		}
+		entries = append(entries, entry)
+	}
+	return append(entries, mouseControls...)
+}
+
+// ControlsHelp returns one "keys: action" line per control, for printing
+func (r *VoxelRenderer) ControlsHelp() []string {
+	var lines []string
+	for _, e := range r.helpEntries() {
+		lines = append(lines, e.Keys+": "+e.Action)
+	}
+	return lines
+}
+
+// RenderHelp draws the help screen over the frame
+func (r *VoxelRenderer) RenderHelp() {
+	if r.statsOverlay == nil {
+		return
+	}
+	r.statsOverlay.RenderHelp("Controls (? to close)", r.helpEntries())
+}
@@ -0,0 +1,171 @@
+package opengl
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v4.3-core/gl"
+
+	"worldgenerator/rendering/opengl/shaders"
+)
+
+// Depth of field blur radii in window pixels
+const (
+	DefaultAperture     = 8.0 // Far beyond the focus distance
+	maxDepthOfFieldBlur = 12.0
+)
+
+// depthOfFieldTarget is the offscreen framebuffer the planet is ray marched
+// into when depth of field is on: the image plus each pixel's distance to
+// the surface, which the blur pass reads to size its circles of confusion
+type depthOfFieldTarget struct {
+	program         uint32 // Disc blur
+	fbo             uint32
+	colorTexture    uint32
+	distanceTexture uint32
+	width, height   int32
+}
+
+// SetDepthOfField blurs the planet away from a focal plane, for cinematic
+// recordings. Focus sits FocusDistance meters from the camera, or on the
+// nearest surface when that is 0, until a click picks a surface point to
+// follow. Aperture sets how strong the blur gets. It costs a fullscreen pass,
+// so it is off unless enabled
+func (r *VoxelRenderer) SetDepthOfField(enabled bool) error {
+	r.focusTracking = false
+	if !enabled {
+		r.releaseDepthOfField()
+		return nil
+	}
+
+	if r.dof == nil {
+		program, err := shaders.CompileDepthOfFieldShaders()
+		if err != nil {
+			return fmt.Errorf("failed to compile depth of field shader: %v", err)
+		}
+		r.dof = &depthOfFieldTarget{program: program}
+	}
+	return nil
+}
+
+// DepthOfField reports whether depth of field is on
+func (r *VoxelRenderer) DepthOfField() bool {
+	return r.dof != nil
+}
+
+// focusOn makes the depth of field follow the surface under the cursor
+func (r *VoxelRenderer) focusOn(xpos, ypos float64) {
+	if r.dof == nil {
+		return
+	}
+	if point, hit := r.pickSurface(xpos, ypos); hit {
+		r.focusPoint = point
+		r.focusTracking = true
+		fmt.Printf("Focus: %.0f km away\n", r.focusDistance()/1000)
+	}
+}
+
+// focusDistance returns the distance in meters from the camera to the focal
+// plane this frame, following the picked point as the camera moves
+func (r *VoxelRenderer) focusDistance() float32 {
+	camera := r.planetCameraPos()
+	switch {
+	case r.focusTracking:
+		return camera.Sub(r.focusPoint).Len()
+	case r.FocusDistance > 0:
+		return r.FocusDistance
+	}
+	return camera.Len() - r.planetRadius
+}
+
+// bindDepthOfFieldTarget redirects the ray march to the offscreen image and
+// distance buffer, reallocating them at a new size
+func (r *VoxelRenderer) bindDepthOfFieldTarget(width, height int32) error {
+	d := r.dof
+	if width != d.width || height != d.height {
+		d.deleteTarget()
+
+		gl.GenTextures(1, &d.colorTexture)
+		gl.BindTexture(gl.TEXTURE_2D, d.colorTexture)
+		gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA8, width, height, 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+
+		gl.GenTextures(1, &d.distanceTexture)
+		gl.BindTexture(gl.TEXTURE_2D, d.distanceTexture)
+		gl.TexImage2D(gl.TEXTURE_2D, 0, gl.R32F, width, height, 0, gl.RED, gl.FLOAT, nil)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+		gl.BindTexture(gl.TEXTURE_2D, 0)
+
+		gl.GenFramebuffers(1, &d.fbo)
+		gl.BindFramebuffer(gl.FRAMEBUFFER, d.fbo)
+		gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, d.colorTexture, 0)
+		gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT1, gl.TEXTURE_2D, d.distanceTexture, 0)
+		drawBuffers := []uint32{gl.COLOR_ATTACHMENT0, gl.COLOR_ATTACHMENT1}
+		gl.DrawBuffers(int32(len(drawBuffers)), &drawBuffers[0])
+		if status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER); status != gl.FRAMEBUFFER_COMPLETE {
+			gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+			d.deleteTarget()
+			return fmt.Errorf("depth of field framebuffer incomplete: 0x%x", status)
+		}
+
+		d.width, d.height = width, height
+	}
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, d.fbo)
+	gl.Viewport(0, 0, width, height)
+	return nil
+}
+
+// applyDepthOfField draws the blurred image into the bound framebuffer,
+// which must be the same size as the offscreen image. Blur radii scale with
+// the offscreen image so supersampling doesn't shrink the effect
+func (r *VoxelRenderer) applyDepthOfField(pixelScale float32) {
+	d := r.dof
+
+	gl.UseProgram(d.program)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, d.colorTexture)
+	gl.ActiveTexture(gl.TEXTURE1)
+	gl.BindTexture(gl.TEXTURE_2D, d.distanceTexture)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.Uniform1i(gl.GetUniformLocation(d.program, gl.Str("colorTexture\x00")), 0)
+	gl.Uniform1i(gl.GetUniformLocation(d.program, gl.Str("distanceTexture\x00")), 1)
+	gl.Uniform1f(gl.GetUniformLocation(d.program, gl.Str("focusDistance\x00")), r.focusDistance())
+	gl.Uniform1f(gl.GetUniformLocation(d.program, gl.Str("aperture\x00")), r.Aperture*pixelScale)
+	gl.Uniform1f(gl.GetUniformLocation(d.program, gl.Str("maxRadius\x00")), maxDepthOfFieldBlur*pixelScale)
+
+	gl.BindVertexArray(r.quadVAO)
+	gl.DrawArrays(gl.TRIANGLE_STRIP, 0, 4)
+}
+
+// deleteTarget frees the offscreen framebuffer and its textures
+func (d *depthOfFieldTarget) deleteTarget() {
+	if d.fbo != 0 {
+		gl.DeleteFramebuffers(1, &d.fbo)
+		d.fbo = 0
+	}
+	if d.colorTexture != 0 {
+		gl.DeleteTextures(1, &d.colorTexture)
+		d.colorTexture = 0
+	}
+	if d.distanceTexture != 0 {
+		gl.DeleteTextures(1, &d.distanceTexture)
+		d.distanceTexture = 0
+	}
+	d.width, d.height = 0, 0
+}
+
+// releaseDepthOfField frees all depth of field resources
+func (r *VoxelRenderer) releaseDepthOfField() {
+	if r.dof == nil {
+		return
+	}
+	r.dof.deleteTarget()
+	gl.DeleteProgram(r.dof.program)
+	r.dof = nil
+}
@@ -0,0 +1,47 @@
+package opengl
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// TestFocusDistance checks the focus falls back from a clicked point to the
+// set distance to the nearest surface, and follows the point as the camera
+// moves
+func TestFocusDistance(t *testing.T) {
+	const radius = 6371000.0
+	r := &VoxelRenderer{
+		width:        800,
+		height:       600,
+		planetRadius: radius,
+		cameraPos:    mgl32.Vec3{0, 0, 3 * radius},
+		FieldOfView:  DefaultFieldOfView,
+		dof:          &depthOfFieldTarget{},
+	}
+	r.updateMatrices()
+
+	if got := r.focusDistance(); !(math.Abs(float64(got)-2*radius) <= 1) {
+		t.Errorf("default focus %.0f m, want the nearest surface at %.0f m", got, 2*radius)
+	}
+
+	r.FocusDistance = 1e7
+	if got := r.focusDistance(); got != 1e7 {
+		t.Errorf("set focus %.0f m, want 1e7 m", got)
+	}
+
+	r.focusOn(400, 300)
+	if !r.focusTracking {
+		t.Fatal("click on the planet's center didn't focus")
+	}
+	if got := r.focusDistance(); !(math.Abs(float64(got)-2*radius) <= 10) {
+		t.Errorf("clicked focus %.0f m, want %.0f m", got, 2*radius)
+	}
+
+	r.cameraPos = r.cameraPos.Mul(5.0 / 3.0)
+	r.updateMatrices()
+	if got := r.focusDistance(); !(math.Abs(float64(got)-4*radius) <= 10) {
+		t.Errorf("focus after zooming out %.0f m, want it to follow the point to %.0f m", got, 4*radius)
+	}
+}
@@ -0,0 +1,27 @@
+package opengl
+
+import (
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// HeatFluxLegend is the heat flux view's color ramp from the lowest flux
+// (old cratons) to the highest (spreading ridges)
+// Keep in sync with heatFluxColor in the ray march shader
+var HeatFluxLegend = []mgl32.Vec4{
+	{0.05, 0.1, 0.45, 1.0},
+	{0.9, 0.9, 0.9, 1.0},
+	{0.85, 0.1, 0.05, 1.0},
+	{1.0, 0.95, 0.3, 1.0},
+}
+
+// heatFluxLegendText describes the heat flux colors and the range they span
+// The range follows the last texture upload, so colors are relative
+func (r *VoxelRenderer) heatFluxLegendText() string {
+	if r.voxelTextures == nil {
+		return "Blue=low heat flux (cratons), White, Red, Yellow=high heat flux (ridges)"
+	}
+	return fmt.Sprintf("Blue=%.1f mW/m² (cratons) → White → Red → Yellow=%.1f mW/m² (ridges)",
+		r.voxelTextures.HeatFluxMin*1000, r.voxelTextures.HeatFluxMax*1000)
+}
@@ -0,0 +1,37 @@
+package opengl
+
+import (
+	"time"
+)
+
+// maxInterpolationGap is the longest time between texture uploads that is
+// still blended. Longer gaps mean a manual step, a new planet or a stall, and
+// the new state is shown at once
+const maxInterpolationGap = 500 * time.Millisecond
+
+// markTextureUpload records when new voxel data reached the textures and how
+// long after the previous upload, which is how long the blend toward it takes
+func (r *VoxelRenderer) markTextureUpload(now time.Time) {
+	if !r.lastTextureUpload.IsZero() {
+		r.uploadInterval = now.Sub(r.lastTextureUpload)
+	}
+	r.lastTextureUpload = now
+}
+
+// interpolationFraction returns how far to blend from the previous physics
+// update toward the latest: 0 right after an upload, reaching 1 when the next
+// upload is due. Material types are never blended; see temperatureAt and
+// velocityAt in the ray march shader
+func (r *VoxelRenderer) interpolationFraction(now time.Time) float32 {
+	if !r.InterpolateFrames || r.uploadInterval <= 0 || r.uploadInterval > maxInterpolationGap {
+		return 1
+	}
+	if r.voxelTextures != nil && !r.voxelTextures.HasPrevious() {
+		return 1
+	}
+	fraction := float32(now.Sub(r.lastTextureUpload)) / float32(r.uploadInterval)
+	if fraction > 1 {
+		return 1
+	}
+	return fraction
+}
@@ -0,0 +1,103 @@
+package opengl
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestInterpolationFraction checks the blend runs from the previous update to
+// the latest over the time between uploads, and that long gaps aren't blended
+func TestInterpolationFraction(t *testing.T) {
+	r := &VoxelRenderer{InterpolateFrames: true}
+	start := time.Unix(1000, 0)
+	interval := 100 * time.Millisecond
+
+	r.markTextureUpload(start)
+	if got := r.interpolationFraction(start); got != 1 {
+		t.Errorf("first upload blends at %.2f, want 1 with nothing to blend from", got)
+	}
+
+	r.markTextureUpload(start.Add(interval))
+	for _, tt := range []struct {
+		after time.Duration
+		want  float32
+	}{
+		{0, 0},
+		{interval / 4, 0.25},
+		{interval / 2, 0.5},
+		{interval, 1},
+		{3 * interval, 1},
+	} {
+		if got := r.interpolationFraction(start.Add(interval + tt.after)); got != tt.want {
+			t.Errorf("%v after an upload blends at %.2f, want %.2f", tt.after, got, tt.want)
+		}
+	}
+
+	r.InterpolateFrames = false
+	if got := r.interpolationFraction(start.Add(interval)); got != 1 {
+		t.Errorf("interpolation off blends at %.2f, want 1", got)
+	}
+
+	// A manual step after a long pause jumps straight to the new state
+	r.InterpolateFrames = true
+	r.markTextureUpload(start.Add(interval + 5*time.Second))
+	if got := r.interpolationFraction(start.Add(interval + 5*time.Second)); got != 1 {
+		t.Errorf("upload after a long gap blends at %.2f, want 1", got)
+	}
+}
+
+// TestInterpolationSmoothness measures the largest jump from one frame to the
+// next of a coastline on a plate drifting 5 cm a year, at 1x to 100x speed.
+// Physics publishes 10 times a second and the renderer draws 60 frames a
+// second, so unblended the coastline stands still for five frames and jumps
+// the whole update on the sixth; blended it moves the same distance every
+// frame, one update behind
+func TestInterpolationSmoothness(t *testing.T) {
+	const (
+		physicsInterval = 100 * time.Millisecond
+		frameRate       = 60
+		yearsPerSecond  = 1e6  // At 1x
+		driftRate       = 0.05 // m/year
+	)
+
+	for _, speed := range []float64{1, 10, 100} {
+		drift := func(at time.Duration) float64 {
+			return driftRate * yearsPerSecond * speed * at.Seconds()
+		}
+
+		var largest [2]float64 // Unblended, blended
+		for i, blend := range []bool{false, true} {
+			r := &VoxelRenderer{InterpolateFrames: blend}
+			start := time.Unix(1000, 0)
+			var previous, latest, shown float64 // Meters drifted
+			nextUpdate := time.Duration(0)
+			for frame := 0; frame < 120; frame++ {
+				at := time.Duration(frame) * time.Second / frameRate
+				now := start.Add(at)
+				if at >= nextUpdate {
+					previous, latest = latest, drift(nextUpdate)
+					r.markTextureUpload(now)
+					nextUpdate += physicsInterval
+				}
+
+				// The shader's mix of the previous and latest textures
+				position := previous + (latest-previous)*float64(r.interpolationFraction(now))
+				if frame > 0 {
+					largest[i] = math.Max(largest[i], math.Abs(position-shown))
+				}
+				shown = position
+			}
+		}
+
+		perUpdate, perFrame := drift(physicsInterval), drift(time.Second/frameRate)
+		t.Logf("%3.0fx: largest jump %6.1f km a frame unblended, %6.1f km blended (%.1f km of drift a frame)",
+			speed, largest[0]/1000, largest[1]/1000, perFrame/1000)
+		if largest[0] < 0.99*perUpdate {
+			t.Errorf("%.0fx: unblended coastline jumped at most %.0f m, want a whole update's %.0f m", speed, largest[0], perUpdate)
+		}
+		if largest[1] > 1.01*perFrame {
+			t.Errorf("%.0fx: blended coastline jumped %.0f m in a frame, want at most its %.0f m of drift", speed, largest[1], perFrame)
+		}
+	}
+}
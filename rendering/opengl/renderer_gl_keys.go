@@ -0,0 +1,141 @@
+package opengl
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// chordMods are the modifiers that distinguish bindings; lock keys are ignored
+const chordMods = glfw.ModShift | glfw.ModControl | glfw.ModAlt | glfw.ModSuper
+
+// KeyChord is a key together with the modifiers held with it
+type KeyChord struct {
+	Key  glfw.Key
+	Mods glfw.ModifierKey
+}
+
+// KeyAction is something the user can do from the keyboard
+type KeyAction struct {
+	Name        string     // Short label for the help screen's key column when Keys is long, e.g. "1-9"
+	Description string     // What the action does
+	Keys        []KeyChord // Every chord that triggers it
+	Repeat      bool       // Also fire while the key is held down
+
+	Handler func(r *VoxelRenderer, chord KeyChord)
+	State   func(r *VoxelRenderer) string // Current setting for the help screen (nil = none)
+}
+
+// KeyRegistry maps key chords to actions. Actions keep their registration
+// order so help lists them consistently
+type KeyRegistry struct {
+	actions []*KeyAction
+	byChord map[KeyChord]*KeyAction
+}
+
+// NewKeyRegistry creates an empty registry
+func NewKeyRegistry() *KeyRegistry {
+	return &KeyRegistry{byChord: make(map[KeyChord]*KeyAction)}
+}
+
+// Register adds an action, failing if any of its chords is already taken
+func (kr *KeyRegistry) Register(action KeyAction) error {
+	if len(action.Keys) == 0 || action.Handler == nil {
+		return fmt.Errorf("key action %q needs keys and a handler", action.Description)
+	}
+	for _, chord := range action.Keys {
+		chord.Mods &= chordMods
+		if other, taken := kr.byChord[chord]; taken {
+			return fmt.Errorf("%s is bound to both %q and %q", chordLabel(chord), other.Description, action.Description)
+		}
+	}
+
+	a := &action
+	for _, chord := range action.Keys {
+		chord.Mods &= chordMods
+		kr.byChord[chord] = a
+	}
+	kr.actions = append(kr.actions, a)
+	return nil
+}
+
+// Lookup returns the action bound to exactly this key and modifier
+// combination, so Shift+1 never falls through to 1
+func (kr *KeyRegistry) Lookup(key glfw.Key, mods glfw.ModifierKey) (*KeyAction, KeyChord) {
+	chord := KeyChord{Key: key, Mods: mods & chordMods}
+	return kr.byChord[chord], chord
+}
+
+// Actions returns every registered action in registration order
+func (kr *KeyRegistry) Actions() []*KeyAction {
+	return kr.actions
+}
+
+// KeyLabel returns how the action's keys are shown to the user
+func (a *KeyAction) KeyLabel() string {
+	if a.Name != "" {
+		return a.Name
+	}
+	labels := make([]string, len(a.Keys))
+	for i, chord := range a.Keys {
+		labels[i] = chordLabel(chord)
+	}
+	return strings.Join(labels, "/")
+}
+
+// keyNames labels keys that aren't a letter or digit
+var keyNames = map[glfw.Key]string{
+	glfw.KeyEscape:       "Esc",
+	glfw.KeyPeriod:       ".",
+	glfw.KeySlash:        "/",
+	glfw.KeyLeftBracket:  "[",
+	glfw.KeyRightBracket: "]",
+	glfw.KeyLeft:         "Left",
+	glfw.KeyRight:        "Right",
+	glfw.KeyUp:           "Up",
+	glfw.KeyDown:         "Down",
+	glfw.KeyMinus:        "-",
+	glfw.KeyEqual:        "=",
+	glfw.KeySpace:        "Space",
+}
+
+// chordLabel names a chord the way the user would type it, e.g. "Shift+O"
+func chordLabel(chord KeyChord) string {
+	var name string
+	switch {
+	case chord.Key >= glfw.KeyA && chord.Key <= glfw.KeyZ:
+		name = string(rune('A' + chord.Key - glfw.KeyA))
+	case chord.Key >= glfw.Key0 && chord.Key <= glfw.Key9:
+		name = string(rune('0' + chord.Key - glfw.Key0))
+	case chord.Key >= glfw.KeyF1 && chord.Key <= glfw.KeyF25:
+		name = fmt.Sprintf("F%d", chord.Key-glfw.KeyF1+1)
+	default:
+		var ok bool
+		if name, ok = keyNames[chord.Key]; !ok {
+			name = fmt.Sprintf("key %d", chord.Key)
+		}
+	}
+
+	// Shift+/ is more familiar as ?
+	if chord.Key == glfw.KeySlash && chord.Mods&glfw.ModShift != 0 {
+		name = "?"
+		chord.Mods &^= glfw.ModShift
+	}
+
+	prefix := ""
+	for _, m := range []struct {
+		mod  glfw.ModifierKey
+		name string
+	}{{glfw.ModControl, "Ctrl+"}, {glfw.ModAlt, "Alt+"}, {glfw.ModSuper, "Super+"}, {glfw.ModShift, "Shift+"}} {
+		if chord.Mods&m.mod != 0 {
+			prefix += m.name
+		}
+	}
+	return prefix + name
+}
+
+// RegisterKeyAction binds a new action, failing if one of its chords is taken
+func (r *VoxelRenderer) RegisterKeyAction(action KeyAction) error {
+	return r.keys.Register(action)
+}
@@ -0,0 +1,52 @@
+package opengl
+
+import (
+	"testing"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// TestDefaultKeyActionsUnique checks no two built-in actions claim the same
+// key and modifier combination, and that Shift chords don't fall through to
+// the unshifted binding
+func TestDefaultKeyActionsUnique(t *testing.T) {
+	seen := make(map[KeyChord]string)
+	for _, action := range defaultKeyActions() {
+		for _, chord := range action.Keys {
+			if other, taken := seen[chord]; taken {
+				t.Errorf("%s is bound to both %q and %q", chordLabel(chord), other, action.Description)
+			}
+			seen[chord] = action.Description
+		}
+	}
+
+	keys := NewKeyRegistry()
+	for _, action := range defaultKeyActions() {
+		if err := keys.Register(action); err != nil {
+			t.Fatalf("registering defaults: %v", err)
+		}
+	}
+
+	// A second binding for a taken chord is refused
+	err := keys.Register(KeyAction{
+		Description: "Duplicate",
+		Keys:        []KeyChord{{Key: glfw.KeyP}},
+		Handler:     func(*VoxelRenderer, KeyChord) {},
+	})
+	if err == nil {
+		t.Error("registered a second action on P")
+	}
+
+	// Number keys pick a view, Shift+number a speed, and lock keys don't matter
+	view, _ := keys.Lookup(glfw.Key1, glfw.ModNumLock)
+	speed, _ := keys.Lookup(glfw.Key1, glfw.ModShift|glfw.ModCapsLock)
+	if view == nil || speed == nil || view == speed {
+		t.Fatalf("1 and Shift+1 resolve to %v and %v, want two different actions", view, speed)
+	}
+	if view.Description != "Change visualization" {
+		t.Errorf("1 bound to %q, want the view switch", view.Description)
+	}
+	if bound, _ := keys.Lookup(glfw.Key6, glfw.ModShift); bound != nil {
+		t.Errorf("Shift+6 bound to %q, want nothing", bound.Description)
+	}
+}
@@ -0,0 +1,153 @@
+package opengl
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// MapProjection selects between the 3D globe and flat maps of the whole
+// surface, numbered as the shader's mapProjection uniform
+type MapProjection int32
+
+const (
+	ProjectionGlobe MapProjection = iota
+	ProjectionEquirectangular
+	ProjectionMollweide
+	projectionCount
+)
+
+// mapProjectionNames names each MapProjection, in order
+var mapProjectionNames = []string{"Globe", "Equirectangular map", "Mollweide map"}
+
+// Map zoom limits; zoom 1 fits the whole map in the window
+const (
+	minMapZoom = 1.0
+	maxMapZoom = 20.0
+)
+
+// String names the projection for the help screen and console
+func (p MapProjection) String() string {
+	if p >= 0 && int(p) < len(mapProjectionNames) {
+		return mapProjectionNames[p]
+	}
+	return "Unknown"
+}
+
+// ParseMapProjection parses a -map flag value
+func ParseMapProjection(s string) (MapProjection, error) {
+	switch s {
+	case "globe", "":
+		return ProjectionGlobe, nil
+	case "equirectangular":
+		return ProjectionEquirectangular, nil
+	case "mollweide":
+		return ProjectionMollweide, nil
+	}
+	return ProjectionGlobe, fmt.Errorf("unknown map projection %q (want globe, equirectangular or mollweide)", s)
+}
+
+// SetMapProjection switches between the globe and a flat world map. Maps
+// show every render mode's coloring; dragging scrolls them and the scroll
+// wheel zooms, instead of moving the camera
+func (r *VoxelRenderer) SetMapProjection(projection MapProjection) {
+	r.MapProjection = projection
+	if r.mapZoom < minMapZoom {
+		r.mapZoom = minMapZoom
+	}
+}
+
+// mapView reports whether a flat map is shown instead of the globe
+func (r *VoxelRenderer) mapView() bool {
+	return r.MapProjection != ProjectionGlobe
+}
+
+// mapFit returns the window's aspect ratio and the map's half height in
+// screen units at zoom 1: the 2:1 map fills the height of a wide window and
+// the width of a narrow one
+func (r *VoxelRenderer) mapFit() (aspect, halfHeight float64) {
+	aspect = float64(r.width) / float64(r.height)
+	return aspect, math.Min(1, aspect/2)
+}
+
+// mapPoint returns the map coordinates under a window pixel. The whole map
+// spans x from -2 to 2 and y from -1 to 1, centered on the central meridian
+// and shifted up or down by mapPan. Keep in sync with mapLatLon in the ray
+// march shader
+func (r *VoxelRenderer) mapPoint(xpos, ypos float64) (x, y float64) {
+	aspect, halfHeight := r.mapFit()
+	scale := halfHeight * math.Max(r.mapZoom, minMapZoom)
+	x = (2*xpos/float64(r.width) - 1) * aspect / scale
+	y = (1 - 2*ypos/float64(r.height)) / scale
+	return x, y + r.mapPan
+}
+
+// mapLatLon inverts a map projection, returning latitude and longitude in
+// radians of map coordinates x, y (see mapPoint), or false off the map.
+// centralLon is the longitude at the middle of the map
+func mapLatLon(projection MapProjection, x, y, centralLon float64) (lat, lon float64, ok bool) {
+	switch projection {
+	case ProjectionEquirectangular:
+		if math.Abs(y) > 1 {
+			return 0, 0, false
+		}
+		lat = y * math.Pi / 2
+		lon = x * math.Pi / 2
+
+	case ProjectionMollweide:
+		// Scaled so the ellipse spans the same 4 by 2 box
+		mx, my := x*math.Sqrt2, y*math.Sqrt2
+		if mx*mx/8+my*my/2 > 1 {
+			return 0, 0, false
+		}
+		theta := math.Asin(my / math.Sqrt2)
+		lat = math.Asin((2*theta + math.Sin(2*theta)) / math.Pi)
+		if c := math.Cos(theta); c > 1e-9 {
+			lon = math.Pi * mx / (2 * math.Sqrt2 * c)
+		}
+
+	default:
+		return 0, 0, false
+	}
+
+	// Wrap into -180° to 180°, so the equirectangular map scrolls endlessly
+	lon = math.Remainder(lon+centralLon, 2*math.Pi)
+	return lat, lon, true
+}
+
+// pickMap returns the surface point under the cursor on the map, in the
+// planet frame pickSurface uses
+func (r *VoxelRenderer) pickMap(xpos, ypos float64) (mgl32.Vec3, bool) {
+	x, y := r.mapPoint(xpos, ypos)
+	lat, lon, ok := mapLatLon(r.MapProjection, x, y, r.mapCenterLon)
+	if !ok {
+		return mgl32.Vec3{}, false
+	}
+	// The point at geodetic latitude lat sits at parametric latitude beta
+	// on the flattened surface, as in the shader's map view
+	beta := math.Atan2((1-float64(r.flattening))*math.Sin(lat), math.Cos(lat))
+	dir := mgl32.Vec3{
+		float32(math.Cos(beta) * math.Cos(lon)),
+		float32(math.Sin(beta)),
+		float32(math.Cos(beta) * math.Sin(lon)),
+	}
+	return toWorldSpace(dir.Mul(r.planetRadius), r.flattening), true
+}
+
+// panMap scrolls the map with a mouse drag so the point under the cursor
+// follows it: sideways turns the central meridian, up and down pans
+func (r *VoxelRenderer) panMap(dx, dy float64) {
+	_, halfHeight := r.mapFit()
+	scale := halfHeight * math.Max(r.mapZoom, minMapZoom) * float64(r.height) / 2
+	r.mapCenterLon = math.Remainder(r.mapCenterLon-dx/scale*math.Pi/2, 2*math.Pi)
+	r.mapPan = math.Max(-1, math.Min(1, r.mapPan+dy/scale))
+}
+
+// zoomMap zooms the map with the scroll wheel
+func (r *VoxelRenderer) zoomMap(yoff float64) {
+	r.mapZoom = math.Max(minMapZoom, math.Min(maxMapZoom, math.Max(r.mapZoom, minMapZoom)*math.Pow(1.1, yoff)))
+	if r.mapZoom == minMapZoom {
+		r.mapPan = 0 // The whole map fits again
+	}
+}
@@ -0,0 +1,85 @@
+package opengl
+
+import (
+	"math"
+	"testing"
+)
+
+// mollweideForward projects latitude and longitude in radians to the map
+// coordinates mapLatLon inverts, solving for the auxiliary angle by Newton's
+// method
+func mollweideForward(lat, lon float64) (x, y float64) {
+	theta := lat
+	for i := 0; i < 50; i++ {
+		f := 2*theta + math.Sin(2*theta) - math.Pi*math.Sin(lat)
+		d := 2 + 2*math.Cos(2*theta)
+		if d < 1e-12 {
+			break
+		}
+		theta -= f / d
+	}
+	x = 2 * math.Sqrt2 / math.Pi * lon * math.Cos(theta)
+	y = math.Sqrt2 * math.Sin(theta)
+	return x / math.Sqrt2, y / math.Sqrt2
+}
+
+// TestMapLatLon checks both projections invert to the points they draw,
+// honor the central meridian and leave the corners of the Mollweide map empty
+func TestMapLatLon(t *testing.T) {
+	deg := math.Pi / 180
+	for _, point := range [][2]float64{{0, 0}, {45, 90}, {-30, -150}, {80, 10}} {
+		lat, lon := point[0]*deg, point[1]*deg
+
+		gotLat, gotLon, ok := mapLatLon(ProjectionEquirectangular, lon/(math.Pi/2), lat/(math.Pi/2), 0)
+		if !ok || math.Abs(gotLat-lat) > 1e-9 || math.Abs(gotLon-lon) > 1e-9 {
+			t.Errorf("equirectangular %v° came back as %.4f°, %.4f° (%v)", point, gotLat/deg, gotLon/deg, ok)
+		}
+
+		x, y := mollweideForward(lat, lon)
+		gotLat, gotLon, ok = mapLatLon(ProjectionMollweide, x, y, 0)
+		if !ok || math.Abs(gotLat-lat) > 1e-6 || math.Abs(gotLon-lon) > 1e-6 {
+			t.Errorf("Mollweide %v° came back as %.4f°, %.4f° (%v)", point, gotLat/deg, gotLon/deg, ok)
+		}
+	}
+
+	// Turning the central meridian moves the middle of the map, wrapping
+	// around the antimeridian
+	if _, lon, _ := mapLatLon(ProjectionEquirectangular, 0, 0, 170*deg); math.Abs(lon-170*deg) > 1e-9 {
+		t.Errorf("map center at %.2f°, want the 170° central meridian", lon/deg)
+	}
+	if _, lon, _ := mapLatLon(ProjectionEquirectangular, 0.5, 0, 170*deg); math.Abs(lon+145*deg) > 1e-9 {
+		t.Errorf("45° east of 170° drawn as %.2f°, want -145°", lon/deg)
+	}
+
+	if _, _, ok := mapLatLon(ProjectionMollweide, 1.9, 0.9, 0); ok {
+		t.Error("corner outside the Mollweide ellipse is on the map")
+	}
+	if _, _, ok := mapLatLon(ProjectionEquirectangular, 0, 1.1, 0); ok {
+		t.Error("point beyond the pole is on the equirectangular map")
+	}
+}
+
+// TestMapPicking checks a click on the map picks the point drawn there and
+// that dragging keeps that point under the cursor
+func TestMapPicking(t *testing.T) {
+	r := &VoxelRenderer{width: 800, height: 400, planetRadius: 6371000}
+	r.SetMapProjection(ProjectionEquirectangular)
+
+	// A 2:1 window fits the map exactly: three quarters across is 90° east,
+	// a quarter down is 45° north
+	pos, ok := r.pickSurface(600, 100)
+	if !ok {
+		t.Fatal("click on the map missed")
+	}
+	if lat, lon := surfaceLatLon(pos, 0); math.Abs(lat-45) > 1e-3 || math.Abs(lon-90) > 1e-3 {
+		t.Errorf("picked %.3f°, %.3f°, want 45°, 90°", lat, lon)
+	}
+
+	r.zoomMap(10)
+	before, _ := r.pickSurface(500, 250)
+	r.panMap(-40, 30)
+	after, ok := r.pickSurface(460, 280)
+	if !ok || after.Sub(before).Len() > 1 {
+		t.Errorf("dragged point moved %.0f m from under the cursor", after.Sub(before).Len())
+	}
+}
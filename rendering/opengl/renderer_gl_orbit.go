@@ -0,0 +1,52 @@
+package opengl
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// defaultOrbitSpeed is a slow lobby-display spin, one orbit every two minutes
+const defaultOrbitSpeed = 3.0 // Degrees per second
+
+// defaultOrbitIdle is how long the orbit waits after camera input before resuming
+const defaultOrbitIdle = 5 * time.Second
+
+// SetAutoOrbit turns the unattended camera orbit on or off
+func (r *VoxelRenderer) SetAutoOrbit(enabled bool) {
+	r.autoOrbit = enabled
+	r.lastOrbitUpdate = time.Time{}
+}
+
+// AutoOrbiting reports whether the camera orbits on its own
+func (r *VoxelRenderer) AutoOrbiting() bool {
+	return r.autoOrbit
+}
+
+// markCameraInput holds the auto-orbit while the user moves the camera
+func (r *VoxelRenderer) markCameraInput() {
+	r.lastCameraInput = time.Now()
+}
+
+// updateAutoOrbit advances the camera longitude by the time since the last frame
+// The orbit only moves cameraRotationX, so zoom and tilt set by the user are kept
+func (r *VoxelRenderer) updateAutoOrbit() {
+	now := time.Now()
+	last := r.lastOrbitUpdate
+	r.lastOrbitUpdate = now
+
+	if !r.autoOrbit || last.IsZero() || now.Sub(r.lastCameraInput) < r.AutoOrbitIdle {
+		return
+	}
+
+	dt := float32(now.Sub(last).Seconds())
+	r.cameraRotationX += mgl32.DegToRad(r.AutoOrbitSpeed) * dt
+	r.updateMatrices()
+}
+
+// scaleOrbitSpeed multiplies the orbit speed and reports the new value
+func (r *VoxelRenderer) scaleOrbitSpeed(factor float32) {
+	r.AutoOrbitSpeed *= factor
+	fmt.Printf("Auto-orbit speed: %.2f°/s\n", r.AutoOrbitSpeed)
+}
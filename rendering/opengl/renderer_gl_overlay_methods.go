@@ -17,6 +17,13 @@ func (r *VoxelRenderer) RenderFullscreenStats() {
 	gl.Enable(gl.BLEND)
 	gl.BlendFunc(gl.SRC_ALPHA, gl.ONE_MINUS_SRC_ALPHA)
 
+	// Views with a color scale show it under the stats
+	if r.RenderMode == 8 {
+		r.statsOverlay.SetColorLegend(HeatFluxLegend)
+	} else {
+		r.statsOverlay.SetColorLegend(nil)
+	}
+
 	// Render the stats overlay
 	r.statsOverlay.Render()
 
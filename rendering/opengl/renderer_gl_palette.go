@@ -0,0 +1,102 @@
+package opengl
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"worldgenerator/core"
+)
+
+// MaterialPalette is the material view's color for each material type,
+// indexed by core.MaterialType. Materials past its end, such as ones
+// registered after it was made, fall back to core.MaterialColor
+type MaterialPalette []mgl32.Vec3
+
+// DefaultMaterialPalette returns the natural colors of every registered
+// material, see core.MaterialColor
+func DefaultMaterialPalette() MaterialPalette {
+	palette := make(MaterialPalette, core.MaterialCount())
+	for mat := range palette {
+		color := core.MaterialColor(core.MaterialType(mat))
+		palette[mat] = mgl32.Vec3{float32(color.X), float32(color.Y), float32(color.Z)}
+	}
+	return palette
+}
+
+// Color returns the palette's color for a material
+func (p MaterialPalette) Color(mat core.MaterialType) mgl32.Vec3 {
+	if int(mat) < len(p) {
+		return p[mat]
+	}
+	color := core.MaterialColor(mat)
+	return mgl32.Vec3{float32(color.X), float32(color.Y), float32(color.Z)}
+}
+
+// LegendColor returns the palette's color for a material as core.ExportLegend
+// takes it
+func (p MaterialPalette) LegendColor(mat core.MaterialType) core.Vector3 {
+	color := p.Color(mat)
+	return core.Vector3{X: float64(color[0]), Y: float64(color[1]), Z: float64(color[2])}
+}
+
+// ParseMaterialPalette reads a palette from JSON mapping material names (see
+// core.MaterialName) to RGB colors from 0 to 1, e.g. {"granite": [0.55, 0.5,
+// 0.4]}. Materials it leaves out keep their default color
+func ParseMaterialPalette(data []byte) (MaterialPalette, error) {
+	var colors map[string][3]float32
+	if err := json.Unmarshal(data, &colors); err != nil {
+		return nil, fmt.Errorf("invalid material palette: %v", err)
+	}
+
+	palette := DefaultMaterialPalette()
+	byName := make(map[string]core.MaterialType, len(palette))
+	for mat := range palette {
+		byName[core.MaterialName(core.MaterialType(mat))] = core.MaterialType(mat)
+	}
+	for name, rgb := range colors {
+		mat, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("material palette: unknown material %q", name)
+		}
+		for _, c := range rgb {
+			if c < 0 || c > 1 {
+				return nil, fmt.Errorf("material palette: %s color %v is outside 0-1", name, rgb)
+			}
+		}
+		palette[mat] = mgl32.Vec3(rgb)
+	}
+	return palette, nil
+}
+
+// LoadMaterialPalette reads a palette file written for ParseMaterialPalette
+func LoadMaterialPalette(path string) (MaterialPalette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseMaterialPalette(data)
+}
+
+// SetMaterialPalette colors the material view with palette from the next
+// frame; nil restores DefaultMaterialPalette
+func (r *VoxelRenderer) SetMaterialPalette(palette MaterialPalette) {
+	r.materialPalette = append(MaterialPalette(nil), palette...)
+}
+
+// materialColorUniform flattens the palette into the shader's materialColors
+// array, one RGB triple for every registered material
+func (r *VoxelRenderer) materialColorUniform() []float32 {
+	palette := r.materialPalette
+	if palette == nil {
+		palette = DefaultMaterialPalette()
+	}
+	colors := make([]float32, 0, core.MaterialCount()*3)
+	for mat := 0; mat < core.MaterialCount(); mat++ {
+		color := palette.Color(core.MaterialType(mat))
+		colors = append(colors, color[0], color[1], color[2])
+	}
+	return colors
+}
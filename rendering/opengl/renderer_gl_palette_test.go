@@ -0,0 +1,52 @@
+package opengl
+
+import (
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"worldgenerator/core"
+)
+
+// TestParseMaterialPalette checks a palette file overrides only the materials
+// it names and rejects unknown materials and out-of-range colors
+func TestParseMaterialPalette(t *testing.T) {
+	palette, err := ParseMaterialPalette([]byte(`{"granite": [0.2, 0.6, 0.3]}`))
+	if err != nil {
+		t.Fatalf("ParseMaterialPalette: %v", err)
+	}
+	if got, want := palette.Color(core.MatGranite), (mgl32.Vec3{0.2, 0.6, 0.3}); got != want {
+		t.Errorf("granite is %v, want %v from the file", got, want)
+	}
+	if got, want := palette.Color(core.MatBasalt), DefaultMaterialPalette().Color(core.MatBasalt); got != want {
+		t.Errorf("basalt is %v, want the default %v", got, want)
+	}
+
+	for _, bad := range []string{`{"unobtainium": [1, 1, 1]}`, `{"water": [0, 0, 2]}`, `{"water": "blue"}`} {
+		if _, err := ParseMaterialPalette([]byte(bad)); err == nil {
+			t.Errorf("palette %s accepted", bad)
+		}
+	}
+}
+
+// TestMaterialColorUniform checks the shader gets one color per registered
+// material, taken from the palette set on the renderer
+func TestMaterialColorUniform(t *testing.T) {
+	r := &VoxelRenderer{}
+	palette := DefaultMaterialPalette()
+	palette[core.MatWater] = mgl32.Vec3{0.1, 0.2, 0.3}
+	r.SetMaterialPalette(palette)
+	palette[core.MatWater] = mgl32.Vec3{1, 1, 1} // The renderer keeps its own copy
+
+	colors := r.materialColorUniform()
+	if len(colors) != core.MaterialCount()*3 {
+		t.Fatalf("%d floats for %d materials", len(colors), core.MaterialCount())
+	}
+	if water := colors[core.MatWater*3 : core.MatWater*3+3]; water[0] != 0.1 || water[1] != 0.2 || water[2] != 0.3 {
+		t.Errorf("water uploaded as %v, want the palette's 0.1, 0.2, 0.3", water)
+	}
+
+	r.SetMaterialPalette(nil)
+	if got, want := r.materialColorUniform()[core.MatWater*3], DefaultMaterialPalette()[core.MatWater][0]; got != want {
+		t.Errorf("water red %g after resetting the palette, want the default %g", got, want)
+	}
+}
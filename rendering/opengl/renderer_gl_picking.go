@@ -8,24 +8,54 @@ import (
 	"worldgenerator/simulation"
 )
 
-// HandleMouseClick performs ray casting to select plates
+// HandleMouseClick performs ray casting to select plates, or with a
+// cross-section open to print the voxel clicked on the cut face
 func (r *VoxelRenderer) HandleMouseClick(xpos, ypos float64, planet *core.VoxelPlanet) {
+	if r.crossSection && !r.mapView() {
+		r.inspectSliceVoxel(xpos, ypos, planet)
+		return
+	}
+
 	// Only handle clicks in plate mode
 	if r.RenderMode != 4 || planet.Physics == nil {
 		return
 	}
-	// TODO: Properly type assert physics system
-	// vp, ok := planet.Physics.(*physics.VoxelPhysics)
-	// if !ok || vp.plates == nil {
-	//     return
-	// }
 	
+	hitPoint, hit := r.pickSurface(xpos, ypos)
+	if !hit {
+		return
+	}
+	
+	// Find which voxel/plate was hit
+	plateID := r.findPlateAtPosition(hitPoint, planet)
+	if plateID > 0 {
+		r.selectedPlateID = plateID
+	}
+}
+
+// pickSurface casts a ray from the camera through the cursor and returns
+// where it meets the planet's surface, or on a map the point drawn there
+func (r *VoxelRenderer) pickSurface(xpos, ypos float64) (mgl32.Vec3, bool) {
+	if r.mapView() {
+		return r.pickMap(xpos, ypos)
+	}
+
+	rayOrigin, rayDir := r.cursorRay(xpos, ypos)
+	
+	// Perform ray-sphere intersection, in sphere space on a flattened planet
+	hitPoint, hit := r.raySphereIntersect(toSphereSpace(rayOrigin, r.flattening), toSphereSpace(rayDir, r.flattening), r.planetRadius)
+	return toWorldSpace(hitPoint, r.flattening), hit
+}
+
+// cursorRay returns the ray from the camera through the cursor in planet
+// coordinates, with a unit direction
+func (r *VoxelRenderer) cursorRay(xpos, ypos float64) (origin, dir mgl32.Vec3) {
 	// Convert screen coordinates to NDC
 	x := (2.0*float32(xpos))/float32(r.width) - 1.0
 	y := 1.0 - (2.0*float32(ypos))/float32(r.height) // Flip Y
 	
 	// Create ray from camera
-	invViewProj := r.projMatrix.Mul4(r.viewMatrix).Inv()
+	invViewProj := r.viewProj().Inv()
 	
 	// Near and far points in NDC
 	nearPoint := mgl32.Vec4{x, y, -1.0, 1.0}
@@ -47,21 +77,30 @@ func (r *VoxelRenderer) HandleMouseClick(xpos, ypos float64, planet *core.VoxelP
 		farWorld[2] - nearWorld[2],
 	}.Normalize()
 	
-	// Perform ray-sphere intersection
-	hitPoint, hit := r.raySphereIntersect(rayOrigin, rayDir, r.planetRadius)
-	if !hit {
-		return
-	}
-	
-	// Find which voxel/plate was hit
-	plateID := r.findPlateAtPosition(hitPoint, planet)
-	if plateID > 0 {
-		r.selectedPlateID = plateID
-		// TODO: Properly type assert and access plates
-		// if vp, ok := planet.Physics.(*physics.VoxelPhysics); ok {
-		//     r.displayPlateInfo(plateID, vp.plates)
-		// }
-	}
+	return rayOrigin, rayDir
+}
+
+// surfaceLatLon returns the geographic position in degrees of a point in
+// world space, with Y as the polar axis as in the ray marching shader. The
+// latitude is geodetic, that of the planet's flattened shell through pos
+func surfaceLatLon(pos mgl32.Vec3, flattening float32) (lat, lon float64) {
+	b := 1 - float64(flattening)
+	lat = math.Atan2(float64(pos[1]), b*b*math.Hypot(float64(pos[0]), float64(pos[2]))) * 180.0 / math.Pi
+	lon = math.Atan2(float64(pos[2]), float64(pos[0])) * 180.0 / math.Pi
+	return lat, lon
+}
+
+// toSphereSpace stretches a world space point or direction along the polar
+// axis, so a planet flattened by flattening and each of its shells become
+// spheres of their equatorial radius, as the ray marching shader draws them
+// Rays keep their parameter, as directions aren't renormalized
+func toSphereSpace(v mgl32.Vec3, flattening float32) mgl32.Vec3 {
+	return mgl32.Vec3{v[0], v[1] / (1 - flattening), v[2]}
+}
+
+// toWorldSpace undoes toSphereSpace
+func toWorldSpace(v mgl32.Vec3, flattening float32) mgl32.Vec3 {
+	return mgl32.Vec3{v[0], v[1] * (1 - flattening), v[2]}
 }
 
 // raySphereIntersect performs ray-sphere intersection
@@ -93,55 +132,30 @@ func (r *VoxelRenderer) raySphereIntersect(origin, dir mgl32.Vec3, radius float3
 	return hitPoint, true
 }
 
-// findPlateAtPosition finds which plate contains the given 3D position
+// findPlateAtPosition finds which plate contains the given 3D position, 0
+// when none does
 func (r *VoxelRenderer) findPlateAtPosition(pos mgl32.Vec3, planet *core.VoxelPlanet) int {
-	// Convert 3D position to spherical coordinates
-	normalized := pos.Normalize()
-	
-	lat := math.Asin(float64(normalized[2])) * 180.0 / math.Pi       // -90 to 90
-	lon := math.Atan2(float64(normalized[1]), float64(normalized[0])) * 180.0 / math.Pi // -180 to 180
+	lat, lon := surfaceLatLon(pos, r.flattening)
 	
 	// Find the surface shell
 	surfaceShell := len(planet.Shells) - 2
 	if surfaceShell < 0 {
 		return 0
 	}
-	
 	shell := &planet.Shells[surfaceShell]
+	alt := planet.ShellAltitude((shell.InnerRadius+shell.OuterRadius)/2, lat)
 	
-	// Convert to voxel indices
-	latBandF := (lat + 90.0) / 180.0 * float64(shell.LatBands)
-	latBand := int(math.Floor(latBandF))
-	if latBand >= shell.LatBands {
-		latBand = shell.LatBands - 1
-	}
-	if latBand < 0 {
-		latBand = 0
-	}
-	
-	// Get longitude index
-	lonCount := len(shell.Voxels[latBand])
-	lonNorm := (lon + 180.0) / 360.0
-	lonIdx := int(lonNorm * float64(lonCount))
-	lonIdx = lonIdx % lonCount
-	if lonIdx < 0 {
-		lonIdx += lonCount
+	// Look up plate ID, as this state recorded it
+	voxel, _ := planet.VoxelAtGeographic(lat, lon, alt)
+	if voxel == nil {
+		return 0
 	}
-	
-	// Look up plate ID
-	// TODO: Properly type assert and access plates
-	// coord := core.VoxelCoord{Shell: surfaceShell, Lat: latBand, Lon: lonIdx}
-	// if vp, ok := planet.Physics.(*physics.VoxelPhysics); ok && vp.plates != nil {
-	//     if plateID, exists := vp.plates.VoxelPlateMap[coord]; exists {
-	//         return plateID
-	//     }
-	// }
-	
-	return 0
+	return int(voxel.PlateID)
 }
 
-// displayPlateInfo shows information about the selected plate
-func (r *VoxelRenderer) displayPlateInfo(plateID int, plateManager *simulation.PlateManager) {
+// displayPlateInfo shows information about the selected plate, whose surface
+// speed follows from the planet radius
+func (r *VoxelRenderer) displayPlateInfo(plateID int, plateManager *simulation.PlateManager, radius float64) {
 	// Find the plate
 	var plate *simulation.TectonicPlate
 	for _, p := range plateManager.Plates {
@@ -168,7 +182,7 @@ func (r *VoxelRenderer) displayPlateInfo(plateID int, plateManager *simulation.P
 	fmt.Printf("\n--- Motion ---\n")
 	fmt.Printf("Euler pole: %.1f°N, %.1f°E\n", plate.EulerPoleLat, plate.EulerPoleLon)
 	fmt.Printf("Angular velocity: %.2e rad/year\n", plate.AngularVelocity)
-	surfaceVel := math.Abs(plate.AngularVelocity) * 6371000 // Earth radius
+	surfaceVel := math.Abs(plate.AngularVelocity) * radius // m/year
 	fmt.Printf("Surface velocity: %.1f cm/year\n", surfaceVel*100)
 	
 	// Forces
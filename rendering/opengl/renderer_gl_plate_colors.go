@@ -0,0 +1,42 @@
+package opengl
+
+import (
+	"github.com/go-gl/gl/v4.3-core/gl"
+
+	"worldgenerator/core"
+	"worldgenerator/simulation"
+)
+
+// plateHueTextureUnit follows the colormap texture
+const plateHueTextureUnit = 11
+
+// bindPlateColors uploads each plate's hue from the plate manager's
+// simulation.PlateColorRegistry, indexed by plate ID, and binds it for plate
+// view. The table is a few hundred floats, so it is refreshed every frame
+// the view is on. Without a plate manager the shader hashes plate IDs
+func (r *VoxelRenderer) bindPlateColors() {
+	count := int32(0)
+	if planet, ok := r.PlanetRef.(*core.VoxelPlanet); ok && planet != nil && r.RenderMode == 4 {
+		if pm, ok := core.GetPlateManager(planet).(*simulation.PlateManager); ok && pm.Colors != nil {
+			if hues := pm.Colors.HueTable(); len(hues) > 0 {
+				if r.plateHueTexture == 0 {
+					gl.GenTextures(1, &r.plateHueTexture)
+					gl.BindTexture(gl.TEXTURE_1D, r.plateHueTexture)
+					gl.TexParameteri(gl.TEXTURE_1D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+					gl.TexParameteri(gl.TEXTURE_1D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+					gl.TexParameteri(gl.TEXTURE_1D, gl.TEXTURE_WRAP_S, gl.CLAMP_TO_EDGE)
+				}
+				gl.BindTexture(gl.TEXTURE_1D, r.plateHueTexture)
+				gl.TexImage1D(gl.TEXTURE_1D, 0, gl.R32F, int32(len(hues)), 0, gl.RED, gl.FLOAT, gl.Ptr(hues))
+				gl.BindTexture(gl.TEXTURE_1D, 0)
+				count = int32(len(hues))
+			}
+		}
+	}
+
+	gl.ActiveTexture(gl.TEXTURE0 + plateHueTextureUnit)
+	gl.BindTexture(gl.TEXTURE_1D, r.plateHueTexture)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.Uniform1i(gl.GetUniformLocation(r.shaderProgram, gl.Str("plateHueTexture\x00")), plateHueTextureUnit)
+	gl.Uniform1i(gl.GetUniformLocation(r.shaderProgram, gl.Str("plateHueCount\x00")), count)
+}
@@ -0,0 +1,55 @@
+package opengl
+
+import (
+	"fmt"
+	"math"
+
+	"worldgenerator/core"
+	"worldgenerator/rendering/opengl/overlay"
+	"worldgenerator/simulation"
+)
+
+// selectedPlateStats returns the statistics physics published with planet
+// for the plate clicked in plate view, false when there is none or it no
+// longer exists
+func (r *VoxelRenderer) selectedPlateStats(planet *core.VoxelPlanet) (simulation.PlateStats, bool) {
+	plates := simulation.PublishedPlates(planet)
+	if plates == nil || r.selectedPlateID == 0 {
+		return simulation.PlateStats{}, false
+	}
+	return plates.PlateStats(r.selectedPlateID)
+}
+
+// plateStatLines formats a plate's statistics for the overlay panel
+func plateStatLines(s simulation.PlateStats) []overlay.PlateStatLine {
+	heading := math.Atan2(s.VelEast, s.VelNorth) * 180 / math.Pi
+	if heading < 0 {
+		heading += 360
+	}
+	return []overlay.PlateStatLine{
+		{Label: "Type", Value: s.Type},
+		{Label: "Area", Value: fmt.Sprintf("%.2f million km2", s.Area/1e12)},
+		{Label: "Voxels", Value: fmt.Sprintf("%d (%d on boundary)", s.MemberCount, s.BoundaryCount)},
+		{Label: "Average age", Value: fmt.Sprintf("%.1f My", s.AverageAge/1e6)},
+		{Label: "Centroid", Value: fmt.Sprintf("%.1f°, %.1f°", s.CentroidLat, s.CentroidLon)},
+		{Label: "Velocity", Value: fmt.Sprintf("%.2f cm/yr toward %.0f°", s.Speed(), heading)},
+	}
+}
+
+// RenderPlateStats draws the selected plate's statistics while plate view is
+// on, refreshed every frame as the plate moves
+func (r *VoxelRenderer) RenderPlateStats() {
+	planet, ok := r.PlanetRef.(*core.VoxelPlanet)
+	if !ok || planet == nil || r.statsOverlay == nil || r.RenderMode != 4 {
+		return
+	}
+	s, ok := r.selectedPlateStats(planet)
+	if !ok {
+		return
+	}
+	title := fmt.Sprintf("Plate %d", s.ID)
+	if s.Name != "" {
+		title += " " + s.Name
+	}
+	r.statsOverlay.RenderPlateStats(title, plateStatLines(s))
+}
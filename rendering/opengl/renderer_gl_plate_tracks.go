@@ -0,0 +1,35 @@
+package opengl
+
+import (
+	"time"
+
+	"worldgenerator/core"
+	"worldgenerator/simulation"
+)
+
+// plateTrackRefreshInterval is the least wall time between plate track
+// redraws while the overlay is shown; tracks only grow a point every
+// simulation.PlateManager.TrackInterval years
+const plateTrackRefreshInterval = 2 * time.Second
+
+// plateTrackTextureUnit follows the plate hues
+const plateTrackTextureUnit = 12
+
+// updatePlateTracks redraws the plate track overlay from planet's plate
+// manager when it is shown, at most every plateTrackRefreshInterval unless
+// force is set. Each track takes its plate's plate view color
+func (r *VoxelRenderer) updatePlateTracks(planet *core.VoxelPlanet, force bool) {
+	if !r.showPlateTracks || r.voxelTextures == nil {
+		return
+	}
+	if !force && time.Since(r.lastPlateTrackRefresh) < plateTrackRefreshInterval {
+		return
+	}
+	pm, ok := core.GetPlateManager(planet).(*simulation.PlateManager)
+	if !ok || pm.Colors == nil {
+		return
+	}
+
+	r.voxelTextures.UpdatePlateTracks(pm.GetPlateTracks(), pm.Colors.Color)
+	r.lastPlateTrackRefresh = time.Now()
+}
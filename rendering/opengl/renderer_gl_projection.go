@@ -0,0 +1,122 @@
+package opengl
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// Field of view limits and key step in degrees
+const (
+	DefaultFieldOfView = 45.0
+	minFieldOfView     = 5.0
+	maxFieldOfView     = 120.0
+	fieldOfViewStep    = 5.0
+)
+
+// Perspective clip planes in meters, wide enough for planet scale
+const (
+	perspectiveNear = 1000.0
+	perspectiveFar  = 100000000.0
+)
+
+// SetFieldOfView sets the vertical field of view in degrees, clamped to a
+// usable range. In orthographic mode it sets how much of the planet fits on
+// screen, so switching projections keeps the planet the same size
+func (r *VoxelRenderer) SetFieldOfView(degrees float32) {
+	r.FieldOfView = float32(math.Max(minFieldOfView, math.Min(maxFieldOfView, float64(degrees))))
+	r.updateMatrices()
+}
+
+// SetOrthographic switches between perspective and orthographic projection
+// Orthographic views the planet from infinitely far away along the camera
+// direction, so angular sizes on the globe can be compared without
+// perspective foreshortening
+func (r *VoxelRenderer) SetOrthographic(enabled bool) {
+	r.Orthographic = enabled
+	r.updateMatrices()
+}
+
+// projection returns the projection matrix for the current camera distance
+// The orthographic box shows what the perspective frustum shows at the
+// planet's center, and its clip planes bracket the planet so rays start
+// outside it even when the camera is zoomed in below the surface scale
+func (r *VoxelRenderer) projection() mgl32.Mat4 {
+	aspect := float32(r.width) / float32(r.height)
+	fov := r.FieldOfView
+	if fov == 0 {
+		fov = DefaultFieldOfView
+	}
+
+	if !r.Orthographic {
+		return mgl32.Perspective(mgl32.DegToRad(fov), aspect, perspectiveNear, perspectiveFar)
+	}
+
+	dist := r.cameraPos.Len()
+	halfHeight := dist * float32(math.Tan(float64(mgl32.DegToRad(fov))/2))
+	halfWidth := halfHeight * aspect
+	return mgl32.Ortho(-halfWidth, halfWidth, -halfHeight, halfHeight, dist-2*r.planetRadius, dist+2*r.planetRadius)
+}
+
+// projectionLabel describes the projection for the help screen and console
+func (r *VoxelRenderer) projectionLabel() string {
+	if r.Orthographic {
+		return fmt.Sprintf("orthographic, %.0f° framing", r.FieldOfView)
+	}
+	return fmt.Sprintf("perspective, %.0f° FOV", r.FieldOfView)
+}
+
+// ViewProjection is every way the renderer can show the planet, combining
+// the camera projection with the flat map views, in the order Shift+V
+// cycles through them
+type ViewProjection int
+
+const (
+	ViewPerspective ViewProjection = iota
+	ViewOrthographic
+	ViewEquirectangular
+	ViewMollweide
+	viewProjectionCount
+)
+
+// viewProjectionNames names each ViewProjection, in order
+var viewProjectionNames = []string{"Perspective", "Orthographic", "Equirectangular map", "Mollweide map"}
+
+// String names the projection for the help screen and console
+func (p ViewProjection) String() string {
+	if p >= 0 && int(p) < len(viewProjectionNames) {
+		return viewProjectionNames[p]
+	}
+	return "Unknown"
+}
+
+// SetProjection switches to a projection: the perspective or orthographic
+// globe, or one of the flat maps, which unwrap the surface shell into a
+// lat/lon image from the same textures. Leaving a map returns to the globe
+func (r *VoxelRenderer) SetProjection(mode ViewProjection) {
+	switch mode {
+	case ViewEquirectangular:
+		r.SetMapProjection(ProjectionEquirectangular)
+	case ViewMollweide:
+		r.SetMapProjection(ProjectionMollweide)
+	default:
+		r.SetMapProjection(ProjectionGlobe)
+		r.SetOrthographic(mode == ViewOrthographic)
+	}
+}
+
+// Projection returns the projection being shown. A map is reported as such
+// whatever the globe's camera projection underneath it
+func (r *VoxelRenderer) Projection() ViewProjection {
+	switch r.MapProjection {
+	case ProjectionEquirectangular:
+		return ViewEquirectangular
+	case ProjectionMollweide:
+		return ViewMollweide
+	}
+	if r.Orthographic {
+		return ViewOrthographic
+	}
+	return ViewPerspective
+}
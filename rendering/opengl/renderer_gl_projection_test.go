@@ -0,0 +1,121 @@
+package opengl
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"worldgenerator/core"
+)
+
+// TestOrthographicPicking checks picking in orthographic projection hits the
+// point straight below the cursor, so a cursor half a radius above the
+// planet's center picks 30° latitude, where perspective picks elsewhere
+func TestOrthographicPicking(t *testing.T) {
+	const radius = 6371000.0
+	r := &VoxelRenderer{
+		width:        800,
+		height:       600,
+		planetRadius: radius,
+		cameraPos:    mgl32.Vec3{0, 0, 3 * radius},
+		FieldOfView:  DefaultFieldOfView,
+	}
+	r.SetOrthographic(true)
+
+	// Center of the screen is the point facing the camera
+	pos, ok := r.pickSurface(400, 300)
+	if !ok {
+		t.Fatal("center of the screen missed the planet")
+	}
+	if lat, lon := surfaceLatLon(pos, 0); math.Abs(lat) > 0.01 || math.Abs(lon) > 0.01 {
+		t.Errorf("center picked %.3f°, %.3f°, want 0°, 0°", lat, lon)
+	}
+
+	// Screen height spans the perspective view's height at the planet's center
+	halfHeight := 3 * radius * math.Tan(float64(mgl32.DegToRad(DefaultFieldOfView))/2)
+	y := 300 - 0.5*radius/halfHeight*300
+	pos, ok = r.pickSurface(400, y)
+	if !ok {
+		t.Fatal("cursor above the center missed the planet")
+	}
+	if lat, _ := surfaceLatLon(pos, 0); math.Abs(lat-30) > 0.05 {
+		t.Errorf("orthographic pick at half a radius up hit %.3f° latitude, want 30°", lat)
+	}
+
+	r.SetOrthographic(false)
+	pos, ok = r.pickSurface(400, y)
+	if lat, _ := surfaceLatLon(pos, 0); ok && math.Abs(lat-30) < 1 {
+		t.Errorf("perspective pick hit %.3f° latitude, want foreshortening away from 30°", lat)
+	}
+}
+
+// TestSetFieldOfViewClamps checks the field of view stays within its limits
+func TestSetFieldOfViewClamps(t *testing.T) {
+	r := &VoxelRenderer{width: 800, height: 600, planetRadius: 6371000, cameraPos: mgl32.Vec3{0, 0, 1}}
+	r.SetFieldOfView(1)
+	if r.FieldOfView != minFieldOfView {
+		t.Errorf("1° set %.0f°, want %.0f°", r.FieldOfView, minFieldOfView)
+	}
+	r.SetFieldOfView(170)
+	if r.FieldOfView != maxFieldOfView {
+		t.Errorf("170° set %.0f°, want %.0f°", r.FieldOfView, maxFieldOfView)
+	}
+}
+
+// TestProjectionCycle checks Shift+V's cycle visits each projection once and
+// comes back to perspective, and that leaving a map restores the globe
+func TestProjectionCycle(t *testing.T) {
+	r := &VoxelRenderer{width: 800, height: 600, planetRadius: 6371000.0, cameraPos: mgl32.Vec3{0, 0, 3 * 6371000.0}}
+
+	mode := r.Projection()
+	for want := ViewPerspective; want < viewProjectionCount; want++ {
+		if mode != want {
+			t.Fatalf("cycle reached %s, want %s", mode, want)
+		}
+		r.SetProjection((mode + 1) % viewProjectionCount)
+		mode = r.Projection()
+	}
+	if mode != ViewPerspective || r.Orthographic || r.mapView() {
+		t.Errorf("cycle ended on %s (orthographic %v, map %s), want the perspective globe", mode, r.Orthographic, r.MapProjection)
+	}
+
+	// Orthographic survives under a map but a map switch reports the map
+	r.SetProjection(ViewOrthographic)
+	r.SetMapProjection(ProjectionMollweide)
+	if got := r.Projection(); got != ViewMollweide {
+		t.Errorf("orthographic globe under a Mollweide map reported as %s", got)
+	}
+}
+
+// TestFlattenedPicking checks picking a flattened planet lands on its
+// spheroid surface with the geodetic latitude core assigns that point, and
+// misses above the pole where a sphere would have been hit. The planet is
+// small, as inverting the orthographic view of an Earth-sized one underflows
+func TestFlattenedPicking(t *testing.T) {
+	const radius, flattening = 10.0, 0.3
+	r := &VoxelRenderer{
+		width:        800,
+		height:       600,
+		planetRadius: radius,
+		flattening:   flattening,
+		cameraPos:    mgl32.Vec3{0, 0, 3 * radius},
+		FieldOfView:  DefaultFieldOfView,
+	}
+	r.SetOrthographic(true)
+	halfHeight := 3 * radius * math.Tan(float64(mgl32.DegToRad(DefaultFieldOfView))/2)
+
+	pos, ok := r.pickSurface(400, 300-0.5*radius/halfHeight*300)
+	if !ok {
+		t.Fatal("cursor half a radius up missed the planet")
+	}
+	want := core.CartesianToGeographic(core.Cartesian{X: float64(pos[0]), Y: float64(pos[1]), Z: float64(pos[2])}, radius, flattening)
+	lat, _ := surfaceLatLon(pos, flattening)
+	if !(math.Abs(want.Alt) < 1e-4 && math.Abs(lat-core.RadiansToDegrees(want.Lat)) < 1e-3 && lat > 30) {
+		t.Errorf("picked %.4f° at %.5f altitude, core puts that point at %.4f°, above 30° as the pole draws in",
+			lat, want.Alt, core.RadiansToDegrees(want.Lat))
+	}
+
+	if _, ok := r.pickSurface(400, 300-0.8*radius/halfHeight*300); ok {
+		t.Error("cursor above the flattened pole hit the planet")
+	}
+}
@@ -0,0 +1,144 @@
+package opengl
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-gl/gl/v4.3-core/gl"
+)
+
+// recordingQueue is how many captured frames can wait for the PNG encoder
+// before new ones are dropped
+const recordingQueue = 8
+
+// recordingWarnInterval limits how often dropped frames are reported
+const recordingWarnInterval = 5 * time.Second
+
+// recordedFrame is a captured frame waiting to be written
+type recordedFrame struct {
+	path string
+	img  *image.RGBA
+}
+
+// frameRecorder dumps every Nth rendered frame into a directory as numbered
+// PNGs for assembling a timelapse. Frames are read back on the render thread
+// and encoded on a background goroutine; when encoding falls behind, frames
+// are dropped rather than stalling the render loop
+type frameRecorder struct {
+	dir    string
+	every  int
+	frames int // Frames rendered since recording started
+	saved  int // Frames handed to the encoder, numbering the files
+
+	queue chan recordedFrame
+	done  chan struct{}
+
+	dropped     int // Frames dropped since the last warning
+	lastWarning time.Time
+}
+
+// StartRecording saves every Nth rendered frame to dir as frame_000001.png,
+// frame_000002.png and so on, creating the directory if needed. The numbers
+// stay consecutive when frames are dropped, so the sequence plays straight
+// into ffmpeg
+func (r *VoxelRenderer) StartRecording(dir string, every int) error {
+	if every < 1 {
+		return fmt.Errorf("recording interval must be at least 1 frame, got %d", every)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create recording directory: %v", err)
+	}
+	r.StopRecording()
+
+	rec := &frameRecorder{
+		dir:   dir,
+		every: every,
+		queue: make(chan recordedFrame, recordingQueue),
+		done:  make(chan struct{}),
+	}
+	go rec.encode()
+	r.recorder = rec
+	return nil
+}
+
+// Recording reports whether frames are being recorded
+func (r *VoxelRenderer) Recording() bool {
+	return r.recorder != nil
+}
+
+// StopRecording finishes writing the frames already captured and ends the
+// recording
+func (r *VoxelRenderer) StopRecording() {
+	rec := r.recorder
+	if rec == nil {
+		return
+	}
+	r.recorder = nil
+
+	close(rec.queue)
+	<-rec.done
+	rec.warnDropped()
+	fmt.Printf("🎬 Recorded %d frames to %s\n", rec.saved, rec.dir)
+}
+
+// recordFrame captures the frame in the back buffer when it falls on the
+// recording cadence
+func (r *VoxelRenderer) recordFrame() error {
+	rec := r.recorder
+	rec.frames++
+	if (rec.frames-1)%rec.every != 0 {
+		return nil
+	}
+
+	// Skip the readback entirely when the encoder has no room for it
+	if len(rec.queue) == cap(rec.queue) {
+		rec.drop()
+		return nil
+	}
+	img, err := r.captureFrame(gl.BACK)
+	if err != nil {
+		return err
+	}
+	select {
+	case rec.queue <- recordedFrame{path: recordingFramePath(rec.dir, rec.saved+1), img: img}:
+		rec.saved++
+	default:
+		rec.drop()
+	}
+	return nil
+}
+
+// recordingFramePath names the nth recorded frame
+func recordingFramePath(dir string, n int) string {
+	return filepath.Join(dir, fmt.Sprintf("frame_%06d.png", n))
+}
+
+// drop counts a frame the encoder couldn't keep up with, warning now and then
+func (rec *frameRecorder) drop() {
+	rec.dropped++
+	if time.Since(rec.lastWarning) >= recordingWarnInterval {
+		rec.warnDropped()
+	}
+}
+
+// warnDropped reports frames dropped since the last warning
+func (rec *frameRecorder) warnDropped() {
+	if rec.dropped > 0 {
+		fmt.Printf("⚠️  Recording dropped %d frames, PNG encoding can't keep up (try a larger -record-every)\n", rec.dropped)
+	}
+	rec.dropped = 0
+	rec.lastWarning = time.Now()
+}
+
+// encode writes queued frames until the queue is closed
+func (rec *frameRecorder) encode() {
+	defer close(rec.done)
+	for frame := range rec.queue {
+		if err := writePNG(frame.path, frame.img); err != nil {
+			fmt.Printf("❌ Recording frame failed: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package opengl
+
+import (
+	"fmt"
+	"image"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRecordingFlushesQueue checks frames still waiting for the encoder are
+// written when recording stops, under consecutive numbers
+func TestRecordingFlushesQueue(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "frames")
+	r := &VoxelRenderer{}
+	if err := r.StartRecording(dir, 0); err == nil {
+		t.Error("recording every 0th frame accepted")
+	}
+	if err := r.StartRecording(dir, 3); err != nil {
+		t.Fatal(err)
+	}
+	if !r.Recording() {
+		t.Fatal("not recording after StartRecording")
+	}
+
+	// Queue frames as recordFrame would, without a GL context to read from
+	rec := r.recorder
+	for i := 0; i < recordingQueue; i++ {
+		rec.saved++
+		rec.queue <- recordedFrame{path: recordingFramePath(dir, rec.saved), img: image.NewRGBA(image.Rect(0, 0, 4, 2))}
+	}
+	r.StopRecording()
+	if r.Recording() {
+		t.Error("still recording after StopRecording")
+	}
+
+	for n := 1; n <= recordingQueue; n++ {
+		if _, err := os.Stat(filepath.Join(dir, fmt.Sprintf("frame_%06d.png", n))); err != nil {
+			t.Errorf("frame %d not written: %v", n, err)
+		}
+	}
+	r.StopRecording() // Stopping twice is harmless
+}
@@ -0,0 +1,30 @@
+package opengl
+
+import (
+	"time"
+
+	"worldgenerator/core"
+	"worldgenerator/physics"
+)
+
+// riverRefreshInterval is the least wall time between river network updates
+// while the overlay is shown; routing the whole surface takes a moment and
+// rivers move far slower than the textures refresh
+const riverRefreshInterval = 5 * time.Second
+
+// updateRivers redraws the river overlay from planet when it is shown and the
+// surface has changed since, at most every riverRefreshInterval unless force
+// is set
+func (r *VoxelRenderer) updateRivers(planet *core.VoxelPlanet, force bool) {
+	if !r.showRivers || r.voxelTextures == nil || len(planet.Shells) < 2 {
+		return
+	}
+	version := planet.Shells[len(planet.Shells)-2].Version
+	if !force && (version != 0 && version == r.riverVersion || time.Since(r.lastRiverRefresh) < riverRefreshInterval) {
+		return
+	}
+
+	r.voxelTextures.UpdateRivers(physics.ComputeRiverNetwork(planet))
+	r.riverVersion = version
+	r.lastRiverRefresh = time.Now()
+}
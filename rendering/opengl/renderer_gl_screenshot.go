@@ -0,0 +1,134 @@
+package opengl
+
+import (
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-gl/gl/v4.3-core/gl"
+
+	"worldgenerator/core"
+)
+
+// RequestScreenshot schedules a screenshot of the next rendered frame
+func (r *VoxelRenderer) RequestScreenshot() {
+	r.screenshotRequested = true
+}
+
+// CaptureScreenshot writes the frame on screen to path as a PNG. It reads
+// the window's front buffer, so call it between frames; RequestScreenshot
+// instead saves the next frame and encodes it in the background
+func (r *VoxelRenderer) CaptureScreenshot(path string) error {
+	img, err := r.captureFrame(gl.FRONT)
+	if err != nil {
+		return err
+	}
+	return writePNG(path, img)
+}
+
+// captureFrame reads back a window buffer (gl.BACK or gl.FRONT) at full
+// framebuffer resolution, which follows the window through resizes
+// When supersampling, the offscreen image is read instead, giving a screenshot
+// at the supersampled resolution (without the stats overlay)
+func (r *VoxelRenderer) captureFrame(buffer uint32) (*image.RGBA, error) {
+	// Framebuffer size can differ from window size on high-DPI displays
+	width, height := r.window.GetFramebufferSize()
+	if r.ssaa != nil && r.ssaa.fbo != 0 {
+		width, height = int(r.ssaa.width), int(r.ssaa.height)
+	}
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid framebuffer size %dx%d", width, height)
+	}
+
+	pixels := make([]uint8, width*height*4)
+
+	// RGBA rows are always 4-byte aligned, but set pack alignment explicitly
+	// so the readback never depends on pixel-store state left by other code
+	gl.PixelStorei(gl.PACK_ALIGNMENT, 1)
+	if r.ssaa != nil && r.ssaa.fbo != 0 {
+		gl.BindFramebuffer(gl.READ_FRAMEBUFFER, r.ssaa.fbo)
+		gl.ReadBuffer(gl.COLOR_ATTACHMENT0)
+		defer gl.BindFramebuffer(gl.READ_FRAMEBUFFER, 0)
+	} else {
+		gl.ReadBuffer(buffer)
+		defer gl.ReadBuffer(gl.BACK)
+	}
+	gl.ReadPixels(0, 0, int32(width), int32(height), gl.RGBA, gl.UNSIGNED_BYTE, gl.Ptr(&pixels[0]))
+	if err := checkGLError("frame readback"); err != nil {
+		return nil, err
+	}
+
+	return flipFrame(pixels, width, height), nil
+}
+
+// flipFrame turns OpenGL's bottom-up RGBA rows into a top-down image
+func flipFrame(pixels []uint8, width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	rowSize := width * 4
+	for y := 0; y < height; y++ {
+		src := pixels[(height-1-y)*rowSize : (height-y)*rowSize]
+		copy(img.Pix[y*img.Stride:y*img.Stride+rowSize], src)
+	}
+
+	// Force opaque output - the ray marcher leaves alpha undefined in places
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+
+	return img
+}
+
+// writePNG encodes an image to a new file at path, with core.WriteFileAtomic
+// so an interrupted capture never leaves a truncated screenshot behind
+func writePNG(path string, img image.Image) error {
+	err := core.WriteFileAtomic(path, func(w io.Writer) error {
+		return png.Encode(w, img)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write screenshot file: %v", err)
+	}
+	return nil
+}
+
+// nextScreenshotPath returns worldgen_<time>_<n>.png in dir, counting n up
+// from the renderer's last screenshot past any file already there, so
+// screenshots never overwrite each other
+func (r *VoxelRenderer) nextScreenshotPath(dir string, now time.Time) string {
+	for {
+		r.screenshotCount++
+		name := fmt.Sprintf("worldgen_%s_%03d.png", now.Format("20060102_150405"), r.screenshotCount)
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			return path
+		}
+	}
+}
+
+// saveScreenshot reads back the frame and writes it to the working directory
+// on a background goroutine, so the PNG encoding doesn't hold up the render
+// loop. Terminate waits for writes still in progress
+func (r *VoxelRenderer) saveScreenshot() error {
+	img, err := r.captureFrame(gl.BACK)
+	if err != nil {
+		return err
+	}
+
+	path := r.nextScreenshotPath(".", time.Now())
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	r.screenshots.Add(1)
+	go func() {
+		defer r.screenshots.Done()
+		if err := writePNG(path, img); err != nil {
+			fmt.Printf("❌ Screenshot failed: %v\n", err)
+		} else {
+			fmt.Printf("📷 Screenshot saved: %s\n", path)
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,55 @@
+package opengl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestFlipFrame checks OpenGL's bottom row becomes the top of the image and
+// alpha is forced opaque
+func TestFlipFrame(t *testing.T) {
+	// 2x3 frame, bottom-up: each row's red channel holds its GL row index
+	pixels := make([]uint8, 2*3*4)
+	for row := 0; row < 3; row++ {
+		for col := 0; col < 2; col++ {
+			pixels[(row*2+col)*4] = uint8(row)
+		}
+	}
+
+	img := flipFrame(pixels, 2, 3)
+	for y := 0; y < 3; y++ {
+		for x := 0; x < 2; x++ {
+			c := img.RGBAAt(x, y)
+			if want := uint8(2 - y); c.R != want {
+				t.Errorf("pixel (%d,%d) came from GL row %d, want %d", x, y, c.R, want)
+			}
+			if c.A != 255 {
+				t.Errorf("pixel (%d,%d) alpha %d, want 255", x, y, c.A)
+			}
+		}
+	}
+}
+
+// TestNextScreenshotPath checks successive screenshots in the same second get
+// distinct names and existing files are never reused
+func TestNextScreenshotPath(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2024, 3, 1, 12, 30, 45, 0, time.UTC)
+	r := &VoxelRenderer{}
+
+	first := r.nextScreenshotPath(dir, now)
+	if want := filepath.Join(dir, "worldgen_20240301_123045_001.png"); first != want {
+		t.Fatalf("first screenshot %s, want %s", first, want)
+	}
+
+	// A file left by an earlier run is skipped over
+	taken := filepath.Join(dir, "worldgen_20240301_123045_002.png")
+	if err := os.WriteFile(taken, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if next := r.nextScreenshotPath(dir, now); next == first || next == taken {
+		t.Errorf("second screenshot %s reuses an existing name", next)
+	}
+}
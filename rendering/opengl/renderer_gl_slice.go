@@ -0,0 +1,113 @@
+package opengl
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"worldgenerator/core"
+)
+
+// slicePickDepth is how far past the visible face, in meters, a cross-section
+// pick samples, so a hit exactly on a shell or cut boundary lands in the
+// voxel that was drawn there
+const slicePickDepth = 10.0
+
+// sliceHit returns where a ray first meets the part of a sphere the
+// cross-section leaves standing, the points whose coordinate along axis is at
+// least cut, as the ray marching shader draws it. onCut reports whether that
+// point is on the cut face rather than the planet's surface
+func sliceHit(origin, dir mgl32.Vec3, radius float32, axis int32, cut float32) (t float64, onCut, hit bool) {
+	o := [3]float64{float64(origin[0]), float64(origin[1]), float64(origin[2])}
+	d := [3]float64{float64(dir[0]), float64(dir[1]), float64(dir[2])}
+	if axis < 0 || axis > 2 {
+		return 0, false, false
+	}
+
+	// Where the ray is inside the sphere
+	a := d[0]*d[0] + d[1]*d[1] + d[2]*d[2]
+	b := 2 * (o[0]*d[0] + o[1]*d[1] + o[2]*d[2])
+	c := o[0]*o[0] + o[1]*o[1] + o[2]*o[2] - float64(radius)*float64(radius)
+	disc := b*b - 4*a*c
+	if a == 0 || disc < 0 {
+		return 0, false, false
+	}
+	sqrtD := math.Sqrt(disc)
+	enter, exit := (-b-sqrtD)/(2*a), (-b+sqrtD)/(2*a)
+	enter = math.Max(enter, 0)
+
+	// Where the ray is on the kept side of the cut
+	planeT := (float64(cut) - o[axis]) / d[axis]
+	switch {
+	case d[axis] > 0 && planeT > enter:
+		enter, onCut = planeT, true
+	case d[axis] < 0:
+		exit = math.Min(exit, planeT)
+	case d[axis] == 0 && o[axis] < float64(cut):
+		return 0, false, false
+	}
+
+	if enter >= exit {
+		return 0, false, false
+	}
+	return enter, onCut, true
+}
+
+// pickSlice returns the voxel drawn under the cursor with the cross-section
+// open, on the cut face or the uncut surface, and whether it's on the face
+func (r *VoxelRenderer) pickSlice(xpos, ypos float64, planet *core.VoxelPlanet) (*core.VoxelMaterial, core.VoxelCoord, bool, bool) {
+	// In sphere space, where the cut along Y moves with the stretch
+	origin, dir := r.cursorRay(xpos, ypos)
+	origin, dir = toSphereSpace(origin, r.flattening), toSphereSpace(dir, r.flattening)
+	cut := r.crossSectionPos
+	if r.crossSectionAxis == 1 {
+		cut /= 1 - r.flattening
+	}
+	t, onCut, hit := sliceHit(origin, dir, r.planetRadius, r.crossSectionAxis, cut)
+	if !hit {
+		return nil, core.VoxelCoord{Shell: -1}, false, false
+	}
+
+	// Shells are spheres here, so the distance from the center is the
+	// shell's equatorial radius
+	point := origin.Add(dir.Mul(float32(t + slicePickDepth)))
+	lat, lon := surfaceLatLon(toWorldSpace(point, r.flattening), r.flattening)
+	voxel, coord := planet.VoxelAtGeographic(lat, lon, planet.ShellAltitude(float64(point.Len()), lat))
+	return voxel, coord, onCut, voxel != nil
+}
+
+// inspectSliceVoxel prints the full state of the voxel clicked with the
+// cross-section open
+func (r *VoxelRenderer) inspectSliceVoxel(xpos, ypos float64, planet *core.VoxelPlanet) {
+	voxel, coord, onCut, ok := r.pickSlice(xpos, ypos, planet)
+	if !ok {
+		fmt.Println("No voxel under the cursor")
+		return
+	}
+	where := "surface"
+	if onCut {
+		where = fmt.Sprintf("%c cross-section", 'X'+r.crossSectionAxis)
+	}
+	fmt.Printf("\n=== VOXEL (%s) ===\n%s", where, formatVoxel(planet, coord, voxel))
+}
+
+// formatVoxel describes every field of a voxel, one per line
+func formatVoxel(planet *core.VoxelPlanet, coord core.VoxelCoord, voxel *core.VoxelMaterial) string {
+	shell := &planet.Shells[coord.Shell]
+	lat, lon := planet.VoxelLatLon(coord)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Voxel: shell %d, band %d, lon %d (%.2f°, %.2f°)\n", coord.Shell, coord.Lat, coord.Lon, lat, lon)
+	fmt.Fprintf(&b, "Depth: %.0f-%.0f km\n", (planet.Radius-shell.OuterRadius)/1000, (planet.Radius-shell.InnerRadius)/1000)
+	fmt.Fprintf(&b, "Material: %s (composition %.2f)\n", core.MaterialName(voxel.Type), voxel.Composition)
+	fmt.Fprintf(&b, "Temperature: %.0f K\n", voxel.Temperature)
+	fmt.Fprintf(&b, "Pressure: %.3g Pa\n", voxel.Pressure)
+	fmt.Fprintf(&b, "Density: %.0f kg/m³\n", voxel.Density)
+	fmt.Fprintf(&b, "Velocity: north %.3g, east %.3g, radial %.3g m/year\n", voxel.VelNorth, voxel.VelEast, voxel.VelR)
+	fmt.Fprintf(&b, "Plate: %d\n", voxel.PlateID)
+	fmt.Fprintf(&b, "Stress: %.3g Pa (yield %.3g Pa, brittle %v, fractured %v)\n", voxel.Stress, voxel.YieldStrength, voxel.IsBrittle, voxel.IsFractured)
+	fmt.Fprintf(&b, "Age: %.2f My\n", voxel.Age/1e6)
+	fmt.Fprintf(&b, "Elevation: %.0f m\n", voxel.Elevation)
+	return b.String()
+}
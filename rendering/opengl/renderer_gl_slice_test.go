@@ -0,0 +1,54 @@
+package opengl
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+	"worldgenerator/core"
+)
+
+// TestSliceHit checks picking with an X cross-section at the center finds the
+// cut face, the uncut surface, and nothing where the planet is cut away
+func TestSliceHit(t *testing.T) {
+	const radius = 1000.0
+	for _, tc := range []struct {
+		name      string
+		origin    mgl32.Vec3
+		dir       mgl32.Vec3
+		wantT     float64
+		wantOnCut bool
+		wantHit   bool
+	}{
+		{"cut face", mgl32.Vec3{-3 * radius, 0, 0}, mgl32.Vec3{1, 0, 0}, 3 * radius, true, true},
+		{"uncut surface", mgl32.Vec3{500, 0, 3 * radius}, mgl32.Vec3{0, 0, -1}, 3*radius - math.Sqrt(radius*radius-500*500), false, true},
+		{"surface before the cut", mgl32.Vec3{3 * radius, 0, 0}, mgl32.Vec3{-1, 0, 0}, 2 * radius, false, true},
+		{"cut away", mgl32.Vec3{-500, 0, 3 * radius}, mgl32.Vec3{0, 0, -1}, 0, false, false},
+		{"past the planet", mgl32.Vec3{0, 2 * radius, 3 * radius}, mgl32.Vec3{0, 0, -1}, 0, false, false},
+	} {
+		gotT, onCut, hit := sliceHit(tc.origin, tc.dir, radius, 0, 0)
+		if hit != tc.wantHit || onCut != tc.wantOnCut || math.Abs(gotT-tc.wantT) > 1e-3 {
+			t.Errorf("%s: t %.3f, on cut %v, hit %v; want t %.3f, on cut %v, hit %v",
+				tc.name, gotT, onCut, hit, tc.wantT, tc.wantOnCut, tc.wantHit)
+		}
+	}
+}
+
+// TestFormatVoxel checks the printout covers the fields worth debugging
+func TestFormatVoxel(t *testing.T) {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 4, 12)
+	coord := core.VoxelCoord{Shell: 1, Lat: 6, Lon: 3}
+	voxel := planet.GetVoxel(coord)
+	voxel.Type = core.MatMagma
+	voxel.Temperature = 1873
+	voxel.PlateID = 7
+	voxel.VelR = 2.5e-9
+
+	text := formatVoxel(planet, coord, voxel)
+	for _, want := range []string{"shell 1, band 6, lon 3", core.MaterialName(core.MatMagma), "1873 K", "Plate: 7", "radial 2.5e-09", "Stress:", "Age:"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("voxel printout lacks %q:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package opengl
+
+import (
+	"time"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// SetSpin turns the planet about its own axis, leaned tiltDeg from the
+// screen's vertical, at speed degrees per second of wall time (0 = still)
+// Real rotation is far too slow to see at simulation speeds, so the spin is
+// for show; the tilt is the planet's actual axial tilt
+func (r *VoxelRenderer) SetSpin(tiltDeg, speed float32) {
+	r.AxialTilt = tiltDeg
+	r.SpinSpeed = speed
+	r.lastSpinUpdate = time.Time{}
+}
+
+// updateSpin advances the planet's spin by the time since the last frame
+func (r *VoxelRenderer) updateSpin() {
+	now := time.Now()
+	last := r.lastSpinUpdate
+	r.lastSpinUpdate = now
+
+	if r.SpinSpeed == 0 || last.IsZero() {
+		return
+	}
+	r.spinAngle += mgl32.DegToRad(r.SpinSpeed) * float32(now.Sub(last).Seconds())
+}
+
+// planetModel turns planet coordinates, with Y along the spin axis, into
+// world space: the planet's spin, then its axis leaned toward the camera's right
+func (r *VoxelRenderer) planetModel() mgl32.Mat4 {
+	tilt := mgl32.HomogRotate3DZ(-mgl32.DegToRad(r.AxialTilt))
+	return tilt.Mul4(mgl32.HomogRotate3DY(r.spinAngle))
+}
+
+// viewProj maps planet coordinates to clip space
+func (r *VoxelRenderer) viewProj() mgl32.Mat4 {
+	return r.projMatrix.Mul4(r.viewMatrix).Mul4(r.planetModel())
+}
+
+// planetCameraPos returns the camera position in planet coordinates
+func (r *VoxelRenderer) planetCameraPos() mgl32.Vec3 {
+	return r.planetModel().Inv().Mul4x1(r.cameraPos.Vec4(1)).Vec3()
+}
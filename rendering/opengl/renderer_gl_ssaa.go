@@ -0,0 +1,141 @@
+package opengl
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v4.3-core/gl"
+
+	"worldgenerator/rendering/opengl/shaders"
+)
+
+// MaxSupersampling is the largest supported supersampling factor
+// 4x already ray marches 16 samples per pixel
+const MaxSupersampling = 4
+
+// supersampleTarget is the offscreen framebuffer the planet is ray marched
+// into when supersampling, at factor times the window's resolution
+type supersampleTarget struct {
+	factor        int
+	program       uint32 // Box-filter downsample
+	fbo           uint32
+	colorTexture  uint32
+	width, height int32 // Size of the offscreen image
+}
+
+// SetSupersampling renders the planet at factor times the window resolution
+// and box-filters it down to the window, smoothing coastlines and plate
+// boundaries. Factor 1 renders directly to the window. The stats overlay is
+// still drawn at native resolution; screenshots capture the full-size image
+func (r *VoxelRenderer) SetSupersampling(factor int) error {
+	if factor < 1 || factor > MaxSupersampling {
+		return fmt.Errorf("supersampling factor %d out of range 1-%d", factor, MaxSupersampling)
+	}
+
+	if factor == 1 {
+		r.releaseSupersampling()
+		return nil
+	}
+
+	if r.ssaa == nil {
+		program, err := shaders.CompileDownsampleShaders()
+		if err != nil {
+			return fmt.Errorf("failed to compile downsample shader: %v", err)
+		}
+		r.ssaa = &supersampleTarget{program: program}
+	}
+	r.ssaa.factor = factor
+
+	// Force reallocation at the new size on the next frame
+	r.ssaa.width, r.ssaa.height = 0, 0
+	return nil
+}
+
+// Supersampling returns the current supersampling factor
+func (r *VoxelRenderer) Supersampling() int {
+	if r.ssaa == nil {
+		return 1
+	}
+	return r.ssaa.factor
+}
+
+// bindSupersampleTarget redirects drawing to the offscreen image sized for
+// the given native viewport, reallocating it when the window was resized
+func (r *VoxelRenderer) bindSupersampleTarget(viewWidth, viewHeight int32) error {
+	s := r.ssaa
+	width := viewWidth * int32(s.factor)
+	height := viewHeight * int32(s.factor)
+
+	if width != s.width || height != s.height {
+		var maxSize int32
+		gl.GetIntegerv(gl.MAX_TEXTURE_SIZE, &maxSize)
+		if width > maxSize || height > maxSize {
+			return fmt.Errorf("supersampled size %dx%d exceeds the GPU limit of %d", width, height, maxSize)
+		}
+
+		s.deleteTarget()
+
+		gl.GenTextures(1, &s.colorTexture)
+		gl.BindTexture(gl.TEXTURE_2D, s.colorTexture)
+		gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA8, width, height, 0, gl.RGBA, gl.UNSIGNED_BYTE, nil)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+		gl.BindTexture(gl.TEXTURE_2D, 0)
+
+		gl.GenFramebuffers(1, &s.fbo)
+		gl.BindFramebuffer(gl.FRAMEBUFFER, s.fbo)
+		gl.FramebufferTexture2D(gl.FRAMEBUFFER, gl.COLOR_ATTACHMENT0, gl.TEXTURE_2D, s.colorTexture, 0)
+		if status := gl.CheckFramebufferStatus(gl.FRAMEBUFFER); status != gl.FRAMEBUFFER_COMPLETE {
+			gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+			s.deleteTarget()
+			return fmt.Errorf("supersample framebuffer incomplete: 0x%x", status)
+		}
+
+		s.width, s.height = width, height
+	}
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, s.fbo)
+	gl.Viewport(0, 0, width, height)
+	return nil
+}
+
+// resolveSupersampleTarget averages the offscreen image into the window's
+// back buffer and restores the native viewport
+func (r *VoxelRenderer) resolveSupersampleTarget(viewWidth, viewHeight int32) {
+	s := r.ssaa
+
+	gl.BindFramebuffer(gl.FRAMEBUFFER, 0)
+	gl.Viewport(0, 0, viewWidth, viewHeight)
+	gl.Clear(gl.COLOR_BUFFER_BIT | gl.DEPTH_BUFFER_BIT)
+
+	gl.UseProgram(s.program)
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D, s.colorTexture)
+	gl.Uniform1i(gl.GetUniformLocation(s.program, gl.Str("sourceTexture\x00")), 0)
+	gl.Uniform1i(gl.GetUniformLocation(s.program, gl.Str("factor\x00")), int32(s.factor))
+
+	gl.BindVertexArray(r.quadVAO)
+	gl.DrawArrays(gl.TRIANGLE_STRIP, 0, 4)
+}
+
+// deleteTarget frees the offscreen framebuffer and its texture
+func (s *supersampleTarget) deleteTarget() {
+	if s.fbo != 0 {
+		gl.DeleteFramebuffers(1, &s.fbo)
+		s.fbo = 0
+	}
+	if s.colorTexture != 0 {
+		gl.DeleteTextures(1, &s.colorTexture)
+		s.colorTexture = 0
+	}
+	s.width, s.height = 0, 0
+}
+
+// releaseSupersampling frees all supersampling resources
+func (r *VoxelRenderer) releaseSupersampling() {
+	if r.ssaa == nil {
+		return
+	}
+	r.ssaa.deleteTarget()
+	gl.DeleteProgram(r.ssaa.program)
+	r.ssaa = nil
+}
@@ -0,0 +1,71 @@
+package shaders
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v4.3-core/gl"
+)
+
+// depthOfFieldFragmentShader blurs each pixel over a disc whose radius grows
+// with its distance from the focal plane. Samples only count toward a pixel
+// when their own blur reaches it, so sharp foreground edges don't smear onto
+// the blurred planet behind them
+const depthOfFieldFragmentShader = `
+#version 410 core
+
+uniform sampler2D colorTexture;
+uniform sampler2D distanceTexture; // Camera to surface in meters, huge for space
+uniform float focusDistance;       // Meters
+uniform float aperture;            // Blur radius in pixels far beyond the focus distance
+uniform float maxRadius;           // Pixels
+
+out vec4 outColor;
+
+const int SAMPLES = 32;
+const float GOLDEN_ANGLE = 2.39996323;
+
+// blurRadius is the circle of confusion in pixels for a surface distance
+float blurRadius(float dist) {
+    float defocus = abs(dist - focusDistance) / max(dist, 1.0);
+    return min(aperture * defocus, maxRadius);
+}
+
+void main() {
+    ivec2 size = textureSize(colorTexture, 0);
+    vec2 uv = gl_FragCoord.xy / vec2(size);
+    float radius = blurRadius(texture(distanceTexture, uv).r);
+
+    vec4 sum = texture(colorTexture, uv);
+    float weight = 1.0;
+    if (radius >= 0.5) {
+        for (int i = 1; i < SAMPLES; i++) {
+            // Golden-angle spiral fills the disc evenly
+            float r = radius * sqrt(float(i) / float(SAMPLES));
+            float a = float(i) * GOLDEN_ANGLE;
+            vec2 sampleUV = uv + vec2(cos(a), sin(a)) * r / vec2(size);
+            float reach = blurRadius(texture(distanceTexture, sampleUV).r);
+            float w = clamp(reach - r + 1.0, 0.0, 1.0);
+            sum += texture(colorTexture, sampleUV) * w;
+            weight += w;
+        }
+    }
+    outColor = vec4(sum.rgb / weight, 1.0);
+}
+`
+
+// CompileDepthOfFieldShaders compiles the depth of field blur program
+func CompileDepthOfFieldShaders() (uint32, error) {
+	vertShader, err := compileShader(downsampleVertexShader, gl.VERTEX_SHADER)
+	if err != nil {
+		return 0, fmt.Errorf("depth of field vertex shader: %v", err)
+	}
+	defer gl.DeleteShader(vertShader)
+
+	fragShader, err := compileShader(depthOfFieldFragmentShader, gl.FRAGMENT_SHADER)
+	if err != nil {
+		return 0, fmt.Errorf("depth of field fragment shader: %v", err)
+	}
+	defer gl.DeleteShader(fragShader)
+
+	return linkProgram(vertShader, fragShader)
+}
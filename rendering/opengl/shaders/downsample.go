@@ -0,0 +1,64 @@
+package shaders
+
+import (
+	"fmt"
+
+	"github.com/go-gl/gl/v4.3-core/gl"
+)
+
+// downsampleVertexShader draws the same fullscreen quad as the ray marcher
+const downsampleVertexShader = `
+#version 410 core
+
+const vec2 positions[4] = vec2[](
+    vec2(-1.0, -1.0),
+    vec2( 1.0, -1.0),
+    vec2(-1.0,  1.0),
+    vec2( 1.0,  1.0)
+);
+
+void main() {
+    gl_Position = vec4(positions[gl_VertexID], 0.0, 1.0);
+}
+`
+
+// downsampleFragmentShader box-filters each factor x factor block of the
+// supersampled image into one output pixel
+const downsampleFragmentShader = `
+#version 410 core
+
+uniform sampler2D sourceTexture;
+uniform int factor;
+
+out vec4 outColor;
+
+void main() {
+    ivec2 base = ivec2(gl_FragCoord.xy) * factor;
+    ivec2 maxCoord = textureSize(sourceTexture, 0) - 1;
+
+    vec4 sum = vec4(0.0);
+    for (int y = 0; y < factor; y++) {
+        for (int x = 0; x < factor; x++) {
+            sum += texelFetch(sourceTexture, min(base + ivec2(x, y), maxCoord), 0);
+        }
+    }
+    outColor = sum / float(factor * factor);
+}
+`
+
+// CompileDownsampleShaders compiles the supersampling resolve program
+func CompileDownsampleShaders() (uint32, error) {
+	vertShader, err := compileShader(downsampleVertexShader, gl.VERTEX_SHADER)
+	if err != nil {
+		return 0, fmt.Errorf("downsample vertex shader: %v", err)
+	}
+	defer gl.DeleteShader(vertShader)
+
+	fragShader, err := compileShader(downsampleFragmentShader, gl.FRAGMENT_SHADER)
+	if err != nil {
+		return 0, fmt.Errorf("downsample fragment shader: %v", err)
+	}
+	defer gl.DeleteShader(fragShader)
+
+	return linkProgram(vertShader, fragShader)
+}
@@ -31,12 +31,14 @@ const voxelRayMarchFragmentShaderV2 = `
 #version 410 core
 
 in vec2 fragCoord;
-out vec4 outColor;
+layout(location = 0) out vec4 outColor;
+layout(location = 1) out float outDistance; // Camera to surface (m), read by depth of field
 
 // Uniforms
 uniform mat4 invViewProj;
 uniform vec3 cameraPos;
 uniform float planetRadius;
+uniform float flattening; // (a-b)/a, see core.VoxelPlanet.Flattening
 uniform int renderMode;
 uniform float debugValue; // For testing uniform passing
 uniform int crossSection;
@@ -44,17 +46,128 @@ uniform int crossSectionAxis;
 uniform float crossSectionPos;
 uniform int shellCount;
 uniform float time;
+uniform float stepScale; // Ray march step as a fraction of planetRadius, set from camera distance
+
+// Flat world map instead of the globe, see mapPoint in renderer_gl_map.go
+const int PROJECTION_EQUIRECTANGULAR = 1;
+const int PROJECTION_MOLLWEIDE = 2;
+uniform int mapProjection; // 0 = globe
+uniform float mapCenterLon; // Radians
+uniform float mapPan;
+uniform float mapZoom;
+uniform float viewportAspect;
+
+// Graticule (lat/lon grid) overlay
+uniform int showGraticule;
+uniform float graticuleSpacing; // Degrees between lines
+uniform vec3 graticuleColor;
+
+// River overlay, drawn from physics.ComputeRiverNetwork
+uniform int showRivers;
+uniform sampler2D riverTexture; // 0 = no river, 0.5-1 = small to large river
+uniform vec3 riverColor;
+
+// Plate drift paths, from simulation.PlateManager.GetPlateTracks
+uniform int showPlateTracks;
+uniform sampler2D plateTrackTexture; // Plate colors, premultiplied by alpha
+
+// Biome view, from physics.ClassifyBiomes
+const int BIOME_COUNT = 11; // core.BiomeCount
+uniform sampler2D biomeTexture; // core.Biome of each surface voxel
+uniform vec3 biomeColors[BIOME_COUNT];
+
+// Voxel grid overlay
+uniform int showShellGrid;
+uniform sampler2D lonCountTexture; // Longitude cells of each band (x) of each shell (y)
+
+// Ocean depth tinting (material mode)
+uniform vec3 oceanShallowColor;
+uniform vec3 oceanDeepColor;
+uniform float oceanTransparency; // 0=opaque water, 1=shallow seafloor fully visible
+
+// Incandescent glow of magma and cooling lava
+uniform float emissiveScale; // Multiplies the glow (0 = off, 1 = default)
+
+// Colors for every material type, including ones registered at runtime,
+// from the renderer's MaterialPalette
+const int MAX_MATERIALS = 64;
+uniform vec3 materialColors[MAX_MATERIALS];
+uniform int materialCount;
 
 // Voxel data textures
 uniform sampler2DArray materialTexture;
 uniform sampler2DArray temperatureTexture;
 uniform sampler2DArray velocityTexture;
 uniform sampler1D shellInfoTexture;
+uniform sampler2D heatFluxTexture; // Surface heat flux in W/m²
+
+// Heat flux view scaling (W/m²), the range of the current upload
+uniform vec2 heatFluxRange;
+
+// Colormaps baked from core.Colormaps, one row each in this order, with the
+// value range each row spans
+const int COLORMAP_TEMPERATURE = 0;
+const int COLORMAP_ELEVATION = 1;
+const int COLORMAP_AGE = 2;
+const int COLORMAP_COUNT = 3;
+uniform sampler2D colormapTexture;
+uniform vec2 colormapRanges[COLORMAP_COUNT];
+
+// Hue in degrees of each plate by ID, from simulation.PlateColorRegistry
+// (-1 = none). Plates beyond the table hash their ID instead
+uniform sampler1D plateHueTexture;
+uniform int plateHueCount;
+
+// The same fields one physics update earlier, blended toward the latest so
+// motion stays smooth between updates
+uniform sampler2DArray prevTemperatureTexture;
+uniform sampler2DArray prevVelocityTexture;
+uniform float interpolation; // 0 = previous update, 1 = latest
+
+// temperatureAt returns temperature, elevation, plate ID and stress, blended
+// between physics updates. Plate IDs are labels, so they come from the latest
+vec4 temperatureAt(vec3 texCoord) {
+    vec4 latest = texture(temperatureTexture, texCoord);
+    if (interpolation >= 1.0) return latest;
+    vec4 blended = mix(texture(prevTemperatureTexture, texCoord), latest, interpolation);
+    blended.b = latest.b;
+    return blended;
+}
+
+// velocityAt returns the north/east velocity and lat/lon sub-position,
+// blended between physics updates. A sub-position that wrapped because the
+// material moved into the next cell is taken from the latest
+vec4 velocityAt(vec3 texCoord) {
+    vec4 latest = texture(velocityTexture, texCoord);
+    if (interpolation >= 1.0) return latest;
+    vec4 previous = texture(prevVelocityTexture, texCoord);
+    vec4 blended = mix(previous, latest, interpolation);
+    bvec2 wrapped = greaterThan(abs(latest.ba - previous.ba), vec2(0.5));
+    blended.ba = mix(blended.ba, latest.ba, vec2(wrapped));
+    return blended;
+}
 
 // Constants
 const float EPSILON = 0.001;
 const int MAX_STEPS = 200;
-const float STEP_SCALE = 0.01; // Balance between quality and performance
+const float OCEAN_DEEP_DEPTH = 5000.0;    // Meters below sea level where water reaches oceanDeepColor
+const float OCEAN_VISIBILITY_DEPTH = 150.0; // Meters of water that dim the seafloor by 1/e
+const float GLOW_MIN_TEMP = 900.0;  // Kelvin where magma and basalt start to glow
+const float GLOW_MAX_TEMP = 1500.0; // Kelvin of full glow
+const float STRESS_MIN = 1e5;       // Pascals of the quietest stress shown (0.1 MPa)
+const float STRESS_MAX = 1e9;       // Pascals of the strongest cold lithosphere (1 GPa)
+const float STRESS_GLOW_MIN = 5e7;  // Pascals where rock near failure starts to glow
+const float STRESS_GLOW_MAX = 2e8;  // Pascals of full glow, the yield strength of weak crust
+const float WIND_MAX = 10.0;        // m/s of the strongest wind shown
+const float CM_PER_YEAR = 100.0;     // cm/year per m/year of voxel velocity, core.CmPerYear(1)
+const float PLATE_SPEED_MAX = 10.0;  // cm/year of the fastest plate motion shown
+const vec3 SHELL_EDGE_COLOR = vec3(1.0, 1.0, 1.0);
+const vec3 CELL_EDGE_COLOR = vec3(1.0, 0.8, 0.2);
+
+// World size of a pixel t meters along the ray is pixelSpread + t * pixelAngle,
+// set in main for drawing lines about a pixel wide
+float pixelSpread;
+float pixelAngle;
 
 // Material properties
 struct MaterialProps {
@@ -66,53 +179,54 @@ struct MaterialProps {
 MaterialProps getMaterialProps(int matType) {
     MaterialProps props;
     props.emissive = 0.0;
-    
+
+    // Unknown materials are magenta
+    props.color = (matType >= 0 && matType < materialCount && matType < MAX_MATERIALS)
+        ? materialColors[matType] : vec3(1.0, 0.0, 1.0);
+
     switch(matType) {
         case 0: // Air
-            props.color = vec3(0.7, 0.8, 1.0);
             props.opacity = 0.001; // Extremely transparent air
             break;
-        case 1: // Water
-            props.color = vec3(0.0, 0.0, 1.0); // BRIGHT BLUE for debugging
-            props.opacity = 1.0; // Fully opaque ocean
-            break;
-        case 2: // Basalt
-            props.color = vec3(0.5, 0.5, 0.5); // Grey volcanic rock
-            props.opacity = 1.0;
-            break;
-        case 3: // Granite
-            props.color = vec3(0.0, 1.0, 0.0); // BRIGHT GREEN for debugging
-            props.opacity = 1.0;
-            break;
         case 4: // Peridotite
-            props.color = vec3(0.5, 0.4, 0.3);
             props.opacity = 0.8;
             break;
         case 5: // Magma
-            props.color = vec3(1.0, 0.3, 0.0);
-            props.opacity = 0.9;
-            props.emissive = 0.5;
-            break;
-        case 6: // Sediment
-            props.color = vec3(0.9, 0.8, 0.6);
-            props.opacity = 1.0;
+            props.opacity = 0.9; // Glow comes from lavaGlow, driven by temperature
             break;
         case 7: // Ice
-            props.color = vec3(0.95, 0.95, 1.0);
             props.opacity = 0.7;
             break;
-        case 8: // Sand
-            props.color = vec3(0.8, 0.7, 0.5);
-            props.opacity = 1.0;
-            break;
         default:
-            props.color = vec3(1.0, 0.0, 1.0);
             props.opacity = 1.0;
     }
-    
+
     return props;
 }
 
+// A flattened planet is ray marched stretched along its polar axis, where
+// it and every shell become spheres of their equatorial radius, so shell
+// lookups stay a matter of distance from the center. rayMarchVolume takes
+// its ray there once; see toSphereSpace in renderer_gl_picking.go
+vec3 toSphereSpace(vec3 p) {
+    return vec3(p.x, p.y / (1.0 - flattening), p.z);
+}
+
+vec3 toWorldSpace(vec3 p) {
+    return vec3(p.x, p.y * (1.0 - flattening), p.z);
+}
+
+// Geodetic latitude of a sphere space direction, the latitude the voxel
+// bands are laid out in; asin(n.y) on a sphere
+float geodeticLatitude(vec3 n) {
+    return atan(n.y, (1.0 - flattening) * length(n.xz));
+}
+
+// World space surface normal at a sphere space position
+vec3 surfaceNormal(vec3 pos) {
+    return normalize(vec3(pos.x, pos.y / (1.0 - flattening), pos.z));
+}
+
 // Find which shell contains a given radius
 int findShell(float r) {
     for (int i = 0; i < shellCount; i++) {
@@ -136,7 +250,7 @@ float getPlateID(vec3 pos) {
     // Convert to spherical coordinates
     // Camera uses Y as up, so Y is our vertical axis
     vec3 normalized = normalize(pos);
-    float lat = asin(clamp(normalized.y, -1.0, 1.0));
+    float lat = geodeticLatitude(normalized);
     float lon = atan(normalized.z, normalized.x);
     
     // Convert to texture coordinates
@@ -144,7 +258,21 @@ float getPlateID(vec3 pos) {
     float v = (lat + 1.57079633) / 3.14159265;
     
     vec3 texCoord = vec3(u, v, float(shell));
-    return texture(temperatureTexture, texCoord).b; // PlateID is in blue channel
+    return temperatureAt(texCoord).b; // PlateID is in blue channel
+}
+
+// plateColor returns a plate's color in plate view, from its lineage's hue
+vec3 plateColor(float plateID) {
+    int id = int(plateID + 0.5);
+    float hue = id < plateHueCount ? texelFetch(plateHueTexture, id, 0).r : -1.0;
+    if (hue < 0.0) {
+        hue = mod(plateID * 137.5, 360.0);
+    }
+    // HSV to RGB conversion
+    vec3 c = vec3(hue / 360.0, 0.7, 0.8);
+    vec4 K = vec4(1.0, 2.0 / 3.0, 1.0 / 3.0, 3.0);
+    vec3 p = abs(fract(c.xxx + K.xyz) * 6.0 - K.www);
+    return c.z * mix(K.xxx, clamp(p - K.xxx, 0.0, 1.0), c.y);
 }
 
 // Sample voxel data at a 3D position with smoothing
@@ -158,7 +286,7 @@ vec4 sampleVoxelData(vec3 pos) {
     // Convert to spherical coordinates
     // Camera uses Y as up, so Y is our vertical axis
     vec3 normalized = normalize(pos);
-    float lat = asin(clamp(normalized.y, -1.0, 1.0)); // -PI/2 to PI/2
+    float lat = geodeticLatitude(normalized); // -PI/2 to PI/2
     float lon = atan(normalized.z, normalized.x); // -PI to PI
     
     // Convert to texture coordinates matching the texture generation
@@ -174,12 +302,195 @@ vec4 sampleVoxelData(vec3 pos) {
     }
     
     float matType = texture(materialTexture, texCoord).r; // Use nearest filtering for materials
-    vec3 tempElevPlate = texture(temperatureTexture, texCoord).rgb; // Temperature, elevation, plateID
-    vec2 vel = texture(velocityTexture, texCoord).rg;
+    vec3 tempElevPlate = temperatureAt(texCoord).rgb; // Temperature, elevation, plateID (stress in alpha)
+    vec2 vel = velocityAt(texCoord).rg;
     
     return vec4(matType, tempElevPlate.g, vel.x, vel.y); // Return elevation in .y component
 }
 
+// Color of open water over a seafloor at the given elevation (negative below sea level)
+// Deeper water darkens toward oceanDeepColor, and in shallow seas the seafloor
+// material in the shell below shows through according to oceanTransparency
+vec3 oceanColor(float elevation, vec3 texCoord) {
+    float depth = max(-elevation, 0.0);
+    vec3 water = mix(oceanShallowColor, oceanDeepColor, sqrt(clamp(depth / OCEAN_DEEP_DEPTH, 0.0, 1.0)));
+
+    // Seafloor under the water column; the ocean crust is assumed basaltic
+    // where the shell below is mantle or fluid
+    int floorMat = 2;
+    if (texCoord.z > 0.0) {
+        int below = int(texture(materialTexture, texCoord - vec3(0.0, 0.0, 1.0)).r + 0.5);
+        if (below == 2 || below == 3 || below == 6 || below == 8) {
+            floorMat = below;
+        }
+    }
+    vec3 seafloor = getMaterialProps(floorMat).color;
+
+    float visibility = clamp(oceanTransparency, 0.0, 1.0) * exp(-depth / OCEAN_VISIBILITY_DEPTH);
+    return mix(water, seafloor, visibility);
+}
+
+// Glow strength of magma and basalt at a temperature in kelvin
+// Basalt fades out as it cools below GLOW_MIN_TEMP, so fresh seafloor at
+// ridges and new arc volcanics show a rim of cooling lava
+float lavaGlow(int matType, float temperature) {
+    if (matType != 2 && matType != 5) return 0.0;
+    return emissiveScale * smoothstep(GLOW_MIN_TEMP, GLOW_MAX_TEMP, temperature);
+}
+
+// Glow color from dull red at GLOW_MIN_TEMP to orange-yellow at GLOW_MAX_TEMP
+vec3 glowColor(float temperature) {
+    float t = clamp((temperature - GLOW_MIN_TEMP) / (GLOW_MAX_TEMP - GLOW_MIN_TEMP), 0.0, 1.0);
+    return mix(vec3(0.8, 0.1, 0.0), vec3(1.0, 0.75, 0.3), t);
+}
+
+// Stress ramp on a log scale from STRESS_MIN in dark blue through purple to
+// orange at STRESS_MAX, so strain building at locked boundaries stands out
+vec3 stressColor(float stress) {
+    float t = clamp(log(max(stress, STRESS_MIN) / STRESS_MIN) / log(STRESS_MAX / STRESS_MIN), 0.0, 1.0) * 2.0;
+    if (t < 1.0) return mix(vec3(0.05, 0.05, 0.2), vec3(0.55, 0.1, 0.55), t);
+    return mix(vec3(0.55, 0.1, 0.55), vec3(1.0, 0.55, 0.1), t - 1.0);
+}
+
+// Glow of rock close to failing in an earthquake
+float stressGlow(float stress) {
+    return smoothstep(STRESS_GLOW_MIN, STRESS_GLOW_MAX, stress);
+}
+
+// Color of a value on one of the colormaps, clamped to its range
+vec3 colormap(int row, float value) {
+    vec2 bounds = colormapRanges[row];
+    float t = clamp((value - bounds.x) / (bounds.y - bounds.x), 0.0, 1.0);
+    float size = float(textureSize(colormapTexture, 0).x);
+    float u = (t * (size - 1.0) + 0.5) / size; // Texel centers hold the range ends
+    return texture(colormapTexture, vec2(u, (float(row) + 0.5) / float(COLORMAP_COUNT))).rgb;
+}
+
+// Heat flux ramp: cold cratons in blue through white to red and yellow ridges
+// Keep in sync with HeatFluxLegend in renderer_gl_heat_flux.go
+vec3 heatFluxColor(float flux) {
+    float span = max(heatFluxRange.y - heatFluxRange.x, 1e-9);
+    float t = clamp((flux - heatFluxRange.x) / span, 0.0, 1.0) * 3.0;
+    if (t < 1.0) return mix(vec3(0.05, 0.1, 0.45), vec3(0.9, 0.9, 0.9), t);
+    if (t < 2.0) return mix(vec3(0.9, 0.9, 0.9), vec3(0.85, 0.1, 0.05), t - 1.0);
+    return mix(vec3(0.85, 0.1, 0.05), vec3(1.0, 0.95, 0.3), t - 2.0);
+}
+
+// Blend latitude/longitude grid lines over a surface color
+vec3 applyGraticule(vec3 color, float lat, float lon, vec3 normal, vec3 rd) {
+    float latDeg = degrees(lat);
+    float lonDeg = degrees(lon);
+    float spacing = max(graticuleSpacing, 0.1);
+    float width = spacing * 0.02;
+
+    // Angular distance to the nearest line; longitude distance shrinks toward the poles
+    float latDist = abs(fract(latDeg / spacing + 0.5) - 0.5) * spacing;
+    float lonDist = abs(fract(lonDeg / spacing + 0.5) - 0.5) * spacing * cos(lat);
+
+    // Equator and prime meridian are drawn wider and fully opaque
+    bool isEquator = abs(latDeg) < spacing * 0.5;
+    bool isPrimeMeridian = abs(lonDeg) < spacing * 0.5;
+    float latLine = 1.0 - smoothstep(0.0, isEquator ? width * 2.5 : width, latDist);
+    float lonLine = 1.0 - smoothstep(0.0, isPrimeMeridian ? width * 2.5 : width, lonDist);
+    float strength = max(latLine * (isEquator ? 1.0 : 0.6),
+                         lonLine * (isPrimeMeridian ? 1.0 : 0.6));
+
+    // Fade out toward the limb so lines don't crowd at the horizon
+    float facing = smoothstep(0.0, 0.3, dot(normal, -rd));
+    return mix(color, graticuleColor, strength * facing);
+}
+
+// Blend rivers over a land color; the sea hides their last stretch
+vec3 applyRivers(vec3 color, float u, float v) {
+    float river = texture(riverTexture, vec2(u, v)).r;
+    return mix(color, riverColor, smoothstep(0.1, 0.5, river) * river);
+}
+
+// Tint a color by the prevailing wind in the atmosphere shell above it,
+// warm for westerlies and cool for easterlies, stronger with speed
+vec3 applyWind(vec3 color, float u, float v) {
+    vec2 wind = velocityAt(vec3(u, v, float(shellCount - 1))).rg; // North, east
+    vec3 windColor = wind.y > 0.0 ? vec3(1.0, 0.45, 0.1) : vec3(0.1, 0.8, 1.0);
+    return mix(color, windColor, 0.8 * clamp(length(wind) / WIND_MAX, 0.0, 1.0));
+}
+
+// Distances in meters from pos to the nearest shell boundary (x) and to the
+// nearest latitude or longitude edge of its voxel (y), negative outside the
+// shells. Cells follow each band's own longitude count, as in
+// sampleVoxelAtLocation in voxel_texture_data.go
+vec2 gridEdgeDistance(vec3 pos) {
+    float r = length(pos);
+    int shell = findShell(r);
+    if (shell < 0) return vec2(-1.0);
+    vec4 shellInfo = texelFetch(shellInfoTexture, shell, 0);
+    float latBands = shellInfo.z;
+
+    vec3 normalized = pos / r;
+    float lat = geodeticLatitude(normalized);
+    float lon = atan(normalized.z, normalized.x);
+
+    float radial = min(r - shellInfo.x, shellInfo.y - r);
+
+    float latCell = (lat + 1.57079633) / 3.14159265 * latBands;
+    float latEdge = abs(fract(latCell + 0.5) - 0.5) * 3.14159265 / latBands * r;
+
+    int band = clamp(int(latCell), 0, int(latBands) - 1);
+    float lonCount = texelFetch(lonCountTexture, ivec2(band, shell), 0).r;
+    float lonCell = (lon + 3.14159265) / 6.28318531 * lonCount;
+    float lonEdge = abs(fract(lonCell + 0.5) - 0.5) * 6.28318531 / lonCount * r * cos(lat);
+
+    return vec2(radial, min(latEdge, lonEdge));
+}
+
+// Voxel grid line color and coverage at pos, seen from t meters away
+// Shell boundaries are only meaningful on a cut through the shells
+vec4 shellGridLines(vec3 pos, float t, bool showShells) {
+    vec2 dist = gridEdgeDistance(pos);
+    if (dist.x < 0.0) return vec4(0.0);
+    float width = max(pixelSpread + t * pixelAngle, 1.0);
+    float cell = (1.0 - smoothstep(0.0, width, dist.y)) * 0.7;
+    float shellLine = showShells ? 1.0 - smoothstep(0.0, width * 1.5, dist.x) : 0.0;
+    return shellLine >= cell ? vec4(SHELL_EDGE_COLOR, shellLine) : vec4(CELL_EDGE_COLOR, cell);
+}
+
+// Coordinate of v along the cross-section axis
+float crossSectionCoord(vec3 v) {
+    return (crossSectionAxis == 0) ? v.x : (crossSectionAxis == 1) ? v.y : v.z;
+}
+
+// Whether the cross-section removes pos, in sphere space; maps always show the whole surface
+bool cutAway(vec3 pos) {
+    return crossSection > 0 && mapProjection == 0 && crossSectionCoord(toWorldSpace(pos)) < crossSectionPos;
+}
+
+// Latitude and longitude drawn at a screen position on the map, or false off
+// the map. Keep in sync with mapPoint and mapLatLon in renderer_gl_map.go
+bool mapLatLon(vec2 screen, out float lat, out float lon) {
+    float halfHeight = min(1.0, viewportAspect / 2.0) * mapZoom;
+    vec2 p = (screen * 2.0 - 1.0) * vec2(viewportAspect, 1.0) / halfHeight + vec2(0.0, mapPan);
+    lat = 0.0;
+    lon = 0.0;
+
+    if (mapProjection == PROJECTION_EQUIRECTANGULAR) {
+        if (abs(p.y) > 1.0) return false;
+        lat = p.y * 1.57079633;
+        lon = p.x * 1.57079633;
+    } else if (mapProjection == PROJECTION_MOLLWEIDE) {
+        vec2 m = p * 1.41421356;
+        if (m.x * m.x / 8.0 + m.y * m.y / 2.0 > 1.0) return false;
+        float theta = asin(clamp(m.y / 1.41421356, -1.0, 1.0));
+        lat = asin(clamp((2.0 * theta + sin(2.0 * theta)) / 3.14159265, -1.0, 1.0));
+        float c = cos(theta);
+        if (c > 1e-6) lon = 3.14159265 * m.x / (2.0 * 1.41421356 * c);
+    } else {
+        return false;
+    }
+
+    // Wrap into -180° to 180°
+    lon = mod(lon + mapCenterLon + 3.14159265, 6.28318531) - 3.14159265;
+    return true;
+}
+
 // Ray-sphere intersection
 bool raySphereIntersect(vec3 ro, vec3 rd, float radius, out float t0, out float t1) {
     vec3 oc = ro; // ray origin relative to sphere center (at origin)
@@ -197,8 +508,20 @@ bool raySphereIntersect(vec3 ro, vec3 rd, float radius, out float t0, out float
     return true;
 }
 
+// Ray-ellipsoid intersection for a world space ray and the flattened planet
+// of equatorial radius radius. The ray is stretched into sphere space without
+// renormalizing, so t0 and t1 still measure along the world space ray
+bool rayEllipsoidIntersect(vec3 ro, vec3 rd, float radius, out float t0, out float t1) {
+    return raySphereIntersect(toSphereSpace(ro), toSphereSpace(rd), radius, t0, t1);
+}
+
 // Volume ray marching with proper opacity accumulation
 vec4 rayMarchVolume(vec3 ro, vec3 rd) {
+    // March in sphere space, lighting with the world space view direction
+    vec3 viewDir = normalize(rd);
+    ro = toSphereSpace(ro);
+    rd = toSphereSpace(rd);
+
     // Find entry and exit points
     float t0, t1;
     float atmosphereRadius = planetRadius * 1.01; // Include thin atmosphere
@@ -210,10 +533,10 @@ vec4 rayMarchVolume(vec3 ro, vec3 rd) {
     // Use surface rendering for better performance and appearance
     float t0_surface, t1_surface;
     if (raySphereIntersect(ro, rd, planetRadius, t0_surface, t1_surface)) {
-        if (t0_surface > 0.0) {
+        if (t0_surface > 0.0 && !cutAway(ro + rd * t0_surface)) {
             // Hit the planet surface
             vec3 hitPos = ro + rd * t0_surface;
-            vec3 normal = normalize(hitPos);
+            vec3 normal = surfaceNormal(hitPos);
             
             // Sample slightly inside the surface to avoid shell boundary issues
             vec3 samplePos = hitPos * 0.999; // Move 0.1% inward
@@ -230,103 +553,87 @@ vec4 rayMarchVolume(vec3 ro, vec3 rd) {
             
             // Calculate texture coordinates for this position
             vec3 normalized = normalize(samplePos);
-            float lat = asin(clamp(normalized.y, -1.0, 1.0));
+            float lat = geodeticLatitude(normalized);
             float lon = atan(normalized.z, normalized.x);
             float u = (lon + 3.14159265) / (2.0 * 3.14159265);
             float v = (lat + 1.57079633) / 3.14159265;
             
             // Apply render modes for surface rendering
-            if (renderMode == 0) { // Material mode - use the bright colors we already got
-                // color is already set from getMaterialProps above
-                // Don't change it!
-                // DEBUG: Make sure we see bright colors
-                if (matType == 1) color = vec3(0.0, 0.0, 1.0); // Bright blue water
-                if (matType == 3) color = vec3(0.0, 1.0, 0.0); // Bright green land
-                // DEBUG: Show material type for debugging
-                if (matType == 0) color = vec3(1.0, 1.0, 0.0); // Yellow for air (shouldn't see this!)
-                if (matType == 2) color = vec3(0.5, 0.5, 0.5); // Grey for basalt
-                if (matType == 6) color = vec3(0.9, 0.8, 0.6); // Sandy tan for sediment
-                if (matType == 10) color = vec3(1.0, 0.0, 0.0); // RED for invalid shell
-                
+            if (renderMode == 0) { // Material mode: palette colors from getMaterialProps
+                if (matType == 1) { // Water tinted by depth over the seafloor
+                    color = oceanColor(voxelData.y, vec3(u, v, float(findShell(length(samplePos)))));
+                }
             } else if (renderMode == 7) { // Elevation visualization
                 float elevation = voxelData.y; // From temperature texture's G channel
-                
-                // Earth-like elevation colors
-                if (elevation < -4000.0) {
-                    color = vec3(0.05, 0.1, 0.3); // Deep ocean
-                } else if (elevation < -2000.0) {
-                    float t = (elevation + 4000.0) / 2000.0;
-                    color = mix(vec3(0.05, 0.1, 0.3), vec3(0.1, 0.3, 0.6), t);
-                } else if (elevation < -200.0) {
-                    float t = (elevation + 2000.0) / 1800.0;
-                    color = mix(vec3(0.1, 0.3, 0.6), vec3(0.2, 0.5, 0.8), t);
-                } else if (elevation < 0.0) {
-                    float t = (elevation + 200.0) / 200.0;
-                    color = mix(vec3(0.2, 0.5, 0.8), vec3(0.3, 0.6, 0.85), t);
-                } else if (elevation < 50.0) {
-                    float t = elevation / 50.0;
-                    color = mix(vec3(0.76, 0.7, 0.5), vec3(0.7, 0.65, 0.45), t);
-                } else if (elevation < 500.0) {
-                    float t = (elevation - 50.0) / 450.0;
-                    color = mix(vec3(0.7, 0.65, 0.45), vec3(0.3, 0.5, 0.2), t);
-                } else if (elevation < 1500.0) {
-                    float t = (elevation - 500.0) / 1000.0;
-                    vec3 hillGreen = vec3(0.25, 0.4, 0.15);
-                    vec3 hillBrown = vec3(0.4, 0.35, 0.25);
-                    color = mix(vec3(0.3, 0.5, 0.2), mix(hillGreen, hillBrown, t*0.3), t);
-                } else if (elevation < 3000.0) {
-                    float t = (elevation - 1500.0) / 1500.0;
-                    vec3 brownRock = vec3(0.45, 0.35, 0.25);
-                    vec3 greyRock = vec3(0.5, 0.48, 0.45);
-                    color = mix(brownRock, greyRock, t);
-                } else if (elevation < 4500.0) {
-                    float t = (elevation - 3000.0) / 1500.0;
-                    color = mix(vec3(0.5, 0.48, 0.45), vec3(0.4, 0.38, 0.36), t);
-                } else {
-                    float t = min((elevation - 4500.0) / 1500.0, 1.0);
-                    color = mix(vec3(0.4, 0.38, 0.36), vec3(0.95, 0.96, 0.98), t);
-                }
+                color = colormap(COLORMAP_ELEVATION, elevation);
             } else if (renderMode == 1) { // Temperature
                 // Need to fetch temperature from texture directly
                 int shell = findShell(length(samplePos));
-                vec3 tempElevPlate = texture(temperatureTexture, vec3(u, v, float(shell))).rgb;
+                vec3 tempElevPlate = temperatureAt(vec3(u, v, float(shell))).rgb;
                 float temp = tempElevPlate.r; // Temperature is in R channel
-                float normalizedTemp = clamp((temp - 273.0) / 3000.0, 0.0, 1.0);
-                color = mix(vec3(0.0, 0.0, 1.0), vec3(1.0, 0.0, 0.0), normalizedTemp);
-            } else if (renderMode == 2) { // Velocity
-                float vel = length(voxelData.zw) * 1e9;
-                color = mix(vec3(0.0, 0.0, 0.5), vec3(1.0, 1.0, 0.0), clamp(vel / 5.0, 0.0, 1.0));
+                color = colormap(COLORMAP_TEMPERATURE, temp);
+            } else if (renderMode == 2) { // Velocity: plate motion under the winds
+                float vel = length(voxelData.zw) * CM_PER_YEAR;
+                color = mix(vec3(0.0, 0.0, 0.5), vec3(1.0, 1.0, 0.0), clamp(vel / PLATE_SPEED_MAX, 0.0, 1.0));
+                color = applyWind(color, u, v);
             } else if (renderMode == 4) { // Plate visualization
                 // Use actual plate ID from texture
                 float plateID = getPlateID(samplePos);
                 if (plateID > 0.0 && (matType == 2 || matType == 3)) {
-                    // Generate color from plate ID
-                    float hue = mod(plateID * 137.5, 360.0) / 360.0;
-                    // HSV to RGB conversion
-                    vec3 c = vec3(hue, 0.7, 0.8);
-                    vec4 K = vec4(1.0, 2.0 / 3.0, 1.0 / 3.0, 3.0);
-                    vec3 p = abs(fract(c.xxx + K.xyz) * 6.0 - K.www);
-                    color = c.z * mix(K.xxx, clamp(p - K.xxx, 0.0, 1.0), c.y);
+                    color = plateColor(plateID);
                 } else {
                     color = vec3(0.1, 0.1, 0.1);
                 }
+            } else if (renderMode == 8) { // Surface heat flux
+                color = heatFluxColor(texture(heatFluxTexture, vec2(u, v)).r);
             } else if (renderMode == 5) { // Stress
-                float vel = length(voxelData.zw) * 1e9;
-                if (vel > 0.01) {
-                    float normalizedVel = vel / 5.0;
-                    color = mix(vec3(0.0, 0.0, 0.5), vec3(1.0, 0.0, 0.0), clamp(normalizedVel, 0.0, 1.0));
-                } else {
-                    color = vec3(0.05, 0.05, 0.2);
-                }
+                color = stressColor(temperatureAt(vec3(u, v, float(findShell(length(samplePos))))).a);
+            } else if (renderMode == 9) { // Biomes
+                int biome = int(texture(biomeTexture, vec2(u, v)).r + 0.5);
+                color = biomeColors[clamp(biome, 0, BIOME_COUNT - 1)];
             }
             
-            // Bright lighting
+            // Bright lighting; maps are lit evenly
             vec3 lightDir = normalize(vec3(1.0, 1.0, 0.5));
-            float NdotL = max(dot(normal, lightDir), 0.0);
+            float NdotL = mapProjection > 0 ? 0.6 : max(dot(normal, lightDir), 0.0);
             color = color * (0.7 + 0.5 * NdotL);
             
+            // Hot lava glows on its own, unaffected by lighting
+            if (renderMode == 0) {
+                float surfaceTemp = temperatureAt(vec3(u, v, float(findShell(length(samplePos))))).r;
+                color += glowColor(surfaceTemp) * lavaGlow(matType, surfaceTemp);
+            }
+
+            // So does rock about to slip
+            if (renderMode == 5) {
+                float stress = temperatureAt(vec3(u, v, float(findShell(length(samplePos))))).a;
+                color += vec3(1.0, 0.9, 0.5) * stressGlow(stress);
+            }
+            
+            // Rivers
+            if (showRivers > 0 && matType != 1) {
+                color = applyRivers(color, u, v);
+            }
+
+            // Plate tracks, over land and sea alike
+            if (showPlateTracks > 0) {
+                vec4 track = texture(plateTrackTexture, vec2(u, v));
+                color = color * (1.0 - track.a) + track.rgb;
+            }
+
+            // Lat/lon grid
+            if (showGraticule > 0) {
+                color = applyGraticule(color, lat, lon, normal, viewDir);
+            }
+
+            // Voxel cells of the surface shell
+            if (showShellGrid > 0) {
+                vec4 grid = shellGridLines(samplePos, t0_surface, false);
+                color = mix(color, grid.rgb, grid.a);
+            }
+            
             // Add subtle atmosphere effect
-            float fresnel = 1.0 - max(dot(normal, -rd), 0.0);
+            float fresnel = 1.0 - max(dot(normal, -viewDir), 0.0);
             color += vec3(0.05, 0.1, 0.2) * pow(fresnel, 3.0);
             
             return vec4(color, 1.0);
@@ -337,29 +644,40 @@ vec4 rayMarchVolume(vec3 ro, vec3 rd) {
     float tStart = max(t0, 0.0);
     float tEnd = t1;
     
-    // Adaptive step size based on distance from camera
-    float baseStep = planetRadius * STEP_SCALE;
+    // Step size follows camera distance: coarse from far away, fine up close
+    float baseStep = planetRadius * stepScale;
     
     // Accumulate color and opacity
     vec3 accumColor = vec3(0.0);
     float accumAlpha = 0.0;
     
     // Ray march through the volume
-    float t = tStart;
+    // Start each pixel a fraction of a step in so the sample shells don't line
+    // up across the screen as bands when the step size changes with zoom
+    float jitter = fract(52.9829189 * fract(dot(gl_FragCoord.xy, vec2(0.06711056, 0.00583715))));
+    float t = tStart + baseStep * jitter;
     int steps = 0;
+    bool culled = false;
     
     while (t < tEnd && accumAlpha < 0.99 && steps < MAX_STEPS) {
         vec3 pos = ro + rd * t;
         
         // Cross-section culling
-        if (crossSection > 0) {
-            float coord = (crossSectionAxis == 0) ? pos.x :
-                         (crossSectionAxis == 1) ? pos.y : pos.z;
-            if (coord < crossSectionPos) {
-                t += baseStep;
-                steps++;
-                continue;
-            }
+        if (cutAway(pos)) {
+            culled = true;
+            t += baseStep;
+            steps++;
+            continue;
+        }
+
+        // The ray just crossed the cut face: lay the voxel grid over it,
+        // drawn where the ray meets the plane
+        if (culled && showShellGrid > 0) {
+            culled = false;
+            float tCut = (crossSectionPos - crossSectionCoord(toWorldSpace(ro))) / crossSectionCoord(toWorldSpace(rd));
+            vec4 grid = shellGridLines(ro + rd * tCut, tCut, true);
+            accumColor += grid.rgb * grid.a * (1.0 - accumAlpha);
+            accumAlpha += grid.a * (1.0 - accumAlpha);
         }
         
         // Sample voxel data
@@ -370,7 +688,7 @@ vec4 rayMarchVolume(vec3 ro, vec3 rd) {
         // For sub-position visualization, we need to sample the full velocity texture
         float shellIndex = float(findShell(length(pos)));
         vec3 normalized = normalize(pos);
-        float lat = asin(clamp(normalized.y, -1.0, 1.0));
+        float lat = geodeticLatitude(normalized);
         float lon = atan(normalized.z, normalized.x);
         float u = (lon + 3.14159265) / (2.0 * 3.14159265);
         float v = (lat + 1.57079633) / 3.14159265;
@@ -384,46 +702,25 @@ vec4 rayMarchVolume(vec3 ro, vec3 rd) {
         // Visualization modes
         vec3 color = props.color;
         if (renderMode == 1) { // Temperature
-            float normalizedTemp = clamp((temperature - 273.0) / 3000.0, 0.0, 1.0);
-            color = mix(vec3(0.0, 0.0, 1.0), vec3(1.0, 0.0, 0.0), normalizedTemp);
+            color = colormap(COLORMAP_TEMPERATURE, temperature);
             props.opacity = 0.1; // Make temperature semi-transparent
         } else if (renderMode == 2) { // Velocity
-            float vel = length(voxelData.zw) * 1e9; // Convert to cm/year
-            color = mix(vec3(0.0, 0.0, 0.5), vec3(1.0, 1.0, 0.0), clamp(vel / 10.0, 0.0, 1.0));
+            float vel = length(voxelData.zw) * CM_PER_YEAR;
+            color = mix(vec3(0.0, 0.0, 0.5), vec3(1.0, 1.0, 0.0), clamp(vel / PLATE_SPEED_MAX, 0.0, 1.0));
             props.opacity = 0.1;
         } else if (renderMode == 4) { // Plates - use actual plate data
             float plateID = getPlateID(pos);
             if (plateID > 0.0 && (matType == 2 || matType == 3)) { // Only for crustal material
-                float hue = mod(plateID * 137.5, 360.0) / 360.0;
-                vec3 hsv = vec3(hue, 0.7, 0.8);
-                vec4 K = vec4(1.0, 2.0 / 3.0, 1.0 / 3.0, 3.0);
-                vec3 p = abs(fract(hsv.xxx + K.xyz) * 6.0 - K.www);
-                color = hsv.z * mix(K.xxx, clamp(p - K.xxx, 0.0, 1.0), hsv.y);
+                color = plateColor(plateID);
             }
         } else if (renderMode == 5) { // Stress visualization
-            // Show velocity magnitudes as stress indicators
-            // voxelData.z = VelNorth, voxelData.w = VelEast
-            float vel = length(voxelData.zw) * 1e9; // Convert to cm/year
-            
-            // DEBUG: Show any non-zero velocity as bright color
-            if (abs(voxelData.z) > 0.0 || abs(voxelData.w) > 0.0) {
-                // Show velocity magnitude with better scaling
-                float normalizedVel = vel / 5.0; // 0-5 cm/year range
-                color = mix(vec3(0.0, 0.0, 0.5), vec3(1.0, 0.0, 0.0), clamp(normalizedVel, 0.0, 1.0));
-                props.opacity = 0.9;
-                
-                // Add brightness for any velocity
-                if (vel > 0.1) {
-                    props.emissive = 0.3;
-                }
-            } else {
-                // Zero velocity shown as dark blue
-                color = vec3(0.05, 0.05, 0.2);
-                props.opacity = 0.5;
-            }
+            float stress = temperatureAt(vec3(u, v, shellIndex)).a;
+            color = stressColor(stress);
+            props.opacity = 0.9;
+            props.emissive = stressGlow(stress);
         } else if (renderMode == 6) { // Sub-position visualization
             // Show sub-cell positions as color gradient
-            vec4 fullVelData = texture(velocityTexture, vec3(u, v, shellIndex));
+            vec4 fullVelData = velocityAt(vec3(u, v, shellIndex));
             float subPosLat = fullVelData.z; // Sub-position latitude
             float subPosLon = fullVelData.w; // Sub-position longitude
             
@@ -445,63 +742,11 @@ vec4 rayMarchVolume(vec3 ro, vec3 rd) {
             }
         } else if (renderMode == 7) { // Elevation/altitude visualization
             // Get elevation from temperature texture's G channel
-            vec2 tempElev = texture(temperatureTexture, vec3(u, v, shellIndex)).rg;
+            vec2 tempElev = temperatureAt(vec3(u, v, shellIndex)).rg;
             float elevation = tempElev.g; // Elevation in meters
             
             if (matType == 2 || matType == 3) { // Only for crustal material
-                // Earth-like elevation colors
-                // Deep ocean = dark blue
-                // Shallow ocean = lighter blue  
-                // Beaches/lowlands = sandy tan
-                // Plains = grass green
-                // Hills = darker green with brown
-                // Mountains = grey/brown rock
-                // Snow caps = white
-                
-                if (elevation < -4000.0) {
-                    // Deep ocean - dark blue
-                    color = vec3(0.05, 0.1, 0.3);
-                } else if (elevation < -2000.0) {
-                    // Mid ocean - medium blue
-                    float t = (elevation + 4000.0) / 2000.0;
-                    color = mix(vec3(0.05, 0.1, 0.3), vec3(0.1, 0.3, 0.6), t);
-                } else if (elevation < -200.0) {
-                    // Shallow ocean - light blue
-                    float t = (elevation + 2000.0) / 1800.0;
-                    color = mix(vec3(0.1, 0.3, 0.6), vec3(0.2, 0.5, 0.8), t);
-                } else if (elevation < 0.0) {
-                    // Continental shelf - very light blue
-                    float t = (elevation + 200.0) / 200.0;
-                    color = mix(vec3(0.2, 0.5, 0.8), vec3(0.3, 0.6, 0.85), t);
-                } else if (elevation < 50.0) {
-                    // Beaches and coastal plains - sandy tan
-                    float t = elevation / 50.0;
-                    color = mix(vec3(0.76, 0.7, 0.5), vec3(0.7, 0.65, 0.45), t);
-                } else if (elevation < 500.0) {
-                    // Lowland plains - grass green
-                    float t = (elevation - 50.0) / 450.0;
-                    color = mix(vec3(0.7, 0.65, 0.45), vec3(0.3, 0.5, 0.2), t);
-                } else if (elevation < 1500.0) {
-                    // Hills - darker green with hints of brown
-                    float t = (elevation - 500.0) / 1000.0;
-                    vec3 hillGreen = vec3(0.25, 0.4, 0.15);
-                    vec3 hillBrown = vec3(0.4, 0.35, 0.25);
-                    color = mix(vec3(0.3, 0.5, 0.2), mix(hillGreen, hillBrown, t*0.3), t);
-                } else if (elevation < 3000.0) {
-                    // Mountains - grey brown rock
-                    float t = (elevation - 1500.0) / 1500.0;
-                    vec3 brownRock = vec3(0.45, 0.35, 0.25);
-                    vec3 greyRock = vec3(0.5, 0.48, 0.45);
-                    color = mix(brownRock, greyRock, t);
-                } else if (elevation < 4500.0) {
-                    // High mountains - darker grey
-                    float t = (elevation - 3000.0) / 1500.0;
-                    color = mix(vec3(0.5, 0.48, 0.45), vec3(0.4, 0.38, 0.36), t);
-                } else {
-                    // Snow caps - white with slight blue tint
-                    float t = min((elevation - 4500.0) / 1500.0, 1.0);
-                    color = mix(vec3(0.4, 0.38, 0.36), vec3(0.95, 0.96, 0.98), t);
-                }
+                color = colormap(COLORMAP_ELEVATION, elevation);
                 
                 // Make mountains more visible
                 if (elevation > 1000.0) {
@@ -519,18 +764,22 @@ vec4 rayMarchVolume(vec3 ro, vec3 rd) {
         }
         
         // Enhanced lighting with camera-relative light
-        vec3 normal = normalize(pos);
+        vec3 normal = surfaceNormal(pos);
         vec3 lightDir = normalize(cameraPos); // Light from camera direction
         float NdotL = max(dot(normal, lightDir), 0.0);
         
         // Strong ambient light to ensure visibility
-        float rimLight = 1.0 - max(dot(normal, -rd), 0.0);
+        float rimLight = 1.0 - max(dot(normal, -viewDir), 0.0);
         rimLight = pow(rimLight, 2.0) * 0.3;
         
         vec3 lighting = vec3(0.8) + vec3(0.6) * NdotL + vec3(rimLight);
         
         // Apply lighting and emissive
         color = color * lighting + color * props.emissive;
+        if (renderMode == 0) {
+            float voxelTemp = temperatureAt(vec3(u, v, shellIndex)).r;
+            color += glowColor(voxelTemp) * lavaGlow(matType, voxelTemp);
+        }
         
         // Opacity accumulation (front-to-back)
         float stepOpacity = props.opacity * baseStep / planetRadius * 20.0; // Strong opacity for visibility
@@ -564,11 +813,44 @@ void main() {
     vec4 nearPoint = invViewProj * vec4(fragCoord * 2.0 - 1.0, -1.0, 1.0);
     vec4 farPoint = invViewProj * vec4(fragCoord * 2.0 - 1.0, 1.0, 1.0);
     
-    vec3 ro = cameraPos;
+    // Rays start on the near plane: at the camera in perspective, parallel
+    // from the whole plane in orthographic projection
+    vec3 ro = nearPoint.xyz / nearPoint.w;
     vec3 rd = normalize(farPoint.xyz / farPoint.w - nearPoint.xyz / nearPoint.w);
     
+    // Maps look straight down on the point each pixel shows, so the surface
+    // is colored exactly as on the globe
+    // The pixel footprint is taken before any pixel leaves early, so the
+    // derivatives stay defined
+    pixelSpread = length(fwidth(ro));
+    pixelAngle = length(fwidth(rd));
+    if (mapProjection > 0) {
+        float lat, lon;
+        bool onMap = mapLatLon(fragCoord, lat, lon);
+        // Straight down in sphere space, where the point at geodetic
+        // latitude lat sits at the parametric latitude beta
+        float beta = atan((1.0 - flattening) * sin(lat), cos(lat));
+        vec3 up = vec3(cos(beta) * cos(lon), sin(beta), cos(beta) * sin(lon));
+        ro = toWorldSpace(up * planetRadius * 2.0);
+        rd = toWorldSpace(-up);
+        pixelSpread = 0.0;
+        pixelAngle = length(fwidth(up));
+        if (!onMap) {
+            outColor = vec4(0.05, 0.05, 0.1, 1.0);
+            outDistance = 1e12;
+            return;
+        }
+    }
+    
     // Volume ray marching
     vec4 result = rayMarchVolume(ro, rd);
+
+    // Distance to the planet's surface, or effectively infinite past it
+    float tNear, tFar;
+    outDistance = 1e12;
+    if (rayEllipsoidIntersect(ro, rd, planetRadius, tNear, tFar) && tFar > 0.0) {
+        outDistance = length(ro + rd * max(tNear, 0.0) - cameraPos);
+    }
     
     // Composite over background
     vec3 background = vec3(0.05, 0.05, 0.1);
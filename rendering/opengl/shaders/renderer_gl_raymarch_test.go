@@ -0,0 +1,31 @@
+package shaders
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// TestPlateSpeedScale checks the velocity view converts voxel velocities
+// the way the console does: a voxel moving 5 cm/year shows as 5
+func TestPlateSpeedScale(t *testing.T) {
+	match := regexp.MustCompile(`const float CM_PER_YEAR = ([0-9.e+-]+);`).FindStringSubmatch(voxelRayMarchFragmentShaderV2)
+	if match == nil {
+		t.Fatal("ray march shader has no CM_PER_YEAR")
+	}
+	scale, err := strconv.ParseFloat(match[1], 64)
+	if err != nil {
+		t.Fatalf("CM_PER_YEAR %q: %v", match[1], err)
+	}
+
+	const velocity = 0.05 // m/year
+	if got := velocity * scale; math.Abs(got-5) > 1e-9 {
+		t.Errorf("shader shows %g m/year as %g cm/year, want 5", velocity, got)
+	}
+	if got := core.CmPerYear(velocity); math.Abs(got-5) > 1e-9 {
+		t.Errorf("console prints %g m/year as %g cm/year, want 5", velocity, got)
+	}
+}
@@ -0,0 +1,43 @@
+package opengl
+
+import (
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// SharedContext is a hidden window whose OpenGL context shares buffers and
+// programs with the renderer's, so work on other goroutines (such as the
+// gpu.ComputePhysics kernels) can use them while the main thread renders
+type SharedContext struct {
+	window *glfw.Window
+}
+
+// NewSharedContext opens a SharedContext. Like every GLFW window it must be
+// created, and destroyed, on the main thread
+func (r *VoxelRenderer) NewSharedContext() (*SharedContext, error) {
+	glfw.WindowHint(glfw.Visible, glfw.False)
+	window, err := glfw.CreateWindow(1, 1, "compute", nil, r.window)
+	glfw.WindowHint(glfw.Visible, glfw.True)
+	if err != nil {
+		return nil, err
+	}
+	return &SharedContext{window: window}, nil
+}
+
+// MakeCurrent makes the context current on the calling OS thread and returns
+// a function that restores the context current before
+func (c *SharedContext) MakeCurrent() func() {
+	previous := glfw.GetCurrentContext()
+	c.window.MakeContextCurrent()
+	return func() {
+		if previous != nil {
+			previous.MakeContextCurrent()
+		} else {
+			glfw.DetachCurrentContext()
+		}
+	}
+}
+
+// Destroy closes the hidden window
+func (c *SharedContext) Destroy() {
+	c.window.Destroy()
+}
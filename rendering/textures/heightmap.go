@@ -0,0 +1,121 @@
+package textures
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+
+	"worldgenerator/core"
+)
+
+// HeightmapSeaLevel is the gray value sea level maps to in an exported
+// heightmap. The lowest surface elevation maps to 0 and the highest to 65535,
+// each linearly on its side of sea level, so coastlines always sit at the
+// same value however deep the oceans or high the mountains
+const HeightmapSeaLevel = 32768
+
+// ExportHeightmap writes the surface shell elevation as a 16-bit grayscale
+// PNG on an equirectangular grid, north up and longitude -180° at the left
+// edge. Each pixel interpolates the voxel centers around it, wrapping in
+// longitude and converging on one value at each pole
+func ExportHeightmap(planet *core.VoxelPlanet, width, height int, path string) error {
+	if width <= 0 || height <= 0 {
+		return fmt.Errorf("heightmap size must be positive, got %dx%d", width, height)
+	}
+	if len(planet.Shells) < 2 {
+		return fmt.Errorf("planet has no surface shell")
+	}
+
+	img := heightmapImage(planet, width, height)
+
+	err := core.WriteFileAtomic(path, func(w io.Writer) error {
+		return png.Encode(w, img)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write heightmap file: %v", err)
+	}
+	return nil
+}
+
+// heightmapImage samples the surface shell into a grayscale image
+func heightmapImage(planet *core.VoxelPlanet, width, height int) *image.Gray16 {
+	shell := &planet.Shells[len(planet.Shells)-2]
+	seaLevel := planet.SeaLevel
+
+	minElev, maxElev := seaLevel, seaLevel
+	for _, band := range shell.Voxels {
+		for _, voxel := range band {
+			minElev = math.Min(minElev, float64(voxel.Elevation))
+			maxElev = math.Max(maxElev, float64(voxel.Elevation))
+		}
+	}
+
+	img := image.NewGray16(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		lat := 90 - (float64(y)+0.5)/float64(height)*180
+		for x := 0; x < width; x++ {
+			lon := (float64(x)+0.5)/float64(width)*360 - 180
+			elev := surfaceElevationAt(shell, lat, lon)
+
+			// Piecewise linear so sea level lands on HeightmapSeaLevel
+			var gray float64
+			if elev < seaLevel {
+				gray = HeightmapSeaLevel * (elev - minElev) / (seaLevel - minElev)
+			} else if maxElev > seaLevel {
+				gray = HeightmapSeaLevel + (math.MaxUint16-HeightmapSeaLevel)*(elev-seaLevel)/(maxElev-seaLevel)
+			} else {
+				gray = HeightmapSeaLevel
+			}
+			gray = math.Max(0, math.Min(math.MaxUint16, math.Round(gray)))
+			img.SetGray16(x, y, color.Gray16{Y: uint16(gray)})
+		}
+	}
+	return img
+}
+
+// surfaceElevationAt interpolates voxel elevations at lat/lon in degrees,
+// between the two nearest band latitudes and the two nearest voxel centers
+// in each band's longitude. The polar bands are centered on the poles, so
+// they count as their mean and every longitude meets at the same pole value
+func surfaceElevationAt(shell *core.SphericalShell, lat, lon float64) float64 {
+	spacing := 180 / float64(shell.LatBands-1)
+	pos := math.Max(0, math.Min(float64(shell.LatBands-1), (lat+90)/spacing)) // Band index, fractional between bands
+
+	south := min(int(pos), shell.LatBands-2)
+	t := pos - float64(south)
+	return ringElevationAt(shell, south, lon)*(1-t) + ringElevationAt(shell, south+1, lon)*t
+}
+
+// ringElevationAt is a band's elevation at lon, or its mean for a polar band
+func ringElevationAt(shell *core.SphericalShell, band int, lon float64) float64 {
+	if band == 0 || band == shell.LatBands-1 {
+		return bandMeanElevation(shell, band)
+	}
+	return bandElevationAt(shell, band, lon)
+}
+
+// bandElevationAt interpolates between the two voxel centers of a band
+// around lon, wrapping from the last voxel back to the first
+func bandElevationAt(shell *core.SphericalShell, band int, lon float64) float64 {
+	voxels := shell.Voxels[band]
+	count := len(voxels)
+	pos := (lon+180)/360*float64(count) - 0.5
+	pos -= math.Floor(pos/float64(count)) * float64(count) // Wrap into [0, count)
+
+	west := int(pos) % count
+	east := (west + 1) % count
+	t := pos - math.Floor(pos)
+	return float64(voxels[west].Elevation)*(1-t) + float64(voxels[east].Elevation)*t
+}
+
+// bandMeanElevation averages a band's elevation, the value at its pole
+func bandMeanElevation(shell *core.SphericalShell, band int) float64 {
+	sum := 0.0
+	for _, voxel := range shell.Voxels[band] {
+		sum += float64(voxel.Elevation)
+	}
+	return sum / float64(len(shell.Voxels[band]))
+}
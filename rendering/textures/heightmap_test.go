@@ -0,0 +1,92 @@
+package textures
+
+import (
+	"image"
+	"image/png"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// TestExportHeightmap checks the PNG is 16-bit, spans the full range with sea
+// level at the documented midpoint, and has no seam at the date line or
+// spread at the poles
+func TestExportHeightmap(t *testing.T) {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 4, 24)
+	planet.SeaLevel = 100
+	shell := &planet.Shells[len(planet.Shells)-2]
+
+	// Smooth terrain with a ridge along the date line, from -3.9 km to +3.1 km
+	for latIdx := range shell.Voxels {
+		for lonIdx := range shell.Voxels[latIdx] {
+			lat, lon := planet.VoxelLatLon(core.VoxelCoord{Shell: len(planet.Shells) - 2, Lat: latIdx, Lon: lonIdx})
+			ridge := math.Cos(lon*math.Pi/180) * -1 // Highest at ±180°
+			shell.Voxels[latIdx][lonIdx].Elevation = float32(100 + 3500*ridge*math.Cos(lat*math.Pi/180) - 500)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "heightmap.png")
+	const width, height = 360, 180
+	if err := ExportHeightmap(planet, width, height, path); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	decoded, err := png.Decode(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, ok := decoded.(*image.Gray16)
+	if !ok {
+		t.Fatalf("decoded a %T, want 16-bit grayscale", decoded)
+	}
+	if img.Bounds().Dx() != width || img.Bounds().Dy() != height {
+		t.Fatalf("heightmap is %v, want %dx%d", img.Bounds(), width, height)
+	}
+
+	minGray, maxGray := math.MaxUint16, 0
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			gray := int(img.Gray16At(x, y).Y)
+			minGray, maxGray = min(minGray, gray), max(maxGray, gray)
+		}
+	}
+	if minGray > 1000 || maxGray < 64535 {
+		t.Errorf("heightmap spans %d-%d, want close to the full range", minGray, maxGray)
+	}
+
+	// The date line runs through the ridge, so the edge columns match
+	for y := 0; y < height; y++ {
+		west, east := int(img.Gray16At(0, y).Y), int(img.Gray16At(width-1, y).Y)
+		if diff := west - east; diff > 500 || diff < -500 {
+			t.Fatalf("row %d: seam at the date line, %d west vs %d east", y, west, east)
+		}
+	}
+
+	// The polar rows converge toward one value
+	for _, y := range []int{0, height - 1} {
+		first := int(img.Gray16At(0, y).Y)
+		for x := 1; x < width; x++ {
+			if diff := int(img.Gray16At(x, y).Y) - first; diff > 1000 || diff < -1000 {
+				t.Fatalf("row %d spreads from %d to %d across longitudes", y, first, first+diff)
+			}
+		}
+	}
+
+	// A flat planet at sea level is exactly the midpoint
+	flat := core.CreateVoxelPlanetWithResolution(6371000.0, 4, 12)
+	flatImg := heightmapImage(flat, 8, 4)
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 8; x++ {
+			if got := flatImg.Gray16At(x, y).Y; got != HeightmapSeaLevel {
+				t.Fatalf("flat planet pixel %d,%d is %d, want %d", x, y, got, HeightmapSeaLevel)
+			}
+		}
+	}
+}
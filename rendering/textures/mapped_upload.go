@@ -0,0 +1,228 @@
+package textures
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"unsafe"
+
+	"github.com/go-gl/gl/v4.3-core/gl"
+
+	"worldgenerator/core"
+)
+
+// UploadMode selects how voxel data reaches the textures each frame
+type UploadMode int
+
+const (
+	UploadAuto   UploadMode = iota // Mapped on macOS, copy elsewhere
+	UploadCopy                     // glTexSubImage3D from Go memory
+	UploadMapped                   // Write into mapped pixel buffers, upload asynchronously
+)
+
+// ParseUploadMode parses a -texture-upload flag value
+func ParseUploadMode(s string) (UploadMode, error) {
+	switch s {
+	case "auto", "":
+		return UploadAuto, nil
+	case "copy":
+		return UploadCopy, nil
+	case "mapped":
+		return UploadMapped, nil
+	}
+	return UploadAuto, fmt.Errorf("unknown texture upload mode %q (want auto, copy or mapped)", s)
+}
+
+// uploadRingSlots is how many shells can be in flight before the CPU waits on the GPU
+const uploadRingSlots = 4
+
+// fenceTimeout bounds each wait for an in-flight slot in nanoseconds
+const fenceTimeout = 100_000_000
+
+// fenceWaits is how many times a slot is waited on before it is orphaned
+const fenceWaits = 2
+
+// mappedUploader streams shell texels through a ring of pixel unpack buffers.
+//
+// macOS caps OpenGL at 4.1, so ARB_buffer_storage (4.4) and with it the
+// persistent coherent mapping used by WindowsGPUBufferManager is unavailable.
+// The closest equivalent is mapping each slot with glMapBufferRange as
+// unsynchronized, invalidated and explicitly flushed: texels are written
+// straight into driver memory with no intermediate Go arrays or extra copy,
+// and glTexSubImage3D from the buffer becomes an asynchronous DMA. A fence per
+// slot keeps the CPU from overwriting data the GPU has not consumed yet.
+type mappedUploader struct {
+	buffers   [uploadRingSlots]uint32
+	fences    [uploadRingSlots]uintptr
+	next      int
+	slotBytes int
+
+	// Byte offsets of each texture's texels within a slot
+	tempOffset int
+	velOffset  int
+}
+
+// newMappedUploader allocates the buffer ring for one shell's texels per slot
+func newMappedUploader(textureSize int32) *mappedUploader {
+	texels := int(textureSize) * int(textureSize)
+	m := &mappedUploader{
+		tempOffset: texels * 4,
+		velOffset:  texels * 4 * (1 + 4),
+		slotBytes:  texels * 4 * (1 + 4 + 4),
+	}
+
+	gl.GenBuffers(uploadRingSlots, &m.buffers[0])
+	for _, buffer := range m.buffers {
+		gl.BindBuffer(gl.PIXEL_UNPACK_BUFFER, buffer)
+		gl.BufferData(gl.PIXEL_UNPACK_BUFFER, m.slotBytes, nil, gl.STREAM_DRAW)
+	}
+	gl.BindBuffer(gl.PIXEL_UNPACK_BUFFER, 0)
+
+	return m
+}
+
+// upload fills and submits the listed shells through the ring
+func (m *mappedUploader) upload(vtd *VoxelTextureData, planet *core.VoxelPlanet, shells []int) {
+	size := int(vtd.textureSize)
+	texels := size * size
+
+	for _, shellIdx := range shells {
+		slot := m.next
+		m.next = (m.next + 1) % uploadRingSlots
+
+		// Wait until the GPU has finished reading this slot's last upload. A
+		// slot still busy after that, or whose fence can't be waited on, is
+		// orphaned: the driver hands it fresh storage and the GPU finishes
+		// reading the old one
+		gl.BindBuffer(gl.PIXEL_UNPACK_BUFFER, m.buffers[slot])
+		if m.fences[slot] != 0 {
+			if !waitFence(m.fences[slot]) {
+				gl.BufferData(gl.PIXEL_UNPACK_BUFFER, m.slotBytes, nil, gl.STREAM_DRAW)
+			}
+			gl.DeleteSync(m.fences[slot])
+			m.fences[slot] = 0
+		}
+
+		access := uint32(gl.MAP_WRITE_BIT | gl.MAP_INVALIDATE_RANGE_BIT | gl.MAP_UNSYNCHRONIZED_BIT | gl.MAP_FLUSH_EXPLICIT_BIT)
+		ptr := gl.MapBufferRange(gl.PIXEL_UNPACK_BUFFER, 0, m.slotBytes, access)
+		if ptr == nil {
+			// Mapping failed - drop back to the copy path for good
+			gl.BindBuffer(gl.PIXEL_UNPACK_BUFFER, 0)
+			fmt.Println("⚠️  Mapped texture upload unavailable, falling back to copy")
+			m.release()
+			vtd.mapped = nil
+			vtd.uploadCopy(planet, shells)
+			return
+		}
+
+		// Fill mapped memory directly
+		floats := unsafe.Slice((*float32)(ptr), m.slotBytes/4)
+		fillShellTexels(&planet.Shells[shellIdx], size,
+			floats[:texels], floats[texels:texels*5], floats[texels*5:])
+
+		gl.FlushMappedBufferRange(gl.PIXEL_UNPACK_BUFFER, 0, m.slotBytes)
+		gl.UnmapBuffer(gl.PIXEL_UNPACK_BUFFER)
+
+		// Texture uploads source from the bound buffer at these offsets
+		gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.MaterialTexture)
+		gl.TexSubImage3D(gl.TEXTURE_2D_ARRAY, 0, 0, 0, int32(shellIdx),
+			vtd.textureSize, vtd.textureSize, 1, gl.RED, gl.FLOAT, gl.PtrOffset(0))
+
+		gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.TemperatureTexture)
+		gl.TexSubImage3D(gl.TEXTURE_2D_ARRAY, 0, 0, 0, int32(shellIdx),
+			vtd.textureSize, vtd.textureSize, 1, gl.RGBA, gl.FLOAT, gl.PtrOffset(m.tempOffset))
+
+		gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.VelocityTexture)
+		gl.TexSubImage3D(gl.TEXTURE_2D_ARRAY, 0, 0, 0, int32(shellIdx),
+			vtd.textureSize, vtd.textureSize, 1, gl.RGBA, gl.FLOAT, gl.PtrOffset(m.velOffset))
+
+		m.fences[slot] = gl.FenceSync(gl.SYNC_GPU_COMMANDS_COMPLETE, 0)
+	}
+
+	gl.BindBuffer(gl.PIXEL_UNPACK_BUFFER, 0)
+}
+
+// waitFence waits for a fence to signal, up to fenceWaits timeouts, and
+// reports whether it did
+func waitFence(fence uintptr) bool {
+	flags := uint32(gl.SYNC_FLUSH_COMMANDS_BIT)
+	for i := 0; i < fenceWaits; i++ {
+		switch gl.ClientWaitSync(fence, flags, fenceTimeout) {
+		case gl.ALREADY_SIGNALED, gl.CONDITION_SATISFIED:
+			return true
+		case gl.WAIT_FAILED:
+			return false
+		}
+		// Commands were flushed by the first wait
+		flags = 0
+	}
+	return false
+}
+
+// release frees the buffer ring and any outstanding fences
+func (m *mappedUploader) release() {
+	for i, fence := range m.fences {
+		if fence != 0 {
+			gl.DeleteSync(fence)
+			m.fences[i] = 0
+		}
+	}
+	gl.DeleteBuffers(uploadRingSlots, &m.buffers[0])
+}
+
+// SetUploadMode switches between the copy and mapped upload paths
+func (vtd *VoxelTextureData) SetUploadMode(mode UploadMode) {
+	useMapped := mode == UploadMapped || (mode == UploadAuto && runtime.GOOS == "darwin")
+
+	if useMapped && vtd.mapped == nil {
+		vtd.mapped = newMappedUploader(vtd.textureSize)
+	} else if !useMapped && vtd.mapped != nil {
+		vtd.mapped.release()
+		vtd.mapped = nil
+	}
+}
+
+// UsingMappedUpload reports whether uploads go through mapped pixel buffers
+func (vtd *VoxelTextureData) UsingMappedUpload() bool {
+	return vtd.mapped != nil
+}
+
+// VerifyUpload reads the material and temperature textures back and compares
+// every shell against what the copy path would have uploaded
+func (vtd *VoxelTextureData) VerifyUpload(planet *core.VoxelPlanet) error {
+	size := int(vtd.textureSize)
+	texels := size * size
+	layers := int(vtd.maxShells)
+
+	gotMaterial := make([]float32, texels*layers)
+	gotTemp := make([]float32, texels*4*layers)
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.MaterialTexture)
+	gl.GetTexImage(gl.TEXTURE_2D_ARRAY, 0, gl.RED, gl.FLOAT, unsafe.Pointer(&gotMaterial[0]))
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.TemperatureTexture)
+	gl.GetTexImage(gl.TEXTURE_2D_ARRAY, 0, gl.RGBA, gl.FLOAT, unsafe.Pointer(&gotTemp[0]))
+
+	material := make([]float32, texels)
+	temp := make([]float32, texels*4)
+	vel := make([]float32, texels*4)
+	for shellIdx := range planet.Shells {
+		if shellIdx >= layers {
+			break
+		}
+		fillShellTexels(&planet.Shells[shellIdx], size, material, temp, vel)
+
+		for i, want := range material {
+			if got := gotMaterial[shellIdx*texels+i]; got != want {
+				return fmt.Errorf("shell %d texel %d: material %.0f, want %.0f", shellIdx, i, got, want)
+			}
+		}
+		for i, want := range temp {
+			got := gotTemp[shellIdx*texels*4+i]
+			if got != want && !(math.IsNaN(float64(got)) && math.IsNaN(float64(want))) {
+				return fmt.Errorf("shell %d texel %d: temperature channel %d is %g, want %g",
+					shellIdx, i/4, i%4, got, want)
+			}
+		}
+	}
+
+	return nil
+}
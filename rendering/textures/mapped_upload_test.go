@@ -0,0 +1,47 @@
+package textures
+
+import (
+	"testing"
+
+	"github.com/go-gl/gl/v4.3-core/gl"
+
+	"worldgenerator/core"
+)
+
+// BenchmarkUploadMode compares a full per-frame texture upload of a large
+// planet through the copy path with one through mapped pixel buffers, and
+// checks both leave the textures holding the planet
+func BenchmarkUploadMode(b *testing.B) {
+	defer openTextureContext(b)()
+
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 20, 180)
+
+	for _, bench := range []struct {
+		name string
+		mode UploadMode
+	}{
+		{"copy", UploadCopy},
+		{"mapped", UploadMapped},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			vtd := NewVoxelTextureData(len(planet.Shells))
+			defer vtd.Cleanup()
+			vtd.SetUploadMode(bench.mode)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				planet.MarkAllShellsDirty()
+				vtd.UpdateFromPlanet(planet)
+			}
+			gl.Finish()
+			b.StopTimer()
+
+			if bench.mode == UploadMapped && !vtd.UsingMappedUpload() {
+				b.Fatal("mapped upload fell back to copying")
+			}
+			if err := vtd.VerifyUpload(planet); err != nil {
+				b.Fatal(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package textures
+
+import (
+	"math"
+	"unsafe"
+
+	"github.com/go-gl/gl/v4.3-core/gl"
+
+	"worldgenerator/simulation"
+)
+
+// Plate track overlay resolution. Tracks span thousands of kilometers, so
+// they need less detail than rivers
+const (
+	plateTrackTextureWidth  = 1024
+	plateTrackTextureHeight = 512
+)
+
+// oldestTrackOpacity is how faint the oldest point of a track draws; the
+// newest draws opaque, so each path shows which way its plate went
+const oldestTrackOpacity = 0.3
+
+// UpdatePlateTracks draws each plate's track in the color color gives its
+// plate, replacing the last set
+func (vtd *VoxelTextureData) UpdatePlateTracks(tracks []simulation.PlateTrack, color func(plateID int) [3]float32) {
+	texels := make([]float32, 4*plateTrackTextureWidth*plateTrackTextureHeight)
+	fillPlateTrackTexels(tracks, color, plateTrackTextureWidth, plateTrackTextureHeight, texels)
+
+	gl.BindTexture(gl.TEXTURE_2D, vtd.PlateTrackTexture)
+	gl.TexSubImage2D(gl.TEXTURE_2D, 0, 0, 0, plateTrackTextureWidth, plateTrackTextureHeight, gl.RGBA, gl.FLOAT, unsafe.Pointer(&texels[0]))
+}
+
+// fillPlateTrackTexels rasterizes each track's polyline onto an
+// equirectangular RGBA grid laid out as fillRiverTexels's, with premultiplied
+// alpha so the texture filters cleanly. Opacity grows along each track from
+// oldestTrackOpacity to 1, and the newest point, where the plate stands now,
+// is marked with a dot. Segments across the antimeridian take the short way
+// round
+func fillPlateTrackTexels(tracks []simulation.PlateTrack, color func(plateID int) [3]float32, width, height int, texels []float32) {
+	plot := func(x, y int, rgb [3]float32, alpha float32) {
+		x = ((x % width) + width) % width
+		y = max(0, min(height-1, y))
+		idx := 4 * (y*width + x)
+		if alpha < texels[idx+3] {
+			return
+		}
+		for c := 0; c < 3; c++ {
+			texels[idx+c] = rgb[c] * alpha
+		}
+		texels[idx+3] = alpha
+	}
+	texel := func(p float64, size int, span float64) float64 {
+		return (p + span/2) / span * float64(size)
+	}
+
+	for _, track := range tracks {
+		if len(track.Points) == 0 {
+			continue
+		}
+		rgb := color(track.PlateID)
+		opacity := func(k int) float32 {
+			if len(track.Points) == 1 {
+				return 1
+			}
+			return float32(oldestTrackOpacity + (1-oldestTrackOpacity)*float64(k)/float64(len(track.Points)-1))
+		}
+
+		for k := 1; k < len(track.Points); k++ {
+			from, to := track.Points[k-1], track.Points[k]
+			x0 := texel(from.Lon, width, 360)
+			y0 := texel(from.Lat, height, 180)
+			dLon := to.Lon - from.Lon
+			dLon -= 360 * math.Round(dLon/360)
+			dx := dLon / 360 * float64(width)
+			dy := (to.Lat - from.Lat) / 180 * float64(height)
+
+			a0, a1 := opacity(k-1), opacity(k)
+			steps := int(math.Ceil(math.Max(math.Abs(dx), math.Abs(dy)) * 2))
+			for s := 0; s <= steps; s++ {
+				t := float64(s) / float64(max(steps, 1))
+				plot(int(math.Floor(x0+dx*t)), int(math.Floor(y0+dy*t)), rgb, a0+(a1-a0)*float32(t))
+			}
+		}
+
+		last := track.Points[len(track.Points)-1]
+		x := int(math.Floor(texel(last.Lon, width, 360)))
+		y := int(math.Floor(texel(last.Lat, height, 180)))
+		for oy := -1; oy <= 1; oy++ {
+			for ox := -1; ox <= 1; ox++ {
+				plot(x+ox, y+oy, rgb, 1)
+			}
+		}
+	}
+}
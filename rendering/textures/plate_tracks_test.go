@@ -0,0 +1,59 @@
+package textures
+
+import (
+	"math"
+	"testing"
+
+	"worldgenerator/core"
+	"worldgenerator/simulation"
+)
+
+// TestFillPlateTrackTexels checks a track across the antimeridian is drawn
+// the short way in its plate's color, fading toward its oldest point, with
+// a dot where the plate stands now
+func TestFillPlateTrackTexels(t *testing.T) {
+	const width, height = 360, 180
+	tracks := []simulation.PlateTrack{{
+		PlateID: 3,
+		Points: []core.PlateTrackPoint{
+			{Time: 0, Lat: 10.5, Lon: 170.5},
+			{Time: 1e6, Lat: 10.5, Lon: 178.5},
+			{Time: 2e6, Lat: 10.5, Lon: -170.5},
+		},
+	}}
+	red := [3]float32{1, 0, 0}
+	color := func(plateID int) [3]float32 {
+		if plateID != 3 {
+			t.Fatalf("colored plate %d, want 3", plateID)
+		}
+		return red
+	}
+	texels := make([]float32, 4*width*height)
+	fillPlateTrackTexels(tracks, color, width, height, texels)
+
+	at := func(x, y int) []float32 { return texels[4*(y*width+x) : 4*(y*width+x)+4] }
+	for x := 20; x < 340; x++ {
+		if at(x, 100)[3] != 0 {
+			t.Fatalf("track drawn the long way round, texel %d set", x)
+		}
+	}
+
+	// Opacity grows from the oldest point to the newest
+	oldest := at(350, 100)
+	if math.Abs(float64(oldest[3]-oldestTrackOpacity)) > 0.01 || oldest[0] != oldest[3] || oldest[1] != 0 {
+		t.Errorf("oldest point %v, want red at opacity %g", oldest, oldestTrackOpacity)
+	}
+	for x := 351; x < 368; x++ {
+		if prev, cur := at((x-1)%width, 100)[3], at(x%width, 100)[3]; cur < prev {
+			t.Fatalf("opacity falls from %.3f to %.3f at texel %d, toward the newer end", prev, cur, x%width)
+		}
+	}
+
+	// The plate's position now is marked beyond the line's width
+	if dot := at(9, 101); dot[3] != 1 {
+		t.Errorf("no marker beside the newest point: %v", dot)
+	}
+	if at(9, 102)[3] != 0 {
+		t.Error("marker larger than 3 texels")
+	}
+}
@@ -0,0 +1,58 @@
+package textures
+
+import (
+	"math"
+	"unsafe"
+
+	"github.com/go-gl/gl/v4.3-core/gl"
+
+	"worldgenerator/physics"
+)
+
+// River overlay resolution, finer than the voxel textures so rivers draw as
+// thin lines rather than voxel-wide bands
+const (
+	riverTextureWidth  = 2048
+	riverTextureHeight = 1024
+)
+
+// UpdateRivers draws rivers into the river texture, replacing the last set
+func (vtd *VoxelTextureData) UpdateRivers(rivers []physics.River) {
+	texels := make([]float32, riverTextureWidth*riverTextureHeight)
+	fillRiverTexels(rivers, riverTextureWidth, riverTextureHeight, texels)
+
+	gl.BindTexture(gl.TEXTURE_2D, vtd.RiverTexture)
+	gl.TexSubImage2D(gl.TEXTURE_2D, 0, 0, 0, riverTextureWidth, riverTextureHeight, gl.RED, gl.FLOAT, unsafe.Pointer(&texels[0]))
+}
+
+// fillRiverTexels rasterizes each river's polyline onto an equirectangular
+// grid, longitude -180° at x = 0 and latitude -90° at y = 0. A texel holds
+// the strength of the largest river crossing it, from 0.5 at the drainage
+// threshold up to 1 for rivers draining 100 times as much. Segments across
+// the antimeridian take the short way round, wrapping to the other edge
+func fillRiverTexels(rivers []physics.River, width, height int, texels []float32) {
+	for _, river := range rivers {
+		for k := 1; k < len(river.Points); k++ {
+			from, to := river.Points[k-1], river.Points[k]
+			strength := float32(math.Min(1, 0.5+0.25*math.Log10(math.Max(1, from.DrainageArea/physics.RiverMinDrainageArea))))
+
+			x0 := (from.Lon + 180) / 360 * float64(width)
+			y0 := (from.Lat + 90) / 180 * float64(height)
+			dLon := to.Lon - from.Lon
+			dLon -= 360 * math.Round(dLon/360)
+			dx := dLon / 360 * float64(width)
+			dy := (to.Lat - from.Lat) / 180 * float64(height)
+
+			steps := int(math.Ceil(math.Max(math.Abs(dx), math.Abs(dy)) * 2))
+			for s := 0; s <= steps; s++ {
+				t := float64(s) / float64(max(steps, 1))
+				x := int(math.Floor(x0 + dx*t))
+				y := int(math.Floor(y0 + dy*t))
+				x = ((x % width) + width) % width
+				y = max(0, min(height-1, y))
+				idx := y*width + x
+				texels[idx] = max(texels[idx], strength)
+			}
+		}
+	}
+}
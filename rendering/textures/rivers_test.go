@@ -0,0 +1,37 @@
+package textures
+
+import (
+	"testing"
+
+	"worldgenerator/physics"
+)
+
+// TestFillRiverTexelsWrapsAntimeridian checks a river segment crossing ±180°
+// is drawn the short way, touching both edges and nothing in between
+func TestFillRiverTexelsWrapsAntimeridian(t *testing.T) {
+	const width, height = 360, 180
+	rivers := []physics.River{{
+		Points: []physics.RiverPoint{
+			{Lat: 10.5, Lon: 178.5, DrainageArea: physics.RiverMinDrainageArea},
+			{Lat: 10.5, Lon: -178.5, DrainageArea: physics.RiverMinDrainageArea * 100},
+		},
+		Joins: -1,
+	}}
+	texels := make([]float32, width*height)
+	fillRiverTexels(rivers, width, height, texels)
+
+	row := texels[100*width : 101*width]
+	if row[0] == 0 || row[width-1] == 0 || row[358] == 0 || row[1] == 0 {
+		t.Errorf("segment across the antimeridian missing at the edges: %v %v %v %v", row[358], row[width-1], row[0], row[1])
+	}
+	for x := 2; x < 358; x++ {
+		if row[x] != 0 {
+			t.Fatalf("segment drawn the long way round, texel %d set", x)
+		}
+	}
+
+	// Strength follows the upstream end of the segment
+	if row[0] != 0.5 {
+		t.Errorf("strength %v at the drainage threshold, want 0.5", row[0])
+	}
+}
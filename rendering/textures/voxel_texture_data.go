@@ -1,305 +1,553 @@
-package textures
-
-import (
-	"fmt"
-	"math"
-	"unsafe"
-
-	"github.com/go-gl/gl/v4.3-core/gl"
-
-	"worldgenerator/core"
-)
-
-// VoxelTextureData manages voxel data as 3D textures for GPU access
-type VoxelTextureData struct {
-	MaterialTexture    uint32
-	TemperatureTexture uint32
-	VelocityTexture    uint32
-	ShellInfoTexture   uint32
-
-	textureSize     int32
-	maxShells       int32
-	lastDebugOutput int
-}
-
-// NewVoxelTextureData creates texture storage for voxel data
-func NewVoxelTextureData(maxShells int) *VoxelTextureData {
-	vtd := &VoxelTextureData{
-		maxShells:   int32(maxShells),
-		textureSize: 360, // Match voxel grid resolution to avoid aliasing
-	}
-
-	// Create textures
-	gl.GenTextures(1, &vtd.MaterialTexture)
-	gl.GenTextures(1, &vtd.TemperatureTexture)
-	gl.GenTextures(1, &vtd.VelocityTexture)
-	gl.GenTextures(1, &vtd.ShellInfoTexture)
-
-	// Initialize material texture (2D texture array for shells)
-	gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.MaterialTexture)
-	gl.TexImage3D(gl.TEXTURE_2D_ARRAY, 0, gl.R32F, vtd.textureSize, vtd.textureSize, vtd.maxShells,
-		0, gl.RED, gl.FLOAT, nil)
-	// Use nearest filtering for material texture to avoid interpolation between different materials
-	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
-	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
-	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_WRAP_S, gl.REPEAT)
-	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
-
-	// Initialize temperature texture (RGB: temperature, elevation, plateID)
-	gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.TemperatureTexture)
-	gl.TexImage3D(gl.TEXTURE_2D_ARRAY, 0, gl.RGB32F, vtd.textureSize, vtd.textureSize, vtd.maxShells,
-		0, gl.RGB, gl.FLOAT, nil)
-	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_MIN_FILTER, gl.LINEAR_MIPMAP_LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_WRAP_S, gl.REPEAT)
-	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
-
-	// Initialize velocity texture (RGBA: theta/phi velocity, lat/lon sub-position)
-	gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.VelocityTexture)
-	gl.TexImage3D(gl.TEXTURE_2D_ARRAY, 0, gl.RGBA32F, vtd.textureSize, vtd.textureSize, vtd.maxShells,
-		0, gl.RGBA, gl.FLOAT, nil)
-	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_MIN_FILTER, gl.LINEAR_MIPMAP_LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
-	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_WRAP_S, gl.REPEAT)
-	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
-
-	// Shell info texture (1D texture with shell metadata)
-	gl.BindTexture(gl.TEXTURE_1D, vtd.ShellInfoTexture)
-	gl.TexImage1D(gl.TEXTURE_1D, 0, gl.RGBA32F, vtd.maxShells, 0, gl.RGBA, gl.FLOAT, nil)
-	gl.TexParameteri(gl.TEXTURE_1D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
-	gl.TexParameteri(gl.TEXTURE_1D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
-
-	return vtd
-}
-
-var debugOnce = true
-var updateCount = 0
-
-// sampleVoxelAtLocation samples voxel data at a specific lat/lon position
-// It handles the non-uniform longitude distribution by finding the correct voxel
-func sampleVoxelAtLocation(shell *core.SphericalShell, lat, lon float64) core.VoxelMaterial {
-	// Find the latitude band
-	latBandF := (lat + 90.0) / 180.0 * float64(shell.LatBands)
-	latBand := int(math.Floor(latBandF))
-
-	// Clamp latitude band
-	if latBand >= shell.LatBands {
-		latBand = shell.LatBands - 1
-	}
-	if latBand < 0 {
-		latBand = 0
-	}
-
-	// For the exact latitude band, find the appropriate longitude voxel
-	// The key is to use the actual voxel count for this specific latitude band
-	voxelCount := len(shell.Voxels[latBand])
-	if voxelCount == 0 {
-		return core.VoxelMaterial{Type: core.MatAir}
-	}
-
-	// Convert longitude to voxel index for this latitude band
-	lonNorm := (lon + 180.0) / 360.0 // 0 to 1
-	lonIndexF := lonNorm * float64(voxelCount)
-	lonIndex := int(math.Floor(lonIndexF))
-
-	// Wrap around
-	lonIndex = lonIndex % voxelCount
-	if lonIndex < 0 {
-		lonIndex += voxelCount
-	}
-
-	// Return the voxel data
-	return shell.Voxels[latBand][lonIndex]
-}
-
-// UpdateFromPlanet updates textures with planet voxel data
-
-func (vtd *VoxelTextureData) UpdateFromPlanet(planet *core.VoxelPlanet) {
-	updateCount++
-
-	// Track update timing
-	if int(planet.Time/1e8)%10 == 0 && int(planet.Time/1e8) != vtd.lastDebugOutput {
-		vtd.lastDebugOutput = int(planet.Time / 1e8)
-	}
-
-	// Prepare data arrays
-	materialData := make([]float32, vtd.textureSize*vtd.textureSize)
-	tempData := make([]float32, vtd.textureSize*vtd.textureSize*3) // 3 components (temp + elevation + plateID)
-	velData := make([]float32, vtd.textureSize*vtd.textureSize*4)  // 4 components (vel + sub-pos)
-
-	// Update each shell
-	for shellIdx, shell := range planet.Shells {
-		if shellIdx >= int(vtd.maxShells) {
-			break
-		}
-
-		// Clear arrays
-		for i := range materialData {
-			materialData[i] = 0
-		}
-		for i := 0; i < len(tempData); i++ {
-			tempData[i] = 0
-		}
-		for i := range velData {
-			velData[i] = 0
-		}
-
-		// Debug: count non-air voxels
-		nonAirCount := 0
-
-		// Fill texture by recreating the continent data at texture resolution
-		// This avoids the mismatch between voxel grid and texture grid
-		for texY := 0; texY < int(vtd.textureSize); texY++ {
-			for texX := 0; texX < int(vtd.textureSize); texX++ {
-				// Convert texture coordinates to spherical coordinates
-				u := float64(texX) / float64(vtd.textureSize) // 0 to 1
-				v := float64(texY) / float64(vtd.textureSize) // 0 to 1
-
-				lon := u*360.0 - 180.0 // -180 to 180
-				lat := v*180.0 - 90.0  // -90 to 90
-
-				idx := texY*int(vtd.textureSize) + texX
-
-				// Always sample from voxel data for consistency
-				voxel := sampleVoxelAtLocation(&shell, lat, lon)
-				materialData[idx] = float32(voxel.Type)
-				if voxel.Type != core.MatAir {
-					nonAirCount++
-				}
-				tempData[idx*3] = voxel.Temperature
-				tempData[idx*3+1] = voxel.Elevation
-				tempData[idx*3+2] = float32(voxel.PlateID)
-				velData[idx*4] = voxel.VelNorth
-				velData[idx*4+1] = voxel.VelEast
-				velData[idx*4+2] = voxel.SubPosLat
-				velData[idx*4+3] = voxel.SubPosLon
-			}
-		}
-
-		// Debug output for surface shell
-		if shellIdx == len(planet.Shells)-2 {
-			// Count unique plate IDs
-			plateIDs := make(map[int32]bool)
-			maxVel := float32(0.0)
-			velCount := 0
-			for _, row := range shell.Voxels {
-				for _, voxel := range row {
-					if voxel.PlateID > 0 && (voxel.Type == core.MatGranite || voxel.Type == core.MatBasalt) {
-						plateIDs[voxel.PlateID] = true
-					}
-					// Check velocities
-					vel := float32(math.Sqrt(float64(voxel.VelNorth*voxel.VelNorth + voxel.VelEast*voxel.VelEast)))
-					if vel > 0 {
-						velCount++
-						if vel > maxVel {
-							maxVel = vel
-						}
-					}
-				}
-			}
-
-			// Always print first few updates and then periodically
-			if updateCount <= 5 || updateCount%100 == 0 {
-				fmt.Printf("Surface shell has %d unique plate IDs\n", len(plateIDs))
-				fmt.Printf("[Update %d] Surface shell %d (r=%.0f-%.0f km): %d non-air voxels out of %d texture pixels\n",
-					updateCount, shellIdx, shell.InnerRadius/1000, shell.OuterRadius/1000, nonAirCount, vtd.textureSize*vtd.textureSize)
-				fmt.Printf("  Velocities: %d voxels with velocity, max=%.2e m/s (%.1f cm/yr)\n",
-					velCount, maxVel, maxVel*1e9*365.25*24*3600/1e7)
-			}
-
-			// Check material distribution
-			matCounts := make(map[core.MaterialType]int)
-			for i := 0; i < len(materialData); i++ {
-				mat := core.MaterialType(materialData[i])
-				matCounts[mat]++
-			}
-			if updateCount <= 5 || updateCount%100 == 0 {
-				fmt.Printf("  Material distribution in texture: Water=%d, Land=%d, Other=%d\n",
-					matCounts[core.MatWater], matCounts[core.MatGranite],
-					len(materialData)-matCounts[core.MatWater]-matCounts[core.MatGranite])
-			}
-
-			// Check velocity data in texture
-			maxTexVel := float32(0.0)
-			texVelCount := 0
-			for i := 0; i < len(velData)/4; i++ {
-				velN := velData[i*4]
-				velE := velData[i*4+1]
-				vel := float32(math.Sqrt(float64(velN*velN + velE*velE)))
-				if vel > 0 {
-					texVelCount++
-					if vel > maxTexVel {
-						maxTexVel = vel
-					}
-				}
-			}
-			if updateCount <= 5 || updateCount%100 == 0 {
-				fmt.Printf("  Texture velocities: %d pixels with velocity, max=%.2e m/s\n", texVelCount, maxTexVel)
-			}
-
-			debugOnce = false
-		}
-
-		// Upload to GPU
-		gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.MaterialTexture)
-		gl.TexSubImage3D(gl.TEXTURE_2D_ARRAY, 0, 0, 0, int32(shellIdx),
-			vtd.textureSize, vtd.textureSize, 1,
-			gl.RED, gl.FLOAT, unsafe.Pointer(&materialData[0]))
-
-		gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.TemperatureTexture)
-		gl.TexSubImage3D(gl.TEXTURE_2D_ARRAY, 0, 0, 0, int32(shellIdx),
-			vtd.textureSize, vtd.textureSize, 1,
-			gl.RGB, gl.FLOAT, unsafe.Pointer(&tempData[0]))
-
-		gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.VelocityTexture)
-		gl.TexSubImage3D(gl.TEXTURE_2D_ARRAY, 0, 0, 0, int32(shellIdx),
-			vtd.textureSize, vtd.textureSize, 1,
-			gl.RGBA, gl.FLOAT, unsafe.Pointer(&velData[0]))
-	}
-
-	// Update shell info
-	shellInfo := make([]float32, vtd.maxShells*4) // RGBA = inner radius, outer radius, lat bands, reserved
-	for i, shell := range planet.Shells {
-		if i >= int(vtd.maxShells) {
-			break
-		}
-		shellInfo[i*4] = float32(shell.InnerRadius)
-		shellInfo[i*4+1] = float32(shell.OuterRadius)
-		shellInfo[i*4+2] = float32(shell.LatBands)
-		shellInfo[i*4+3] = 0 // Reserved
-	}
-
-	gl.BindTexture(gl.TEXTURE_1D, vtd.ShellInfoTexture)
-	gl.TexSubImage1D(gl.TEXTURE_1D, 0, 0, vtd.maxShells, gl.RGBA, gl.FLOAT, unsafe.Pointer(&shellInfo[0]))
-
-	// Generate mipmaps for temperature and velocity textures only
-	// Material texture uses nearest filtering so no mipmaps needed
-	gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.TemperatureTexture)
-	gl.GenerateMipmap(gl.TEXTURE_2D_ARRAY)
-
-	gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.VelocityTexture)
-	gl.GenerateMipmap(gl.TEXTURE_2D_ARRAY)
-}
-
-// Bind binds all textures to their texture units
-func (vtd *VoxelTextureData) Bind() {
-	gl.ActiveTexture(gl.TEXTURE0)
-	gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.MaterialTexture)
-
-	gl.ActiveTexture(gl.TEXTURE1)
-	gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.TemperatureTexture)
-
-	gl.ActiveTexture(gl.TEXTURE2)
-	gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.VelocityTexture)
-
-	gl.ActiveTexture(gl.TEXTURE3)
-	gl.BindTexture(gl.TEXTURE_1D, vtd.ShellInfoTexture)
-}
-
-// Cleanup releases texture resources
-func (vtd *VoxelTextureData) Cleanup() {
-	gl.DeleteTextures(1, &vtd.MaterialTexture)
-	gl.DeleteTextures(1, &vtd.TemperatureTexture)
-	gl.DeleteTextures(1, &vtd.VelocityTexture)
-	gl.DeleteTextures(1, &vtd.ShellInfoTexture)
-}
+package textures
+
+import (
+	"fmt"
+	"math"
+	"time"
+	"unsafe"
+
+	"github.com/go-gl/gl/v4.3-core/gl"
+
+	"worldgenerator/core"
+)
+
+// VoxelTextureData manages voxel data as 3D textures for GPU access
+type VoxelTextureData struct {
+	MaterialTexture    uint32
+	TemperatureTexture uint32
+	VelocityTexture    uint32
+	ShellInfoTexture   uint32
+	HeatFluxTexture    uint32 // Surface heat flux in W/m², 2D
+	LonCountTexture    uint32 // Longitude cells of each band (x) of each shell (y), 2D
+	RiverTexture       uint32 // River strength on the surface, 2D (see UpdateRivers)
+	PlateTrackTexture  uint32 // Plate drift paths on the surface, 2D (see UpdatePlateTracks)
+	BiomeTexture       uint32 // Biome of each surface voxel, 2D
+
+	// Temperature and velocity as of the upload before last, for blending
+	// between physics updates
+	PrevTemperatureTexture uint32
+	PrevVelocityTexture    uint32
+	uploads                int
+
+	// Range of the last heat flux upload, for scaling the heat flux view
+	HeatFluxMin, HeatFluxMax float32
+
+	textureSize     int32
+	maxShells       int32
+	lonCountWidth   int32 // Lat bands the lon count texture was allocated for
+	lastDebugOutput int
+
+	// Streaming upload through mapped pixel buffers (nil = copy path)
+	mapped *mappedUploader
+
+	// Shell version each texture layer holds, so unchanged shells are skipped
+	// (see core.VoxelPlanet.MarkShellDirty). The field versions swap along
+	// with the temperature and velocity textures
+	materialVersions  []uint64
+	fieldVersions     []uint64
+	prevFieldVersions []uint64
+
+	// LastUploadTime is how long the most recent UpdateFromPlanet took
+	LastUploadTime time.Duration
+
+	// LastUploadShells is how many shells the most recent UpdateFromPlanet rewrote
+	LastUploadShells int
+}
+
+// NewVoxelTextureData creates texture storage for voxel data
+func NewVoxelTextureData(maxShells int) *VoxelTextureData {
+	vtd := &VoxelTextureData{
+		maxShells:         int32(maxShells),
+		textureSize:       360, // Match voxel grid resolution to avoid aliasing
+		materialVersions:  make([]uint64, maxShells),
+		fieldVersions:     make([]uint64, maxShells),
+		prevFieldVersions: make([]uint64, maxShells),
+	}
+
+	// Create textures
+	gl.GenTextures(1, &vtd.MaterialTexture)
+	gl.GenTextures(1, &vtd.TemperatureTexture)
+	gl.GenTextures(1, &vtd.VelocityTexture)
+	gl.GenTextures(1, &vtd.ShellInfoTexture)
+	gl.GenTextures(1, &vtd.HeatFluxTexture)
+	gl.GenTextures(1, &vtd.LonCountTexture)
+	gl.GenTextures(1, &vtd.RiverTexture)
+	gl.GenTextures(1, &vtd.PlateTrackTexture)
+	gl.GenTextures(1, &vtd.BiomeTexture)
+	gl.GenTextures(1, &vtd.PrevTemperatureTexture)
+	gl.GenTextures(1, &vtd.PrevVelocityTexture)
+
+	// Initialize material texture (2D texture array for shells)
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.MaterialTexture)
+	gl.TexImage3D(gl.TEXTURE_2D_ARRAY, 0, gl.R32F, vtd.textureSize, vtd.textureSize, vtd.maxShells,
+		0, gl.RED, gl.FLOAT, nil)
+	// Use nearest filtering for material texture to avoid interpolation between different materials
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_WRAP_S, gl.REPEAT)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+
+	// Initialize temperature textures (RGBA: temperature, elevation, plateID, stress)
+	vtd.allocateFieldTexture(vtd.TemperatureTexture)
+	vtd.allocateFieldTexture(vtd.PrevTemperatureTexture)
+
+	// Initialize velocity textures (RGBA: theta/phi velocity, lat/lon sub-position)
+	vtd.allocateFieldTexture(vtd.VelocityTexture)
+	vtd.allocateFieldTexture(vtd.PrevVelocityTexture)
+
+	// Shell info texture (1D texture with shell metadata)
+	gl.BindTexture(gl.TEXTURE_1D, vtd.ShellInfoTexture)
+	gl.TexImage1D(gl.TEXTURE_1D, 0, gl.RGBA32F, vtd.maxShells, 0, gl.RGBA, gl.FLOAT, nil)
+	gl.TexParameteri(gl.TEXTURE_1D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_1D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+
+	// Heat flux texture (surface shell only, computed on the CPU because it
+	// needs the temperature of the shell below)
+	gl.BindTexture(gl.TEXTURE_2D, vtd.HeatFluxTexture)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.R32F, vtd.textureSize, vtd.textureSize, 0, gl.RED, gl.FLOAT, nil)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.REPEAT)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+
+	// River texture (surface only, drawn from the river network on the CPU)
+	gl.BindTexture(gl.TEXTURE_2D, vtd.RiverTexture)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.R32F, riverTextureWidth, riverTextureHeight, 0, gl.RED, gl.FLOAT, nil)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.REPEAT)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+
+	// Plate track texture (surface only, drawn from the plate tracks on the CPU)
+	gl.BindTexture(gl.TEXTURE_2D, vtd.PlateTrackTexture)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.RGBA32F, plateTrackTextureWidth, plateTrackTextureHeight, 0, gl.RGBA, gl.FLOAT, nil)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.REPEAT)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+
+	// Biome texture (surface shell only); biomes are categories, so nearest
+	// filtering like the material texture
+	gl.BindTexture(gl.TEXTURE_2D, vtd.BiomeTexture)
+	gl.TexImage2D(gl.TEXTURE_2D, 0, gl.R32F, vtd.textureSize, vtd.textureSize, 0, gl.RED, gl.FLOAT, nil)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_S, gl.REPEAT)
+	gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+
+	return vtd
+}
+
+// allocateFieldTexture sets up a filtered, mipmapped RGBA layer per shell for
+// continuous fields
+func (vtd *VoxelTextureData) allocateFieldTexture(texture uint32) {
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, texture)
+	gl.TexImage3D(gl.TEXTURE_2D_ARRAY, 0, gl.RGBA32F, vtd.textureSize, vtd.textureSize, vtd.maxShells,
+		0, gl.RGBA, gl.FLOAT, nil)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_MIN_FILTER, gl.LINEAR_MIPMAP_LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_MAG_FILTER, gl.LINEAR)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_WRAP_S, gl.REPEAT)
+	gl.TexParameteri(gl.TEXTURE_2D_ARRAY, gl.TEXTURE_WRAP_T, gl.CLAMP_TO_EDGE)
+}
+
+var debugOnce = true
+var updateCount = 0
+
+// sampleVoxelAtLocation samples voxel data at a specific lat/lon position
+// It handles the non-uniform longitude distribution by finding the correct voxel
+func sampleVoxelAtLocation(shell *core.SphericalShell, lat, lon float64) core.VoxelMaterial {
+	// Find the latitude band
+	latBand := core.GetBandForLatitude(lat, shell.LatBands)
+
+	// For the exact latitude band, find the appropriate longitude voxel
+	// The key is to use the actual voxel count for this specific latitude band
+	voxelCount := len(shell.Voxels[latBand])
+	if voxelCount == 0 {
+		return core.VoxelMaterial{Type: core.MatAir}
+	}
+
+	// Convert longitude to voxel index for this latitude band
+	lonNorm := (lon + 180.0) / 360.0 // 0 to 1
+	lonIndexF := lonNorm * float64(voxelCount)
+	lonIndex := int(math.Floor(lonIndexF))
+
+	// Wrap around
+	lonIndex = lonIndex % voxelCount
+	if lonIndex < 0 {
+		lonIndex += voxelCount
+	}
+
+	// Return the voxel data
+	return shell.Voxels[latBand][lonIndex]
+}
+
+// UpdateFromPlanet updates textures with planet voxel data, rewriting only
+// the shells that changed since their layers were last written
+// The temperature and velocity textures it replaces become the previous ones
+func (vtd *VoxelTextureData) UpdateFromPlanet(planet *core.VoxelPlanet) {
+	start := time.Now()
+	updateCount++
+
+	// Every shell the older pair doesn't hold is rewritten, so it can take
+	// the new data without being cleared
+	vtd.swapFieldTextures()
+	stale := vtd.staleShells(planet)
+	vtd.LastUploadShells = len(stale)
+
+	// Track update timing
+	if int(planet.Time/1e8)%10 == 0 && int(planet.Time/1e8) != vtd.lastDebugOutput {
+		vtd.lastDebugOutput = int(planet.Time / 1e8)
+	}
+
+	if vtd.mapped != nil {
+		vtd.mapped.upload(vtd, planet, stale)
+	} else {
+		vtd.uploadCopy(planet, stale)
+	}
+	vtd.recordUpload(planet, stale)
+
+	// Update shell info
+	shellInfo := make([]float32, vtd.maxShells*4) // RGBA = inner radius, outer radius, lat bands, reserved
+	for i, shell := range planet.Shells {
+		if i >= int(vtd.maxShells) {
+			break
+		}
+		shellInfo[i*4] = float32(shell.InnerRadius)
+		shellInfo[i*4+1] = float32(shell.OuterRadius)
+		shellInfo[i*4+2] = float32(shell.LatBands)
+		shellInfo[i*4+3] = 0 // Reserved
+	}
+
+	gl.BindTexture(gl.TEXTURE_1D, vtd.ShellInfoTexture)
+	gl.TexSubImage1D(gl.TEXTURE_1D, 0, 0, vtd.maxShells, gl.RGBA, gl.FLOAT, unsafe.Pointer(&shellInfo[0]))
+
+	// Update longitude counts, reallocating when the finest shell changes
+	width, lonCounts := lonCountTexels(planet, int(vtd.maxShells))
+	gl.BindTexture(gl.TEXTURE_2D, vtd.LonCountTexture)
+	if int32(width) != vtd.lonCountWidth {
+		vtd.lonCountWidth = int32(width)
+		gl.TexImage2D(gl.TEXTURE_2D, 0, gl.R32F, vtd.lonCountWidth, vtd.maxShells, 0, gl.RED, gl.FLOAT, nil)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MIN_FILTER, gl.NEAREST)
+		gl.TexParameteri(gl.TEXTURE_2D, gl.TEXTURE_MAG_FILTER, gl.NEAREST)
+	}
+	gl.TexSubImage2D(gl.TEXTURE_2D, 0, 0, 0, vtd.lonCountWidth, vtd.maxShells, gl.RED, gl.FLOAT, unsafe.Pointer(&lonCounts[0]))
+
+	// Nothing below changes unless a shell did
+	if len(stale) == 0 {
+		vtd.LastUploadTime = time.Since(start)
+		return
+	}
+
+	// Update surface heat flux
+	fluxData := make([]float32, vtd.textureSize*vtd.textureSize)
+	vtd.HeatFluxMin, vtd.HeatFluxMax = fillHeatFluxTexels(planet, int(vtd.textureSize), fluxData)
+	gl.BindTexture(gl.TEXTURE_2D, vtd.HeatFluxTexture)
+	gl.TexSubImage2D(gl.TEXTURE_2D, 0, 0, 0, vtd.textureSize, vtd.textureSize, gl.RED, gl.FLOAT, unsafe.Pointer(&fluxData[0]))
+
+	// Update surface biomes
+	biomeData := make([]float32, vtd.textureSize*vtd.textureSize)
+	fillBiomeTexels(planet, int(vtd.textureSize), biomeData)
+	gl.BindTexture(gl.TEXTURE_2D, vtd.BiomeTexture)
+	gl.TexSubImage2D(gl.TEXTURE_2D, 0, 0, 0, vtd.textureSize, vtd.textureSize, gl.RED, gl.FLOAT, unsafe.Pointer(&biomeData[0]))
+
+	// Generate mipmaps for temperature and velocity textures only
+	// Material texture uses nearest filtering so no mipmaps needed
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.TemperatureTexture)
+	gl.GenerateMipmap(gl.TEXTURE_2D_ARRAY)
+
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.VelocityTexture)
+	gl.GenerateMipmap(gl.TEXTURE_2D_ARRAY)
+
+	vtd.LastUploadTime = time.Since(start)
+}
+
+// fillShellTexels resamples one shell onto the texture grid
+// material, temp and vel hold 1, 4 and 4 floats per texel; returns the non-air texel count
+func fillShellTexels(shell *core.SphericalShell, size int, material, temp, vel []float32) int {
+	nonAirCount := 0
+
+	// Fill texture by recreating the continent data at texture resolution
+	// This avoids the mismatch between voxel grid and texture grid
+	for texY := 0; texY < size; texY++ {
+		for texX := 0; texX < size; texX++ {
+			// Convert texture coordinates to spherical coordinates
+			u := float64(texX) / float64(size) // 0 to 1
+			v := float64(texY) / float64(size) // 0 to 1
+
+			lon := u*360.0 - 180.0 // -180 to 180
+			lat := v*180.0 - 90.0  // -90 to 90
+
+			idx := texY*size + texX
+
+			// Always sample from voxel data for consistency
+			voxel := sampleVoxelAtLocation(shell, lat, lon)
+			material[idx] = float32(voxel.SurfaceType())
+			if voxel.Type != core.MatAir {
+				nonAirCount++
+			}
+			temp[idx*4] = voxel.Temperature
+			temp[idx*4+1] = voxel.Elevation
+			temp[idx*4+2] = float32(voxel.PlateID)
+			temp[idx*4+3] = voxel.Stress
+			vel[idx*4] = voxel.VelNorth
+			vel[idx*4+1] = voxel.VelEast
+			vel[idx*4+2] = voxel.SubPosLat
+			vel[idx*4+3] = voxel.SubPosLon
+		}
+	}
+
+	return nonAirCount
+}
+
+// swapFieldTextures makes the previous temperature and velocity textures the
+// current ones, to be overwritten by the next upload
+func (vtd *VoxelTextureData) swapFieldTextures() {
+	vtd.TemperatureTexture, vtd.PrevTemperatureTexture = vtd.PrevTemperatureTexture, vtd.TemperatureTexture
+	vtd.VelocityTexture, vtd.PrevVelocityTexture = vtd.PrevVelocityTexture, vtd.VelocityTexture
+	vtd.fieldVersions, vtd.prevFieldVersions = vtd.prevFieldVersions, vtd.fieldVersions
+	vtd.uploads++
+}
+
+// staleShells lists the shells whose layers in the current textures don't
+// hold their voxels. Shells never marked dirty are always stale
+func (vtd *VoxelTextureData) staleShells(planet *core.VoxelPlanet) []int {
+	var stale []int
+	for shellIdx, shell := range planet.Shells {
+		if shellIdx >= int(vtd.maxShells) {
+			break
+		}
+		if shell.Version == 0 || vtd.materialVersions[shellIdx] != shell.Version || vtd.fieldVersions[shellIdx] != shell.Version {
+			stale = append(stale, shellIdx)
+		}
+	}
+	return stale
+}
+
+// recordUpload notes the listed shells' layers now hold their voxels
+func (vtd *VoxelTextureData) recordUpload(planet *core.VoxelPlanet, shells []int) {
+	for _, shellIdx := range shells {
+		version := planet.Shells[shellIdx].Version
+		vtd.materialVersions[shellIdx] = version
+		vtd.fieldVersions[shellIdx] = version
+	}
+}
+
+// lonCountTexels lays out each shell's LonCounts as a row, padded to the
+// shell with the most latitude bands; returns the row width and the texels
+func lonCountTexels(planet *core.VoxelPlanet, maxShells int) (int, []float32) {
+	width := 1
+	for _, shell := range planet.Shells {
+		width = max(width, len(shell.LonCounts))
+	}
+
+	texels := make([]float32, width*maxShells)
+	for shellIdx, shell := range planet.Shells {
+		if shellIdx >= maxShells {
+			break
+		}
+		for lat, count := range shell.LonCounts {
+			texels[shellIdx*width+lat] = float32(count)
+		}
+	}
+	return width, texels
+}
+
+// fillHeatFluxTexels resamples the surface heat flux onto the texture grid
+// and returns its minimum and maximum
+func fillHeatFluxTexels(planet *core.VoxelPlanet, size int, flux []float32) (minFlux, maxFlux float32) {
+	minFlux = float32(math.Inf(1))
+	maxFlux = float32(math.Inf(-1))
+
+	for texY := 0; texY < size; texY++ {
+		lat := (float64(texY)+0.5)/float64(size)*180.0 - 90.0
+		for texX := 0; texX < size; texX++ {
+			lon := (float64(texX)+0.5)/float64(size)*360.0 - 180.0
+
+			q := float32(planet.SurfaceHeatFlux(lat, lon))
+			flux[texY*size+texX] = q
+			minFlux = float32(math.Min(float64(minFlux), float64(q)))
+			maxFlux = float32(math.Max(float64(maxFlux), float64(q)))
+		}
+	}
+
+	return minFlux, maxFlux
+}
+
+// fillBiomeTexels resamples the surface shell's biomes onto the texture grid
+func fillBiomeTexels(planet *core.VoxelPlanet, size int, biomes []float32) {
+	if len(planet.Shells) < 2 {
+		return
+	}
+	shell := &planet.Shells[len(planet.Shells)-2]
+	for texY := 0; texY < size; texY++ {
+		lat := (float64(texY)+0.5)/float64(size)*180.0 - 90.0
+		for texX := 0; texX < size; texX++ {
+			lon := (float64(texX)+0.5)/float64(size)*360.0 - 180.0
+			biomes[texY*size+texX] = float32(sampleVoxelAtLocation(shell, lat, lon).Biome)
+		}
+	}
+}
+
+// uploadCopy fills Go-side arrays for the listed shells and lets the driver
+// copy them into the textures
+func (vtd *VoxelTextureData) uploadCopy(planet *core.VoxelPlanet, shells []int) {
+	if len(shells) == 0 {
+		return
+	}
+
+	// Prepare data arrays
+	materialData := make([]float32, vtd.textureSize*vtd.textureSize)
+	tempData := make([]float32, vtd.textureSize*vtd.textureSize*4) // 4 components (temp + elevation + plateID + stress)
+	velData := make([]float32, vtd.textureSize*vtd.textureSize*4)  // 4 components (vel + sub-pos)
+
+	// Update each shell
+	for _, shellIdx := range shells {
+		shell := planet.Shells[shellIdx]
+
+		// Every texel is overwritten, so the arrays are reused without clearing
+		nonAirCount := fillShellTexels(&shell, int(vtd.textureSize), materialData, tempData, velData)
+
+		// Debug output for surface shell
+		if shellIdx == len(planet.Shells)-2 {
+			// Count unique plate IDs
+			plateIDs := make(map[int32]bool)
+			maxVel := float32(0.0)
+			velCount := 0
+			for _, row := range shell.Voxels {
+				for _, voxel := range row {
+					if voxel.PlateID > 0 && (voxel.Type == core.MatGranite || voxel.Type == core.MatBasalt) {
+						plateIDs[voxel.PlateID] = true
+					}
+					// Check velocities
+					vel := float32(math.Sqrt(float64(voxel.VelNorth*voxel.VelNorth + voxel.VelEast*voxel.VelEast)))
+					if vel > 0 {
+						velCount++
+						if vel > maxVel {
+							maxVel = vel
+						}
+					}
+				}
+			}
+
+			// Always print first few updates and then periodically
+			if updateCount <= 5 || updateCount%100 == 0 {
+				fmt.Printf("Surface shell has %d unique plate IDs\n", len(plateIDs))
+				fmt.Printf("[Update %d] Surface shell %d (r=%.0f-%.0f km): %d non-air voxels out of %d texture pixels\n",
+					updateCount, shellIdx, shell.InnerRadius/1000, shell.OuterRadius/1000, nonAirCount, vtd.textureSize*vtd.textureSize)
+				fmt.Printf("  Velocities: %d voxels with velocity, max=%.2e m/yr (%.1f cm/yr)\n",
+					velCount, maxVel, core.CmPerYear(float64(maxVel)))
+			}
+
+			// Check material distribution
+			matCounts := make(map[core.MaterialType]int)
+			for i := 0; i < len(materialData); i++ {
+				mat := core.MaterialType(materialData[i])
+				matCounts[mat]++
+			}
+			if updateCount <= 5 || updateCount%100 == 0 {
+				fmt.Printf("  Material distribution in texture: Water=%d, Land=%d, Other=%d\n",
+					matCounts[core.MatWater], matCounts[core.MatGranite],
+					len(materialData)-matCounts[core.MatWater]-matCounts[core.MatGranite])
+			}
+
+			// Check velocity data in texture
+			maxTexVel := float32(0.0)
+			texVelCount := 0
+			for i := 0; i < len(velData)/4; i++ {
+				velN := velData[i*4]
+				velE := velData[i*4+1]
+				vel := float32(math.Sqrt(float64(velN*velN + velE*velE)))
+				if vel > 0 {
+					texVelCount++
+					if vel > maxTexVel {
+						maxTexVel = vel
+					}
+				}
+			}
+			if updateCount <= 5 || updateCount%100 == 0 {
+				fmt.Printf("  Texture velocities: %d pixels with velocity, max=%.2e m/s\n", texVelCount, maxTexVel)
+			}
+
+			debugOnce = false
+		}
+
+		// Upload to GPU
+		gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.MaterialTexture)
+		gl.TexSubImage3D(gl.TEXTURE_2D_ARRAY, 0, 0, 0, int32(shellIdx),
+			vtd.textureSize, vtd.textureSize, 1,
+			gl.RED, gl.FLOAT, unsafe.Pointer(&materialData[0]))
+
+		gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.TemperatureTexture)
+		gl.TexSubImage3D(gl.TEXTURE_2D_ARRAY, 0, 0, 0, int32(shellIdx),
+			vtd.textureSize, vtd.textureSize, 1,
+			gl.RGBA, gl.FLOAT, unsafe.Pointer(&tempData[0]))
+
+		gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.VelocityTexture)
+		gl.TexSubImage3D(gl.TEXTURE_2D_ARRAY, 0, 0, 0, int32(shellIdx),
+			vtd.textureSize, vtd.textureSize, 1,
+			gl.RGBA, gl.FLOAT, unsafe.Pointer(&velData[0]))
+	}
+
+}
+
+// HasPrevious reports whether the previous textures hold an uploaded planet
+func (vtd *VoxelTextureData) HasPrevious() bool {
+	return vtd.uploads >= 2
+}
+
+// Bind binds all textures to their texture units
+func (vtd *VoxelTextureData) Bind() {
+	gl.ActiveTexture(gl.TEXTURE0)
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.MaterialTexture)
+
+	gl.ActiveTexture(gl.TEXTURE1)
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.TemperatureTexture)
+
+	gl.ActiveTexture(gl.TEXTURE2)
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.VelocityTexture)
+
+	gl.ActiveTexture(gl.TEXTURE3)
+	gl.BindTexture(gl.TEXTURE_1D, vtd.ShellInfoTexture)
+
+	gl.ActiveTexture(gl.TEXTURE4)
+	gl.BindTexture(gl.TEXTURE_2D, vtd.HeatFluxTexture)
+
+	gl.ActiveTexture(gl.TEXTURE5)
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.PrevTemperatureTexture)
+
+	gl.ActiveTexture(gl.TEXTURE6)
+	gl.BindTexture(gl.TEXTURE_2D_ARRAY, vtd.PrevVelocityTexture)
+
+	gl.ActiveTexture(gl.TEXTURE7)
+	gl.BindTexture(gl.TEXTURE_2D, vtd.LonCountTexture)
+
+	// Unit 8 holds the renderer's colormaps
+	gl.ActiveTexture(gl.TEXTURE9)
+	gl.BindTexture(gl.TEXTURE_2D, vtd.RiverTexture)
+
+	gl.ActiveTexture(gl.TEXTURE10)
+	gl.BindTexture(gl.TEXTURE_2D, vtd.BiomeTexture)
+
+	// Unit 11 holds the renderer's plate hues
+	gl.ActiveTexture(gl.TEXTURE12)
+	gl.BindTexture(gl.TEXTURE_2D, vtd.PlateTrackTexture)
+}
+
+// Cleanup releases texture resources
+func (vtd *VoxelTextureData) Cleanup() {
+	if vtd.mapped != nil {
+		vtd.mapped.release()
+		vtd.mapped = nil
+	}
+	gl.DeleteTextures(1, &vtd.MaterialTexture)
+	gl.DeleteTextures(1, &vtd.TemperatureTexture)
+	gl.DeleteTextures(1, &vtd.VelocityTexture)
+	gl.DeleteTextures(1, &vtd.ShellInfoTexture)
+	gl.DeleteTextures(1, &vtd.HeatFluxTexture)
+	gl.DeleteTextures(1, &vtd.LonCountTexture)
+	gl.DeleteTextures(1, &vtd.RiverTexture)
+	gl.DeleteTextures(1, &vtd.PlateTrackTexture)
+	gl.DeleteTextures(1, &vtd.BiomeTexture)
+	gl.DeleteTextures(1, &vtd.PrevTemperatureTexture)
+	gl.DeleteTextures(1, &vtd.PrevVelocityTexture)
+}
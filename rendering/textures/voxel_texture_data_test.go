@@ -0,0 +1,200 @@
+package textures
+
+import (
+	"os"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/go-gl/gl/v4.3-core/gl"
+	"github.com/go-gl/glfw/v3.3/glfw"
+
+	"worldgenerator/core"
+)
+
+// TestFillShellTexelsStress checks each texel's alpha channel carries the
+// accumulated stress of the same voxel its temperature came from, and that
+// every voxel's stress reaches the texture
+func TestFillShellTexelsStress(t *testing.T) {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 4, 24)
+	shell := &planet.Shells[len(planet.Shells)-2]
+
+	// Label every voxel by temperature and give it a distinct stress
+	stressOf := make(map[float32]float32)
+	for latIdx := range shell.Voxels {
+		for lonIdx := range shell.Voxels[latIdx] {
+			voxel := &shell.Voxels[latIdx][lonIdx]
+			voxel.Temperature = float32(len(stressOf))
+			voxel.Stress = float32(len(stressOf)+1) * 1e6
+			stressOf[voxel.Temperature] = voxel.Stress
+		}
+	}
+
+	const size = 180
+	material := make([]float32, size*size)
+	temp := make([]float32, size*size*4)
+	vel := make([]float32, size*size*4)
+	fillShellTexels(shell, size, material, temp, vel)
+
+	seen := make(map[float32]bool)
+	for i := 0; i < size*size; i++ {
+		want, ok := stressOf[temp[i*4]]
+		if !ok {
+			t.Fatalf("texel %d temperature %g matches no voxel", i, temp[i*4])
+		}
+		if got := temp[i*4+3]; got != want {
+			t.Fatalf("texel %d stress %g, want %g from its voxel", i, got, want)
+		}
+		seen[want] = true
+	}
+	if len(seen) != len(stressOf) {
+		t.Errorf("%d of %d voxels' stress reached the texture", len(seen), len(stressOf))
+	}
+}
+
+// TestLonCountTexels checks every shell's longitude counts land in its own
+// row, so the shader sees the fewer cells of the polar bands
+func TestLonCountTexels(t *testing.T) {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 4, 24)
+	const maxShells = 6
+	width, texels := lonCountTexels(planet, maxShells)
+
+	if len(texels) != width*maxShells {
+		t.Fatalf("%d texels for %d bands by %d shells", len(texels), width, maxShells)
+	}
+	for shellIdx, shell := range planet.Shells {
+		if len(shell.LonCounts) > width {
+			t.Fatalf("shell %d has %d bands, wider than the %d texel rows", shellIdx, len(shell.LonCounts), width)
+		}
+		for lat, count := range shell.LonCounts {
+			if got := texels[shellIdx*width+lat]; got != float32(count) {
+				t.Errorf("shell %d band %d: texel %g, want %d longitudes", shellIdx, lat, got, count)
+			}
+		}
+	}
+
+	surface := planet.Shells[len(planet.Shells)-2].LonCounts
+	if surface[0] >= surface[len(surface)/2] {
+		t.Errorf("polar band has %d longitudes, equator %d", surface[0], surface[len(surface)/2])
+	}
+}
+
+// TestStaleShells checks only changed shells are uploaded again, including
+// into the older texture pair that becomes current after the next swap
+func TestStaleShells(t *testing.T) {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 4, 24)
+	maxShells := len(planet.Shells)
+	vtd := &VoxelTextureData{
+		maxShells:         int32(maxShells),
+		materialVersions:  make([]uint64, maxShells),
+		fieldVersions:     make([]uint64, maxShells),
+		prevFieldVersions: make([]uint64, maxShells),
+	}
+	all := []int{0, 1, 2, 3}
+	surface := len(planet.Shells) - 2
+
+	// upload runs UpdateFromPlanet's bookkeeping and returns the shells it
+	// would rewrite
+	upload := func() []int {
+		vtd.swapFieldTextures()
+		stale := vtd.staleShells(planet)
+		vtd.recordUpload(planet, stale)
+		return stale
+	}
+
+	// Untracked shells are always uploaded
+	if got := upload(); !slices.Equal(got, all) {
+		t.Fatalf("first upload rewrote %v, want %v", got, all)
+	}
+	if got := upload(); !slices.Equal(got, all) {
+		t.Fatalf("untracked upload rewrote %v, want %v", got, all)
+	}
+
+	// Once tracked, both texture pairs take every shell once
+	planet.MarkAllShellsDirty()
+	if got := upload(); !slices.Equal(got, all) {
+		t.Fatalf("all dirty rewrote %v, want %v", got, all)
+	}
+	if got := upload(); !slices.Equal(got, all) {
+		t.Fatalf("older pair rewrote %v, want %v", got, all)
+	}
+	if got := upload(); len(got) != 0 {
+		t.Fatalf("unchanged planet rewrote %v", got)
+	}
+
+	// A surface change reaches both pairs and nothing else is touched
+	planet.MarkShellDirty(surface)
+	if got := upload(); !slices.Equal(got, []int{surface}) {
+		t.Fatalf("surface change rewrote %v, want [%d]", got, surface)
+	}
+	if got := upload(); !slices.Equal(got, []int{surface}) {
+		t.Fatalf("older pair rewrote %v after a surface change, want [%d]", got, surface)
+	}
+	if got := upload(); len(got) != 0 {
+		t.Fatalf("settled planet rewrote %v", got)
+	}
+}
+
+// openTextureContext makes a hidden OpenGL context current for the texture
+// benchmarks, skipping them without a display. The returned function closes it
+func openTextureContext(tb testing.TB) func() {
+	if runtime.GOOS == "linux" && os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+		tb.Skip("no display")
+	}
+	if err := glfw.Init(); err != nil {
+		tb.Skipf("no GLFW: %v", err)
+	}
+	glfw.WindowHint(glfw.ContextVersionMajor, 4)
+	glfw.WindowHint(glfw.ContextVersionMinor, 1)
+	glfw.WindowHint(glfw.OpenGLProfile, glfw.OpenGLCoreProfile)
+	glfw.WindowHint(glfw.OpenGLForwardCompatible, glfw.True)
+	glfw.WindowHint(glfw.Visible, glfw.False)
+	window, err := glfw.CreateWindow(1, 1, "test", nil, nil)
+	if err != nil {
+		glfw.Terminate()
+		tb.Skipf("no OpenGL 4.1 context: %v", err)
+	}
+	window.MakeContextCurrent()
+	if err := gl.Init(); err != nil {
+		window.Destroy()
+		glfw.Terminate()
+		tb.Skipf("no OpenGL: %v", err)
+	}
+	return func() {
+		window.Destroy()
+		glfw.Terminate()
+	}
+}
+
+// BenchmarkUpdateFromPlanet compares a full texture upload with one where
+// physics only touched the surface shell
+func BenchmarkUpdateFromPlanet(b *testing.B) {
+	defer openTextureContext(b)()
+
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 20, 180)
+	surface := len(planet.Shells) - 2
+
+	for _, bench := range []struct {
+		name  string
+		touch func()
+	}{
+		{"all shells", planet.MarkAllShellsDirty},
+		{"surface only", func() { planet.MarkShellDirty(surface) }},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			vtd := NewVoxelTextureData(len(planet.Shells))
+			defer vtd.Cleanup()
+			planet.MarkAllShellsDirty()
+			vtd.UpdateFromPlanet(planet)
+			vtd.UpdateFromPlanet(planet)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				bench.touch()
+				vtd.UpdateFromPlanet(planet)
+			}
+			gl.Finish()
+			b.ReportMetric(float64(vtd.LastUploadShells), "shells/op")
+		})
+	}
+}
@@ -0,0 +1,327 @@
+// Package vulkan draws the planet through Vulkan, as an alternative to the
+// OpenGL renderer for drivers where Vulkan is the better supported API. It
+// mirrors the surface of opengl.VoxelRenderer that the main loop uses, but
+// so far only ray casts the surface shell in the material and elevation
+// views. The Vulkan calls need the vulkan build tag; without it
+// NewVoxelRenderer always fails
+package vulkan
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"runtime"
+	"time"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+
+	"worldgenerator/core"
+)
+
+// DefaultWindowTitle prefixes the live stats in the window title
+const DefaultWindowTitle = "Voxel Planet Evolution (Vulkan)"
+
+// errUnavailable is returned by NewVoxelRenderer without the vulkan build tag
+var errUnavailable = errors.New("Vulkan unavailable: built without Vulkan support (rebuild with -tags vulkan)")
+
+// Camera limits in planet radii from the center
+const (
+	defaultCameraDistance = 3.0
+	minCameraDistance     = 1.05
+	maxCameraDistance     = 20.0
+)
+
+// defaultFieldOfView is the camera's vertical field of view in degrees
+const defaultFieldOfView = 45.0
+
+// renderModeNames names each render mode, in mode order, as the OpenGL
+// renderer does
+var renderModeNames = []string{
+	"Material", "Temperature", "Velocity", "Age", "Plates",
+	"Stress", "Sub-position", "Elevation", "Heat flux", "Biomes",
+}
+
+// controlsHelp lists the controls the Vulkan renderer handles
+var controlsHelp = []string{
+	"1: Material view",
+	"8: Elevation view",
+	"Shift+1-5: Time speed 10x, 100x, 1000x, 10000x, 100000x",
+	"0: Reset time speed to 1x",
+	"P: Pause/unpause simulation",
+	".: Advance one physics step while paused",
+	"N: Advance -step-years while paused",
+	"Esc: Exit",
+	"Mouse drag: Rotate",
+	"Scroll: Zoom in/out",
+}
+
+// VoxelRenderer draws voxel planets through Vulkan
+type VoxelRenderer struct {
+	window *glfw.Window
+	device *device // Vulkan objects, see renderer_vk_device.go
+
+	// Surface colors, recolored from planet when the render mode changes
+	grid      surfaceGrid
+	gridMode  core.RenderMode
+	planet    *core.VoxelPlanet
+	uploadDur time.Duration
+
+	// Render settings
+	width, height int
+	RenderMode    int32 // 0=material, 7=elevation; other modes aren't drawn yet
+	FieldOfView   float32
+	planetRadius  float32
+
+	// Orbit camera, distance in planet radii
+	cameraDistance  float32
+	cameraRotationX float32
+	cameraRotationY float32
+	MouseDown       bool
+	lastMouseX      float64
+	lastMouseY      float64
+
+	// Window title prefix for live stats (empty = DefaultWindowTitle)
+	WindowTitle string
+
+	// Simulation control (public for main.go access)
+	SpeedMultiplier    float32
+	Paused             bool
+	StepRequested      bool
+	StepYearsRequested bool
+}
+
+// NewVoxelRenderer opens a window and sets up Vulkan to draw into it
+func NewVoxelRenderer(width, height int) (*VoxelRenderer, error) {
+	if !supported {
+		return nil, errUnavailable
+	}
+	runtime.LockOSThread()
+
+	if err := glfw.Init(); err != nil {
+		return nil, fmt.Errorf("failed to initialize GLFW: %v", err)
+	}
+	if !glfw.VulkanSupported() {
+		glfw.Terminate()
+		return nil, fmt.Errorf("no Vulkan loader found")
+	}
+
+	// Vulkan draws into the window itself, so GLFW makes no OpenGL context
+	glfw.WindowHint(glfw.ClientAPI, glfw.NoAPI)
+	glfw.WindowHint(glfw.Resizable, glfw.True)
+	window, err := glfw.CreateWindow(width, height, DefaultWindowTitle, nil, nil)
+	if err != nil {
+		glfw.Terminate()
+		return nil, fmt.Errorf("failed to create window: %v", err)
+	}
+
+	dev, err := newDevice(window)
+	if err != nil {
+		window.Destroy()
+		glfw.Terminate()
+		return nil, fmt.Errorf("failed to initialize Vulkan: %v", err)
+	}
+
+	r := &VoxelRenderer{
+		window:          window,
+		device:          dev,
+		width:           width,
+		height:          height,
+		FieldOfView:     defaultFieldOfView,
+		planetRadius:    6371000,
+		cameraDistance:  defaultCameraDistance,
+		SpeedMultiplier: 1.0,
+	}
+
+	window.SetFramebufferSizeCallback(func(w *glfw.Window, width, height int) {
+		r.onResize(width, height)
+	})
+	window.SetKeyCallback(func(w *glfw.Window, key glfw.Key, scancode int, action glfw.Action, mods glfw.ModifierKey) {
+		r.onKey(key, action, mods)
+	})
+	window.SetScrollCallback(func(w *glfw.Window, xoff, yoff float64) {
+		r.onScroll(yoff)
+	})
+	window.SetMouseButtonCallback(func(w *glfw.Window, button glfw.MouseButton, action glfw.Action, mods glfw.ModifierKey) {
+		if button == glfw.MouseButtonLeft {
+			r.MouseDown = action == glfw.Press
+			r.lastMouseX, r.lastMouseY = w.GetCursorPos()
+		}
+	})
+	window.SetCursorPosCallback(func(w *glfw.Window, xpos, ypos float64) {
+		r.onMouseMove(xpos, ypos)
+	})
+
+	return r, nil
+}
+
+// CreateBuffers allocates the surface color buffer for a planet. The Vulkan
+// renderer draws from the surface shell alone, so unlike the OpenGL renderer
+// it needs no voxel buffers
+func (r *VoxelRenderer) CreateBuffers(planet *core.VoxelPlanet) error {
+	r.planetRadius = float32(planet.Radius)
+	columns, rows := surfaceGridSize(planet)
+	return r.device.allocateTexels(columns * rows)
+}
+
+// UpdateVoxelTextures colors the planet's surface for the current render
+// mode and uploads it for the next frame
+func (r *VoxelRenderer) UpdateVoxelTextures(planet *core.VoxelPlanet) error {
+	r.planet = planet
+	return r.uploadSurface()
+}
+
+// uploadSurface recolors the last planet for the current render mode
+func (r *VoxelRenderer) uploadSurface() error {
+	if r.planet == nil {
+		return nil
+	}
+	start := time.Now()
+	r.gridMode = r.drawnMode()
+	fillSurfaceTexels(&r.grid, r.planet, r.gridMode)
+	err := r.device.uploadTexels(r.grid.Texels)
+	r.uploadDur = time.Since(start)
+	return err
+}
+
+// drawnMode returns the render mode the surface is colored with
+func (r *VoxelRenderer) drawnMode() core.RenderMode {
+	if core.RenderMode(r.RenderMode) == core.RenderElevation {
+		return core.RenderElevation
+	}
+	return core.RenderMaterial
+}
+
+// TextureUploadTime returns how long the last surface upload took
+func (r *VoxelRenderer) TextureUploadTime() time.Duration {
+	return r.uploadDur
+}
+
+// Render draws one frame and presents it
+func (r *VoxelRenderer) Render() error {
+	if r.drawnMode() != r.gridMode {
+		if err := r.uploadSurface(); err != nil {
+			return err
+		}
+	}
+	if r.width == 0 || r.height == 0 {
+		return nil // Minimized
+	}
+	camera := newCameraUniforms(r.cameraRotationX, r.cameraRotationY, r.cameraDistance,
+		r.FieldOfView, float32(r.width)/float32(r.height), &r.grid)
+	return r.device.draw(&camera)
+}
+
+// GetCameraDistance returns the current camera distance from the planet center
+func (r *VoxelRenderer) GetCameraDistance() float32 {
+	return r.cameraDistance * r.planetRadius
+}
+
+// UpdateTitle shows sim time, FPS and speed in the window title
+func (r *VoxelRenderer) UpdateTitle(simYears, fps float64) {
+	name := r.WindowTitle
+	if name == "" {
+		name = DefaultWindowTitle
+	}
+
+	speed := fmt.Sprintf("%.0fx", r.SpeedMultiplier)
+	if r.Paused {
+		speed = "paused"
+	}
+
+	r.window.SetTitle(fmt.Sprintf("%s — %.1f My — %.0f FPS — %s", name, simYears/1e6, fps, speed))
+}
+
+// ControlsHelp returns one "keys: action" line per control, for printing
+func (r *VoxelRenderer) ControlsHelp() []string {
+	return controlsHelp
+}
+
+// setRenderMode switches views, keeping the current one for views the
+// Vulkan renderer can't draw yet
+func (r *VoxelRenderer) setRenderMode(mode int32) {
+	switch core.RenderMode(mode) {
+	case core.RenderMaterial:
+		fmt.Println("Switched to material view")
+	case core.RenderElevation:
+		fmt.Println("Switched to elevation visualization")
+		fmt.Println("Blue=ocean trenches, Green=lowlands, Yellow=highlands, Red=mountains, White=peaks")
+	default:
+		fmt.Printf("%s view isn't supported by the Vulkan renderer yet, staying in %s view\n",
+			renderModeNames[mode], renderModeNames[r.RenderMode])
+		return
+	}
+	r.RenderMode = mode
+}
+
+func (r *VoxelRenderer) onResize(width, height int) {
+	r.width = width
+	r.height = height
+	r.device.resize()
+}
+
+func (r *VoxelRenderer) onKey(key glfw.Key, action glfw.Action, mods glfw.ModifierKey) {
+	if action != glfw.Press {
+		return
+	}
+
+	switch {
+	case key >= glfw.Key1 && key <= glfw.Key5 && mods&glfw.ModShift != 0:
+		r.SpeedMultiplier = float32(math.Pow(10, float64(key-glfw.Key0)))
+		fmt.Printf("Time speed: %.0fx\n", r.SpeedMultiplier)
+	case key >= glfw.Key1 && key <= glfw.Key9:
+		r.setRenderMode(int32(key - glfw.Key1))
+	case key == glfw.Key0:
+		r.SpeedMultiplier = 1.0
+		fmt.Println("Time speed reset to 1x")
+	case key == glfw.KeyP:
+		r.Paused = !r.Paused
+		if r.Paused {
+			fmt.Println("Simulation PAUSED")
+		} else {
+			fmt.Println("Simulation RESUMED")
+		}
+	case key == glfw.KeyPeriod || key == glfw.KeyN:
+		if !r.Paused {
+			fmt.Println("Pause (P) before stepping")
+		} else if key == glfw.KeyPeriod {
+			r.StepRequested = true
+		} else {
+			r.StepYearsRequested = true
+		}
+	case key == glfw.KeyEscape:
+		r.window.SetShouldClose(true)
+	}
+}
+
+func (r *VoxelRenderer) onScroll(yoff float64) {
+	distance := r.cameraDistance * float32(1.0-yoff*0.1) // Inverted for natural scrolling
+	r.cameraDistance = max(minCameraDistance, min(distance, maxCameraDistance))
+}
+
+func (r *VoxelRenderer) onMouseMove(xpos, ypos float64) {
+	if !r.MouseDown {
+		return
+	}
+	const sensitivity = 0.008
+	r.cameraRotationX += float32(xpos-r.lastMouseX) * sensitivity
+	r.cameraRotationY += float32(ypos-r.lastMouseY) * sensitivity
+	r.cameraRotationY = max(-1.5, min(r.cameraRotationY, 1.5))
+	r.lastMouseX, r.lastMouseY = xpos, ypos
+}
+
+// ShouldClose returns true if the window should close
+func (r *VoxelRenderer) ShouldClose() bool {
+	return r.window.ShouldClose()
+}
+
+// PollEvents processes window events
+func (r *VoxelRenderer) PollEvents() {
+	glfw.PollEvents()
+}
+
+// Terminate waits for the GPU, frees the Vulkan objects and closes the window
+func (r *VoxelRenderer) Terminate() {
+	r.device.release()
+	r.window.Destroy()
+	glfw.Terminate()
+}
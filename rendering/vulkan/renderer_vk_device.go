@@ -0,0 +1,799 @@
+//go:build vulkan
+// +build vulkan
+
+package vulkan
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/go-gl/glfw/v3.3/glfw"
+	vk "github.com/vulkan-go/vulkan"
+)
+
+// supported reports whether this build can talk to Vulkan
+const supported = true
+
+// device holds the Vulkan objects behind a window: one graphics queue that
+// also presents, a swapchain recreated on resize, and a single pipeline that
+// ray casts the planet from a fullscreen triangle. One frame is in flight at
+// a time, so the host-visible camera and texel buffers can be rewritten as
+// soon as the previous frame's fence signals
+type device struct {
+	window   *glfw.Window
+	instance vk.Instance
+	surface  vk.Surface
+	gpu      vk.PhysicalDevice
+	logical  vk.Device
+	queue    vk.Queue
+	family   uint32
+	memory   vk.PhysicalDeviceMemoryProperties
+
+	// Swapchain and the per-image views and framebuffers drawn into
+	swapchain    vk.Swapchain
+	format       vk.Format
+	extent       vk.Extent2D
+	views        []vk.ImageView
+	framebuffers []vk.Framebuffer
+	stale        bool // Window resized, rebuild before the next frame
+
+	renderPass     vk.RenderPass
+	setLayout      vk.DescriptorSetLayout
+	pipelineLayout vk.PipelineLayout
+	pipeline       vk.Pipeline
+	descriptorPool vk.DescriptorPool
+	descriptorSet  vk.DescriptorSet
+
+	// Camera uniforms and surface texels, both persistently mapped
+	camera hostBuffer
+	texels hostBuffer
+
+	commandPool   vk.CommandPool
+	commands      vk.CommandBuffer
+	imageReady    vk.Semaphore
+	renderDone    vk.Semaphore
+	frameFinished vk.Fence
+}
+
+// hostBuffer is a buffer in host-visible, coherent memory, mapped for its
+// whole life
+type hostBuffer struct {
+	buffer vk.Buffer
+	memory vk.DeviceMemory
+	size   int
+	mapped unsafe.Pointer
+}
+
+// check turns a failed Vulkan result into an error naming the call
+func check(result vk.Result, call string) error {
+	if err := vk.Error(result); err != nil {
+		return fmt.Errorf("%s: %v", call, err)
+	}
+	return nil
+}
+
+// newDevice creates the Vulkan instance and device for a window, and
+// everything needed to draw into it except the texel buffer, which waits
+// for the planet's size (see allocateTexels)
+func newDevice(window *glfw.Window) (*device, error) {
+	vk.SetGetInstanceProcAddr(glfw.GetVulkanGetInstanceProcAddress())
+	if err := vk.Init(); err != nil {
+		return nil, fmt.Errorf("failed to load Vulkan: %v", err)
+	}
+
+	d := &device{window: window, format: vk.FormatB8g8r8a8Unorm}
+	steps := []func() error{
+		d.createInstance,
+		d.pickDevice,
+		d.createLogicalDevice,
+		d.createSwapchain,
+		d.createPipeline,
+		d.createDescriptors,
+		d.createCommands,
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			d.release()
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// safeStrings null-terminates strings for the Vulkan C API
+func safeStrings(names []string) []string {
+	terminated := make([]string, len(names))
+	for i, name := range names {
+		terminated[i] = name + "\x00"
+	}
+	return terminated
+}
+
+func (d *device) createInstance() error {
+	extensions := safeStrings(d.window.GetRequiredInstanceExtensions())
+	info := vk.InstanceCreateInfo{
+		SType: vk.StructureTypeInstanceCreateInfo,
+		PApplicationInfo: &vk.ApplicationInfo{
+			SType:            vk.StructureTypeApplicationInfo,
+			PApplicationName: "worldgenerator\x00",
+			PEngineName:      "worldgenerator\x00",
+			ApiVersion:       vk.MakeVersion(1, 0, 0),
+		},
+		EnabledExtensionCount:   uint32(len(extensions)),
+		PpEnabledExtensionNames: extensions,
+	}
+	if err := check(vk.CreateInstance(&info, nil, &d.instance), "vkCreateInstance"); err != nil {
+		return err
+	}
+	if err := vk.InitInstance(d.instance); err != nil {
+		return fmt.Errorf("failed to load instance functions: %v", err)
+	}
+
+	surface, err := d.window.CreateWindowSurface(d.instance, nil)
+	if err != nil {
+		return err
+	}
+	d.surface = vk.SurfaceFromPointer(surface)
+	return nil
+}
+
+// pickDevice takes the first GPU with a queue family that both draws and
+// presents to the window
+func (d *device) pickDevice() error {
+	var count uint32
+	if err := check(vk.EnumeratePhysicalDevices(d.instance, &count, nil), "vkEnumeratePhysicalDevices"); err != nil {
+		return err
+	}
+	gpus := make([]vk.PhysicalDevice, count)
+	if err := check(vk.EnumeratePhysicalDevices(d.instance, &count, gpus), "vkEnumeratePhysicalDevices"); err != nil {
+		return err
+	}
+
+	for _, gpu := range gpus {
+		var familyCount uint32
+		vk.GetPhysicalDeviceQueueFamilyProperties(gpu, &familyCount, nil)
+		families := make([]vk.QueueFamilyProperties, familyCount)
+		vk.GetPhysicalDeviceQueueFamilyProperties(gpu, &familyCount, families)
+		for i := range families {
+			families[i].Deref()
+			var present vk.Bool32
+			vk.GetPhysicalDeviceSurfaceSupport(gpu, uint32(i), d.surface, &present)
+			if families[i].QueueFlags&vk.QueueFlags(vk.QueueGraphicsBit) != 0 && present.B() {
+				d.gpu, d.family = gpu, uint32(i)
+
+				var props vk.PhysicalDeviceProperties
+				vk.GetPhysicalDeviceProperties(gpu, &props)
+				props.Deref()
+				fmt.Println("Vulkan device:", vk.ToString(props.DeviceName[:]))
+
+				vk.GetPhysicalDeviceMemoryProperties(gpu, &d.memory)
+				d.memory.Deref()
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("no GPU can draw to the window")
+}
+
+func (d *device) createLogicalDevice() error {
+	extensions := safeStrings([]string{vk.KhrSwapchainExtensionName})
+	info := vk.DeviceCreateInfo{
+		SType:                vk.StructureTypeDeviceCreateInfo,
+		QueueCreateInfoCount: 1,
+		PQueueCreateInfos: []vk.DeviceQueueCreateInfo{{
+			SType:            vk.StructureTypeDeviceQueueCreateInfo,
+			QueueFamilyIndex: d.family,
+			QueueCount:       1,
+			PQueuePriorities: []float32{1},
+		}},
+		EnabledExtensionCount:   uint32(len(extensions)),
+		PpEnabledExtensionNames: extensions,
+	}
+	if err := check(vk.CreateDevice(d.gpu, &info, nil, &d.logical), "vkCreateDevice"); err != nil {
+		return err
+	}
+	var queue vk.Queue
+	vk.GetDeviceQueue(d.logical, d.family, 0, &queue)
+	d.queue = queue
+	return nil
+}
+
+// createSwapchain sizes the swapchain to the window, with a view and
+// framebuffer per image once the render pass exists
+func (d *device) createSwapchain() error {
+	var caps vk.SurfaceCapabilities
+	if err := check(vk.GetPhysicalDeviceSurfaceCapabilities(d.gpu, d.surface, &caps), "vkGetPhysicalDeviceSurfaceCapabilitiesKHR"); err != nil {
+		return err
+	}
+	caps.Deref()
+	caps.CurrentExtent.Deref()
+	caps.MinImageExtent.Deref()
+	caps.MaxImageExtent.Deref()
+
+	// Most drivers fix the extent to the window; the rest leave it to us
+	d.extent = caps.CurrentExtent
+	if d.extent.Width == vk.MaxUint32 {
+		width, height := d.window.GetFramebufferSize()
+		d.extent.Width = max(caps.MinImageExtent.Width, min(uint32(width), caps.MaxImageExtent.Width))
+		d.extent.Height = max(caps.MinImageExtent.Height, min(uint32(height), caps.MaxImageExtent.Height))
+	}
+	if d.extent.Width == 0 || d.extent.Height == 0 {
+		d.stale = true // Minimized, try again once the window has a size
+		return nil
+	}
+
+	var formatCount uint32
+	vk.GetPhysicalDeviceSurfaceFormats(d.gpu, d.surface, &formatCount, nil)
+	formats := make([]vk.SurfaceFormat, formatCount)
+	vk.GetPhysicalDeviceSurfaceFormats(d.gpu, d.surface, &formatCount, formats)
+	colorSpace := vk.ColorSpaceSrgbNonlinear
+	if len(formats) > 0 {
+		formats[0].Deref()
+		d.format, colorSpace = formats[0].Format, formats[0].ColorSpace
+		for i := range formats {
+			formats[i].Deref()
+			if formats[i].Format == vk.FormatB8g8r8a8Unorm {
+				d.format, colorSpace = formats[i].Format, formats[i].ColorSpace
+				break
+			}
+		}
+	}
+
+	images := caps.MinImageCount + 1
+	if caps.MaxImageCount > 0 {
+		images = min(images, caps.MaxImageCount)
+	}
+	old := d.swapchain
+	info := vk.SwapchainCreateInfo{
+		SType:            vk.StructureTypeSwapchainCreateInfo,
+		Surface:          d.surface,
+		MinImageCount:    images,
+		ImageFormat:      d.format,
+		ImageColorSpace:  colorSpace,
+		ImageExtent:      d.extent,
+		ImageArrayLayers: 1,
+		ImageUsage:       vk.ImageUsageFlags(vk.ImageUsageColorAttachmentBit),
+		ImageSharingMode: vk.SharingModeExclusive,
+		PreTransform:     caps.CurrentTransform,
+		CompositeAlpha:   vk.CompositeAlphaOpaqueBit,
+		PresentMode:      vk.PresentModeFifo, // The only mode every driver has
+		Clipped:          vk.True,
+		OldSwapchain:     old,
+	}
+	var swapchain vk.Swapchain
+	if err := check(vk.CreateSwapchain(d.logical, &info, nil, &swapchain), "vkCreateSwapchainKHR"); err != nil {
+		return err
+	}
+	if old != vk.NullSwapchain {
+		vk.DestroySwapchain(d.logical, old, nil)
+	}
+	d.swapchain = swapchain
+
+	var imageCount uint32
+	vk.GetSwapchainImages(d.logical, d.swapchain, &imageCount, nil)
+	swapImages := make([]vk.Image, imageCount)
+	vk.GetSwapchainImages(d.logical, d.swapchain, &imageCount, swapImages)
+	d.views = make([]vk.ImageView, imageCount)
+	for i, image := range swapImages {
+		viewInfo := vk.ImageViewCreateInfo{
+			SType:    vk.StructureTypeImageViewCreateInfo,
+			Image:    image,
+			ViewType: vk.ImageViewType2d,
+			Format:   d.format,
+			Components: vk.ComponentMapping{
+				R: vk.ComponentSwizzleIdentity, G: vk.ComponentSwizzleIdentity,
+				B: vk.ComponentSwizzleIdentity, A: vk.ComponentSwizzleIdentity,
+			},
+			SubresourceRange: vk.ImageSubresourceRange{
+				AspectMask: vk.ImageAspectFlags(vk.ImageAspectColorBit),
+				LevelCount: 1,
+				LayerCount: 1,
+			},
+		}
+		if err := check(vk.CreateImageView(d.logical, &viewInfo, nil, &d.views[i]), "vkCreateImageView"); err != nil {
+			return err
+		}
+	}
+	d.stale = false
+
+	if d.renderPass != vk.NullRenderPass {
+		return d.createFramebuffers()
+	}
+	return nil
+}
+
+func (d *device) createFramebuffers() error {
+	d.framebuffers = make([]vk.Framebuffer, len(d.views))
+	for i, view := range d.views {
+		info := vk.FramebufferCreateInfo{
+			SType:           vk.StructureTypeFramebufferCreateInfo,
+			RenderPass:      d.renderPass,
+			AttachmentCount: 1,
+			PAttachments:    []vk.ImageView{view},
+			Width:           d.extent.Width,
+			Height:          d.extent.Height,
+			Layers:          1,
+		}
+		if err := check(vk.CreateFramebuffer(d.logical, &info, nil, &d.framebuffers[i]), "vkCreateFramebuffer"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseSwapchain frees the per-image views and framebuffers, keeping the
+// swapchain itself to hand over to its replacement
+func (d *device) releaseSwapchain() {
+	for _, fb := range d.framebuffers {
+		vk.DestroyFramebuffer(d.logical, fb, nil)
+	}
+	for _, view := range d.views {
+		vk.DestroyImageView(d.logical, view, nil)
+	}
+	d.framebuffers, d.views = nil, nil
+}
+
+// createPipeline builds the render pass and the ray cast pipeline. The
+// viewport is dynamic so resizes don't rebuild the pipeline
+func (d *device) createPipeline() error {
+	passInfo := vk.RenderPassCreateInfo{
+		SType:           vk.StructureTypeRenderPassCreateInfo,
+		AttachmentCount: 1,
+		PAttachments: []vk.AttachmentDescription{{
+			Format:         d.format,
+			Samples:        vk.SampleCount1Bit,
+			LoadOp:         vk.AttachmentLoadOpClear,
+			StoreOp:        vk.AttachmentStoreOpStore,
+			StencilLoadOp:  vk.AttachmentLoadOpDontCare,
+			StencilStoreOp: vk.AttachmentStoreOpDontCare,
+			InitialLayout:  vk.ImageLayoutUndefined,
+			FinalLayout:    vk.ImageLayoutPresentSrc,
+		}},
+		SubpassCount: 1,
+		PSubpasses: []vk.SubpassDescription{{
+			PipelineBindPoint:    vk.PipelineBindPointGraphics,
+			ColorAttachmentCount: 1,
+			PColorAttachments: []vk.AttachmentReference{{
+				Attachment: 0,
+				Layout:     vk.ImageLayoutColorAttachmentOptimal,
+			}},
+		}},
+		DependencyCount: 1,
+		PDependencies: []vk.SubpassDependency{{
+			SrcSubpass:    vk.SubpassExternal,
+			SrcStageMask:  vk.PipelineStageFlags(vk.PipelineStageColorAttachmentOutputBit),
+			DstStageMask:  vk.PipelineStageFlags(vk.PipelineStageColorAttachmentOutputBit),
+			DstAccessMask: vk.AccessFlags(vk.AccessColorAttachmentWriteBit),
+		}},
+	}
+	if err := check(vk.CreateRenderPass(d.logical, &passInfo, nil, &d.renderPass), "vkCreateRenderPass"); err != nil {
+		return err
+	}
+	if !d.stale {
+		if err := d.createFramebuffers(); err != nil {
+			return err
+		}
+	}
+
+	layoutInfo := vk.DescriptorSetLayoutCreateInfo{
+		SType:        vk.StructureTypeDescriptorSetLayoutCreateInfo,
+		BindingCount: 2,
+		PBindings: []vk.DescriptorSetLayoutBinding{
+			{
+				Binding:         0,
+				DescriptorType:  vk.DescriptorTypeUniformBuffer,
+				DescriptorCount: 1,
+				StageFlags:      vk.ShaderStageFlags(vk.ShaderStageFragmentBit),
+			},
+			{
+				Binding:         1,
+				DescriptorType:  vk.DescriptorTypeStorageBuffer,
+				DescriptorCount: 1,
+				StageFlags:      vk.ShaderStageFlags(vk.ShaderStageFragmentBit),
+			},
+		},
+	}
+	if err := check(vk.CreateDescriptorSetLayout(d.logical, &layoutInfo, nil, &d.setLayout), "vkCreateDescriptorSetLayout"); err != nil {
+		return err
+	}
+	pipelineLayoutInfo := vk.PipelineLayoutCreateInfo{
+		SType:          vk.StructureTypePipelineLayoutCreateInfo,
+		SetLayoutCount: 1,
+		PSetLayouts:    []vk.DescriptorSetLayout{d.setLayout},
+	}
+	if err := check(vk.CreatePipelineLayout(d.logical, &pipelineLayoutInfo, nil, &d.pipelineLayout), "vkCreatePipelineLayout"); err != nil {
+		return err
+	}
+
+	code := unsafe.Slice((*uint32)(unsafe.Pointer(&raymarchSPIRV[0])), len(raymarchSPIRV)/4)
+	moduleInfo := vk.ShaderModuleCreateInfo{
+		SType:    vk.StructureTypeShaderModuleCreateInfo,
+		CodeSize: uint(len(raymarchSPIRV)),
+		PCode:    code,
+	}
+	var module vk.ShaderModule
+	if err := check(vk.CreateShaderModule(d.logical, &moduleInfo, nil, &module), "vkCreateShaderModule"); err != nil {
+		return err
+	}
+	defer vk.DestroyShaderModule(d.logical, module, nil)
+
+	pipelineInfo := vk.GraphicsPipelineCreateInfo{
+		SType:      vk.StructureTypeGraphicsPipelineCreateInfo,
+		StageCount: 2,
+		PStages: []vk.PipelineShaderStageCreateInfo{
+			{
+				SType:  vk.StructureTypePipelineShaderStageCreateInfo,
+				Stage:  vk.ShaderStageVertexBit,
+				Module: module,
+				PName:  "vs_main\x00",
+			},
+			{
+				SType:  vk.StructureTypePipelineShaderStageCreateInfo,
+				Stage:  vk.ShaderStageFragmentBit,
+				Module: module,
+				PName:  "fs_main\x00",
+			},
+		},
+		PVertexInputState: &vk.PipelineVertexInputStateCreateInfo{
+			SType: vk.StructureTypePipelineVertexInputStateCreateInfo,
+		},
+		PInputAssemblyState: &vk.PipelineInputAssemblyStateCreateInfo{
+			SType:    vk.StructureTypePipelineInputAssemblyStateCreateInfo,
+			Topology: vk.PrimitiveTopologyTriangleList,
+		},
+		PViewportState: &vk.PipelineViewportStateCreateInfo{
+			SType:         vk.StructureTypePipelineViewportStateCreateInfo,
+			ViewportCount: 1,
+			ScissorCount:  1,
+		},
+		PRasterizationState: &vk.PipelineRasterizationStateCreateInfo{
+			SType:       vk.StructureTypePipelineRasterizationStateCreateInfo,
+			PolygonMode: vk.PolygonModeFill,
+			CullMode:    vk.CullModeFlags(vk.CullModeNone),
+			FrontFace:   vk.FrontFaceCounterClockwise,
+			LineWidth:   1,
+		},
+		PMultisampleState: &vk.PipelineMultisampleStateCreateInfo{
+			SType:                vk.StructureTypePipelineMultisampleStateCreateInfo,
+			RasterizationSamples: vk.SampleCount1Bit,
+		},
+		PColorBlendState: &vk.PipelineColorBlendStateCreateInfo{
+			SType:           vk.StructureTypePipelineColorBlendStateCreateInfo,
+			AttachmentCount: 1,
+			PAttachments: []vk.PipelineColorBlendAttachmentState{{
+				ColorWriteMask: vk.ColorComponentFlags(vk.ColorComponentRBit | vk.ColorComponentGBit |
+					vk.ColorComponentBBit | vk.ColorComponentABit),
+			}},
+		},
+		PDynamicState: &vk.PipelineDynamicStateCreateInfo{
+			SType:             vk.StructureTypePipelineDynamicStateCreateInfo,
+			DynamicStateCount: 2,
+			PDynamicStates:    []vk.DynamicState{vk.DynamicStateViewport, vk.DynamicStateScissor},
+		},
+		Layout:     d.pipelineLayout,
+		RenderPass: d.renderPass,
+	}
+	pipelines := make([]vk.Pipeline, 1)
+	if err := check(vk.CreateGraphicsPipelines(d.logical, vk.NullPipelineCache, 1,
+		[]vk.GraphicsPipelineCreateInfo{pipelineInfo}, nil, pipelines), "vkCreateGraphicsPipelines"); err != nil {
+		return err
+	}
+	d.pipeline = pipelines[0]
+	return nil
+}
+
+// createDescriptors allocates the descriptor set and the camera buffer it
+// points binding 0 at; binding 1 is pointed at the texels by allocateTexels
+func (d *device) createDescriptors() error {
+	poolInfo := vk.DescriptorPoolCreateInfo{
+		SType:         vk.StructureTypeDescriptorPoolCreateInfo,
+		MaxSets:       1,
+		PoolSizeCount: 2,
+		PPoolSizes: []vk.DescriptorPoolSize{
+			{Type: vk.DescriptorTypeUniformBuffer, DescriptorCount: 1},
+			{Type: vk.DescriptorTypeStorageBuffer, DescriptorCount: 1},
+		},
+	}
+	if err := check(vk.CreateDescriptorPool(d.logical, &poolInfo, nil, &d.descriptorPool), "vkCreateDescriptorPool"); err != nil {
+		return err
+	}
+	allocInfo := vk.DescriptorSetAllocateInfo{
+		SType:              vk.StructureTypeDescriptorSetAllocateInfo,
+		DescriptorPool:     d.descriptorPool,
+		DescriptorSetCount: 1,
+		PSetLayouts:        []vk.DescriptorSetLayout{d.setLayout},
+	}
+	if err := check(vk.AllocateDescriptorSets(d.logical, &allocInfo, &d.descriptorSet), "vkAllocateDescriptorSets"); err != nil {
+		return err
+	}
+
+	if err := d.createHostBuffer(&d.camera, int(unsafe.Sizeof(cameraUniforms{})), vk.BufferUsageUniformBufferBit); err != nil {
+		return err
+	}
+	d.bindBuffer(0, vk.DescriptorTypeUniformBuffer, &d.camera)
+	return nil
+}
+
+// createHostBuffer creates and maps a buffer the CPU writes directly
+func (d *device) createHostBuffer(b *hostBuffer, size int, usage vk.BufferUsageFlagBits) error {
+	info := vk.BufferCreateInfo{
+		SType:       vk.StructureTypeBufferCreateInfo,
+		Size:        vk.DeviceSize(size),
+		Usage:       vk.BufferUsageFlags(usage),
+		SharingMode: vk.SharingModeExclusive,
+	}
+	if err := check(vk.CreateBuffer(d.logical, &info, nil, &b.buffer), "vkCreateBuffer"); err != nil {
+		return err
+	}
+
+	var reqs vk.MemoryRequirements
+	vk.GetBufferMemoryRequirements(d.logical, b.buffer, &reqs)
+	reqs.Deref()
+	memoryType, ok := d.hostMemoryType(reqs.MemoryTypeBits)
+	if !ok {
+		return fmt.Errorf("no host-visible memory for a %d-byte buffer", size)
+	}
+	allocInfo := vk.MemoryAllocateInfo{
+		SType:           vk.StructureTypeMemoryAllocateInfo,
+		AllocationSize:  reqs.Size,
+		MemoryTypeIndex: memoryType,
+	}
+	if err := check(vk.AllocateMemory(d.logical, &allocInfo, nil, &b.memory), "vkAllocateMemory"); err != nil {
+		return err
+	}
+	if err := check(vk.BindBufferMemory(d.logical, b.buffer, b.memory, 0), "vkBindBufferMemory"); err != nil {
+		return err
+	}
+	b.size = size
+	return check(vk.MapMemory(d.logical, b.memory, 0, vk.DeviceSize(size), 0, &b.mapped), "vkMapMemory")
+}
+
+// hostMemoryType finds a host-visible, coherent memory type the buffer allows
+func (d *device) hostMemoryType(allowed uint32) (uint32, bool) {
+	want := vk.MemoryPropertyFlags(vk.MemoryPropertyHostVisibleBit | vk.MemoryPropertyHostCoherentBit)
+	for i := uint32(0); i < d.memory.MemoryTypeCount; i++ {
+		memoryType := d.memory.MemoryTypes[i]
+		memoryType.Deref()
+		if allowed&(1<<i) != 0 && memoryType.PropertyFlags&want == want {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (d *device) releaseHostBuffer(b *hostBuffer) {
+	if b.mapped != nil {
+		vk.UnmapMemory(d.logical, b.memory)
+	}
+	if b.buffer != vk.NullBuffer {
+		vk.DestroyBuffer(d.logical, b.buffer, nil)
+	}
+	if b.memory != vk.NullDeviceMemory {
+		vk.FreeMemory(d.logical, b.memory, nil)
+	}
+	*b = hostBuffer{}
+}
+
+// bindBuffer points a descriptor set binding at a whole buffer
+func (d *device) bindBuffer(binding uint32, kind vk.DescriptorType, b *hostBuffer) {
+	vk.UpdateDescriptorSets(d.logical, 1, []vk.WriteDescriptorSet{{
+		SType:           vk.StructureTypeWriteDescriptorSet,
+		DstSet:          d.descriptorSet,
+		DstBinding:      binding,
+		DescriptorCount: 1,
+		DescriptorType:  kind,
+		PBufferInfo: []vk.DescriptorBufferInfo{{
+			Buffer: b.buffer,
+			Range:  vk.DeviceSize(vk.WholeSize),
+		}},
+	}}, 0, nil)
+}
+
+func (d *device) createCommands() error {
+	poolInfo := vk.CommandPoolCreateInfo{
+		SType:            vk.StructureTypeCommandPoolCreateInfo,
+		Flags:            vk.CommandPoolCreateFlags(vk.CommandPoolCreateResetCommandBufferBit),
+		QueueFamilyIndex: d.family,
+	}
+	if err := check(vk.CreateCommandPool(d.logical, &poolInfo, nil, &d.commandPool), "vkCreateCommandPool"); err != nil {
+		return err
+	}
+	buffers := make([]vk.CommandBuffer, 1)
+	allocInfo := vk.CommandBufferAllocateInfo{
+		SType:              vk.StructureTypeCommandBufferAllocateInfo,
+		CommandPool:        d.commandPool,
+		Level:              vk.CommandBufferLevelPrimary,
+		CommandBufferCount: 1,
+	}
+	if err := check(vk.AllocateCommandBuffers(d.logical, &allocInfo, buffers), "vkAllocateCommandBuffers"); err != nil {
+		return err
+	}
+	d.commands = buffers[0]
+
+	semaphoreInfo := vk.SemaphoreCreateInfo{SType: vk.StructureTypeSemaphoreCreateInfo}
+	for _, s := range []*vk.Semaphore{&d.imageReady, &d.renderDone} {
+		if err := check(vk.CreateSemaphore(d.logical, &semaphoreInfo, nil, s), "vkCreateSemaphore"); err != nil {
+			return err
+		}
+	}
+	// Signaled so the first frame doesn't wait for one that never ran
+	fenceInfo := vk.FenceCreateInfo{
+		SType: vk.StructureTypeFenceCreateInfo,
+		Flags: vk.FenceCreateFlags(vk.FenceCreateSignaledBit),
+	}
+	return check(vk.CreateFence(d.logical, &fenceInfo, nil, &d.frameFinished), "vkCreateFence")
+}
+
+// waitFrame blocks until the GPU has finished the last frame, after which
+// its buffers are free to rewrite
+func (d *device) waitFrame() error {
+	return check(vk.WaitForFences(d.logical, 1, []vk.Fence{d.frameFinished}, vk.True, vk.MaxUint64), "vkWaitForFences")
+}
+
+// allocateTexels makes room for count surface texels, replacing the texel
+// buffer only when it is too small
+func (d *device) allocateTexels(count int) error {
+	size := count * 4
+	if size <= d.texels.size {
+		return nil
+	}
+	if err := d.waitFrame(); err != nil {
+		return err
+	}
+	d.releaseHostBuffer(&d.texels)
+	if err := d.createHostBuffer(&d.texels, size, vk.BufferUsageStorageBufferBit); err != nil {
+		return err
+	}
+	d.bindBuffer(1, vk.DescriptorTypeStorageBuffer, &d.texels)
+	return nil
+}
+
+// uploadTexels copies surface texels to the GPU for the next frame
+func (d *device) uploadTexels(texels []uint32) error {
+	if len(texels) == 0 {
+		return nil
+	}
+	if err := d.allocateTexels(len(texels)); err != nil {
+		return err
+	}
+	if err := d.waitFrame(); err != nil {
+		return err
+	}
+	vk.Memcopy(d.texels.mapped, unsafe.Slice((*byte)(unsafe.Pointer(&texels[0])), len(texels)*4))
+	return nil
+}
+
+// resize rebuilds the swapchain before the next frame
+func (d *device) resize() {
+	d.stale = true
+}
+
+// rebuildSwapchain replaces the swapchain after a resize
+func (d *device) rebuildSwapchain() error {
+	if err := check(vk.DeviceWaitIdle(d.logical), "vkDeviceWaitIdle"); err != nil {
+		return err
+	}
+	d.releaseSwapchain()
+	return d.createSwapchain()
+}
+
+// draw records and submits one frame and presents it
+func (d *device) draw(camera *cameraUniforms) error {
+	if d.texels.buffer == vk.NullBuffer {
+		return nil // Nothing uploaded yet
+	}
+	if d.stale {
+		if err := d.rebuildSwapchain(); err != nil || d.stale {
+			return err // Still minimized if stale
+		}
+	}
+	if err := d.waitFrame(); err != nil {
+		return err
+	}
+
+	var image uint32
+	result := vk.AcquireNextImage(d.logical, d.swapchain, vk.MaxUint64, d.imageReady, vk.NullFence, &image)
+	if result == vk.ErrorOutOfDate {
+		d.stale = true
+		return nil
+	}
+	if result != vk.Success && result != vk.Suboptimal {
+		return check(result, "vkAcquireNextImageKHR")
+	}
+	if err := check(vk.ResetFences(d.logical, 1, []vk.Fence{d.frameFinished}), "vkResetFences"); err != nil {
+		return err
+	}
+	vk.Memcopy(d.camera.mapped, unsafe.Slice((*byte)(unsafe.Pointer(camera)), unsafe.Sizeof(*camera)))
+
+	cmd := d.commands
+	vk.ResetCommandBuffer(cmd, 0)
+	beginInfo := vk.CommandBufferBeginInfo{
+		SType: vk.StructureTypeCommandBufferBeginInfo,
+		Flags: vk.CommandBufferUsageFlags(vk.CommandBufferUsageOneTimeSubmitBit),
+	}
+	if err := check(vk.BeginCommandBuffer(cmd, &beginInfo), "vkBeginCommandBuffer"); err != nil {
+		return err
+	}
+	area := vk.Rect2D{Extent: d.extent}
+	passBegin := vk.RenderPassBeginInfo{
+		SType:           vk.StructureTypeRenderPassBeginInfo,
+		RenderPass:      d.renderPass,
+		Framebuffer:     d.framebuffers[image],
+		RenderArea:      area,
+		ClearValueCount: 1,
+		PClearValues:    []vk.ClearValue{vk.NewClearValue([]float32{0.05, 0.05, 0.1, 1.0})},
+	}
+	vk.CmdBeginRenderPass(cmd, &passBegin, vk.SubpassContentsInline)
+	vk.CmdBindPipeline(cmd, vk.PipelineBindPointGraphics, d.pipeline)
+	vk.CmdSetViewport(cmd, 0, 1, []vk.Viewport{{
+		Width:    float32(d.extent.Width),
+		Height:   float32(d.extent.Height),
+		MaxDepth: 1,
+	}})
+	vk.CmdSetScissor(cmd, 0, 1, []vk.Rect2D{area})
+	vk.CmdBindDescriptorSets(cmd, vk.PipelineBindPointGraphics, d.pipelineLayout, 0, 1,
+		[]vk.DescriptorSet{d.descriptorSet}, 0, nil)
+	vk.CmdDraw(cmd, 3, 1, 0, 0) // Fullscreen triangle, see vs_main
+	vk.CmdEndRenderPass(cmd)
+	if err := check(vk.EndCommandBuffer(cmd), "vkEndCommandBuffer"); err != nil {
+		return err
+	}
+
+	submit := vk.SubmitInfo{
+		SType:                vk.StructureTypeSubmitInfo,
+		WaitSemaphoreCount:   1,
+		PWaitSemaphores:      []vk.Semaphore{d.imageReady},
+		PWaitDstStageMask:    []vk.PipelineStageFlags{vk.PipelineStageFlags(vk.PipelineStageColorAttachmentOutputBit)},
+		CommandBufferCount:   1,
+		PCommandBuffers:      []vk.CommandBuffer{cmd},
+		SignalSemaphoreCount: 1,
+		PSignalSemaphores:    []vk.Semaphore{d.renderDone},
+	}
+	if err := check(vk.QueueSubmit(d.queue, 1, []vk.SubmitInfo{submit}, d.frameFinished), "vkQueueSubmit"); err != nil {
+		return err
+	}
+
+	present := vk.PresentInfo{
+		SType:              vk.StructureTypePresentInfo,
+		WaitSemaphoreCount: 1,
+		PWaitSemaphores:    []vk.Semaphore{d.renderDone},
+		SwapchainCount:     1,
+		PSwapchains:        []vk.Swapchain{d.swapchain},
+		PImageIndices:      []uint32{image},
+	}
+	result = vk.QueuePresent(d.queue, &present)
+	if result == vk.ErrorOutOfDate || result == vk.Suboptimal {
+		d.stale = true
+		return nil
+	}
+	return check(result, "vkQueuePresentKHR")
+}
+
+// release waits for the GPU and frees every Vulkan object, including those
+// of a device that failed partway through newDevice
+func (d *device) release() {
+	if d.logical != nil {
+		vk.DeviceWaitIdle(d.logical)
+		vk.DestroyFence(d.logical, d.frameFinished, nil)
+		vk.DestroySemaphore(d.logical, d.renderDone, nil)
+		vk.DestroySemaphore(d.logical, d.imageReady, nil)
+		vk.DestroyCommandPool(d.logical, d.commandPool, nil)
+		d.releaseHostBuffer(&d.texels)
+		d.releaseHostBuffer(&d.camera)
+		vk.DestroyDescriptorPool(d.logical, d.descriptorPool, nil)
+		vk.DestroyPipeline(d.logical, d.pipeline, nil)
+		vk.DestroyPipelineLayout(d.logical, d.pipelineLayout, nil)
+		vk.DestroyDescriptorSetLayout(d.logical, d.setLayout, nil)
+		d.releaseSwapchain()
+		vk.DestroyRenderPass(d.logical, d.renderPass, nil)
+		vk.DestroySwapchain(d.logical, d.swapchain, nil)
+		vk.DestroyDevice(d.logical, nil)
+	}
+	if d.instance != nil {
+		if d.surface != vk.NullSurface {
+			vk.DestroySurface(d.instance, d.surface, nil)
+		}
+		vk.DestroyInstance(d.instance, nil)
+	}
+	*d = device{}
+}
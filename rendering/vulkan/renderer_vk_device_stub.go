@@ -0,0 +1,19 @@
+//go:build !vulkan
+// +build !vulkan
+
+package vulkan
+
+import "github.com/go-gl/glfw/v3.3/glfw"
+
+// supported reports whether this build can talk to Vulkan
+const supported = false
+
+// device placeholder for builds without the vulkan tag
+type device struct{}
+
+func newDevice(window *glfw.Window) (*device, error) { return nil, errUnavailable }
+func (d *device) allocateTexels(count int) error     { return errUnavailable }
+func (d *device) uploadTexels(texels []uint32) error { return errUnavailable }
+func (d *device) draw(camera *cameraUniforms) error  { return errUnavailable }
+func (d *device) resize()                            {}
+func (d *device) release()                           {}
@@ -0,0 +1,12 @@
+package vulkan
+
+import _ "embed"
+
+//go:generate go run github.com/gogpu/naga/cmd/nagac@v0.19.0 -o shaders/raymarch.spv shaders/raymarch.wgsl
+
+// raymarchSPIRV is shaders/raymarch.wgsl compiled to SPIR-V, with the vertex
+// stage in vs_main and the fragment stage in fs_main. Run go generate after
+// editing the WGSL
+//
+//go:embed shaders/raymarch.spv
+var raymarchSPIRV []byte
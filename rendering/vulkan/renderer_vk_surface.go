@@ -0,0 +1,102 @@
+package vulkan
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"worldgenerator/core"
+)
+
+// surfaceGrid is the surface colored for one render mode, one packed RGBA8
+// texel per cell of an equirectangular grid. Rows are the surface shell's
+// latitude bands from south to north; every row has as many columns as the
+// widest band, so narrow bands near the poles repeat each voxel across
+// several columns
+type surfaceGrid struct {
+	Columns, Rows int
+	Texels        []uint32
+}
+
+// surfaceGridSize returns the grid dimensions for a planet's surface shell
+func surfaceGridSize(planet *core.VoxelPlanet) (columns, rows int) {
+	shell := &planet.Shells[len(planet.Shells)-2]
+	for _, count := range shell.LonCounts {
+		columns = max(columns, count)
+	}
+	return columns, shell.LatBands
+}
+
+// fillSurfaceTexels colors the grid from the planet's surface shell,
+// reusing its texels when they are already the right size. Only the
+// material and elevation modes are drawn; anything else falls back to
+// material
+func fillSurfaceTexels(grid *surfaceGrid, planet *core.VoxelPlanet, mode core.RenderMode) {
+	grid.Columns, grid.Rows = surfaceGridSize(planet)
+	if n := grid.Columns * grid.Rows; cap(grid.Texels) >= n {
+		grid.Texels = grid.Texels[:n]
+	} else {
+		grid.Texels = make([]uint32, n)
+	}
+
+	shell := &planet.Shells[len(planet.Shells)-2]
+	for row := 0; row < grid.Rows; row++ {
+		band := shell.Voxels[row]
+		for col := 0; col < grid.Columns; col++ {
+			voxel := &band[col*len(band)/grid.Columns]
+			var color core.Vector3
+			if mode == core.RenderElevation {
+				color = core.ElevationColormap.Color(float64(voxel.Elevation))
+			} else {
+				color = core.MaterialColor(voxel.SurfaceType())
+			}
+			grid.Texels[row*grid.Columns+col] = packColor(color)
+		}
+	}
+}
+
+// packColor packs a color as RGBA8 with red in the low byte, as the
+// shader's unpack4x8unorm reads it
+func packColor(c core.Vector3) uint32 {
+	channel := func(v float64) uint32 {
+		return uint32(math.Max(0, math.Min(1, v))*255 + 0.5)
+	}
+	return channel(c.X) | channel(c.Y)<<8 | channel(c.Z)<<16 | 255<<24
+}
+
+// cameraUniforms is the shader's Camera uniform block. Positions are in
+// planet radii so the ray cast keeps its precision at any planet size
+type cameraUniforms struct {
+	InvViewProj mgl32.Mat4 // Vulkan clip space back to world space
+	Position    mgl32.Vec4 // Camera position, w unused
+	Grid        mgl32.Vec4 // Surface grid columns and rows, zw unused
+}
+
+// vulkanClip converts OpenGL clip space to Vulkan's, where y points down
+// the screen and depth runs from 0 to 1
+var vulkanClip = mgl32.Mat4{
+	1, 0, 0, 0,
+	0, -1, 0, 0,
+	0, 0, 0.5, 0,
+	0, 0, 0.5, 1,
+}
+
+// newCameraUniforms places the camera distance planet radii from the center
+// at the given orbit angles in radians, looking at the planet with a
+// vertical field of view in degrees
+func newCameraUniforms(rotationX, rotationY, distance, fieldOfView, aspect float32, grid *surfaceGrid) cameraUniforms {
+	cosY := float32(math.Cos(float64(rotationY)))
+	position := mgl32.Vec3{
+		distance * cosY * float32(math.Cos(float64(rotationX))),
+		distance * float32(math.Sin(float64(rotationY))),
+		distance * cosY * float32(math.Sin(float64(rotationX))),
+	}
+	view := mgl32.LookAtV(position, mgl32.Vec3{}, mgl32.Vec3{0, 1, 0})
+	near := float32(math.Max(1e-3, float64(distance-1)*0.5))
+	proj := mgl32.Perspective(mgl32.DegToRad(fieldOfView), aspect, near, distance+2)
+	return cameraUniforms{
+		InvViewProj: vulkanClip.Mul4(proj).Mul4(view).Inv(),
+		Position:    position.Vec4(1),
+		Grid:        mgl32.Vec4{float32(grid.Columns), float32(grid.Rows)},
+	}
+}
@@ -0,0 +1,116 @@
+package vulkan
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/go-gl/mathgl/mgl32"
+
+	"worldgenerator/core"
+)
+
+// TestFillSurfaceTexels checks every grid cell takes the color of the
+// surface voxel it lies in, across the 180° seam and in the narrow polar bands
+func TestFillSurfaceTexels(t *testing.T) {
+	planet := core.CreateVoxelPlanetWithResolution(6371000.0, 4, 24)
+	surface := len(planet.Shells) - 2
+	shell := &planet.Shells[surface]
+	for latIdx := range shell.Voxels {
+		for lonIdx := range shell.Voxels[latIdx] {
+			voxel := &shell.Voxels[latIdx][lonIdx]
+			voxel.Type, voxel.Elevation = core.MatGranite, 800
+			if lonIdx == 0 {
+				voxel.Type, voxel.Elevation = core.MatWater, -3000 // Just east of 180°W
+			}
+		}
+	}
+
+	var grid surfaceGrid
+	fillSurfaceTexels(&grid, planet, core.RenderMaterial)
+	if grid.Rows != shell.LatBands || len(grid.Texels) != grid.Columns*grid.Rows {
+		t.Fatalf("%dx%d grid with %d texels for %d bands", grid.Columns, grid.Rows, len(grid.Texels), shell.LatBands)
+	}
+	water, granite := packColor(core.MaterialColor(core.MatWater)), packColor(core.MaterialColor(core.MatGranite))
+	for row := 0; row < grid.Rows; row++ {
+		lonCount := shell.LonCounts[row]
+		for col := 0; col < grid.Columns; col++ {
+			// The column's western edge lies in the first voxel's longitudes
+			want := granite
+			if float64(col)/float64(grid.Columns) < 1/float64(lonCount) {
+				want = water
+			}
+			if got := grid.Texels[row*grid.Columns+col]; got != want {
+				t.Fatalf("band %d (%d voxels) column %d: %08x, want %08x", row, lonCount, col, got, want)
+			}
+		}
+	}
+
+	// Elevation reuses the same texels
+	texels := &grid.Texels[0]
+	fillSurfaceTexels(&grid, planet, core.RenderElevation)
+	if &grid.Texels[0] != texels {
+		t.Error("texels reallocated for a grid of the same size")
+	}
+	equator := grid.Rows / 2 * grid.Columns
+	if got, want := grid.Texels[equator+grid.Columns-1], packColor(core.ElevationColormap.Color(800)); got != want {
+		t.Errorf("land at 180°E colored %08x, want %08x", got, want)
+	}
+	if got, want := grid.Texels[equator], packColor(core.ElevationColormap.Color(-3000)); got != want {
+		t.Errorf("sea at 180°W colored %08x, want %08x", got, want)
+	}
+}
+
+// TestCameraUniforms follows the shader's rays from the camera's uniforms:
+// the center of the screen looks at the point facing the camera and the top
+// of the screen, Vulkan's -y, is north
+func TestCameraUniforms(t *testing.T) {
+	grid := surfaceGrid{Columns: 48, Rows: 24}
+	camera := newCameraUniforms(0, 0, defaultCameraDistance, defaultFieldOfView, 4.0/3.0, &grid)
+	if camera.Grid.X() != 48 || camera.Grid.Y() != 24 {
+		t.Errorf("grid uniform %v, want 48x24", camera.Grid)
+	}
+
+	// hit returns where the ray through a point in Vulkan clip space meets the
+	// unit sphere, as fs_main finds it
+	hit := func(x, y float32) (mgl32.Vec3, bool) {
+		near := camera.InvViewProj.Mul4x1(mgl32.Vec4{x, y, 0, 1})
+		far := camera.InvViewProj.Mul4x1(mgl32.Vec4{x, y, 1, 1})
+		origin := near.Vec3().Mul(1 / near.W())
+		dir := far.Vec3().Mul(1 / far.W()).Sub(origin).Normalize()
+		b := origin.Dot(dir)
+		disc := b*b - (origin.Dot(origin) - 1)
+		if disc < 0 {
+			return mgl32.Vec3{}, false
+		}
+		return origin.Add(dir.Mul(-b - float32(math.Sqrt(float64(disc))))), true
+	}
+
+	center, ok := hit(0, 0)
+	if !ok || !center.ApproxEqualThreshold(mgl32.Vec3{1, 0, 0}, 1e-3) {
+		t.Errorf("center of the screen hit %v (%v), want the point facing the camera", center, ok)
+	}
+	if top, ok := hit(0, -0.5); !ok || top.Y() <= 0 {
+		t.Errorf("upper half of the screen hit %v (%v), want the northern hemisphere", top, ok)
+	}
+	if _, ok := hit(0.99, 0.99); ok {
+		t.Error("corner of the screen hit the planet from three radii away")
+	}
+}
+
+// TestRaymarchSPIRV checks the embedded shader is SPIR-V with both entry
+// points the pipeline names
+func TestRaymarchSPIRV(t *testing.T) {
+	if len(raymarchSPIRV) < 20 || len(raymarchSPIRV)%4 != 0 {
+		t.Fatalf("shader is %d bytes, want whole words", len(raymarchSPIRV))
+	}
+	if magic := binary.LittleEndian.Uint32(raymarchSPIRV); magic != 0x07230203 {
+		t.Fatalf("magic number %08x, want SPIR-V's 07230203", magic)
+	}
+	for _, entry := range []string{"vs_main", "fs_main"} {
+		if !bytes.Contains(raymarchSPIRV, []byte(entry+"\x00")) {
+			t.Errorf("no %s entry point", entry)
+		}
+	}
+}
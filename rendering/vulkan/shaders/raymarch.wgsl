@@ -0,0 +1,70 @@
+// Ray casts the planet surface for the Vulkan renderer. The surface colors
+// are worked out on the CPU (see surfaceTexels), one packed RGBA8 texel per
+// cell of an equirectangular grid, so the shader only finds where each pixel's
+// ray meets the sphere and looks the color up there.
+//
+// Compiled to raymarch.spv with go generate; keep the Camera layout in sync
+// with cameraUniforms.
+
+struct Camera {
+    inv_view_proj: mat4x4<f32>, // Vulkan clip space back to world space
+    position: vec4<f32>,        // Camera in planet radii, w unused
+    grid: vec4<f32>,            // Texel columns and rows, zw unused
+}
+
+@group(0) @binding(0) var<uniform> camera: Camera;
+@group(0) @binding(1) var<storage, read> texels: array<u32>;
+
+const PI: f32 = 3.14159265;
+const BACKGROUND: vec3<f32> = vec3<f32>(0.05, 0.05, 0.1);
+
+struct VertexOutput {
+    @builtin(position) position: vec4<f32>,
+    @location(0) ndc: vec2<f32>,
+}
+
+// vs_main draws one triangle covering the screen, with no vertex buffer
+@vertex
+fn vs_main(@builtin(vertex_index) index: u32) -> VertexOutput {
+    let uv = vec2<f32>(f32((index << 1u) & 2u), f32(index & 2u));
+    let ndc = uv * 2.0 - 1.0;
+    var out: VertexOutput;
+    out.position = vec4<f32>(ndc, 0.0, 1.0);
+    out.ndc = ndc;
+    return out;
+}
+
+@fragment
+fn fs_main(in: VertexOutput) -> @location(0) vec4<f32> {
+    let near = camera.inv_view_proj * vec4<f32>(in.ndc, 0.0, 1.0);
+    let far = camera.inv_view_proj * vec4<f32>(in.ndc, 1.0, 1.0);
+    let origin = near.xyz / near.w;
+    let dir = normalize(far.xyz / far.w - origin);
+
+    // Nearest intersection with the unit sphere
+    let b = dot(origin, dir);
+    let c = dot(origin, origin) - 1.0;
+    let disc = b * b - c;
+    if (disc < 0.0) {
+        return vec4<f32>(BACKGROUND, 1.0);
+    }
+    let t = -b - sqrt(disc);
+    if (t < 0.0) {
+        return vec4<f32>(BACKGROUND, 1.0);
+    }
+    let normal = normalize(origin + dir * t);
+
+    // Bands run pole to pole with the first and last centered on the poles,
+    // longitude cells start at 180°W
+    let lat = asin(clamp(normal.y, -1.0, 1.0));
+    let lon = atan2(normal.z, normal.x);
+    let columns = u32(camera.grid.x);
+    let rows = u32(camera.grid.y);
+    let row = u32(clamp(round((lat / PI + 0.5) * (camera.grid.y - 1.0)), 0.0, camera.grid.y - 1.0));
+    let column = min(u32((lon / (2.0 * PI) + 0.5) * camera.grid.x), columns - 1u);
+    let color = unpack4x8unorm(texels[min(row, rows - 1u) * columns + column]).rgb;
+
+    // Lit from the camera so the globe reads as round
+    let light = 0.35 + 0.65 * max(dot(normal, -dir), 0.0);
+    return vec4<f32>(color * light, 1.0);
+}
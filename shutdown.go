@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+)
+
+// shutdownSignal turns SIGINT/SIGTERM into a request the main loop checks
+// alongside the window's close button, so Ctrl-C takes the same exit path:
+// the physics thread is stopped and exports are written before main returns
+type shutdownSignal struct {
+	requested atomic.Bool
+}
+
+// watchShutdownSignals starts listening for SIGINT and SIGTERM
+// A second signal while shutting down exits immediately
+func watchShutdownSignals() *shutdownSignal {
+	s := &shutdownSignal{}
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		sig := <-signals
+		fmt.Printf("\n⚠️  Received %v, finishing the current step and shutting down (repeat to force)\n", sig)
+		s.requested.Store(true)
+
+		<-signals
+		fmt.Println("\n❌ Forced exit")
+		os.Exit(1)
+	}()
+
+	return s
+}
+
+// Requested reports whether a shutdown signal has arrived
+func (s *shutdownSignal) Requested() bool {
+	return s.requested.Load()
+}
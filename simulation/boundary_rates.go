@@ -0,0 +1,184 @@
+package simulation
+
+import (
+	"math"
+
+	"worldgenerator/core"
+)
+
+// boundaryNormalRings is how many rings of neighbors are averaged to find the
+// boundary normal, smoothing the staircase of the voxel grid
+const boundaryNormalRings = 2
+
+// BoundarySegment is the relative plate motion across one voxel edge
+type BoundarySegment struct {
+	PlateA         int               `json:"plateA"`
+	PlateB         int               `json:"plateB"` // Always greater than PlateA
+	Lat            float64           `json:"lat"`    // Edge midpoint in degrees
+	Lon            float64           `json:"lon"`
+	NormalEast     float64           `json:"normalEast"`     // Unit boundary normal from PlateA into PlateB,
+	NormalNorth    float64           `json:"normalNorth"`    // as local east and north components
+	NormalRate     float64           `json:"normalRate"`     // cm/yr, positive = divergent (opening), negative = convergent
+	TangentialRate float64           `json:"tangentialRate"` // cm/yr, positive when PlateB slides toward the normal rotated 90° counterclockwise
+	Type           PlateBoundaryType `json:"type"`           // Dominant motion
+}
+
+// String names a boundary type
+func (t PlateBoundaryType) String() string {
+	switch t {
+	case BoundaryDivergent:
+		return "divergent"
+	case BoundaryConvergent:
+		return "convergent"
+	case BoundaryTransform:
+		return "transform"
+	}
+	return "none"
+}
+
+// MarshalText writes boundary types by name in JSON
+func (t PlateBoundaryType) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// BoundaryRates returns one segment for every pair of adjacent surface voxels
+// on different plates, with PlateB's velocity relative to PlateA decomposed
+// into the component across the boundary and the component along it
+// The boundary normal comes from the plates' footprints around the edge rather
+// than the single voxel step, so diagonal boundaries get diagonal normals
+func (pm *PlateManager) BoundaryRates() []BoundarySegment {
+	surface := len(pm.planet.Shells) - 2
+	if surface < 0 {
+		return nil
+	}
+	shell := &pm.planet.Shells[surface]
+
+	var segments []BoundarySegment
+	for latIdx := range shell.Voxels {
+		for lonIdx := range shell.Voxels[latIdx] {
+			a := core.VoxelCoord{Shell: surface, Lat: latIdx, Lon: lonIdx}
+			plateA, ok := pm.VoxelPlateMap[a]
+			if !ok {
+				continue
+			}
+			for _, b := range core.ShellNeighbors(shell, a) {
+				// Each edge is reported once, from the lower plate ID
+				if plateB, ok := pm.VoxelPlateMap[b]; ok && plateA < plateB {
+					segments = append(segments, pm.boundarySegment(shell, a, b, plateA, plateB))
+				}
+			}
+		}
+	}
+
+	return segments
+}
+
+// boundarySegment measures the relative motion across the edge between a and b
+func (pm *PlateManager) boundarySegment(shell *core.SphericalShell, a, b core.VoxelCoord, plateA, plateB int) BoundarySegment {
+	posA := pm.unitPosition(a)
+	posB := pm.unitPosition(b)
+	mid := posA.Add(posB).Normalize()
+
+	// Normal from the centroid of PlateA's nearby voxels to PlateB's
+	var centroidA, centroidB core.Vector3
+	for _, coord := range nearbyVoxels(shell, a, b) {
+		switch pm.VoxelPlateMap[coord] {
+		case plateA:
+			centroidA = centroidA.Add(pm.unitPosition(coord))
+		case plateB:
+			centroidB = centroidB.Add(pm.unitPosition(coord))
+		}
+	}
+	normal := tangentAt(mid, centroidB.Normalize().Sub(centroidA.Normalize()))
+	if normal.Length() == 0 {
+		normal = tangentAt(mid, posB.Sub(posA))
+	}
+	normal = normal.Normalize()
+	along := mid.Cross(normal)
+
+	// PlateB's velocity relative to PlateA
+	relative := pm.velocity3D(b).Sub(pm.velocity3D(a))
+	segment := BoundarySegment{
+		PlateA:         plateA,
+		PlateB:         plateB,
+		NormalRate:     core.CmPerYear(relative.Dot(normal)),
+		TangentialRate: core.CmPerYear(relative.Dot(along)),
+	}
+
+	lat := math.Asin(math.Max(-1, math.Min(1, mid.Z)))
+	lon := math.Atan2(mid.Y, mid.X)
+	east, north := localFrame(lat, lon)
+	segment.Lat = lat * 180 / math.Pi
+	segment.Lon = lon * 180 / math.Pi
+	segment.NormalEast = normal.Dot(east)
+	segment.NormalNorth = normal.Dot(north)
+
+	switch {
+	case math.Abs(segment.TangentialRate) > math.Abs(segment.NormalRate):
+		segment.Type = BoundaryTransform
+	case segment.NormalRate > 0:
+		segment.Type = BoundaryDivergent
+	case segment.NormalRate < 0:
+		segment.Type = BoundaryConvergent
+	default:
+		segment.Type = BoundaryNone
+	}
+
+	return segment
+}
+
+// nearbyVoxels returns the voxels within boundaryNormalRings of a or b
+func nearbyVoxels(shell *core.SphericalShell, a, b core.VoxelCoord) []core.VoxelCoord {
+	seen := map[core.VoxelCoord]bool{a: true, b: true}
+	ring := []core.VoxelCoord{a, b}
+	all := []core.VoxelCoord{a, b}
+	for i := 0; i < boundaryNormalRings; i++ {
+		var next []core.VoxelCoord
+		for _, coord := range ring {
+			for _, n := range core.ShellNeighbors(shell, coord) {
+				if !seen[n] {
+					seen[n] = true
+					next = append(next, n)
+				}
+			}
+		}
+		all = append(all, next...)
+		ring = next
+	}
+	return all
+}
+
+// unitPosition returns a surface voxel's center on the unit sphere, Z toward the north pole
+func (pm *PlateManager) unitPosition(coord core.VoxelCoord) core.Vector3 {
+	lat, lon := pm.planet.VoxelLatLon(coord)
+	latRad, lonRad := lat*math.Pi/180, lon*math.Pi/180
+	return core.Vector3{
+		X: math.Cos(latRad) * math.Cos(lonRad),
+		Y: math.Cos(latRad) * math.Sin(lonRad),
+		Z: math.Sin(latRad),
+	}
+}
+
+// velocity3D returns a voxel's horizontal velocity (m/year) in the unitPosition frame
+func (pm *PlateManager) velocity3D(coord core.VoxelCoord) core.Vector3 {
+	voxel := &pm.planet.Shells[coord.Shell].Voxels[coord.Lat][coord.Lon]
+	lat, lon := pm.planet.VoxelLatLon(coord)
+	east, north := localFrame(lat*math.Pi/180, lon*math.Pi/180)
+	return east.Scale(float64(voxel.VelEast)).Add(north.Scale(float64(voxel.VelNorth)))
+}
+
+// localFrame returns the unit east and north vectors at a latitude and longitude in radians
+func localFrame(lat, lon float64) (east, north core.Vector3) {
+	east = core.Vector3{X: -math.Sin(lon), Y: math.Cos(lon)}
+	north = core.Vector3{
+		X: -math.Sin(lat) * math.Cos(lon),
+		Y: -math.Sin(lat) * math.Sin(lon),
+		Z: math.Cos(lat),
+	}
+	return east, north
+}
+
+// tangentAt removes the component of v along the surface normal up
+func tangentAt(up, v core.Vector3) core.Vector3 {
+	return v.Sub(up.Scale(v.Dot(up)))
+}
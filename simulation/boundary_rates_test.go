@@ -0,0 +1,91 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// addMovingPlates splits a 20°-wide equatorial strip at the prime meridian
+// into a western and an eastern plate with the given velocities in cm/yr
+func addMovingPlates(pm *PlateManager, shell *core.SphericalShell, surface int, westEast, westNorth, eastEast, eastNorth float64) (*TectonicPlate, *TectonicPlate) {
+	west, east := pm.newPlate(), pm.newPlate()
+	var westCoords, eastCoords []core.VoxelCoord
+
+	for lat := shell.LatBands/2 - 3; lat < shell.LatBands/2+3; lat++ {
+		for lon := range shell.Voxels[lat] {
+			coord := core.VoxelCoord{Shell: surface, Lat: lat, Lon: lon}
+			_, lonDeg := pm.planet.VoxelLatLon(coord)
+			if math.Abs(lonDeg) > 10 {
+				continue
+			}
+
+			voxel := &shell.Voxels[lat][lon]
+			voxel.Type = core.MatBasalt
+			voxel.IsBrittle = true
+			if lonDeg < 0 {
+				voxel.VelEast = float32(westEast / core.CmPerYear(1))
+				voxel.VelNorth = float32(westNorth / core.CmPerYear(1))
+				westCoords = append(westCoords, coord)
+			} else {
+				voxel.VelEast = float32(eastEast / core.CmPerYear(1))
+				voxel.VelNorth = float32(eastNorth / core.CmPerYear(1))
+				eastCoords = append(eastCoords, coord)
+			}
+		}
+	}
+
+	pm.assignMembers(west, westCoords)
+	pm.assignMembers(east, eastCoords)
+	pm.Plates = []*TectonicPlate{west, east}
+	return west, east
+}
+
+// TestBoundaryRatesSpreading checks a ridge between plates moving apart at
+// 2.5 cm/yr each reports a 5 cm/yr spreading rate across an east-facing normal
+func TestBoundaryRatesSpreading(t *testing.T) {
+	pm, shell, surface := newOceanPlateManager()
+	west, east := addMovingPlates(pm, shell, surface, -2.5, 0, 2.5, 0)
+
+	segments := pm.BoundaryRates()
+	if len(segments) == 0 {
+		t.Fatal("no boundary segments between adjacent plates")
+	}
+	for _, seg := range segments {
+		if seg.PlateA != west.ID || seg.PlateB != east.ID {
+			t.Fatalf("segment between plates %d and %d, want %d and %d", seg.PlateA, seg.PlateB, west.ID, east.ID)
+		}
+		if math.Abs(seg.NormalRate-5) > 0.05 {
+			t.Errorf("segment at %.2f°,%.2f°: spreading %.3f cm/yr, want 5", seg.Lat, seg.Lon, seg.NormalRate)
+		}
+		if math.Abs(seg.TangentialRate) > 0.05 {
+			t.Errorf("segment at %.2f°,%.2f°: %.3f cm/yr along a pure ridge", seg.Lat, seg.Lon, seg.TangentialRate)
+		}
+		if seg.NormalEast < 0.99 {
+			t.Errorf("segment at %.2f°,%.2f°: normal (%.2f east, %.2f north), want due east",
+				seg.Lat, seg.Lon, seg.NormalEast, seg.NormalNorth)
+		}
+		if seg.Type != BoundaryDivergent {
+			t.Errorf("segment at %.2f°,%.2f°: type %d, want divergent", seg.Lat, seg.Lon, seg.Type)
+		}
+	}
+
+	// Reversed motion converges, sliding north and south is a transform
+	pm, shell, surface = newOceanPlateManager()
+	addMovingPlates(pm, shell, surface, 3, 0, -1, 0)
+	for _, seg := range pm.BoundaryRates() {
+		if math.Abs(seg.NormalRate+4) > 0.05 || seg.Type != BoundaryConvergent {
+			t.Fatalf("converging at %.3f cm/yr (type %d), want -4 and convergent", seg.NormalRate, seg.Type)
+		}
+	}
+
+	pm, shell, surface = newOceanPlateManager()
+	addMovingPlates(pm, shell, surface, 0, -1, 0, 2)
+	for _, seg := range pm.BoundaryRates() {
+		if math.Abs(seg.TangentialRate-3) > 0.05 || math.Abs(seg.NormalRate) > 0.05 || seg.Type != BoundaryTransform {
+			t.Fatalf("transform with %.3f cm/yr along and %.3f across (type %d), want 3 and 0",
+				seg.TangentialRate, seg.NormalRate, seg.Type)
+		}
+	}
+}
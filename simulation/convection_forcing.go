@@ -0,0 +1,103 @@
+package simulation
+
+import (
+	"math"
+
+	"worldgenerator/core"
+)
+
+// forcingCellAnomaly is the temperature contrast (K) of the baseline mantle
+// cell forcing adds under the plates: a degree-2 pattern rising beneath the
+// equator at 0° and 180° longitude and sinking along the 90° meridians, so
+// forced plates have a flow to follow even over a uniform mantle
+const forcingCellAnomaly = 100.0
+
+// applyConvectionForcing keeps a plate moving at least at the planet's
+// ConvectionForcing speed, as if carried by a baseline mantle flow
+// Plate forces alone often stay too weak to move anything visibly, because
+// convection only starts above a Rayleigh threshold initial conditions may
+// not reach. A forced plate turns about the pole its convection torque sets,
+// carrying it off hot mantle toward cold: the baseline cell alone moves it at
+// ConvectionForcing, and real convection pushing harder speeds it up
+func (pm *PlateManager) applyConvectionForcing(plate *TectonicPlate) {
+	if pm.planet.ConvectionForcing <= 0 || len(plate.MemberVoxels) == 0 {
+		return
+	}
+
+	// Never past the speed limit, even when forced harder
+	minAngularVel := math.Min(pm.planet.ConvectionForcing/100.0/pm.planet.Radius, pm.maxAngularVelocity()) // cm/year to radians/year
+	if math.Abs(plate.AngularVelocity) >= minAngularVel {
+		return
+	}
+
+	torque, cellTorque := pm.convectionTorque(plate)
+	if torque.Length() < 1e-12 {
+		return // Balanced mantle under the plate, no direction to push
+	}
+
+	angularVel := minAngularVel
+	if cell := cellTorque.Length(); cell > 1e-12 {
+		angularVel = math.Min(math.Max(minAngularVel, minAngularVel*torque.Length()/cell), pm.maxAngularVelocity())
+	}
+
+	pole := torque.Normalize()
+	plate.EulerPoleLat = math.Asin(math.Max(-1, math.Min(1, pole.Z))) * 180.0 / math.Pi
+	plate.EulerPoleLon = math.Atan2(pole.Y, pole.X) * 180.0 / math.Pi
+	plate.AngularVelocity = angularVel
+}
+
+// convectionTorque returns the rotation axis, scaled by strength, the mantle
+// under a plate drives it about, and the part of it from the baseline cell
+// alone. Each voxel is pushed by the temperature anomaly of the mantle
+// beneath it; with d the anomaly-weighted sum of voxel positions and c the
+// plate center, turning about d × c moves the plate from its hot side toward
+// its cold side. Positions are in the frame applyPlateMotion uses
+func (pm *PlateManager) convectionTorque(plate *TectonicPlate) (torque, cellTorque core.Vector3) {
+	positions := make([]core.Vector3, len(plate.MemberVoxels))
+	anomalies := make([]float64, len(plate.MemberVoxels))
+	var center core.Vector3
+	mean := 0.0
+	for i, coord := range plate.MemberVoxels {
+		shell := &pm.planet.Shells[coord.Shell]
+		lat := core.GetLatitudeForBand(coord.Lat, shell.LatBands) * math.Pi / 180.0
+		lon := float64(coord.Lon) * 2.0 * math.Pi / float64(len(shell.Voxels[coord.Lat]))
+		positions[i] = core.Vector3{
+			X: math.Cos(lat) * math.Cos(lon),
+			Y: math.Cos(lat) * math.Sin(lon),
+			Z: math.Sin(lat),
+		}
+		center = center.Add(positions[i])
+		anomalies[i] = pm.mantleTemperatureBelow(coord)
+		mean += anomalies[i]
+	}
+
+	// A plate wrapping the whole globe has no side to push from
+	if center.Length() < 1e-9 {
+		return core.Vector3{}, core.Vector3{}
+	}
+	center = center.Normalize()
+	mean /= float64(len(positions))
+
+	var hot, cell core.Vector3
+	for i, r := range positions {
+		baseline := forcingCellAnomaly * (r.X*r.X - 1.0/3.0)
+		hot = hot.Add(r.Scale(anomalies[i] - mean + baseline))
+		cell = cell.Add(r.Scale(baseline))
+	}
+	return hot.Cross(center), cell.Cross(center)
+}
+
+// mantleTemperatureBelow returns the temperature of the voxel in the shell
+// under a plate voxel, or 0 for the innermost shell
+func (pm *PlateManager) mantleTemperatureBelow(coord core.VoxelCoord) float64 {
+	if coord.Shell == 0 {
+		return 0
+	}
+	lat, lon := pm.planet.VoxelLatLon(coord)
+	below := &pm.planet.Shells[coord.Shell-1]
+	voxel, _ := pm.planet.VoxelAtGeographic(lat, lon, pm.planet.ShellAltitude((below.InnerRadius+below.OuterRadius)/2, lat))
+	if voxel == nil {
+		return 0
+	}
+	return float64(voxel.Temperature)
+}
@@ -0,0 +1,178 @@
+package simulation
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"worldgenerator/core"
+)
+
+const (
+	// plateHueStep spreads the hues of unrelated plates around the color
+	// wheel by the golden angle, so neighbors rarely look alike
+	plateHueStep = 137.5
+
+	// splitHueStep is how far in degrees each fragment's hue sits from the
+	// plate it rifted off: related, but still told apart
+	splitHueStep = 25.0
+
+	// Saturation and value plate view draws every hue with
+	plateSaturation = 0.7
+	plateValue      = 0.8
+)
+
+// PlateColorRegistry remembers the display hue of every plate lineage. A
+// plate keeps its color as it moves, welds and is re-identified, fragments
+// rifted off it take a hue near its own, and an ID reused by an unrelated
+// plate starts a new color. Hues live in the planet's PlateHues, so they are
+// saved and loaded with it. It is safe to read from the render thread
+type PlateColorRegistry struct {
+	mu   sync.RWMutex
+	hues map[int32]float32 // Degrees, by plate ID
+}
+
+// newPlateColorRegistry keeps its hues in planet.PlateHues, picking up any a
+// loaded planet brought with it
+func newPlateColorRegistry(planet *core.VoxelPlanet) *PlateColorRegistry {
+	if planet.PlateHues == nil {
+		planet.PlateHues = make(map[int32]float32)
+	}
+	return &PlateColorRegistry{hues: planet.PlateHues}
+}
+
+// freshHue is the hue of a plate with no ancestry
+func freshHue(id int) float64 {
+	return math.Mod(float64(id)*plateHueStep, 360)
+}
+
+// relatedHue returns the hue of the nth plate descended from a plate of
+// hue: the first keeps it, the rest alternate either side in growing steps
+func relatedHue(hue float64, n int) float64 {
+	offset := float64((n+1)/2) * splitHueStep
+	if n%2 == 0 {
+		offset = -offset
+	}
+	return math.Mod(hue+offset+360, 360)
+}
+
+// Hue returns a plate's hue in degrees, false when the plate has none
+func (c *PlateColorRegistry) Hue(id int) (float64, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	hue, ok := c.hues[int32(id)]
+	return float64(hue), ok
+}
+
+// Color returns a plate's RGB color in plate view. Plates the registry
+// doesn't know get the hue of a plate with no ancestry
+func (c *PlateColorRegistry) Color(id int) [3]float32 {
+	hue, ok := c.Hue(id)
+	if !ok {
+		hue = freshHue(id)
+	}
+	return hsvToRGB(hue, plateSaturation, plateValue)
+}
+
+// HueTable returns every hue indexed by plate ID, -1 for IDs with none, for
+// upload to the renderer
+func (c *PlateColorRegistry) HueTable() []float32 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	maxID := int32(-1)
+	for id := range c.hues {
+		if id > maxID {
+			maxID = id
+		}
+	}
+	table := make([]float32, maxID+1)
+	for i := range table {
+		table[i] = -1
+	}
+	for id, hue := range c.hues {
+		if id >= 0 {
+			table[id] = hue
+		}
+	}
+	return table
+}
+
+// assign gives a new plate with no ancestry its own hue
+func (c *PlateColorRegistry) assign(id int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.hues[int32(id)] = float32(freshHue(id))
+}
+
+// split gives the nth fragment (from 1) rifted off parent a hue near the
+// parent's
+func (c *PlateColorRegistry) split(parent, fragment, n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	hue, ok := c.hues[int32(parent)]
+	if !ok {
+		hue = float32(freshHue(parent))
+	}
+	c.hues[int32(fragment)] = float32(relatedHue(float64(hue), n))
+}
+
+// release forgets a destroyed plate's hue, so its ID starts a new lineage
+func (c *PlateColorRegistry) release(id int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.hues, int32(id))
+}
+
+// snapshot copies the hues, for relabeling after plates are re-identified
+func (c *PlateColorRegistry) snapshot() map[int32]float32 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	hues := make(map[int32]float32, len(c.hues))
+	for id, hue := range c.hues {
+		hues[id] = hue
+	}
+	return hues
+}
+
+// inherit recolors freshly identified plates from the hues plates had
+// before, keyed by each plate's ancestor: the plate most of its voxels
+// belonged to. The largest descendant of a lineage keeps its hue and the
+// others take related ones, as if they had rifted off it
+func (c *PlateColorRegistry) inherit(plates []*TectonicPlate, ancestors map[int]int32, previous map[int32]float32) {
+	order := append([]*TectonicPlate(nil), plates...)
+	sort.SliceStable(order, func(i, j int) bool {
+		if len(order[i].MemberVoxels) != len(order[j].MemberVoxels) {
+			return len(order[i].MemberVoxels) > len(order[j].MemberVoxels)
+		}
+		return order[i].ID < order[j].ID
+	})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id := range c.hues {
+		delete(c.hues, id)
+	}
+	descendants := make(map[int32]int)
+	for _, plate := range order {
+		ancestor := ancestors[plate.ID]
+		hue, ok := previous[ancestor]
+		if ancestor == 0 || !ok {
+			c.hues[int32(plate.ID)] = float32(freshHue(plate.ID))
+			continue
+		}
+		c.hues[int32(plate.ID)] = float32(relatedHue(float64(hue), descendants[ancestor]))
+		descendants[ancestor]++
+	}
+}
+
+// hsvToRGB converts a hue in degrees, saturation and value to RGB, as the
+// ray march shader does
+func hsvToRGB(hue, saturation, value float64) [3]float32 {
+	var rgb [3]float32
+	for i, k := range [3]float64{1, 2.0 / 3, 1.0 / 3} {
+		p := math.Abs(math.Mod(hue/360+k, 1)*6 - 3)
+		channel := math.Max(0, math.Min(1, p-1))
+		rgb[i] = float32(value * (1 + saturation*(channel-1)))
+	}
+	return rgb
+}
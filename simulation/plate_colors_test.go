@@ -0,0 +1,92 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// hueDistance is how far apart two hues are around the color wheel
+func hueDistance(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 360)
+	return math.Min(d, 360-d)
+}
+
+// TestPlateColorsFollowLineage checks a rifted fragment takes a hue near its
+// parent's, a destroyed plate's reused ID doesn't bring its color back, and
+// re-identifying the plates keeps each one's color
+func TestPlateColorsFollowLineage(t *testing.T) {
+	pm, shell, surface := newOceanPlateManager()
+	for lat := range shell.Voxels {
+		for lon := range shell.Voxels[lat] {
+			shell.Voxels[lat][lon].IsBrittle = true
+		}
+	}
+
+	// One plate rifts in two, leaving the fragment near the parent's color
+	plate := pm.newPlate()
+	addBlock(pm, shell, surface, plate, 100, 240)
+	addBlock(pm, shell, surface, plate, 250, 370)
+	pm.Plates = []*TectonicPlate{plate}
+	parentHue, _ := pm.Colors.Hue(plate.ID)
+	if created := pm.SplitDisconnectedPlates(); created != 1 {
+		t.Fatalf("created %d plates, want 1", created)
+	}
+	fragment := pm.Plates[1]
+	fragmentHue, ok := pm.Colors.Hue(fragment.ID)
+	if d := hueDistance(fragmentHue, parentHue); !ok || d == 0 || d > splitHueStep {
+		t.Errorf("fragment hue %.1f°, parent %.1f°, want within %g° but distinct", fragmentHue, parentHue, splitHueStep)
+	}
+	if hue, _ := pm.Colors.Hue(plate.ID); hue != parentHue {
+		t.Errorf("parent hue changed from %.1f° to %.1f° in the split", parentHue, hue)
+	}
+
+	// Re-identification hands out new IDs, but each plate keeps its color
+	pm.IdentifyPlates()
+	if len(pm.Plates) != 2 {
+		t.Fatalf("re-identified %d plates, want 2", len(pm.Plates))
+	}
+	for _, p := range pm.Plates {
+		want := parentHue
+		if len(p.MemberVoxels) < 140 {
+			want = fragmentHue
+		}
+		if hue, _ := pm.Colors.Hue(p.ID); hue != want {
+			t.Errorf("re-identified plate %d of %d voxels has hue %.1f°, want %.1f°", p.ID, len(p.MemberVoxels), hue, want)
+		}
+	}
+
+	// A destroyed plate's ID comes back with a fresh color, not the old one
+	gone := pm.Plates[1]
+	goneID := gone.ID
+	pm.Colors.split(pm.Plates[0].ID, goneID, 3) // A color freshHue wouldn't give
+	pm.discardPlate(gone)
+	reused := pm.newPlate()
+	if reused.ID != goneID {
+		t.Fatalf("new plate took ID %d, want the freed %d", reused.ID, goneID)
+	}
+	if hue, _ := pm.Colors.Hue(reused.ID); hue != freshHue(reused.ID) {
+		t.Errorf("reused ID %d has hue %.1f°, want the fresh %.1f°", reused.ID, hue, freshHue(reused.ID))
+	}
+
+	// The hues live in the planet, for saving
+	if len(pm.planet.PlateHues) != 2 || pm.planet.PlateHues[int32(reused.ID)] != float32(freshHue(reused.ID)) {
+		t.Errorf("planet holds plate hues %v", pm.planet.PlateHues)
+	}
+}
+
+// TestPlateColorMatchesShader checks Color converts hues as plate view
+// draws them: the golden-angle hue of plate 1 is a green turning cyan
+func TestPlateColorMatchesShader(t *testing.T) {
+	pm := NewPlateManager(core.CreateVoxelPlanet(6371000.0, 4))
+	got := pm.Colors.Color(1)
+	// 137.5° at saturation 0.7 and value 0.8: full green, blue 17.5° of
+	// the 60° toward cyan
+	want := [3]float32{0.24, 0.8, 0.24 + 0.56*17.5/60}
+	for i := range got {
+		if math.Abs(float64(got[i]-want[i])) > 1e-3 {
+			t.Fatalf("plate 1 color %v, want %v", got, want)
+		}
+	}
+}
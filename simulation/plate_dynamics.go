@@ -71,7 +71,8 @@ func (pfc *PlateForceCalculator) identifyPlateBoundaries() {
 	pfc.boundaries = make(map[string]*PlateBoundary)
 	
 	// Check all boundary voxels
-	for plateID, plate := range pfc.manager.Plates {
+	for _, plate := range pfc.manager.Plates {
+		plateID := plate.ID
 		for _, coord := range plate.BoundaryVoxels {
 			// Check neighbors
 			neighbors := pfc.getNeighborCoords(coord)
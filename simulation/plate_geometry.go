@@ -0,0 +1,289 @@
+package simulation
+
+import (
+	"encoding/json"
+	"math"
+
+	"worldgenerator/core"
+)
+
+// PlateProperties describes one plate in the exported GeoJSON
+type PlateProperties struct {
+	ID              int     `json:"id"`
+	Name            string  `json:"name"`
+	Type            string  `json:"type"`
+	EulerPoleLat    float64 `json:"eulerPoleLat"`    // Degrees
+	EulerPoleLon    float64 `json:"eulerPoleLon"`    // Degrees
+	AngularVelocity float64 `json:"angularVelocity"` // Radians per year
+	Area            float64 `json:"area"`            // m²
+}
+
+// geoJSONFeatureCollection is the top-level GeoJSON object
+type geoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []geoJSONFeature `json:"features"`
+}
+
+// geoJSONFeature is one plate's outline and properties
+type geoJSONFeature struct {
+	Type       string          `json:"type"`
+	Geometry   geoJSONGeometry `json:"geometry"`
+	Properties PlateProperties `json:"properties"`
+}
+
+// geoJSONGeometry is a MultiPolygon: polygons of rings of [lon, lat] positions
+type geoJSONGeometry struct {
+	Type        string           `json:"type"`
+	Coordinates [][][][2]float64 `json:"coordinates"`
+}
+
+// geoPoint is a cell corner in degrees, exact for equal fractions of a turn
+// whatever band it was computed from, so corners can be matched as map keys
+type geoPoint struct{ Lon, Lat float64 }
+
+// geoEdge is a directed cell edge with its plate on the left
+type geoEdge struct{ From, To geoPoint }
+
+// ExportPlateGeometry returns the plates as a GeoJSON FeatureCollection, one
+// MultiPolygon feature per plate traced around its member voxels
+// Rings follow RFC 7946: exteriors counterclockwise, holes clockwise, and
+// plates crossing the antimeridian are cut there rather than wrapped
+func (pm *PlateManager) ExportPlateGeometry() ([]byte, error) {
+	collection := geoJSONFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: make([]geoJSONFeature, 0, len(pm.Plates)),
+	}
+
+	for _, plate := range pm.Plates {
+		polygons := assemblePolygons(chainRings(pm.plateOutline(plate)))
+		if polygons == nil {
+			polygons = [][][][2]float64{}
+		}
+		collection.Features = append(collection.Features, geoJSONFeature{
+			Type: "Feature",
+			Geometry: geoJSONGeometry{
+				Type:        "MultiPolygon",
+				Coordinates: polygons,
+			},
+			Properties: PlateProperties{
+				ID:              plate.ID,
+				Name:            plate.Name,
+				Type:            plate.Type,
+				EulerPoleLat:    plate.EulerPoleLat,
+				EulerPoleLon:    plate.EulerPoleLon,
+				AngularVelocity: plate.AngularVelocity,
+				Area:            plate.TotalArea,
+			},
+		})
+	}
+
+	return json.Marshal(collection)
+}
+
+// plateOutline returns every edge between a member voxel and a voxel of
+// another plate (or no voxel at all), directed with the plate on its left
+// Edges against a band with a different longitude count are split at that
+// band's cell boundaries so each piece has a single voxel on the far side
+func (pm *PlateManager) plateOutline(plate *TectonicPlate) []geoEdge {
+	member := func(coord core.VoxelCoord) bool {
+		id, ok := pm.VoxelPlateMap[coord]
+		return ok && id == plate.ID
+	}
+
+	var edges []geoEdge
+	for _, coord := range plate.MemberVoxels {
+		if !member(coord) {
+			continue
+		}
+		shell := &pm.planet.Shells[coord.Shell]
+		bands := len(shell.Voxels)
+		lonCount := len(shell.Voxels[coord.Lat])
+		south, north := bandEdgeLat(coord.Lat, bands), bandEdgeLat(coord.Lat+1, bands)
+		west, east := cellEdgeLon(coord.Lon, lonCount), cellEdgeLon(coord.Lon+1, lonCount)
+
+		// West and east, cut at the antimeridian instead of wrapping
+		if coord.Lon == 0 || !member(core.VoxelCoord{Shell: coord.Shell, Lat: coord.Lat, Lon: coord.Lon - 1}) {
+			edges = append(edges, geoEdge{geoPoint{west, north}, geoPoint{west, south}})
+		}
+		if coord.Lon == lonCount-1 || !member(core.VoxelCoord{Shell: coord.Shell, Lat: coord.Lat, Lon: coord.Lon + 1}) {
+			edges = append(edges, geoEdge{geoPoint{east, south}, geoPoint{east, north}})
+		}
+
+		// South runs west to east, north east to west
+		for _, side := range []struct {
+			lat   int
+			edge  float64
+			north bool
+		}{{coord.Lat - 1, south, false}, {coord.Lat + 1, north, true}} {
+			if side.lat < 0 || side.lat >= bands {
+				edges = append(edges, sideEdge(west, east, side.edge, side.north))
+				continue
+			}
+			otherCount := len(shell.Voxels[side.lat])
+			first := coord.Lon * otherCount / lonCount
+			for j := first; j < otherCount; j++ {
+				from := math.Max(west, cellEdgeLon(j, otherCount))
+				to := math.Min(east, cellEdgeLon(j+1, otherCount))
+				if from >= east {
+					break
+				}
+				if to <= from || member(core.VoxelCoord{Shell: coord.Shell, Lat: side.lat, Lon: j}) {
+					continue
+				}
+				edges = append(edges, sideEdge(from, to, side.edge, side.north))
+			}
+		}
+	}
+
+	return edges
+}
+
+// sideEdge is the horizontal edge from west to east at lat, reversed on a
+// cell's north side to keep the cell on its left
+func sideEdge(west, east, lat float64, north bool) geoEdge {
+	if north {
+		return geoEdge{geoPoint{east, lat}, geoPoint{west, lat}}
+	}
+	return geoEdge{geoPoint{west, lat}, geoPoint{east, lat}}
+}
+
+// bandEdgeLat is the southern edge of band i of n in degrees
+func bandEdgeLat(i, n int) float64 {
+	return float64(i*180)/float64(n) - 90
+}
+
+// cellEdgeLon is the western edge of cell j of n in degrees
+func cellEdgeLon(j, n int) float64 {
+	return float64(j*360)/float64(n) - 180
+}
+
+// chainRings joins directed edges end to start into closed rings
+// Where two rings touch at a corner, the sharpest left turn is taken so the
+// rings stay separate instead of crossing through the shared corner
+func chainRings(edges []geoEdge) [][]geoPoint {
+	outgoing := make(map[geoPoint][]int)
+	for i, e := range edges {
+		outgoing[e.From] = append(outgoing[e.From], i)
+	}
+	used := make([]bool, len(edges))
+
+	var rings [][]geoPoint
+	for start := range edges {
+		if used[start] {
+			continue
+		}
+		used[start] = true
+		ring := []geoPoint{edges[start].From}
+		current := edges[start]
+		for current.To != edges[start].From {
+			ring = append(ring, current.To)
+			next := -1
+			bestTurn := math.Inf(-1)
+			for _, i := range outgoing[current.To] {
+				if used[i] {
+					continue
+				}
+				if turn := turnAngle(current, edges[i]); turn > bestTurn {
+					next, bestTurn = i, turn
+				}
+			}
+			if next < 0 {
+				break // Open chain; can't happen for a closed outline
+			}
+			used[next] = true
+			current = edges[next]
+		}
+		ring = append(ring, ring[0])
+		rings = append(rings, simplifyRing(ring))
+	}
+
+	return rings
+}
+
+// turnAngle is how far b turns left from a's heading, in radians
+func turnAngle(a, b geoEdge) float64 {
+	ax, ay := a.To.Lon-a.From.Lon, a.To.Lat-a.From.Lat
+	bx, by := b.To.Lon-b.From.Lon, b.To.Lat-b.From.Lat
+	return math.Atan2(ax*by-ay*bx, ax*bx+ay*by)
+}
+
+// simplifyRing drops corners where the ring carries straight on
+func simplifyRing(ring []geoPoint) []geoPoint {
+	n := len(ring) - 1 // Last point repeats the first
+	out := make([]geoPoint, 0, len(ring))
+	for i := 0; i < n; i++ {
+		prev, p, next := ring[(i+n-1)%n], ring[i], ring[(i+1)%n]
+		if (p.Lon-prev.Lon)*(next.Lat-p.Lat) != (p.Lat-prev.Lat)*(next.Lon-p.Lon) {
+			out = append(out, p)
+		}
+	}
+	if len(out) < 3 {
+		return ring
+	}
+	return append(out, out[0])
+}
+
+// ringArea is the signed area of a closed ring in square degrees,
+// positive for counterclockwise
+func ringArea(ring []geoPoint) float64 {
+	area := 0.0
+	for i := 0; i+1 < len(ring); i++ {
+		area += ring[i].Lon*ring[i+1].Lat - ring[i+1].Lon*ring[i].Lat
+	}
+	return area / 2
+}
+
+// ringContains reports whether p lies inside ring by ray casting
+func ringContains(ring []geoPoint, p geoPoint) bool {
+	inside := false
+	for i := 0; i+1 < len(ring); i++ {
+		a, b := ring[i], ring[i+1]
+		if (a.Lat > p.Lat) != (b.Lat > p.Lat) &&
+			p.Lon < a.Lon+(p.Lat-a.Lat)*(b.Lon-a.Lon)/(b.Lat-a.Lat) {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// assemblePolygons groups rings into polygons, giving each clockwise hole to
+// the smallest counterclockwise exterior around it
+func assemblePolygons(rings [][]geoPoint) [][][][2]float64 {
+	var exteriors, holes [][]geoPoint
+	for _, ring := range rings {
+		if ringArea(ring) > 0 {
+			exteriors = append(exteriors, ring)
+		} else {
+			holes = append(holes, ring)
+		}
+	}
+
+	polygons := make([][][][2]float64, len(exteriors))
+	for i, ring := range exteriors {
+		polygons[i] = [][][2]float64{ringPositions(ring)}
+	}
+	for _, hole := range holes {
+		// Midpoint of the first edge, which no other ring passes through
+		probe := geoPoint{(hole[0].Lon + hole[1].Lon) / 2, (hole[0].Lat + hole[1].Lat) / 2}
+		owner := -1
+		for i, ring := range exteriors {
+			if ringContains(ring, probe) && (owner < 0 || ringArea(ring) < ringArea(exteriors[owner])) {
+				owner = i
+			}
+		}
+		if owner >= 0 {
+			polygons[owner] = append(polygons[owner], ringPositions(hole))
+		}
+	}
+
+	return polygons
+}
+
+// ringPositions converts a ring to GeoJSON [lon, lat] positions
+func ringPositions(ring []geoPoint) [][2]float64 {
+	positions := make([][2]float64, len(ring))
+	for i, p := range ring {
+		positions[i] = [2]float64{p.Lon, p.Lat}
+	}
+	return positions
+}
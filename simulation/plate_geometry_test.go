@@ -0,0 +1,160 @@
+package simulation
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// exportedPlates is the part of the GeoJSON the tests read back
+type exportedPlates struct {
+	Type     string `json:"type"`
+	Features []struct {
+		Type     string `json:"type"`
+		Geometry struct {
+			Type        string           `json:"type"`
+			Coordinates [][][][2]float64 `json:"coordinates"`
+		} `json:"geometry"`
+		Properties PlateProperties `json:"properties"`
+	} `json:"features"`
+}
+
+// TestExportPlateGeometry checks the export is a valid FeatureCollection with
+// one feature per plate, closed counterclockwise exteriors and clockwise
+// holes, and outlines enclosing exactly the area of each plate's voxels
+func TestExportPlateGeometry(t *testing.T) {
+	pm, shell, surface := newOceanPlateManager()
+	planet := pm.planet
+
+	// Three plates tile the surface in sectors whose borders step east every
+	// few bands, so outlines follow ragged edges and close around the poles
+	plates := []*TectonicPlate{pm.newPlate(), pm.newPlate(), pm.newPlate()}
+	members := make([][]core.VoxelCoord, len(plates))
+	for lat := range shell.Voxels {
+		count := len(shell.Voxels[lat])
+		for lon := range shell.Voxels[lat] {
+			i := (len(plates)*lon/count + lat/4) % len(plates)
+			members[i] = append(members[i], core.VoxelCoord{Shell: surface, Lat: lat, Lon: lon})
+		}
+	}
+	for i, plate := range plates {
+		pm.assignMembers(plate, members[i])
+	}
+	pm.Plates = plates
+
+	data, err := pm.ExportPlateGeometry()
+	if err != nil {
+		t.Fatalf("ExportPlateGeometry: %v", err)
+	}
+	var exported exportedPlates
+	if err := json.Unmarshal(data, &exported); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if exported.Type != "FeatureCollection" {
+		t.Fatalf("type %q, want FeatureCollection", exported.Type)
+	}
+	if len(exported.Features) != len(pm.Plates) {
+		t.Fatalf("%d features for %d plates", len(exported.Features), len(pm.Plates))
+	}
+
+	for i, feature := range exported.Features {
+		plate := pm.Plates[i]
+		if feature.Type != "Feature" || feature.Geometry.Type != "MultiPolygon" {
+			t.Fatalf("plate %d exported as %s %s", plate.ID, feature.Type, feature.Geometry.Type)
+		}
+		if feature.Properties.ID != plate.ID || feature.Properties.EulerPoleLat != plate.EulerPoleLat ||
+			feature.Properties.AngularVelocity != plate.AngularVelocity {
+			t.Errorf("feature %d properties %+v don't match plate %d", i, feature.Properties, plate.ID)
+		}
+
+		// Outline area in square degrees against the voxels it traces
+		want := 0.0
+		for _, coord := range plate.MemberVoxels {
+			shell := &planet.Shells[coord.Shell]
+			want += 180.0 / float64(len(shell.Voxels)) * 360.0 / float64(len(shell.Voxels[coord.Lat]))
+		}
+		got := 0.0
+		for _, polygon := range feature.Geometry.Coordinates {
+			for r, ring := range polygon {
+				if len(ring) < 4 || ring[0] != ring[len(ring)-1] {
+					t.Fatalf("plate %d has an open ring of %d positions", plate.ID, len(ring))
+				}
+				points := make([]geoPoint, len(ring))
+				for k, p := range ring {
+					if p[0] < -180 || p[0] > 180 || p[1] < -90 || p[1] > 90 {
+						t.Fatalf("plate %d position %v out of range", plate.ID, p)
+					}
+					points[k] = geoPoint{p[0], p[1]}
+				}
+				area := ringArea(points)
+				if (r == 0) != (area > 0) {
+					t.Errorf("plate %d ring %d has signed area %.2f, want exteriors positive and holes negative", plate.ID, r, area)
+				}
+				got += area
+			}
+		}
+		if math.Abs(got-want) > 1e-6*want {
+			t.Errorf("plate %d outline encloses %.4f deg², its voxels cover %.4f", plate.ID, got, want)
+		}
+	}
+}
+
+// TestExportPlateGeometryHoleAndAntimeridian checks a plate around an island
+// of another gets a hole, and a plate across the antimeridian is cut into two
+// polygons there
+func TestExportPlateGeometryHoleAndAntimeridian(t *testing.T) {
+	pm, shell, surface := newOceanPlateManager()
+	lat := shell.LatBands / 2
+	lonCount := len(shell.Voxels[lat])
+
+	var ring, island, straddle []core.VoxelCoord
+	for dLat := -2; dLat <= 2; dLat++ {
+		for lon := 100; lon < 105; lon++ {
+			coord := core.VoxelCoord{Shell: surface, Lat: lat + dLat, Lon: lon}
+			if dLat == 0 && lon == 102 {
+				island = append(island, coord)
+			} else {
+				ring = append(ring, coord)
+			}
+		}
+		for _, lon := range []int{0, 1, lonCount - 2, lonCount - 1} {
+			straddle = append(straddle, core.VoxelCoord{Shell: surface, Lat: lat + dLat, Lon: lon})
+		}
+	}
+	plates := []*TectonicPlate{pm.newPlate(), pm.newPlate(), pm.newPlate()}
+	pm.assignMembers(plates[0], ring)
+	pm.assignMembers(plates[1], island)
+	pm.assignMembers(plates[2], straddle)
+	pm.Plates = plates
+
+	data, err := pm.ExportPlateGeometry()
+	if err != nil {
+		t.Fatalf("ExportPlateGeometry: %v", err)
+	}
+	var exported exportedPlates
+	if err := json.Unmarshal(data, &exported); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+
+	if polygons := exported.Features[0].Geometry.Coordinates; len(polygons) != 1 || len(polygons[0]) != 2 {
+		t.Errorf("ring plate has %d polygons, want 1 with a hole", len(polygons))
+	}
+	if polygons := exported.Features[1].Geometry.Coordinates; len(polygons) != 1 || len(polygons[0]) != 1 || len(polygons[0][0]) != 5 {
+		t.Errorf("island plate exported as %v, want a single square", polygons)
+	}
+	polygons := exported.Features[2].Geometry.Coordinates
+	if len(polygons) != 2 {
+		t.Fatalf("antimeridian plate has %d polygons, want 2", len(polygons))
+	}
+	for _, polygon := range polygons {
+		touches := false
+		for _, p := range polygon[0] {
+			touches = touches || math.Abs(p[0]) == 180
+		}
+		if !touches {
+			t.Errorf("antimeridian plate polygon %v doesn't reach ±180°", polygon[0])
+		}
+	}
+}
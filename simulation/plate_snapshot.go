@@ -0,0 +1,46 @@
+package simulation
+
+import (
+	"worldgenerator/core"
+)
+
+// PlateSnapshot is the plate analysis of one physics state, taken on the
+// physics thread and published with the planet, so readers on other threads
+// never touch the plate manager physics keeps rewriting
+// It is never modified once published, so any number of readers can share it
+type PlateSnapshot struct {
+	Boundaries []BoundarySegment // See BoundaryRates
+	Stats      []PlateStats      // Every plate, see GetPlateStats
+}
+
+// Snapshot analyzes the plates as they stand
+func (pm *PlateManager) Snapshot() *PlateSnapshot {
+	return &PlateSnapshot{
+		Boundaries: pm.BoundaryRates(),
+		Stats:      pm.GetPlateStats(),
+	}
+}
+
+// PlateStats returns the statistics of the plate with the ID, false when the
+// analyzed state has no such plate
+func (s *PlateSnapshot) PlateStats(plateID int) (PlateStats, bool) {
+	for _, stats := range s.Stats {
+		if stats.ID == plateID {
+			return stats, true
+		}
+	}
+	return PlateStats{}, false
+}
+
+// HasPlate reports whether the analyzed state has a plate with the ID
+func (s *PlateSnapshot) HasPlate(plateID int) bool {
+	_, ok := s.PlateStats(plateID)
+	return ok
+}
+
+// PublishedPlates returns the plate analysis published with planet, nil
+// before physics has published one
+func PublishedPlates(planet *core.VoxelPlanet) *PlateSnapshot {
+	snapshot, _ := planet.Plates.(*PlateSnapshot)
+	return snapshot
+}
@@ -0,0 +1,96 @@
+package simulation
+
+import (
+	"math"
+
+	"worldgenerator/core"
+)
+
+// PlateStats summarizes one plate as it stands, for analysis and display
+type PlateStats struct {
+	ID            int          `json:"id"`
+	Name          string       `json:"name"`
+	Type          string       `json:"type"`
+	Area          float64      `json:"area"`          // m²
+	AverageAge    float64      `json:"averageAge"`    // Years
+	MemberCount   int          `json:"memberCount"`   // Surface voxels
+	BoundaryCount int          `json:"boundaryCount"` // Of which on the plate's edge
+	CentroidLat   float64      `json:"centroidLat"`   // Degrees
+	CentroidLon   float64      `json:"centroidLon"`   // Degrees
+	Velocity      core.Vector3 `json:"velocity"`      // Mean horizontal velocity (m/year) in the unitPosition frame
+	VelEast       float64      `json:"velEast"`       // Velocity's east and north
+	VelNorth      float64      `json:"velNorth"`      // components at the centroid, m/year
+}
+
+// GetPlateStats returns the current statistics of every plate, in the order
+// of pm.Plates. Areas and ages come from the member voxels as they are now
+func (pm *PlateManager) GetPlateStats() []PlateStats {
+	stats := make([]PlateStats, 0, len(pm.Plates))
+	for _, plate := range pm.Plates {
+		stats = append(stats, pm.plateStats(plate))
+	}
+	return stats
+}
+
+// plateStats measures one plate
+func (pm *PlateManager) plateStats(plate *TectonicPlate) PlateStats {
+	s := PlateStats{
+		ID:            plate.ID,
+		Name:          plate.Name,
+		Type:          plate.Type,
+		MemberCount:   len(plate.MemberVoxels),
+		BoundaryCount: len(plate.BoundaryVoxels),
+	}
+	if len(plate.MemberVoxels) == 0 {
+		return s
+	}
+
+	var velocity core.Vector3
+	for _, coord := range plate.MemberVoxels {
+		shell := &pm.planet.Shells[coord.Shell]
+		area := pm.planet.VoxelArea(shell, coord.Lat)
+		s.Area += area
+		s.AverageAge += float64(shell.Voxels[coord.Lat][coord.Lon].Age) * area
+		velocity = velocity.Add(pm.velocity3D(coord).Scale(area))
+	}
+	s.AverageAge /= s.Area
+	s.Velocity = velocity.Scale(1 / s.Area)
+
+	lat, lon, ok := pm.plateCentroid(plate)
+	if !ok {
+		return s
+	}
+	s.CentroidLat, s.CentroidLon = lat, lon
+
+	east, north := localFrame(lat*math.Pi/180, lon*math.Pi/180)
+	s.VelEast = s.Velocity.Dot(east)
+	s.VelNorth = s.Velocity.Dot(north)
+	return s
+}
+
+// plateCentroid returns the spherical mean of a plate's member positions in
+// degrees, weighted by area, so a plate straddling the antimeridian is
+// centered on it rather than on the far side of the globe. False when the
+// plate has no members, or they spread so evenly around the globe that it
+// has no meaningful center
+func (pm *PlateManager) plateCentroid(plate *TectonicPlate) (lat, lon float64, ok bool) {
+	var center core.Vector3
+	totalArea := 0.0
+	for _, coord := range plate.MemberVoxels {
+		area := pm.planet.VoxelArea(&pm.planet.Shells[coord.Shell], coord.Lat)
+		totalArea += area
+		center = center.Add(pm.unitPosition(coord).Scale(area))
+	}
+	if totalArea == 0 || center.Length() < 1e-9*totalArea {
+		return 0, 0, false
+	}
+	center = center.Normalize()
+	lat = math.Asin(math.Max(-1, math.Min(1, center.Z))) * 180 / math.Pi
+	lon = math.Atan2(center.Y, center.X) * 180 / math.Pi
+	return lat, lon, true
+}
+
+// Speed returns how fast the plate moves on average, in cm/yr
+func (s PlateStats) Speed() float64 {
+	return core.CmPerYear(s.Velocity.Length())
+}
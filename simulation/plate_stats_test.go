@@ -0,0 +1,50 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// TestPlateStatsCentroidAcrossAntimeridian checks a plate straddling 180° is
+// centered there, not on the prime meridian a lat/lon average would give,
+// and reports its area, age and eastward drift
+func TestPlateStatsCentroidAcrossAntimeridian(t *testing.T) {
+	pm, shell, surface := newOceanPlateManager()
+	plate := pm.newPlate()
+
+	var coords []core.VoxelCoord
+	var area float64
+	for lat := shell.LatBands/2 - 2; lat < shell.LatBands/2+2; lat++ {
+		for lon := range shell.Voxels[lat] {
+			coord := core.VoxelCoord{Shell: surface, Lat: lat, Lon: lon}
+			if _, lonDeg := pm.planet.VoxelLatLon(coord); math.Abs(lonDeg) < 160 {
+				continue
+			}
+			voxel := &shell.Voxels[lat][lon]
+			voxel.Age = 2e6
+			voxel.VelEast = float32(3 / core.CmPerYear(1))
+			coords = append(coords, coord)
+			area += pm.planet.VoxelArea(shell, lat)
+		}
+	}
+	pm.assignMembers(plate, coords)
+	pm.Plates = []*TectonicPlate{plate}
+
+	stats := pm.GetPlateStats()
+	if len(stats) != 1 || stats[0].ID != plate.ID || stats[0].MemberCount != len(coords) {
+		t.Fatalf("stats %+v, want plate %d with %d members", stats, plate.ID, len(coords))
+	}
+	s := stats[0]
+	if math.Abs(s.CentroidLat) > 1e-6 || 180-math.Abs(s.CentroidLon) > 1e-6 {
+		t.Errorf("centroid at %.3f°, %.3f°, want on the equator at 180°", s.CentroidLat, s.CentroidLon)
+	}
+	if math.Abs(s.Area-area) > 1e-9*area || math.Abs(s.AverageAge-2e6) > 1 {
+		t.Errorf("area %.4g m² and age %.4g years, want %.4g m² and 2e6 years", s.Area, s.AverageAge, area)
+	}
+	// Eastward at each member, so a little less once averaged across 40° of longitude
+	if east := core.CmPerYear(s.VelEast); east > 3 || east < 2.9 || math.Abs(s.VelNorth) > 1e-3*s.VelEast {
+		t.Errorf("moving %.3f cm/yr east and %.3g m/s north, want about 3 cm/yr east", east, s.VelNorth)
+	}
+}
@@ -0,0 +1,290 @@
+package simulation
+
+import (
+	"fmt"
+	"sort"
+
+	"worldgenerator/core"
+)
+
+const (
+	// defaultSutureWeldTime is how long plates must converge before they weld
+	// into one - continental collisions like India-Asia take tens of millions of years
+	defaultSutureWeldTime = 50e6
+
+	// defaultMinSutureLength keeps brief point contacts from welding plates
+	defaultMinSutureLength = 10
+)
+
+// newPlate creates an empty plate with the next available ID
+func (pm *PlateManager) newPlate() *TectonicPlate {
+	id := pm.allocatePlateID()
+	pm.Colors.assign(id)
+	return &TectonicPlate{
+		ID:   id,
+		Name: fmt.Sprintf("Plate_%d", id),
+	}
+}
+
+// allocatePlateID returns the lowest ID freed by a destroyed plate, or a new one
+func (pm *PlateManager) allocatePlateID() int {
+	if len(pm.freeIDs) > 0 {
+		sort.Ints(pm.freeIDs)
+		id := pm.freeIDs[0]
+		pm.freeIDs = pm.freeIDs[1:]
+		return id
+	}
+	id := pm.nextPlateID
+	pm.nextPlateID++
+	return id
+}
+
+// releasePlateID returns a destroyed plate's ID to the pool, and forgets its
+// color and track so the next plate to take the ID doesn't look like it
+func (pm *PlateManager) releasePlateID(id int) {
+	pm.freeIDs = append(pm.freeIDs, id)
+	pm.Colors.release(id)
+	pm.tracks.release(id)
+}
+
+// UpdatePlateTopology splits plates separated by rifts and welds plates
+// joined by long-lived sutures
+func (pm *PlateManager) UpdatePlateTopology(dt float64) {
+	split := pm.SplitDisconnectedPlates()
+	merged := pm.MergeWeldedPlates(dt)
+
+	if split > 0 || merged > 0 {
+		pm.refreshPlates()
+	}
+}
+
+// SplitDisconnectedPlates divides each plate whose members no longer form one
+// connected region, e.g. after a divergent boundary has rifted it apart
+// The largest piece keeps the plate's ID; returns the number of plates created
+func (pm *PlateManager) SplitDisconnectedPlates() int {
+	created := 0
+
+	for _, plate := range append([]*TectonicPlate(nil), pm.Plates...) {
+		pieces := pm.connectedPieces(plate)
+		if len(pieces) < 2 {
+			continue
+		}
+
+		sort.Slice(pieces, func(i, j int) bool {
+			return len(pieces[i]) > len(pieces[j])
+		})
+
+		// Members lost to the rift no longer map to this plate
+		for _, coord := range plate.MemberVoxels {
+			voxel := &pm.planet.Shells[coord.Shell].Voxels[coord.Lat][coord.Lon]
+			if voxel.PlateID != int32(plate.ID) && pm.VoxelPlateMap[coord] == plate.ID {
+				delete(pm.VoxelPlateMap, coord)
+			}
+		}
+
+		kept := pieces[0]
+		for n, piece := range pieces[1:] {
+			// At the cap the fragment stays part of its parent
+			if pm.MaxPlates > 0 && len(pm.Plates) >= pm.MaxPlates {
+				kept = append(kept, piece...)
+				continue
+			}
+
+			// The new plate inherits the parent's motion
+			fragment := pm.newPlate()
+			fragment.EulerPoleLat = plate.EulerPoleLat
+			fragment.EulerPoleLon = plate.EulerPoleLon
+			fragment.AngularVelocity = plate.AngularVelocity
+			pm.assignMembers(fragment, piece)
+			pm.Colors.split(plate.ID, fragment.ID, n+1)
+			pm.tracks.split(plate.ID, fragment.ID)
+
+			pm.Plates = append(pm.Plates, fragment)
+			created++
+		}
+		pm.assignMembers(plate, kept)
+	}
+
+	return created
+}
+
+// connectedPieces flood-fills a plate's surviving members into connected regions
+// Members that rifted into ocean or were taken by another plate are dropped
+func (pm *PlateManager) connectedPieces(plate *TectonicPlate) [][]core.VoxelCoord {
+	belongs := func(voxel *core.VoxelMaterial) bool {
+		return voxel.PlateID == int32(plate.ID) &&
+			voxel.Type != core.MatAir && voxel.Type != core.MatWater
+	}
+
+	assigned := make(map[core.VoxelCoord]bool)
+	var pieces [][]core.VoxelCoord
+
+	for _, coord := range plate.MemberVoxels {
+		if assigned[coord] {
+			continue
+		}
+		shell := &pm.planet.Shells[coord.Shell]
+		piece := core.SphericalFloodFill(shell, coord, belongs)
+		if len(piece) == 0 {
+			continue
+		}
+		for _, member := range piece {
+			assigned[member] = true
+		}
+		pieces = append(pieces, piece)
+	}
+
+	return pieces
+}
+
+// MergeWeldedPlates joins plates across convergent boundaries that have stayed
+// active for SutureWeldTime years along at least MinSutureLength voxels
+// The larger plate absorbs the smaller; returns the number of merges
+func (pm *PlateManager) MergeWeldedPlates(dt float64) int {
+	if pm.forceCalculator == nil {
+		return 0
+	}
+	if pm.sutureAge == nil {
+		pm.sutureAge = make(map[[2]int]float64)
+	}
+
+	// Age every suture that is still converging
+	active := make(map[[2]int]bool)
+	for _, boundary := range pm.forceCalculator.boundaries {
+		if boundary.Type != BoundaryConvergent || len(boundary.BoundaryVoxels) < pm.MinSutureLength {
+			continue
+		}
+		pair := [2]int{boundary.PlateA, boundary.PlateB}
+		active[pair] = true
+		pm.sutureAge[pair] += dt
+	}
+
+	// Sutures that stopped converging start over
+	var welded [][2]int
+	for pair, age := range pm.sutureAge {
+		if !active[pair] {
+			delete(pm.sutureAge, pair)
+		} else if age >= pm.SutureWeldTime {
+			welded = append(welded, pair)
+		}
+	}
+
+	// Oldest sutures weld first, ties broken by ID for reproducibility
+	sort.Slice(welded, func(i, j int) bool {
+		ai, aj := pm.sutureAge[welded[i]], pm.sutureAge[welded[j]]
+		if ai != aj {
+			return ai > aj
+		}
+		if welded[i][0] != welded[j][0] {
+			return welded[i][0] < welded[j][0]
+		}
+		return welded[i][1] < welded[j][1]
+	})
+
+	merged := 0
+	for _, pair := range welded {
+		a := pm.forceCalculator.getPlateByID(pair[0])
+		b := pm.forceCalculator.getPlateByID(pair[1])
+		if a == nil || b == nil {
+			continue // One side was already absorbed this step
+		}
+		if len(b.MemberVoxels) > len(a.MemberVoxels) {
+			a, b = b, a
+		}
+		pm.mergePlates(a, b)
+		merged++
+	}
+
+	return merged
+}
+
+// mergePlates moves all of absorbed's voxels into keep and destroys absorbed
+func (pm *PlateManager) mergePlates(keep, absorbed *TectonicPlate) {
+	pm.assignMembers(keep, append(keep.MemberVoxels, absorbed.MemberVoxels...))
+	pm.removePlate(absorbed)
+}
+
+// removePlate drops a plate from the manager and frees its ID
+// Its voxels are left as they are for the caller to reassign
+func (pm *PlateManager) removePlate(plate *TectonicPlate) {
+	for i, p := range pm.Plates {
+		if p == plate {
+			pm.Plates = append(pm.Plates[:i], pm.Plates[i+1:]...)
+			break
+		}
+	}
+	for pair := range pm.sutureAge {
+		if pair[0] == plate.ID || pair[1] == plate.ID {
+			delete(pm.sutureAge, pair)
+		}
+	}
+	pm.releasePlateID(plate.ID)
+}
+
+// discardPlate destroys a plate and clears plate membership from its voxels
+func (pm *PlateManager) discardPlate(plate *TectonicPlate) {
+	for _, coord := range plate.MemberVoxels {
+		if pm.VoxelPlateMap[coord] == plate.ID {
+			delete(pm.VoxelPlateMap, coord)
+			pm.planet.Shells[coord.Shell].Voxels[coord.Lat][coord.Lon].PlateID = 0
+		}
+	}
+	pm.removePlate(plate)
+}
+
+// assignMembers makes coords the plate's members and tags their voxels
+func (pm *PlateManager) assignMembers(plate *TectonicPlate, coords []core.VoxelCoord) {
+	plate.MemberVoxels = coords
+	for _, coord := range coords {
+		pm.VoxelPlateMap[coord] = plate.ID
+		pm.planet.Shells[coord.Shell].Voxels[coord.Lat][coord.Lon].PlateID = int32(plate.ID)
+	}
+}
+
+// enforcePlateLimit folds the smallest plates into their longest-bordering
+// neighbor until the plate count is within MaxPlates
+func (pm *PlateManager) enforcePlateLimit() {
+	for pm.MaxPlates > 0 && len(pm.Plates) > pm.MaxPlates {
+		smallest := pm.Plates[0]
+		for _, plate := range pm.Plates[1:] {
+			if len(plate.MemberVoxels) < len(smallest.MemberVoxels) {
+				smallest = plate
+			}
+		}
+
+		// Count shared edges with each neighboring plate
+		contact := make(map[int]int)
+		for _, coord := range smallest.MemberVoxels {
+			shell := &pm.planet.Shells[coord.Shell]
+			for _, neighbor := range core.ShellNeighbors(shell, coord) {
+				if id, ok := pm.VoxelPlateMap[neighbor]; ok && id != smallest.ID {
+					contact[id]++
+				}
+			}
+		}
+
+		var target *TectonicPlate
+		for _, plate := range pm.Plates {
+			if plate != smallest && contact[plate.ID] > 0 &&
+				(target == nil || contact[plate.ID] > contact[target.ID]) {
+				target = plate
+			}
+		}
+
+		// Isolated plates have nothing to merge into
+		if target == nil {
+			pm.discardPlate(smallest)
+		} else {
+			pm.mergePlates(target, smallest)
+		}
+	}
+}
+
+// refreshPlates recomputes properties and boundaries after membership changes
+func (pm *PlateManager) refreshPlates() {
+	pm.BoundaryMap = make(map[core.VoxelCoord]bool)
+	for _, plate := range pm.Plates {
+		pm.calculatePlateProperties(plate)
+		pm.identifyPlateBoundaries(plate)
+	}
+}
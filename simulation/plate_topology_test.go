@@ -0,0 +1,122 @@
+package simulation
+
+import (
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// newOceanPlateManager returns a manager over a planet whose surface is all ocean
+func newOceanPlateManager() (*PlateManager, *core.SphericalShell, int) {
+	planet := core.CreateVoxelPlanet(6371000.0, 20)
+	surface := len(planet.Shells) - 2
+	shell := &planet.Shells[surface]
+	for lat := range shell.Voxels {
+		for lon := range shell.Voxels[lat] {
+			shell.Voxels[lat][lon].Type = core.MatWater
+			shell.Voxels[lat][lon].PlateID = 0
+		}
+	}
+	return NewPlateManager(planet), shell, surface
+}
+
+// addBlock turns an equatorial lon range into brittle granite on plate
+func addBlock(pm *PlateManager, shell *core.SphericalShell, surface int, plate *TectonicPlate, lonStart, lonEnd int) {
+	lat := shell.LatBands / 2
+	var coords []core.VoxelCoord
+	for lon := lonStart; lon < lonEnd; lon++ {
+		shell.Voxels[lat][lon].Type = core.MatGranite
+		shell.Voxels[lat][lon].IsBrittle = true
+		coords = append(coords, core.VoxelCoord{Shell: surface, Lat: lat, Lon: lon})
+	}
+	pm.assignMembers(plate, append(plate.MemberVoxels, coords...))
+}
+
+// TestSplitRiftedPlate checks a plate separated by a rift becomes two plates
+func TestSplitRiftedPlate(t *testing.T) {
+	pm, shell, surface := newOceanPlateManager()
+
+	// One plate spans two blocks with open ocean between them
+	plate := pm.newPlate()
+	addBlock(pm, shell, surface, plate, 100, 140)
+	addBlock(pm, shell, surface, plate, 150, 170)
+	pm.Plates = []*TectonicPlate{plate}
+
+	if created := pm.SplitDisconnectedPlates(); created != 1 {
+		t.Fatalf("created %d plates, want 1", created)
+	}
+	if len(pm.Plates) != 2 {
+		t.Fatalf("got %d plates, want 2", len(pm.Plates))
+	}
+
+	// The larger block keeps the original ID
+	if len(plate.MemberVoxels) != 40 {
+		t.Errorf("original plate has %d voxels, want 40", len(plate.MemberVoxels))
+	}
+	fragment := pm.Plates[1]
+	if fragment.ID == plate.ID || len(fragment.MemberVoxels) != 20 {
+		t.Errorf("fragment has ID %d and %d voxels, want a new ID and 20", fragment.ID, len(fragment.MemberVoxels))
+	}
+	for _, coord := range fragment.MemberVoxels {
+		voxel := &shell.Voxels[coord.Lat][coord.Lon]
+		if voxel.PlateID != int32(fragment.ID) || pm.VoxelPlateMap[coord] != fragment.ID {
+			t.Fatalf("fragment voxel %v still tagged as plate %d", coord, voxel.PlateID)
+		}
+	}
+
+	// At the plate cap the rift leaves the plate whole
+	pm, shell, surface = newOceanPlateManager()
+	pm.MaxPlates = 1
+	plate = pm.newPlate()
+	addBlock(pm, shell, surface, plate, 100, 140)
+	addBlock(pm, shell, surface, plate, 150, 170)
+	pm.Plates = []*TectonicPlate{plate}
+
+	if created := pm.SplitDisconnectedPlates(); created != 0 || len(plate.MemberVoxels) != 60 {
+		t.Errorf("at cap: created %d plates with %d voxels left, want 0 and 60", created, len(plate.MemberVoxels))
+	}
+}
+
+// TestMergeWeldedPlates checks a long-lived convergent suture welds two plates into one
+func TestMergeWeldedPlates(t *testing.T) {
+	pm, shell, surface := newOceanPlateManager()
+	pm.SutureWeldTime = 100
+
+	a := pm.newPlate()
+	b := pm.newPlate()
+	addBlock(pm, shell, surface, a, 100, 140)
+	addBlock(pm, shell, surface, b, 140, 160)
+	pm.Plates = []*TectonicPlate{a, b}
+
+	// The two blocks collide along their shared edge
+	pm.forceCalculator = NewPlateForceCalculator(pm.planet, pm)
+	suture := &PlateBoundary{PlateA: a.ID, PlateB: b.ID, Type: BoundaryConvergent}
+	for i := 0; i < pm.MinSutureLength; i++ {
+		suture.BoundaryVoxels = append(suture.BoundaryVoxels, a.MemberVoxels[len(a.MemberVoxels)-1])
+	}
+	pm.forceCalculator.boundaries["1_2"] = suture
+
+	if merged := pm.MergeWeldedPlates(60); merged != 0 {
+		t.Fatalf("young suture merged %d plates, want 0", merged)
+	}
+	if merged := pm.MergeWeldedPlates(60); merged != 1 {
+		t.Fatalf("welded suture merged %d plates, want 1", merged)
+	}
+
+	if len(pm.Plates) != 1 || pm.Plates[0] != a {
+		t.Fatalf("got %d plates, want only the larger plate", len(pm.Plates))
+	}
+	if len(a.MemberVoxels) != 60 {
+		t.Errorf("merged plate has %d voxels, want 60", len(a.MemberVoxels))
+	}
+	for _, coord := range a.MemberVoxels {
+		if shell.Voxels[coord.Lat][coord.Lon].PlateID != int32(a.ID) {
+			t.Fatalf("voxel %v not retagged to plate %d", coord, a.ID)
+		}
+	}
+
+	// The absorbed plate's ID is reused
+	if id := pm.allocatePlateID(); id != b.ID {
+		t.Errorf("next plate ID %d, want reused %d", id, b.ID)
+	}
+}
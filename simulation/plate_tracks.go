@@ -0,0 +1,136 @@
+package simulation
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"worldgenerator/core"
+)
+
+const (
+	// defaultTrackInterval is the years between points of a plate's track:
+	// at a few cm/yr a plate moves tens of kilometers in that time, a
+	// fraction of a voxel, so the path reads as a line rather than dots
+	defaultTrackInterval = 1e6
+
+	// defaultMaxTrackPoints caps each track, dropping its oldest points
+	defaultMaxTrackPoints = 500
+)
+
+// PlateTrack is the path a plate's centroid has followed, oldest point
+// first, in the manner of an apparent polar wander path. A plate carries the
+// track of the lineage it belongs to: fragments rifted off a plate share its
+// path up to the rift, and a plate absorbed by another leaves its track behind
+type PlateTrack struct {
+	PlateID int                    `json:"plateId"`
+	Points  []core.PlateTrackPoint `json:"points"`
+}
+
+// plateTrackLog records every plate's track, keeping them in the planet's
+// PlateTracks so they are saved and loaded with it. It is safe to read from
+// the render thread
+type plateTrackLog struct {
+	mu           sync.RWMutex
+	tracks       map[int32][]core.PlateTrackPoint
+	lastRecorded float64 // Planet time of the newest point, -Inf before any
+}
+
+// newPlateTrackLog keeps its tracks in planet.PlateTracks, carrying on from
+// any a loaded planet brought with it
+func newPlateTrackLog(planet *core.VoxelPlanet) *plateTrackLog {
+	if planet.PlateTracks == nil {
+		planet.PlateTracks = make(map[int32][]core.PlateTrackPoint)
+	}
+	log := &plateTrackLog{tracks: planet.PlateTracks, lastRecorded: math.Inf(-1)}
+	for _, points := range planet.PlateTracks {
+		if n := len(points); n > 0 && points[n-1].Time > log.lastRecorded {
+			log.lastRecorded = points[n-1].Time
+		}
+	}
+	return log
+}
+
+// GetPlateTracks returns a copy of every plate's track in plate ID order
+func (pm *PlateManager) GetPlateTracks() []PlateTrack {
+	pm.tracks.mu.RLock()
+	defer pm.tracks.mu.RUnlock()
+	tracks := make([]PlateTrack, 0, len(pm.tracks.tracks))
+	for id, points := range pm.tracks.tracks {
+		tracks = append(tracks, PlateTrack{
+			PlateID: int(id),
+			Points:  append([]core.PlateTrackPoint(nil), points...),
+		})
+	}
+	sort.Slice(tracks, func(i, j int) bool { return tracks[i].PlateID < tracks[j].PlateID })
+	return tracks
+}
+
+// recordPlateTracks adds each plate's centroid to its track once
+// TrackInterval years have passed since the last points were taken
+func (pm *PlateManager) recordPlateTracks() {
+	now := pm.planet.Time
+	if pm.TrackInterval <= 0 || now < pm.tracks.lastRecorded+pm.TrackInterval {
+		return
+	}
+
+	centroids := make(map[int32]core.PlateTrackPoint, len(pm.Plates))
+	for _, plate := range pm.Plates {
+		if lat, lon, ok := pm.plateCentroid(plate); ok {
+			centroids[int32(plate.ID)] = core.PlateTrackPoint{Time: now, Lat: lat, Lon: lon}
+		}
+	}
+
+	pm.tracks.mu.Lock()
+	defer pm.tracks.mu.Unlock()
+	for id, point := range centroids {
+		points := append(pm.tracks.tracks[id], point)
+		if pm.MaxTrackPoints > 0 && len(points) > pm.MaxTrackPoints {
+			points = append([]core.PlateTrackPoint(nil), points[len(points)-pm.MaxTrackPoints:]...)
+		}
+		pm.tracks.tracks[id] = points
+	}
+	pm.tracks.lastRecorded = now
+}
+
+// split gives a fragment rifted off parent a copy of the parent's track
+func (l *plateTrackLog) split(parent, fragment int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if points := l.tracks[int32(parent)]; len(points) > 0 {
+		l.tracks[int32(fragment)] = append([]core.PlateTrackPoint(nil), points...)
+	}
+}
+
+// release forgets a destroyed plate's track, so its ID starts a new one
+func (l *plateTrackLog) release(id int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.tracks, int32(id))
+}
+
+// snapshot copies the tracks, for handing on after plates are re-identified
+func (l *plateTrackLog) snapshot() map[int32][]core.PlateTrackPoint {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	tracks := make(map[int32][]core.PlateTrackPoint, len(l.tracks))
+	for id, points := range l.tracks {
+		tracks[id] = points
+	}
+	return tracks
+}
+
+// inherit gives freshly identified plates the tracks their ancestors had
+// before (see PlateManager.plateAncestors); plates with none start afresh
+func (l *plateTrackLog) inherit(plates []*TectonicPlate, ancestors map[int]int32, previous map[int32][]core.PlateTrackPoint) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for id := range l.tracks {
+		delete(l.tracks, id)
+	}
+	for _, plate := range plates {
+		if points := previous[ancestors[plate.ID]]; len(points) > 0 {
+			l.tracks[int32(plate.ID)] = append([]core.PlateTrackPoint(nil), points...)
+		}
+	}
+}
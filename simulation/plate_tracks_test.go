@@ -0,0 +1,106 @@
+package simulation
+
+import (
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// TestPlateTracksRecordDrift moves a plate east a step at a time: its track
+// takes a point every TrackInterval years, keeps only the newest
+// MaxTrackPoints, and follows the plate's lineage through a rift and
+// re-identification
+func TestPlateTracksRecordDrift(t *testing.T) {
+	pm, shell, surface := newOceanPlateManager()
+	for lat := range shell.Voxels {
+		for lon := range shell.Voxels[lat] {
+			shell.Voxels[lat][lon].IsBrittle = true
+		}
+	}
+	pm.TrackInterval = 1e6
+	pm.MaxTrackPoints = 3
+
+	plate := pm.newPlate()
+	pm.Plates = []*TectonicPlate{plate}
+	for step := 0; step < 9; step++ {
+		pm.planet.Time = float64(step) * 0.5e6
+		for _, coord := range plate.MemberVoxels {
+			shell.Voxels[coord.Lat][coord.Lon].Type = core.MatWater
+		}
+		plate.MemberVoxels = nil
+		addBlock(pm, shell, surface, plate, 100+10*step, 360+10*step)
+		pm.recordPlateTracks()
+	}
+
+	tracks := pm.GetPlateTracks()
+	if len(tracks) != 1 || tracks[0].PlateID != plate.ID {
+		t.Fatalf("tracks %+v, want one for plate %d", tracks, plate.ID)
+	}
+	points := tracks[0].Points
+	if len(points) != 3 {
+		t.Fatalf("track has %d points, want the newest 3", len(points))
+	}
+	for i, want := range []float64{2e6, 3e6, 4e6} {
+		if points[i].Time != want {
+			t.Errorf("point %d at %g years, want %g", i, points[i].Time, want)
+		}
+	}
+	if !(points[0].Lon < points[1].Lon && points[1].Lon < points[2].Lon) {
+		t.Errorf("track longitudes %.1f°, %.1f°, %.1f°, want the plate's eastward drift", points[0].Lon, points[1].Lon, points[2].Lon)
+	}
+	if lat, lon, _ := pm.plateCentroid(plate); points[2].Lat != lat || points[2].Lon != lon {
+		t.Errorf("newest point at %.2f°, %.2f°, want the centroid %.2f°, %.2f°", points[2].Lat, points[2].Lon, lat, lon)
+	}
+
+	// A fragment rifted off shares the parent's history
+	for lon := 300; lon < 310; lon++ {
+		shell.Voxels[shell.LatBands/2][lon].Type = core.MatWater
+	}
+	if created := pm.SplitDisconnectedPlates(); created != 1 {
+		t.Fatalf("created %d plates, want 1", created)
+	}
+	fragment := pm.Plates[1]
+	tracks = pm.GetPlateTracks()
+	if len(tracks) != 2 || len(tracks[0].Points) != 3 || len(tracks[1].Points) != 3 || tracks[1].PlateID != fragment.ID {
+		t.Fatalf("tracks after the rift %+v, want plates %d and %d with 3 points each", tracks, plate.ID, fragment.ID)
+	}
+
+	// Re-identification hands out new IDs, but each plate keeps its track
+	pm.IdentifyPlates()
+	tracks = pm.GetPlateTracks()
+	if len(tracks) != len(pm.Plates) || len(tracks) != 2 {
+		t.Fatalf("%d tracks for %d re-identified plates, want 2", len(tracks), len(pm.Plates))
+	}
+	for _, track := range tracks {
+		if len(track.Points) != 3 || track.Points[2] != points[2] {
+			t.Errorf("plate %d lost its history: %+v", track.PlateID, track.Points)
+		}
+	}
+
+	// A destroyed plate's track goes with it, and the rest live in the planet for saving
+	gone := pm.Plates[1]
+	pm.discardPlate(gone)
+	if _, ok := pm.planet.PlateTracks[int32(gone.ID)]; ok || len(pm.planet.PlateTracks) != 1 {
+		t.Errorf("planet holds tracks for plates %v after plate %d was destroyed", pm.planet.PlateTracks, gone.ID)
+	}
+}
+
+// TestPlateTracksResumeAfterLoad checks a manager over a loaded planet picks
+// up its tracks and waits out the interval from their newest point
+func TestPlateTracksResumeAfterLoad(t *testing.T) {
+	planet := core.CreateVoxelPlanet(6371000.0, 20)
+	planet.Time = 5.5e6
+	planet.PlateTracks = map[int32][]core.PlateTrackPoint{1: {{Time: 5e6, Lat: 1, Lon: 2}}}
+	pm := NewPlateManager(planet)
+	pm.Plates = []*TectonicPlate{{ID: 1, MemberVoxels: []core.VoxelCoord{{Shell: len(planet.Shells) - 2, Lat: 10, Lon: 0}}}}
+
+	pm.recordPlateTracks()
+	if n := len(planet.PlateTracks[1]); n != 1 {
+		t.Errorf("track has %d points half an interval after loading, want 1", n)
+	}
+	planet.Time = 6e6
+	pm.recordPlateTracks()
+	if n := len(planet.PlateTracks[1]); n != 2 {
+		t.Errorf("track has %d points an interval after loading, want 2", n)
+	}
+}
@@ -0,0 +1,51 @@
+package simulation
+
+import (
+	"math"
+
+	"worldgenerator/core"
+)
+
+// eulerPole returns the unit vector along a plate's Euler pole, in the frame
+// applyPlateMotion positions voxels in
+func eulerPole(plate *TectonicPlate) core.Vector3 {
+	poleLat := plate.EulerPoleLat * math.Pi / 180.0
+	poleLon := plate.EulerPoleLon * math.Pi / 180.0
+	return core.Vector3{
+		X: math.Cos(poleLat) * math.Cos(poleLon),
+		Y: math.Cos(poleLat) * math.Sin(poleLon),
+		Z: math.Sin(poleLat),
+	}
+}
+
+// referencePlate returns the plate the planet holds still, or nil when motion
+// is in the absolute frame
+func (pm *PlateManager) referencePlate() *TectonicPlate {
+	if pm.planet.ReferencePlate == 0 {
+		return nil
+	}
+	for _, plate := range pm.Plates {
+		if plate.ID == pm.planet.ReferencePlate {
+			return plate
+		}
+	}
+	return nil
+}
+
+// plateRotation returns the Euler pole unit vector and angular velocity a
+// plate's voxels move with. With a reference plate every rotation is relative
+// to it: subtracting its Euler vector from each plate's changes the frame the
+// way subtracting a velocity does on a plane, so the reference plate stands
+// still and every other plate keeps its motion relative to it. The plates'
+// own Euler poles stay in the absolute frame, so releasing the reference
+// resumes absolute motion
+func (pm *PlateManager) plateRotation(plate *TectonicPlate) (pole core.Vector3, angularVelocity float64) {
+	reference := pm.referencePlate()
+	if reference == nil {
+		return eulerPole(plate), plate.AngularVelocity
+	}
+
+	// Euler vectors: along the pole, as long as the angular velocity
+	relative := eulerPole(plate).Scale(plate.AngularVelocity).Sub(eulerPole(reference).Scale(reference.AngularVelocity))
+	return relative.Normalize(), relative.Length()
+}
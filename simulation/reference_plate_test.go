@@ -0,0 +1,48 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+)
+
+// TestReferencePlateHoldsStill checks holding a plate still stops it while
+// the other plate keeps its motion relative to it
+func TestReferencePlateHoldsStill(t *testing.T) {
+	pm, shell, surface := newOceanPlateManager()
+	west, east := addMovingPlates(pm, shell, surface, 0, 0, 0, 0)
+
+	// Both spin about the geographic pole, so at the equator the relative
+	// motion is just the difference in speed
+	radius := pm.planet.Radius
+	west.EulerPoleLat, west.AngularVelocity = 90, 0.03/radius
+	east.EulerPoleLat, east.AngularVelocity = 90, 0.08/radius
+
+	applyAll := func() {
+		for _, plate := range pm.Plates {
+			pm.applyPlateMotion(plate)
+		}
+	}
+	applyAll()
+	westAbsolute, eastAbsolute := pm.getAverageVelocity(west), pm.getAverageVelocity(east)
+	if westAbsolute.Length() == 0 || eastAbsolute.Length() == 0 {
+		t.Fatal("plates don't move in the absolute frame")
+	}
+
+	pm.planet.ReferencePlate = west.ID
+	applyAll()
+	westRelative, eastRelative := pm.getAverageVelocity(west), pm.getAverageVelocity(east)
+	if speed := westRelative.Length(); speed > 1e-9 {
+		t.Errorf("reference plate still moves at %g", speed)
+	}
+	want := eastAbsolute.Sub(westAbsolute)
+	if diff := eastRelative.Sub(want).Length(); diff > 1e-6*want.Length() {
+		t.Errorf("east plate moves at %+v relative to the reference, want %+v", eastRelative, want)
+	}
+
+	// Releasing the reference restores absolute motion
+	pm.planet.ReferencePlate = 0
+	applyAll()
+	if diff := pm.getAverageVelocity(west).Sub(westAbsolute).Length(); math.Abs(diff) > 1e-9 {
+		t.Errorf("west plate moves %g off its absolute velocity after release", diff)
+	}
+}
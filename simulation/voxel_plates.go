@@ -1,7 +1,6 @@
 package simulation
 
 import (
-	"fmt"
 	"math"
 
 	"worldgenerator/core"
@@ -46,6 +45,21 @@ type PlateManager struct {
 	BoundaryMap   map[core.VoxelCoord]bool // Quick lookup for boundary voxels
 	planet        *core.VoxelPlanet
 	nextPlateID   int
+	freeIDs       []int // IDs of destroyed plates, reused before new ones
+
+	// Plate topology - splitting at rifts, welding at sutures
+	MaxPlates       int                // Cap on plate count (0 = unlimited)
+	SutureWeldTime  float64            // Years a convergent boundary must persist to weld
+	MinSutureLength int                // Boundary voxels needed for a suture to weld
+	sutureAge       map[[2]int]float64 // Years each plate pair has been converging
+
+	// Display color of each plate lineage, saved with the planet
+	Colors *PlateColorRegistry
+
+	// Drift history of each plate, saved with the planet (see GetPlateTracks)
+	TrackInterval  float64 // Years between recorded centroids (0 = don't record)
+	MaxTrackPoints int     // Points kept per track, oldest dropped first (0 = unlimited)
+	tracks         *plateTrackLog
 	
 	// Advanced plate dynamics
 	forceCalculator *PlateForceCalculator
@@ -58,6 +72,17 @@ func NewPlateManager(planet *core.VoxelPlanet) *PlateManager {
 		VoxelPlateMap: make(map[core.VoxelCoord]int),
 		BoundaryMap:   make(map[core.VoxelCoord]bool),
 		nextPlateID:   1,
+
+		MaxPlates:       planet.PlateLimit(),
+		SutureWeldTime:  defaultSutureWeldTime,
+		MinSutureLength: defaultMinSutureLength,
+		sutureAge:       make(map[[2]int]float64),
+
+		Colors: newPlateColorRegistry(planet),
+
+		TrackInterval:  defaultTrackInterval,
+		MaxTrackPoints: defaultMaxTrackPoints,
+		tracks:         newPlateTrackLog(planet),
 	}
 }
 
@@ -99,8 +124,17 @@ func (pm *PlateManager) GetPlateBoundaries() []*PlateBoundary {
 
 // IdentifyPlates segments the lithosphere into discrete plates
 func (pm *PlateManager) IdentifyPlates() {
-	// Clear existing plates
+	// Plates so far, to carry their colors and tracks over to the plates found now
+	previousHues := pm.Colors.snapshot()
+	previousTracks := pm.tracks.snapshot()
+	previousIDs := pm.surfacePlateIDs()
+
+	// Clear existing plates, returning their IDs to the pool
+	for _, plate := range pm.Plates {
+		pm.releasePlateID(plate.ID)
+	}
 	pm.Plates = nil
+	pm.sutureAge = make(map[[2]int]float64)
 	pm.VoxelPlateMap = make(map[core.VoxelCoord]int)
 	pm.BoundaryMap = make(map[core.VoxelCoord]bool)
 
@@ -140,10 +174,17 @@ func (pm *PlateManager) IdentifyPlates() {
 			plate := pm.createPlateFromSeed(coord, visited)
 			if len(plate.MemberVoxels) > 100 { // Minimum size threshold
 				pm.Plates = append(pm.Plates, plate)
+			} else {
+				pm.discardPlate(plate)
 			}
 		}
 	}
 
+	pm.enforcePlateLimit()
+	ancestors := pm.plateAncestors(previousIDs)
+	pm.Colors.inherit(pm.Plates, ancestors, previousHues)
+	pm.tracks.inherit(pm.Plates, ancestors, previousTracks)
+
 	// Calculate plate properties
 	for _, plate := range pm.Plates {
 		pm.calculatePlateProperties(plate)
@@ -151,13 +192,53 @@ func (pm *PlateManager) IdentifyPlates() {
 	}
 }
 
+// surfacePlateIDs copies the plate ID of every surface voxel
+func (pm *PlateManager) surfacePlateIDs() [][]int32 {
+	surfaceShell := len(pm.planet.Shells) - 2
+	if surfaceShell < 0 {
+		return nil
+	}
+	shell := &pm.planet.Shells[surfaceShell]
+	ids := make([][]int32, len(shell.Voxels))
+	for latIdx := range shell.Voxels {
+		ids[latIdx] = make([]int32, len(shell.Voxels[latIdx]))
+		for lonIdx := range shell.Voxels[latIdx] {
+			ids[latIdx][lonIdx] = shell.Voxels[latIdx][lonIdx].PlateID
+		}
+	}
+	return ids
+}
+
+// plateAncestors maps each plate to the one most of its members belonged to
+// in previousIDs (see surfacePlateIDs), ties going to the lower ID. Plates
+// grown entirely from unplated voxels have none
+func (pm *PlateManager) plateAncestors(previousIDs [][]int32) map[int]int32 {
+	ancestors := make(map[int]int32, len(pm.Plates))
+	for _, plate := range pm.Plates {
+		counts := make(map[int32]int)
+		for _, coord := range plate.MemberVoxels {
+			if coord.Lat < len(previousIDs) && coord.Lon < len(previousIDs[coord.Lat]) {
+				if id := previousIDs[coord.Lat][coord.Lon]; id > 0 {
+					counts[id]++
+				}
+			}
+		}
+		best := int32(0)
+		for id, n := range counts {
+			if n > counts[best] || (n == counts[best] && id < best) {
+				best = id
+			}
+		}
+		if best != 0 {
+			ancestors[plate.ID] = best
+		}
+	}
+	return ancestors
+}
+
 // createPlateFromSeed grows a plate from a seed voxel using flood fill
 func (pm *PlateManager) createPlateFromSeed(seed core.VoxelCoord, visited map[core.VoxelCoord]bool) *TectonicPlate {
-	plate := &TectonicPlate{
-		ID:   pm.nextPlateID,
-		Name: fmt.Sprintf("Plate_%d", pm.nextPlateID),
-	}
-	pm.nextPlateID++
+	plate := pm.newPlate()
 
 	shell := &pm.planet.Shells[seed.Shell]
 	seedVoxel := &shell.Voxels[seed.Lat][seed.Lon]
@@ -222,7 +303,7 @@ func (pm *PlateManager) shouldBelongToPlate(coord core.VoxelCoord, refVelNorth,
 	}
 
 	// Check velocity similarity (within threshold)
-	velThreshold := float32(1e-6) // m/s
+	velThreshold := float32(1e-6) // m/year
 	thetaDiff := float32(math.Abs(float64(voxel.VelNorth - refVelNorth)))
 	phiDiff := float32(math.Abs(float64(voxel.VelEast - refVelEast)))
 
@@ -263,8 +344,8 @@ func (pm *PlateManager) calculatePlateProperties(plate *TectonicPlate) {
 
 	totalAge := float64(0)
 	totalThickness := float64(0)
-	continentalCount := 0
-	oceanicCount := 0
+	continentalArea := 0.0
+	oceanicArea := 0.0
 
 	// Center of mass for Euler pole calculation
 	var centerLat, centerLon float64
@@ -283,11 +364,11 @@ func (pm *PlateManager) calculatePlateProperties(plate *TectonicPlate) {
 		thickness := 100000.0 // 100 km default lithosphere thickness
 		totalThickness += thickness
 
-		// Plate type
+		// Plate type, by area so polar voxels don't outweigh the rest
 		if voxel.Type == core.MatGranite {
-			continentalCount++
+			continentalArea += pm.planet.VoxelArea(shell, coord.Lat)
 		} else if voxel.Type == core.MatBasalt {
-			oceanicCount++
+			oceanicArea += pm.planet.VoxelArea(shell, coord.Lat)
 		}
 
 		// Position for center calculation
@@ -303,9 +384,9 @@ func (pm *PlateManager) calculatePlateProperties(plate *TectonicPlate) {
 	plate.AverageThickness = totalThickness / n
 
 	// Plate type
-	if continentalCount > oceanicCount*2 {
+	if continentalArea > oceanicArea*2 {
 		plate.Type = "continental"
-	} else if oceanicCount > continentalCount*2 {
+	} else if oceanicArea > continentalArea*2 {
 		plate.Type = "oceanic"
 	} else {
 		plate.Type = "mixed"
@@ -353,12 +434,15 @@ func (pm *PlateManager) UpdatePlateMotion(dt float64) {
 	// Then update plate motion based on forces
 	for _, plate := range pm.Plates {
 		pm.updatePlateVelocity(plate, dt)
+		pm.applyConvectionForcing(plate)
 	}
 
 	// Finally, apply plate motion to member voxels
 	for _, plate := range pm.Plates {
 		pm.applyPlateMotion(plate)
 	}
+
+	pm.recordPlateTracks()
 }
 
 // calculatePlateForces is now handled by PlateForceCalculator
@@ -386,8 +470,8 @@ func (pm *PlateManager) updatePlateVelocity(plate *TectonicPlate, dt float64) {
 	// Update angular velocity
 	plate.AngularVelocity += angularAccel * dt
 
-	// Limit to realistic plate velocities (max ~20 cm/year at surface)
-	maxAngularVel := 0.2 / pm.planet.Radius // 20 cm/year
+	// Limit to realistic plate velocities at the surface, as the GPU path does
+	maxAngularVel := pm.maxAngularVelocity()
 	if math.Abs(plate.AngularVelocity) > maxAngularVel {
 		plate.AngularVelocity = maxAngularVel * math.Abs(plate.AngularVelocity) / plate.AngularVelocity
 	}
@@ -396,19 +480,22 @@ func (pm *PlateManager) updatePlateVelocity(plate *TectonicPlate, dt float64) {
 	// Simplified - in reality poles migrate slowly
 }
 
+// maxAngularVelocity returns the fastest a plate may spin in radians per
+// year, so its surface moves no faster than the planet's PlateSpeedLimit
+func (pm *PlateManager) maxAngularVelocity() float64 {
+	return pm.planet.PlateSpeedLimit() / 100.0 / pm.planet.Radius // cm/year to radians/year
+}
+
 // GetPlateVelocities returns angular velocities for all plates
 func (pm *PlateManager) GetPlateVelocities() map[int32][3]float32 {
 	velocities := make(map[int32][3]float32)
 
 	for _, plate := range pm.Plates {
-		// Convert Euler pole to radians
-		poleLat := plate.EulerPoleLat * math.Pi / 180.0
-		poleLon := plate.EulerPoleLon * math.Pi / 180.0
-
-		// Euler pole unit vector * angular velocity
-		poleX := float32(math.Cos(poleLat) * math.Cos(poleLon) * plate.AngularVelocity)
-		poleY := float32(math.Cos(poleLat) * math.Sin(poleLon) * plate.AngularVelocity)
-		poleZ := float32(math.Sin(poleLat) * plate.AngularVelocity)
+		// Euler pole unit vector * angular velocity, relative to any reference plate
+		pole, angularVelocity := pm.plateRotation(plate)
+		poleX := float32(pole.X * angularVelocity)
+		poleY := float32(pole.Y * angularVelocity)
+		poleZ := float32(pole.Z * angularVelocity)
 
 		velocities[int32(plate.ID)] = [3]float32{poleX, poleY, poleZ}
 	}
@@ -418,14 +505,9 @@ func (pm *PlateManager) GetPlateVelocities() map[int32][3]float32 {
 
 // applyPlateMotion applies rigid body rotation to all voxels in the plate
 func (pm *PlateManager) applyPlateMotion(plate *TectonicPlate) {
-	// Convert Euler pole to radians
-	poleLat := plate.EulerPoleLat * math.Pi / 180.0
-	poleLon := plate.EulerPoleLon * math.Pi / 180.0
-
-	// Euler pole unit vector
-	poleX := math.Cos(poleLat) * math.Cos(poleLon)
-	poleY := math.Cos(poleLat) * math.Sin(poleLon)
-	poleZ := math.Sin(poleLat)
+	// Euler pole unit vector, relative to any reference plate
+	pole, angularVelocity := pm.plateRotation(plate)
+	poleX, poleY, poleZ := pole.X, pole.Y, pole.Z
 
 	for _, coord := range plate.MemberVoxels {
 		shell := &pm.planet.Shells[coord.Shell]
@@ -460,7 +542,7 @@ func (pm *PlateManager) applyPlateMotion(plate *TectonicPlate) {
 		dotProduct := poleX*voxelX + poleY*voxelY + poleZ*voxelZ
 		sinAngle := math.Sqrt(1.0 - dotProduct*dotProduct)
 
-		velocity := plate.AngularVelocity * pm.planet.Radius * sinAngle
+		velocity := angularVelocity * pm.planet.Radius * sinAngle
 
 		// Cross product to get velocity direction
 		// v = pole × position
@@ -511,4 +593,4 @@ func (pm *PlateManager) getAverageVelocity(plate *TectonicPlate) core.Vector3 {
 
 // core.Vector3 is already defined in types.go
 
-// getLatitudeForBand is already defined in voxel_planet.go
+// core.GetLatitudeForBand is defined in coordinates.go
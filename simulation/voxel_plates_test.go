@@ -0,0 +1,96 @@
+package simulation
+
+import (
+	"math"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// TestPlateSpeedLimit pushes a plate far harder than any real force would:
+// it spins up only to the planet's MaxPlateVelocity, and convection forcing
+// can't drive it past that either
+func TestPlateSpeedLimit(t *testing.T) {
+	pm, shell, surface := newOceanPlateManager()
+	pm.planet.MaxPlateVelocity = 8
+	plate := pm.newPlate()
+	pm.Plates = []*TectonicPlate{plate}
+	addBlock(pm, shell, surface, plate, 100, 200)
+
+	speed := func() float64 { return math.Abs(plate.AngularVelocity) * pm.planet.Radius * 100 } // cm/year
+	plate.CollisionForce = core.Vector3{X: 1e30, Y: -1e30}
+	for step := 0; step < 10; step++ {
+		pm.updatePlateVelocity(plate, 1000)
+		if speed() > 8+1e-9 {
+			t.Fatalf("step %d: plate moving %.3g cm/year, past the 8 cm/year limit", step, speed())
+		}
+	}
+	if speed() < 8-1e-9 {
+		t.Errorf("plate pushed at 1e30 N moves %.3g cm/year, want the 8 cm/year limit", speed())
+	}
+
+	plate.AngularVelocity = 0
+	pm.planet.ConvectionForcing = 50
+	pm.applyConvectionForcing(plate)
+	if math.Abs(speed()-8) > 1e-9 {
+		t.Errorf("forcing at 50 cm/year moves the plate %.3g cm/year, want the 8 cm/year limit", speed())
+	}
+}
+
+// TestPlateVelocityInCmPerYear spins a plate at 5 cm/year about the spin
+// axis: the equatorial voxels it moves read back as 5 cm/year, the speed the
+// console and the velocity view report
+func TestPlateVelocityInCmPerYear(t *testing.T) {
+	pm, shell, surface := newOceanPlateManager()
+	plate := pm.newPlate()
+	pm.Plates = []*TectonicPlate{plate}
+	addBlock(pm, shell, surface, plate, 100, 110)
+	plate.BoundaryVoxels = nil // Move every voxel rigidly
+	plate.EulerPoleLat = 90
+	plate.AngularVelocity = 0.05 / pm.planet.Radius // 5 cm/year at the equator
+
+	pm.applyPlateMotion(plate)
+	for _, coord := range plate.MemberVoxels {
+		voxel := &shell.Voxels[coord.Lat][coord.Lon]
+		lat := core.GetLatitudeForBand(coord.Lat, shell.LatBands) * math.Pi / 180
+		got := core.CmPerYear(math.Hypot(float64(voxel.VelNorth), float64(voxel.VelEast)))
+		if want := 5 * math.Cos(lat); math.Abs(got-want) > 1e-3*want {
+			t.Fatalf("voxel %d,%d moves %.4g cm/year, want %.4g", coord.Lat, coord.Lon, got, want)
+		}
+	}
+}
+
+// TestConvectionForcingFollowsMantle heats the mantle under one end of a
+// forced plate: the plate drifts off the hot end toward the cold one, and
+// faster than the baseline forcing alone
+func TestConvectionForcingFollowsMantle(t *testing.T) {
+	for _, hotWest := range []bool{true, false} {
+		pm, shell, surface := newOceanPlateManager()
+		pm.planet.ConvectionForcing = 1
+		plate := pm.newPlate()
+		pm.Plates = []*TectonicPlate{plate}
+		addBlock(pm, shell, surface, plate, 100, 120)
+
+		for _, coord := range plate.MemberVoxels {
+			lat, lon := pm.planet.VoxelLatLon(coord)
+			below := &pm.planet.Shells[surface-1]
+			mantle, _ := pm.planet.VoxelAtGeographic(lat, lon, pm.planet.ShellAltitude((below.InnerRadius+below.OuterRadius)/2, lat))
+			mantle.Temperature = 2000
+			if (coord.Lon < 110) == hotWest {
+				mantle.Temperature = 3000
+			}
+		}
+
+		pm.applyConvectionForcing(plate)
+		middle := plate.MemberVoxels[len(plate.MemberVoxels)/2]
+		lon := float64(middle.Lon) * 2 * math.Pi / float64(len(shell.Voxels[middle.Lat]))
+		r := core.Vector3{X: math.Cos(lon), Y: math.Sin(lon)} // On the equator
+		east := eulerPole(plate).Cross(r).Dot(core.Vector3{X: -math.Sin(lon), Y: math.Cos(lon)})
+		if (east > 0) != hotWest {
+			t.Errorf("hot west %v: plate middle moves %.3g of its speed east, want away from the hot mantle", hotWest, east)
+		}
+		if speed := math.Abs(plate.AngularVelocity) * pm.planet.Radius * 100; speed <= 1 {
+			t.Errorf("hot west %v: plate moves %.3g cm/year, want faster than the 1 cm/year forcing", hotWest, speed)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"worldgenerator/core"
+	"worldgenerator/gpu"
+	"worldgenerator/physics"
+)
+
+// spinupBarWidth is the number of characters in the progress bar
+const spinupBarWidth = 40
+
+// runSpinup ages the planet to targetYear before the window opens
+func runSpinup(planet *core.VoxelPlanet, targetYear, stepYears float64, gpuCompute gpu.GPUCompute) {
+	fmt.Printf("Spinning up to %.1f My in steps of %.0f years...\n", targetYear/1e6, stepYears)
+
+	start := time.Now()
+	startYear := planet.Time
+	lastDraw := time.Time{}
+
+	steps := physics.SpinUp(planet, targetYear, stepYears, gpuCompute, func(year float64) {
+		// Redrawing every step would dominate fast steps
+		if time.Since(lastDraw) < 100*time.Millisecond && year < targetYear {
+			return
+		}
+		lastDraw = time.Now()
+		printProgress(start, (year-startYear)/(targetYear-startYear), year)
+	})
+
+	if planet.Halted() {
+		fmt.Printf("\n❌ Spin-up halted at %.1f My after %d steps\n", planet.Time/1e6, steps)
+		return
+	}
+	fmt.Printf("\n✅ Spin-up complete: %d steps in %v\n", steps, time.Since(start).Round(time.Millisecond))
+}
+
+// printProgress redraws the progress bar line for a run that is fraction
+// done at year and began at start
+func printProgress(start time.Time, fraction, year float64) {
+	fraction = math.Max(0, math.Min(1, fraction))
+	filled := int(fraction * spinupBarWidth)
+	eta := time.Duration(0)
+	if fraction > 0 {
+		eta = time.Duration(float64(time.Since(start)) * (1 - fraction) / fraction)
+	}
+
+	fmt.Printf("\r[%s%s] %5.1f%%  %8.1f My  ETA %v   ",
+		strings.Repeat("#", filled), strings.Repeat("-", spinupBarWidth-filled),
+		fraction*100, year/1e6, eta.Round(time.Second))
+}
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// raiseLand turns the surface voxels inside a lat/lon box into land
+// lonMin may be greater than lonMax to cross the antimeridian
+// Returns the number of distinct voxels raised
+func raiseLand(planet *core.VoxelPlanet, latMin, latMax, lonMin, lonMax float64) int {
+	raised := make(map[core.VoxelCoord]bool)
+	lonSpan := lonMax - lonMin
+	if lonSpan < 0 {
+		lonSpan += 360.0
+	}
+
+	for lat := latMin; lat <= latMax; lat += 0.1 {
+		for dLon := 0.0; dLon <= lonSpan; dLon += 0.1 {
+			voxel, coord := planet.VoxelAtGeographic(lat, lonMin+dLon, -1000.0)
+			if voxel == nil {
+				continue
+			}
+			voxel.Type = core.MatGranite
+			voxel.Elevation = 500
+			raised[coord] = true
+		}
+	}
+	return len(raised)
+}
+
+// TestIdentifyContinentsSeparateMasses checks two distant landmasses stay separate
+func TestIdentifyContinentsSeparateMasses(t *testing.T) {
+	planet := core.CreateVoxelPlanet(6371000.0, 8)
+
+	// Large mass straddling the antimeridian, smaller one in the northern hemisphere
+	bigCount := raiseLand(planet, -10, 10, 170, -170)
+	smallCount := raiseLand(planet, 30, 40, 0, 10)
+
+	continents := core.IdentifyContinents(planet)
+	if len(continents) != 2 {
+		t.Fatalf("got %d continents, want 2", len(continents))
+	}
+
+	big, small := continents[0], continents[1]
+	if big.ID != 1 || small.ID != 2 {
+		t.Errorf("IDs not ordered by size: got %d, %d", big.ID, small.ID)
+	}
+	if big.VoxelCount != bigCount {
+		t.Errorf("antimeridian mass: got %d voxels, want %d", big.VoxelCount, bigCount)
+	}
+	if small.VoxelCount != smallCount {
+		t.Errorf("northern mass: got %d voxels, want %d", small.VoxelCount, smallCount)
+	}
+
+	// The antimeridian mass must wrap rather than span the whole globe
+	if big.MinLon <= big.MaxLon {
+		t.Errorf("antimeridian mass bounds %.1f..%.1f should wrap past 180", big.MinLon, big.MaxLon)
+	}
+	if big.CentroidLon > -175 && big.CentroidLon < 175 {
+		t.Errorf("antimeridian mass centroid lon %.1f, want near 180", big.CentroidLon)
+	}
+	if small.CentroidLat < 30 || small.CentroidLat > 40 {
+		t.Errorf("northern mass centroid lat %.1f, want 30..40", small.CentroidLat)
+	}
+}
+
+// TestIdentifyContinentsAcrossPole checks land on opposite sides of a pole is one mass
+func TestIdentifyContinentsAcrossPole(t *testing.T) {
+	planet := core.CreateVoxelPlanet(6371000.0, 8)
+
+	raiseLand(planet, 88, 90, 0, 10)
+	raiseLand(planet, 88, 90, 180, -170)
+
+	continents := core.IdentifyContinents(planet)
+	if len(continents) != 1 {
+		t.Fatalf("got %d continents, want 1 joined at the pole", len(continents))
+	}
+}
+
+// TestIdentifyContinentsIgnoresSubmerged checks rock below sea level is not land
+func TestIdentifyContinentsIgnoresSubmerged(t *testing.T) {
+	planet := core.CreateVoxelPlanet(6371000.0, 8)
+
+	raiseLand(planet, -5, 5, 40, 50)
+	raiseLand(planet, -5, 5, -50, -40)
+
+	planet.SeaLevel = 1000 // Drowns everything raised to 500 m
+	if continents := core.IdentifyContinents(planet); len(continents) != 0 {
+		t.Errorf("got %d continents below sea level, want 0", len(continents))
+	}
+
+	planet.SeaLevel = 0
+	if continents := core.IdentifyContinents(planet); len(continents) != 2 {
+		t.Errorf("got %d continents at sea level 0, want 2", len(continents))
+	}
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// axisCoord returns the component of v along axis
+func axisCoord(v core.Vector3, axis int) float64 {
+	switch axis {
+	case 0:
+		return v.X
+	case 1:
+		return v.Y
+	default:
+		return v.Z
+	}
+}
+
+// TestCrossSectionCutPlane checks cut face vertices lie on the requested plane
+// and the surface stays on the kept side
+func TestCrossSectionCutPlane(t *testing.T) {
+	planet := core.CreateVoxelPlanet(6371000.0, 8)
+	tolerance := planet.Radius * 1e-9
+
+	for axis := 0; axis < 3; axis++ {
+		for _, position := range []float64{0, 0.5 * planet.Radius, -0.9 * planet.Radius} {
+			mesh, err := core.ExportCrossSectionMesh(planet, axis, position)
+			if err != nil {
+				t.Fatalf("axis %d at %.0f: %v", axis, position, err)
+			}
+
+			cutMaterials := make(map[core.MaterialType]bool)
+			cutFaces := 0
+			for _, face := range mesh.Faces {
+				for _, idx := range face.V {
+					v := mesh.Vertices[idx]
+					along := axisCoord(v, axis)
+					if face.Cut && math.Abs(along-position) > tolerance {
+						t.Fatalf("axis %d at %.0f: cut vertex %v is %.3f m off the plane",
+							axis, position, v, along-position)
+					}
+					if !face.Cut && along < position-tolerance {
+						t.Fatalf("axis %d at %.0f: surface vertex %v is on the removed side", axis, position, v)
+					}
+				}
+				if face.Cut {
+					cutFaces++
+					cutMaterials[face.Material] = true
+				}
+			}
+
+			if cutFaces == 0 || cutFaces == len(mesh.Faces) {
+				t.Errorf("axis %d at %.0f: %d of %d faces on the cut, want both cut and surface faces",
+					axis, position, cutFaces, len(mesh.Faces))
+			}
+			if position == 0 && len(cutMaterials) < 2 {
+				t.Errorf("axis %d: cut through the center shows %d materials, want mantle and crust", axis, len(cutMaterials))
+			}
+		}
+	}
+
+	if _, err := core.ExportCrossSectionMesh(planet, 0, planet.Radius); err == nil {
+		t.Error("expected an error for a plane outside the planet")
+	}
+	if _, err := core.ExportCrossSectionMesh(planet, 3, 0); err == nil {
+		t.Error("expected an error for an invalid axis")
+	}
+}
+
+// TestCrossSectionOBJ checks the OBJ groups faces by material with a matching .mtl
+func TestCrossSectionOBJ(t *testing.T) {
+	planet := core.CreateVoxelPlanet(6371000.0, 8)
+	mesh, err := core.ExportCrossSectionMesh(planet, 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "section.obj")
+	if err := mesh.WriteOBJ(path); err != nil {
+		t.Fatal(err)
+	}
+
+	obj, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mtl, err := os.ReadFile(strings.TrimSuffix(path, ".obj") + ".mtl")
+	if err != nil {
+		t.Fatalf("missing material library: %v", err)
+	}
+
+	if !strings.Contains(string(obj), "mtllib section.mtl") {
+		t.Error("OBJ does not reference its material library")
+	}
+	faces := strings.Count(string(obj), "\nf ")
+	if faces != len(mesh.Faces) {
+		t.Errorf("OBJ has %d faces, mesh has %d", faces, len(mesh.Faces))
+	}
+	for _, line := range strings.Split(string(obj), "\n") {
+		if name, ok := strings.CutPrefix(line, "usemtl "); ok {
+			if !strings.Contains(string(mtl), "newmtl "+name+"\n") {
+				t.Errorf("material %q used but not defined", name)
+			}
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package tests
+
+import (
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// TestEndConditionParse checks the flag syntax and rejects malformed conditions
+func TestEndConditionParse(t *testing.T) {
+	cond, err := core.ParseEndCondition("until:year>=1e9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cond.Stat != "year" || cond.Op != ">=" || cond.Threshold != 1e9 {
+		t.Errorf("parsed %+v, want year >= 1e9", *cond)
+	}
+	if cond.String() != "until:year>=1e+09" {
+		t.Errorf("formatted as %q", cond.String())
+	}
+
+	// The prefix is optional and negative thresholds keep their sign
+	cond, err = core.ParseEndCondition("seaLevel<-50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cond.Op != "<" || cond.Threshold != -50 {
+		t.Errorf("parsed %+v, want seaLevel < -50", *cond)
+	}
+
+	for _, bad := range []string{"until:", "until:plates==1", "until:year>soon", "continents"} {
+		if _, err := core.ParseEndCondition(bad); err == nil {
+			t.Errorf("%q accepted", bad)
+		}
+	}
+}
+
+// TestEndConditionYear checks a year condition fires once time reaches it
+func TestEndConditionYear(t *testing.T) {
+	planet := core.CreateVoxelPlanet(6371000.0, 4)
+	cond, err := core.ParseEndCondition("until:year>=1e9")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	planet.Time = 0.999e9
+	if cond.Met(planet) {
+		t.Error("fired before 1 Gy")
+	}
+	planet.Time = 1e9
+	if !cond.Met(planet) {
+		t.Error("didn't fire at 1 Gy")
+	}
+}
+
+// TestEndConditionSeaLevel checks a sea level condition fires only above the
+// threshold
+func TestEndConditionSeaLevel(t *testing.T) {
+	planet := core.CreateVoxelPlanet(6371000.0, 4)
+	cond, err := core.ParseEndCondition("until:seaLevel>50")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	planet.SeaLevel = 50
+	if cond.Met(planet) {
+		t.Error("fired at 50 m")
+	}
+	planet.SeaLevel = 50.5
+	if !cond.Met(planet) {
+		t.Error("didn't fire at 50.5 m")
+	}
+}
+
+// TestEndConditionContinents checks a supercontinent condition fires when two
+// landmasses join into one
+func TestEndConditionContinents(t *testing.T) {
+	planet := core.CreateVoxelPlanet(6371000.0, 8)
+	cond, err := core.ParseEndCondition("until:continents==1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cond.Met(planet) {
+		t.Error("fired with no land")
+	}
+	raiseLand(planet, -10, 10, 0, 20)
+	raiseLand(planet, -10, 10, 40, 60)
+	if got := cond.Value(planet); got != 2 {
+		t.Fatalf("counted %g continents, want 2", got)
+	}
+	if cond.Met(planet) {
+		t.Error("fired with two continents")
+	}
+
+	// Bridge the gap
+	raiseLand(planet, -2, 2, 20, 40)
+	if !cond.Met(planet) {
+		t.Errorf("didn't fire once joined (%g continents)", cond.Value(planet))
+	}
+}
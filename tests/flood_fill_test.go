@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// isMagma accepts the marker material used by the flood fill tests
+func isMagma(voxel *core.VoxelMaterial) bool {
+	return voxel.Type == core.MatMagma
+}
+
+// surfaceShell returns the surface shell of a fresh planet and its index
+func surfaceShell() (*core.SphericalShell, int) {
+	planet := core.CreateVoxelPlanet(6371000.0, 8)
+	shellIdx := len(planet.Shells) - 2
+	return &planet.Shells[shellIdx], shellIdx
+}
+
+// TestSphericalFloodFillWrapsLongitude checks a region crossing lon index 0 is one region
+func TestSphericalFloodFillWrapsLongitude(t *testing.T) {
+	shell, shellIdx := surfaceShell()
+	latIdx := shell.LatBands / 2
+	lonCount := shell.LonCounts[latIdx]
+
+	for _, lon := range []int{lonCount - 2, lonCount - 1, 0, 1} {
+		shell.Voxels[latIdx][lon].Type = core.MatMagma
+	}
+
+	region := core.SphericalFloodFill(shell, core.VoxelCoord{Shell: shellIdx, Lat: latIdx, Lon: 1}, isMagma)
+	if len(region) != 4 {
+		t.Fatalf("got %d voxels, want 4 across the wrap", len(region))
+	}
+	for _, coord := range region {
+		if coord.Shell != shellIdx || coord.Lat != latIdx {
+			t.Errorf("unexpected voxel %+v in region", coord)
+		}
+	}
+}
+
+// TestSphericalFloodFillAcrossPole checks bands of different resolution connect
+// by overlap and that the polar band connects through the pole
+func TestSphericalFloodFillAcrossPole(t *testing.T) {
+	shell, shellIdx := surfaceShell()
+	if shell.LonCounts[0] == shell.LonCounts[1] {
+		t.Fatalf("test needs bands of different resolution near the pole")
+	}
+
+	// Pick voxels in band 1 on opposite sides, and the band 0 voxels they overlap
+	west := 0
+	east := shell.LonCounts[1] / 2
+	overlap := func(lon int) int {
+		return lon * shell.LonCounts[0] / shell.LonCounts[1]
+	}
+	marked := []core.VoxelCoord{
+		{Shell: shellIdx, Lat: 1, Lon: west},
+		{Shell: shellIdx, Lat: 0, Lon: overlap(west)},
+		{Shell: shellIdx, Lat: 0, Lon: overlap(east)},
+		{Shell: shellIdx, Lat: 1, Lon: east},
+	}
+	for _, coord := range marked {
+		shell.Voxels[coord.Lat][coord.Lon].Type = core.MatMagma
+	}
+
+	// An isolated voxel two bands up must not join
+	shell.Voxels[3][0].Type = core.MatMagma
+
+	region := core.SphericalFloodFill(shell, marked[0], isMagma)
+	if len(region) != len(marked) {
+		t.Fatalf("got %d voxels, want %d joined through the pole", len(region), len(marked))
+	}
+	found := make(map[core.VoxelCoord]bool)
+	for _, coord := range region {
+		found[coord] = true
+	}
+	for _, coord := range marked {
+		if !found[coord] {
+			t.Errorf("voxel %+v missing from region", coord)
+		}
+	}
+}
+
+// TestSphericalFloodFillRejectedStart checks an unaccepted start yields no region
+func TestSphericalFloodFillRejectedStart(t *testing.T) {
+	shell, shellIdx := surfaceShell()
+
+	region := core.SphericalFloodFill(shell, core.VoxelCoord{Shell: shellIdx, Lat: 10, Lon: 0}, isMagma)
+	if region != nil {
+		t.Errorf("got %d voxels from a rejected start, want none", len(region))
+	}
+}
@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// TestSurfaceHeatFlux checks the flux follows Fourier's law across the two
+// outer rock shells, so a hot ridge outshines a cold craton
+func TestSurfaceHeatFlux(t *testing.T) {
+	planet := core.CreateVoxelPlanet(6371000.0, 8)
+	surfaceIdx := len(planet.Shells) - 2
+
+	// A ridge with hot mantle underneath and a craton over cool lithosphere
+	setColumn := func(lat, lon float64, surfaceTemp, belowTemp float32) {
+		surface, _ := planet.VoxelAtGeographic(lat, lon, -1)
+		surface.Type = core.MatBasalt
+		surface.Temperature = surfaceTemp
+
+		below, coord := planet.VoxelAtGeographic(lat, lon, -planet.Radius+planet.Shells[surfaceIdx-1].InnerRadius+1)
+		if coord.Shell != surfaceIdx-1 {
+			t.Fatalf("lookup below (%.0f, %.0f) landed in shell %d, want %d", lat, lon, coord.Shell, surfaceIdx-1)
+		}
+		below.Type = core.MatPeridotite
+		below.Temperature = belowTemp
+	}
+	setColumn(0, 30, 600, 1600)
+	setColumn(45, -90, 290, 400)
+
+	upper := planet.Shells[surfaceIdx]
+	lower := planet.Shells[surfaceIdx-1]
+	dr := (upper.InnerRadius+upper.OuterRadius)/2 - (lower.InnerRadius+lower.OuterRadius)/2
+	k := float64(core.MaterialProperties[core.MatPeridotite].ThermalConductivity)
+
+	ridge := planet.SurfaceHeatFlux(0, 30)
+	if want := k * 1000 / dr; math.Abs(ridge-want)/want > 1e-6 {
+		t.Errorf("ridge flux %.4g W/m², want k·ΔT/Δr = %.4g W/m²", ridge, want)
+	}
+	craton := planet.SurfaceHeatFlux(45, -90)
+	if craton <= 0 || craton >= ridge {
+		t.Errorf("craton flux %.4g W/m² should be positive and below the ridge's %.4g W/m²", craton, ridge)
+	}
+
+	// The innermost shell has nothing beneath it
+	if q := planet.HeatFlux(0, 0, 30); q != 0 {
+		t.Errorf("innermost shell flux %.4g W/m², want 0", q)
+	}
+}
@@ -0,0 +1,69 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// setTwoLevelSurface puts bands at or above highFrom at 500 m and everything else at -4000 m
+func setTwoLevelSurface(planet *core.VoxelPlanet, highFrom int) {
+	shell := &planet.Shells[len(planet.Shells)-2]
+	for latIdx := range shell.Voxels {
+		for lonIdx := range shell.Voxels[latIdx] {
+			voxel := &shell.Voxels[latIdx][lonIdx]
+			if latIdx >= highFrom {
+				voxel.Type = core.MatGranite
+				voxel.Elevation = 500
+			} else {
+				voxel.Type = core.MatWater
+				voxel.Elevation = -4000
+			}
+		}
+	}
+}
+
+// TestHypsometricCurveStep checks a two-level surface gives a single step
+func TestHypsometricCurveStep(t *testing.T) {
+	planet := core.CreateVoxelPlanet(6371000.0, 8)
+	shell := &planet.Shells[len(planet.Shells)-2]
+	setTwoLevelSurface(planet, shell.LatBands/2) // Northern hemisphere high
+
+	curve := core.HypsometricCurve(planet, 10)
+	if len(curve) != 10 {
+		t.Fatalf("got %d bins, want 10", len(curve))
+	}
+	if curve[0].Elevation <= -4000 || curve[len(curve)-1].Elevation != 500 {
+		t.Errorf("curve spans %.0f..%.0f m, want (-4000, 500]", curve[0].Elevation, curve[len(curve)-1].Elevation)
+	}
+
+	for i, bin := range curve[:len(curve)-1] {
+		if math.Abs(bin.Fraction-0.5) > 1e-9 {
+			t.Errorf("bin %d at %.0f m: fraction %.4f, want 0.5 (ocean floor only)", i, bin.Elevation, bin.Fraction)
+		}
+	}
+	if last := curve[len(curve)-1]; math.Abs(last.Fraction-1) > 1e-9 {
+		t.Errorf("top bin fraction %.4f, want 1", last.Fraction)
+	}
+}
+
+// TestHypsometricCurveAreaWeighting checks polar voxels count for less area
+func TestHypsometricCurveAreaWeighting(t *testing.T) {
+	planet := core.CreateVoxelPlanet(6371000.0, 8)
+	shell := &planet.Shells[len(planet.Shells)-2]
+
+	// Raise a cap north of 72°N
+	highFrom := shell.LatBands * 9 / 10
+	setTwoLevelSurface(planet, highFrom)
+
+	// Area of a cap above latitude φ is (1 - sin φ) / 2 of the sphere, and
+	// the cap starts halfway between its first band and the one below
+	edge := (core.GetLatitudeForBand(highFrom, shell.LatBands) - 90.0/float64(shell.LatBands-1)) * math.Pi / 180.0
+	want := 1 - (1-math.Sin(edge))/2
+
+	curve := core.HypsometricCurve(planet, 4)
+	if math.Abs(curve[0].Fraction-want) > 1e-9 {
+		t.Errorf("ocean floor fraction %.5f, want %.5f from spherical cap area", curve[0].Fraction, want)
+	}
+}
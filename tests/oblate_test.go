@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// TestOblateRoundTrip checks converting to Cartesian and back recovers the
+// geodetic latitude, longitude and altitude on flattened bodies, from Earth
+// to one squashed far past any real planet, poles and equator included
+func TestOblateRoundTrip(t *testing.T) {
+	const radius = 6378137.0
+	for _, flattening := range []float64{core.EarthFlattening, 0.1, 0.3} {
+		for _, lat := range []float64{-90, -60, -23.5, 0, 0.001, 45, 89.999, 90} {
+			for _, alt := range []float64{-2.9e6, -11000, 0, 8848, 400000} {
+				lon := 90.0 - lat
+				g := core.Geographic{Lat: core.DegreesToRadians(lat), Lon: core.DegreesToRadians(lon), Alt: alt}
+				back := core.CartesianToGeographic(core.GeographicToCartesian(g, radius, flattening), radius, flattening)
+
+				gotLat, gotLon := core.RadiansToDegrees(back.Lat), core.RadiansToDegrees(back.Lon)
+				lonOK := math.Abs(lat) == 90 || math.Abs(gotLon-lon) < 1e-9
+				if math.Abs(gotLat-lat) > 1e-9 || !lonOK || math.Abs(back.Alt-alt) > 1e-6 {
+					t.Errorf("f=%.5f: %.3f°, %.3f°, %.0f m came back as %.9f°, %.9f°, %.6f m",
+						flattening, lat, lon, alt, gotLat, gotLon, back.Alt)
+				}
+			}
+		}
+	}
+}
+
+// TestOblateShape checks a flattened surface reaches the equatorial radius
+// at the equator and falls short by the flattening at the poles, and that
+// zero flattening is still the sphere
+func TestOblateShape(t *testing.T) {
+	const radius = 6378137.0
+	pole := core.GeographicToCartesian(core.Geographic{Lat: math.Pi / 2}, radius, core.EarthFlattening)
+	if want := radius * (1 - core.EarthFlattening); math.Abs(pole.Y-want) > 1e-6 {
+		t.Errorf("north pole %.3f m from the center, want %.3f m", pole.Y, want)
+	}
+	if radius-pole.Y < 21000 || radius-pole.Y > 22000 {
+		t.Errorf("Earth's poles %.0f m closer than its equator, want about 21 km", radius-pole.Y)
+	}
+	equator := core.GeographicToCartesian(core.Geographic{Lon: math.Pi / 2}, radius, core.EarthFlattening)
+	if math.Abs(equator.Z-radius) > 1e-6 {
+		t.Errorf("equator %.3f m from the center, want %.3f m", equator.Z, radius)
+	}
+
+	planet := core.CreateVoxelPlanet(radius, 8)
+	planet.Flattening = 0.3
+	shell := &planet.Shells[len(planet.Shells)-2]
+	if got, want := planet.ShellRadiusAt(shell.OuterRadius, 90), shell.OuterRadius*0.7; math.Abs(got-want) > 1e-6 {
+		t.Errorf("surface shell reaches %.3f m at the pole, want %.3f m", got, want)
+	}
+	if got := planet.ShellRadiusAt(shell.OuterRadius, 0); math.Abs(got-shell.OuterRadius) > 1e-6 {
+		t.Errorf("surface shell reaches %.3f m at the equator, want %.3f m", got, shell.OuterRadius)
+	}
+
+	g := core.Geographic{Lat: core.DegreesToRadians(37), Lon: core.DegreesToRadians(-122), Alt: 500}
+	sphere := core.GeographicToCartesian(g, radius, 0)
+	if r := math.Sqrt(sphere.X*sphere.X + sphere.Y*sphere.Y + sphere.Z*sphere.Z); math.Abs(r-radius-500) > 1e-6 {
+		t.Errorf("point 500 m above a sphere is %.3f m from the center, want %.3f m", r, radius+500)
+	}
+}
+
+// TestOblateLookupAndArea checks a flattened planet's lookups find the shell
+// a point lies in near the poles, where shells close in toward the center,
+// and that surface cell areas add up to the spheroid, not the sphere
+func TestOblateLookupAndArea(t *testing.T) {
+	planet := core.CreateVoxelPlanet(6378137.0, 8)
+	planet.Flattening = 0.3
+
+	for i := range planet.Shells {
+		shell := &planet.Shells[i]
+		mid := (shell.InnerRadius + shell.OuterRadius) / 2
+		point := core.GeographicToCartesian(core.Geographic{Lat: core.DegreesToRadians(80), Lon: core.DegreesToRadians(10)}, mid, planet.Flattening)
+		g := core.CartesianToGeographic(point, planet.Radius, planet.Flattening)
+		if _, coord := planet.VoxelAtGeographic(core.RadiansToDegrees(g.Lat), core.RadiansToDegrees(g.Lon), g.Alt); coord.Shell != i {
+			t.Errorf("middle of shell %d at 80° found in shell %d", i, coord.Shell)
+		}
+	}
+
+	shell := &planet.Shells[len(planet.Shells)-2]
+	total := 0.0
+	for lat := range shell.Voxels {
+		total += planet.VoxelArea(shell, lat) * float64(len(shell.Voxels[lat]))
+	}
+	e2 := planet.Flattening * (2 - planet.Flattening)
+	e := math.Sqrt(e2)
+	want := 2 * math.Pi * shell.OuterRadius * shell.OuterRadius * (1 + (1-e2)/e*math.Atanh(e))
+	if math.Abs(total-want)/want > 1e-9 {
+		t.Errorf("surface cells cover %.6e m², want the spheroid's %.6e m²", total, want)
+	}
+}
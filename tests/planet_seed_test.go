@@ -0,0 +1,33 @@
+package tests
+
+import (
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// TestGeneratedPlanetReportsSeed checks a randomized planet remembers the seed it was built from
+func TestGeneratedPlanetReportsSeed(t *testing.T) {
+	for _, seed := range []int64{42, -7, 1754000000} {
+		planet, err := core.CreateRandomizedPlanet(6371000.0, 5, core.PlanetGenerationParams{
+			Seed:               seed,
+			ContinentCount:     3,
+			OceanFraction:      0.7,
+			MinContinentSize:   0.02,
+			MaxContinentSize:   0.1,
+			ContinentRoughness: 0.5,
+			SurfaceBands:       60,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := planet.Seed(); got != seed {
+			t.Errorf("planet generated with seed %d reports seed %d", seed, got)
+		}
+	}
+
+	// Planets built without a seed report none
+	if seed := core.CreateVoxelPlanet(6371000.0, 5).Seed(); seed != 0 {
+		t.Errorf("unseeded planet reports seed %d", seed)
+	}
+}
@@ -0,0 +1,30 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// TestVoxelAreaCoversSphere checks the surface cell areas add up to the whole
+// sphere and shrink toward the poles
+func TestVoxelAreaCoversSphere(t *testing.T) {
+	planet := core.CreateVoxelPlanet(6371000.0, 8)
+	shell := &planet.Shells[len(planet.Shells)-2]
+
+	total := 0.0
+	for lat := range shell.Voxels {
+		total += planet.VoxelArea(shell, lat) * float64(len(shell.Voxels[lat]))
+	}
+	want := 4 * math.Pi * shell.OuterRadius * shell.OuterRadius
+	if math.Abs(total-want)/want > 1e-9 {
+		t.Errorf("surface cells cover %.6e m², want 4πr² = %.6e m²", total, want)
+	}
+
+	polar := planet.VoxelArea(shell, 0)
+	equatorial := planet.VoxelArea(shell, shell.LatBands/2)
+	if polar >= equatorial {
+		t.Errorf("polar cell %.3e m² is not smaller than equatorial cell %.3e m²", polar, equatorial)
+	}
+}
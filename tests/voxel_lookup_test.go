@@ -0,0 +1,288 @@
+package tests
+
+import (
+	"math"
+	"testing"
+
+	"worldgenerator/core"
+)
+
+// newLookupPlanet creates a small planet for coordinate lookup tests
+func newLookupPlanet(t *testing.T) *core.VoxelPlanet {
+	t.Helper()
+	return core.CreateVoxelPlanet(6371000.0, 8)
+}
+
+// TestVoxelAtGeographicMatchesGetVoxel checks the returned pointer and coordinate agree
+func TestVoxelAtGeographicMatchesGetVoxel(t *testing.T) {
+	planet := newLookupPlanet(t)
+
+	voxel, coord := planet.VoxelAtGeographic(12.5, -47.0, -1000.0)
+	if voxel == nil {
+		t.Fatal("expected a voxel 1 km below the surface")
+	}
+	if voxel != planet.GetVoxel(coord) {
+		t.Errorf("returned voxel does not match GetVoxel(%+v)", coord)
+	}
+	if coord.Shell != len(planet.Shells)-2 {
+		t.Errorf("1 km depth: got shell %d, want surface shell %d", coord.Shell, len(planet.Shells)-2)
+	}
+}
+
+// TestVoxelAtGeographicPoles checks the poles map to the first and last bands
+func TestVoxelAtGeographicPoles(t *testing.T) {
+	planet := newLookupPlanet(t)
+	surface := len(planet.Shells) - 2
+	latBands := planet.Shells[surface].LatBands
+
+	tests := []struct {
+		name     string
+		lat      float64
+		wantBand int
+	}{
+		{"North Pole", 90.0, latBands - 1},
+		{"South Pole", -90.0, 0},
+		{"Near North Pole", 89.99, latBands - 1},
+		{"Near South Pole", -89.99, 0},
+		{"Beyond North Pole", 95.0, latBands - 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			for lon := -180.0; lon <= 180.0; lon += 45.0 {
+				voxel, coord := planet.VoxelAtGeographic(tc.lat, lon, -1000.0)
+				if voxel == nil {
+					t.Fatalf("lon %.0f: got nil voxel", lon)
+				}
+				if coord.Lat != tc.wantBand {
+					t.Errorf("lon %.0f: got band %d, want %d", lon, coord.Lat, tc.wantBand)
+				}
+				if coord.Lon < 0 || coord.Lon >= planet.Shells[surface].LonCounts[coord.Lat] {
+					t.Errorf("lon %.0f: index %d out of range for band", lon, coord.Lon)
+				}
+			}
+		})
+	}
+}
+
+// TestVoxelAtGeographicLongitudeWrap checks longitudes outside [-180, 180) wrap around
+func TestVoxelAtGeographicLongitudeWrap(t *testing.T) {
+	planet := newLookupPlanet(t)
+
+	tests := []struct {
+		name      string
+		lon       float64
+		sameAsLon float64
+	}{
+		{"Antimeridian", 180.0, -180.0},
+		{"Past Antimeridian", 190.0, -170.0},
+		{"Full Turn West", -540.0, -180.0},
+		{"Full Turn East", 405.0, 45.0},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, got := planet.VoxelAtGeographic(30.0, tc.lon, -1000.0)
+			_, want := planet.VoxelAtGeographic(30.0, tc.sameAsLon, -1000.0)
+			if got != want {
+				t.Errorf("lon %.1f: got %+v, want %+v (lon %.1f)", tc.lon, got, want, tc.sameAsLon)
+			}
+		})
+	}
+}
+
+// TestVoxelAtGeographicShellBoundaries checks radial lookup at and around shell edges
+func TestVoxelAtGeographicShellBoundaries(t *testing.T) {
+	planet := newLookupPlanet(t)
+
+	for i := 1; i < len(planet.Shells); i++ {
+		boundary := planet.Shells[i].InnerRadius - planet.Radius
+
+		if _, coord := planet.VoxelAtGeographic(0, 0, boundary+1.0); coord.Shell != i {
+			t.Errorf("1 m above inner edge of shell %d: got shell %d", i, coord.Shell)
+		}
+		if _, coord := planet.VoxelAtGeographic(0, 0, boundary-1.0); coord.Shell != i-1 {
+			t.Errorf("1 m below shell %d: got shell %d, want %d", i, coord.Shell, i-1)
+		}
+		mid := (planet.Shells[i].InnerRadius+planet.Shells[i].OuterRadius)/2 - planet.Radius
+		if _, coord := planet.VoxelAtGeographic(0, 0, mid); coord.Shell != i {
+			t.Errorf("middle of shell %d: got shell %d", i, coord.Shell)
+		}
+	}
+
+	// Outside the grid
+	top := planet.Shells[len(planet.Shells)-1].OuterRadius - planet.Radius
+	if voxel, coord := planet.VoxelAtGeographic(0, 0, top+1.0); voxel != nil || coord.Shell != -1 {
+		t.Errorf("above atmosphere: got %+v, want nil", coord)
+	}
+	bottom := planet.Shells[0].InnerRadius - planet.Radius
+	if voxel, coord := planet.VoxelAtGeographic(0, 0, bottom-1.0); voxel != nil || coord.Shell != -1 {
+		t.Errorf("below innermost shell: got %+v, want nil", coord)
+	}
+}
+
+// TestColumn checks a column holds one voxel per shell, innermost first, and
+// runs without gaps from the innermost shell out past the surface
+func TestColumn(t *testing.T) {
+	planet := newLookupPlanet(t)
+	lat, lon := 37.5, 122.0
+
+	column := planet.Column(lat, lon)
+	if len(column) != len(planet.Shells) {
+		t.Fatalf("column has %d voxels, want one per shell (%d)", len(column), len(planet.Shells))
+	}
+
+	top := planet.Shells[len(planet.Shells)-1].OuterRadius
+	if top < planet.Radius {
+		t.Errorf("column ends at radius %.0f m, below the surface at %.0f m", top, planet.Radius)
+	}
+
+	for i, voxel := range column {
+		shell := planet.Shells[i]
+		if i > 0 && shell.InnerRadius != planet.Shells[i-1].OuterRadius {
+			t.Errorf("gap between shells %d and %d", i-1, i)
+		}
+
+		mid := (shell.InnerRadius + shell.OuterRadius) / 2
+		want, coord := planet.VoxelAtGeographic(lat, lon, mid-planet.Radius)
+		if coord.Shell != i || voxel != want {
+			t.Errorf("column[%d] is not the voxel at (%.1f, %.1f) in shell %d", i, lat, lon, i)
+		}
+	}
+}
+
+// TestGetVoxelAtLatLonRoundTrip checks every surface voxel is found again at
+// its band's latitude and its longitude center, whatever the band's
+// longitude count
+func TestGetVoxelAtLatLonRoundTrip(t *testing.T) {
+	planet := newLookupPlanet(t)
+	surface := len(planet.Shells) - 2
+	shell := &planet.Shells[surface]
+
+	for latIdx := 0; latIdx < shell.LatBands; latIdx++ {
+		lat := core.GetLatitudeForBand(latIdx, shell.LatBands)
+		lonCount := shell.LonCounts[latIdx]
+		for lonIdx := 0; lonIdx < lonCount; lonIdx++ {
+			lon := core.GetLongitudeForIndex(lonIdx, lonCount) + 180.0/float64(lonCount)
+			voxel, coord, ok := planet.GetVoxelAtLatLon(lat, lon, -1000.0)
+			want := core.VoxelCoord{Shell: surface, Lat: latIdx, Lon: lonIdx}
+			if !ok || coord != want || voxel != planet.GetVoxel(want) {
+				t.Fatalf("(%.2f, %.2f): got %+v (%v), want %+v", lat, lon, coord, ok, want)
+			}
+		}
+	}
+}
+
+// TestGetVoxelAtLatLonPoles checks each pole is one band at every longitude
+func TestGetVoxelAtLatLonPoles(t *testing.T) {
+	planet := newLookupPlanet(t)
+	surface := len(planet.Shells) - 2
+	shell := &planet.Shells[surface]
+
+	for _, tc := range []struct {
+		lat      float64
+		wantBand int
+	}{
+		{90, shell.LatBands - 1},
+		{89.9, shell.LatBands - 1},
+		{-90, 0},
+		{-89.9, 0},
+	} {
+		for lon := -180.0; lon <= 180.0; lon += 30 {
+			voxel, coord, ok := planet.GetVoxelAtLatLon(tc.lat, lon, -1000.0)
+			if !ok || coord.Lat != tc.wantBand || voxel != planet.GetVoxel(coord) {
+				t.Errorf("(%.1f, %.0f): got %+v (%v), want band %d", tc.lat, lon, coord, ok, tc.wantBand)
+			}
+			if coord.Lon < 0 || coord.Lon >= shell.LonCounts[coord.Lat] {
+				t.Errorf("(%.1f, %.0f): longitude index %d outside the band", tc.lat, lon, coord.Lon)
+			}
+		}
+	}
+}
+
+// TestGetVoxelAtLatLonSeam checks the first and last voxels of a band meet
+// at ±180° and that longitudes wrap there
+func TestGetVoxelAtLatLonSeam(t *testing.T) {
+	planet := newLookupPlanet(t)
+	surface := len(planet.Shells) - 2
+	shell := &planet.Shells[surface]
+	lat := 20.0
+	last := shell.LonCounts[core.GetBandForLatitude(lat, shell.LatBands)] - 1
+
+	for _, tc := range []struct {
+		lon     float64
+		wantLon int
+	}{
+		{-180, 0},
+		{180, 0},
+		{-179.999, 0},
+		{179.999, last},
+		{-180.001, last},
+		{540, 0},
+		{-539.999, 0},
+	} {
+		_, coord, ok := planet.GetVoxelAtLatLon(lat, tc.lon, -1000.0)
+		if !ok || coord.Lon != tc.wantLon {
+			t.Errorf("lon %.3f: got index %d (%v), want %d", tc.lon, coord.Lon, ok, tc.wantLon)
+		}
+	}
+}
+
+// TestGetVoxelAtLatLonOutside checks positions off the grid aren't found
+func TestGetVoxelAtLatLonOutside(t *testing.T) {
+	planet := newLookupPlanet(t)
+	top := planet.Shells[len(planet.Shells)-1].OuterRadius - planet.Radius
+	bottom := planet.Shells[0].InnerRadius - planet.Radius
+
+	for _, tc := range []struct {
+		name          string
+		lat, lon, alt float64
+	}{
+		{"Above atmosphere", 0, 0, top + 1},
+		{"Below innermost shell", 0, 0, bottom - 1},
+		{"Beyond north pole", 90.5, 0, -1000},
+		{"Beyond south pole", -91, 0, -1000},
+		{"NaN latitude", math.NaN(), 0, -1000},
+		{"Infinite longitude", 0, math.Inf(1), -1000},
+	} {
+		if voxel, coord, ok := planet.GetVoxelAtLatLon(tc.lat, tc.lon, tc.alt); ok || voxel != nil || coord.Shell != -1 {
+			t.Errorf("%s: got %+v (%v), want nothing", tc.name, coord, ok)
+		}
+	}
+}
+
+// TestGetVoxelAtLatLonMatchesVoxelAtGeographic checks both lookups pick the
+// same voxel away from the poles, including on band and voxel edges
+func TestGetVoxelAtLatLonMatchesVoxelAtGeographic(t *testing.T) {
+	planet := newLookupPlanet(t)
+
+	for _, alt := range []float64{-1000.0, -planet.Radius / 2} {
+		for lat := -85.0; lat <= 85.0; lat += 0.25 {
+			for lon := -180.0; lon < 180.0; lon += 7.5 {
+				want, wantCoord := planet.VoxelAtGeographic(lat, lon, alt)
+				got, coord, ok := planet.GetVoxelAtLatLon(lat, lon, alt)
+				if !ok || got != want || coord != wantCoord {
+					t.Fatalf("(%.2f, %.1f, %.0f): GetVoxelAtLatLon %+v (%v), VoxelAtGeographic %+v", lat, lon, alt, coord, ok, wantCoord)
+				}
+			}
+		}
+	}
+}
+
+// TestVoxelLatLonRoundTrip checks VoxelAtGeographic finds every voxel at the
+// position VoxelLatLon gives for it
+func TestVoxelLatLonRoundTrip(t *testing.T) {
+	planet := newLookupPlanet(t)
+	surface := len(planet.Shells) - 2
+	shell := &planet.Shells[surface]
+
+	for latIdx := 0; latIdx < shell.LatBands; latIdx++ {
+		for lonIdx := 0; lonIdx < shell.LonCounts[latIdx]; lonIdx++ {
+			want := core.VoxelCoord{Shell: surface, Lat: latIdx, Lon: lonIdx}
+			lat, lon := planet.VoxelLatLon(want)
+			if _, coord := planet.VoxelAtGeographic(lat, lon, -1000.0); coord != want {
+				t.Fatalf("(%.2f, %.2f): got %+v, want %+v", lat, lon, coord, want)
+			}
+		}
+	}
+}
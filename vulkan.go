@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"worldgenerator/core"
+	"worldgenerator/gpu"
+	"worldgenerator/physics"
+	"worldgenerator/rendering/vulkan"
+)
+
+// vulkanReportInterval is how often the Vulkan loop updates the window title
+// and console stats
+const vulkanReportInterval = 5 * time.Second
+
+// runVulkan shows the planet evolving in a Vulkan window until it is closed,
+// the end condition is met or a shutdown signal arrives. It drives the
+// threaded physics engine like the OpenGL loop, without the features the
+// Vulkan renderer doesn't have yet: snapshots, regeneration, plate picking
+// and the HTTP API. It returns the final planet once the physics thread has
+// stopped, ready for exports
+func runVulkan(renderer *vulkan.VoxelRenderer, planet *core.VoxelPlanet, gpuCompute gpu.GPUCompute,
+	simSpeed, physicsDt, stepYears float64, fixedTick bool, endCondition *core.EndCondition, quiet bool,
+	shutdown *shutdownSignal) *core.VoxelPlanet {
+	reportError := func(err error) {
+		if err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+		}
+	}
+	reportError(renderer.CreateBuffers(planet))
+	reportError(renderer.UpdateVoxelTextures(planet))
+
+	engine := physics.NewThreadedPhysicsInterface(planet, gpuCompute, simSpeed)
+	if physicsDt > 0 {
+		engine.SetFixedTimestep(physicsDt)
+		fmt.Printf("Physics timestep: fixed %.0f years per step\n", physicsDt)
+	}
+	if fixedTick {
+		engine.SetFixedTick(engine.GetPhysicsUpdateInterval())
+		fmt.Printf("Physics ticks: fixed %.0f years each\n", simSpeed*engine.GetPhysicsUpdateInterval())
+	}
+
+	fmt.Println("\nControls:")
+	for _, line := range renderer.ControlsHelp() {
+		fmt.Println("  " + line)
+	}
+	fmt.Println("\nStarting simulation...")
+
+	// endReached checks the -until condition, which ends the run like closing
+	// the window
+	endReached := func() bool {
+		if endCondition == nil || !endCondition.Met(planet) {
+			return false
+		}
+		fmt.Printf("\n🏁 %v met at %.1f My (%s = %g)\n", endCondition, planet.Time/1e6,
+			endCondition.Stat, endCondition.Value(planet))
+		return true
+	}
+	ended := endReached() // Spin-up may already have got there
+
+	frames := 0
+	lastReport := time.Now()
+	for !ended && !renderer.ShouldClose() && !shutdown.Requested() {
+		renderer.PollEvents()
+
+		engine.UpdateSimSpeed(simSpeed * float64(renderer.SpeedMultiplier))
+		engine.SetPaused(renderer.Paused)
+		if renderer.StepRequested {
+			renderer.StepRequested = false
+			if engine.StepOnce() {
+				fmt.Println("Stepping one physics step")
+			}
+		}
+		if renderer.StepYearsRequested {
+			renderer.StepYearsRequested = false
+			if engine.StepYears(stepYears) {
+				fmt.Printf("Stepping %.0f years\n", stepYears)
+			}
+		}
+
+		if updated, ok := engine.Update(); ok {
+			planet = updated
+
+			// Freeze on the broken state so it can be inspected
+			if planet.Halted() && !renderer.Paused {
+				renderer.Paused = true
+				fmt.Printf("Simulation paused: %v\n", planet.PhysicsFault)
+			}
+			reportError(renderer.UpdateVoxelTextures(planet))
+			ended = endReached()
+		}
+
+		reportError(renderer.Render())
+		frames++
+
+		if elapsed := time.Since(lastReport); elapsed >= vulkanReportInterval {
+			fps := float64(frames) / elapsed.Seconds()
+			renderer.UpdateTitle(planet.Time, fps)
+			if !quiet {
+				status := ""
+				if renderer.Paused {
+					status = " | PAUSED"
+				}
+				fmt.Printf("\rFPS: %.1f | Physics: %.1fms (%s) | Upload: %.1fms | Distance: %.0f km | Sim Time: %.1f My%s    ",
+					fps, engine.GetPhysicsFrameTime()*1000, engine.GetTimings(), renderer.TextureUploadTime().Seconds()*1000,
+					renderer.GetCameraDistance()/1000, planet.Time/1e6, status)
+			}
+			frames = 0
+			lastReport = time.Now()
+		}
+	}
+
+	fmt.Println("\nShutting down...")
+
+	// Let the physics thread finish its step so exports see a settled planet
+	engine.Stop()
+	return engine.GetCurrentPlanet()
+}